@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE album_rating_scores (
+    rating_log_id text not null references album_rating_log(id) on delete cascade,
+    dimension     text not null,
+    score         float not null,
+    primary key (rating_log_id, dimension)
+);
+
+CREATE TABLE album_rating_answers (
+    rating_log_id text not null references album_rating_log(id) on delete cascade,
+    question_key  text not null,
+    value         integer not null,
+    primary key (rating_log_id, question_key)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE album_rating_answers;
+DROP TABLE album_rating_scores;
+-- +goose StatementEnd
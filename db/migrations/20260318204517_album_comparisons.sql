@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE album_comparisons (
+    id                text primary key,
+    user_id           text not null references users(id) on delete cascade,
+    album_id          text not null references albums(id) on delete cascade,
+    opponent_album_id text not null references albums(id) on delete cascade,
+    outcome           text not null,
+    created_at        datetime not null default current_timestamp
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE album_comparisons;
+-- +goose StatementEnd
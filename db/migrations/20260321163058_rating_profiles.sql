@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE rating_profiles (
+    user_id    text primary key references users(id) on delete cascade,
+    profile    text not null,
+    updated_at datetime not null default current_timestamp
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE rating_profiles;
+-- +goose StatementEnd
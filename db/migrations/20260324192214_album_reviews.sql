@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE album_reviews (
+    id            text primary key,
+    user_id       text not null references users(id) on delete cascade,
+    album_id      text not null references albums(id) on delete cascade,
+    rating_log_id text references album_rating_log(id) on delete set null,
+    body          text not null default '',
+    status        text not null default 'draft',
+    published_at  datetime,
+    created_at    datetime not null default current_timestamp,
+    updated_at    datetime not null default current_timestamp,
+    unique(user_id, album_id)
+);
+
+CREATE TABLE album_review_revisions (
+    id         text primary key,
+    review_id  text not null references album_reviews(id) on delete cascade,
+    body       text not null,
+    status     text not null,
+    created_at datetime not null default current_timestamp,
+    updated_at datetime not null default current_timestamp
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE album_review_revisions;
+DROP TABLE album_reviews;
+-- +goose StatementEnd
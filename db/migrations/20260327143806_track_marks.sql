@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE track_marks (
+    user_id    text not null references users(id) on delete cascade,
+    track_id   text not null references tracks(id) on delete cascade,
+    mark       text not null check(mark in ('standout', 'skip')),
+    created_at datetime not null default current_timestamp,
+    updated_at datetime not null default current_timestamp,
+    primary key (user_id, track_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE track_marks;
+-- +goose StatementEnd
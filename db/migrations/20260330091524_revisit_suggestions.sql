@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE revisit_rules (
+    user_id    text primary key references users(id) on delete cascade,
+    rules      text not null,
+    updated_at datetime not null default current_timestamp
+);
+
+CREATE TABLE revisit_suggestions (
+    user_id       text not null references users(id) on delete cascade,
+    album_id      text not null references albums(id) on delete cascade,
+    rating_log_id text not null references album_rating_log(id) on delete cascade,
+    reason        text not null check(reason in ('stale', 'single_listen')),
+    plays_since   integer not null default 0,
+    created_at    datetime not null default current_timestamp,
+    primary key(user_id, album_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE revisit_suggestions;
+DROP TABLE revisit_rules;
+-- +goose StatementEnd
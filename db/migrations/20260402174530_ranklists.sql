@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE ranklists (
+    id          text primary key,
+    user_id     text not null references users(id) on delete cascade,
+    name        text not null,
+    description text not null default '',
+    source      text not null default 'manual' check(source in ('manual', 'tag', 'rating')),
+    tag_id      text references tags(id) on delete set null,
+    min_rating  float,
+    max_rating  float,
+    created_at  datetime not null default current_timestamp,
+    updated_at  datetime not null default current_timestamp
+);
+
+CREATE TABLE ranklist_entries (
+    id          text primary key,
+    ranklist_id text not null references ranklists(id) on delete cascade,
+    album_id    text not null references albums(id) on delete cascade,
+    position    text not null,
+    blurb       text not null default '',
+    created_at  datetime not null default current_timestamp,
+    unique(ranklist_id, album_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE ranklist_entries;
+DROP TABLE ranklists;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE shelves (
+    id          text primary key,
+    user_id     text not null references users(id) on delete cascade,
+    name        text not null,
+    description text not null default '',
+    filter      text,
+    created_at  datetime not null default current_timestamp,
+    updated_at  datetime not null default current_timestamp,
+    unique(user_id, name)
+);
+
+CREATE TABLE shelf_albums (
+    shelf_id    text not null references shelves(id) on delete cascade,
+    album_id    text not null references albums(id) on delete cascade,
+    created_at  datetime not null default current_timestamp,
+    primary key (shelf_id, album_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE shelf_albums;
+DROP TABLE shelves;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE wishlist_items (
+    id             text primary key,
+    user_id        text not null references users(id) on delete cascade,
+    album_id       text references albums(id) on delete set null,
+    musicbrainz_id text,
+    title          text not null,
+    artist_names   text not null default '',
+    image_url      text,
+    format         text not null default 'any' check(format in ('any', 'vinyl', 'cd', 'cassette', 'digital')),
+    priority       integer not null default 2 check(priority between 1 and 3),
+    max_price      real,
+    notes          text not null default '',
+    heard_from     text not null default '',
+    fulfilled_at   datetime,
+    created_at     datetime not null default current_timestamp,
+    updated_at     datetime not null default current_timestamp
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE wishlist_items;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE album_links (
+    id            text primary key,
+    user_id       text not null references users(id) on delete cascade,
+    from_album_id text not null references albums(id) on delete cascade,
+    to_album_id   text not null references albums(id) on delete cascade,
+    kind          text not null check(kind in ('influenced_by', 'sounds_like', 'sequel_to', 'same_session', 'introduced_me_to')),
+    note          text not null default '',
+    created_at    datetime not null default current_timestamp,
+    updated_at    datetime not null default current_timestamp,
+    unique(user_id, from_album_id, to_album_id, kind),
+    check(from_album_id != to_album_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE album_links;
+-- +goose StatementEnd
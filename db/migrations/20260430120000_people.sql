@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE people (
+    id         text primary key,
+    user_id    text not null references users(id) on delete cascade,
+    name       text not null,
+    notes      text not null default '',
+    created_at datetime not null default current_timestamp,
+    updated_at datetime not null default current_timestamp,
+    unique(user_id, name)
+);
+
+CREATE TABLE album_people (
+    id         text primary key,
+    user_id    text not null references users(id) on delete cascade,
+    album_id   text not null references albums(id) on delete cascade,
+    person_id  text not null references people(id) on delete cascade,
+    direction  text not null check(direction in ('introduced_by', 'shared_with')),
+    shared_at  datetime not null default current_timestamp,
+    created_at datetime not null default current_timestamp,
+    unique(user_id, album_id, person_id, direction)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE album_people;
+DROP TABLE people;
+-- +goose StatementEnd
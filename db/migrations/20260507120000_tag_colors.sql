@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tags ADD COLUMN color text not null default 'none' check(color in ('none', 'primary', 'secondary', 'accent', 'info', 'success', 'warning', 'error'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tags DROP COLUMN color;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tag_groups ADD COLUMN position integer not null default 0;
+ALTER TABLE tag_groups ADD COLUMN color text not null default 'none' check(color in ('none', 'primary', 'secondary', 'accent', 'info', 'success', 'warning', 'error'));
+ALTER TABLE tag_groups ADD COLUMN exclusive boolean not null default false;
+UPDATE tag_groups SET position = 1 WHERE name = 'Mood';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tag_groups DROP COLUMN exclusive;
+ALTER TABLE tag_groups DROP COLUMN color;
+ALTER TABLE tag_groups DROP COLUMN position;
+-- +goose StatementEnd
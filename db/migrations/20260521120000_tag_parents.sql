@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tags ADD COLUMN parent_id text references tags(id) on delete set null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tags DROP COLUMN parent_id;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE bulk_edits (
+    id         text primary key,
+    user_id    text not null references users(id) on delete cascade,
+    operation  text not null check(operation in ('add_tag', 'remove_tag', 'add_format', 'clear_ratings', 'add_to_shelf')),
+    summary    text not null,
+    undo       text not null,
+    created_at datetime not null default current_timestamp,
+    undone_at  datetime
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE bulk_edits;
+-- +goose StatementEnd
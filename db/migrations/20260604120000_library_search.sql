@@ -0,0 +1,215 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE VIRTUAL TABLE library_search USING fts5(
+    user_id UNINDEXED,
+    album_id UNINDEXED,
+    title,
+    artist,
+    track,
+    tag,
+    note,
+    tokenize = "unicode61 remove_diacritics 2",
+    prefix = '2 3'
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE VIEW library_search_source AS
+SELECT
+    user_releases.user_id,
+    albums.id AS album_id,
+    albums.title,
+    COALESCE((
+        SELECT GROUP_CONCAT(artists.name, ' ') FROM album_artists
+        JOIN artists ON artists.id = album_artists.artist_id
+        WHERE album_artists.album_id = albums.id
+    ), '') AS artist,
+    COALESCE((
+        SELECT GROUP_CONCAT(tracks.title, ' ') FROM album_tracks
+        JOIN tracks ON tracks.id = album_tracks.track_id
+        WHERE album_tracks.album_id = albums.id
+    ), '') AS track,
+    COALESCE((
+        SELECT GROUP_CONCAT(COALESCE(tag_groups.name || ' ', '') || tags.name, ' ') FROM album_tags
+        JOIN tags ON tags.id = album_tags.tag_id
+        LEFT JOIN tag_groups ON tag_groups.id = tags.group_id
+        WHERE album_tags.user_id = user_releases.user_id AND album_tags.album_id = albums.id
+    ), '') AS tag,
+    COALESCE((
+        SELECT GROUP_CONCAT(album_rating_log.note, ' ') FROM album_rating_log
+        WHERE album_rating_log.user_id = user_releases.user_id AND album_rating_log.album_id = albums.id
+    ), '') AS note
+FROM user_releases
+JOIN releases ON releases.id = user_releases.release_id
+JOIN albums ON albums.id = releases.album_id
+GROUP BY user_releases.user_id, albums.id;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source;
+-- +goose StatementEnd
+
+-- Each trigger rebuilds the index rows of the albums a write touches: one
+-- user's rows for their releases, tags and ratings, every user's rows for an
+-- album's own details.
+
+-- +goose StatementBegin
+CREATE TRIGGER library_search_user_releases_insert AFTER INSERT ON user_releases BEGIN
+    DELETE FROM library_search WHERE user_id = NEW.user_id AND album_id = (SELECT album_id FROM releases WHERE id = NEW.release_id);
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE user_id = NEW.user_id AND album_id = (SELECT album_id FROM releases WHERE id = NEW.release_id);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER library_search_user_releases_delete AFTER DELETE ON user_releases BEGIN
+    DELETE FROM library_search WHERE user_id = OLD.user_id AND album_id = (SELECT album_id FROM releases WHERE id = OLD.release_id);
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE user_id = OLD.user_id AND album_id = (SELECT album_id FROM releases WHERE id = OLD.release_id);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER library_search_album_tags_insert AFTER INSERT ON album_tags BEGIN
+    DELETE FROM library_search WHERE user_id = NEW.user_id AND album_id = NEW.album_id;
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE user_id = NEW.user_id AND album_id = NEW.album_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER library_search_album_tags_delete AFTER DELETE ON album_tags BEGIN
+    DELETE FROM library_search WHERE user_id = OLD.user_id AND album_id = OLD.album_id;
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE user_id = OLD.user_id AND album_id = OLD.album_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER library_search_tags_update AFTER UPDATE OF name, group_id ON tags BEGIN
+    DELETE FROM library_search WHERE user_id = NEW.user_id AND album_id IN (SELECT album_id FROM album_tags WHERE tag_id = NEW.id);
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE user_id = NEW.user_id AND album_id IN (SELECT album_id FROM album_tags WHERE tag_id = NEW.id);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER library_search_tag_groups_update AFTER UPDATE OF name ON tag_groups BEGIN
+    DELETE FROM library_search WHERE user_id = NEW.user_id AND album_id IN (
+        SELECT album_tags.album_id FROM album_tags JOIN tags ON tags.id = album_tags.tag_id WHERE tags.group_id = NEW.id
+    );
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE user_id = NEW.user_id AND album_id IN (
+        SELECT album_tags.album_id FROM album_tags JOIN tags ON tags.id = album_tags.tag_id WHERE tags.group_id = NEW.id
+    );
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER library_search_album_rating_log_insert AFTER INSERT ON album_rating_log BEGIN
+    DELETE FROM library_search WHERE user_id = NEW.user_id AND album_id = NEW.album_id;
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE user_id = NEW.user_id AND album_id = NEW.album_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER library_search_album_rating_log_update AFTER UPDATE OF note ON album_rating_log BEGIN
+    DELETE FROM library_search WHERE user_id = NEW.user_id AND album_id = NEW.album_id;
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE user_id = NEW.user_id AND album_id = NEW.album_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER library_search_album_rating_log_delete AFTER DELETE ON album_rating_log BEGIN
+    DELETE FROM library_search WHERE user_id = OLD.user_id AND album_id = OLD.album_id;
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE user_id = OLD.user_id AND album_id = OLD.album_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER library_search_albums_update AFTER UPDATE OF title ON albums BEGIN
+    DELETE FROM library_search WHERE album_id = NEW.id;
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE album_id = NEW.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER library_search_album_artists_insert AFTER INSERT ON album_artists BEGIN
+    DELETE FROM library_search WHERE album_id = NEW.album_id;
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE album_id = NEW.album_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER library_search_album_artists_delete AFTER DELETE ON album_artists BEGIN
+    DELETE FROM library_search WHERE album_id = OLD.album_id;
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE album_id = OLD.album_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER library_search_artists_update AFTER UPDATE OF name ON artists BEGIN
+    DELETE FROM library_search WHERE album_id IN (SELECT album_id FROM album_artists WHERE artist_id = NEW.id);
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE album_id IN (SELECT album_id FROM album_artists WHERE artist_id = NEW.id);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER library_search_album_tracks_insert AFTER INSERT ON album_tracks BEGIN
+    DELETE FROM library_search WHERE album_id = NEW.album_id;
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE album_id = NEW.album_id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER library_search_tracks_update AFTER UPDATE OF title ON tracks BEGIN
+    DELETE FROM library_search WHERE album_id IN (SELECT album_id FROM album_tracks WHERE track_id = NEW.id);
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE album_id IN (SELECT album_id FROM album_tracks WHERE track_id = NEW.id);
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER library_search_tracks_update;
+DROP TRIGGER library_search_album_tracks_insert;
+DROP TRIGGER library_search_artists_update;
+DROP TRIGGER library_search_album_artists_delete;
+DROP TRIGGER library_search_album_artists_insert;
+DROP TRIGGER library_search_albums_update;
+DROP TRIGGER library_search_album_rating_log_delete;
+DROP TRIGGER library_search_album_rating_log_update;
+DROP TRIGGER library_search_album_rating_log_insert;
+DROP TRIGGER library_search_tag_groups_update;
+DROP TRIGGER library_search_tags_update;
+DROP TRIGGER library_search_album_tags_delete;
+DROP TRIGGER library_search_album_tags_insert;
+DROP TRIGGER library_search_user_releases_delete;
+DROP TRIGGER library_search_user_releases_insert;
+DROP VIEW library_search_source;
+DROP TABLE library_search;
+-- +goose StatementEnd
@@ -0,0 +1,72 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE album_artists ADD COLUMN position integer not null default 0;
+ALTER TABLE album_artists ADD COLUMN role text not null default 'primary' check(role in ('primary', 'featured'));
+
+-- Credits were listed in the order they were inserted.
+UPDATE album_artists SET position = (
+    SELECT COUNT(*) FROM album_artists AS earlier
+    WHERE earlier.album_id = album_artists.album_id AND earlier.rowid < album_artists.rowid
+);
+
+-- sort_name is the name with a leading article moved to the end, as in
+-- "Beatles, The", or null when the name sorts as it is.
+ALTER TABLE artists ADD COLUMN sort_name text;
+
+UPDATE artists SET sort_name = CASE
+    WHEN name LIKE 'The %' THEN substr(name, 5) || ', ' || substr(name, 1, 3)
+    WHEN name LIKE 'An %' THEN substr(name, 4) || ', ' || substr(name, 1, 2)
+    WHEN name LIKE 'A %' THEN substr(name, 3) || ', ' || substr(name, 1, 1)
+END;
+
+CREATE TRIGGER artists_sort_name_insert AFTER INSERT ON artists BEGIN
+    UPDATE artists SET sort_name = CASE
+        WHEN name LIKE 'The %' THEN substr(name, 5) || ', ' || substr(name, 1, 3)
+        WHEN name LIKE 'An %' THEN substr(name, 4) || ', ' || substr(name, 1, 2)
+        WHEN name LIKE 'A %' THEN substr(name, 3) || ', ' || substr(name, 1, 1)
+    END
+    WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER artists_sort_name_update AFTER UPDATE OF name ON artists BEGIN
+    UPDATE artists SET sort_name = CASE
+        WHEN name LIKE 'The %' THEN substr(name, 5) || ', ' || substr(name, 1, 3)
+        WHEN name LIKE 'An %' THEN substr(name, 4) || ', ' || substr(name, 1, 2)
+        WHEN name LIKE 'A %' THEN substr(name, 3) || ', ' || substr(name, 1, 1)
+    END
+    WHERE id = NEW.id;
+END;
+
+-- A user's own sort name for an artist, overriding the derived one.
+CREATE TABLE artist_sort_names (
+    user_id    text not null references users(id) on delete cascade,
+    artist_id  text not null references artists(id) on delete cascade,
+    sort_name  text not null,
+    created_at datetime not null default current_timestamp,
+    unique(user_id, artist_id)
+);
+
+-- Other identities of an artist: the Spotify profiles of duplicates merged
+-- into it, its ids on other sources and names entered by hand. Syncing an
+-- album credited to a Spotify alias credits the artist it belongs to.
+CREATE TABLE artist_aliases (
+    id          text primary key,
+    artist_id   text not null references artists(id) on delete cascade,
+    source      text not null check(source in ('spotify', 'musicbrainz', 'discogs', 'manual')),
+    external_id text,
+    name        text not null,
+    created_at  datetime not null default current_timestamp,
+    unique(source, external_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE artist_aliases;
+DROP TABLE artist_sort_names;
+DROP TRIGGER artists_sort_name_update;
+DROP TRIGGER artists_sort_name_insert;
+ALTER TABLE artists DROP COLUMN sort_name;
+ALTER TABLE album_artists DROP COLUMN role;
+ALTER TABLE album_artists DROP COLUMN position;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Plays are stored in UTC; the user's timezone, an IANA name, puts them on
+-- the user's own days and hours.
+ALTER TABLE users ADD COLUMN timezone text not null default 'UTC';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN timezone;
+-- +goose StatementEnd
@@ -1,15 +1,44 @@
 -- name: GetOrCreateAlbumArtist :one
-INSERT INTO album_artists (album_id, artist_id) VALUES (?, ?)
+INSERT INTO album_artists (album_id, artist_id, position, role) VALUES (?, ?, ?, ?)
 ON CONFLICT (album_id, artist_id)
-DO UPDATE SET album_id = album_id
+DO UPDATE SET position = excluded.position, role = excluded.role
 RETURNING *;
 
 -- name: GetAlbumArtistByAlbumId :many
-SELECT album_artists.album_id, sqlc.embed(artists) FROM album_artists
+SELECT album_artists.album_id, album_artists.role, sqlc.embed(artists) FROM album_artists
 JOIN artists ON album_artists.artist_id = artists.id
-WHERE album_id = ?;
+WHERE album_id = ?
+ORDER BY album_artists.position, album_artists.rowid;
 
 -- name: GetAlbumArtistsByAlbumIds :many
-SELECT album_artists.album_id, sqlc.embed(artists) FROM album_artists
+SELECT album_artists.album_id, album_artists.role, sqlc.embed(artists) FROM album_artists
 JOIN artists ON album_artists.artist_id = artists.id
-WHERE album_id IN (sqlc.slice('album_ids'));
+WHERE album_id IN (sqlc.slice('album_ids'))
+ORDER BY album_artists.position, album_artists.rowid;
+
+-- name: GetLibraryArtists :many
+SELECT DISTINCT artists.* FROM artists
+JOIN album_artists ON album_artists.artist_id = artists.id
+JOIN releases ON releases.album_id = album_artists.album_id
+JOIN user_releases ON user_releases.release_id = releases.id
+WHERE user_releases.user_id = ?
+ORDER BY artists.name;
+
+-- name: GetArtistAlbumPlays :many
+SELECT albums.id, albums.spotify_id, albums.title, COUNT(track_plays.id) AS plays
+FROM album_artists
+JOIN albums ON albums.id = album_artists.album_id
+LEFT JOIN track_plays ON track_plays.album_id = albums.id AND track_plays.user_id = ?
+WHERE album_artists.artist_id = ?
+GROUP BY albums.id;
+
+-- name: MoveAlbumArtists :exec
+INSERT INTO album_artists (album_id, artist_id, position, role)
+SELECT album_id, ?, position, role FROM album_artists WHERE artist_id = ?
+ON CONFLICT (album_id, artist_id)
+DO UPDATE SET
+    position = MIN(album_artists.position, excluded.position),
+    role = CASE WHEN excluded.role = 'primary' THEN 'primary' ELSE album_artists.role END;
+
+-- name: DeleteAlbumArtistsByArtistId :exec
+DELETE FROM album_artists WHERE artist_id = ?;
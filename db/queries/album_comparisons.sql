@@ -0,0 +1,21 @@
+-- name: InsertAlbumComparison :one
+INSERT INTO album_comparisons (id, user_id, album_id, opponent_album_id, outcome, created_at)
+VALUES (?, ?, ?, ?, ?, current_timestamp)
+RETURNING *;
+
+-- name: GetComparisonCandidates :many
+SELECT albums.id, albums.title, albums.image_url, arl.rating,
+    COALESCE((
+        SELECT GROUP_CONCAT(a.name, ', ')
+        FROM (SELECT DISTINCT ar.id, ar.name FROM album_artists aa JOIN artists ar ON ar.id = aa.artist_id WHERE aa.album_id = albums.id) AS a
+    ), '') as artist_names
+FROM album_rating_log arl
+JOIN (
+    SELECT arl2.album_id, MAX(arl2.created_at) AS max_created_at
+    FROM album_rating_log arl2
+    WHERE arl2.user_id = ?
+    GROUP BY arl2.album_id
+) latest ON arl.album_id = latest.album_id AND arl.created_at = latest.max_created_at
+JOIN albums ON albums.id = arl.album_id
+WHERE arl.user_id = ? AND arl.album_id != ?
+ORDER BY arl.rating ASC, albums.id ASC;
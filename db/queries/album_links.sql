@@ -0,0 +1,22 @@
+-- name: CreateAlbumLink :one
+INSERT INTO album_links (id, user_id, from_album_id, to_album_id, kind, note)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetAlbumLink :one
+SELECT * FROM album_links WHERE id = ? AND user_id = ?;
+
+-- name: GetAlbumLinkByEnds :one
+SELECT * FROM album_links
+WHERE user_id = ? AND from_album_id = ? AND to_album_id = ? AND kind = ?;
+
+-- name: GetAlbumLinksByAlbumId :many
+SELECT * FROM album_links
+WHERE user_id = ? AND (from_album_id = ? OR to_album_id = ?)
+ORDER BY created_at;
+
+-- name: GetAlbumLinksByUserId :many
+SELECT * FROM album_links WHERE user_id = ? ORDER BY created_at;
+
+-- name: DeleteAlbumLink :exec
+DELETE FROM album_links WHERE id = ? AND user_id = ?;
@@ -0,0 +1,27 @@
+-- name: CreateAlbumPerson :one
+INSERT INTO album_people (id, user_id, album_id, person_id, direction, shared_at) VALUES (?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetAlbumPerson :one
+SELECT * FROM album_people WHERE id = ? AND user_id = ?;
+
+-- name: GetAlbumPersonByEnds :one
+SELECT * FROM album_people
+WHERE user_id = ? AND album_id = ? AND person_id = ? AND direction = ?;
+
+-- name: GetAlbumPeopleByAlbumId :many
+SELECT sqlc.embed(album_people), people.name AS person_name
+FROM album_people
+JOIN people ON people.id = album_people.person_id
+WHERE album_people.user_id = ? AND album_people.album_id = ?
+ORDER BY album_people.shared_at, album_people.created_at;
+
+-- name: GetAlbumPeopleByAlbumIds :many
+SELECT album_id, person_id, direction FROM album_people
+WHERE user_id = ? AND album_id IN (sqlc.slice('album_ids'));
+
+-- name: DeleteAlbumPerson :exec
+DELETE FROM album_people WHERE id = ? AND user_id = ?;
+
+-- name: DeleteAlbumPeopleByPersonId :exec
+DELETE FROM album_people WHERE person_id = ? AND user_id = ?;
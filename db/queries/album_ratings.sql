@@ -10,7 +10,7 @@ WHERE id = ? AND user_id = ?;
 -- name: GetLatestUserAlbumRating :one
 SELECT * FROM album_rating_log
 WHERE user_id = ? AND album_id = ?
-ORDER BY created_at DESC
+ORDER BY created_at DESC, rowid DESC
 LIMIT 1;
 
 -- name: GetLatestUserAlbumRatings :many
@@ -26,7 +26,7 @@ WHERE arl.user_id = ?;
 -- name: GetUserAlbumRatingLog :many
 SELECT * FROM album_rating_log
 WHERE user_id = ? AND album_id = ?
-ORDER BY created_at DESC;
+ORDER BY created_at DESC, rowid DESC;
 
 -- name: GetUnratedAlbums :many
 SELECT albums.*,
@@ -54,3 +54,63 @@ WHERE user_releases.user_id = ?
 GROUP BY albums.id
 ORDER BY MAX(track_plays.played_at) DESC NULLS LAST, MAX(user_releases.added_at) DESC
 LIMIT 20;
+
+-- name: InsertAlbumRatingScore :exec
+INSERT INTO album_rating_scores (rating_log_id, dimension, score)
+VALUES (?, ?, ?);
+
+-- name: InsertAlbumRatingAnswer :exec
+INSERT INTO album_rating_answers (rating_log_id, question_key, value)
+VALUES (?, ?, ?);
+
+-- name: GetAlbumRatingScoresByEntryIds :many
+SELECT * FROM album_rating_scores
+WHERE rating_log_id IN (sqlc.slice('entry_ids'));
+
+-- name: GetAlbumRatingAnswersByEntryIds :many
+SELECT * FROM album_rating_answers
+WHERE rating_log_id IN (sqlc.slice('entry_ids'));
+
+-- name: DeleteAlbumRatingScoresByEntryId :exec
+DELETE FROM album_rating_scores
+WHERE rating_log_id IN (
+    SELECT id FROM album_rating_log
+    WHERE album_rating_log.id = ? AND album_rating_log.user_id = ?
+);
+
+-- name: DeleteAlbumRatingAnswersByEntryId :exec
+DELETE FROM album_rating_answers
+WHERE rating_log_id IN (
+    SELECT id FROM album_rating_log
+    WHERE album_rating_log.id = ? AND album_rating_log.user_id = ?
+);
+
+-- name: GetUserRatingLogWithAlbums :many
+SELECT album_rating_log.id, album_rating_log.album_id, album_rating_log.rating, album_rating_log.created_at,
+    albums.title AS album_title,
+    COALESCE((
+        SELECT GROUP_CONCAT(a.name, ', ')
+        FROM (SELECT DISTINCT ar.id, ar.name FROM album_artists aa JOIN artists ar ON ar.id = aa.artist_id WHERE aa.album_id = albums.id) AS a
+    ), '') as artist_names
+FROM album_rating_log
+JOIN albums ON albums.id = album_rating_log.album_id
+WHERE album_rating_log.user_id = ?
+ORDER BY album_rating_log.created_at, album_rating_log.rowid;
+
+-- name: GetAlbumRatingLogByAlbumIds :many
+SELECT * FROM album_rating_log
+WHERE user_id = ? AND album_id IN (sqlc.slice('album_ids'));
+
+-- name: RestoreAlbumRatingLogEntry :exec
+INSERT INTO album_rating_log (id, user_id, album_id, rating, note, created_at)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: GetLatestUserAlbumRatingsByAlbumIds :many
+SELECT arl.* FROM album_rating_log arl
+JOIN (
+    SELECT arl2.album_id, MAX(arl2.created_at) AS max_created_at
+    FROM album_rating_log arl2
+    WHERE arl2.user_id = ? AND arl2.album_id IN (sqlc.slice('album_ids'))
+    GROUP BY arl2.album_id
+) latest ON arl.album_id = latest.album_id AND arl.created_at = latest.max_created_at
+WHERE arl.user_id = ?;
@@ -0,0 +1,62 @@
+-- name: GetAlbumReview :one
+SELECT * FROM album_reviews
+WHERE user_id = ? AND album_id = ?;
+
+-- name: InsertAlbumReview :one
+INSERT INTO album_reviews (id, user_id, album_id, rating_log_id, body, status, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, current_timestamp, current_timestamp)
+RETURNING *;
+
+-- name: UpdateAlbumReview :one
+UPDATE album_reviews
+SET body = ?, rating_log_id = ?, status = ?, updated_at = current_timestamp
+WHERE id = ? AND user_id = ?
+RETURNING *;
+
+-- name: PublishAlbumReview :one
+UPDATE album_reviews
+SET status = 'published', published_at = current_timestamp, updated_at = current_timestamp
+WHERE id = ? AND user_id = ?
+RETURNING *;
+
+-- name: DeleteAlbumReview :exec
+DELETE FROM album_reviews
+WHERE id = ? AND user_id = ?;
+
+-- name: ClearAlbumReviewRatingLogId :exec
+UPDATE album_reviews
+SET rating_log_id = NULL
+WHERE rating_log_id = ? AND user_id = ?;
+
+-- name: GetAlbumReviewRevisions :many
+SELECT * FROM album_review_revisions
+WHERE review_id = ?
+ORDER BY created_at DESC, rowid DESC;
+
+-- name: GetLatestAlbumReviewRevision :one
+SELECT * FROM album_review_revisions
+WHERE review_id = ?
+ORDER BY created_at DESC, rowid DESC
+LIMIT 1;
+
+-- name: InsertAlbumReviewRevision :exec
+INSERT INTO album_review_revisions (id, review_id, body, status, created_at, updated_at)
+VALUES (?, ?, ?, ?, current_timestamp, current_timestamp);
+
+-- name: UpdateAlbumReviewRevision :exec
+UPDATE album_review_revisions
+SET body = ?, updated_at = current_timestamp
+WHERE id = ?;
+
+-- name: DeleteAlbumReviewRevisionsByReviewId :exec
+DELETE FROM album_review_revisions
+WHERE review_id = ?;
+
+-- name: GetAlbumReviewsByRatingLogIds :many
+SELECT * FROM album_reviews
+WHERE user_id = ? AND rating_log_id IN (sqlc.slice('rating_log_ids'));
+
+-- name: SetAlbumReviewRatingLogId :exec
+UPDATE album_reviews
+SET rating_log_id = ?
+WHERE id = ? AND user_id = ? AND rating_log_id IS NULL;
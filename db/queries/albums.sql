@@ -15,3 +15,9 @@ SELECT * FROM albums WHERE id IN (sqlc.slice('ids'));
 
 -- name: GetAlbumBySpotifyId :one
 SELECT * FROM albums WHERE spotify_id = ?;
+
+-- name: GetLibraryAlbumsBySpotifyIds :many
+SELECT DISTINCT albums.* FROM albums
+JOIN releases ON releases.album_id = albums.id
+JOIN user_releases ON user_releases.release_id = releases.id
+WHERE user_releases.user_id = ? AND albums.spotify_id IN (sqlc.slice('spotify_ids'));
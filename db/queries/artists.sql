@@ -12,3 +12,58 @@ SELECT * FROM artists WHERE id = ?;
 
 -- name: GetArtistBySpotifyId :one
 SELECT * FROM artists WHERE spotify_id = ?;
+
+-- name: GetArtistBySpotifyAlias :one
+SELECT artists.* FROM artist_aliases
+JOIN artists ON artists.id = artist_aliases.artist_id
+WHERE artist_aliases.source = 'spotify' AND artist_aliases.external_id = ?;
+
+-- name: DeleteArtist :exec
+DELETE FROM artists WHERE id = ?;
+
+-- name: GetArtistSortNames :many
+SELECT * FROM artist_sort_names WHERE user_id = ?;
+
+-- name: UpsertArtistSortName :exec
+INSERT INTO artist_sort_names (user_id, artist_id, sort_name) VALUES (?, ?, ?)
+ON CONFLICT (user_id, artist_id)
+DO UPDATE SET sort_name = excluded.sort_name;
+
+-- name: DeleteArtistSortName :exec
+DELETE FROM artist_sort_names WHERE user_id = ? AND artist_id = ?;
+
+-- name: MoveArtistSortNames :exec
+UPDATE OR IGNORE artist_sort_names SET artist_id = ? WHERE artist_id = ?;
+
+-- name: DeleteArtistSortNamesByArtistId :exec
+DELETE FROM artist_sort_names WHERE artist_id = ?;
+
+-- name: GetArtistAliases :many
+SELECT * FROM artist_aliases WHERE artist_id = ?
+ORDER BY created_at, rowid;
+
+-- name: CreateArtistAlias :one
+INSERT INTO artist_aliases (id, artist_id, source, external_id, name) VALUES (?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: UpsertArtistAlias :exec
+INSERT INTO artist_aliases (id, artist_id, source, external_id, name) VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (source, external_id)
+DO UPDATE SET artist_id = excluded.artist_id, name = excluded.name;
+
+-- name: MoveArtistAliases :exec
+UPDATE artist_aliases SET artist_id = ? WHERE artist_id = ?;
+
+-- name: DeleteArtistAlias :execrows
+DELETE FROM artist_aliases WHERE id = ? AND artist_id = ?;
+
+-- name: GetArtistAlias :one
+SELECT * FROM artist_aliases WHERE source = ? AND external_id = ?;
+
+-- name: IsArtistInUserLibrary :one
+SELECT EXISTS (
+    SELECT 1 FROM album_artists
+    JOIN releases ON releases.album_id = album_artists.album_id
+    JOIN user_releases ON user_releases.release_id = releases.id
+    WHERE album_artists.artist_id = ? AND user_releases.user_id = ?
+) AS in_library;
@@ -0,0 +1,9 @@
+-- name: InsertBulkEdit :one
+INSERT INTO bulk_edits (id, user_id, operation, summary, undo) VALUES (?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetBulkEdit :one
+SELECT * FROM bulk_edits WHERE id = ? AND user_id = ?;
+
+-- name: MarkBulkEditUndone :exec
+UPDATE bulk_edits SET undone_at = current_timestamp WHERE id = ? AND user_id = ?;
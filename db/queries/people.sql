@@ -0,0 +1,40 @@
+-- name: CreatePerson :one
+INSERT INTO people (id, user_id, name, notes) VALUES (?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetPerson :one
+SELECT * FROM people WHERE id = ? AND user_id = ?;
+
+-- name: GetPersonByName :one
+SELECT * FROM people WHERE user_id = ? AND name = ? COLLATE NOCASE;
+
+-- name: GetPeopleByUserId :many
+SELECT * FROM people WHERE user_id = ? ORDER BY name COLLATE NOCASE;
+
+-- name: UpdatePerson :exec
+UPDATE people SET name = ?, notes = ?, updated_at = current_timestamp
+WHERE id = ? AND user_id = ?;
+
+-- name: DeletePerson :exec
+DELETE FROM people WHERE id = ? AND user_id = ?;
+
+-- name: GetPersonRecommendationStats :many
+SELECT sqlc.embed(people),
+    COUNT(album_people.id) AS introduced_count,
+    COUNT(album_rating_log.rating) AS rated_count,
+    AVG(album_rating_log.rating) AS average_rating,
+    (
+        SELECT COUNT(*) FROM album_people shared
+        WHERE shared.person_id = people.id AND shared.direction = 'shared_with'
+    ) AS shared_count
+FROM people
+LEFT JOIN album_people ON album_people.person_id = people.id AND album_people.direction = 'introduced_by'
+LEFT JOIN album_rating_log ON album_rating_log.id = (
+    SELECT arl.id FROM album_rating_log arl
+    WHERE arl.user_id = people.user_id AND arl.album_id = album_people.album_id
+    ORDER BY arl.created_at DESC, arl.rowid DESC
+    LIMIT 1
+)
+WHERE people.user_id = ?
+GROUP BY people.id
+ORDER BY average_rating DESC NULLS LAST, introduced_count DESC, people.name COLLATE NOCASE;
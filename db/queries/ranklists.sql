@@ -0,0 +1,138 @@
+-- name: CreateRanklist :one
+INSERT INTO ranklists (id, user_id, name, description, source, tag_id, min_rating, max_rating)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetRanklist :one
+SELECT sqlc.embed(ranklists),
+    COALESCE(tags.name, '') as tag_name,
+    (SELECT COUNT(*) FROM ranklist_entries WHERE ranklist_entries.ranklist_id = ranklists.id) as entry_count
+FROM ranklists
+LEFT JOIN tags ON tags.id = ranklists.tag_id
+WHERE ranklists.id = ? AND ranklists.user_id = ?;
+
+-- name: GetRanklistsByUserId :many
+SELECT sqlc.embed(ranklists),
+    COALESCE(tags.name, '') as tag_name,
+    (SELECT COUNT(*) FROM ranklist_entries WHERE ranklist_entries.ranklist_id = ranklists.id) as entry_count
+FROM ranklists
+LEFT JOIN tags ON tags.id = ranklists.tag_id
+WHERE ranklists.user_id = ?
+ORDER BY ranklists.updated_at DESC, ranklists.created_at DESC;
+
+-- name: UpdateRanklist :exec
+UPDATE ranklists
+SET name = ?, description = ?, updated_at = current_timestamp
+WHERE id = ? AND user_id = ?;
+
+-- name: TouchRanklist :exec
+UPDATE ranklists
+SET updated_at = current_timestamp
+WHERE id = ?;
+
+-- name: DeleteRanklist :exec
+DELETE FROM ranklists
+WHERE id = ? AND user_id = ?;
+
+-- name: DeleteRanklistEntriesByRanklistId :exec
+DELETE FROM ranklist_entries
+WHERE ranklist_id = ?;
+
+-- name: GetRanklistEntries :many
+SELECT sqlc.embed(ranklist_entries),
+    albums.title as album_title,
+    albums.image_url,
+    COALESCE((
+        SELECT GROUP_CONCAT(a.name, ', ')
+        FROM (SELECT DISTINCT ar.id, ar.name FROM album_artists aa JOIN artists ar ON ar.id = aa.artist_id WHERE aa.album_id = albums.id) AS a
+    ), '') as artist_names,
+    album_rating_log.rating
+FROM ranklist_entries
+JOIN ranklists ON ranklists.id = ranklist_entries.ranklist_id
+JOIN albums ON albums.id = ranklist_entries.album_id
+LEFT JOIN album_rating_log ON album_rating_log.id = (
+    SELECT arl.id FROM album_rating_log arl
+    WHERE arl.user_id = ranklists.user_id AND arl.album_id = ranklist_entries.album_id
+    ORDER BY arl.created_at DESC, arl.rowid DESC
+    LIMIT 1
+)
+WHERE ranklist_entries.ranklist_id = ?
+ORDER BY ranklist_entries.position, ranklist_entries.id;
+
+-- name: GetRanklistEntryPositions :many
+SELECT id, album_id, position FROM ranklist_entries
+WHERE ranklist_id = ?
+ORDER BY position, id;
+
+-- name: InsertRanklistEntry :exec
+INSERT INTO ranklist_entries (id, ranklist_id, album_id, position)
+VALUES (?, ?, ?, ?);
+
+-- name: UpdateRanklistEntryPosition :execrows
+UPDATE ranklist_entries
+SET position = ?
+WHERE id = ? AND ranklist_id = ?;
+
+-- name: UpdateRanklistEntryBlurb :execrows
+UPDATE ranklist_entries
+SET blurb = ?
+WHERE id = ? AND ranklist_id = ?;
+
+-- name: DeleteRanklistEntry :execrows
+DELETE FROM ranklist_entries
+WHERE id = ? AND ranklist_id = ?;
+
+-- name: GetRanklistTagMatches :many
+SELECT albums.id FROM albums
+LEFT JOIN album_rating_log ON album_rating_log.id = (
+    SELECT arl.id FROM album_rating_log arl
+    WHERE arl.user_id = ? AND arl.album_id = albums.id
+    ORDER BY arl.created_at DESC, arl.rowid DESC
+    LIMIT 1
+)
+WHERE EXISTS (
+      SELECT 1 FROM album_tags
+      WHERE album_tags.album_id = albums.id AND album_tags.user_id = ? AND album_tags.tag_id IN (sqlc.slice('tag_ids'))
+  )
+  AND EXISTS (
+      SELECT 1 FROM user_releases
+      JOIN releases ON releases.id = user_releases.release_id
+      WHERE releases.album_id = albums.id AND user_releases.user_id = ?
+  )
+ORDER BY album_rating_log.rating DESC NULLS LAST, albums.title;
+
+-- name: GetRanklistRatingMatches :many
+SELECT album_rating_log.album_id FROM album_rating_log
+JOIN albums ON albums.id = album_rating_log.album_id
+WHERE album_rating_log.user_id = ?
+  AND album_rating_log.id = (
+      SELECT arl.id FROM album_rating_log arl
+      WHERE arl.user_id = album_rating_log.user_id AND arl.album_id = album_rating_log.album_id
+      ORDER BY arl.created_at DESC, arl.rowid DESC
+      LIMIT 1
+  )
+  AND album_rating_log.rating >= ?
+  AND album_rating_log.rating <= ?
+  AND EXISTS (
+      SELECT 1 FROM user_releases
+      JOIN releases ON releases.id = user_releases.release_id
+      WHERE releases.album_id = album_rating_log.album_id AND user_releases.user_id = album_rating_log.user_id
+  )
+ORDER BY album_rating_log.rating DESC, albums.title;
+
+-- name: GetRanklistCandidateAlbums :many
+SELECT DISTINCT albums.id, albums.title,
+    COALESCE((
+        SELECT GROUP_CONCAT(a.name, ', ')
+        FROM (SELECT DISTINCT ar.id, ar.name FROM album_artists aa JOIN artists ar ON ar.id = aa.artist_id WHERE aa.album_id = albums.id) AS a
+    ), '') as artist_names
+FROM user_releases
+JOIN releases ON releases.id = user_releases.release_id
+JOIN albums ON albums.id = releases.album_id
+WHERE user_releases.user_id = ?
+  AND albums.id NOT IN (SELECT album_id FROM ranklist_entries WHERE ranklist_id = ?)
+ORDER BY albums.title;
+
+-- name: UpdateRanklistsTagId :exec
+UPDATE ranklists SET tag_id = sqlc.arg(to_tag_id), updated_at = current_timestamp
+WHERE user_id = sqlc.arg(user_id) AND tag_id = sqlc.arg(from_tag_id);
@@ -0,0 +1,14 @@
+-- name: GetRatingProfile :one
+SELECT * FROM rating_profiles
+WHERE user_id = ?;
+
+-- name: UpsertRatingProfile :exec
+INSERT INTO rating_profiles (user_id, profile, updated_at)
+VALUES (?, ?, current_timestamp)
+ON CONFLICT (user_id) DO UPDATE SET
+    profile = excluded.profile,
+    updated_at = excluded.updated_at;
+
+-- name: DeleteRatingProfile :exec
+DELETE FROM rating_profiles
+WHERE user_id = ?;
@@ -0,0 +1,71 @@
+-- name: GetRevisitRules :one
+SELECT * FROM revisit_rules
+WHERE user_id = ?;
+
+-- name: UpsertRevisitRules :exec
+INSERT INTO revisit_rules (user_id, rules, updated_at)
+VALUES (?, ?, current_timestamp)
+ON CONFLICT (user_id) DO UPDATE SET
+    rules = excluded.rules,
+    updated_at = excluded.updated_at;
+
+-- name: GetUsersWithRatings :many
+SELECT DISTINCT user_id FROM album_rating_log;
+
+-- name: GetRevisitCandidates :many
+SELECT latest.album_id, latest.id AS rating_log_id, latest.created_at AS rated_at,
+    (
+        SELECT COUNT(*) FROM track_plays tp
+        WHERE tp.user_id = latest.user_id AND tp.album_id = latest.album_id
+          AND datetime(tp.played_at) > datetime(latest.created_at)
+    ) AS plays_since,
+    (
+        SELECT COUNT(DISTINCT date(tp.played_at)) FROM track_plays tp
+        WHERE tp.user_id = latest.user_id AND tp.album_id = latest.album_id
+          AND datetime(tp.played_at) <= datetime(latest.created_at)
+    ) AS listens_before
+FROM album_rating_log latest
+WHERE latest.user_id = ?
+  AND latest.id = (
+      SELECT arl.id FROM album_rating_log arl
+      WHERE arl.user_id = latest.user_id AND arl.album_id = latest.album_id
+      ORDER BY arl.created_at DESC, arl.rowid DESC
+      LIMIT 1
+  )
+  AND EXISTS (
+      SELECT 1 FROM user_releases
+      JOIN releases ON releases.id = user_releases.release_id
+      WHERE releases.album_id = latest.album_id AND user_releases.user_id = latest.user_id
+  );
+
+-- name: InsertRevisitSuggestion :exec
+INSERT INTO revisit_suggestions (user_id, album_id, rating_log_id, reason, plays_since, created_at)
+VALUES (?, ?, ?, ?, ?, current_timestamp);
+
+-- name: DeleteRevisitSuggestionsByUserId :exec
+DELETE FROM revisit_suggestions
+WHERE user_id = ?;
+
+-- name: DeleteRevisitSuggestion :exec
+DELETE FROM revisit_suggestions
+WHERE user_id = ? AND album_id = ?;
+
+-- name: GetRevisitAlbums :many
+SELECT albums.*,
+    COALESCE((
+        SELECT GROUP_CONCAT(a.name, ', ')
+        FROM (SELECT DISTINCT ar.id, ar.name FROM album_artists aa JOIN artists ar ON ar.id = aa.artist_id WHERE aa.album_id = albums.id) AS a
+    ), '') as artist_names,
+    revisit_suggestions.reason,
+    revisit_suggestions.plays_since,
+    album_rating_log.created_at AS rated_at
+FROM revisit_suggestions
+JOIN albums ON albums.id = revisit_suggestions.album_id
+JOIN album_rating_log ON album_rating_log.id = revisit_suggestions.rating_log_id
+WHERE revisit_suggestions.user_id = ?
+ORDER BY revisit_suggestions.plays_since DESC, album_rating_log.created_at
+LIMIT 20;
+
+-- name: DeleteRevisitSuggestionByRatingLogId :exec
+DELETE FROM revisit_suggestions
+WHERE rating_log_id = ? AND user_id = ?;
@@ -0,0 +1,39 @@
+-- name: CreateShelf :one
+INSERT INTO shelves (id, user_id, name, description, filter) VALUES (?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetShelf :one
+SELECT * FROM shelves WHERE id = ? AND user_id = ?;
+
+-- name: GetShelfByName :one
+SELECT * FROM shelves WHERE user_id = ? AND name = ?;
+
+-- name: GetShelvesByUserId :many
+SELECT * FROM shelves WHERE user_id = ? ORDER BY name COLLATE NOCASE;
+
+-- name: UpdateShelf :exec
+UPDATE shelves SET name = ?, description = ?, updated_at = current_timestamp
+WHERE id = ? AND user_id = ?;
+
+-- name: UpdateShelfFilter :exec
+UPDATE shelves SET filter = ?, updated_at = current_timestamp
+WHERE id = ? AND user_id = ?;
+
+-- name: DeleteShelf :exec
+DELETE FROM shelves WHERE id = ? AND user_id = ?;
+
+-- name: DeleteShelfAlbumsByShelfId :exec
+DELETE FROM shelf_albums WHERE shelf_id = ?;
+
+-- name: AddShelfAlbum :exec
+INSERT INTO shelf_albums (shelf_id, album_id) VALUES (?, ?)
+ON CONFLICT (shelf_id, album_id) DO NOTHING;
+
+-- name: DeleteShelfAlbum :exec
+DELETE FROM shelf_albums WHERE shelf_id = ? AND album_id = ?;
+
+-- name: GetShelfAlbumsByAlbumIds :many
+SELECT shelf_albums.shelf_id, shelf_albums.album_id
+FROM shelf_albums
+JOIN shelves ON shelf_albums.shelf_id = shelves.id
+WHERE shelves.user_id = ? AND shelf_albums.album_id IN (sqlc.slice('album_ids'));
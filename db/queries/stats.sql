@@ -0,0 +1,47 @@
+-- name: GetStatsVersion :one
+SELECT
+    (SELECT COUNT(*) || '/' || COALESCE(MAX(user_releases.added_at), '') FROM user_releases WHERE user_releases.user_id = ?) AS releases,
+    (SELECT COUNT(*) || '/' || COALESCE(MAX(album_rating_log.created_at), '') FROM album_rating_log WHERE album_rating_log.user_id = ?) AS ratings,
+    (SELECT COUNT(*) || '/' || COALESCE(MAX(album_tags.created_at), '') FROM album_tags WHERE album_tags.user_id = ?) AS tags,
+    (SELECT COUNT(*) || '/' || COALESCE(MAX(track_plays.played_at), '') FROM track_plays WHERE track_plays.user_id = ?) AS plays;
+
+-- name: GetStatsReleases :many
+SELECT releases.album_id, releases.format, user_releases.added_at FROM user_releases
+JOIN releases ON user_releases.release_id = releases.id
+WHERE user_releases.user_id = ?;
+
+-- name: GetStatsAlbumTags :many
+SELECT album_tags.album_id, tags.id AS tag_id, tags.name AS tag_name FROM album_tags
+JOIN tags ON album_tags.tag_id = tags.id
+WHERE album_tags.user_id = ?;
+
+-- name: GetStatsAlbumArtists :many
+SELECT DISTINCT album_artists.album_id, artists.id AS artist_id, artists.name AS artist_name FROM album_artists
+JOIN artists ON album_artists.artist_id = artists.id
+JOIN releases ON releases.album_id = album_artists.album_id
+JOIN user_releases ON user_releases.release_id = releases.id
+WHERE user_releases.user_id = ? AND album_artists.role = 'primary';
+
+-- name: GetStatsArtistPlays :many
+SELECT artists.id AS artist_id, artists.name AS artist_name, COUNT(track_plays.id) AS plays FROM track_plays
+JOIN album_artists ON album_artists.album_id = track_plays.album_id AND album_artists.role = 'primary'
+JOIN artists ON album_artists.artist_id = artists.id
+WHERE track_plays.user_id = sqlc.arg(user_id)
+AND datetime(track_plays.played_at) >= datetime(sqlc.arg(played_from))
+AND datetime(track_plays.played_at) < datetime(sqlc.arg(played_to))
+GROUP BY artists.id
+ORDER BY plays DESC, artists.name
+LIMIT sqlc.arg(max_artists);
+
+-- name: GetStatsPlayTimes :many
+SELECT played_at FROM track_plays WHERE user_id = ?;
+
+-- name: GetStatsAlbumPlayTimes :many
+SELECT played_at FROM track_plays WHERE user_id = ? AND album_id = ?;
+
+-- name: GetStatsTagPlayTimes :many
+SELECT track_plays.played_at FROM track_plays
+WHERE track_plays.user_id = ? AND track_plays.album_id IN (
+    SELECT album_tags.album_id FROM album_tags
+    WHERE album_tags.user_id = ? AND album_tags.tag_id IN (sqlc.slice('tag_ids'))
+);
@@ -1,10 +1,45 @@
 -- name: GetOrCreateTagGroup :one
-INSERT INTO tag_groups (id, user_id, name) VALUES (?, ?, ?)
+INSERT INTO tag_groups (id, user_id, name, position) VALUES (?, ?, ?, ?)
 ON CONFLICT (user_id, name) DO UPDATE SET name = name
 RETURNING *;
 
 -- name: GetTagGroupsByUserId :many
-SELECT * FROM tag_groups WHERE user_id = ?;
+SELECT * FROM tag_groups WHERE user_id = ? ORDER BY position, name;
+
+-- name: GetTagGroup :one
+SELECT * FROM tag_groups WHERE id = ? AND user_id = ?;
+
+-- name: GetTagGroupByName :one
+SELECT * FROM tag_groups WHERE user_id = ? AND name = ? COLLATE NOCASE;
+
+-- name: CreateTagGroup :one
+INSERT INTO tag_groups (id, user_id, name, position, color, exclusive)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: UpdateTagGroup :one
+UPDATE tag_groups SET name = ?, color = ?, exclusive = ?
+WHERE id = ? AND user_id = ?
+RETURNING *;
+
+-- name: SetTagGroupPosition :exec
+UPDATE tag_groups SET position = ? WHERE id = ? AND user_id = ?;
+
+-- name: DeleteTagGroup :exec
+DELETE FROM tag_groups WHERE id = ? AND user_id = ?;
+
+-- name: UngroupTagsByGroupId :exec
+UPDATE tags SET group_id = NULL WHERE user_id = ? AND group_id = ?;
+
+-- name: CountAlbumsWithManyTagsInGroup :one
+SELECT COUNT(*) FROM (
+    SELECT album_tags.album_id
+    FROM album_tags
+    JOIN tags ON album_tags.tag_id = tags.id
+    WHERE album_tags.user_id = ? AND tags.group_id = ?
+    GROUP BY album_tags.album_id
+    HAVING COUNT(*) > 1
+);
 
 -- name: GetOrCreateTag :one
 INSERT INTO tags (id, user_id, name, group_id) VALUES (?, ?, ?, ?)
@@ -14,7 +49,10 @@ RETURNING *;
 -- name: GetTagsByUserId :many
 SELECT sqlc.embed(tags),
     COALESCE(tag_groups.id, '') as group_id_value,
-    COALESCE(tag_groups.name, '') as group_name
+    COALESCE(tag_groups.name, '') as group_name,
+    COALESCE(tag_groups.color, 'none') as group_color,
+    COALESCE(tag_groups.position, 0) as group_position,
+    COALESCE(tag_groups.exclusive, false) as group_exclusive
 FROM tags
 LEFT JOIN tag_groups ON tags.group_id = tag_groups.id
 WHERE tags.user_id = ?
@@ -23,22 +61,28 @@ ORDER BY tags.name;
 -- name: GetAlbumTagsByAlbumId :many
 SELECT album_tags.album_id, sqlc.embed(tags),
     COALESCE(tag_groups.id, '') as group_id_value,
-    COALESCE(tag_groups.name, '') as group_name
+    COALESCE(tag_groups.name, '') as group_name,
+    COALESCE(tag_groups.color, 'none') as group_color,
+    COALESCE(tag_groups.position, 0) as group_position,
+    COALESCE(tag_groups.exclusive, false) as group_exclusive
 FROM album_tags
 JOIN tags ON album_tags.tag_id = tags.id
 LEFT JOIN tag_groups ON tags.group_id = tag_groups.id
 WHERE album_tags.user_id = ? AND album_tags.album_id = ?
-ORDER BY tags.name;
+ORDER BY tag_groups.id IS NULL, tag_groups.position, tags.name;
 
 -- name: GetAlbumTagsByAlbumIds :many
 SELECT album_tags.album_id, sqlc.embed(tags),
     COALESCE(tag_groups.id, '') as group_id_value,
-    COALESCE(tag_groups.name, '') as group_name
+    COALESCE(tag_groups.name, '') as group_name,
+    COALESCE(tag_groups.color, 'none') as group_color,
+    COALESCE(tag_groups.position, 0) as group_position,
+    COALESCE(tag_groups.exclusive, false) as group_exclusive
 FROM album_tags
 JOIN tags ON album_tags.tag_id = tags.id
 LEFT JOIN tag_groups ON tags.group_id = tag_groups.id
 WHERE album_tags.user_id = ? AND album_tags.album_id IN (sqlc.slice('album_ids'))
-ORDER BY tags.name;
+ORDER BY tag_groups.id IS NULL, tag_groups.position, tags.name;
 
 -- name: CreateAlbumTag :one
 INSERT INTO album_tags (id, user_id, album_id, tag_id) VALUES (?, ?, ?, ?)
@@ -50,3 +94,94 @@ DELETE FROM album_tags WHERE user_id = ? AND album_id = ? AND tag_id = ?;
 
 -- name: DeleteAlbumTagsByAlbumId :exec
 DELETE FROM album_tags WHERE user_id = ? AND album_id = ?;
+
+-- name: GetTag :one
+SELECT * FROM tags WHERE id = ? AND user_id = ?;
+
+-- name: GetTagByName :one
+SELECT * FROM tags WHERE user_id = ? AND name = ?;
+
+-- name: GetTagUsageByUserId :many
+WITH RECURSIVE lineage(ancestor_id, tag_id) AS (
+    SELECT tags.id, tags.id FROM tags WHERE tags.user_id = ?
+    UNION
+    SELECT lineage.ancestor_id, tags.id FROM lineage JOIN tags ON tags.parent_id = lineage.tag_id
+)
+SELECT sqlc.embed(tags),
+    COALESCE(tag_groups.id, '') as group_id_value,
+    COALESCE(tag_groups.name, '') as group_name,
+    COALESCE(tag_groups.color, 'none') as group_color,
+    COALESCE(tag_groups.position, 0) as group_position,
+    COALESCE(tag_groups.exclusive, false) as group_exclusive,
+    (SELECT COUNT(*) FROM album_tags WHERE album_tags.user_id = tags.user_id AND album_tags.tag_id = tags.id) as album_count,
+    (SELECT COUNT(DISTINCT album_tags.album_id) FROM lineage
+        JOIN album_tags ON album_tags.tag_id = lineage.tag_id
+        WHERE lineage.ancestor_id = tags.id AND album_tags.user_id = tags.user_id) as total_album_count
+FROM tags
+LEFT JOIN tag_groups ON tags.group_id = tag_groups.id
+WHERE tags.user_id = ?
+ORDER BY tags.name;
+
+-- name: UpdateTag :one
+UPDATE tags SET name = ?, group_id = ?, color = ?, parent_id = ?
+WHERE id = ? AND user_id = ?
+RETURNING *;
+
+-- name: GetSubtagIds :many
+WITH RECURSIVE subtags(id) AS (
+    SELECT tags.id FROM tags WHERE tags.id = ? AND tags.user_id = ?
+    UNION
+    SELECT tags.id FROM tags JOIN subtags ON tags.parent_id = subtags.id
+)
+SELECT id FROM subtags;
+
+-- name: ReparentTags :exec
+UPDATE tags SET parent_id = sqlc.narg(to_parent_id)
+WHERE user_id = sqlc.arg(user_id) AND parent_id = sqlc.arg(from_parent_id);
+
+-- name: DeleteTag :exec
+DELETE FROM tags WHERE id = ? AND user_id = ?;
+
+-- name: GetAlbumIdsByTagId :many
+SELECT album_id FROM album_tags WHERE user_id = ? AND tag_id = ?;
+
+-- name: DeleteAlbumTagsByTagId :exec
+DELETE FROM album_tags WHERE user_id = ? AND tag_id = ?;
+
+-- name: GetArtistTagCounts :many
+SELECT album_tags.tag_id, COUNT(DISTINCT album_tags.album_id) AS album_count
+FROM album_tags
+JOIN album_artists ON album_artists.album_id = album_tags.album_id
+WHERE album_tags.user_id = ? AND album_tags.album_id != ?
+  AND album_artists.artist_id IN (SELECT album_artists.artist_id FROM album_artists WHERE album_artists.album_id = ?)
+GROUP BY album_tags.tag_id;
+
+-- name: GetCooccurringTagCounts :many
+SELECT other.tag_id, COUNT(DISTINCT other.album_id) AS album_count
+FROM album_tags AS shared
+JOIN album_tags AS other ON other.user_id = shared.user_id AND other.album_id = shared.album_id AND other.tag_id != shared.tag_id
+WHERE shared.user_id = ? AND shared.album_id != ?
+  AND shared.tag_id IN (SELECT album_tags.tag_id FROM album_tags WHERE album_tags.user_id = ? AND album_tags.album_id = ?)
+GROUP BY other.tag_id;
+
+-- name: GetAlbumTagsByTagIdAndAlbumIds :many
+SELECT * FROM album_tags
+WHERE user_id = ? AND tag_id = ? AND album_id IN (sqlc.slice('album_ids'));
+
+-- name: GetAlbumTagsInGroupByAlbumIds :many
+SELECT album_tags.* FROM album_tags
+JOIN tags ON tags.id = album_tags.tag_id
+WHERE album_tags.user_id = ? AND tags.group_id = ? AND album_tags.album_id IN (sqlc.slice('album_ids'));
+
+-- name: RestoreAlbumTag :exec
+INSERT INTO album_tags (id, user_id, album_id, tag_id, created_at) VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (user_id, album_id, tag_id) DO NOTHING;
+
+-- name: DeleteAlbumTagById :exec
+DELETE FROM album_tags WHERE id = ? AND user_id = ?;
+
+-- name: GetLibraryTagIds :many
+SELECT DISTINCT album_tags.tag_id FROM album_tags
+JOIN releases ON releases.album_id = album_tags.album_id
+JOIN user_releases ON user_releases.release_id = releases.id AND user_releases.user_id = album_tags.user_id
+WHERE album_tags.user_id = ?;
@@ -0,0 +1,45 @@
+-- name: UpsertTrackMark :exec
+INSERT INTO track_marks (user_id, track_id, mark, created_at, updated_at)
+VALUES (?, ?, ?, current_timestamp, current_timestamp)
+ON CONFLICT (user_id, track_id) DO UPDATE SET
+    mark = excluded.mark,
+    updated_at = excluded.updated_at;
+
+-- name: DeleteTrackMark :exec
+DELETE FROM track_marks
+WHERE user_id = ? AND track_id = ?;
+
+-- name: GetTrackMarksByAlbumId :many
+SELECT track_marks.* FROM track_marks
+JOIN album_tracks ON album_tracks.track_id = track_marks.track_id
+WHERE track_marks.user_id = ? AND album_tracks.album_id = ?;
+
+-- name: GetTopTracks :many
+SELECT tracks.id, tracks.title, albums.id AS album_id, albums.title AS album_title, albums.image_url,
+    COALESCE((
+        SELECT GROUP_CONCAT(a.name, ', ')
+        FROM (SELECT DISTINCT ar.id, ar.name FROM album_artists aa JOIN artists ar ON ar.id = aa.artist_id WHERE aa.album_id = albums.id) AS a
+    ), '') as artist_names,
+    album_rating_log.rating,
+    (
+        SELECT COUNT(*) FROM track_plays
+        WHERE track_plays.track_id = tracks.id AND track_plays.user_id = track_marks.user_id
+    ) AS play_count
+FROM track_marks
+JOIN tracks ON tracks.id = track_marks.track_id
+JOIN album_tracks ON album_tracks.track_id = tracks.id
+JOIN albums ON albums.id = album_tracks.album_id
+LEFT JOIN album_rating_log ON album_rating_log.id = (
+    SELECT arl.id FROM album_rating_log arl
+    WHERE arl.user_id = track_marks.user_id AND arl.album_id = albums.id
+    ORDER BY arl.created_at DESC, arl.rowid DESC
+    LIMIT 1
+)
+WHERE track_marks.user_id = ? AND track_marks.mark = 'standout'
+  AND EXISTS (
+      SELECT 1 FROM user_releases
+      JOIN releases ON releases.id = user_releases.release_id
+      WHERE releases.album_id = albums.id AND user_releases.user_id = track_marks.user_id
+  )
+ORDER BY album_rating_log.rating IS NULL, album_rating_log.rating DESC, play_count DESC, tracks.title
+LIMIT 100;
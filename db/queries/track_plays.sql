@@ -19,7 +19,12 @@ SELECT albums.*, MAX(track_plays.played_at) as last_played_at,
         SELECT 1 FROM user_releases
         JOIN releases ON releases.id = user_releases.release_id
         WHERE releases.album_id = albums.id AND user_releases.user_id = track_plays.user_id
-    ) as in_library
+    ) as in_library,
+    EXISTS (
+        SELECT 1 FROM wishlist_items
+        WHERE wishlist_items.album_id = albums.id AND wishlist_items.user_id = track_plays.user_id
+            AND wishlist_items.fulfilled_at IS NULL
+    ) as wishlisted
 FROM track_plays
 JOIN albums ON albums.id = track_plays.album_id
 WHERE track_plays.user_id = ?
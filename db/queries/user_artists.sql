@@ -8,3 +8,9 @@ RETURNING *;
 SELECT sqlc.embed(user_artists), sqlc.embed(artists) FROM user_artists
 JOIN artists ON user_artists.artist_id = artists.id
 WHERE user_id = ?;
+
+-- name: MoveUserArtists :exec
+UPDATE OR IGNORE user_artists SET artist_id = ? WHERE artist_id = ?;
+
+-- name: DeleteUserArtistsByArtistId :exec
+DELETE FROM user_artists WHERE artist_id = ?;
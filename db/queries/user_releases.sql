@@ -14,3 +14,37 @@ SELECT sqlc.embed(user_releases), sqlc.embed(releases) FROM user_releases
 JOIN releases ON user_releases.release_id = releases.id
 WHERE user_id = ?
 AND album_id = ?;
+
+-- name: GetLibraryAlbumIds :many
+SELECT DISTINCT releases.album_id FROM user_releases
+JOIN releases ON user_releases.release_id = releases.id
+WHERE user_releases.user_id = ? AND releases.album_id IN (sqlc.slice('album_ids'));
+
+-- name: GetAlbumIdsWithFormat :many
+SELECT DISTINCT releases.album_id FROM user_releases
+JOIN releases ON user_releases.release_id = releases.id
+WHERE user_releases.user_id = ? AND releases.format = ? AND releases.album_id IN (sqlc.slice('album_ids'));
+
+-- name: DeleteUserRelease :exec
+DELETE FROM user_releases WHERE id = ? AND user_id = ?;
+
+-- name: GetUserReleasesByAlbumIds :many
+SELECT sqlc.embed(user_releases), sqlc.embed(releases) FROM user_releases
+JOIN releases ON user_releases.release_id = releases.id
+WHERE user_id = ? AND releases.album_id IN (sqlc.slice('album_ids'));
+
+-- name: GetLibraryStats :one
+WITH library AS (
+    SELECT DISTINCT releases.album_id FROM user_releases
+    JOIN releases ON user_releases.release_id = releases.id
+    JOIN albums ON releases.album_id = albums.id
+    WHERE user_releases.user_id = ?
+)
+SELECT
+    (SELECT COUNT(*) FROM library) AS album_count,
+    (SELECT COUNT(DISTINCT artists.id) FROM album_artists
+        JOIN artists ON album_artists.artist_id = artists.id
+        JOIN library ON album_artists.album_id = library.album_id) AS artist_count,
+    (SELECT COUNT(DISTINCT tracks.id) FROM album_tracks
+        JOIN tracks ON album_tracks.track_id = tracks.id
+        JOIN library ON album_tracks.album_id = library.album_id) AS track_count;
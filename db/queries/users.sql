@@ -17,3 +17,6 @@ SELECT * FROM users WHERE spotify_id = ?;
 -- name: GetUsersWithSpotifyToken :many
 SELECT * FROM users
 WHERE spotify_refresh_token IS NOT NULL AND deleted_at IS NULL;
+
+-- name: SetUserTimezone :exec
+UPDATE users SET timezone = ? WHERE id = ?;
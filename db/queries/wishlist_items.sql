@@ -0,0 +1,69 @@
+-- name: CreateWishlistItem :one
+INSERT INTO wishlist_items (
+    id, user_id, album_id, musicbrainz_id, title, artist_names, image_url,
+    format, priority, max_price, notes, heard_from
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetWishlistItem :one
+SELECT * FROM wishlist_items WHERE id = ? AND user_id = ?;
+
+-- name: GetWishlistItemByAlbumId :one
+SELECT * FROM wishlist_items WHERE user_id = ? AND album_id = ?
+LIMIT 1;
+
+-- name: GetWishlistItemByMusicBrainzId :one
+SELECT * FROM wishlist_items WHERE user_id = ? AND musicbrainz_id = ?
+LIMIT 1;
+
+-- name: GetWishlistItemsByUserId :many
+SELECT * FROM wishlist_items WHERE user_id = ?
+ORDER BY fulfilled_at IS NOT NULL, fulfilled_at DESC, priority, created_at DESC;
+
+-- name: UpdateWishlistItem :one
+UPDATE wishlist_items
+SET format = ?, priority = ?, max_price = ?, notes = ?, heard_from = ?, updated_at = current_timestamp
+WHERE id = ? AND user_id = ?
+RETURNING *;
+
+-- name: SetWishlistItemFulfilledAt :one
+UPDATE wishlist_items SET fulfilled_at = ?, updated_at = current_timestamp
+WHERE id = ? AND user_id = ?
+RETURNING *;
+
+-- name: DeleteWishlistItem :exec
+DELETE FROM wishlist_items WHERE id = ? AND user_id = ?;
+
+-- name: LinkWishlistItemsToLibrary :execrows
+UPDATE wishlist_items
+SET album_id = (
+    SELECT albums.id FROM albums
+    JOIN releases ON releases.album_id = albums.id
+    JOIN user_releases ON user_releases.release_id = releases.id
+    JOIN album_artists ON album_artists.album_id = albums.id
+    JOIN artists ON artists.id = album_artists.artist_id
+    WHERE user_releases.user_id = wishlist_items.user_id
+        AND lower(albums.title) = lower(wishlist_items.title)
+        AND contains_name(wishlist_items.artist_names, artists.name)
+    LIMIT 1
+), updated_at = current_timestamp
+WHERE user_id = ? AND album_id IS NULL AND fulfilled_at IS NULL AND EXISTS (
+    SELECT 1 FROM albums
+    JOIN releases ON releases.album_id = albums.id
+    JOIN user_releases ON user_releases.release_id = releases.id
+    JOIN album_artists ON album_artists.album_id = albums.id
+    JOIN artists ON artists.id = album_artists.artist_id
+    WHERE user_releases.user_id = wishlist_items.user_id
+        AND lower(albums.title) = lower(wishlist_items.title)
+        AND contains_name(wishlist_items.artist_names, artists.name)
+);
+
+-- name: FulfillWishlistItemsInLibrary :execrows
+UPDATE wishlist_items SET fulfilled_at = ?, updated_at = current_timestamp
+WHERE user_id = ? AND album_id IS NOT NULL AND fulfilled_at IS NULL AND EXISTS (
+    SELECT 1 FROM user_releases
+    JOIN releases ON releases.id = user_releases.release_id
+    WHERE user_releases.user_id = wishlist_items.user_id
+        AND releases.album_id = wishlist_items.album_id
+        AND (wishlist_items.format = 'any' OR releases.format = wishlist_items.format)
+);
@@ -10,14 +10,14 @@ CREATE TABLE users (
     spotify_id text not null unique,
     created_at datetime not null default current_timestamp,
     deleted_at datetime
-, spotify_refresh_token text);
+, spotify_refresh_token text, timezone text not null default 'UTC');
 CREATE TABLE artists (
     id text primary key,
     spotify_id text not null unique,
     name text not null,
     created_at datetime not null default current_timestamp,
     deleted_at datetime
-);
+, sort_name text);
 CREATE TABLE albums (
     id text primary key,
     spotify_id text not null unique,
@@ -73,7 +73,7 @@ CREATE TABLE feeds (
 );
 CREATE TABLE IF NOT EXISTS "album_artists" (
     album_id text not null references albums(id) on delete cascade,
-    artist_id text not null references artists(id) on delete cascade,
+    artist_id text not null references artists(id) on delete cascade, position integer not null default 0, role text not null default 'primary' check(role in ('primary', 'featured')),
     unique(album_id, artist_id)
 );
 CREATE TABLE IF NOT EXISTS "album_tracks" (
@@ -93,7 +93,7 @@ CREATE TABLE tag_groups (
     id         TEXT PRIMARY KEY,
     user_id    TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     name       TEXT NOT NULL,
-    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP, position integer not null default 0, color text not null default 'none' check(color in ('none', 'primary', 'secondary', 'accent', 'info', 'success', 'warning', 'error')), exclusive boolean not null default false,
     UNIQUE(user_id, name)
 );
 CREATE TABLE tags (
@@ -101,7 +101,7 @@ CREATE TABLE tags (
     user_id    TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     name       TEXT NOT NULL,
     group_id   TEXT REFERENCES tag_groups(id) ON DELETE SET NULL,
-    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP, color text not null default 'none' check(color in ('none', 'primary', 'secondary', 'accent', 'info', 'success', 'warning', 'error')), parent_id text references tags(id) on delete set null,
     UNIQUE(user_id, name)
 );
 CREATE TABLE album_tags (
@@ -120,3 +120,336 @@ CREATE TABLE album_rating_log (
     note       text,
     created_at datetime not null default current_timestamp
 );
+CREATE TABLE album_rating_scores (
+    rating_log_id text not null references album_rating_log(id) on delete cascade,
+    dimension     text not null,
+    score         float not null,
+    primary key (rating_log_id, dimension)
+);
+CREATE TABLE album_rating_answers (
+    rating_log_id text not null references album_rating_log(id) on delete cascade,
+    question_key  text not null,
+    value         integer not null,
+    primary key (rating_log_id, question_key)
+);
+CREATE TABLE album_comparisons (
+    id                text primary key,
+    user_id           text not null references users(id) on delete cascade,
+    album_id          text not null references albums(id) on delete cascade,
+    opponent_album_id text not null references albums(id) on delete cascade,
+    outcome           text not null,
+    created_at        datetime not null default current_timestamp
+);
+CREATE TABLE rating_profiles (
+    user_id    text primary key references users(id) on delete cascade,
+    profile    text not null,
+    updated_at datetime not null default current_timestamp
+);
+CREATE TABLE album_reviews (
+    id            text primary key,
+    user_id       text not null references users(id) on delete cascade,
+    album_id      text not null references albums(id) on delete cascade,
+    rating_log_id text references album_rating_log(id) on delete set null,
+    body          text not null default '',
+    status        text not null default 'draft',
+    published_at  datetime,
+    created_at    datetime not null default current_timestamp,
+    updated_at    datetime not null default current_timestamp,
+    unique(user_id, album_id)
+);
+CREATE TABLE album_review_revisions (
+    id         text primary key,
+    review_id  text not null references album_reviews(id) on delete cascade,
+    body       text not null,
+    status     text not null,
+    created_at datetime not null default current_timestamp,
+    updated_at datetime not null default current_timestamp
+);
+CREATE TABLE track_marks (
+    user_id    text not null references users(id) on delete cascade,
+    track_id   text not null references tracks(id) on delete cascade,
+    mark       text not null check(mark in ('standout', 'skip')),
+    created_at datetime not null default current_timestamp,
+    updated_at datetime not null default current_timestamp,
+    primary key (user_id, track_id)
+);
+CREATE TABLE revisit_rules (
+    user_id    text primary key references users(id) on delete cascade,
+    rules      text not null,
+    updated_at datetime not null default current_timestamp
+);
+CREATE TABLE revisit_suggestions (
+    user_id       text not null references users(id) on delete cascade,
+    album_id      text not null references albums(id) on delete cascade,
+    rating_log_id text not null references album_rating_log(id) on delete cascade,
+    reason        text not null check(reason in ('stale', 'single_listen')),
+    plays_since   integer not null default 0,
+    created_at    datetime not null default current_timestamp,
+    primary key(user_id, album_id)
+);
+CREATE TABLE ranklists (
+    id          text primary key,
+    user_id     text not null references users(id) on delete cascade,
+    name        text not null,
+    description text not null default '',
+    source      text not null default 'manual' check(source in ('manual', 'tag', 'rating')),
+    tag_id      text references tags(id) on delete set null,
+    min_rating  float,
+    max_rating  float,
+    created_at  datetime not null default current_timestamp,
+    updated_at  datetime not null default current_timestamp
+);
+CREATE TABLE ranklist_entries (
+    id          text primary key,
+    ranklist_id text not null references ranklists(id) on delete cascade,
+    album_id    text not null references albums(id) on delete cascade,
+    position    text not null,
+    blurb       text not null default '',
+    created_at  datetime not null default current_timestamp,
+    unique(ranklist_id, album_id)
+);
+CREATE TABLE shelves (
+    id          text primary key,
+    user_id     text not null references users(id) on delete cascade,
+    name        text not null,
+    description text not null default '',
+    filter      text,
+    created_at  datetime not null default current_timestamp,
+    updated_at  datetime not null default current_timestamp,
+    unique(user_id, name)
+);
+CREATE TABLE shelf_albums (
+    shelf_id    text not null references shelves(id) on delete cascade,
+    album_id    text not null references albums(id) on delete cascade,
+    created_at  datetime not null default current_timestamp,
+    primary key (shelf_id, album_id)
+);
+CREATE TABLE wishlist_items (
+    id             text primary key,
+    user_id        text not null references users(id) on delete cascade,
+    album_id       text references albums(id) on delete set null,
+    musicbrainz_id text,
+    title          text not null,
+    artist_names   text not null default '',
+    image_url      text,
+    format         text not null default 'any' check(format in ('any', 'vinyl', 'cd', 'cassette', 'digital')),
+    priority       integer not null default 2 check(priority between 1 and 3),
+    max_price      real,
+    notes          text not null default '',
+    heard_from     text not null default '',
+    fulfilled_at   datetime,
+    created_at     datetime not null default current_timestamp,
+    updated_at     datetime not null default current_timestamp
+);
+CREATE TABLE album_links (
+    id            text primary key,
+    user_id       text not null references users(id) on delete cascade,
+    from_album_id text not null references albums(id) on delete cascade,
+    to_album_id   text not null references albums(id) on delete cascade,
+    kind          text not null check(kind in ('influenced_by', 'sounds_like', 'sequel_to', 'same_session', 'introduced_me_to')),
+    note          text not null default '',
+    created_at    datetime not null default current_timestamp,
+    updated_at    datetime not null default current_timestamp,
+    unique(user_id, from_album_id, to_album_id, kind),
+    check(from_album_id != to_album_id)
+);
+CREATE TABLE people (
+    id         text primary key,
+    user_id    text not null references users(id) on delete cascade,
+    name       text not null,
+    notes      text not null default '',
+    created_at datetime not null default current_timestamp,
+    updated_at datetime not null default current_timestamp,
+    unique(user_id, name)
+);
+CREATE TABLE album_people (
+    id         text primary key,
+    user_id    text not null references users(id) on delete cascade,
+    album_id   text not null references albums(id) on delete cascade,
+    person_id  text not null references people(id) on delete cascade,
+    direction  text not null check(direction in ('introduced_by', 'shared_with')),
+    shared_at  datetime not null default current_timestamp,
+    created_at datetime not null default current_timestamp,
+    unique(user_id, album_id, person_id, direction)
+);
+CREATE TABLE bulk_edits (
+    id         text primary key,
+    user_id    text not null references users(id) on delete cascade,
+    operation  text not null check(operation in ('add_tag', 'remove_tag', 'add_format', 'clear_ratings', 'add_to_shelf')),
+    summary    text not null,
+    undo       text not null,
+    created_at datetime not null default current_timestamp,
+    undone_at  datetime
+);
+CREATE VIRTUAL TABLE library_search USING fts5(
+    user_id UNINDEXED,
+    album_id UNINDEXED,
+    title,
+    artist,
+    track,
+    tag,
+    note,
+    tokenize = "unicode61 remove_diacritics 2",
+    prefix = '2 3'
+);
+CREATE TABLE IF NOT EXISTS 'library_search_data'(id INTEGER PRIMARY KEY, block BLOB);
+CREATE TABLE IF NOT EXISTS 'library_search_idx'(segid, term, pgno, PRIMARY KEY(segid, term)) WITHOUT ROWID;
+CREATE TABLE IF NOT EXISTS 'library_search_content'(id INTEGER PRIMARY KEY, c0, c1, c2, c3, c4, c5, c6);
+CREATE TABLE IF NOT EXISTS 'library_search_docsize'(id INTEGER PRIMARY KEY, sz BLOB);
+CREATE TABLE IF NOT EXISTS 'library_search_config'(k PRIMARY KEY, v) WITHOUT ROWID;
+CREATE VIEW library_search_source AS
+SELECT
+    user_releases.user_id,
+    albums.id AS album_id,
+    albums.title,
+    COALESCE((
+        SELECT GROUP_CONCAT(artists.name, ' ') FROM album_artists
+        JOIN artists ON artists.id = album_artists.artist_id
+        WHERE album_artists.album_id = albums.id
+    ), '') AS artist,
+    COALESCE((
+        SELECT GROUP_CONCAT(tracks.title, ' ') FROM album_tracks
+        JOIN tracks ON tracks.id = album_tracks.track_id
+        WHERE album_tracks.album_id = albums.id
+    ), '') AS track,
+    COALESCE((
+        SELECT GROUP_CONCAT(COALESCE(tag_groups.name || ' ', '') || tags.name, ' ') FROM album_tags
+        JOIN tags ON tags.id = album_tags.tag_id
+        LEFT JOIN tag_groups ON tag_groups.id = tags.group_id
+        WHERE album_tags.user_id = user_releases.user_id AND album_tags.album_id = albums.id
+    ), '') AS tag,
+    COALESCE((
+        SELECT GROUP_CONCAT(album_rating_log.note, ' ') FROM album_rating_log
+        WHERE album_rating_log.user_id = user_releases.user_id AND album_rating_log.album_id = albums.id
+    ), '') AS note
+FROM user_releases
+JOIN releases ON releases.id = user_releases.release_id
+JOIN albums ON albums.id = releases.album_id
+GROUP BY user_releases.user_id, albums.id
+/* library_search_source(user_id,album_id,title,artist,track,tag,note) */;
+CREATE TRIGGER library_search_user_releases_insert AFTER INSERT ON user_releases BEGIN
+    DELETE FROM library_search WHERE user_id = NEW.user_id AND album_id = (SELECT album_id FROM releases WHERE id = NEW.release_id);
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE user_id = NEW.user_id AND album_id = (SELECT album_id FROM releases WHERE id = NEW.release_id);
+END;
+CREATE TRIGGER library_search_user_releases_delete AFTER DELETE ON user_releases BEGIN
+    DELETE FROM library_search WHERE user_id = OLD.user_id AND album_id = (SELECT album_id FROM releases WHERE id = OLD.release_id);
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE user_id = OLD.user_id AND album_id = (SELECT album_id FROM releases WHERE id = OLD.release_id);
+END;
+CREATE TRIGGER library_search_album_tags_insert AFTER INSERT ON album_tags BEGIN
+    DELETE FROM library_search WHERE user_id = NEW.user_id AND album_id = NEW.album_id;
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE user_id = NEW.user_id AND album_id = NEW.album_id;
+END;
+CREATE TRIGGER library_search_album_tags_delete AFTER DELETE ON album_tags BEGIN
+    DELETE FROM library_search WHERE user_id = OLD.user_id AND album_id = OLD.album_id;
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE user_id = OLD.user_id AND album_id = OLD.album_id;
+END;
+CREATE TRIGGER library_search_tags_update AFTER UPDATE OF name, group_id ON tags BEGIN
+    DELETE FROM library_search WHERE user_id = NEW.user_id AND album_id IN (SELECT album_id FROM album_tags WHERE tag_id = NEW.id);
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE user_id = NEW.user_id AND album_id IN (SELECT album_id FROM album_tags WHERE tag_id = NEW.id);
+END;
+CREATE TRIGGER library_search_tag_groups_update AFTER UPDATE OF name ON tag_groups BEGIN
+    DELETE FROM library_search WHERE user_id = NEW.user_id AND album_id IN (
+        SELECT album_tags.album_id FROM album_tags JOIN tags ON tags.id = album_tags.tag_id WHERE tags.group_id = NEW.id
+    );
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE user_id = NEW.user_id AND album_id IN (
+        SELECT album_tags.album_id FROM album_tags JOIN tags ON tags.id = album_tags.tag_id WHERE tags.group_id = NEW.id
+    );
+END;
+CREATE TRIGGER library_search_album_rating_log_insert AFTER INSERT ON album_rating_log BEGIN
+    DELETE FROM library_search WHERE user_id = NEW.user_id AND album_id = NEW.album_id;
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE user_id = NEW.user_id AND album_id = NEW.album_id;
+END;
+CREATE TRIGGER library_search_album_rating_log_update AFTER UPDATE OF note ON album_rating_log BEGIN
+    DELETE FROM library_search WHERE user_id = NEW.user_id AND album_id = NEW.album_id;
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE user_id = NEW.user_id AND album_id = NEW.album_id;
+END;
+CREATE TRIGGER library_search_album_rating_log_delete AFTER DELETE ON album_rating_log BEGIN
+    DELETE FROM library_search WHERE user_id = OLD.user_id AND album_id = OLD.album_id;
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE user_id = OLD.user_id AND album_id = OLD.album_id;
+END;
+CREATE TRIGGER library_search_albums_update AFTER UPDATE OF title ON albums BEGIN
+    DELETE FROM library_search WHERE album_id = NEW.id;
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE album_id = NEW.id;
+END;
+CREATE TRIGGER library_search_album_artists_insert AFTER INSERT ON album_artists BEGIN
+    DELETE FROM library_search WHERE album_id = NEW.album_id;
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE album_id = NEW.album_id;
+END;
+CREATE TRIGGER library_search_album_artists_delete AFTER DELETE ON album_artists BEGIN
+    DELETE FROM library_search WHERE album_id = OLD.album_id;
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE album_id = OLD.album_id;
+END;
+CREATE TRIGGER library_search_artists_update AFTER UPDATE OF name ON artists BEGIN
+    DELETE FROM library_search WHERE album_id IN (SELECT album_id FROM album_artists WHERE artist_id = NEW.id);
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE album_id IN (SELECT album_id FROM album_artists WHERE artist_id = NEW.id);
+END;
+CREATE TRIGGER library_search_album_tracks_insert AFTER INSERT ON album_tracks BEGIN
+    DELETE FROM library_search WHERE album_id = NEW.album_id;
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE album_id = NEW.album_id;
+END;
+CREATE TRIGGER library_search_tracks_update AFTER UPDATE OF title ON tracks BEGIN
+    DELETE FROM library_search WHERE album_id IN (SELECT album_id FROM album_tracks WHERE track_id = NEW.id);
+    INSERT INTO library_search (user_id, album_id, title, artist, track, tag, note)
+    SELECT user_id, album_id, title, artist, track, tag, note FROM library_search_source
+    WHERE album_id IN (SELECT album_id FROM album_tracks WHERE track_id = NEW.id);
+END;
+CREATE TRIGGER artists_sort_name_insert AFTER INSERT ON artists BEGIN
+    UPDATE artists SET sort_name = CASE
+        WHEN name LIKE 'The %' THEN substr(name, 5) || ', ' || substr(name, 1, 3)
+        WHEN name LIKE 'An %' THEN substr(name, 4) || ', ' || substr(name, 1, 2)
+        WHEN name LIKE 'A %' THEN substr(name, 3) || ', ' || substr(name, 1, 1)
+    END
+    WHERE id = NEW.id;
+END;
+CREATE TRIGGER artists_sort_name_update AFTER UPDATE OF name ON artists BEGIN
+    UPDATE artists SET sort_name = CASE
+        WHEN name LIKE 'The %' THEN substr(name, 5) || ', ' || substr(name, 1, 3)
+        WHEN name LIKE 'An %' THEN substr(name, 4) || ', ' || substr(name, 1, 2)
+        WHEN name LIKE 'A %' THEN substr(name, 3) || ', ' || substr(name, 1, 1)
+    END
+    WHERE id = NEW.id;
+END;
+CREATE TABLE artist_sort_names (
+    user_id    text not null references users(id) on delete cascade,
+    artist_id  text not null references artists(id) on delete cascade,
+    sort_name  text not null,
+    created_at datetime not null default current_timestamp,
+    unique(user_id, artist_id)
+);
+CREATE TABLE artist_aliases (
+    id          text primary key,
+    artist_id   text not null references artists(id) on delete cascade,
+    source      text not null check(source in ('spotify', 'musicbrainz', 'discogs', 'manual')),
+    external_id text,
+    name        text not null,
+    created_at  datetime not null default current_timestamp,
+    unique(source, external_id)
+);
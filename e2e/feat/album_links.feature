@@ -0,0 +1,27 @@
+Feature: Album links
+
+  Users connect albums with typed, directed links: one album was influenced
+  by another, sounds like it, is a sequel to it, came from the same session,
+  or introduced them to it. Each link can carry a note. Links are added and
+  removed from the album detail page, where they read from that album's
+  side, and the whole set is served as a graph of nodes and edges.
+
+  Scenario: Linking an album from its detail page
+    Given a logged-in user on an album detail page with another album in their library
+    When they open the link modal, pick a kind and an album, add a note and click Save
+    Then the link is listed on the album with its label, album and note
+
+  Scenario: Seeing a link from the other album
+    Given a logged-in user who linked one album to another
+    When they open the other album's detail page
+    Then the link is listed there, read from that album's side
+
+  Scenario: Removing a link
+    Given a logged-in user on an album detail page with a link
+    When they click the link's remove button
+    Then the link is no longer listed
+
+  Scenario: Fetching the link graph
+    Given a logged-in user who linked one album to another
+    When they request the link graph
+    Then it lists both albums as nodes and the link as an edge
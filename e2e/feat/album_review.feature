@@ -0,0 +1,30 @@
+Feature: Album Reviews
+
+  Users can write one long-form markdown review per album. Reviews start as
+  drafts that autosave while typing, can be published and unpublished, and
+  keep a revision history.
+
+  Scenario: Review editor opens from the album detail page
+    Given a logged-in user on an album detail page
+    When they click the review edit button
+    Then the review editor is shown
+
+  Scenario: Drafts autosave while typing
+    Given a logged-in user on the review editor
+    When they type into the review body and pause
+    Then the save status shows the draft was saved
+
+  Scenario: Preview renders markdown
+    Given a logged-in user on the review editor with markdown in the body
+    When they click the Preview tab
+    Then the rendered markdown is shown
+
+  Scenario: Publishing shows the review on the album detail page
+    Given a logged-in user on the review editor
+    When they write a review and click Publish
+    Then the album detail page shows the rendered review without a Draft badge
+
+  Scenario: Deleting a review clears it from the album detail page
+    Given a logged-in user with a review for an album
+    When they delete the review from the editor
+    Then the album detail page shows no review
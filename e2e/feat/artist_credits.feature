@@ -0,0 +1,16 @@
+Feature: Artist credits
+
+  Albums credit their artists in order, as primary or featured. Each artist
+  has a sort name, derived from their name ("Beatles, The") and overridable
+  per user, which the library's artist sort uses. Artists can carry aliases
+  from other sources, and a duplicate artist can be merged into another.
+
+  Scenario: Overriding an artist's sort name
+    Given a logged-in user on an artist's page
+    When they save a custom sort name
+    Then the sort name field shows it with an option to reset it
+
+  Scenario: Adding a manual alias
+    Given a logged-in user on an artist's page
+    When they add an alias that is only a name
+    Then the alias is listed under the artist's aliases
@@ -0,0 +1,16 @@
+Feature: Artist pages
+
+  Each artist has a page listing the user's albums by them with their
+  ratings, the average rating and how many plays the user has of the
+  artist. Below, the artist's discography on Spotify shows how far the user
+  has got with each album: rated, owned, heard or not heard.
+
+  Scenario: Opening an artist from an album
+    Given a logged-in user on an album's detail page
+    When they choose one of the album's artists
+    Then the artist's page opens listing the album among their library albums
+
+  Scenario: Seeing discography coverage
+    Given a logged-in user on an artist's page
+    When the discography loads
+    Then each album shows its coverage status, or a message if Spotify is unavailable
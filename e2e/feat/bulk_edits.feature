@@ -0,0 +1,19 @@
+Feature: Bulk edits
+
+  On the dashboard, Select turns on a mode where albums in the list can be
+  ticked and one operation applied to all of them at once: adding or
+  removing a tag, marking them as owned on a physical format, putting them
+  on a shelf, or clearing their ratings. Each edit is summed up with a way
+  to undo it.
+
+  Scenario: Putting selected albums on a shelf
+    Given a logged-in user on the dashboard with a hand-picked shelf
+    When they click Select, tick two albums, pick Add to shelf and the shelf, and click Apply
+    Then the edit is summed up as putting 2 albums on the shelf
+    And the shelf lists the two albums
+
+  Scenario: Undoing a bulk edit
+    Given a logged-in user who has just put two albums on a shelf in bulk
+    When they click Undo
+    Then the summary is marked as undone
+    And the shelf is empty again
@@ -0,0 +1,26 @@
+Feature: Rating Calibration
+
+  Users can see how their current ratings are distributed, where each album
+  sits in that distribution, and how their average has drifted year by year.
+  Scores can be re-mapped onto a target curve and the suggestions logged as
+  new ratings, keeping the old ones in the history.
+
+  Scenario: Calibration report opens from the user menu
+    Given a logged-in user with rated albums on the dashboard
+    When they open Calibration from the user menu
+    Then they see their rating distribution and drift by year
+
+  Scenario: Choosing a different target curve
+    Given a logged-in user on the calibration page
+    When they pick another target curve
+    Then the suggested scores are recalculated for that curve
+
+  Scenario: Invalid custom curve shows an error
+    Given a logged-in user on the calibration page
+    When they choose a custom curve with a spread of zero
+    Then an error explains the curve is invalid
+
+  Scenario: Applying a suggestion logs a new rating
+    Given a logged-in user on the calibration page with suggested scores
+    When they apply the suggestion for one album
+    Then a success message shows one recalibrated rating was logged
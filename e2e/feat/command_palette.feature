@@ -0,0 +1,17 @@
+Feature: Command palette
+
+  Ctrl+K (⌘K on a Mac) or the search button in the header opens a palette
+  that searches everything at once. Results are grouped: library albums
+  first, then artists and tags, then albums from Spotify's catalogue that
+  aren't in the library yet, each of which can be added from the palette.
+
+  Scenario: Opening the palette from the keyboard
+    Given a logged-in user on the dashboard
+    When they press Ctrl+K
+    Then the palette opens with the search box focused
+
+  Scenario: Finding a library album
+    Given a logged-in user with a tagged album
+    When they search the palette for the tag
+    Then the album is listed under the library and the tag under tags
+    And choosing the album opens its detail page
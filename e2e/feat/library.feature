@@ -1,8 +1,8 @@
 Feature: Library Dashboard
 
   The main hub of the app where users browse their music collection. Shows
-  library statistics, a carousel of recent or unrated albums, and a visual
-  list of all albums with chip-based sort and filter controls.
+  library statistics, a carousel of recent, unrated or due-a-revisit albums,
+  and a visual list of all albums with chip-based sort and filter controls.
 
   Scenario: Viewing the library dashboard shows list view
     Given a logged-in user with a library
@@ -29,6 +29,11 @@ Feature: Library Dashboard
     When they click the Unrated carousel tab
     Then the carousel reloads showing the Unrated view
 
+  Scenario: Switching the carousel to Revisit
+    Given a logged-in user on the dashboard
+    When they click the Revisit carousel tab
+    Then the carousel reloads showing the Revisit view
+
   Scenario: Sort chip is visible and shows default sort
     Given a logged-in user on the dashboard
     Then the sort chip is visible and shows "Date Added"
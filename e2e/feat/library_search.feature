@@ -0,0 +1,22 @@
+Feature: Library search
+
+  The search box above the dashboard's chip bar searches album titles,
+  artists, track titles, tags and rating notes as the user types. Words match
+  the start of words, accents are ignored, and "artist:", "track:" and "tag:"
+  limit a word to one field. Results are ranked by relevance and keep the
+  other filters.
+
+  Scenario: Searching as you type
+    Given a logged-in user with a tagged album
+    When they type the start of the tag into the search box
+    Then the album is listed, ranked by relevance
+
+  Scenario: Searching one field
+    Given a logged-in user with a tagged album
+    When they search for the tag as an artist
+    Then the album is not listed
+
+  Scenario: Clearing the search
+    Given a logged-in user searching the library
+    When they clear the search box
+    Then the whole library is listed again, newest first
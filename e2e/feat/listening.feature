@@ -0,0 +1,26 @@
+Feature: Listening heatmap and streaks
+
+  The Listening tab of the stats page shows when the user listens: a
+  heatmap of the last year's daily plays, current and longest streaks,
+  and plays by time of day and day of week, all counted in the user's
+  timezone and narrowable to a tag or an album.
+
+  Scenario: Opening the listening tab
+    Given a logged-in user on the stats page
+    When they open the Listening tab
+    Then they see a year of daily plays and their streaks
+
+  Scenario: Narrowing listening to a tag
+    Given a logged-in user on the listening tab with tags
+    When they pick a tag
+    Then the listening stats are narrowed to that tag and can be cleared
+
+  Scenario: Setting a timezone
+    Given a logged-in user on the listening tab
+    When they save a timezone
+    Then the tab counts days and hours in it
+
+  Scenario: Setting an unknown timezone
+    Given a logged-in user on the listening tab
+    When they save a timezone that doesn't exist
+    Then they see an error
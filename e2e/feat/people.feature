@@ -0,0 +1,32 @@
+Feature: People
+
+  Users keep track of the people albums pass between them and others: who
+  introduced them to an album, and who they shared it with, and when. People
+  are recorded from the album detail page by name, and the library can be
+  filtered to the albums a person introduced. The people page lists everyone
+  with how the albums they introduced have been rated.
+
+  Scenario: Recording who introduced an album
+    Given a logged-in user on an album detail page
+    When they open the people modal, pick "Introduced by", enter a name and click Save
+    Then the person is listed on the album with the "Introduced by" label
+
+  Scenario: Filtering the library to a person's recommendations
+    Given a logged-in user who recorded who introduced an album
+    When they click the person's name on the album detail page
+    Then the library shows the albums that person introduced
+
+  Scenario: Seeing recommendation stats
+    Given a logged-in user who recorded who introduced an album
+    When they open the people page
+    Then the person is listed with the number of albums they introduced
+
+  Scenario: Removing a person from an album
+    Given a logged-in user on an album detail page with a person recorded
+    When they click the person's remove button
+    Then the person is no longer listed on the album
+
+  Scenario: Forgetting a person
+    Given a logged-in user on the people page with a person
+    When they delete the person and confirm
+    Then the person is no longer listed
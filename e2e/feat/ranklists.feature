@@ -0,0 +1,36 @@
+Feature: Ranklists
+
+  Users keep named, ordered lists of albums. Albums are added by hand at any
+  position and reordered by dragging or typing a new rank, each with an
+  optional blurb. A list can instead follow a tag or a rating range, and its
+  albums then stay in sync with that filter.
+
+  Scenario: Creating a hand-picked list
+    Given a logged-in user on the ranklists page
+    When they enter a name and click Create list
+    Then they are taken to the new, empty list
+
+  Scenario: Adding albums at a position
+    Given a logged-in user on a hand-picked list with one album
+    When they add another album at position 1
+    Then the new album is ranked first
+
+  Scenario: Moving an album by typing its rank
+    Given a logged-in user on a hand-picked list with two albums
+    When they change the second album's rank to 1
+    Then the albums swap places
+
+  Scenario: Adding a blurb
+    Given a logged-in user on a hand-picked list with an album
+    When they write a blurb for it and click Save
+    Then the blurb is shown under the album
+
+  Scenario: A list built from a rating range
+    Given a logged-in user on the ranklists page
+    When they create a list of every album rated across their whole scale
+    Then the list holds their rated albums and has no add form
+
+  Scenario: Deleting a list
+    Given a logged-in user on a hand-picked list
+    When they click delete and confirm
+    Then they are returned to the ranklists page and the list is gone
@@ -0,0 +1,14 @@
+Feature: Revisit Rules
+
+  Users set the thresholds that decide which ratings are suggested for a
+  re-listen in the dashboard's Revisit carousel, on the rating scale page.
+
+  Scenario: Saving revisit rules
+    Given a logged-in user on the rating scale page
+    When they change the revisit thresholds and click Save
+    Then the revisit rules are saved
+
+  Scenario: Invalid revisit rules show an error
+    Given a logged-in user on the rating scale page
+    When they require zero plays since a stale rating and click Save
+    Then an error explains the rule is invalid
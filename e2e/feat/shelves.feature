@@ -0,0 +1,32 @@
+Feature: Shelves
+
+  Users group albums onto named, unordered shelves like "Sunday morning" or
+  "Lent to Sam", and an album can sit on any number of them. Albums are put
+  on shelves from their detail page, and the library can be filtered to a
+  single shelf. A smart shelf saves a set of library filters instead and holds
+  every album matching them.
+
+  Scenario: Creating a shelf
+    Given a logged-in user on the shelves page
+    When they enter a name and click Create shelf
+    Then they are taken to the new, empty shelf
+
+  Scenario: Adding an album to a shelf from its detail page
+    Given a logged-in user on an album detail page
+    When they open the shelves modal, name a new shelf and click Save
+    Then the shelf is listed on the album and the album is on the shelf
+
+  Scenario: Filtering the library to a shelf
+    Given a logged-in user with an album on a shelf
+    When they pick the shelf from the Shelf chip on the dashboard
+    Then only the albums on that shelf are listed
+
+  Scenario: Saving filters as a smart shelf
+    Given a logged-in user on the dashboard filtered to rated albums
+    When they click Save as shelf, enter a name and click Save
+    Then they are taken to a smart shelf of their rated albums
+
+  Scenario: Deleting a shelf
+    Given a logged-in user on a shelf
+    When they click delete and confirm
+    Then they are returned to the shelves page and the shelf is gone
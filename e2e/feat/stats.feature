@@ -0,0 +1,21 @@
+Feature: Stats & Insights
+
+  The stats page analyses the library over a date range: how it grew,
+  how much of it is rated and how the ratings spread, ratings by tag,
+  formats owned, and the top artists by plays and by average rating. The
+  same stats are available as JSON.
+
+  Scenario: Opening the stats page
+    Given a logged-in user on the dashboard
+    When they choose Stats from the user menu
+    Then the stats page shows their library's growth and albums added
+
+  Scenario: Narrowing stats to a range
+    Given a logged-in user on the stats page
+    When they pick the last 30 days
+    Then the stats update for that range
+
+  Scenario: Fetching stats as JSON
+    Given a logged-in user
+    When they request the stats JSON for a range
+    Then the response holds the range and the library's stats
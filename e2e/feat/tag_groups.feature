@@ -0,0 +1,27 @@
+Feature: Tag groups
+
+  Users start with the Sound and Mood tag groups and can add their own,
+  such as Era or Occasion, on the tags page. Each group has a place in the
+  order the tagging modal and album badges follow, a color its tags are
+  shown in unless they have their own, and a rule for whether an album can
+  carry more than one of its tags.
+
+  Scenario: Adding a tag group
+    Given a logged-in user on the tags page
+    When they fill in a group name, pick a color, tick "One tag per album" and click Add group
+    Then the group is listed last, marked as one tag per album
+
+  Scenario: Picking a second tag from an exclusive group
+    Given a logged-in user with an exclusive tag group
+    When they add two tags from the group to an album in the tagging modal
+    Then only the second tag is kept as a chip
+
+  Scenario: Reordering tag groups
+    Given a logged-in user on the tags page with several groups
+    When they move the last group up
+    Then it is listed one place higher
+
+  Scenario: Deleting a tag group
+    Given a logged-in user on the tags page with a group
+    When they delete the group and confirm
+    Then the group is no longer listed
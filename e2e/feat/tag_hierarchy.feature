@@ -0,0 +1,21 @@
+Feature: Tag hierarchy
+
+  Users can put a tag under another, such as shoegaze under rock, on the
+  tags page. Filtering by a tag takes in albums tagged with any of its
+  subtags, tags are shown by their path, and a tag's album count includes
+  its subtags' albums.
+
+  Scenario: Putting a tag under another
+    Given a logged-in user with two tags on the tags page
+    When they edit one tag and choose to put it under the other
+    Then the tag is shown with the other tag's name before it
+
+  Scenario: A tag can't go under its own subtag
+    Given a logged-in user with a tag under another
+    When they edit the top tag
+    Then its subtag isn't offered as a tag to put it under
+
+  Scenario: Seeing a subtag's path when tagging
+    Given a logged-in user with a tag under another
+    When they type the subtag's name in the tagging modal
+    Then the suggestion shows the subtag's full path
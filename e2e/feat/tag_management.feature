@@ -0,0 +1,30 @@
+Feature: Tag management
+
+  The tags page lists every tag a user has with the number of albums it's
+  on. Tags can be renamed, moved between groups, recolored, merged into
+  another tag and deleted there, without visiting the albums they're on.
+
+  Scenario: Seeing a tag's usage
+    Given a logged-in user who tagged an album
+    When they open the tags page
+    Then the tag is listed with the number of albums it's on
+
+  Scenario: Renaming and recoloring a tag
+    Given a logged-in user on the tags page with a tag
+    When they edit the tag's name, group and color and click Save
+    Then the tag is listed under its new name in its group
+
+  Scenario: Renaming a tag to a name another tag has
+    Given a logged-in user on the tags page with two tags
+    When they rename one tag to the other's name
+    Then an error says another tag already has that name
+
+  Scenario: Merging tags
+    Given a logged-in user on the tags page with two tags
+    When they merge one tag into the other and confirm
+    Then only the tag merged into is listed, on the album both were on
+
+  Scenario: Deleting a tag
+    Given a logged-in user on the tags page with a tag
+    When they delete the tag and confirm
+    Then the tag is no longer listed
@@ -0,0 +1,20 @@
+Feature: Tag query filter
+
+  The dashboard's Tags chip filters the library with a boolean query over
+  album tags, such as "mood:late-night AND NOT sound:ambient". The query is
+  evaluated in the database and travels with the other filters.
+
+  Scenario: Filtering the library by a tag
+    Given a logged-in user with a tagged album
+    When they open the Tags chip, enter the tag and apply
+    Then only albums with the tag are listed and the chip shows the query
+
+  Scenario: Excluding a tag
+    Given a logged-in user with a tagged album
+    When they filter by NOT the tag
+    Then the tagged album is not listed
+
+  Scenario: A query that can't be read
+    Given a logged-in user on the dashboard
+    When they apply a tag query with an unclosed parenthesis
+    Then the dialog stays open and explains the problem
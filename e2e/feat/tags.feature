@@ -18,3 +18,8 @@ Feature: Tagging
     Given a logged-in user with the tags modal open
     When they click Save Tags
     Then the modal closes
+
+  Scenario: Adding a suggested tag
+    Given a logged-in user with the tags modal open
+    When suggestions have loaded and they click one
+    Then the suggested tag appears as a chip and leaves the suggestions
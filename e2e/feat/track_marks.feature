@@ -0,0 +1,25 @@
+Feature: Track Marks
+
+  Users can mark tracks in an album's tracklist as standouts or skips. Marks
+  pre-fill the track consistency question of the rating questionnaire, and
+  standout tracks are collected on a top tracks page.
+
+  Scenario: Marking a track as a standout
+    Given a logged-in user on an album detail page
+    When they click the standout button on a track
+    Then the track is shown as a standout
+
+  Scenario: Clicking an active mark clears it
+    Given a logged-in user with a standout track
+    When they click the standout button on that track again
+    Then the track is no longer marked
+
+  Scenario: Track marks pre-fill the questionnaire
+    Given a logged-in user who has marked a track on an album
+    When they open the rating questionnaire
+    Then the track consistency question is pre-filled from their track marks
+
+  Scenario: Standout tracks appear on the top tracks page
+    Given a logged-in user with a standout track
+    When they open the top tracks page
+    Then the track is listed with a link to its album
@@ -0,0 +1,27 @@
+Feature: Wishlist
+
+  Users keep a wishlist of albums they want but don't own yet, noting the
+  format they're after, a priority, the most they'd pay and where they heard
+  about it. Albums are added from Recently Spun albums outside the library or
+  found by searching MusicBrainz. A Spotify sync that brings a wishlisted
+  album into the library in the wanted format marks it fulfilled.
+
+  Scenario: Wishlisting a recently spun album
+    Given a logged-in user with a recently spun album outside their library
+    When they click + Wishlist, fill in the details and click Add
+    Then the album shows as Wishlisted and is listed on the wishlist page
+
+  Scenario: Adding an album found on MusicBrainz
+    Given a logged-in user on the wishlist page
+    When they search MusicBrainz and add a result
+    Then the album is listed on their wishlist
+
+  Scenario: Editing a wishlist item
+    Given a logged-in user with an album on their wishlist
+    When they change its priority and click Save
+    Then the item shows the new priority
+
+  Scenario: Marking an item fulfilled by hand
+    Given a logged-in user with an album on their wishlist
+    When they click Got it
+    Then the item moves to the fulfilled list
@@ -0,0 +1,114 @@
+import { test, expect, Page } from '@playwright/test';
+import { loginAs } from '../helpers/auth';
+
+// Scenarios from e2e/feat/album_links.feature
+
+const userId = process.env.E2E_TEST_USER_ID;
+const albumId = process.env.E2E_TEST_ALBUM_ID;
+
+// linkAlbum links the E2E album to the first other album in the library, and
+// returns that album's ID, or null when the library has no other album.
+async function linkAlbum(page: Page, kind: string, note: string): Promise<string | null> {
+  await page.goto(`/app/library/albums/${albumId}`);
+  await page.getByTestId('album-detail-links-add').click();
+  await expect(page.locator('dialog[open]')).toBeVisible();
+
+  const target = page.getByTestId('album-link-target');
+  const targetId = await target.locator('option:not([disabled])').first().getAttribute('value').catch(() => null);
+  if (!targetId) {
+    return null;
+  }
+  await page.getByTestId('album-link-kind').selectOption(kind);
+  await target.selectOption(targetId);
+  await page.getByTestId('album-link-note').fill(note);
+  await page.getByTestId('album-link-save').click();
+  await expect(page.locator('dialog[open]')).toHaveCount(0);
+  return targetId;
+}
+
+async function removeLinks(page: Page, note: string) {
+  const links = page.getByTestId('album-detail-link').filter({ hasText: note });
+  for (let count = await links.count(); count > 0; count--) {
+    await links.first().getByTestId('album-detail-link-remove').click();
+    await expect(links).toHaveCount(count - 1);
+  }
+}
+
+test('Linking an album from its detail page', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  const targetId = await linkAlbum(page, 'influenced_by', 'E2E link note');
+  if (!targetId) {
+    test.skip();
+    return;
+  }
+
+  const link = page.getByTestId('album-detail-link').filter({ hasText: 'E2E link note' });
+  await expect(link).toHaveCount(1);
+  await expect(link.getByTestId('album-detail-link-label')).toHaveText('Influenced by');
+  await expect(link.getByTestId('album-detail-link-album')).toHaveAttribute('href', `/app/library/albums/${targetId}`);
+
+  await removeLinks(page, 'E2E link note');
+});
+
+test('Seeing a link from the other album', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  const targetId = await linkAlbum(page, 'sequel_to', 'E2E sequel note');
+  if (!targetId) {
+    test.skip();
+    return;
+  }
+
+  await page.goto(`/app/library/albums/${targetId}`);
+  const link = page.getByTestId('album-detail-link').filter({ hasText: 'E2E sequel note' });
+  await expect(link.getByTestId('album-detail-link-label')).toHaveText('Followed by');
+  await expect(link.getByTestId('album-detail-link-album')).toHaveAttribute('href', `/app/library/albums/${albumId}`);
+
+  await removeLinks(page, 'E2E sequel note');
+});
+
+test('Removing a link', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  const targetId = await linkAlbum(page, 'sounds_like', 'E2E remove note');
+  if (!targetId) {
+    test.skip();
+    return;
+  }
+
+  await removeLinks(page, 'E2E remove note');
+
+  await page.reload();
+  await expect(page.getByTestId('album-detail-link').filter({ hasText: 'E2E remove note' })).toHaveCount(0);
+});
+
+test('Fetching the link graph', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  const targetId = await linkAlbum(page, 'same_session', 'E2E graph note');
+  if (!targetId) {
+    test.skip();
+    return;
+  }
+
+  const response = await page.request.get('/app/links/graph');
+  expect(response.ok()).toBeTruthy();
+  const graph = await response.json();
+  const nodeIds = graph.nodes.map((node: { id: string }) => node.id);
+  expect(nodeIds).toContain(albumId);
+  expect(nodeIds).toContain(targetId);
+  const edge = graph.edges.find((edge: { note?: string }) => edge.note === 'E2E graph note');
+  expect(edge).toMatchObject({ source: albumId, target: targetId, kind: 'same_session' });
+
+  await page.goto(`/app/library/albums/${albumId}`);
+  await removeLinks(page, 'E2E graph note');
+});
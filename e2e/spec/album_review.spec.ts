@@ -0,0 +1,108 @@
+import { test, expect } from '@playwright/test';
+import { loginAs } from '../helpers/auth';
+
+// Scenarios from e2e/feat/album_review.feature
+
+const userId = process.env.E2E_TEST_USER_ID;
+const albumId = process.env.E2E_TEST_ALBUM_ID;
+
+async function openEditor(page: any) {
+  await page.goto(`/app/review/album-review?albumId=${albumId}`);
+  await expect(page.getByTestId('album-review-body')).toBeVisible();
+}
+
+async function deleteReview(page: any) {
+  const deleteButton = page.getByTestId('album-review-delete');
+  if (await deleteButton.count() === 0) {
+    return;
+  }
+  page.once('dialog', (dialog: any) => dialog.accept());
+  const responsePromise = page.waitForResponse(
+    (resp: any) => resp.url().includes('/album-review') && resp.request().method() === 'DELETE'
+  );
+  await deleteButton.click();
+  await responsePromise;
+}
+
+test('Review editor opens from the album detail page', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await page.goto(`/app/library/albums/${albumId}`);
+
+  await page.getByTestId('album-detail-review-edit').click();
+
+  await expect(page.getByTestId('album-review-body')).toBeVisible();
+  await expect(page.getByTestId('album-review-publish')).toBeVisible();
+});
+
+test('Drafts autosave while typing', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await openEditor(page);
+  await deleteReview(page);
+
+  await page.getByTestId('album-review-body').fill('An autosaved draft');
+
+  await expect(page.getByTestId('album-review-save-status')).toContainText('Draft saved', { timeout: 5000 });
+
+  await deleteReview(page);
+});
+
+test('Preview renders markdown', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await openEditor(page);
+
+  await page.getByTestId('album-review-body').fill('**loud** and _quiet_');
+  await page.getByTestId('album-review-preview-tab').click();
+
+  const preview = page.getByTestId('album-review-preview');
+  await expect(preview.locator('strong')).toHaveText('loud');
+  await expect(preview.locator('em')).toHaveText('quiet');
+
+  await deleteReview(page);
+});
+
+test('Publishing shows the review on the album detail page', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await openEditor(page);
+  await deleteReview(page);
+
+  await page.getByTestId('album-review-body').fill('A **published** review');
+  await page.getByTestId('album-review-publish').click();
+  await expect(page.getByTestId('album-review-unpublish')).toBeVisible();
+
+  await page.goto(`/app/library/albums/${albumId}`);
+  const body = page.getByTestId('album-detail-review-body');
+  await expect(body.locator('strong')).toHaveText('published');
+  await expect(page.getByTestId('album-detail-review-draft')).toHaveCount(0);
+
+  await openEditor(page);
+  await deleteReview(page);
+});
+
+test('Deleting a review clears it from the album detail page', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await openEditor(page);
+
+  await page.getByTestId('album-review-body').fill('Soon to be gone');
+  await page.getByTestId('album-review-save-draft').click();
+  await expect(page.getByTestId('album-review-delete')).toBeVisible();
+
+  await deleteReview(page);
+
+  await page.goto(`/app/library/albums/${albumId}`);
+  await expect(page.getByTestId('album-detail-review-body')).toHaveCount(0);
+});
@@ -0,0 +1,45 @@
+import { test, expect } from '@playwright/test';
+import { loginAs } from '../helpers/auth';
+
+// Scenarios from e2e/feat/artist_credits.feature
+
+const userId = process.env.E2E_TEST_USER_ID;
+const albumId = process.env.E2E_TEST_ALBUM_ID;
+
+test('Overriding an artist\'s sort name', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await page.goto(`/app/library/albums/${albumId}`);
+  await page.getByTestId('album-detail-artist-link').first().click();
+
+  const identity = page.getByTestId('artist-identity');
+  const sortName = `E2E Sort ${Date.now()}`;
+  await identity.getByTestId('artist-sort-name-input').fill(sortName);
+  await identity.getByTestId('artist-sort-name-save').click();
+
+  await expect(identity.getByTestId('artist-sort-name-input')).toHaveValue(sortName);
+  await identity.getByTestId('artist-sort-name-reset').click();
+  await expect(identity.getByTestId('artist-sort-name-reset')).toHaveCount(0);
+});
+
+test('Adding a manual alias', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await page.goto(`/app/library/albums/${albumId}`);
+  await page.getByTestId('album-detail-artist-link').first().click();
+
+  const identity = page.getByTestId('artist-identity');
+  const alias = `E2E Alias ${Date.now()}`;
+  await identity.getByTestId('artist-alias-source').selectOption('manual');
+  await identity.getByTestId('artist-alias-name').fill(alias);
+  await identity.getByTestId('artist-alias-add').click();
+
+  const row = identity.getByTestId('artist-alias').filter({ hasText: alias });
+  await expect(row).toBeVisible();
+  await row.getByTestId('artist-alias-delete').click();
+  await expect(row).toHaveCount(0);
+});
@@ -0,0 +1,38 @@
+import { test, expect } from '@playwright/test';
+import { loginAs } from '../helpers/auth';
+
+// Scenarios from e2e/feat/artist_pages.feature
+
+const userId = process.env.E2E_TEST_USER_ID;
+const albumId = process.env.E2E_TEST_ALBUM_ID;
+
+test('Opening an artist from an album', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await page.goto(`/app/library/albums/${albumId}`);
+
+  await page.getByTestId('album-detail-artist-link').first().click();
+
+  await expect(page).toHaveURL(/\/app\/library\/artists\/[^/]+$/);
+  await expect(page.getByTestId('artist-name')).toBeVisible();
+  await expect(page.locator(`[data-testid="artist-album"] a[href="/app/library/albums/${albumId}"]`)).toBeVisible();
+});
+
+test('Seeing discography coverage', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await page.goto(`/app/library/albums/${albumId}`);
+  await page.getByTestId('album-detail-artist-link').first().click();
+
+  const discography = page.getByTestId('artist-discography');
+  await expect(discography.locator('.loading')).toHaveCount(0);
+
+  // The test environment may not reach Spotify; either outcome is rendered.
+  const statuses = discography.getByTestId('artist-discography-status');
+  const error = discography.getByTestId('artist-discography-error');
+  await expect(statuses.first().or(error).or(discography.getByTestId('artist-discography-empty'))).toBeVisible();
+});
@@ -0,0 +1,64 @@
+import { test, expect, Page } from '@playwright/test';
+import { loginAs } from '../helpers/auth';
+
+// Scenarios from e2e/feat/bulk_edits.feature
+
+const userId = process.env.E2E_TEST_USER_ID;
+
+async function createShelf(page: Page, name: string): Promise<string> {
+  await page.goto('/app/shelves');
+  await page.getByTestId('shelf-create-name').fill(name);
+  await page.getByTestId('shelf-create').click();
+  await expect(page.getByTestId('shelf-name')).toHaveText(name);
+  return page.url();
+}
+
+async function deleteShelf(page: Page, shelfUrl: string) {
+  await page.goto(shelfUrl);
+  page.once('dialog', (dialog) => dialog.accept());
+  await page.getByTestId('shelf-delete').click();
+  await expect(page).toHaveURL(/\/app\/shelves$/);
+}
+
+async function shelveTwoAlbums(page: Page, name: string) {
+  await page.goto('/app/library/dashboard');
+  await page.getByTestId('bulk-select-toggle').click();
+  const checkboxes = page.getByTestId('album-row-select');
+  await checkboxes.nth(0).check();
+  await checkboxes.nth(1).check();
+  await expect(page.getByTestId('bulk-selected-count')).toHaveText('2 selected');
+
+  await page.getByTestId('bulk-operation-select').selectOption('add_to_shelf');
+  await page.getByTestId('bulk-shelf-select').selectOption({ label: name });
+  await page.getByTestId('bulk-apply').click();
+  await expect(page.getByTestId('bulk-edit-summary')).toHaveText(`Put 2 albums on "${name}"`);
+}
+
+test('Putting selected albums on a shelf', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  const shelfUrl = await createShelf(page, 'E2E bulk');
+  await shelveTwoAlbums(page, 'E2E bulk');
+
+  await page.goto(shelfUrl);
+  await expect(page.getByTestId('shelf-album')).toHaveCount(2);
+
+  await deleteShelf(page, shelfUrl);
+});
+
+test('Undoing a bulk edit', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  const shelfUrl = await createShelf(page, 'E2E bulk undo');
+  await shelveTwoAlbums(page, 'E2E bulk undo');
+
+  await page.getByTestId('bulk-edit-undo').click();
+  await expect(page.getByTestId('bulk-edit-summary')).toHaveText('Undone: Put 2 albums on "E2E bulk undo"');
+
+  await page.goto(shelfUrl);
+  await expect(page.getByTestId('shelf-empty')).toBeVisible();
+
+  await deleteShelf(page, shelfUrl);
+});
@@ -0,0 +1,68 @@
+import { test, expect } from '@playwright/test';
+import { loginAs } from '../helpers/auth';
+
+// Scenarios from e2e/feat/calibration.feature
+
+const userId = process.env.E2E_TEST_USER_ID;
+
+test('Calibration report opens from the user menu', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await page.goto('/app/library/dashboard');
+
+  await page.locator('.dropdown-end [role="button"]').click();
+  await page.getByTestId('calibration-link').click();
+
+  await expect(page).toHaveURL(/\/app\/review\/calibration/);
+  await expect(page.getByTestId('calibration-distribution')).toBeVisible();
+  await expect(page.getByTestId('calibration-drift')).toBeVisible();
+});
+
+test('Choosing a different target curve', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await page.goto('/app/review/calibration');
+
+  const responsePromise = page.waitForResponse((resp: any) => resp.url().includes('/calibration/report'));
+  await page.getByTestId('calibration-target').selectOption('critic');
+  await responsePromise;
+
+  await expect(page.getByTestId('calibration-target')).toHaveValue('critic');
+});
+
+test('Invalid custom curve shows an error', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await page.goto('/app/review/calibration');
+
+  await page.getByTestId('calibration-target').selectOption('custom');
+  await page.getByTestId('calibration-target-spread').fill('0');
+  await page.getByTestId('calibration-target-spread').dispatchEvent('change');
+
+  await expect(page.getByTestId('calibration-error')).toContainText('spread');
+});
+
+test('Applying a suggestion logs a new rating', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await page.goto('/app/review/calibration');
+
+  const suggestions = page.getByTestId('calibration-suggestion');
+  test.skip(await suggestions.count() === 0, 'ratings already fit the default curve');
+
+  // Only apply the first suggestion
+  const boxes = page.locator('[data-testid="calibration-suggestion"] input[name="albumId"]');
+  const count = await boxes.count();
+  for (let i = 1; i < count; i++) {
+    await boxes.nth(i).uncheck();
+  }
+
+  page.once('dialog', (dialog: any) => dialog.accept());
+  await page.getByTestId('calibration-apply').click();
+
+  await expect(page.getByTestId('calibration-applied')).toContainText('Logged 1 recalibrated');
+});
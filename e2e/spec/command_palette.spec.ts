@@ -0,0 +1,49 @@
+import { test, expect, Page } from '@playwright/test';
+import { loginAs } from '../helpers/auth';
+
+// Scenarios from e2e/feat/command_palette.feature
+
+const userId = process.env.E2E_TEST_USER_ID;
+const albumId = process.env.E2E_TEST_ALBUM_ID;
+
+const tagName = 'e2e-palette';
+
+async function tagAlbum(page: Page) {
+  await page.goto(`/app/library/albums/${albumId}`);
+  await page.getByTestId('album-detail-tags-edit').click();
+  await expect(page.locator('dialog[open]')).toBeVisible();
+  await page.getByTestId('tags-input').fill(tagName);
+  await page.getByTestId('tags-input').press('Enter');
+  await page.getByTestId('tags-save').click();
+  await expect(page.locator('dialog[open]')).not.toBeVisible();
+}
+
+test('Opening the palette from the keyboard', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await page.goto('/app/library/dashboard');
+
+  await page.keyboard.press('Control+k');
+
+  await expect(page.getByTestId('command-palette')).toBeVisible();
+  await expect(page.getByTestId('command-palette-input')).toBeFocused();
+});
+
+test('Finding a library album', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await tagAlbum(page);
+
+  await page.getByTestId('command-palette-open').click();
+  await page.getByTestId('command-palette-input').pressSequentially(tagName);
+
+  const album = page.locator(`[data-testid="command-palette-album"][href="/app/library/albums/${albumId}"]`);
+  await expect(album).toBeVisible();
+  await expect(page.getByTestId('command-palette-tags')).toContainText(tagName);
+
+  await album.click();
+  await expect(page).toHaveURL(new RegExp(`/app/library/albums/${albumId}$`));
+});
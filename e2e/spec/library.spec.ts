@@ -70,6 +70,18 @@ test('Switching the carousel to Unrated', async ({ context, page }) => {
   await expect(page.getByTestId('carousel-unrated-tab')).not.toHaveAttribute('hx-get');
 });
 
+test('Switching the carousel to Revisit', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await page.goto('/app/library/dashboard');
+
+  await page.getByTestId('carousel-revisit-tab').click();
+
+  await expect(page.getByTestId('carousel-revisit-tab')).not.toHaveAttribute('hx-get');
+  await expect(page.getByTestId('carousel-recently-spun-tab')).toHaveAttribute('hx-get');
+});
+
 // --- Sort chip ---
 
 test('Sort chip is visible and shows default sort', async ({ context, page }) => {
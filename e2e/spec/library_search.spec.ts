@@ -0,0 +1,69 @@
+import { test, expect, Page } from '@playwright/test';
+import { loginAs } from '../helpers/auth';
+
+// Scenarios from e2e/feat/library_search.feature
+
+const userId = process.env.E2E_TEST_USER_ID;
+const albumId = process.env.E2E_TEST_ALBUM_ID;
+
+const tagName = 'e2e-searchable';
+
+async function tagAlbum(page: Page) {
+  await page.goto(`/app/library/albums/${albumId}`);
+  await page.getByTestId('album-detail-tags-edit').click();
+  await expect(page.locator('dialog[open]')).toBeVisible();
+  await page.getByTestId('tags-input').fill(tagName);
+  await page.getByTestId('tags-input').press('Enter');
+  await page.getByTestId('tags-save').click();
+  await expect(page.locator('dialog[open]')).not.toBeVisible();
+}
+
+const albumRow = (page: Page) =>
+  page.locator(`[data-testid="album-list-row"] a[href="/app/library/albums/${albumId}"]`);
+
+test('Searching as you type', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await tagAlbum(page);
+
+  await page.goto('/app/library/dashboard');
+  await page.getByTestId('library-search').pressSequentially('E2E-SEARCHA');
+
+  await expect(page.getByTestId('sort-chip')).toContainText('Relevance');
+  await expect(albumRow(page).first()).toBeVisible();
+  await expect(page.getByTestId('library-search')).toBeFocused();
+  await expect(page.getByTestId('library-search')).toHaveValue('E2E-SEARCHA');
+});
+
+test('Searching one field', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await tagAlbum(page);
+
+  await page.goto('/app/library/dashboard');
+  await page.getByTestId('library-search').fill(`artist:${tagName}`);
+
+  await expect(page.getByTestId('sort-chip')).toContainText('Relevance');
+  await expect(albumRow(page)).toHaveCount(0);
+});
+
+test('Clearing the search', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await tagAlbum(page);
+
+  await page.goto('/app/library/dashboard');
+  await page.getByTestId('library-search').fill(tagName);
+  await expect(page.getByTestId('sort-chip')).toContainText('Relevance');
+
+  await page.getByTestId('library-search').fill('');
+
+  await expect(page.getByTestId('sort-chip')).toContainText('Date Added');
+  await expect(page.getByTestId('album-list-row').first()).toBeVisible();
+});
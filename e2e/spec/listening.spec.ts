@@ -0,0 +1,71 @@
+import { test, expect } from '@playwright/test';
+import { loginAs } from '../helpers/auth';
+
+// Scenarios from e2e/feat/listening.feature
+
+const userId = process.env.E2E_TEST_USER_ID;
+
+test('Opening the listening tab', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await page.goto('/app/stats');
+
+  await page.getByTestId('stats-listening-tab').click();
+
+  await expect(page).toHaveURL(/\/app\/stats\/listening$/);
+  await expect(page.getByTestId('listening-heatmap')).toBeVisible();
+  expect(await page.getByTestId('listening-heatmap-day').count()).toBeGreaterThan(364);
+  await expect(page.getByTestId('listening-current-streak')).toBeVisible();
+  await expect(page.getByTestId('listening-longest-streak')).toBeVisible();
+});
+
+test('Narrowing listening to a tag', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await page.goto('/app/stats/listening');
+
+  const select = page.getByTestId('listening-tag-select');
+  const options = select.locator('option');
+  test.skip((await options.count()) < 2, 'the test user has no tags');
+  const tagId = await options.nth(1).getAttribute('value');
+
+  await select.selectOption(tagId!);
+
+  await expect(page).toHaveURL(new RegExp(`tag=${tagId}`));
+  await expect(page.getByTestId('listening-tag-select')).toHaveValue(tagId!);
+
+  await page.getByTestId('listening-filter-clear').click();
+  await expect(page).toHaveURL(/\/app\/stats\/listening$/);
+});
+
+test('Setting a timezone', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await page.goto('/app/stats/listening');
+
+  const timezone = page.getByTestId('listening-timezone');
+  const original = await timezone.inputValue();
+
+  await timezone.fill('Europe/London');
+  await page.getByTestId('listening-timezone-save').click();
+  await expect(page.getByTestId('listening-timezone')).toHaveValue('Europe/London');
+
+  await page.getByTestId('listening-timezone').fill(original);
+  await page.getByTestId('listening-timezone-save').click();
+  await expect(page.getByTestId('listening-timezone')).toHaveValue(original);
+});
+
+test('Setting an unknown timezone', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await page.goto('/app/stats/listening');
+
+  await page.getByTestId('listening-timezone').fill('Mars/Olympus_Mons');
+  await page.getByTestId('listening-timezone-save').click();
+
+  await expect(page.getByTestId('stats-error')).toContainText('unknown timezone');
+});
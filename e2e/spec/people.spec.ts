@@ -0,0 +1,108 @@
+import { test, expect, Page } from '@playwright/test';
+import { loginAs } from '../helpers/auth';
+
+// Scenarios from e2e/feat/people.feature
+
+const userId = process.env.E2E_TEST_USER_ID;
+const albumId = process.env.E2E_TEST_ALBUM_ID;
+
+async function addAlbumPerson(page: Page, direction: string, name: string) {
+  await page.goto(`/app/library/albums/${albumId}`);
+  await page.getByTestId('album-detail-people-add').click();
+  await expect(page.locator('dialog[open]')).toBeVisible();
+  await page.getByTestId('album-person-direction').selectOption(direction);
+  await page.getByTestId('album-person-name').fill(name);
+  await page.getByTestId('album-person-save').click();
+  await expect(page.locator('dialog[open]')).toHaveCount(0);
+}
+
+// forgetPerson deletes a person from the people page, which also takes them
+// off every album they were recorded on.
+async function forgetPerson(page: Page, name: string) {
+  await page.goto('/app/people');
+  const person = page.getByTestId('person').filter({ hasText: name });
+  if (await person.count() === 0) {
+    return;
+  }
+  page.once('dialog', (dialog) => dialog.accept());
+  await person.getByTestId('person-delete').click();
+  await expect(person).toHaveCount(0);
+}
+
+test('Recording who introduced an album', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await addAlbumPerson(page, 'introduced_by', 'E2E Jamie');
+
+  const person = page.getByTestId('album-detail-person').filter({ hasText: 'E2E Jamie' });
+  await expect(person).toHaveCount(1);
+  await expect(person.getByTestId('album-detail-person-label')).toHaveText('Introduced by');
+
+  await forgetPerson(page, 'E2E Jamie');
+});
+
+test('Filtering the library to a person\'s recommendations', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await addAlbumPerson(page, 'introduced_by', 'E2E Filter Person');
+
+  await page.getByTestId('album-detail-person').filter({ hasText: 'E2E Filter Person' })
+    .getByTestId('album-detail-person-name').click();
+  await expect(page).toHaveURL(/introducedBy=/);
+  await expect(page.getByTestId('introduced-by-chip')).toContainText('E2E Filter Person');
+  await expect(page.getByTestId('album-row-title-link')).toHaveCount(1);
+
+  await forgetPerson(page, 'E2E Filter Person');
+});
+
+test('Seeing recommendation stats', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await addAlbumPerson(page, 'introduced_by', 'E2E Stats Person');
+
+  await page.goto('/app/people');
+  const person = page.getByTestId('person').filter({ hasText: 'E2E Stats Person' });
+  await expect(person).toHaveCount(1);
+  await expect(person.getByTestId('person-introduced-count')).toHaveText('Introduced 1 album');
+
+  await forgetPerson(page, 'E2E Stats Person');
+});
+
+test('Removing a person from an album', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await addAlbumPerson(page, 'shared_with', 'E2E Remove Person');
+
+  const person = page.getByTestId('album-detail-person').filter({ hasText: 'E2E Remove Person' });
+  await expect(person.getByTestId('album-detail-person-label')).toHaveText('Shared with');
+  await person.getByTestId('album-detail-person-remove').click();
+  await expect(person).toHaveCount(0);
+
+  await page.reload();
+  await expect(page.getByTestId('album-detail-person').filter({ hasText: 'E2E Remove Person' })).toHaveCount(0);
+
+  await forgetPerson(page, 'E2E Remove Person');
+});
+
+test('Forgetting a person', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await page.goto('/app/people');
+  await page.getByTestId('person-create-form').getByTestId('person-name-input').fill('E2E Forget Person');
+  await page.getByTestId('person-create').click();
+  await expect(page.getByTestId('person').filter({ hasText: 'E2E Forget Person' })).toHaveCount(1);
+
+  await forgetPerson(page, 'E2E Forget Person');
+
+  await page.reload();
+  await expect(page.getByTestId('person').filter({ hasText: 'E2E Forget Person' })).toHaveCount(0);
+});
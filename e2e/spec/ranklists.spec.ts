@@ -0,0 +1,114 @@
+import { test, expect, Page } from '@playwright/test';
+import { loginAs } from '../helpers/auth';
+
+// Scenarios from e2e/feat/ranklists.feature
+
+const userId = process.env.E2E_TEST_USER_ID;
+
+async function createList(page: Page, name: string) {
+  await page.goto('/app/ranklists');
+  await page.getByTestId('ranklist-create-name').fill(name);
+  await page.getByTestId('ranklist-create').click();
+  await expect(page.getByTestId('ranklist-name')).toHaveText(name);
+}
+
+async function deleteList(page: Page) {
+  page.once('dialog', (dialog) => dialog.accept());
+  await page.getByTestId('ranklist-delete').click();
+  await expect(page).toHaveURL(/\/app\/ranklists$/);
+}
+
+test('Creating a hand-picked list', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await createList(page, 'E2E create');
+
+  await expect(page).toHaveURL(/\/app\/ranklists\/[^/]+$/);
+  await expect(page.getByTestId('ranklist-source')).toContainText('Hand-picked');
+  await expect(page.getByTestId('ranklist-empty')).toBeVisible();
+
+  await deleteList(page);
+});
+
+test('Adding albums at a position', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await createList(page, 'E2E add');
+
+  await page.getByTestId('ranklist-add').click();
+  await expect(page.getByTestId('ranklist-entry')).toHaveCount(1);
+  const first = await page.getByTestId('ranklist-entry-title').first().textContent();
+
+  await page.getByTestId('ranklist-add-rank').fill('1');
+  await page.getByTestId('ranklist-add').click();
+  await expect(page.getByTestId('ranklist-entry')).toHaveCount(2);
+  await expect(page.getByTestId('ranklist-entry-title').nth(1)).toHaveText(first!);
+
+  await deleteList(page);
+});
+
+test('Moving an album by typing its rank', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await createList(page, 'E2E move');
+
+  await page.getByTestId('ranklist-add').click();
+  await expect(page.getByTestId('ranklist-entry')).toHaveCount(1);
+  await page.getByTestId('ranklist-add').click();
+  await expect(page.getByTestId('ranklist-entry')).toHaveCount(2);
+  const second = await page.getByTestId('ranklist-entry-title').nth(1).textContent();
+
+  const rank = page.getByTestId('ranklist-entry-rank').nth(1);
+  await rank.fill('1');
+  await rank.dispatchEvent('change');
+  await expect(page.getByTestId('ranklist-entry-title').first()).toHaveText(second!);
+
+  await deleteList(page);
+});
+
+test('Adding a blurb', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await createList(page, 'E2E blurb');
+
+  await page.getByTestId('ranklist-add').click();
+  await page.getByTestId('ranklist-entry-blurb-edit').click();
+  await page.getByTestId('ranklist-entry-blurb-input').fill('The one that started it all');
+  await page.getByTestId('ranklist-entry-blurb-save').click();
+
+  await expect(page.getByTestId('ranklist-entry-blurb')).toHaveText('The one that started it all');
+
+  await deleteList(page);
+});
+
+test('A list built from a rating range', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await page.goto('/app/ranklists');
+
+  const form = page.getByTestId('ranklist-create-form');
+  await form.getByTestId('ranklist-create-name').fill('E2E rated');
+  await form.getByTestId('ranklist-source-select').selectOption('rating');
+  await page.getByTestId('ranklist-create').click();
+
+  await expect(page.getByTestId('ranklist-source')).toContainText('Albums rated');
+  await expect(page.getByTestId('ranklist-add-form')).toHaveCount(0);
+  await expect(page.getByTestId('ranklist-entry-remove')).toHaveCount(0);
+
+  await deleteList(page);
+});
+
+test('Deleting a list', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await createList(page, 'E2E delete');
+  await deleteList(page);
+
+  await expect(page.getByTestId('ranklist-card-name').filter({ hasText: 'E2E delete' })).toHaveCount(0);
+});
@@ -0,0 +1,38 @@
+import { test, expect } from '@playwright/test';
+import { loginAs } from '../helpers/auth';
+
+// Scenarios from e2e/feat/revisit_rules.feature
+
+const userId = process.env.E2E_TEST_USER_ID;
+
+test('Saving revisit rules', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await page.goto('/app/review/rating-profile');
+
+  await page.getByTestId('revisit-rules-stale-after-days').fill('180');
+  await page.getByTestId('revisit-rules-min-plays-since').fill('5');
+  await page.getByTestId('revisit-rules-save').click();
+
+  await expect(page.getByTestId('revisit-rules-saved')).toBeVisible();
+  await expect(page.getByTestId('revisit-rules-stale-after-days')).toHaveValue('180');
+
+  // Restore the defaults for other tests
+  await page.getByTestId('revisit-rules-stale-after-days').fill('365');
+  await page.getByTestId('revisit-rules-min-plays-since').fill('10');
+  await page.getByTestId('revisit-rules-save').click();
+  await expect(page.getByTestId('revisit-rules-stale-after-days')).toHaveValue('365');
+});
+
+test('Invalid revisit rules show an error', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await page.goto('/app/review/rating-profile');
+
+  await page.getByTestId('revisit-rules-min-plays-since').fill('0');
+  await page.getByTestId('revisit-rules-save').click();
+
+  await expect(page.getByTestId('revisit-rules-error')).toContainText('at least 1');
+});
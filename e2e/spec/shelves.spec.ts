@@ -0,0 +1,110 @@
+import { test, expect, Page } from '@playwright/test';
+import { loginAs } from '../helpers/auth';
+
+// Scenarios from e2e/feat/shelves.feature
+
+const userId = process.env.E2E_TEST_USER_ID;
+const albumId = process.env.E2E_TEST_ALBUM_ID;
+
+async function createShelf(page: Page, name: string) {
+  await page.goto('/app/shelves');
+  await page.getByTestId('shelf-create-name').fill(name);
+  await page.getByTestId('shelf-create').click();
+  await expect(page.getByTestId('shelf-name')).toHaveText(name);
+}
+
+async function deleteShelf(page: Page) {
+  page.once('dialog', (dialog) => dialog.accept());
+  await page.getByTestId('shelf-delete').click();
+  await expect(page).toHaveURL(/\/app\/shelves$/);
+}
+
+async function shelveAlbum(page: Page, name: string) {
+  await page.goto(`/app/library/albums/${albumId}`);
+  await page.getByTestId('album-detail-shelves-edit').click();
+  await expect(page.locator('dialog[open]')).toBeVisible();
+  await page.getByTestId('album-shelves-new').fill(name);
+  await page.getByTestId('album-shelves-save').click();
+  await expect(page.locator('dialog[open]')).toHaveCount(0);
+}
+
+test('Creating a shelf', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await createShelf(page, 'E2E create');
+
+  await expect(page).toHaveURL(/\/app\/shelves\/[^/]+$/);
+  await expect(page.getByTestId('shelf-kind')).toContainText('Hand-picked');
+  await expect(page.getByTestId('shelf-empty')).toBeVisible();
+
+  await deleteShelf(page);
+});
+
+test('Adding an album to a shelf from its detail page', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await shelveAlbum(page, 'E2E sunday');
+
+  const shelf = page.getByTestId('album-detail-shelf').filter({ hasText: 'E2E sunday' });
+  await expect(shelf).toBeVisible();
+
+  await shelf.click();
+  await expect(page.getByTestId('shelf-name')).toHaveText('E2E sunday');
+  await expect(page.getByTestId('shelf-album')).toHaveCount(1);
+
+  await deleteShelf(page);
+});
+
+test('Filtering the library to a shelf', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await shelveAlbum(page, 'E2E filter');
+
+  await page.goto('/app/library/dashboard');
+  await page.getByTestId('shelf-chip').click();
+  await page.getByTestId('shelf-option').filter({ hasText: 'E2E filter' }).click();
+  await page.locator('dialog[open] button[type="submit"]').click();
+
+  await expect(page.getByTestId('shelf-chip')).toHaveText('E2E filter');
+  await expect(page.getByTestId('album-row-title-link')).toHaveCount(1);
+
+  await page.goto(`/app/library/albums/${albumId}`);
+  await page.getByTestId('album-detail-shelf').filter({ hasText: 'E2E filter' }).click();
+  await deleteShelf(page);
+});
+
+test('Saving filters as a smart shelf', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await page.goto('/app/library/dashboard');
+
+  await page.getByTestId('rating-chip').click();
+  await page.locator('dialog[open] input[name="rated"][value="only"]').check();
+  await page.locator('dialog[open] button[type="submit"]').click();
+
+  await page.getByTestId('smart-shelf-chip').click();
+  await page.getByTestId('smart-shelf-name').fill('E2E rated');
+  await page.getByTestId('smart-shelf-save').click();
+
+  await expect(page.getByTestId('shelf-name')).toHaveText('E2E rated');
+  await expect(page.getByTestId('shelf-kind')).toContainText('Smart');
+  await expect(page.getByTestId('shelf-album-remove')).toHaveCount(0);
+
+  await deleteShelf(page);
+});
+
+test('Deleting a shelf', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await createShelf(page, 'E2E delete');
+  await deleteShelf(page);
+
+  await expect(page.getByTestId('shelf-card-name').filter({ hasText: 'E2E delete' })).toHaveCount(0);
+});
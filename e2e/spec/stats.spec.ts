@@ -0,0 +1,45 @@
+import { test, expect } from '@playwright/test';
+import { loginAs } from '../helpers/auth';
+
+// Scenarios from e2e/feat/stats.feature
+
+const userId = process.env.E2E_TEST_USER_ID;
+
+test('Opening the stats page', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await page.goto('/app/library/dashboard');
+
+  await page.locator('.dropdown-end [role="button"]').click();
+  await page.getByTestId('stats-link').click();
+
+  await expect(page).toHaveURL(/\/app\/stats$/);
+  await expect(page.getByTestId('stats-growth')).toBeVisible();
+  await expect(page.getByTestId('stats-albums')).toBeVisible();
+});
+
+test('Narrowing stats to a range', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await page.goto('/app/stats');
+
+  await page.getByTestId('stats-range-preset').filter({ hasText: 'Last 30 days' }).click();
+
+  await expect(page.getByTestId('stats-range-from')).not.toHaveValue('');
+  await expect(page.getByTestId('stats-json-link')).toHaveAttribute('href', /from=\d{4}-\d{2}-\d{2}/);
+});
+
+test('Fetching stats as JSON', async ({ context }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  const response = await context.request.get('/app/stats.json?from=2000-01-01');
+
+  expect(response.ok()).toBeTruthy();
+  const stats = await response.json();
+  expect(stats.range.from).toBe('2000-01-01');
+  expect(stats.albums).toBeGreaterThan(0);
+  expect(Array.isArray(stats.growth.points)).toBeTruthy();
+});
@@ -0,0 +1,98 @@
+import { test, expect, Page } from '@playwright/test';
+import { loginAs } from '../helpers/auth';
+
+// Scenarios from e2e/feat/tag_groups.feature
+
+const userId = process.env.E2E_TEST_USER_ID;
+const albumId = process.env.E2E_TEST_ALBUM_ID;
+
+function groupRow(page: Page, name: string) {
+  return page.getByTestId('tag-group').filter({ has: page.getByTestId('tag-group-name').getByText(name, { exact: true }) });
+}
+
+async function createGroup(page: Page, name: string, exclusive: boolean) {
+  await page.goto('/app/tags');
+  const form = page.getByTestId('tag-group-create-form');
+  await form.getByTestId('tag-group-name-input').fill(name);
+  await form.getByTestId('tag-group-color-select').selectOption('accent');
+  if (exclusive) {
+    await form.getByTestId('tag-group-exclusive-input').check();
+  }
+  await form.getByTestId('tag-group-create').click();
+  await expect(groupRow(page, name)).toHaveCount(1);
+}
+
+async function deleteGroup(page: Page, name: string) {
+  await page.goto('/app/tags');
+  const row = groupRow(page, name);
+  if (await row.count() === 0) {
+    return;
+  }
+  page.once('dialog', (dialog) => dialog.accept());
+  await row.getByTestId('tag-group-delete').click();
+  await expect(groupRow(page, name)).toHaveCount(0);
+}
+
+test('Adding a tag group', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await createGroup(page, 'E2E Era', true);
+
+  const row = groupRow(page, 'E2E Era');
+  await expect(row.getByTestId('tag-group-exclusive')).toHaveText('One tag per album');
+  await expect(row.getByTestId('tag-group-name')).toHaveClass(/badge-accent/);
+  await expect(page.getByTestId('tag-group').last()).toContainText('E2E Era');
+
+  await deleteGroup(page, 'E2E Era');
+});
+
+test('Picking a second tag from an exclusive group', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await createGroup(page, 'E2E Decade', true);
+
+  await page.goto(`/app/library/albums/${albumId}`);
+  await page.getByTestId('album-detail-tags-edit').click();
+  const dialog = page.locator('dialog[open]');
+  await expect(dialog).toBeVisible();
+  await dialog.getByRole('button', { name: 'E2E Decade' }).click();
+  await page.getByTestId('tags-input').fill('e2e seventies');
+  await page.getByTestId('tags-input').press('Enter');
+  await page.getByTestId('tags-input').fill('e2e eighties');
+  await page.getByTestId('tags-input').press('Enter');
+
+  await expect(dialog.locator('.badge', { hasText: 'e2e eighties' })).toBeVisible();
+  await expect(dialog.locator('.badge', { hasText: 'e2e seventies' })).toHaveCount(0);
+
+  await deleteGroup(page, 'E2E Decade');
+});
+
+test('Reordering tag groups', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await createGroup(page, 'E2E Occasion', false);
+
+  const groups = page.getByTestId('tag-group');
+  const count = await groups.count();
+  await expect(groups.nth(count - 1)).toContainText('E2E Occasion');
+  await groupRow(page, 'E2E Occasion').getByTestId('tag-group-up').click();
+  await expect(groups.nth(count - 2)).toContainText('E2E Occasion');
+
+  await deleteGroup(page, 'E2E Occasion');
+});
+
+test('Deleting a tag group', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await createGroup(page, 'E2E Instrumentation', false);
+
+  await deleteGroup(page, 'E2E Instrumentation');
+
+  await page.reload();
+  await expect(groupRow(page, 'E2E Instrumentation')).toHaveCount(0);
+});
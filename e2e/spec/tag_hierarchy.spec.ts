@@ -0,0 +1,95 @@
+import { test, expect, Page } from '@playwright/test';
+import { loginAs } from '../helpers/auth';
+
+// Scenarios from e2e/feat/tag_hierarchy.feature
+
+const userId = process.env.E2E_TEST_USER_ID;
+const albumId = process.env.E2E_TEST_ALBUM_ID;
+
+// addAlbumTags adds tags to the E2E album alongside the ones it already has.
+async function addAlbumTags(page: Page, names: string[]) {
+  await page.goto(`/app/library/albums/${albumId}`);
+  await page.getByTestId('album-detail-tags-edit').click();
+  await expect(page.locator('dialog[open]')).toBeVisible();
+  for (const name of names) {
+    await page.getByTestId('tags-input').fill(name);
+    await page.getByTestId('tags-input').press('Enter');
+  }
+  await page.getByTestId('tags-save').click();
+  await expect(page.locator('dialog[open]')).toHaveCount(0);
+}
+
+function tagRow(page: Page, name: string) {
+  return page.getByTestId('tag').filter({ has: page.getByTestId('tag-name').getByText(name, { exact: true }) });
+}
+
+async function putUnder(page: Page, name: string, parentPath: string) {
+  await page.goto('/app/tags');
+  const row = tagRow(page, name);
+  await row.getByTestId('tag-edit').click();
+  await row.getByTestId('tag-parent-select').selectOption({ label: `Under ${parentPath}` });
+  await row.getByTestId('tag-save').click();
+  await expect(tagRow(page, name).getByTestId('tag-parent')).toHaveText(`${parentPath} /`);
+}
+
+async function deleteTag(page: Page, name: string) {
+  await page.goto('/app/tags');
+  const row = tagRow(page, name);
+  if (await row.count() === 0) {
+    return;
+  }
+  page.once('dialog', (dialog) => dialog.accept());
+  await row.getByTestId('tag-delete').click();
+  await expect(row).toHaveCount(0);
+}
+
+test('Putting a tag under another', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await addAlbumTags(page, ['e2e rock', 'e2e shoegaze']);
+
+  await putUnder(page, 'e2e shoegaze', 'e2e rock');
+
+  await deleteTag(page, 'e2e shoegaze');
+  await deleteTag(page, 'e2e rock');
+});
+
+test('A tag can\'t go under its own subtag', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await addAlbumTags(page, ['e2e metal', 'e2e doom']);
+  await putUnder(page, 'e2e doom', 'e2e metal');
+
+  const row = tagRow(page, 'e2e metal');
+  await row.getByTestId('tag-edit').click();
+  const options = row.getByTestId('tag-parent-select').locator('option');
+  await expect(options.filter({ hasText: 'e2e doom' })).toHaveCount(0);
+  await expect(options.filter({ hasText: 'e2e metal' })).toHaveCount(0);
+
+  await deleteTag(page, 'e2e doom');
+  await deleteTag(page, 'e2e metal');
+});
+
+test('Seeing a subtag\'s path when tagging', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await addAlbumTags(page, ['e2e electronic', 'e2e idm']);
+  await putUnder(page, 'e2e idm', 'e2e electronic');
+
+  await page.goto(`/app/library/albums/${albumId}`);
+  await page.getByTestId('album-detail-tags-edit').click();
+  await expect(page.locator('dialog[open]')).toBeVisible();
+  // The album already carries the tag, so take its chip off to see it suggested.
+  await page.locator('dialog[open]').locator('.badge', { hasText: 'e2e idm' }).getByRole('button').click();
+  await page.getByTestId('tags-input').fill('idm');
+  await expect(page.getByTestId('tag-option-path').filter({ hasText: 'e2e electronic / e2e idm' })).toBeVisible();
+
+  await deleteTag(page, 'e2e idm');
+  await deleteTag(page, 'e2e electronic');
+});
@@ -0,0 +1,126 @@
+import { test, expect, Page } from '@playwright/test';
+import { loginAs } from '../helpers/auth';
+
+// Scenarios from e2e/feat/tag_management.feature
+
+const userId = process.env.E2E_TEST_USER_ID;
+const albumId = process.env.E2E_TEST_ALBUM_ID;
+
+// addAlbumTags adds tags to the E2E album alongside the ones it already has.
+async function addAlbumTags(page: Page, names: string[]) {
+  await page.goto(`/app/library/albums/${albumId}`);
+  await page.getByTestId('album-detail-tags-edit').click();
+  await expect(page.locator('dialog[open]')).toBeVisible();
+  for (const name of names) {
+    await page.getByTestId('tags-input').fill(name);
+    await page.getByTestId('tags-input').press('Enter');
+  }
+  await page.getByTestId('tags-save').click();
+  await expect(page.locator('dialog[open]')).toHaveCount(0);
+}
+
+function tagRow(page: Page, name: string) {
+  return page.getByTestId('tag').filter({ has: page.getByTestId('tag-name').getByText(name, { exact: true }) });
+}
+
+async function deleteTag(page: Page, name: string) {
+  await page.goto('/app/tags');
+  const row = tagRow(page, name);
+  if (await row.count() === 0) {
+    return;
+  }
+  page.once('dialog', (dialog) => dialog.accept());
+  await row.getByTestId('tag-delete').click();
+  await expect(row).toHaveCount(0);
+}
+
+test('Seeing a tag\'s usage', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await addAlbumTags(page, ['e2e usage tag']);
+
+  await page.goto('/app/tags');
+  const row = tagRow(page, 'e2e usage tag');
+  await expect(row.getByTestId('tag-album-count')).toHaveText('1 album');
+  await expect(row.getByTestId('tag-album-count')).toHaveAttribute('href', /\/app\/library\/dashboard\?tag=/);
+
+  await deleteTag(page, 'e2e usage tag');
+});
+
+test('Renaming and recoloring a tag', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await addAlbumTags(page, ['e2e rename tag']);
+
+  await page.goto('/app/tags');
+  const row = tagRow(page, 'e2e rename tag');
+  await row.getByTestId('tag-edit').click();
+  await row.getByTestId('tag-name-input').fill('E2E Renamed Tag!');
+  await row.getByTestId('tag-group-select').selectOption({ label: 'Mood' });
+  await row.getByTestId('tag-color-select').selectOption('info');
+  await row.getByTestId('tag-save').click();
+
+  const renamed = tagRow(page, 'e2e renamed tag');
+  await expect(renamed).toHaveCount(1);
+  await expect(renamed.getByTestId('tag-group')).toHaveText('Mood');
+  await expect(renamed.getByTestId('tag-name')).toHaveClass(/badge-info/);
+
+  await deleteTag(page, 'e2e renamed tag');
+});
+
+test('Renaming a tag to a name another tag has', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await addAlbumTags(page, ['e2e taken a', 'e2e taken b']);
+
+  await page.goto('/app/tags');
+  const row = tagRow(page, 'e2e taken a');
+  await row.getByTestId('tag-edit').click();
+  await row.getByTestId('tag-name-input').fill('e2e taken b');
+  await row.getByTestId('tag-save').click();
+  await expect(row.getByTestId('tag-error')).toContainText('already has that name');
+
+  await deleteTag(page, 'e2e taken a');
+  await deleteTag(page, 'e2e taken b');
+});
+
+test('Merging tags', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await addAlbumTags(page, ['e2e merge from', 'e2e merge into']);
+
+  await page.goto('/app/tags');
+  const row = tagRow(page, 'e2e merge from');
+  await row.getByTestId('tag-merge').click();
+  await row.getByTestId('tag-merge-select').selectOption({ label: 'e2e merge into' });
+  page.once('dialog', (dialog) => dialog.accept());
+  await row.getByTestId('tag-merge-save').click();
+
+  await expect(tagRow(page, 'e2e merge from')).toHaveCount(0);
+  await expect(tagRow(page, 'e2e merge into').getByTestId('tag-album-count')).toHaveText('1 album');
+
+  await deleteTag(page, 'e2e merge into');
+});
+
+test('Deleting a tag', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await addAlbumTags(page, ['e2e delete tag']);
+
+  await deleteTag(page, 'e2e delete tag');
+
+  await page.reload();
+  await expect(tagRow(page, 'e2e delete tag')).toHaveCount(0);
+  await page.goto(`/app/library/albums/${albumId}`);
+  await expect(page.getByTestId('album-detail-tags')).not.toContainText('e2e delete tag');
+});
@@ -0,0 +1,69 @@
+import { test, expect, Page } from '@playwright/test';
+import { loginAs } from '../helpers/auth';
+
+// Scenarios from e2e/feat/tag_query.feature
+
+const userId = process.env.E2E_TEST_USER_ID;
+const albumId = process.env.E2E_TEST_ALBUM_ID;
+
+const tagName = 'e2e-query-tag';
+
+async function tagAlbum(page: Page) {
+  await page.goto(`/app/library/albums/${albumId}`);
+  await page.getByTestId('album-detail-tags-edit').click();
+  await expect(page.locator('dialog[open]')).toBeVisible();
+  await page.getByTestId('tags-input').fill(tagName);
+  await page.getByTestId('tags-input').press('Enter');
+  await page.getByTestId('tags-save').click();
+  await expect(page.locator('dialog[open]')).not.toBeVisible();
+}
+
+async function applyTagQuery(page: Page, query: string) {
+  await page.getByTestId('tag-chip').click();
+  await page.getByTestId('tag-query-input').fill(query);
+  await page.getByTestId('tag-query-apply').click();
+}
+
+test('Filtering the library by a tag', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await tagAlbum(page);
+
+  await page.goto('/app/library/dashboard');
+  await applyTagQuery(page, tagName.toUpperCase());
+
+  await expect(page.getByTestId('tag-chip')).toHaveText(tagName);
+  const rows = page.getByTestId('album-list-row');
+  await expect(rows.first()).toBeVisible();
+  await expect(rows.locator(`a[href="/app/library/albums/${albumId}"]`).first()).toBeVisible();
+});
+
+test('Excluding a tag', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await tagAlbum(page);
+
+  await page.goto('/app/library/dashboard');
+  await applyTagQuery(page, `not ${tagName}`);
+
+  await expect(page.getByTestId('tag-chip')).toHaveText(`NOT ${tagName}`);
+  await expect(page.locator(`[data-testid="album-list-row"] a[href="/app/library/albums/${albumId}"]`)).toHaveCount(0);
+});
+
+test("A query that can't be read", async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await tagAlbum(page);
+
+  await page.goto('/app/library/dashboard');
+  await applyTagQuery(page, `(${tagName} OR`);
+
+  await expect(page.locator('dialog[open]')).toBeVisible();
+  await expect(page.getByTestId('tag-query-error')).toContainText('invalid tag query');
+});
@@ -50,3 +50,25 @@ test('Saving tags closes the modal', async ({ context, page }) => {
 
   await expect(page.locator('dialog[open]')).not.toBeVisible();
 });
+
+test('Adding a suggested tag', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await page.goto(`/app/library/albums/${albumId}`);
+
+  await page.getByTestId('album-detail-tags-edit').click();
+  await expect(page.locator('dialog[open]')).toBeVisible();
+  await expect(page.getByTestId('tag-suggestions').locator('.loading')).toHaveCount(0, { timeout: 15000 });
+
+  const suggestion = page.getByTestId('tag-suggestion').first();
+  // Suggestions depend on the user's other albums and on MusicBrainz.
+  test.skip(await suggestion.count() === 0, 'no tag suggestions for the E2E album');
+
+  const name = await suggestion.getAttribute('data-name');
+  await suggestion.click();
+
+  await expect(page.locator('dialog[open]').locator('.badge', { hasText: name! })).toBeVisible();
+  await expect(suggestion).toBeHidden();
+});
@@ -0,0 +1,86 @@
+import { test, expect } from '@playwright/test';
+import { loginAs } from '../helpers/auth';
+
+// Scenarios from e2e/feat/track_marks.feature
+
+const userId = process.env.E2E_TEST_USER_ID;
+const albumId = process.env.E2E_TEST_ALBUM_ID;
+
+async function setMark(page: any, track: any, mark: 'standout' | 'skip') {
+  const responsePromise = page.waitForResponse((resp: any) => resp.url().includes('/track-mark'));
+  await track.getByTestId(`album-track-${mark}`).click();
+  await responsePromise;
+}
+
+// markFirstTrack leaves the first track marked as a standout, whatever its
+// state from earlier runs.
+async function markFirstTrack(page: any) {
+  await page.goto(`/app/library/albums/${albumId}`);
+  const track = page.getByTestId('album-track').first();
+  await expect(track).toBeVisible();
+  if (await track.getAttribute('data-mark') !== 'standout') {
+    await setMark(page, track, 'standout');
+  }
+  await expect(page.getByTestId('album-track').first()).toHaveAttribute('data-mark', 'standout');
+  return page.getByTestId('album-track').first();
+}
+
+test('Marking a track as a standout', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await page.goto(`/app/library/albums/${albumId}`);
+
+  const track = page.getByTestId('album-track').first();
+  if (await track.getAttribute('data-mark') === 'standout') {
+    await setMark(page, track, 'standout');
+  }
+  await setMark(page, page.getByTestId('album-track').first(), 'standout');
+
+  await expect(page.getByTestId('album-track').first()).toHaveAttribute('data-mark', 'standout');
+});
+
+test('Clicking an active mark clears it', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  const track = await markFirstTrack(page);
+
+  await setMark(page, track, 'standout');
+
+  await expect(page.getByTestId('album-track').first()).toHaveAttribute('data-mark', '');
+});
+
+test('Track marks pre-fill the questionnaire', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await markFirstTrack(page);
+
+  await page.getByTestId('album-detail-rating').locator('[hx-get*="rating-recommender"]').click();
+  await expect(page.getByTestId('rating-confirm')).toBeVisible();
+  await page.getByTestId('rating-confirm').locator('[hx-get*="questions"]').click();
+
+  const questionnaire = page.getByTestId('rating-questionnaire');
+  await expect(questionnaire).toBeVisible();
+  await expect(questionnaire.getByTestId('rating-question-hint')).toContainText('track marks');
+  await expect(questionnaire.locator('fieldset').first().locator('input[type="radio"]:checked')).toHaveCount(1);
+});
+
+test('Standout tracks appear on the top tracks page', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+  expect(albumId, 'E2E_TEST_ALBUM_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  const track = await markFirstTrack(page);
+  const title = (await track.locator('span').nth(1).textContent())!.trim();
+
+  await page.goto('/app/review/top-tracks');
+
+  const row = page.getByTestId('top-track').filter({ hasText: title }).first();
+  await expect(row).toBeVisible();
+  await expect(row.getByTestId('top-track-album-link')).toHaveAttribute('href', `/app/library/albums/${albumId}`);
+});
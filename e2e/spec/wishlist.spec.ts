@@ -0,0 +1,103 @@
+import { test, expect, Page } from '@playwright/test';
+import { loginAs } from '../helpers/auth';
+
+// Scenarios from e2e/feat/wishlist.feature
+
+const userId = process.env.E2E_TEST_USER_ID;
+
+async function addFromSearch(page: Page, query: string) {
+  await page.goto('/app/wishlist');
+  await page.getByTestId('wishlist-search').fill(query);
+  await page.getByTestId('wishlist-search-submit').click();
+
+  const result = page.getByTestId('wishlist-search-result').first();
+  await expect(result).toBeVisible();
+  const title = await result.getByTestId('wishlist-search-result-title').innerText();
+
+  await result.getByTestId('wishlist-search-add').click();
+  await expect(page.locator('dialog[open]')).toBeVisible();
+  await page.getByTestId('wishlist-add-save').click();
+  await expect(page.locator('dialog[open]')).toHaveCount(0);
+
+  return page.getByTestId('wishlist-item').filter({ hasText: title });
+}
+
+async function deleteItem(page: Page, item: ReturnType<Page['getByTestId']>) {
+  page.once('dialog', (dialog) => dialog.accept());
+  await item.getByTestId('wishlist-item-delete').click();
+  await expect(item).toHaveCount(0);
+}
+
+test('Wishlisting a recently spun album', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  await page.goto('/app/library/dashboard');
+
+  const add = page.getByTestId('carousel-wishlist-add').first();
+  if (!await add.isVisible()) {
+    // Every recently spun album is already in the library — skip
+    test.skip();
+    return;
+  }
+
+  await add.click();
+  await expect(page.locator('dialog[open]')).toBeVisible();
+  const title = await page.getByTestId('wishlist-add-title').innerText();
+  await page.getByTestId('wishlist-heard-from').fill('E2E friend');
+  await page.getByTestId('wishlist-max-price').fill('25');
+  await page.getByTestId('wishlist-add-save').click();
+  await expect(page.locator('dialog[open]')).toHaveCount(0);
+  await expect(page.getByTestId('carousel-wishlisted').first()).toBeVisible();
+
+  await page.goto('/app/wishlist');
+  const item = page.getByTestId('wishlist-item').filter({ hasText: title });
+  await expect(item.getByTestId('wishlist-item-heard-from')).toContainText('E2E friend');
+  await expect(item.getByTestId('wishlist-item-max-price')).toContainText('25.00');
+
+  await deleteItem(page, item);
+});
+
+test('Adding an album found on MusicBrainz', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  const item = await addFromSearch(page, 'Spiderland Slint');
+
+  await expect(item).toBeVisible();
+  await expect(item.getByTestId('wishlist-item-format')).toHaveText('Any format');
+
+  await deleteItem(page, item);
+});
+
+test('Editing a wishlist item', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  const item = await addFromSearch(page, 'Hex Bark Psychosis');
+
+  await item.getByTestId('wishlist-item-edit').click();
+  await item.getByTestId('wishlist-priority').selectOption({ label: 'High' });
+  await item.getByTestId('wishlist-format').selectOption({ label: 'Vinyl' });
+  await item.getByTestId('wishlist-item-save').click();
+
+  await expect(item.getByTestId('wishlist-item-priority')).toHaveText('High priority');
+  await expect(item.getByTestId('wishlist-item-format')).toHaveText('Vinyl');
+
+  await deleteItem(page, item);
+});
+
+test('Marking an item fulfilled by hand', async ({ context, page }) => {
+  expect(userId, 'E2E_TEST_USER_ID must be set').toBeTruthy();
+
+  await loginAs(context, userId!);
+  const item = await addFromSearch(page, 'Laughing Stock Talk Talk');
+  const title = await item.getByTestId('wishlist-item-title').innerText();
+
+  await item.getByTestId('wishlist-item-toggle-fulfilled').click();
+
+  const fulfilled = page.getByTestId('wishlist-fulfilled').getByTestId('wishlist-item').filter({ hasText: title });
+  await expect(fulfilled.getByTestId('wishlist-item-fulfilled')).toBeVisible();
+
+  await deleteItem(page, fulfilled);
+});
@@ -6,6 +6,9 @@ import (
 	"os"
 	"github.com/alecdray/wax/src/internal/core/app"
 	"github.com/alecdray/wax/src/internal/server"
+	// Users' timezones are loaded by name, and the runtime image has no
+	// zoneinfo of its own.
+	_ "time/tzdata"
 )
 
 func main() {
@@ -0,0 +1,114 @@
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"github.com/alecdray/wax/src/internal/bulk"
+	"github.com/alecdray/wax/src/internal/core/contextx"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"github.com/alecdray/wax/src/internal/core/httpx"
+)
+
+type HttpHandler struct {
+	bulkService *bulk.Service
+}
+
+func NewHttpHandler(bulkService *bulk.Service) *HttpHandler {
+	return &HttpHandler{
+		bulkService: bulkService,
+	}
+}
+
+func handleBulkError(ctx contextx.ContextX, w http.ResponseWriter, err error) {
+	props := httpx.HandleErrorResponseProps{
+		Status: http.StatusInternalServerError,
+		Err:    err,
+	}
+	switch {
+	case errors.Is(err, bulk.ErrBulkEditNotFound):
+		props.Status = http.StatusNotFound
+	case errors.Is(err, bulk.ErrInvalidBulkEdit):
+		props.Status = http.StatusUnprocessableEntity
+		props.Response = *httpx.NewErrorResponse().SetComponent(BulkEditError(err.Error()))
+	case errors.Is(err, bulk.ErrBulkEditUndone):
+		props.Status = http.StatusConflict
+		props.Response = *httpx.NewErrorResponse().SetComponent(BulkEditError(err.Error()))
+	}
+	httpx.HandleErrorResponse(ctx, w, props)
+}
+
+// ApplyBulkEdit applies one operation to the albums picked on the dashboard
+// and answers with its summary, triggering BulkEditAppliedEvent.
+func (h *HttpHandler) ApplyBulkEdit(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	edit, err := h.bulkService.Apply(ctx, userId, bulk.EditInput{
+		Operation: models.BulkOperation(r.Form.Get("operation")),
+		AlbumIDs:  r.Form["albumId"],
+		TagID:     r.Form.Get("tagId"),
+		Format:    models.ReleaseFormat(r.Form.Get("format")),
+		ShelfID:   r.Form.Get("shelfId"),
+	})
+	if err != nil {
+		handleBulkError(ctx, w, err)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", BulkEditAppliedEvent)
+	err = BulkEditResult(edit).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) UndoBulkEdit(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	edit, err := h.bulkService.Undo(ctx, userId, r.PathValue("editId"))
+	if err != nil {
+		handleBulkError(ctx, w, err)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", BulkEditAppliedEvent)
+	err = BulkEditResult(edit).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
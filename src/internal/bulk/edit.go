@@ -0,0 +1,205 @@
+package bulk
+
+import (
+	"errors"
+	"fmt"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"github.com/alecdray/wax/src/internal/core/db/sqlc"
+	"slices"
+	"strings"
+	"time"
+)
+
+// editMaxAlbums caps how many albums one edit can touch, comfortably above a
+// large import.
+const editMaxAlbums = 5000
+
+var (
+	ErrInvalidBulkEdit  = errors.New("invalid bulk edit")
+	ErrBulkEditNotFound = errors.New("bulk edit not found")
+	ErrBulkEditUndone   = errors.New("that edit has already been undone")
+)
+
+// Operations lists the bulk operations in display order.
+var Operations = []models.BulkOperation{
+	models.BulkOperationAddTag,
+	models.BulkOperationRemoveTag,
+	models.BulkOperationAddFormat,
+	models.BulkOperationAddToShelf,
+	models.BulkOperationClearRatings,
+}
+
+func OperationLabel(operation models.BulkOperation) string {
+	switch operation {
+	case models.BulkOperationAddTag:
+		return "Add tag"
+	case models.BulkOperationRemoveTag:
+		return "Remove tag"
+	case models.BulkOperationAddFormat:
+		return "Add format"
+	case models.BulkOperationAddToShelf:
+		return "Add to shelf"
+	case models.BulkOperationClearRatings:
+		return "Clear ratings"
+	default:
+		return string(operation)
+	}
+}
+
+// PhysicalFormats lists the formats an album can be marked as owned in by
+// hand; digital releases come from Spotify.
+var PhysicalFormats = []models.ReleaseFormat{
+	models.ReleaseFormatVinyl,
+	models.ReleaseFormatCD,
+	models.ReleaseFormatCassette,
+}
+
+func FormatLabel(format models.ReleaseFormat) string {
+	switch format {
+	case models.ReleaseFormatVinyl:
+		return "vinyl"
+	case models.ReleaseFormatCD:
+		return "CD"
+	case models.ReleaseFormatCassette:
+		return "cassette"
+	default:
+		return string(format)
+	}
+}
+
+// EditInput is one operation to apply to a set of albums. Tag operations
+// take TagID, adding a format takes Format and adding to a shelf takes
+// ShelfID.
+type EditInput struct {
+	Operation models.BulkOperation
+	AlbumIDs  []string
+	TagID     string
+	Format    models.ReleaseFormat
+	ShelfID   string
+}
+
+// Normalize drops blank and repeated album IDs, keeping the first of each.
+func (in EditInput) Normalize() EditInput {
+	albumIds := make([]string, 0, len(in.AlbumIDs))
+	seen := make(map[string]bool, len(in.AlbumIDs))
+	for _, albumId := range in.AlbumIDs {
+		albumId = strings.TrimSpace(albumId)
+		if albumId != "" && !seen[albumId] {
+			seen[albumId] = true
+			albumIds = append(albumIds, albumId)
+		}
+	}
+	in.AlbumIDs = albumIds
+	return in
+}
+
+func (in EditInput) Validate() error {
+	var errs []error
+	if len(in.AlbumIDs) == 0 {
+		errs = append(errs, errors.New("pick at least one album"))
+	} else if len(in.AlbumIDs) > editMaxAlbums {
+		errs = append(errs, fmt.Errorf("an edit can change at most %d albums", editMaxAlbums))
+	}
+	switch in.Operation {
+	case models.BulkOperationAddTag, models.BulkOperationRemoveTag:
+		if in.TagID == "" {
+			errs = append(errs, errors.New("pick a tag"))
+		}
+	case models.BulkOperationAddFormat:
+		if !slices.Contains(PhysicalFormats, in.Format) {
+			errs = append(errs, errors.New("pick vinyl, CD or cassette"))
+		}
+	case models.BulkOperationAddToShelf:
+		if in.ShelfID == "" {
+			errs = append(errs, errors.New("pick a shelf"))
+		}
+	case models.BulkOperationClearRatings:
+	default:
+		errs = append(errs, fmt.Errorf("%q isn't a bulk operation", in.Operation))
+	}
+	return errors.Join(errs...)
+}
+
+// EditDTO is an applied bulk edit, described for the user.
+type EditDTO struct {
+	ID        string
+	Operation models.BulkOperation
+	Summary   string
+	CreatedAt time.Time
+	Undone    bool
+}
+
+func newEditDTOFromModel(model sqlc.BulkEdit) EditDTO {
+	return EditDTO{
+		ID:        model.ID,
+		Operation: model.Operation,
+		Summary:   model.Summary,
+		CreatedAt: model.CreatedAt,
+		Undone:    model.UndoneAt.Valid,
+	}
+}
+
+// editOutcome is what an operation did: the thing it applied, such as a tag
+// name, how many albums it changed and, for ratings, how many entries it
+// deleted.
+type editOutcome struct {
+	subject string
+	changed int
+	entries int
+}
+
+// describeEdit sums up an edit over the selected albums, noting the ones it
+// left as they were.
+func describeEdit(operation models.BulkOperation, outcome editOutcome, selected int) string {
+	var summary string
+	switch operation {
+	case models.BulkOperationAddTag:
+		summary = fmt.Sprintf("Tagged %s %q", albumCount(outcome.changed), outcome.subject)
+	case models.BulkOperationRemoveTag:
+		summary = fmt.Sprintf("Took %q off %s", outcome.subject, albumCount(outcome.changed))
+	case models.BulkOperationAddFormat:
+		summary = fmt.Sprintf("Marked %s as owned on %s", albumCount(outcome.changed), outcome.subject)
+	case models.BulkOperationAddToShelf:
+		summary = fmt.Sprintf("Put %s on %q", albumCount(outcome.changed), outcome.subject)
+	case models.BulkOperationClearRatings:
+		summary = fmt.Sprintf("Cleared %s from %s", countNoun(outcome.entries, "rating", "ratings"), albumCount(outcome.changed))
+	}
+	if unchanged := selected - outcome.changed; unchanged > 0 {
+		summary += fmt.Sprintf("; %s left as %s", albumCount(unchanged), pick(unchanged, "it was", "they were"))
+	}
+	return summary
+}
+
+func albumCount(count int) string {
+	return countNoun(count, "album", "albums")
+}
+
+func countNoun(count int, singular, plural string) string {
+	return fmt.Sprintf("%d %s", count, pick(count, singular, plural))
+}
+
+func pick(count int, singular, plural string) string {
+	if count == 1 {
+		return singular
+	}
+	return plural
+}
+
+// editUndo records how to reverse a bulk edit: the rows it added, to be
+// deleted, and the rows it removed, to be put back.
+type editUndo struct {
+	AddedAlbumTagIDs    []string         `json:"addedAlbumTagIds,omitempty"`
+	RemovedAlbumTags    []sqlc.AlbumTag  `json:"removedAlbumTags,omitempty"`
+	AddedUserReleaseIDs []string         `json:"addedUserReleaseIds,omitempty"`
+	RemovedRatings      []ratingSnapshot `json:"removedRatings,omitempty"`
+	ShelfID             string           `json:"shelfId,omitempty"`
+	AddedShelfAlbumIDs  []string         `json:"addedShelfAlbumIds,omitempty"`
+}
+
+// ratingSnapshot is a deleted rating log entry with everything hung off it.
+type ratingSnapshot struct {
+	Entry     sqlc.AlbumRatingLog      `json:"entry"`
+	Scores    []sqlc.AlbumRatingScore  `json:"scores,omitempty"`
+	Answers   []sqlc.AlbumRatingAnswer `json:"answers,omitempty"`
+	ReviewIDs []string                 `json:"reviewIds,omitempty"`
+}
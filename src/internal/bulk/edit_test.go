@@ -0,0 +1,148 @@
+package bulk
+
+import (
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"strings"
+	"testing"
+)
+
+func TestEditInputNormalize(t *testing.T) {
+	input := EditInput{AlbumIDs: []string{" a1 ", "a2", "", "a1", "a3", "a2"}}.Normalize()
+	want := []string{"a1", "a2", "a3"}
+	if len(input.AlbumIDs) != len(want) {
+		t.Fatalf("expected %v, got %v", want, input.AlbumIDs)
+	}
+	for i := range want {
+		if input.AlbumIDs[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, input.AlbumIDs)
+		}
+	}
+}
+
+func TestEditInputValidate(t *testing.T) {
+	tooMany := make([]string, editMaxAlbums+1)
+	for i := range tooMany {
+		tooMany[i] = "a"
+	}
+
+	cases := []struct {
+		name    string
+		input   EditInput
+		wantErr string
+	}{
+		{
+			name:  "adding a tag",
+			input: EditInput{Operation: models.BulkOperationAddTag, AlbumIDs: []string{"a1"}, TagID: "t1"},
+		},
+		{
+			name:    "adding a tag needs a tag",
+			input:   EditInput{Operation: models.BulkOperationAddTag, AlbumIDs: []string{"a1"}},
+			wantErr: "pick a tag",
+		},
+		{
+			name:    "removing a tag needs a tag",
+			input:   EditInput{Operation: models.BulkOperationRemoveTag, AlbumIDs: []string{"a1"}},
+			wantErr: "pick a tag",
+		},
+		{
+			name:  "adding a physical format",
+			input: EditInput{Operation: models.BulkOperationAddFormat, AlbumIDs: []string{"a1"}, Format: models.ReleaseFormatCD},
+		},
+		{
+			name:    "digital isn't added by hand",
+			input:   EditInput{Operation: models.BulkOperationAddFormat, AlbumIDs: []string{"a1"}, Format: models.ReleaseFormatDigital},
+			wantErr: "pick vinyl, CD or cassette",
+		},
+		{
+			name:    "adding to a shelf needs a shelf",
+			input:   EditInput{Operation: models.BulkOperationAddToShelf, AlbumIDs: []string{"a1"}},
+			wantErr: "pick a shelf",
+		},
+		{
+			name:  "clearing ratings needs only albums",
+			input: EditInput{Operation: models.BulkOperationClearRatings, AlbumIDs: []string{"a1"}},
+		},
+		{
+			name:    "an edit needs an album",
+			input:   EditInput{Operation: models.BulkOperationClearRatings},
+			wantErr: "pick at least one album",
+		},
+		{
+			name:    "an edit is capped",
+			input:   EditInput{Operation: models.BulkOperationClearRatings, AlbumIDs: tooMany},
+			wantErr: "at most",
+		},
+		{
+			name:    "unknown operations are rejected",
+			input:   EditInput{Operation: "hide", AlbumIDs: []string{"a1"}},
+			wantErr: "isn't a bulk operation",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.input.Validate()
+			if c.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+				t.Fatalf("expected an error containing %q, got %v", c.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestDescribeEdit(t *testing.T) {
+	cases := []struct {
+		name      string
+		operation models.BulkOperation
+		outcome   editOutcome
+		selected  int
+		want      string
+	}{
+		{
+			name:      "adding a tag",
+			operation: models.BulkOperationAddTag,
+			outcome:   editOutcome{subject: "shoegaze", changed: 3},
+			selected:  3,
+			want:      `Tagged 3 albums "shoegaze"`,
+		},
+		{
+			name:      "removing a tag from some",
+			operation: models.BulkOperationRemoveTag,
+			outcome:   editOutcome{subject: "shoegaze", changed: 1},
+			selected:  3,
+			want:      `Took "shoegaze" off 1 album; 2 albums left as they were`,
+		},
+		{
+			name:      "adding a format",
+			operation: models.BulkOperationAddFormat,
+			outcome:   editOutcome{subject: "vinyl", changed: 2},
+			selected:  3,
+			want:      "Marked 2 albums as owned on vinyl; 1 album left as it was",
+		},
+		{
+			name:      "adding to a shelf",
+			operation: models.BulkOperationAddToShelf,
+			outcome:   editOutcome{subject: "Summer", changed: 2},
+			selected:  2,
+			want:      `Put 2 albums on "Summer"`,
+		},
+		{
+			name:      "clearing ratings",
+			operation: models.BulkOperationClearRatings,
+			outcome:   editOutcome{changed: 2, entries: 5},
+			selected:  2,
+			want:      "Cleared 5 ratings from 2 albums",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := describeEdit(c.operation, c.outcome, c.selected); got != c.want {
+				t.Errorf("expected %q, got %q", c.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,505 @@
+package bulk
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/alecdray/wax/src/internal/core/db"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"github.com/alecdray/wax/src/internal/core/db/sqlc"
+	"github.com/alecdray/wax/src/internal/review"
+	"github.com/alecdray/wax/src/internal/shelves"
+	"github.com/alecdray/wax/src/internal/tags"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Service struct {
+	db *db.DB
+}
+
+func NewService(db *db.DB) *Service {
+	return &Service{db: db}
+}
+
+// Apply applies one operation to a set of albums in a single transaction and
+// records how to undo it. Albums outside the user's library, and albums
+// already as the operation would leave them, are left alone.
+func (s *Service) Apply(ctx context.Context, userId string, input EditInput) (EditDTO, error) {
+	input = input.Normalize()
+	if err := input.Validate(); err != nil {
+		return EditDTO{}, fmt.Errorf("%w: %w", ErrInvalidBulkEdit, err)
+	}
+
+	var result EditDTO
+	err := s.db.WithTx(func(tx *db.DB) error {
+		albumIds, err := tx.Queries().GetLibraryAlbumIds(ctx, sqlc.GetLibraryAlbumIdsParams{
+			UserID:   userId,
+			AlbumIds: input.AlbumIDs,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get library albums: %w", err)
+		}
+
+		var undo editUndo
+		var outcome editOutcome
+		switch input.Operation {
+		case models.BulkOperationAddTag:
+			outcome, err = addTag(ctx, tx, userId, input.TagID, albumIds, &undo)
+		case models.BulkOperationRemoveTag:
+			outcome, err = removeTag(ctx, tx, userId, input.TagID, albumIds, &undo)
+		case models.BulkOperationAddFormat:
+			outcome, err = addFormat(ctx, tx, userId, input.Format, albumIds, &undo)
+		case models.BulkOperationAddToShelf:
+			outcome, err = addToShelf(ctx, tx, userId, input.ShelfID, albumIds, &undo)
+		case models.BulkOperationClearRatings:
+			outcome, err = clearRatings(ctx, tx, userId, albumIds, &undo)
+		}
+		if err != nil {
+			return err
+		}
+
+		undoJSON, err := json.Marshal(undo)
+		if err != nil {
+			return fmt.Errorf("failed to encode undo: %w", err)
+		}
+		model, err := tx.Queries().InsertBulkEdit(ctx, sqlc.InsertBulkEditParams{
+			ID:        uuid.NewString(),
+			UserID:    userId,
+			Operation: input.Operation,
+			Summary:   describeEdit(input.Operation, outcome, len(input.AlbumIDs)),
+			Undo:      string(undoJSON),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record bulk edit: %w", err)
+		}
+		result = newEditDTOFromModel(model)
+		return nil
+	})
+	if err != nil {
+		return EditDTO{}, err
+	}
+	return result, nil
+}
+
+// addTag puts one of the user's tags on the albums. On a tag from a group
+// that allows one tag per album, it replaces the albums' other tag from the
+// group, the same as tagging one album does.
+func addTag(ctx context.Context, tx *db.DB, userId, tagId string, albumIds []string, undo *editUndo) (editOutcome, error) {
+	tag, err := getTag(ctx, tx, userId, tagId)
+	if err != nil {
+		return editOutcome{}, err
+	}
+
+	tagged, err := tx.Queries().GetAlbumTagsByTagIdAndAlbumIds(ctx, sqlc.GetAlbumTagsByTagIdAndAlbumIdsParams{
+		UserID:   userId,
+		TagID:    tagId,
+		AlbumIds: albumIds,
+	})
+	if err != nil {
+		return editOutcome{}, fmt.Errorf("failed to get album tags: %w", err)
+	}
+	alreadyTagged := make(map[string]bool, len(tagged))
+	for _, albumTag := range tagged {
+		alreadyTagged[albumTag.AlbumID] = true
+	}
+
+	if tag.GroupID.Valid {
+		group, err := tx.Queries().GetTagGroup(ctx, sqlc.GetTagGroupParams{
+			ID:     tag.GroupID.String,
+			UserID: userId,
+		})
+		if err != nil {
+			return editOutcome{}, fmt.Errorf("failed to get tag group: %w", err)
+		}
+		if group.Exclusive {
+			inGroup, err := tx.Queries().GetAlbumTagsInGroupByAlbumIds(ctx, sqlc.GetAlbumTagsInGroupByAlbumIdsParams{
+				UserID:   userId,
+				GroupID:  tag.GroupID,
+				AlbumIds: albumIds,
+			})
+			if err != nil {
+				return editOutcome{}, fmt.Errorf("failed to get album tags: %w", err)
+			}
+			for _, albumTag := range inGroup {
+				if albumTag.TagID == tagId {
+					continue
+				}
+				err = tx.Queries().DeleteAlbumTagById(ctx, sqlc.DeleteAlbumTagByIdParams{
+					ID:     albumTag.ID,
+					UserID: userId,
+				})
+				if err != nil {
+					return editOutcome{}, fmt.Errorf("failed to replace album tag: %w", err)
+				}
+				undo.RemovedAlbumTags = append(undo.RemovedAlbumTags, albumTag)
+			}
+		}
+	}
+
+	outcome := editOutcome{subject: tag.Name}
+	for _, albumId := range albumIds {
+		if alreadyTagged[albumId] {
+			continue
+		}
+		albumTag, err := tx.Queries().CreateAlbumTag(ctx, sqlc.CreateAlbumTagParams{
+			ID:      uuid.NewString(),
+			UserID:  userId,
+			AlbumID: albumId,
+			TagID:   tagId,
+		})
+		if err != nil {
+			return editOutcome{}, fmt.Errorf("failed to tag album: %w", err)
+		}
+		undo.AddedAlbumTagIDs = append(undo.AddedAlbumTagIDs, albumTag.ID)
+		outcome.changed++
+	}
+	return outcome, nil
+}
+
+func removeTag(ctx context.Context, tx *db.DB, userId, tagId string, albumIds []string, undo *editUndo) (editOutcome, error) {
+	tag, err := getTag(ctx, tx, userId, tagId)
+	if err != nil {
+		return editOutcome{}, err
+	}
+
+	tagged, err := tx.Queries().GetAlbumTagsByTagIdAndAlbumIds(ctx, sqlc.GetAlbumTagsByTagIdAndAlbumIdsParams{
+		UserID:   userId,
+		TagID:    tagId,
+		AlbumIds: albumIds,
+	})
+	if err != nil {
+		return editOutcome{}, fmt.Errorf("failed to get album tags: %w", err)
+	}
+	for _, albumTag := range tagged {
+		err = tx.Queries().DeleteAlbumTagById(ctx, sqlc.DeleteAlbumTagByIdParams{
+			ID:     albumTag.ID,
+			UserID: userId,
+		})
+		if err != nil {
+			return editOutcome{}, fmt.Errorf("failed to untag album: %w", err)
+		}
+		undo.RemovedAlbumTags = append(undo.RemovedAlbumTags, albumTag)
+	}
+	return editOutcome{subject: tag.Name, changed: len(tagged)}, nil
+}
+
+// addFormat marks the albums as owned in a physical format.
+func addFormat(ctx context.Context, tx *db.DB, userId string, format models.ReleaseFormat, albumIds []string, undo *editUndo) (editOutcome, error) {
+	owned, err := tx.Queries().GetAlbumIdsWithFormat(ctx, sqlc.GetAlbumIdsWithFormatParams{
+		UserID:   userId,
+		Format:   format,
+		AlbumIds: albumIds,
+	})
+	if err != nil {
+		return editOutcome{}, fmt.Errorf("failed to get album formats: %w", err)
+	}
+	alreadyOwned := make(map[string]bool, len(owned))
+	for _, albumId := range owned {
+		alreadyOwned[albumId] = true
+	}
+
+	outcome := editOutcome{subject: FormatLabel(format)}
+	now := time.Now()
+	for _, albumId := range albumIds {
+		if alreadyOwned[albumId] {
+			continue
+		}
+		release, err := tx.Queries().GetOrCreateRelease(ctx, sqlc.GetOrCreateReleaseParams{
+			ID:      uuid.NewString(),
+			AlbumID: albumId,
+			Format:  format,
+		})
+		if err != nil {
+			return editOutcome{}, fmt.Errorf("failed to get or create release: %w", err)
+		}
+		userRelease, err := tx.Queries().UpsertUserRelease(ctx, sqlc.UpsertUserReleaseParams{
+			ID:        uuid.NewString(),
+			UserID:    userId,
+			ReleaseID: release.ID,
+			AddedAt:   now,
+		})
+		if err != nil {
+			return editOutcome{}, fmt.Errorf("failed to add release to library: %w", err)
+		}
+		undo.AddedUserReleaseIDs = append(undo.AddedUserReleaseIDs, userRelease.ID)
+		outcome.changed++
+	}
+	return outcome, nil
+}
+
+// addToShelf puts the albums on one of the user's hand-built shelves.
+func addToShelf(ctx context.Context, tx *db.DB, userId, shelfId string, albumIds []string, undo *editUndo) (editOutcome, error) {
+	shelf, err := tx.Queries().GetShelf(ctx, sqlc.GetShelfParams{
+		ID:     shelfId,
+		UserID: userId,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return editOutcome{}, fmt.Errorf("%w: the shelf doesn't exist", ErrInvalidBulkEdit)
+	} else if err != nil {
+		return editOutcome{}, fmt.Errorf("failed to get shelf: %w", err)
+	}
+	if shelf.Filter.String != "" {
+		return editOutcome{}, fmt.Errorf("%w: %w", ErrInvalidBulkEdit, shelves.ErrSmartShelf)
+	}
+
+	shelved, err := tx.Queries().GetShelfAlbumsByAlbumIds(ctx, sqlc.GetShelfAlbumsByAlbumIdsParams{
+		UserID:   userId,
+		AlbumIds: albumIds,
+	})
+	if err != nil {
+		return editOutcome{}, fmt.Errorf("failed to get album shelves: %w", err)
+	}
+	alreadyShelved := make(map[string]bool, len(shelved))
+	for _, row := range shelved {
+		if row.ShelfID == shelfId {
+			alreadyShelved[row.AlbumID] = true
+		}
+	}
+
+	undo.ShelfID = shelfId
+	outcome := editOutcome{subject: shelf.Name}
+	for _, albumId := range albumIds {
+		if alreadyShelved[albumId] {
+			continue
+		}
+		err = tx.Queries().AddShelfAlbum(ctx, sqlc.AddShelfAlbumParams{
+			ShelfID: shelfId,
+			AlbumID: albumId,
+		})
+		if err != nil {
+			return editOutcome{}, fmt.Errorf("failed to add album to shelf: %w", err)
+		}
+		undo.AddedShelfAlbumIDs = append(undo.AddedShelfAlbumIDs, albumId)
+		outcome.changed++
+	}
+	return outcome, nil
+}
+
+// clearRatings deletes every rating log entry on the albums, keeping a copy
+// of each entry's scores, answers and linked reviews to put back on undo.
+func clearRatings(ctx context.Context, tx *db.DB, userId string, albumIds []string, undo *editUndo) (editOutcome, error) {
+	entries, err := tx.Queries().GetAlbumRatingLogByAlbumIds(ctx, sqlc.GetAlbumRatingLogByAlbumIdsParams{
+		UserID:   userId,
+		AlbumIds: albumIds,
+	})
+	if err != nil {
+		return editOutcome{}, fmt.Errorf("failed to get rating log: %w", err)
+	}
+	if len(entries) == 0 {
+		return editOutcome{}, nil
+	}
+
+	entryIds := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		entryIds = append(entryIds, entry.ID)
+	}
+	scores, err := tx.Queries().GetAlbumRatingScoresByEntryIds(ctx, entryIds)
+	if err != nil {
+		return editOutcome{}, fmt.Errorf("failed to get rating scores: %w", err)
+	}
+	answers, err := tx.Queries().GetAlbumRatingAnswersByEntryIds(ctx, entryIds)
+	if err != nil {
+		return editOutcome{}, fmt.Errorf("failed to get rating answers: %w", err)
+	}
+	reviews, err := tx.Queries().GetAlbumReviewsByRatingLogIds(ctx, sqlc.GetAlbumReviewsByRatingLogIdsParams{
+		UserID:       userId,
+		RatingLogIds: entryIds,
+	})
+	if err != nil {
+		return editOutcome{}, fmt.Errorf("failed to get album reviews: %w", err)
+	}
+
+	snapshots := make(map[string]*ratingSnapshot, len(entries))
+	for _, entry := range entries {
+		snapshots[entry.ID] = &ratingSnapshot{Entry: entry}
+	}
+	for _, score := range scores {
+		snapshots[score.RatingLogID].Scores = append(snapshots[score.RatingLogID].Scores, score)
+	}
+	for _, answer := range answers {
+		snapshots[answer.RatingLogID].Answers = append(snapshots[answer.RatingLogID].Answers, answer)
+	}
+	for _, albumReview := range reviews {
+		snapshot := snapshots[albumReview.RatingLogID.String]
+		snapshot.ReviewIDs = append(snapshot.ReviewIDs, albumReview.ID)
+	}
+
+	rated := make(map[string]bool)
+	for _, entry := range entries {
+		if err := review.DeleteRatingEntryTx(ctx, tx, userId, entry.ID); err != nil {
+			return editOutcome{}, err
+		}
+		undo.RemovedRatings = append(undo.RemovedRatings, *snapshots[entry.ID])
+		rated[entry.AlbumID] = true
+	}
+	return editOutcome{changed: len(rated), entries: len(entries)}, nil
+}
+
+// getTag returns the tag an edit adds or removes.
+func getTag(ctx context.Context, tx *db.DB, userId, tagId string) (sqlc.Tag, error) {
+	tag, err := tags.GetTag(ctx, tx, userId, tagId)
+	if errors.Is(err, tags.ErrTagNotFound) {
+		return sqlc.Tag{}, fmt.Errorf("%w: the tag doesn't exist", ErrInvalidBulkEdit)
+	}
+	return tag, err
+}
+
+// Undo reverses a bulk edit in a single transaction: what it added comes off
+// and what it removed goes back. Album tags whose tag has been deleted since
+// stay gone, and revisit suggestions for restored ratings return with the
+// next refresh.
+func (s *Service) Undo(ctx context.Context, userId, editId string) (EditDTO, error) {
+	var result EditDTO
+	err := s.db.WithTx(func(tx *db.DB) error {
+		model, err := tx.Queries().GetBulkEdit(ctx, sqlc.GetBulkEditParams{
+			ID:     editId,
+			UserID: userId,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrBulkEditNotFound
+		} else if err != nil {
+			return fmt.Errorf("failed to get bulk edit: %w", err)
+		}
+		if model.UndoneAt.Valid {
+			return ErrBulkEditUndone
+		}
+
+		var undo editUndo
+		if err := json.Unmarshal([]byte(model.Undo), &undo); err != nil {
+			return fmt.Errorf("failed to decode undo: %w", err)
+		}
+		if err := undoEdit(ctx, tx, userId, undo); err != nil {
+			return err
+		}
+
+		err = tx.Queries().MarkBulkEditUndone(ctx, sqlc.MarkBulkEditUndoneParams{
+			ID:     editId,
+			UserID: userId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to mark bulk edit undone: %w", err)
+		}
+		result = newEditDTOFromModel(model)
+		result.Undone = true
+		return nil
+	})
+	if err != nil {
+		return EditDTO{}, err
+	}
+	return result, nil
+}
+
+func undoEdit(ctx context.Context, tx *db.DB, userId string, undo editUndo) error {
+	for _, albumTagId := range undo.AddedAlbumTagIDs {
+		err := tx.Queries().DeleteAlbumTagById(ctx, sqlc.DeleteAlbumTagByIdParams{
+			ID:     albumTagId,
+			UserID: userId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to untag album: %w", err)
+		}
+	}
+
+	if len(undo.RemovedAlbumTags) > 0 {
+		userTags, err := tx.Queries().GetTagsByUserId(ctx, userId)
+		if err != nil {
+			return fmt.Errorf("failed to get user tags: %w", err)
+		}
+		tagExists := make(map[string]bool, len(userTags))
+		for _, row := range userTags {
+			tagExists[row.Tag.ID] = true
+		}
+		for _, albumTag := range undo.RemovedAlbumTags {
+			if !tagExists[albumTag.TagID] {
+				continue
+			}
+			err = tx.Queries().RestoreAlbumTag(ctx, sqlc.RestoreAlbumTagParams{
+				ID:        albumTag.ID,
+				UserID:    userId,
+				AlbumID:   albumTag.AlbumID,
+				TagID:     albumTag.TagID,
+				CreatedAt: albumTag.CreatedAt,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to restore album tag: %w", err)
+			}
+		}
+	}
+
+	for _, userReleaseId := range undo.AddedUserReleaseIDs {
+		err := tx.Queries().DeleteUserRelease(ctx, sqlc.DeleteUserReleaseParams{
+			ID:     userReleaseId,
+			UserID: userId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to remove release from library: %w", err)
+		}
+	}
+
+	for _, snapshot := range undo.RemovedRatings {
+		if err := restoreRatingEntry(ctx, tx, userId, snapshot); err != nil {
+			return err
+		}
+	}
+
+	for _, albumId := range undo.AddedShelfAlbumIDs {
+		err := tx.Queries().DeleteShelfAlbum(ctx, sqlc.DeleteShelfAlbumParams{
+			ShelfID: undo.ShelfID,
+			AlbumID: albumId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to remove album from shelf: %w", err)
+		}
+	}
+	return nil
+}
+
+func restoreRatingEntry(ctx context.Context, tx *db.DB, userId string, snapshot ratingSnapshot) error {
+	entry := snapshot.Entry
+	err := tx.Queries().RestoreAlbumRatingLogEntry(ctx, sqlc.RestoreAlbumRatingLogEntryParams{
+		ID:        entry.ID,
+		UserID:    userId,
+		AlbumID:   entry.AlbumID,
+		Rating:    entry.Rating,
+		Note:      entry.Note,
+		CreatedAt: entry.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore rating log entry: %w", err)
+	}
+	for _, score := range snapshot.Scores {
+		err = tx.Queries().InsertAlbumRatingScore(ctx, sqlc.InsertAlbumRatingScoreParams{
+			RatingLogID: entry.ID,
+			Dimension:   score.Dimension,
+			Score:       score.Score,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to restore rating score: %w", err)
+		}
+	}
+	for _, answer := range snapshot.Answers {
+		err = tx.Queries().InsertAlbumRatingAnswer(ctx, sqlc.InsertAlbumRatingAnswerParams{
+			RatingLogID: entry.ID,
+			QuestionKey: answer.QuestionKey,
+			Value:       answer.Value,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to restore rating answer: %w", err)
+		}
+	}
+	for _, reviewId := range snapshot.ReviewIDs {
+		err = tx.Queries().SetAlbumReviewRatingLogId(ctx, sqlc.SetAlbumReviewRatingLogIdParams{
+			RatingLogID: sql.NullString{String: entry.ID, Valid: true},
+			ID:          reviewId,
+			UserID:      userId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to relink album review: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alecdray/wax/src/internal/core/db"
+	"github.com/alecdray/wax/src/internal/core/db/dbtest"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+)
+
+func newTestService(t *testing.T) (*Service, *db.DB) {
+	database := dbtest.New(t)
+	return NewService(database), database
+}
+
+// seedLibrary creates u1 with a digital copy of each album in their library,
+// and an album "elsewhere" that isn't.
+func seedLibrary(t *testing.T, database *db.DB, albumIds ...string) {
+	t.Helper()
+	dbtest.Exec(t, database, "INSERT INTO users (id, spotify_id) VALUES ('u1', 'u1')")
+	dbtest.Exec(t, database, "INSERT INTO albums (id, spotify_id, title) VALUES ('elsewhere', 'elsewhere', 'elsewhere')")
+	for _, albumId := range albumIds {
+		dbtest.Exec(t, database, "INSERT INTO albums (id, spotify_id, title) VALUES (?, ?, ?)", albumId, albumId, albumId)
+		dbtest.Exec(t, database, "INSERT INTO releases (id, album_id, format) VALUES (?, ?, 'digital')", albumId+"-digital", albumId)
+		dbtest.Exec(t, database, "INSERT INTO user_releases (id, user_id, release_id) VALUES (?, 'u1', ?)", "ur-"+albumId, albumId+"-digital")
+	}
+}
+
+func count(t *testing.T, database *db.DB, query string, args ...any) int {
+	t.Helper()
+	var n int
+	if err := database.Sql().QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatalf("failed to count %q: %v", query, err)
+	}
+	return n
+}
+
+func applyAndUndo(t *testing.T, service *Service, input EditInput, afterApply func()) {
+	t.Helper()
+	ctx := context.Background()
+	edit, err := service.Apply(ctx, "u1", input)
+	if err != nil {
+		t.Fatalf("failed to apply %s: %v", input.Operation, err)
+	}
+	afterApply()
+	if _, err := service.Undo(ctx, "u1", edit.ID); err != nil {
+		t.Fatalf("failed to undo %s: %v", input.Operation, err)
+	}
+	if _, err := service.Undo(ctx, "u1", edit.ID); !errors.Is(err, ErrBulkEditUndone) {
+		t.Errorf("expected a second undo to be refused, got %v", err)
+	}
+}
+
+func TestApply_AddTagReplacesTagsInAGroupAndUndoes(t *testing.T) {
+	service, database := newTestService(t)
+	seedLibrary(t, database, "a1", "a2", "a3")
+	dbtest.Exec(t, database, "INSERT INTO tag_groups (id, user_id, name, exclusive) VALUES ('mood', 'u1', 'Mood', true)")
+	dbtest.Exec(t, database, "INSERT INTO tags (id, user_id, name, group_id) VALUES ('calm', 'u1', 'calm', 'mood'), ('loud', 'u1', 'loud', 'mood')")
+	dbtest.Exec(t, database, "INSERT INTO album_tags (id, user_id, album_id, tag_id) VALUES ('at1', 'u1', 'a1', 'calm'), ('at2', 'u1', 'a2', 'loud')")
+
+	tagged := func(albumId, tagId string) int {
+		return count(t, database, "SELECT COUNT(*) FROM album_tags WHERE album_id = ? AND tag_id = ?", albumId, tagId)
+	}
+	applyAndUndo(t, service, EditInput{
+		Operation: models.BulkOperationAddTag,
+		AlbumIDs:  []string{"a1", "a2", "a3", "elsewhere"},
+		TagID:     "loud",
+	}, func() {
+		if tagged("a1", "calm") != 0 || tagged("a1", "loud") != 1 || tagged("a2", "loud") != 1 || tagged("a3", "loud") != 1 {
+			t.Errorf("expected loud to replace calm and tag every library album once")
+		}
+		if tagged("elsewhere", "loud") != 0 {
+			t.Errorf("expected an album outside the library left alone")
+		}
+	})
+
+	if tagged("a1", "calm") != 1 || tagged("a1", "loud") != 0 || tagged("a2", "loud") != 1 || tagged("a3", "loud") != 0 {
+		t.Errorf("expected undo to put calm back on a1 and keep a2's own tag")
+	}
+	if n := count(t, database, "SELECT COUNT(*) FROM album_tags WHERE id = 'at1'"); n != 1 {
+		t.Errorf("expected the replaced album tag restored as it was, got %d", n)
+	}
+}
+
+func TestApply_RemoveTagUndoes(t *testing.T) {
+	service, database := newTestService(t)
+	seedLibrary(t, database, "a1", "a2")
+	dbtest.Exec(t, database, "INSERT INTO tags (id, user_id, name) VALUES ('jazz', 'u1', 'jazz')")
+	dbtest.Exec(t, database, "INSERT INTO album_tags (id, user_id, album_id, tag_id) VALUES ('at1', 'u1', 'a1', 'jazz'), ('at2', 'u1', 'a2', 'jazz')")
+
+	jazzAlbums := func() int {
+		return count(t, database, "SELECT COUNT(*) FROM album_tags WHERE tag_id = 'jazz'")
+	}
+	applyAndUndo(t, service, EditInput{
+		Operation: models.BulkOperationRemoveTag,
+		AlbumIDs:  []string{"a1", "a2"},
+		TagID:     "jazz",
+	}, func() {
+		if n := jazzAlbums(); n != 0 {
+			t.Errorf("expected jazz taken off both albums, got %d left", n)
+		}
+	})
+	if n := jazzAlbums(); n != 2 {
+		t.Errorf("expected undo to put jazz back on both albums, got %d", n)
+	}
+
+	_, err := service.Apply(context.Background(), "u1", EditInput{Operation: models.BulkOperationRemoveTag, AlbumIDs: []string{"a1"}, TagID: "missing"})
+	if !errors.Is(err, ErrInvalidBulkEdit) {
+		t.Errorf("expected a missing tag to be rejected, got %v", err)
+	}
+}
+
+func TestApply_AddFormatUndoes(t *testing.T) {
+	service, database := newTestService(t)
+	seedLibrary(t, database, "a1", "a2")
+	dbtest.Exec(t, database, "INSERT INTO releases (id, album_id, format) VALUES ('a1-vinyl', 'a1', 'vinyl')")
+	dbtest.Exec(t, database, "INSERT INTO user_releases (id, user_id, release_id) VALUES ('ur-a1-vinyl', 'u1', 'a1-vinyl')")
+
+	onVinyl := func() int {
+		return count(t, database, `SELECT COUNT(*) FROM user_releases
+			JOIN releases ON releases.id = user_releases.release_id
+			WHERE user_releases.user_id = 'u1' AND releases.format = 'vinyl'`)
+	}
+	applyAndUndo(t, service, EditInput{
+		Operation: models.BulkOperationAddFormat,
+		AlbumIDs:  []string{"a1", "a2"},
+		Format:    models.ReleaseFormatVinyl,
+	}, func() {
+		if n := onVinyl(); n != 2 {
+			t.Errorf("expected both albums owned on vinyl once, got %d", n)
+		}
+	})
+	if n := onVinyl(); n != 1 {
+		t.Errorf("expected undo to keep only the vinyl already owned, got %d", n)
+	}
+	if n := count(t, database, "SELECT COUNT(*) FROM user_releases WHERE user_id = 'u1'"); n != 3 {
+		t.Errorf("expected the digital copies left in the library, got %d releases", n)
+	}
+}
+
+func TestApply_AddToShelfUndoes(t *testing.T) {
+	service, database := newTestService(t)
+	seedLibrary(t, database, "a1", "a2")
+	dbtest.Exec(t, database, "INSERT INTO shelves (id, user_id, name) VALUES ('s1', 'u1', 'Favourites')")
+	dbtest.Exec(t, database, "INSERT INTO shelves (id, user_id, name, filter) VALUES ('s2', 'u1', 'Smart', '{\"minRating\":8}')")
+	dbtest.Exec(t, database, "INSERT INTO shelf_albums (shelf_id, album_id) VALUES ('s1', 'a1')")
+
+	shelved := func() int {
+		return count(t, database, "SELECT COUNT(*) FROM shelf_albums WHERE shelf_id = 's1'")
+	}
+	applyAndUndo(t, service, EditInput{
+		Operation: models.BulkOperationAddToShelf,
+		AlbumIDs:  []string{"a1", "a2"},
+		ShelfID:   "s1",
+	}, func() {
+		if n := shelved(); n != 2 {
+			t.Errorf("expected both albums on the shelf once, got %d", n)
+		}
+	})
+	if n := count(t, database, "SELECT COUNT(*) FROM shelf_albums WHERE shelf_id = 's1' AND album_id = 'a1'"); n != 1 || shelved() != 1 {
+		t.Errorf("expected undo to leave only the album already on the shelf")
+	}
+
+	_, err := service.Apply(context.Background(), "u1", EditInput{Operation: models.BulkOperationAddToShelf, AlbumIDs: []string{"a1"}, ShelfID: "s2"})
+	if !errors.Is(err, ErrInvalidBulkEdit) {
+		t.Errorf("expected a smart shelf to be rejected, got %v", err)
+	}
+}
+
+func TestApply_ClearRatingsUndoesScoresAnswersAndReviews(t *testing.T) {
+	service, database := newTestService(t)
+	seedLibrary(t, database, "a1", "a2")
+	dbtest.Exec(t, database, `INSERT INTO album_rating_log (id, user_id, album_id, rating, note, created_at) VALUES
+		('r1', 'u1', 'a1', 8, 'grew on me', '2026-01-01 12:00:00'),
+		('r2', 'u1', 'a1', 6, NULL, '2025-06-01 12:00:00'),
+		('r3', 'u1', 'a2', 9, NULL, '2026-02-01 12:00:00')`)
+	dbtest.Exec(t, database, "INSERT INTO album_rating_scores (rating_log_id, dimension, score) VALUES ('r1', 'production', 7.5), ('r1', 'songwriting', 9)")
+	dbtest.Exec(t, database, "INSERT INTO album_rating_answers (rating_log_id, question_key, value) VALUES ('r1', 'replay', 4)")
+	dbtest.Exec(t, database, "INSERT INTO album_reviews (id, user_id, album_id, rating_log_id, body) VALUES ('rev1', 'u1', 'a1', 'r1', 'Slow burner.')")
+
+	rows := func(table string) int {
+		return count(t, database, "SELECT COUNT(*) FROM "+table)
+	}
+	applyAndUndo(t, service, EditInput{
+		Operation: models.BulkOperationClearRatings,
+		AlbumIDs:  []string{"a1"},
+	}, func() {
+		if n := count(t, database, "SELECT COUNT(*) FROM album_rating_log WHERE album_id = 'a1'"); n != 0 {
+			t.Errorf("expected a1's rating log cleared, got %d entries", n)
+		}
+		if rows("album_rating_scores") != 0 || rows("album_rating_answers") != 0 {
+			t.Errorf("expected the scores and answers deleted with their entries")
+		}
+		if n := count(t, database, "SELECT COUNT(*) FROM album_reviews WHERE rating_log_id IS NULL"); n != 1 {
+			t.Errorf("expected the review kept but unlinked")
+		}
+		if n := count(t, database, "SELECT COUNT(*) FROM album_rating_log WHERE album_id = 'a2'"); n != 1 {
+			t.Errorf("expected an album outside the edit left rated")
+		}
+	})
+
+	if n := count(t, database, "SELECT COUNT(*) FROM album_rating_log WHERE id = 'r1' AND rating = 8 AND note = 'grew on me' AND datetime(created_at) = '2026-01-01 12:00:00'"); n != 1 {
+		t.Errorf("expected the latest entry restored as it was")
+	}
+	if rows("album_rating_log") != 3 {
+		t.Errorf("expected every entry restored, got %d", rows("album_rating_log"))
+	}
+	if n := count(t, database, "SELECT COUNT(*) FROM album_rating_scores WHERE rating_log_id = 'r1' AND ((dimension = 'production' AND score = 7.5) OR (dimension = 'songwriting' AND score = 9))"); n != 2 {
+		t.Errorf("expected both scores restored, got %d", n)
+	}
+	if n := count(t, database, "SELECT COUNT(*) FROM album_rating_answers WHERE rating_log_id = 'r1' AND question_key = 'replay' AND value = 4"); n != 1 {
+		t.Errorf("expected the answer restored, got %d", n)
+	}
+	if n := count(t, database, "SELECT COUNT(*) FROM album_reviews WHERE id = 'rev1' AND rating_log_id = 'r1'"); n != 1 {
+		t.Errorf("expected the review linked to its entry again")
+	}
+}
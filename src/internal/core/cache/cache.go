@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// TTL is an in-memory cache whose entries expire a fixed time after they're
+// stored. It holds at most size entries: a full cache first drops what's
+// expired, then the oldest entry.
+type TTL[V any] struct {
+	ttl  time.Duration
+	size int
+	// Now is the clock entries are stored and expired by. Tests replace it.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]entry[V]
+}
+
+type entry[V any] struct {
+	value    V
+	storedAt time.Time
+}
+
+func NewTTL[V any](ttl time.Duration, size int) *TTL[V] {
+	return &TTL[V]{
+		ttl:     ttl,
+		size:    size,
+		Now:     time.Now,
+		entries: make(map[string]entry[V]),
+	}
+}
+
+// Get returns the value stored under a key, unless it's missing or expired.
+func (c *TTL[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || c.Now().Sub(entry.storedAt) >= c.ttl {
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+// Put stores a value under a key, replacing what was there.
+func (c *TTL[V]) Put(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.Now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.size {
+		for k, entry := range c.entries {
+			if now.Sub(entry.storedAt) >= c.ttl {
+				delete(c.entries, k)
+			}
+		}
+	}
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.size {
+		oldest := ""
+		for k, entry := range c.entries {
+			if oldest == "" || entry.storedAt.Before(c.entries[oldest].storedAt) {
+				oldest = k
+			}
+		}
+		delete(c.entries, oldest)
+	}
+	c.entries[key] = entry[V]{value: value, storedAt: now}
+}
@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTTL_Expires(t *testing.T) {
+	now := time.Now()
+	cache := NewTTL[[]string](time.Minute, 10)
+	cache.Now = func() time.Time { return now }
+
+	cache.Put("floyd", []string{"sp-1"})
+	if values, ok := cache.Get("floyd"); !ok || len(values) != 1 {
+		t.Fatalf("expected a cached value, got %v, %v", values, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := cache.Get("floyd"); ok {
+		t.Error("expected the value to have expired")
+	}
+}
+
+func TestTTL_EvictsWhenFull(t *testing.T) {
+	now := time.Now()
+	cache := NewTTL[[]string](time.Minute, 2)
+	cache.Now = func() time.Time { return now }
+
+	cache.Put("a", nil)
+	now = now.Add(time.Second)
+	cache.Put("b", nil)
+	now = now.Add(time.Second)
+	cache.Put("c", nil)
+
+	if _, ok := cache.Get("a"); ok {
+		t.Error("expected the oldest entry to be evicted")
+	}
+	for _, key := range []string{"b", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("expected %q to stay cached", key)
+		}
+	}
+
+	// Expired entries go before live ones.
+	now = now.Add(time.Minute - time.Second)
+	cache.Put("d", nil)
+	if _, ok := cache.Get("c"); !ok {
+		t.Error("expected the live entry to stay cached")
+	}
+	if _, ok := cache.Get("d"); !ok {
+		t.Error("expected the new entry to be cached")
+	}
+}
@@ -8,8 +8,6 @@ import (
 	"time"
 
 	"github.com/pressly/goose/v3"
-
-	_ "github.com/mattn/go-sqlite3"
 )
 
 const migrationsDir = "db/migrations"
@@ -20,7 +18,7 @@ type DB struct {
 }
 
 func NewDB(filepath string) (*DB, error) {
-	sqlDb, err := sql.Open("sqlite3", filepath)
+	sqlDb, err := sql.Open(driverName, filepath)
 	if err != nil {
 		return nil, err
 	}
@@ -29,6 +27,16 @@ func NewDB(filepath string) (*DB, error) {
 		return nil, err
 	}
 
+	// Library search is an FTS5 index, which go-sqlite3 only compiles in
+	// behind a build tag.
+	var fts5 bool
+	if err := sqlDb.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&fts5); err != nil {
+		return nil, err
+	}
+	if !fts5 {
+		return nil, errors.New("SQLite was built without FTS5; build with -tags sqlite_fts5")
+	}
+
 	sqlDb.SetMaxOpenConns(25)
 	sqlDb.SetMaxIdleConns(5)
 	sqlDb.SetConnMaxLifetime(5 * time.Minute)
@@ -0,0 +1,51 @@
+// Package dbtest opens migrated databases for tests.
+package dbtest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alecdray/wax/src/internal/core/db"
+)
+
+// New opens a migrated database in a temporary directory, closed when the
+// test ends. The migrations are read relative to the repository root, so the
+// test is moved there.
+func New(t *testing.T) *db.DB {
+	t.Helper()
+	t.Chdir(repoRoot(t))
+	database, err := db.NewDB(filepath.Join(t.TempDir(), "wax.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+// Exec runs a seeding statement, failing the test if it errors.
+func Exec(t *testing.T, database *db.DB, query string, args ...any) {
+	t.Helper()
+	if _, err := database.Sql().Exec(query, args...); err != nil {
+		t.Fatalf("failed to seed %q: %v", query, err)
+	}
+}
+
+// repoRoot finds the directory holding go.mod above the test's package.
+func repoRoot(t *testing.T) string {
+	t.Helper()
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			t.Fatal("failed to find the repository root")
+		}
+		dir = parent
+	}
+}
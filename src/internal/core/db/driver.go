@@ -0,0 +1,19 @@
+package db
+
+import (
+	"database/sql"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// driverName is go-sqlite3 with the app's SQL functions added to every
+// connection.
+const driverName = "sqlite3_wax"
+
+func init() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("contains_name", containsName, true)
+		},
+	})
+}
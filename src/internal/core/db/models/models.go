@@ -39,3 +39,106 @@ const (
 	ReleaseFormatCD       ReleaseFormat = "cd"
 	ReleaseFormatCassette ReleaseFormat = "cassette"
 )
+
+type ComparisonOutcome string
+
+const (
+	ComparisonOutcomeBetter ComparisonOutcome = "better"
+	ComparisonOutcomeWorse  ComparisonOutcome = "worse"
+	ComparisonOutcomeEqual  ComparisonOutcome = "equal"
+)
+
+type ReviewStatus string
+
+const (
+	ReviewStatusDraft     ReviewStatus = "draft"
+	ReviewStatusPublished ReviewStatus = "published"
+)
+
+type TrackMark string
+
+const (
+	TrackMarkStandout TrackMark = "standout"
+	TrackMarkSkip     TrackMark = "skip"
+)
+
+type RevisitReason string
+
+const (
+	RevisitReasonStale        RevisitReason = "stale"
+	RevisitReasonSingleListen RevisitReason = "single_listen"
+)
+
+type RanklistSource string
+
+const (
+	RanklistSourceManual RanklistSource = "manual"
+	RanklistSourceTag    RanklistSource = "tag"
+	RanklistSourceRating RanklistSource = "rating"
+)
+
+type WishlistFormat string
+
+const (
+	WishlistFormatAny      WishlistFormat = "any"
+	WishlistFormatVinyl    WishlistFormat = "vinyl"
+	WishlistFormatCD       WishlistFormat = "cd"
+	WishlistFormatCassette WishlistFormat = "cassette"
+	WishlistFormatDigital  WishlistFormat = "digital"
+)
+
+type AlbumLinkKind string
+
+const (
+	AlbumLinkKindInfluencedBy   AlbumLinkKind = "influenced_by"
+	AlbumLinkKindSoundsLike     AlbumLinkKind = "sounds_like"
+	AlbumLinkKindSequelTo       AlbumLinkKind = "sequel_to"
+	AlbumLinkKindSameSession    AlbumLinkKind = "same_session"
+	AlbumLinkKindIntroducedMeTo AlbumLinkKind = "introduced_me_to"
+)
+
+type AlbumPersonDirection string
+
+const (
+	AlbumPersonDirectionIntroducedBy AlbumPersonDirection = "introduced_by"
+	AlbumPersonDirectionSharedWith   AlbumPersonDirection = "shared_with"
+)
+
+type ArtistRole string
+
+const (
+	ArtistRolePrimary  ArtistRole = "primary"
+	ArtistRoleFeatured ArtistRole = "featured"
+)
+
+type ArtistAliasSource string
+
+const (
+	ArtistAliasSourceSpotify     ArtistAliasSource = "spotify"
+	ArtistAliasSourceMusicBrainz ArtistAliasSource = "musicbrainz"
+	ArtistAliasSourceDiscogs     ArtistAliasSource = "discogs"
+	ArtistAliasSourceManual      ArtistAliasSource = "manual"
+)
+
+type TagColor string
+
+const (
+	TagColorNone      TagColor = "none"
+	TagColorPrimary   TagColor = "primary"
+	TagColorSecondary TagColor = "secondary"
+	TagColorAccent    TagColor = "accent"
+	TagColorInfo      TagColor = "info"
+	TagColorSuccess   TagColor = "success"
+	TagColorWarning   TagColor = "warning"
+	TagColorError     TagColor = "error"
+)
+
+type BulkOperation string
+
+const (
+	BulkOperationAddTag       BulkOperation = "add_tag"
+	BulkOperationRemoveTag    BulkOperation = "remove_tag"
+	BulkOperationAddFormat    BulkOperation = "add_format"
+	BulkOperationClearRatings BulkOperation = "clear_ratings"
+	BulkOperationAddToShelf   BulkOperation = "add_to_shelf"
+)
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// containsName reports whether a list of names, such as an album's credited
+// artists however they're joined, has name as a whole word or words. Case is
+// ignored, so "can" is in "Can & Damo Suzuki" but not in "Duncan Sheik".
+func containsName(names string, name string) bool {
+	names = strings.ToLower(names)
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return false
+	}
+	for offset := 0; offset < len(names); {
+		i := strings.Index(names[offset:], name)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(name)
+		before, _ := utf8.DecodeLastRuneInString(names[:start])
+		after, _ := utf8.DecodeRuneInString(names[end:])
+		if !isNameRune(before) && !isNameRune(after) {
+			return true
+		}
+		_, size := utf8.DecodeRuneInString(names[start:])
+		offset = start + size
+	}
+	return false
+}
+
+func isNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
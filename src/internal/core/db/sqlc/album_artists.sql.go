@@ -8,16 +8,29 @@ package sqlc
 import (
 	"context"
 	"strings"
+
+	"github.com/alecdray/wax/src/internal/core/db/models"
 )
 
+const deleteAlbumArtistsByArtistId = `-- name: DeleteAlbumArtistsByArtistId :exec
+DELETE FROM album_artists WHERE artist_id = ?
+`
+
+func (q *Queries) DeleteAlbumArtistsByArtistId(ctx context.Context, artistID string) error {
+	_, err := q.db.ExecContext(ctx, deleteAlbumArtistsByArtistId, artistID)
+	return err
+}
+
 const getAlbumArtistByAlbumId = `-- name: GetAlbumArtistByAlbumId :many
-SELECT album_artists.album_id, artists.id, artists.spotify_id, artists.name, artists.created_at, artists.deleted_at FROM album_artists
+SELECT album_artists.album_id, album_artists.role, artists.id, artists.spotify_id, artists.name, artists.created_at, artists.deleted_at, artists.sort_name FROM album_artists
 JOIN artists ON album_artists.artist_id = artists.id
 WHERE album_id = ?
+ORDER BY album_artists.position, album_artists.rowid
 `
 
 type GetAlbumArtistByAlbumIdRow struct {
 	AlbumID string
+	Role    models.ArtistRole
 	Artist  Artist
 }
 
@@ -32,11 +45,13 @@ func (q *Queries) GetAlbumArtistByAlbumId(ctx context.Context, albumID string) (
 		var i GetAlbumArtistByAlbumIdRow
 		if err := rows.Scan(
 			&i.AlbumID,
+			&i.Role,
 			&i.Artist.ID,
 			&i.Artist.SpotifyID,
 			&i.Artist.Name,
 			&i.Artist.CreatedAt,
 			&i.Artist.DeletedAt,
+			&i.Artist.SortName,
 		); err != nil {
 			return nil, err
 		}
@@ -52,13 +67,15 @@ func (q *Queries) GetAlbumArtistByAlbumId(ctx context.Context, albumID string) (
 }
 
 const getAlbumArtistsByAlbumIds = `-- name: GetAlbumArtistsByAlbumIds :many
-SELECT album_artists.album_id, artists.id, artists.spotify_id, artists.name, artists.created_at, artists.deleted_at FROM album_artists
+SELECT album_artists.album_id, album_artists.role, artists.id, artists.spotify_id, artists.name, artists.created_at, artists.deleted_at, artists.sort_name FROM album_artists
 JOIN artists ON album_artists.artist_id = artists.id
 WHERE album_id IN (/*SLICE:album_ids*/?)
+ORDER BY album_artists.position, album_artists.rowid
 `
 
 type GetAlbumArtistsByAlbumIdsRow struct {
 	AlbumID string
+	Role    models.ArtistRole
 	Artist  Artist
 }
 
@@ -83,11 +100,101 @@ func (q *Queries) GetAlbumArtistsByAlbumIds(ctx context.Context, albumIds []stri
 		var i GetAlbumArtistsByAlbumIdsRow
 		if err := rows.Scan(
 			&i.AlbumID,
+			&i.Role,
 			&i.Artist.ID,
 			&i.Artist.SpotifyID,
 			&i.Artist.Name,
 			&i.Artist.CreatedAt,
 			&i.Artist.DeletedAt,
+			&i.Artist.SortName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getArtistAlbumPlays = `-- name: GetArtistAlbumPlays :many
+SELECT albums.id, albums.spotify_id, albums.title, COUNT(track_plays.id) AS plays
+FROM album_artists
+JOIN albums ON albums.id = album_artists.album_id
+LEFT JOIN track_plays ON track_plays.album_id = albums.id AND track_plays.user_id = ?
+WHERE album_artists.artist_id = ?
+GROUP BY albums.id
+`
+
+type GetArtistAlbumPlaysParams struct {
+	UserID   string
+	ArtistID string
+}
+
+type GetArtistAlbumPlaysRow struct {
+	ID        string
+	SpotifyID string
+	Title     string
+	Plays     int64
+}
+
+func (q *Queries) GetArtistAlbumPlays(ctx context.Context, arg GetArtistAlbumPlaysParams) ([]GetArtistAlbumPlaysRow, error) {
+	rows, err := q.db.QueryContext(ctx, getArtistAlbumPlays, arg.UserID, arg.ArtistID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetArtistAlbumPlaysRow
+	for rows.Next() {
+		var i GetArtistAlbumPlaysRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.SpotifyID,
+			&i.Title,
+			&i.Plays,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLibraryArtists = `-- name: GetLibraryArtists :many
+SELECT DISTINCT artists.id, artists.spotify_id, artists.name, artists.created_at, artists.deleted_at, artists.sort_name FROM artists
+JOIN album_artists ON album_artists.artist_id = artists.id
+JOIN releases ON releases.album_id = album_artists.album_id
+JOIN user_releases ON user_releases.release_id = releases.id
+WHERE user_releases.user_id = ?
+ORDER BY artists.name
+`
+
+func (q *Queries) GetLibraryArtists(ctx context.Context, userID string) ([]Artist, error) {
+	rows, err := q.db.QueryContext(ctx, getLibraryArtists, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Artist
+	for rows.Next() {
+		var i Artist
+		if err := rows.Scan(
+			&i.ID,
+			&i.SpotifyID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.DeletedAt,
+			&i.SortName,
 		); err != nil {
 			return nil, err
 		}
@@ -103,20 +210,51 @@ func (q *Queries) GetAlbumArtistsByAlbumIds(ctx context.Context, albumIds []stri
 }
 
 const getOrCreateAlbumArtist = `-- name: GetOrCreateAlbumArtist :one
-INSERT INTO album_artists (album_id, artist_id) VALUES (?, ?)
+INSERT INTO album_artists (album_id, artist_id, position, role) VALUES (?, ?, ?, ?)
 ON CONFLICT (album_id, artist_id)
-DO UPDATE SET album_id = album_id
-RETURNING album_id, artist_id
+DO UPDATE SET position = excluded.position, role = excluded.role
+RETURNING album_id, artist_id, position, role
 `
 
 type GetOrCreateAlbumArtistParams struct {
 	AlbumID  string
 	ArtistID string
+	Position int64
+	Role     models.ArtistRole
 }
 
 func (q *Queries) GetOrCreateAlbumArtist(ctx context.Context, arg GetOrCreateAlbumArtistParams) (AlbumArtist, error) {
-	row := q.db.QueryRowContext(ctx, getOrCreateAlbumArtist, arg.AlbumID, arg.ArtistID)
+	row := q.db.QueryRowContext(ctx, getOrCreateAlbumArtist,
+		arg.AlbumID,
+		arg.ArtistID,
+		arg.Position,
+		arg.Role,
+	)
 	var i AlbumArtist
-	err := row.Scan(&i.AlbumID, &i.ArtistID)
+	err := row.Scan(
+		&i.AlbumID,
+		&i.ArtistID,
+		&i.Position,
+		&i.Role,
+	)
 	return i, err
 }
+
+const moveAlbumArtists = `-- name: MoveAlbumArtists :exec
+INSERT INTO album_artists (album_id, artist_id, position, role)
+SELECT album_id, ?, position, role FROM album_artists WHERE artist_id = ?
+ON CONFLICT (album_id, artist_id)
+DO UPDATE SET
+    position = MIN(album_artists.position, excluded.position),
+    role = CASE WHEN excluded.role = 'primary' THEN 'primary' ELSE album_artists.role END
+`
+
+type MoveAlbumArtistsParams struct {
+	ArtistID    string
+	DuplicateID string
+}
+
+func (q *Queries) MoveAlbumArtists(ctx context.Context, arg MoveAlbumArtistsParams) error {
+	_, err := q.db.ExecContext(ctx, moveAlbumArtists, arg.ArtistID, arg.DuplicateID)
+	return err
+}
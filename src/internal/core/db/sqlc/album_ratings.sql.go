@@ -8,9 +8,28 @@ package sqlc
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"time"
 )
 
+const deleteAlbumRatingAnswersByEntryId = `-- name: DeleteAlbumRatingAnswersByEntryId :exec
+DELETE FROM album_rating_answers
+WHERE rating_log_id IN (
+    SELECT id FROM album_rating_log
+    WHERE album_rating_log.id = ? AND album_rating_log.user_id = ?
+)
+`
+
+type DeleteAlbumRatingAnswersByEntryIdParams struct {
+	ID     string
+	UserID string
+}
+
+func (q *Queries) DeleteAlbumRatingAnswersByEntryId(ctx context.Context, arg DeleteAlbumRatingAnswersByEntryIdParams) error {
+	_, err := q.db.ExecContext(ctx, deleteAlbumRatingAnswersByEntryId, arg.ID, arg.UserID)
+	return err
+}
+
 const deleteAlbumRatingLogEntry = `-- name: DeleteAlbumRatingLogEntry :exec
 DELETE FROM album_rating_log
 WHERE id = ? AND user_id = ?
@@ -26,10 +45,155 @@ func (q *Queries) DeleteAlbumRatingLogEntry(ctx context.Context, arg DeleteAlbum
 	return err
 }
 
+const deleteAlbumRatingScoresByEntryId = `-- name: DeleteAlbumRatingScoresByEntryId :exec
+DELETE FROM album_rating_scores
+WHERE rating_log_id IN (
+    SELECT id FROM album_rating_log
+    WHERE album_rating_log.id = ? AND album_rating_log.user_id = ?
+)
+`
+
+type DeleteAlbumRatingScoresByEntryIdParams struct {
+	ID     string
+	UserID string
+}
+
+func (q *Queries) DeleteAlbumRatingScoresByEntryId(ctx context.Context, arg DeleteAlbumRatingScoresByEntryIdParams) error {
+	_, err := q.db.ExecContext(ctx, deleteAlbumRatingScoresByEntryId, arg.ID, arg.UserID)
+	return err
+}
+
+const getAlbumRatingAnswersByEntryIds = `-- name: GetAlbumRatingAnswersByEntryIds :many
+SELECT rating_log_id, question_key, value FROM album_rating_answers
+WHERE rating_log_id IN (/*SLICE:entry_ids*/?)
+`
+
+func (q *Queries) GetAlbumRatingAnswersByEntryIds(ctx context.Context, entryIds []string) ([]AlbumRatingAnswer, error) {
+	query := getAlbumRatingAnswersByEntryIds
+	var queryParams []interface{}
+	if len(entryIds) > 0 {
+		for _, v := range entryIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:entry_ids*/?", strings.Repeat(",?", len(entryIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:entry_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AlbumRatingAnswer
+	for rows.Next() {
+		var i AlbumRatingAnswer
+		if err := rows.Scan(&i.RatingLogID, &i.QuestionKey, &i.Value); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAlbumRatingLogByAlbumIds = `-- name: GetAlbumRatingLogByAlbumIds :many
+SELECT id, user_id, album_id, rating, note, created_at FROM album_rating_log
+WHERE user_id = ? AND album_id IN (/*SLICE:album_ids*/?)
+`
+
+type GetAlbumRatingLogByAlbumIdsParams struct {
+	UserID   string
+	AlbumIds []string
+}
+
+func (q *Queries) GetAlbumRatingLogByAlbumIds(ctx context.Context, arg GetAlbumRatingLogByAlbumIdsParams) ([]AlbumRatingLog, error) {
+	query := getAlbumRatingLogByAlbumIds
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.UserID)
+	if len(arg.AlbumIds) > 0 {
+		for _, v := range arg.AlbumIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:album_ids*/?", strings.Repeat(",?", len(arg.AlbumIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:album_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AlbumRatingLog
+	for rows.Next() {
+		var i AlbumRatingLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.AlbumID,
+			&i.Rating,
+			&i.Note,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAlbumRatingScoresByEntryIds = `-- name: GetAlbumRatingScoresByEntryIds :many
+SELECT rating_log_id, dimension, score FROM album_rating_scores
+WHERE rating_log_id IN (/*SLICE:entry_ids*/?)
+`
+
+func (q *Queries) GetAlbumRatingScoresByEntryIds(ctx context.Context, entryIds []string) ([]AlbumRatingScore, error) {
+	query := getAlbumRatingScoresByEntryIds
+	var queryParams []interface{}
+	if len(entryIds) > 0 {
+		for _, v := range entryIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:entry_ids*/?", strings.Repeat(",?", len(entryIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:entry_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AlbumRatingScore
+	for rows.Next() {
+		var i AlbumRatingScore
+		if err := rows.Scan(&i.RatingLogID, &i.Dimension, &i.Score); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getLatestUserAlbumRating = `-- name: GetLatestUserAlbumRating :one
 SELECT id, user_id, album_id, rating, note, created_at FROM album_rating_log
 WHERE user_id = ? AND album_id = ?
-ORDER BY created_at DESC
+ORDER BY created_at DESC, rowid DESC
 LIMIT 1
 `
 
@@ -98,6 +262,65 @@ func (q *Queries) GetLatestUserAlbumRatings(ctx context.Context, arg GetLatestUs
 	return items, nil
 }
 
+const getLatestUserAlbumRatingsByAlbumIds = `-- name: GetLatestUserAlbumRatingsByAlbumIds :many
+SELECT arl.id, arl.user_id, arl.album_id, arl.rating, arl.note, arl.created_at FROM album_rating_log arl
+JOIN (
+    SELECT arl2.album_id, MAX(arl2.created_at) AS max_created_at
+    FROM album_rating_log arl2
+    WHERE arl2.user_id = ? AND arl2.album_id IN (/*SLICE:album_ids*/?)
+    GROUP BY arl2.album_id
+) latest ON arl.album_id = latest.album_id AND arl.created_at = latest.max_created_at
+WHERE arl.user_id = ?
+`
+
+type GetLatestUserAlbumRatingsByAlbumIdsParams struct {
+	UserID   string
+	AlbumIds []string
+	UserID_2 string
+}
+
+func (q *Queries) GetLatestUserAlbumRatingsByAlbumIds(ctx context.Context, arg GetLatestUserAlbumRatingsByAlbumIdsParams) ([]AlbumRatingLog, error) {
+	query := getLatestUserAlbumRatingsByAlbumIds
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.UserID)
+	if len(arg.AlbumIds) > 0 {
+		for _, v := range arg.AlbumIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:album_ids*/?", strings.Repeat(",?", len(arg.AlbumIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:album_ids*/?", "NULL", 1)
+	}
+	queryParams = append(queryParams, arg.UserID_2)
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AlbumRatingLog
+	for rows.Next() {
+		var i AlbumRatingLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.AlbumID,
+			&i.Rating,
+			&i.Note,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUnratedAlbums = `-- name: GetUnratedAlbums :many
 SELECT albums.id, albums.spotify_id, albums.title, albums.created_at, albums.deleted_at, albums.image_url,
     COALESCE((
@@ -176,7 +399,7 @@ func (q *Queries) GetUnratedAlbums(ctx context.Context, arg GetUnratedAlbumsPara
 const getUserAlbumRatingLog = `-- name: GetUserAlbumRatingLog :many
 SELECT id, user_id, album_id, rating, note, created_at FROM album_rating_log
 WHERE user_id = ? AND album_id = ?
-ORDER BY created_at DESC
+ORDER BY created_at DESC, rowid DESC
 `
 
 type GetUserAlbumRatingLogParams struct {
@@ -214,6 +437,74 @@ func (q *Queries) GetUserAlbumRatingLog(ctx context.Context, arg GetUserAlbumRat
 	return items, nil
 }
 
+const getUserRatingLogWithAlbums = `-- name: GetUserRatingLogWithAlbums :many
+SELECT album_rating_log.id, album_rating_log.album_id, album_rating_log.rating, album_rating_log.created_at,
+    albums.title AS album_title,
+    COALESCE((
+        SELECT GROUP_CONCAT(a.name, ', ')
+        FROM (SELECT DISTINCT ar.id, ar.name FROM album_artists aa JOIN artists ar ON ar.id = aa.artist_id WHERE aa.album_id = albums.id) AS a
+    ), '') as artist_names
+FROM album_rating_log
+JOIN albums ON albums.id = album_rating_log.album_id
+WHERE album_rating_log.user_id = ?
+ORDER BY album_rating_log.created_at, album_rating_log.rowid
+`
+
+type GetUserRatingLogWithAlbumsRow struct {
+	ID          string
+	AlbumID     string
+	Rating      float64
+	CreatedAt   time.Time
+	AlbumTitle  string
+	ArtistNames interface{}
+}
+
+func (q *Queries) GetUserRatingLogWithAlbums(ctx context.Context, userID string) ([]GetUserRatingLogWithAlbumsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserRatingLogWithAlbums, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserRatingLogWithAlbumsRow
+	for rows.Next() {
+		var i GetUserRatingLogWithAlbumsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AlbumID,
+			&i.Rating,
+			&i.CreatedAt,
+			&i.AlbumTitle,
+			&i.ArtistNames,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertAlbumRatingAnswer = `-- name: InsertAlbumRatingAnswer :exec
+INSERT INTO album_rating_answers (rating_log_id, question_key, value)
+VALUES (?, ?, ?)
+`
+
+type InsertAlbumRatingAnswerParams struct {
+	RatingLogID string
+	QuestionKey string
+	Value       int64
+}
+
+func (q *Queries) InsertAlbumRatingAnswer(ctx context.Context, arg InsertAlbumRatingAnswerParams) error {
+	_, err := q.db.ExecContext(ctx, insertAlbumRatingAnswer, arg.RatingLogID, arg.QuestionKey, arg.Value)
+	return err
+}
+
 const insertAlbumRatingLogEntry = `-- name: InsertAlbumRatingLogEntry :one
 INSERT INTO album_rating_log (id, user_id, album_id, rating, note, created_at)
 VALUES (?, ?, ?, ?, ?, current_timestamp)
@@ -247,3 +538,45 @@ func (q *Queries) InsertAlbumRatingLogEntry(ctx context.Context, arg InsertAlbum
 	)
 	return i, err
 }
+
+const insertAlbumRatingScore = `-- name: InsertAlbumRatingScore :exec
+INSERT INTO album_rating_scores (rating_log_id, dimension, score)
+VALUES (?, ?, ?)
+`
+
+type InsertAlbumRatingScoreParams struct {
+	RatingLogID string
+	Dimension   string
+	Score       float64
+}
+
+func (q *Queries) InsertAlbumRatingScore(ctx context.Context, arg InsertAlbumRatingScoreParams) error {
+	_, err := q.db.ExecContext(ctx, insertAlbumRatingScore, arg.RatingLogID, arg.Dimension, arg.Score)
+	return err
+}
+
+const restoreAlbumRatingLogEntry = `-- name: RestoreAlbumRatingLogEntry :exec
+INSERT INTO album_rating_log (id, user_id, album_id, rating, note, created_at)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type RestoreAlbumRatingLogEntryParams struct {
+	ID        string
+	UserID    string
+	AlbumID   string
+	Rating    float64
+	Note      sql.NullString
+	CreatedAt time.Time
+}
+
+func (q *Queries) RestoreAlbumRatingLogEntry(ctx context.Context, arg RestoreAlbumRatingLogEntryParams) error {
+	_, err := q.db.ExecContext(ctx, restoreAlbumRatingLogEntry,
+		arg.ID,
+		arg.UserID,
+		arg.AlbumID,
+		arg.Rating,
+		arg.Note,
+		arg.CreatedAt,
+	)
+	return err
+}
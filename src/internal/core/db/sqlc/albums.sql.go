@@ -112,6 +112,59 @@ func (q *Queries) GetAlbumsByIDs(ctx context.Context, ids []string) ([]Album, er
 	return items, nil
 }
 
+const getLibraryAlbumsBySpotifyIds = `-- name: GetLibraryAlbumsBySpotifyIds :many
+SELECT DISTINCT albums.id, albums.spotify_id, albums.title, albums.created_at, albums.deleted_at, albums.image_url FROM albums
+JOIN releases ON releases.album_id = albums.id
+JOIN user_releases ON user_releases.release_id = releases.id
+WHERE user_releases.user_id = ? AND albums.spotify_id IN (/*SLICE:spotify_ids*/?)
+`
+
+type GetLibraryAlbumsBySpotifyIdsParams struct {
+	UserID     string
+	SpotifyIds []string
+}
+
+func (q *Queries) GetLibraryAlbumsBySpotifyIds(ctx context.Context, arg GetLibraryAlbumsBySpotifyIdsParams) ([]Album, error) {
+	query := getLibraryAlbumsBySpotifyIds
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.UserID)
+	if len(arg.SpotifyIds) > 0 {
+		for _, v := range arg.SpotifyIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:spotify_ids*/?", strings.Repeat(",?", len(arg.SpotifyIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:spotify_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Album
+	for rows.Next() {
+		var i Album
+		if err := rows.Scan(
+			&i.ID,
+			&i.SpotifyID,
+			&i.Title,
+			&i.CreatedAt,
+			&i.DeletedAt,
+			&i.ImageUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getOrCreateAlbum = `-- name: GetOrCreateAlbum :one
 INSERT INTO albums (id, spotify_id, title, image_url) VALUES (?, ?, ?, ?)
 ON CONFLICT (spotify_id)
@@ -7,6 +7,9 @@ package sqlc
 
 import (
 	"context"
+	"database/sql"
+
+	"github.com/alecdray/wax/src/internal/core/db/models"
 )
 
 const createArtist = `-- name: CreateArtist :exec
@@ -24,8 +27,90 @@ func (q *Queries) CreateArtist(ctx context.Context, arg CreateArtistParams) erro
 	return err
 }
 
+const createArtistAlias = `-- name: CreateArtistAlias :one
+INSERT INTO artist_aliases (id, artist_id, source, external_id, name) VALUES (?, ?, ?, ?, ?)
+RETURNING id, artist_id, source, external_id, name, created_at
+`
+
+type CreateArtistAliasParams struct {
+	ID         string
+	ArtistID   string
+	Source     models.ArtistAliasSource
+	ExternalID sql.NullString
+	Name       string
+}
+
+func (q *Queries) CreateArtistAlias(ctx context.Context, arg CreateArtistAliasParams) (ArtistAlias, error) {
+	row := q.db.QueryRowContext(ctx, createArtistAlias,
+		arg.ID,
+		arg.ArtistID,
+		arg.Source,
+		arg.ExternalID,
+		arg.Name,
+	)
+	var i ArtistAlias
+	err := row.Scan(
+		&i.ID,
+		&i.ArtistID,
+		&i.Source,
+		&i.ExternalID,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteArtist = `-- name: DeleteArtist :exec
+DELETE FROM artists WHERE id = ?
+`
+
+func (q *Queries) DeleteArtist(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteArtist, id)
+	return err
+}
+
+const deleteArtistAlias = `-- name: DeleteArtistAlias :execrows
+DELETE FROM artist_aliases WHERE id = ? AND artist_id = ?
+`
+
+type DeleteArtistAliasParams struct {
+	ID       string
+	ArtistID string
+}
+
+func (q *Queries) DeleteArtistAlias(ctx context.Context, arg DeleteArtistAliasParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteArtistAlias, arg.ID, arg.ArtistID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteArtistSortName = `-- name: DeleteArtistSortName :exec
+DELETE FROM artist_sort_names WHERE user_id = ? AND artist_id = ?
+`
+
+type DeleteArtistSortNameParams struct {
+	UserID   string
+	ArtistID string
+}
+
+func (q *Queries) DeleteArtistSortName(ctx context.Context, arg DeleteArtistSortNameParams) error {
+	_, err := q.db.ExecContext(ctx, deleteArtistSortName, arg.UserID, arg.ArtistID)
+	return err
+}
+
+const deleteArtistSortNamesByArtistId = `-- name: DeleteArtistSortNamesByArtistId :exec
+DELETE FROM artist_sort_names WHERE artist_id = ?
+`
+
+func (q *Queries) DeleteArtistSortNamesByArtistId(ctx context.Context, artistID string) error {
+	_, err := q.db.ExecContext(ctx, deleteArtistSortNamesByArtistId, artistID)
+	return err
+}
+
 const getArtist = `-- name: GetArtist :one
-SELECT id, spotify_id, name, created_at, deleted_at FROM artists WHERE id = ?
+SELECT id, spotify_id, name, created_at, deleted_at, sort_name FROM artists WHERE id = ?
 `
 
 func (q *Queries) GetArtist(ctx context.Context, id string) (Artist, error) {
@@ -37,12 +122,91 @@ func (q *Queries) GetArtist(ctx context.Context, id string) (Artist, error) {
 		&i.Name,
 		&i.CreatedAt,
 		&i.DeletedAt,
+		&i.SortName,
+	)
+	return i, err
+}
+
+const getArtistAlias = `-- name: GetArtistAlias :one
+SELECT id, artist_id, source, external_id, name, created_at FROM artist_aliases WHERE source = ? AND external_id = ?
+`
+
+type GetArtistAliasParams struct {
+	Source     models.ArtistAliasSource
+	ExternalID sql.NullString
+}
+
+func (q *Queries) GetArtistAlias(ctx context.Context, arg GetArtistAliasParams) (ArtistAlias, error) {
+	row := q.db.QueryRowContext(ctx, getArtistAlias, arg.Source, arg.ExternalID)
+	var i ArtistAlias
+	err := row.Scan(
+		&i.ID,
+		&i.ArtistID,
+		&i.Source,
+		&i.ExternalID,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getArtistAliases = `-- name: GetArtistAliases :many
+SELECT id, artist_id, source, external_id, name, created_at FROM artist_aliases WHERE artist_id = ?
+ORDER BY created_at, rowid
+`
+
+func (q *Queries) GetArtistAliases(ctx context.Context, artistID string) ([]ArtistAlias, error) {
+	rows, err := q.db.QueryContext(ctx, getArtistAliases, artistID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ArtistAlias
+	for rows.Next() {
+		var i ArtistAlias
+		if err := rows.Scan(
+			&i.ID,
+			&i.ArtistID,
+			&i.Source,
+			&i.ExternalID,
+			&i.Name,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getArtistBySpotifyAlias = `-- name: GetArtistBySpotifyAlias :one
+SELECT artists.id, artists.spotify_id, artists.name, artists.created_at, artists.deleted_at, artists.sort_name FROM artist_aliases
+JOIN artists ON artists.id = artist_aliases.artist_id
+WHERE artist_aliases.source = 'spotify' AND artist_aliases.external_id = ?
+`
+
+func (q *Queries) GetArtistBySpotifyAlias(ctx context.Context, externalID sql.NullString) (Artist, error) {
+	row := q.db.QueryRowContext(ctx, getArtistBySpotifyAlias, externalID)
+	var i Artist
+	err := row.Scan(
+		&i.ID,
+		&i.SpotifyID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.SortName,
 	)
 	return i, err
 }
 
 const getArtistBySpotifyId = `-- name: GetArtistBySpotifyId :one
-SELECT id, spotify_id, name, created_at, deleted_at FROM artists WHERE spotify_id = ?
+SELECT id, spotify_id, name, created_at, deleted_at, sort_name FROM artists WHERE spotify_id = ?
 `
 
 func (q *Queries) GetArtistBySpotifyId(ctx context.Context, spotifyID string) (Artist, error) {
@@ -54,15 +218,48 @@ func (q *Queries) GetArtistBySpotifyId(ctx context.Context, spotifyID string) (A
 		&i.Name,
 		&i.CreatedAt,
 		&i.DeletedAt,
+		&i.SortName,
 	)
 	return i, err
 }
 
+const getArtistSortNames = `-- name: GetArtistSortNames :many
+SELECT user_id, artist_id, sort_name, created_at FROM artist_sort_names WHERE user_id = ?
+`
+
+func (q *Queries) GetArtistSortNames(ctx context.Context, userID string) ([]ArtistSortName, error) {
+	rows, err := q.db.QueryContext(ctx, getArtistSortNames, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ArtistSortName
+	for rows.Next() {
+		var i ArtistSortName
+		if err := rows.Scan(
+			&i.UserID,
+			&i.ArtistID,
+			&i.SortName,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getOrCreateArtist = `-- name: GetOrCreateArtist :one
 INSERT INTO artists (id, spotify_id, name) VALUES (?, ?, ?)
 ON CONFLICT (spotify_id)
 DO UPDATE SET spotify_id = spotify_id
-RETURNING id, spotify_id, name, created_at, deleted_at
+RETURNING id, spotify_id, name, created_at, deleted_at, sort_name
 `
 
 type GetOrCreateArtistParams struct {
@@ -80,6 +277,98 @@ func (q *Queries) GetOrCreateArtist(ctx context.Context, arg GetOrCreateArtistPa
 		&i.Name,
 		&i.CreatedAt,
 		&i.DeletedAt,
+		&i.SortName,
 	)
 	return i, err
 }
+
+const isArtistInUserLibrary = `-- name: IsArtistInUserLibrary :one
+SELECT EXISTS (
+    SELECT 1 FROM album_artists
+    JOIN releases ON releases.album_id = album_artists.album_id
+    JOIN user_releases ON user_releases.release_id = releases.id
+    WHERE album_artists.artist_id = ? AND user_releases.user_id = ?
+) AS in_library
+`
+
+type IsArtistInUserLibraryParams struct {
+	ArtistID string
+	UserID   string
+}
+
+func (q *Queries) IsArtistInUserLibrary(ctx context.Context, arg IsArtistInUserLibraryParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, isArtistInUserLibrary, arg.ArtistID, arg.UserID)
+	var i int64
+	err := row.Scan(&i)
+	return i, err
+}
+
+const moveArtistAliases = `-- name: MoveArtistAliases :exec
+UPDATE artist_aliases SET artist_id = ? WHERE artist_id = ?
+`
+
+type MoveArtistAliasesParams struct {
+	ArtistID    string
+	DuplicateID string
+}
+
+func (q *Queries) MoveArtistAliases(ctx context.Context, arg MoveArtistAliasesParams) error {
+	_, err := q.db.ExecContext(ctx, moveArtistAliases, arg.ArtistID, arg.DuplicateID)
+	return err
+}
+
+const moveArtistSortNames = `-- name: MoveArtistSortNames :exec
+UPDATE OR IGNORE artist_sort_names SET artist_id = ? WHERE artist_id = ?
+`
+
+type MoveArtistSortNamesParams struct {
+	ArtistID    string
+	DuplicateID string
+}
+
+func (q *Queries) MoveArtistSortNames(ctx context.Context, arg MoveArtistSortNamesParams) error {
+	_, err := q.db.ExecContext(ctx, moveArtistSortNames, arg.ArtistID, arg.DuplicateID)
+	return err
+}
+
+const upsertArtistAlias = `-- name: UpsertArtistAlias :exec
+INSERT INTO artist_aliases (id, artist_id, source, external_id, name) VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (source, external_id)
+DO UPDATE SET artist_id = excluded.artist_id, name = excluded.name
+`
+
+type UpsertArtistAliasParams struct {
+	ID         string
+	ArtistID   string
+	Source     models.ArtistAliasSource
+	ExternalID sql.NullString
+	Name       string
+}
+
+func (q *Queries) UpsertArtistAlias(ctx context.Context, arg UpsertArtistAliasParams) error {
+	_, err := q.db.ExecContext(ctx, upsertArtistAlias,
+		arg.ID,
+		arg.ArtistID,
+		arg.Source,
+		arg.ExternalID,
+		arg.Name,
+	)
+	return err
+}
+
+const upsertArtistSortName = `-- name: UpsertArtistSortName :exec
+INSERT INTO artist_sort_names (user_id, artist_id, sort_name) VALUES (?, ?, ?)
+ON CONFLICT (user_id, artist_id)
+DO UPDATE SET sort_name = excluded.sort_name
+`
+
+type UpsertArtistSortNameParams struct {
+	UserID   string
+	ArtistID string
+	SortName string
+}
+
+func (q *Queries) UpsertArtistSortName(ctx context.Context, arg UpsertArtistSortNameParams) error {
+	_, err := q.db.ExecContext(ctx, upsertArtistSortName, arg.UserID, arg.ArtistID, arg.SortName)
+	return err
+}
@@ -23,6 +23,44 @@ type Album struct {
 type AlbumArtist struct {
 	AlbumID  string
 	ArtistID string
+	Position int64
+	Role     models.ArtistRole
+}
+
+type AlbumComparison struct {
+	ID              string
+	UserID          string
+	AlbumID         string
+	OpponentAlbumID string
+	Outcome         models.ComparisonOutcome
+	CreatedAt       time.Time
+}
+
+type AlbumLink struct {
+	ID          string
+	UserID      string
+	FromAlbumID string
+	ToAlbumID   string
+	Kind        models.AlbumLinkKind
+	Note        string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type AlbumPerson struct {
+	ID        string
+	UserID    string
+	AlbumID   string
+	PersonID  string
+	Direction models.AlbumPersonDirection
+	SharedAt  time.Time
+	CreatedAt time.Time
+}
+
+type AlbumRatingAnswer struct {
+	RatingLogID string
+	QuestionKey string
+	Value       int64
 }
 
 type AlbumRatingLog struct {
@@ -34,6 +72,33 @@ type AlbumRatingLog struct {
 	CreatedAt time.Time
 }
 
+type AlbumRatingScore struct {
+	RatingLogID string
+	Dimension   string
+	Score       float64
+}
+
+type AlbumReview struct {
+	ID          string
+	UserID      string
+	AlbumID     string
+	RatingLogID sql.NullString
+	Body        string
+	Status      models.ReviewStatus
+	PublishedAt sql.NullTime
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type AlbumReviewRevision struct {
+	ID        string
+	ReviewID  string
+	Body      string
+	Status    models.ReviewStatus
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
 type AlbumTag struct {
 	ID        string
 	UserID    string
@@ -53,6 +118,33 @@ type Artist struct {
 	Name      string
 	CreatedAt time.Time
 	DeletedAt sql.NullTime
+	SortName  sql.NullString
+}
+
+type ArtistAlias struct {
+	ID         string
+	ArtistID   string
+	Source     models.ArtistAliasSource
+	ExternalID sql.NullString
+	Name       string
+	CreatedAt  time.Time
+}
+
+type ArtistSortName struct {
+	UserID    string
+	ArtistID  string
+	SortName  string
+	CreatedAt time.Time
+}
+
+type BulkEdit struct {
+	ID        string
+	UserID    string
+	Operation models.BulkOperation
+	Summary   string
+	Undo      string
+	CreatedAt time.Time
+	UndoneAt  sql.NullTime
 }
 
 type Feed struct {
@@ -72,6 +164,43 @@ type GooseDbVersion struct {
 	Tstamp    sql.NullTime
 }
 
+type Person struct {
+	ID        string
+	UserID    string
+	Name      string
+	Notes     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type Ranklist struct {
+	ID          string
+	UserID      string
+	Name        string
+	Description string
+	Source      models.RanklistSource
+	TagID       sql.NullString
+	MinRating   sql.NullFloat64
+	MaxRating   sql.NullFloat64
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type RanklistEntry struct {
+	ID         string
+	RanklistID string
+	AlbumID    string
+	Position   string
+	Blurb      string
+	CreatedAt  time.Time
+}
+
+type RatingProfile struct {
+	UserID    string
+	Profile   string
+	UpdatedAt time.Time
+}
+
 type Release struct {
 	ID        string
 	AlbumID   string
@@ -80,6 +209,37 @@ type Release struct {
 	DeletedAt sql.NullTime
 }
 
+type RevisitRule struct {
+	UserID    string
+	Rules     string
+	UpdatedAt time.Time
+}
+
+type RevisitSuggestion struct {
+	UserID      string
+	AlbumID     string
+	RatingLogID string
+	Reason      models.RevisitReason
+	PlaysSince  int64
+	CreatedAt   time.Time
+}
+
+type Shelf struct {
+	ID          string
+	UserID      string
+	Name        string
+	Description string
+	Filter      sql.NullString
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type ShelfAlbum struct {
+	ShelfID   string
+	AlbumID   string
+	CreatedAt time.Time
+}
+
 type SqliteSequence struct {
 	Name interface{}
 	Seq  interface{}
@@ -91,6 +251,8 @@ type Tag struct {
 	Name      string
 	GroupID   sql.NullString
 	CreatedAt time.Time
+	Color     models.TagColor
+	ParentID  sql.NullString
 }
 
 type TagGroup struct {
@@ -98,6 +260,9 @@ type TagGroup struct {
 	UserID    string
 	Name      string
 	CreatedAt time.Time
+	Position  int64
+	Color     models.TagColor
+	Exclusive bool
 }
 
 type Track struct {
@@ -108,6 +273,14 @@ type Track struct {
 	DeletedAt sql.NullTime
 }
 
+type TrackMark struct {
+	UserID    string
+	TrackID   string
+	Mark      models.TrackMark
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
 type TrackPlay struct {
 	ID       string
 	UserID   string
@@ -122,6 +295,7 @@ type User struct {
 	CreatedAt           time.Time
 	DeletedAt           sql.NullTime
 	SpotifyRefreshToken sql.NullString
+	Timezone            string
 }
 
 type UserArtist struct {
@@ -147,3 +321,21 @@ type UserTrack struct {
 	AddedAt   time.Time
 	DeletedAt sql.NullTime
 }
+
+type WishlistItem struct {
+	ID            string
+	UserID        string
+	AlbumID       sql.NullString
+	MusicbrainzID sql.NullString
+	Title         string
+	ArtistNames   string
+	ImageUrl      sql.NullString
+	Format        models.WishlistFormat
+	Priority      int64
+	MaxPrice      sql.NullFloat64
+	Notes         string
+	HeardFrom     string
+	FulfilledAt   sql.NullTime
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
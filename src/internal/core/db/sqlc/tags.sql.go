@@ -9,7 +9,33 @@ import (
 	"context"
 	"database/sql"
 	"strings"
+	"time"
+
+	"github.com/alecdray/wax/src/internal/core/db/models"
+)
+
+const countAlbumsWithManyTagsInGroup = `-- name: CountAlbumsWithManyTagsInGroup :one
+SELECT COUNT(*) FROM (
+    SELECT album_tags.album_id
+    FROM album_tags
+    JOIN tags ON album_tags.tag_id = tags.id
+    WHERE album_tags.user_id = ? AND tags.group_id = ?
+    GROUP BY album_tags.album_id
+    HAVING COUNT(*) > 1
 )
+`
+
+type CountAlbumsWithManyTagsInGroupParams struct {
+	UserID  string
+	GroupID sql.NullString
+}
+
+func (q *Queries) CountAlbumsWithManyTagsInGroup(ctx context.Context, arg CountAlbumsWithManyTagsInGroupParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAlbumsWithManyTagsInGroup, arg.UserID, arg.GroupID)
+	var i int64
+	err := row.Scan(&i)
+	return i, err
+}
 
 const createAlbumTag = `-- name: CreateAlbumTag :one
 INSERT INTO album_tags (id, user_id, album_id, tag_id) VALUES (?, ?, ?, ?)
@@ -42,6 +68,43 @@ func (q *Queries) CreateAlbumTag(ctx context.Context, arg CreateAlbumTagParams)
 	return i, err
 }
 
+const createTagGroup = `-- name: CreateTagGroup :one
+INSERT INTO tag_groups (id, user_id, name, position, color, exclusive)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING id, user_id, name, created_at, position, color, exclusive
+`
+
+type CreateTagGroupParams struct {
+	ID        string
+	UserID    string
+	Name      string
+	Position  int64
+	Color     models.TagColor
+	Exclusive bool
+}
+
+func (q *Queries) CreateTagGroup(ctx context.Context, arg CreateTagGroupParams) (TagGroup, error) {
+	row := q.db.QueryRowContext(ctx, createTagGroup,
+		arg.ID,
+		arg.UserID,
+		arg.Name,
+		arg.Position,
+		arg.Color,
+		arg.Exclusive,
+	)
+	var i TagGroup
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.Position,
+		&i.Color,
+		&i.Exclusive,
+	)
+	return i, err
+}
+
 const deleteAlbumTag = `-- name: DeleteAlbumTag :exec
 DELETE FROM album_tags WHERE user_id = ? AND album_id = ? AND tag_id = ?
 `
@@ -57,6 +120,20 @@ func (q *Queries) DeleteAlbumTag(ctx context.Context, arg DeleteAlbumTagParams)
 	return err
 }
 
+const deleteAlbumTagById = `-- name: DeleteAlbumTagById :exec
+DELETE FROM album_tags WHERE id = ? AND user_id = ?
+`
+
+type DeleteAlbumTagByIdParams struct {
+	ID     string
+	UserID string
+}
+
+func (q *Queries) DeleteAlbumTagById(ctx context.Context, arg DeleteAlbumTagByIdParams) error {
+	_, err := q.db.ExecContext(ctx, deleteAlbumTagById, arg.ID, arg.UserID)
+	return err
+}
+
 const deleteAlbumTagsByAlbumId = `-- name: DeleteAlbumTagsByAlbumId :exec
 DELETE FROM album_tags WHERE user_id = ? AND album_id = ?
 `
@@ -71,15 +148,92 @@ func (q *Queries) DeleteAlbumTagsByAlbumId(ctx context.Context, arg DeleteAlbumT
 	return err
 }
 
+const deleteAlbumTagsByTagId = `-- name: DeleteAlbumTagsByTagId :exec
+DELETE FROM album_tags WHERE user_id = ? AND tag_id = ?
+`
+
+type DeleteAlbumTagsByTagIdParams struct {
+	UserID string
+	TagID  string
+}
+
+func (q *Queries) DeleteAlbumTagsByTagId(ctx context.Context, arg DeleteAlbumTagsByTagIdParams) error {
+	_, err := q.db.ExecContext(ctx, deleteAlbumTagsByTagId, arg.UserID, arg.TagID)
+	return err
+}
+
+const deleteTag = `-- name: DeleteTag :exec
+DELETE FROM tags WHERE id = ? AND user_id = ?
+`
+
+type DeleteTagParams struct {
+	ID     string
+	UserID string
+}
+
+func (q *Queries) DeleteTag(ctx context.Context, arg DeleteTagParams) error {
+	_, err := q.db.ExecContext(ctx, deleteTag, arg.ID, arg.UserID)
+	return err
+}
+
+const deleteTagGroup = `-- name: DeleteTagGroup :exec
+DELETE FROM tag_groups WHERE id = ? AND user_id = ?
+`
+
+type DeleteTagGroupParams struct {
+	ID     string
+	UserID string
+}
+
+func (q *Queries) DeleteTagGroup(ctx context.Context, arg DeleteTagGroupParams) error {
+	_, err := q.db.ExecContext(ctx, deleteTagGroup, arg.ID, arg.UserID)
+	return err
+}
+
+const getAlbumIdsByTagId = `-- name: GetAlbumIdsByTagId :many
+SELECT album_id FROM album_tags WHERE user_id = ? AND tag_id = ?
+`
+
+type GetAlbumIdsByTagIdParams struct {
+	UserID string
+	TagID  string
+}
+
+func (q *Queries) GetAlbumIdsByTagId(ctx context.Context, arg GetAlbumIdsByTagIdParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getAlbumIdsByTagId, arg.UserID, arg.TagID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var i string
+		if err := rows.Scan(&i); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getAlbumTagsByAlbumId = `-- name: GetAlbumTagsByAlbumId :many
-SELECT album_tags.album_id, tags.id, tags.user_id, tags.name, tags.group_id, tags.created_at,
+SELECT album_tags.album_id, tags.id, tags.user_id, tags.name, tags.group_id, tags.created_at, tags.color, tags.parent_id,
     COALESCE(tag_groups.id, '') as group_id_value,
-    COALESCE(tag_groups.name, '') as group_name
+    COALESCE(tag_groups.name, '') as group_name,
+    COALESCE(tag_groups.color, 'none') as group_color,
+    COALESCE(tag_groups.position, 0) as group_position,
+    COALESCE(tag_groups.exclusive, false) as group_exclusive
 FROM album_tags
 JOIN tags ON album_tags.tag_id = tags.id
 LEFT JOIN tag_groups ON tags.group_id = tag_groups.id
 WHERE album_tags.user_id = ? AND album_tags.album_id = ?
-ORDER BY tags.name
+ORDER BY tag_groups.id IS NULL, tag_groups.position, tags.name
 `
 
 type GetAlbumTagsByAlbumIdParams struct {
@@ -88,10 +242,13 @@ type GetAlbumTagsByAlbumIdParams struct {
 }
 
 type GetAlbumTagsByAlbumIdRow struct {
-	AlbumID      string
-	Tag          Tag
-	GroupIDValue string
-	GroupName    string
+	AlbumID        string
+	Tag            Tag
+	GroupIDValue   string
+	GroupName      string
+	GroupColor     string
+	GroupPosition  int64
+	GroupExclusive bool
 }
 
 func (q *Queries) GetAlbumTagsByAlbumId(ctx context.Context, arg GetAlbumTagsByAlbumIdParams) ([]GetAlbumTagsByAlbumIdRow, error) {
@@ -110,8 +267,13 @@ func (q *Queries) GetAlbumTagsByAlbumId(ctx context.Context, arg GetAlbumTagsByA
 			&i.Tag.Name,
 			&i.Tag.GroupID,
 			&i.Tag.CreatedAt,
+			&i.Tag.Color,
+			&i.Tag.ParentID,
 			&i.GroupIDValue,
 			&i.GroupName,
+			&i.GroupColor,
+			&i.GroupPosition,
+			&i.GroupExclusive,
 		); err != nil {
 			return nil, err
 		}
@@ -127,14 +289,17 @@ func (q *Queries) GetAlbumTagsByAlbumId(ctx context.Context, arg GetAlbumTagsByA
 }
 
 const getAlbumTagsByAlbumIds = `-- name: GetAlbumTagsByAlbumIds :many
-SELECT album_tags.album_id, tags.id, tags.user_id, tags.name, tags.group_id, tags.created_at,
+SELECT album_tags.album_id, tags.id, tags.user_id, tags.name, tags.group_id, tags.created_at, tags.color, tags.parent_id,
     COALESCE(tag_groups.id, '') as group_id_value,
-    COALESCE(tag_groups.name, '') as group_name
+    COALESCE(tag_groups.name, '') as group_name,
+    COALESCE(tag_groups.color, 'none') as group_color,
+    COALESCE(tag_groups.position, 0) as group_position,
+    COALESCE(tag_groups.exclusive, false) as group_exclusive
 FROM album_tags
 JOIN tags ON album_tags.tag_id = tags.id
 LEFT JOIN tag_groups ON tags.group_id = tag_groups.id
 WHERE album_tags.user_id = ? AND album_tags.album_id IN (/*SLICE:album_ids*/?)
-ORDER BY tags.name
+ORDER BY tag_groups.id IS NULL, tag_groups.position, tags.name
 `
 
 type GetAlbumTagsByAlbumIdsParams struct {
@@ -143,10 +308,13 @@ type GetAlbumTagsByAlbumIdsParams struct {
 }
 
 type GetAlbumTagsByAlbumIdsRow struct {
-	AlbumID      string
-	Tag          Tag
-	GroupIDValue string
-	GroupName    string
+	AlbumID        string
+	Tag            Tag
+	GroupIDValue   string
+	GroupName      string
+	GroupColor     string
+	GroupPosition  int64
+	GroupExclusive bool
 }
 
 func (q *Queries) GetAlbumTagsByAlbumIds(ctx context.Context, arg GetAlbumTagsByAlbumIdsParams) ([]GetAlbumTagsByAlbumIdsRow, error) {
@@ -176,8 +344,13 @@ func (q *Queries) GetAlbumTagsByAlbumIds(ctx context.Context, arg GetAlbumTagsBy
 			&i.Tag.Name,
 			&i.Tag.GroupID,
 			&i.Tag.CreatedAt,
+			&i.Tag.Color,
+			&i.Tag.ParentID,
 			&i.GroupIDValue,
 			&i.GroupName,
+			&i.GroupColor,
+			&i.GroupPosition,
+			&i.GroupExclusive,
 		); err != nil {
 			return nil, err
 		}
@@ -192,10 +365,237 @@ func (q *Queries) GetAlbumTagsByAlbumIds(ctx context.Context, arg GetAlbumTagsBy
 	return items, nil
 }
 
+const getAlbumTagsByTagIdAndAlbumIds = `-- name: GetAlbumTagsByTagIdAndAlbumIds :many
+SELECT id, user_id, album_id, tag_id, created_at FROM album_tags
+WHERE user_id = ? AND tag_id = ? AND album_id IN (/*SLICE:album_ids*/?)
+`
+
+type GetAlbumTagsByTagIdAndAlbumIdsParams struct {
+	UserID   string
+	TagID    string
+	AlbumIds []string
+}
+
+func (q *Queries) GetAlbumTagsByTagIdAndAlbumIds(ctx context.Context, arg GetAlbumTagsByTagIdAndAlbumIdsParams) ([]AlbumTag, error) {
+	query := getAlbumTagsByTagIdAndAlbumIds
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.UserID)
+	queryParams = append(queryParams, arg.TagID)
+	if len(arg.AlbumIds) > 0 {
+		for _, v := range arg.AlbumIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:album_ids*/?", strings.Repeat(",?", len(arg.AlbumIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:album_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AlbumTag
+	for rows.Next() {
+		var i AlbumTag
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.AlbumID,
+			&i.TagID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAlbumTagsInGroupByAlbumIds = `-- name: GetAlbumTagsInGroupByAlbumIds :many
+SELECT album_tags.id, album_tags.user_id, album_tags.album_id, album_tags.tag_id, album_tags.created_at FROM album_tags
+JOIN tags ON tags.id = album_tags.tag_id
+WHERE album_tags.user_id = ? AND tags.group_id = ? AND album_tags.album_id IN (/*SLICE:album_ids*/?)
+`
+
+type GetAlbumTagsInGroupByAlbumIdsParams struct {
+	UserID   string
+	GroupID  sql.NullString
+	AlbumIds []string
+}
+
+func (q *Queries) GetAlbumTagsInGroupByAlbumIds(ctx context.Context, arg GetAlbumTagsInGroupByAlbumIdsParams) ([]AlbumTag, error) {
+	query := getAlbumTagsInGroupByAlbumIds
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.UserID)
+	queryParams = append(queryParams, arg.GroupID)
+	if len(arg.AlbumIds) > 0 {
+		for _, v := range arg.AlbumIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:album_ids*/?", strings.Repeat(",?", len(arg.AlbumIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:album_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AlbumTag
+	for rows.Next() {
+		var i AlbumTag
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.AlbumID,
+			&i.TagID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getArtistTagCounts = `-- name: GetArtistTagCounts :many
+SELECT album_tags.tag_id, COUNT(DISTINCT album_tags.album_id) AS album_count
+FROM album_tags
+JOIN album_artists ON album_artists.album_id = album_tags.album_id
+WHERE album_tags.user_id = ? AND album_tags.album_id != ?
+  AND album_artists.artist_id IN (SELECT album_artists.artist_id FROM album_artists WHERE album_artists.album_id = ?)
+GROUP BY album_tags.tag_id
+`
+
+type GetArtistTagCountsParams struct {
+	UserID    string
+	AlbumID   string
+	AlbumID_2 string
+}
+
+type GetArtistTagCountsRow struct {
+	TagID      string
+	AlbumCount int64
+}
+
+func (q *Queries) GetArtistTagCounts(ctx context.Context, arg GetArtistTagCountsParams) ([]GetArtistTagCountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getArtistTagCounts, arg.UserID, arg.AlbumID, arg.AlbumID_2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetArtistTagCountsRow
+	for rows.Next() {
+		var i GetArtistTagCountsRow
+		if err := rows.Scan(&i.TagID, &i.AlbumCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCooccurringTagCounts = `-- name: GetCooccurringTagCounts :many
+SELECT other.tag_id, COUNT(DISTINCT other.album_id) AS album_count
+FROM album_tags AS shared
+JOIN album_tags AS other ON other.user_id = shared.user_id AND other.album_id = shared.album_id AND other.tag_id != shared.tag_id
+WHERE shared.user_id = ? AND shared.album_id != ?
+  AND shared.tag_id IN (SELECT album_tags.tag_id FROM album_tags WHERE album_tags.user_id = ? AND album_tags.album_id = ?)
+GROUP BY other.tag_id
+`
+
+type GetCooccurringTagCountsParams struct {
+	UserID    string
+	AlbumID   string
+	UserID_2  string
+	AlbumID_2 string
+}
+
+type GetCooccurringTagCountsRow struct {
+	TagID      string
+	AlbumCount int64
+}
+
+func (q *Queries) GetCooccurringTagCounts(ctx context.Context, arg GetCooccurringTagCountsParams) ([]GetCooccurringTagCountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getCooccurringTagCounts,
+		arg.UserID,
+		arg.AlbumID,
+		arg.UserID_2,
+		arg.AlbumID_2,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCooccurringTagCountsRow
+	for rows.Next() {
+		var i GetCooccurringTagCountsRow
+		if err := rows.Scan(&i.TagID, &i.AlbumCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLibraryTagIds = `-- name: GetLibraryTagIds :many
+SELECT DISTINCT album_tags.tag_id FROM album_tags
+JOIN releases ON releases.album_id = album_tags.album_id
+JOIN user_releases ON user_releases.release_id = releases.id AND user_releases.user_id = album_tags.user_id
+WHERE album_tags.user_id = ?
+`
+
+func (q *Queries) GetLibraryTagIds(ctx context.Context, userID string) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getLibraryTagIds, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var i string
+		if err := rows.Scan(&i); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getOrCreateTag = `-- name: GetOrCreateTag :one
 INSERT INTO tags (id, user_id, name, group_id) VALUES (?, ?, ?, ?)
 ON CONFLICT (user_id, name) DO UPDATE SET name = name
-RETURNING id, user_id, name, group_id, created_at
+RETURNING id, user_id, name, group_id, created_at, color, parent_id
 `
 
 type GetOrCreateTagParams struct {
@@ -219,36 +619,180 @@ func (q *Queries) GetOrCreateTag(ctx context.Context, arg GetOrCreateTagParams)
 		&i.Name,
 		&i.GroupID,
 		&i.CreatedAt,
+		&i.Color,
+		&i.ParentID,
 	)
 	return i, err
 }
 
 const getOrCreateTagGroup = `-- name: GetOrCreateTagGroup :one
-INSERT INTO tag_groups (id, user_id, name) VALUES (?, ?, ?)
+INSERT INTO tag_groups (id, user_id, name, position) VALUES (?, ?, ?, ?)
 ON CONFLICT (user_id, name) DO UPDATE SET name = name
-RETURNING id, user_id, name, created_at
+RETURNING id, user_id, name, created_at, position, color, exclusive
 `
 
 type GetOrCreateTagGroupParams struct {
+	ID       string
+	UserID   string
+	Name     string
+	Position int64
+}
+
+func (q *Queries) GetOrCreateTagGroup(ctx context.Context, arg GetOrCreateTagGroupParams) (TagGroup, error) {
+	row := q.db.QueryRowContext(ctx, getOrCreateTagGroup,
+		arg.ID,
+		arg.UserID,
+		arg.Name,
+		arg.Position,
+	)
+	var i TagGroup
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.Position,
+		&i.Color,
+		&i.Exclusive,
+	)
+	return i, err
+}
+
+const getSubtagIds = `-- name: GetSubtagIds :many
+WITH RECURSIVE subtags(id) AS (
+    SELECT tags.id FROM tags WHERE tags.id = ? AND tags.user_id = ?
+    UNION
+    SELECT tags.id FROM tags JOIN subtags ON tags.parent_id = subtags.id
+)
+SELECT id FROM subtags
+`
+
+type GetSubtagIdsParams struct {
 	ID     string
 	UserID string
+}
+
+func (q *Queries) GetSubtagIds(ctx context.Context, arg GetSubtagIdsParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getSubtagIds, arg.ID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var i string
+		if err := rows.Scan(&i); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTag = `-- name: GetTag :one
+SELECT id, user_id, name, group_id, created_at, color, parent_id FROM tags WHERE id = ? AND user_id = ?
+`
+
+type GetTagParams struct {
+	ID     string
+	UserID string
+}
+
+func (q *Queries) GetTag(ctx context.Context, arg GetTagParams) (Tag, error) {
+	row := q.db.QueryRowContext(ctx, getTag, arg.ID, arg.UserID)
+	var i Tag
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.GroupID,
+		&i.CreatedAt,
+		&i.Color,
+		&i.ParentID,
+	)
+	return i, err
+}
+
+const getTagByName = `-- name: GetTagByName :one
+SELECT id, user_id, name, group_id, created_at, color, parent_id FROM tags WHERE user_id = ? AND name = ?
+`
+
+type GetTagByNameParams struct {
+	UserID string
 	Name   string
 }
 
-func (q *Queries) GetOrCreateTagGroup(ctx context.Context, arg GetOrCreateTagGroupParams) (TagGroup, error) {
-	row := q.db.QueryRowContext(ctx, getOrCreateTagGroup, arg.ID, arg.UserID, arg.Name)
+func (q *Queries) GetTagByName(ctx context.Context, arg GetTagByNameParams) (Tag, error) {
+	row := q.db.QueryRowContext(ctx, getTagByName, arg.UserID, arg.Name)
+	var i Tag
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.GroupID,
+		&i.CreatedAt,
+		&i.Color,
+		&i.ParentID,
+	)
+	return i, err
+}
+
+const getTagGroup = `-- name: GetTagGroup :one
+SELECT id, user_id, name, created_at, position, color, exclusive FROM tag_groups WHERE id = ? AND user_id = ?
+`
+
+type GetTagGroupParams struct {
+	ID     string
+	UserID string
+}
+
+func (q *Queries) GetTagGroup(ctx context.Context, arg GetTagGroupParams) (TagGroup, error) {
+	row := q.db.QueryRowContext(ctx, getTagGroup, arg.ID, arg.UserID)
+	var i TagGroup
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.Position,
+		&i.Color,
+		&i.Exclusive,
+	)
+	return i, err
+}
+
+const getTagGroupByName = `-- name: GetTagGroupByName :one
+SELECT id, user_id, name, created_at, position, color, exclusive FROM tag_groups WHERE user_id = ? AND name = ? COLLATE NOCASE
+`
+
+type GetTagGroupByNameParams struct {
+	UserID string
+	Name   string
+}
+
+func (q *Queries) GetTagGroupByName(ctx context.Context, arg GetTagGroupByNameParams) (TagGroup, error) {
+	row := q.db.QueryRowContext(ctx, getTagGroupByName, arg.UserID, arg.Name)
 	var i TagGroup
 	err := row.Scan(
 		&i.ID,
 		&i.UserID,
 		&i.Name,
 		&i.CreatedAt,
+		&i.Position,
+		&i.Color,
+		&i.Exclusive,
 	)
 	return i, err
 }
 
 const getTagGroupsByUserId = `-- name: GetTagGroupsByUserId :many
-SELECT id, user_id, name, created_at FROM tag_groups WHERE user_id = ?
+SELECT id, user_id, name, created_at, position, color, exclusive FROM tag_groups WHERE user_id = ? ORDER BY position, name
 `
 
 func (q *Queries) GetTagGroupsByUserId(ctx context.Context, userID string) ([]TagGroup, error) {
@@ -265,6 +809,85 @@ func (q *Queries) GetTagGroupsByUserId(ctx context.Context, userID string) ([]Ta
 			&i.UserID,
 			&i.Name,
 			&i.CreatedAt,
+			&i.Position,
+			&i.Color,
+			&i.Exclusive,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTagUsageByUserId = `-- name: GetTagUsageByUserId :many
+WITH RECURSIVE lineage(ancestor_id, tag_id) AS (
+    SELECT tags.id, tags.id FROM tags WHERE tags.user_id = ?
+    UNION
+    SELECT lineage.ancestor_id, tags.id FROM lineage JOIN tags ON tags.parent_id = lineage.tag_id
+)
+SELECT tags.id, tags.user_id, tags.name, tags.group_id, tags.created_at, tags.color, tags.parent_id,
+    COALESCE(tag_groups.id, '') as group_id_value,
+    COALESCE(tag_groups.name, '') as group_name,
+    COALESCE(tag_groups.color, 'none') as group_color,
+    COALESCE(tag_groups.position, 0) as group_position,
+    COALESCE(tag_groups.exclusive, false) as group_exclusive,
+    (SELECT COUNT(*) FROM album_tags WHERE album_tags.user_id = tags.user_id AND album_tags.tag_id = tags.id) as album_count,
+    (SELECT COUNT(DISTINCT album_tags.album_id) FROM lineage
+        JOIN album_tags ON album_tags.tag_id = lineage.tag_id
+        WHERE lineage.ancestor_id = tags.id AND album_tags.user_id = tags.user_id) as total_album_count
+FROM tags
+LEFT JOIN tag_groups ON tags.group_id = tag_groups.id
+WHERE tags.user_id = ?
+ORDER BY tags.name
+`
+
+type GetTagUsageByUserIdParams struct {
+	UserID   string
+	UserID_2 string
+}
+
+type GetTagUsageByUserIdRow struct {
+	Tag             Tag
+	GroupIDValue    string
+	GroupName       string
+	GroupColor      string
+	GroupPosition   int64
+	GroupExclusive  bool
+	AlbumCount      int64
+	TotalAlbumCount int64
+}
+
+func (q *Queries) GetTagUsageByUserId(ctx context.Context, arg GetTagUsageByUserIdParams) ([]GetTagUsageByUserIdRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTagUsageByUserId, arg.UserID, arg.UserID_2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTagUsageByUserIdRow
+	for rows.Next() {
+		var i GetTagUsageByUserIdRow
+		if err := rows.Scan(
+			&i.Tag.ID,
+			&i.Tag.UserID,
+			&i.Tag.Name,
+			&i.Tag.GroupID,
+			&i.Tag.CreatedAt,
+			&i.Tag.Color,
+			&i.Tag.ParentID,
+			&i.GroupIDValue,
+			&i.GroupName,
+			&i.GroupColor,
+			&i.GroupPosition,
+			&i.GroupExclusive,
+			&i.AlbumCount,
+			&i.TotalAlbumCount,
 		); err != nil {
 			return nil, err
 		}
@@ -280,9 +903,12 @@ func (q *Queries) GetTagGroupsByUserId(ctx context.Context, userID string) ([]Ta
 }
 
 const getTagsByUserId = `-- name: GetTagsByUserId :many
-SELECT tags.id, tags.user_id, tags.name, tags.group_id, tags.created_at,
+SELECT tags.id, tags.user_id, tags.name, tags.group_id, tags.created_at, tags.color, tags.parent_id,
     COALESCE(tag_groups.id, '') as group_id_value,
-    COALESCE(tag_groups.name, '') as group_name
+    COALESCE(tag_groups.name, '') as group_name,
+    COALESCE(tag_groups.color, 'none') as group_color,
+    COALESCE(tag_groups.position, 0) as group_position,
+    COALESCE(tag_groups.exclusive, false) as group_exclusive
 FROM tags
 LEFT JOIN tag_groups ON tags.group_id = tag_groups.id
 WHERE tags.user_id = ?
@@ -290,9 +916,12 @@ ORDER BY tags.name
 `
 
 type GetTagsByUserIdRow struct {
-	Tag          Tag
-	GroupIDValue string
-	GroupName    string
+	Tag            Tag
+	GroupIDValue   string
+	GroupName      string
+	GroupColor     string
+	GroupPosition  int64
+	GroupExclusive bool
 }
 
 func (q *Queries) GetTagsByUserId(ctx context.Context, userID string) ([]GetTagsByUserIdRow, error) {
@@ -310,8 +939,13 @@ func (q *Queries) GetTagsByUserId(ctx context.Context, userID string) ([]GetTags
 			&i.Tag.Name,
 			&i.Tag.GroupID,
 			&i.Tag.CreatedAt,
+			&i.Tag.Color,
+			&i.Tag.ParentID,
 			&i.GroupIDValue,
 			&i.GroupName,
+			&i.GroupColor,
+			&i.GroupPosition,
+			&i.GroupExclusive,
 		); err != nil {
 			return nil, err
 		}
@@ -325,3 +959,144 @@ func (q *Queries) GetTagsByUserId(ctx context.Context, userID string) ([]GetTags
 	}
 	return items, nil
 }
+
+const reparentTags = `-- name: ReparentTags :exec
+UPDATE tags SET parent_id = ?
+WHERE user_id = ? AND parent_id = ?
+`
+
+type ReparentTagsParams struct {
+	ToParentID   sql.NullString
+	UserID       string
+	FromParentID sql.NullString
+}
+
+func (q *Queries) ReparentTags(ctx context.Context, arg ReparentTagsParams) error {
+	_, err := q.db.ExecContext(ctx, reparentTags, arg.ToParentID, arg.UserID, arg.FromParentID)
+	return err
+}
+
+const restoreAlbumTag = `-- name: RestoreAlbumTag :exec
+INSERT INTO album_tags (id, user_id, album_id, tag_id, created_at) VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (user_id, album_id, tag_id) DO NOTHING
+`
+
+type RestoreAlbumTagParams struct {
+	ID        string
+	UserID    string
+	AlbumID   string
+	TagID     string
+	CreatedAt time.Time
+}
+
+func (q *Queries) RestoreAlbumTag(ctx context.Context, arg RestoreAlbumTagParams) error {
+	_, err := q.db.ExecContext(ctx, restoreAlbumTag,
+		arg.ID,
+		arg.UserID,
+		arg.AlbumID,
+		arg.TagID,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const setTagGroupPosition = `-- name: SetTagGroupPosition :exec
+UPDATE tag_groups SET position = ? WHERE id = ? AND user_id = ?
+`
+
+type SetTagGroupPositionParams struct {
+	Position int64
+	ID       string
+	UserID   string
+}
+
+func (q *Queries) SetTagGroupPosition(ctx context.Context, arg SetTagGroupPositionParams) error {
+	_, err := q.db.ExecContext(ctx, setTagGroupPosition, arg.Position, arg.ID, arg.UserID)
+	return err
+}
+
+const ungroupTagsByGroupId = `-- name: UngroupTagsByGroupId :exec
+UPDATE tags SET group_id = NULL WHERE user_id = ? AND group_id = ?
+`
+
+type UngroupTagsByGroupIdParams struct {
+	UserID  string
+	GroupID sql.NullString
+}
+
+func (q *Queries) UngroupTagsByGroupId(ctx context.Context, arg UngroupTagsByGroupIdParams) error {
+	_, err := q.db.ExecContext(ctx, ungroupTagsByGroupId, arg.UserID, arg.GroupID)
+	return err
+}
+
+const updateTag = `-- name: UpdateTag :one
+UPDATE tags SET name = ?, group_id = ?, color = ?, parent_id = ?
+WHERE id = ? AND user_id = ?
+RETURNING id, user_id, name, group_id, created_at, color, parent_id
+`
+
+type UpdateTagParams struct {
+	Name     string
+	GroupID  sql.NullString
+	Color    models.TagColor
+	ParentID sql.NullString
+	ID       string
+	UserID   string
+}
+
+func (q *Queries) UpdateTag(ctx context.Context, arg UpdateTagParams) (Tag, error) {
+	row := q.db.QueryRowContext(ctx, updateTag,
+		arg.Name,
+		arg.GroupID,
+		arg.Color,
+		arg.ParentID,
+		arg.ID,
+		arg.UserID,
+	)
+	var i Tag
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.GroupID,
+		&i.CreatedAt,
+		&i.Color,
+		&i.ParentID,
+	)
+	return i, err
+}
+
+const updateTagGroup = `-- name: UpdateTagGroup :one
+UPDATE tag_groups SET name = ?, color = ?, exclusive = ?
+WHERE id = ? AND user_id = ?
+RETURNING id, user_id, name, created_at, position, color, exclusive
+`
+
+type UpdateTagGroupParams struct {
+	Name      string
+	Color     models.TagColor
+	Exclusive bool
+	ID        string
+	UserID    string
+}
+
+func (q *Queries) UpdateTagGroup(ctx context.Context, arg UpdateTagGroupParams) (TagGroup, error) {
+	row := q.db.QueryRowContext(ctx, updateTagGroup,
+		arg.Name,
+		arg.Color,
+		arg.Exclusive,
+		arg.ID,
+		arg.UserID,
+	)
+	var i TagGroup
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.Position,
+		&i.Color,
+		&i.Exclusive,
+	)
+	return i, err
+}
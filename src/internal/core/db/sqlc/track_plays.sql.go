@@ -73,7 +73,12 @@ SELECT albums.id, albums.spotify_id, albums.title, albums.created_at, albums.del
         SELECT 1 FROM user_releases
         JOIN releases ON releases.id = user_releases.release_id
         WHERE releases.album_id = albums.id AND user_releases.user_id = track_plays.user_id
-    ) as in_library
+    ) as in_library,
+    EXISTS (
+        SELECT 1 FROM wishlist_items
+        WHERE wishlist_items.album_id = albums.id AND wishlist_items.user_id = track_plays.user_id
+            AND wishlist_items.fulfilled_at IS NULL
+    ) as wishlisted
 FROM track_plays
 JOIN albums ON albums.id = track_plays.album_id
 WHERE track_plays.user_id = ?
@@ -92,6 +97,7 @@ type GetRecentlyPlayedAlbumsRow struct {
 	LastPlayedAt interface{}
 	ArtistNames  interface{}
 	InLibrary    int64
+	Wishlisted   int64
 }
 
 func (q *Queries) GetRecentlyPlayedAlbums(ctx context.Context, userID string) ([]GetRecentlyPlayedAlbumsRow, error) {
@@ -113,6 +119,7 @@ func (q *Queries) GetRecentlyPlayedAlbums(ctx context.Context, userID string) ([
 			&i.LastPlayedAt,
 			&i.ArtistNames,
 			&i.InLibrary,
+			&i.Wishlisted,
 		); err != nil {
 			return nil, err
 		}
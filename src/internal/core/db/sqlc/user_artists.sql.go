@@ -9,6 +9,15 @@ import (
 	"context"
 )
 
+const deleteUserArtistsByArtistId = `-- name: DeleteUserArtistsByArtistId :exec
+DELETE FROM user_artists WHERE artist_id = ?
+`
+
+func (q *Queries) DeleteUserArtistsByArtistId(ctx context.Context, artistID string) error {
+	_, err := q.db.ExecContext(ctx, deleteUserArtistsByArtistId, artistID)
+	return err
+}
+
 const getOrCreateUserArtist = `-- name: GetOrCreateUserArtist :exec
 INSERT INTO user_artists (id, user_id, artist_id) VALUES (?, ?, ?)
 ON CONFLICT (user_id, artist_id)
@@ -28,7 +37,7 @@ func (q *Queries) GetOrCreateUserArtist(ctx context.Context, arg GetOrCreateUser
 }
 
 const getUserArtists = `-- name: GetUserArtists :many
-SELECT user_artists.id, user_artists.user_id, user_artists.artist_id, user_artists.added_at, user_artists.deleted_at, artists.id, artists.spotify_id, artists.name, artists.created_at, artists.deleted_at FROM user_artists
+SELECT user_artists.id, user_artists.user_id, user_artists.artist_id, user_artists.added_at, user_artists.deleted_at, artists.id, artists.spotify_id, artists.name, artists.created_at, artists.deleted_at, artists.sort_name FROM user_artists
 JOIN artists ON user_artists.artist_id = artists.id
 WHERE user_id = ?
 `
@@ -58,6 +67,7 @@ func (q *Queries) GetUserArtists(ctx context.Context, userID string) ([]GetUserA
 			&i.Artist.Name,
 			&i.Artist.CreatedAt,
 			&i.Artist.DeletedAt,
+			&i.Artist.SortName,
 		); err != nil {
 			return nil, err
 		}
@@ -71,3 +81,17 @@ func (q *Queries) GetUserArtists(ctx context.Context, userID string) ([]GetUserA
 	}
 	return items, nil
 }
+
+const moveUserArtists = `-- name: MoveUserArtists :exec
+UPDATE OR IGNORE user_artists SET artist_id = ? WHERE artist_id = ?
+`
+
+type MoveUserArtistsParams struct {
+	ArtistID    string
+	DuplicateID string
+}
+
+func (q *Queries) MoveUserArtists(ctx context.Context, arg MoveUserArtistsParams) error {
+	_, err := q.db.ExecContext(ctx, moveUserArtists, arg.ArtistID, arg.DuplicateID)
+	return err
+}
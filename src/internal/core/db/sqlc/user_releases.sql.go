@@ -7,9 +7,148 @@ package sqlc
 
 import (
 	"context"
+	"strings"
 	"time"
+
+	"github.com/alecdray/wax/src/internal/core/db/models"
 )
 
+const deleteUserRelease = `-- name: DeleteUserRelease :exec
+DELETE FROM user_releases WHERE id = ? AND user_id = ?
+`
+
+type DeleteUserReleaseParams struct {
+	ID     string
+	UserID string
+}
+
+func (q *Queries) DeleteUserRelease(ctx context.Context, arg DeleteUserReleaseParams) error {
+	_, err := q.db.ExecContext(ctx, deleteUserRelease, arg.ID, arg.UserID)
+	return err
+}
+
+const getAlbumIdsWithFormat = `-- name: GetAlbumIdsWithFormat :many
+SELECT DISTINCT releases.album_id FROM user_releases
+JOIN releases ON user_releases.release_id = releases.id
+WHERE user_releases.user_id = ? AND releases.format = ? AND releases.album_id IN (/*SLICE:album_ids*/?)
+`
+
+type GetAlbumIdsWithFormatParams struct {
+	UserID   string
+	Format   models.ReleaseFormat
+	AlbumIds []string
+}
+
+func (q *Queries) GetAlbumIdsWithFormat(ctx context.Context, arg GetAlbumIdsWithFormatParams) ([]string, error) {
+	query := getAlbumIdsWithFormat
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.UserID)
+	queryParams = append(queryParams, arg.Format)
+	if len(arg.AlbumIds) > 0 {
+		for _, v := range arg.AlbumIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:album_ids*/?", strings.Repeat(",?", len(arg.AlbumIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:album_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var i string
+		if err := rows.Scan(&i); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLibraryAlbumIds = `-- name: GetLibraryAlbumIds :many
+SELECT DISTINCT releases.album_id FROM user_releases
+JOIN releases ON user_releases.release_id = releases.id
+WHERE user_releases.user_id = ? AND releases.album_id IN (/*SLICE:album_ids*/?)
+`
+
+type GetLibraryAlbumIdsParams struct {
+	UserID   string
+	AlbumIds []string
+}
+
+func (q *Queries) GetLibraryAlbumIds(ctx context.Context, arg GetLibraryAlbumIdsParams) ([]string, error) {
+	query := getLibraryAlbumIds
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.UserID)
+	if len(arg.AlbumIds) > 0 {
+		for _, v := range arg.AlbumIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:album_ids*/?", strings.Repeat(",?", len(arg.AlbumIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:album_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var i string
+		if err := rows.Scan(&i); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLibraryStats = `-- name: GetLibraryStats :one
+WITH library AS (
+    SELECT DISTINCT releases.album_id FROM user_releases
+    JOIN releases ON user_releases.release_id = releases.id
+    JOIN albums ON releases.album_id = albums.id
+    WHERE user_releases.user_id = ?
+)
+SELECT
+    (SELECT COUNT(*) FROM library) AS album_count,
+    (SELECT COUNT(DISTINCT artists.id) FROM album_artists
+        JOIN artists ON album_artists.artist_id = artists.id
+        JOIN library ON album_artists.album_id = library.album_id) AS artist_count,
+    (SELECT COUNT(DISTINCT tracks.id) FROM album_tracks
+        JOIN tracks ON album_tracks.track_id = tracks.id
+        JOIN library ON album_tracks.album_id = library.album_id) AS track_count
+`
+
+type GetLibraryStatsRow struct {
+	AlbumCount  int64
+	ArtistCount int64
+	TrackCount  int64
+}
+
+func (q *Queries) GetLibraryStats(ctx context.Context, userID string) (GetLibraryStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, getLibraryStats, userID)
+	var i GetLibraryStatsRow
+	err := row.Scan(&i.AlbumCount, &i.ArtistCount, &i.TrackCount)
+	return i, err
+}
+
 const getUserReleases = `-- name: GetUserReleases :many
 SELECT user_releases.id, user_releases.user_id, user_releases.release_id, user_releases.added_at, user_releases.deleted_at, releases.id, releases.album_id, releases.format, releases.created_at, releases.deleted_at FROM user_releases
 JOIN releases ON user_releases.release_id = releases.id
@@ -106,6 +245,67 @@ func (q *Queries) GetUserReleasesByAlbumId(ctx context.Context, arg GetUserRelea
 	return items, nil
 }
 
+const getUserReleasesByAlbumIds = `-- name: GetUserReleasesByAlbumIds :many
+SELECT user_releases.id, user_releases.user_id, user_releases.release_id, user_releases.added_at, user_releases.deleted_at, releases.id, releases.album_id, releases.format, releases.created_at, releases.deleted_at FROM user_releases
+JOIN releases ON user_releases.release_id = releases.id
+WHERE user_id = ? AND releases.album_id IN (/*SLICE:album_ids*/?)
+`
+
+type GetUserReleasesByAlbumIdsParams struct {
+	UserID   string
+	AlbumIds []string
+}
+
+type GetUserReleasesByAlbumIdsRow struct {
+	UserRelease UserRelease
+	Release     Release
+}
+
+func (q *Queries) GetUserReleasesByAlbumIds(ctx context.Context, arg GetUserReleasesByAlbumIdsParams) ([]GetUserReleasesByAlbumIdsRow, error) {
+	query := getUserReleasesByAlbumIds
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.UserID)
+	if len(arg.AlbumIds) > 0 {
+		for _, v := range arg.AlbumIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:album_ids*/?", strings.Repeat(",?", len(arg.AlbumIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:album_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserReleasesByAlbumIdsRow
+	for rows.Next() {
+		var i GetUserReleasesByAlbumIdsRow
+		if err := rows.Scan(
+			&i.UserRelease.ID,
+			&i.UserRelease.UserID,
+			&i.UserRelease.ReleaseID,
+			&i.UserRelease.AddedAt,
+			&i.UserRelease.DeletedAt,
+			&i.Release.ID,
+			&i.Release.AlbumID,
+			&i.Release.Format,
+			&i.Release.CreatedAt,
+			&i.Release.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const upsertUserRelease = `-- name: UpsertUserRelease :one
 INSERT INTO user_releases (id, user_id, release_id, added_at) VALUES (?, ?, ?, ?)
 ON CONFLICT (user_id, release_id)
@@ -12,7 +12,7 @@ import (
 
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (id, spotify_id) VALUES (?, ?)
-RETURNING id, spotify_id, created_at, deleted_at, spotify_refresh_token
+RETURNING id, spotify_id, created_at, deleted_at, spotify_refresh_token, timezone
 `
 
 type CreateUserParams struct {
@@ -29,12 +29,13 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.CreatedAt,
 		&i.DeletedAt,
 		&i.SpotifyRefreshToken,
+		&i.Timezone,
 	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT id, spotify_id, created_at, deleted_at, spotify_refresh_token FROM users WHERE id = ?
+SELECT id, spotify_id, created_at, deleted_at, spotify_refresh_token, timezone FROM users WHERE id = ?
 `
 
 func (q *Queries) GetUser(ctx context.Context, id string) (User, error) {
@@ -46,12 +47,13 @@ func (q *Queries) GetUser(ctx context.Context, id string) (User, error) {
 		&i.CreatedAt,
 		&i.DeletedAt,
 		&i.SpotifyRefreshToken,
+		&i.Timezone,
 	)
 	return i, err
 }
 
 const getUserBySpotifyId = `-- name: GetUserBySpotifyId :one
-SELECT id, spotify_id, created_at, deleted_at, spotify_refresh_token FROM users WHERE spotify_id = ?
+SELECT id, spotify_id, created_at, deleted_at, spotify_refresh_token, timezone FROM users WHERE spotify_id = ?
 `
 
 func (q *Queries) GetUserBySpotifyId(ctx context.Context, spotifyID string) (User, error) {
@@ -63,12 +65,13 @@ func (q *Queries) GetUserBySpotifyId(ctx context.Context, spotifyID string) (Use
 		&i.CreatedAt,
 		&i.DeletedAt,
 		&i.SpotifyRefreshToken,
+		&i.Timezone,
 	)
 	return i, err
 }
 
 const getUsersWithSpotifyToken = `-- name: GetUsersWithSpotifyToken :many
-SELECT id, spotify_id, created_at, deleted_at, spotify_refresh_token FROM users
+SELECT id, spotify_id, created_at, deleted_at, spotify_refresh_token, timezone FROM users
 WHERE spotify_refresh_token IS NOT NULL AND deleted_at IS NULL
 `
 
@@ -87,6 +90,7 @@ func (q *Queries) GetUsersWithSpotifyToken(ctx context.Context) ([]User, error)
 			&i.CreatedAt,
 			&i.DeletedAt,
 			&i.SpotifyRefreshToken,
+			&i.Timezone,
 		); err != nil {
 			return nil, err
 		}
@@ -101,11 +105,25 @@ func (q *Queries) GetUsersWithSpotifyToken(ctx context.Context) ([]User, error)
 	return items, nil
 }
 
+const setUserTimezone = `-- name: SetUserTimezone :exec
+UPDATE users SET timezone = ? WHERE id = ?
+`
+
+type SetUserTimezoneParams struct {
+	Timezone string
+	ID       string
+}
+
+func (q *Queries) SetUserTimezone(ctx context.Context, arg SetUserTimezoneParams) error {
+	_, err := q.db.ExecContext(ctx, setUserTimezone, arg.Timezone, arg.ID)
+	return err
+}
+
 const upsertSpotifyUser = `-- name: UpsertSpotifyUser :one
 INSERT INTO users (id, spotify_id, spotify_refresh_token) VALUES (?, ?, ?)
 ON CONFLICT (spotify_id)
 DO UPDATE SET spotify_id = EXCLUDED.spotify_id, spotify_refresh_token = coalesce(EXCLUDED.spotify_refresh_token, spotify_refresh_token)
-RETURNING id, spotify_id, created_at, deleted_at, spotify_refresh_token
+RETURNING id, spotify_id, created_at, deleted_at, spotify_refresh_token, timezone
 `
 
 type UpsertSpotifyUserParams struct {
@@ -123,6 +141,7 @@ func (q *Queries) UpsertSpotifyUser(ctx context.Context, arg UpsertSpotifyUserPa
 		&i.CreatedAt,
 		&i.DeletedAt,
 		&i.SpotifyRefreshToken,
+		&i.Timezone,
 	)
 	return i, err
 }
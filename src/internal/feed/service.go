@@ -13,6 +13,7 @@ import (
 	"github.com/alecdray/wax/src/internal/core/utils"
 	"github.com/alecdray/wax/src/internal/library"
 	"github.com/alecdray/wax/src/internal/spotify"
+	"github.com/alecdray/wax/src/internal/wishlist"
 	"time"
 
 	"github.com/google/uuid"
@@ -76,16 +77,18 @@ func (f *FeedDTO) SetSyncing() {
 }
 
 type Service struct {
-	db             *db.DB
-	spotifyService *spotify.Service
-	libraryService *library.Service
+	db              *db.DB
+	spotifyService  *spotify.Service
+	libraryService  *library.Service
+	wishlistService *wishlist.Service
 }
 
-func NewService(db *db.DB, spotifyService *spotify.Service, libraryService *library.Service) *Service {
+func NewService(db *db.DB, spotifyService *spotify.Service, libraryService *library.Service, wishlistService *wishlist.Service) *Service {
 	return &Service{
-		db:             db,
-		spotifyService: spotifyService,
-		libraryService: libraryService,
+		db:              db,
+		spotifyService:  spotifyService,
+		libraryService:  libraryService,
+		wishlistService: wishlistService,
 	}
 }
 
@@ -139,6 +142,68 @@ func (s *Service) UpdateFeed(ctx context.Context, feed FeedDTO) (*FeedDTO, error
 	return NewFeedDTOFromModel(feedModel), nil
 }
 
+// newAlbumDTOFromSpotify builds a digital release of a Spotify album to add
+// to the library.
+func newAlbumDTOFromSpotify(album spotify.SimpleAlbum, tracks []spotify.SimpleTrack, addedAt *time.Time) library.AlbumDTO {
+	var imageURL string
+	if len(album.Images) > 0 {
+		imageURL = album.Images[0].URL
+	}
+
+	lib := library.AlbumDTO{
+		ID:        uuid.NewString(),
+		SpotifyID: album.ID.String(),
+		Title:     album.Name,
+		ImageURL:  imageURL,
+		Artists:   make([]library.ArtistDTO, len(album.Artists)),
+		Tracks:    []library.TrackDTO{},
+		Releases: []library.ReleaseDTO{
+			{
+				ID:      uuid.NewString(),
+				Format:  models.ReleaseFormatDigital,
+				AddedAt: addedAt,
+			},
+		},
+	}
+
+	for i, artist := range album.Artists {
+		lib.Artists[i] = library.ArtistDTO{
+			ID:        uuid.NewString(),
+			SpotifyID: artist.ID.String(),
+			Name:      artist.Name,
+		}
+	}
+
+	// Artists on the tracks but not on the album are featured on it.
+	credited := make(map[string]bool, len(album.Artists))
+	for _, artist := range album.Artists {
+		credited[artist.ID.String()] = true
+	}
+	for _, track := range tracks {
+		for _, artist := range track.Artists {
+			if credited[artist.ID.String()] {
+				continue
+			}
+			credited[artist.ID.String()] = true
+			lib.Featured = append(lib.Featured, library.ArtistDTO{
+				ID:        uuid.NewString(),
+				SpotifyID: artist.ID.String(),
+				Name:      artist.Name,
+			})
+		}
+	}
+
+	for _, track := range tracks {
+		lib.Tracks = append(lib.Tracks, library.TrackDTO{
+			ID:        uuid.NewString(),
+			SpotifyID: track.ID.String(),
+			Title:     track.Name,
+		})
+	}
+
+	return lib
+}
+
 func (s *Service) syncAlbumsToLibrary(ctx contextx.ContextX, feed FeedDTO, syncWindow *time.Duration) error {
 	var savedAlbums []spotify.SavedAlbum
 	var err error
@@ -161,49 +226,12 @@ func (s *Service) syncAlbumsToLibrary(ctx contextx.ContextX, feed FeedDTO, syncW
 		var addedAt *time.Time = nil
 		_addedAt, err := time.Parse(time.RFC3339, album.AddedAt)
 		if err != nil {
-			slog.Error("failed to parse added at time during syncSpotifyFeed", err)
+			slog.Error("failed to parse added at time during syncSpotifyFeed", "error", err)
 		} else {
 			addedAt = &_addedAt
 		}
 
-		var imageURL string
-		if len(album.Images) > 0 {
-			imageURL = album.Images[0].URL
-		}
-
-		lib := library.AlbumDTO{
-			ID:        uuid.NewString(),
-			SpotifyID: album.ID.String(),
-			Title:     album.Name,
-			ImageURL:  imageURL,
-			Artists:   make([]library.ArtistDTO, len(album.Artists)),
-			Tracks:    []library.TrackDTO{},
-			Releases: []library.ReleaseDTO{
-				{
-					ID:      uuid.NewString(),
-					Format:  models.ReleaseFormatDigital,
-					AddedAt: addedAt,
-				},
-			},
-		}
-
-		for i, artist := range album.Artists {
-			lib.Artists[i] = library.ArtistDTO{
-				ID:        uuid.NewString(),
-				SpotifyID: artist.ID.String(),
-				Name:      artist.Name,
-			}
-		}
-
-		for _, track := range album.Tracks.Tracks {
-			lib.Tracks = append(lib.Tracks, library.TrackDTO{
-				ID:        uuid.NewString(),
-				SpotifyID: track.ID.String(),
-				Title:     track.Name,
-			})
-		}
-
-		albumsToSync[i] = lib
+		albumsToSync[i] = newAlbumDTOFromSpotify(album.SimpleAlbum, album.Tracks.Tracks, addedAt)
 	}
 
 	err = s.libraryService.AddAlbumsToLibrary(ctx, feed.UserID, albumsToSync)
@@ -212,9 +240,48 @@ func (s *Service) syncAlbumsToLibrary(ctx contextx.ContextX, feed FeedDTO, syncW
 		return err
 	}
 
+	// The library is already synced, so a wishlist that can't be updated
+	// waits for the next sync.
+	_, err = s.wishlistService.FulfillFromLibrary(ctx, feed.UserID)
+	if err != nil {
+		slog.Error("failed to fulfill wishlist during syncSpotifyFeed", "userId", feed.UserID, "error", err)
+	}
+
 	return nil
 }
 
+// AddSpotifyAlbum adds an album from Spotify's catalogue to the user's library
+// as a digital release, without saving it to their Spotify library, and
+// returns its library id.
+func (s *Service) AddSpotifyAlbum(ctx contextx.ContextX, userID string, spotifyAlbumID string) (string, error) {
+	album, err := s.spotifyService.GetAlbum(ctx, userID, spotifyAlbumID)
+	if err != nil {
+		err = fmt.Errorf("failed to get spotify album: %w", err)
+		return "", err
+	}
+
+	err = s.libraryService.AddAlbumsToLibrary(ctx, userID, []library.AlbumDTO{
+		newAlbumDTOFromSpotify(album.SimpleAlbum, album.Tracks.Tracks, utils.NewPointer(time.Now())),
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to add album to library: %w", err)
+		return "", err
+	}
+
+	_, err = s.wishlistService.FulfillFromLibrary(ctx, userID)
+	if err != nil {
+		slog.Error("failed to fulfill wishlist after adding a spotify album", "userId", userID, "error", err)
+	}
+
+	albumIds, err := s.libraryService.GetLibraryAlbumIdsBySpotifyIds(ctx, userID, []string{spotifyAlbumID})
+	if err != nil {
+		err = fmt.Errorf("failed to get added album: %w", err)
+		return "", err
+	}
+
+	return albumIds[spotifyAlbumID], nil
+}
+
 func (s *Service) SyncSpotifyFeed(ctx contextx.ContextX, feed FeedDTO) (*FeedDTO, error) {
 	if feed.Kind != models.FeedKindSpotify {
 		return nil, fmt.Errorf("feed kind must be spotify")
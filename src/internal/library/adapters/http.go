@@ -1,59 +1,146 @@
 package adapters
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"github.com/alecdray/wax/src/internal/core/contextx"
 	"github.com/alecdray/wax/src/internal/core/db/models"
+	"github.com/alecdray/wax/src/internal/core/httpx"
+	"github.com/alecdray/wax/src/internal/core/templates"
 	"github.com/alecdray/wax/src/internal/core/task"
 	"github.com/alecdray/wax/src/internal/feed"
 	"github.com/alecdray/wax/src/internal/library"
 	"github.com/alecdray/wax/src/internal/musicbrainz"
+	"github.com/alecdray/wax/src/internal/people"
+	"github.com/alecdray/wax/src/internal/review"
+	"github.com/alecdray/wax/src/internal/shelves"
 	"github.com/alecdray/wax/src/internal/spotify"
+	"github.com/alecdray/wax/src/internal/tags"
 )
 
 type HttpHandler struct {
-	spotifyAuth *spotify.AuthService
-	mb          *musicbrainz.Service
-	feedService *feed.Service
+	spotifyAuth    *spotify.AuthService
+	spotifyService *spotify.Service
+	mb             *musicbrainz.Service
+	feedService    *feed.Service
 	libraryService *library.Service
-	taskManager *task.TaskManager
+	shelvesService *shelves.Service
+	peopleService  *people.Service
+	taskManager    *task.TaskManager
 }
 
-func NewHttpHandler(spotifyAuth *spotify.AuthService, mb *musicbrainz.Service, feedService *feed.Service, libraryService *library.Service, taskManager *task.TaskManager) *HttpHandler {
+func NewHttpHandler(spotifyAuth *spotify.AuthService, spotifyService *spotify.Service, mb *musicbrainz.Service, feedService *feed.Service, libraryService *library.Service, shelvesService *shelves.Service, peopleService *people.Service, taskManager *task.TaskManager) *HttpHandler {
 	return &HttpHandler{
 		spotifyAuth:    spotifyAuth,
+		spotifyService: spotifyService,
 		mb:             mb,
 		feedService:    feedService,
 		libraryService: libraryService,
+		shelvesService: shelvesService,
+		peopleService:  peopleService,
 		taskManager:    taskManager,
 	}
 }
 
-func parseFilterParams(r *http.Request) library.FilterParams {
-	q := r.URL.Query()
+// ParseFilterParams reads library filters from query or form values. The
+// shelf filter is left out since it needs the shelf looked up, and a tag
+// query that can't be read is dropped like any other malformed value.
+func ParseFilterParams(ctx context.Context, q url.Values) library.FilterParams {
+	// Rating bounds arrive on the user's rating scale; filtering happens on
+	// stored, canonical ratings.
+	profile := review.RatingProfileFromContext(ctx)
 	var fp library.FilterParams
 	if minStr := q.Get("minRating"); minStr != "" {
 		if v, err := strconv.ParseFloat(minStr, 64); err == nil {
+			v = profile.FromScale(v)
 			fp.MinRating = &v
 		}
 	}
 	if maxStr := q.Get("maxRating"); maxStr != "" {
 		if v, err := strconv.ParseFloat(maxStr, 64); err == nil {
+			v = profile.FromScale(v)
 			fp.MaxRating = &v
 		}
 	}
+	if dimension := q.Get("ratingDimension"); dimension != "" {
+		fp.RatingDimension = review.ParseRatingDimension(dimension)
+	}
 	fp.Rated = q.Get("rated")
 	if format := q.Get("format"); format != "" {
 		fp.Formats = []models.ReleaseFormat{models.ReleaseFormat(format)}
 	}
 	fp.ArtistIDs = q["artist"]
+	fp.TagIDs = q["tag"]
+	if text := q.Get("tags"); strings.TrimSpace(text) != "" {
+		if query, err := tags.ParseQuery(text); err == nil {
+			fp.TagQuery = query
+		}
+	}
+	fp.IntroducedByIDs = q["introducedBy"]
 	return fp
 }
 
+func (h *HttpHandler) parseFilterParams(ctx context.Context, userId string, r *http.Request) (library.FilterParams, error) {
+	q := r.URL.Query()
+	fp := ParseFilterParams(ctx, q)
+	fp.Search = strings.TrimSpace(q.Get("q"))
+	if fp.TagQuery == nil && strings.TrimSpace(q.Get("tags")) != "" {
+		// Parse again for the reason the query was dropped.
+		_, err := tags.ParseQuery(q.Get("tags"))
+		return fp, err
+	}
+	shelfId := q.Get("shelf")
+	if shelfId == "" {
+		return fp, nil
+	}
+	shelf, err := h.shelvesService.GetShelf(ctx, userId, shelfId)
+	if errors.Is(err, shelves.ErrShelfNotFound) {
+		// A deleted shelf just drops out of the filter.
+		return fp, nil
+	} else if err != nil {
+		return fp, err
+	}
+	fp.Shelf = &shelf
+	return fp, nil
+}
+
+// handleFilterError answers filters that can't be applied. A tag query that
+// can't be read is explained in the tag chip's dialog.
+func handleFilterError(ctx context.Context, w http.ResponseWriter, err error) {
+	props := httpx.HandleErrorResponseProps{
+		Status: http.StatusInternalServerError,
+		Err:    fmt.Errorf("failed to parse filters: %w", err),
+	}
+	if errors.Is(err, tags.ErrInvalidTagQuery) {
+		props.Status = http.StatusUnprocessableEntity
+		props.Response = *httpx.NewErrorResponse().SetComponent(TagQueryError(err.Error()))
+	}
+	httpx.HandleErrorResponse(ctx, w, props)
+}
+
+// parseAlbumsQuery reads the sort and page of the album list. The list sorts
+// by date added, newest first, unless told otherwise. Relevance only applies
+// to a search.
+func parseAlbumsQuery(r *http.Request, fp library.FilterParams) library.AlbumsQuery {
+	q := r.URL.Query()
+	sort := library.ParseAlbumSort(q.Get("sortBy"))
+	if sort == library.AlbumSortRelevance && library.SearchMatch(fp.Search) == "" {
+		sort = library.AlbumSortDate
+	}
+	return library.AlbumsQuery{
+		Filter:    fp,
+		Sort:      sort,
+		Ascending: q.Get("dir") == "asc",
+		After:     q.Get("after"),
+	}
+}
+
 func (h *HttpHandler) GetDashboardPage(w http.ResponseWriter, r *http.Request) {
 	ctx := contextx.NewContextX(r.Context())
 
@@ -75,14 +162,48 @@ func (h *HttpHandler) GetDashboardPage(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	lib, err := h.libraryService.GetLibrary(ctx, userId)
+	fp, err := h.parseFilterParams(ctx, userId, r)
+	if err != nil {
+		handleFilterError(ctx, w, err)
+		return
+	}
+
+	firstPage, err := h.libraryService.QueryAlbums(ctx, userId, library.AlbumsQuery{Filter: fp})
 	if err != nil {
-		err = fmt.Errorf("failed to get library: %w", err)
+		err = fmt.Errorf("failed to get albums: %w", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	lib.Albums.SortByDate(false)
+	stats, err := h.libraryService.GetLibraryStats(ctx, userId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	artists, err := h.libraryService.GetLibraryArtists(ctx, userId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	libraryTags, err := h.libraryService.GetLibraryTags(ctx, userId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	userShelves, err := h.shelvesService.GetUserShelves(ctx, userId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	userPeople, err := h.peopleService.GetUserPeople(ctx, userId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	recentAlbums, err := h.libraryService.GetRecentlyPlayedAlbums(ctx, userId)
 	if err != nil {
@@ -92,12 +213,15 @@ func (h *HttpHandler) GetDashboardPage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	dashboardPage := DashboardPage(DashboardPageProps{
-		Library:         lib,
-		Feeds:           feeds,
-		RecentAlbums:    recentAlbums,
-		FirstPageAlbums: lib.Albums.Page(0),
-		Artists:         lib.Artists,
-		FilterParams:    library.FilterParams{},
+		Stats:        stats,
+		Feeds:        feeds,
+		RecentAlbums: recentAlbums,
+		FirstPage:    firstPage,
+		Artists:      artists,
+		Tags:         libraryTags,
+		Shelves:      userShelves,
+		People:       userPeople,
+		FilterParams: fp,
 	})
 	dashboardPage.Render(r.Context(), w)
 }
@@ -120,6 +244,8 @@ func (h *HttpHandler) GetCarousel(w http.ResponseWriter, r *http.Request) {
 	switch view {
 	case CarouselViewUnrated:
 		albums, err = h.libraryService.GetUnratedAlbums(ctx, userId)
+	case CarouselViewRevisit:
+		albums, err = h.libraryService.GetRevisitAlbums(ctx, userId)
 	default:
 		view = CarouselViewRecentlyPlayed
 		albums, err = h.libraryService.GetRecentlyPlayedAlbums(ctx, userId)
@@ -141,41 +267,47 @@ func (h *HttpHandler) GetAlbumsTable(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	lib, err := h.libraryService.GetLibrary(ctx, userId)
+	fp, err := h.parseFilterParams(ctx, userId, r)
 	if err != nil {
-		err = fmt.Errorf("failed to get library: %w", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		handleFilterError(ctx, w, err)
 		return
 	}
 
-	if lib == nil {
-		http.Error(w, "library not found", http.StatusNotFound)
+	query := parseAlbumsQuery(r, fp)
+	query.After = ""
+	page, err := h.libraryService.QueryAlbums(ctx, userId, query)
+	if err != nil {
+		err = fmt.Errorf("failed to get albums: %w", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	albums := lib.Albums
-	sortBy := r.URL.Query().Get("sortBy")
-	dir := r.URL.Query().Get("dir")
+	artists, err := h.libraryService.GetLibraryArtists(ctx, userId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
-	ascending := dir != "desc"
+	libraryTags, err := h.libraryService.GetLibraryTags(ctx, userId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
-	switch sortBy {
-	case "album":
-		albums.SortByTitle(ascending)
-	case "artist":
-		albums.SortByArtist(ascending)
-	case "rating":
-		albums.SortByRating(ascending)
-	case "date":
-		albums.SortByDate(ascending)
-	case "lastPlayed":
-		albums.SortByLastPlayed(ascending)
+	userShelves, err := h.shelvesService.GetUserShelves(ctx, userId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	fp := parseFilterParams(r)
-	albums = albums.Filter(fp)
+	userPeople, err := h.peopleService.GetUserPeople(ctx, userId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
-	component := AlbumsList(albums.Page(0), sortBy, dir, fp, lib.Artists)
+	dir := r.URL.Query().Get("dir")
+	component := AlbumsList(page, string(query.Sort), dir, fp, artists, libraryTags, userShelves, userPeople)
 	component.Render(r.Context(), w)
 }
 
@@ -234,42 +366,29 @@ func (h *HttpHandler) GetAlbumsPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	lib, err := h.libraryService.GetLibrary(ctx, userId)
+	fp, err := h.parseFilterParams(ctx, userId, r)
 	if err != nil {
-		err = fmt.Errorf("failed to get library: %w", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		handleFilterError(ctx, w, err)
 		return
 	}
 
-	sortBy := r.URL.Query().Get("sortBy")
-	dir := r.URL.Query().Get("dir")
-	offset := 0
-	fmt.Sscanf(r.URL.Query().Get("offset"), "%d", &offset)
-
-	ascending := dir != "desc"
-	albums := lib.Albums
-	switch sortBy {
-	case "album":
-		albums.SortByTitle(ascending)
-	case "artist":
-		albums.SortByArtist(ascending)
-	case "rating":
-		albums.SortByRating(ascending)
-	case "date":
-		albums.SortByDate(ascending)
-	case "lastPlayed":
-		albums.SortByLastPlayed(ascending)
+	query := parseAlbumsQuery(r, fp)
+	page, err := h.libraryService.QueryAlbums(ctx, userId, query)
+	if errors.Is(err, library.ErrInvalidCursor) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		err = fmt.Errorf("failed to get albums: %w", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	fp := parseFilterParams(r)
-	albums = albums.Filter(fp)
-
-	page := albums.Page(offset)
-	if len(page) == 0 {
+	if len(page.Albums) == 0 {
 		return
 	}
 
-	albumsListBody(page, offset, sortBy, dir, fp).Render(r.Context(), w)
+	dir := r.URL.Query().Get("dir")
+	albumsListBody(page, false, string(query.Sort), dir, fp).Render(r.Context(), w)
 }
 
 func (h *HttpHandler) GetAlbumDetailPage(w http.ResponseWriter, r *http.Request) {
@@ -318,3 +437,240 @@ func (h *HttpHandler) GetFeedsDropdown(w http.ResponseWriter, r *http.Request) {
 	buttonComponent := FeedsDropdownButton(feeds, true)
 	buttonComponent.Render(r.Context(), w)
 }
+
+// GetArtistPage shows an artist with the user's albums by them.
+func (h *HttpHandler) GetArtistPage(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	overview, err := h.libraryService.GetArtistOverview(ctx, userId, r.PathValue("artistId"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, library.ErrArtistNotFound) {
+			status = http.StatusNotFound
+		}
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: status,
+			Err:    err,
+		})
+		return
+	}
+
+	artists, err := h.libraryService.GetLibraryArtists(ctx, userId)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+
+	err = ArtistPage(overview, library.FindArtistDuplicates(overview.Artist, artists)).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render artist page: %w", err),
+		})
+	}
+}
+
+// GetArtistDiscography answers the artist page with the artist's Spotify
+// discography and how much of it the user has covered.
+func (h *HttpHandler) GetArtistDiscography(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	overview, err := h.libraryService.GetArtistOverview(ctx, userId, r.PathValue("artistId"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, library.ErrArtistNotFound) {
+			status = http.StatusNotFound
+		}
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: status,
+			Err:    err,
+		})
+		return
+	}
+
+	spotifyAlbums, err := h.spotifyService.GetArtistAlbums(ctx, userId, overview.Artist.SpotifyID)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status:   http.StatusBadGateway,
+			Err:      fmt.Errorf("failed to get spotify artist albums: %w", err),
+			Response: *httpx.NewErrorResponse().SetComponent(ArtistDiscographyError("Spotify's discography isn't available right now.")),
+		})
+		return
+	}
+
+	err = ArtistDiscography(overview.Discography(spotifyAlbums)).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render discography: %w", err),
+		})
+	}
+}
+
+func handleArtistError(ctx contextx.ContextX, w http.ResponseWriter, err error) {
+	props := httpx.HandleErrorResponseProps{
+		Status: http.StatusInternalServerError,
+		Err:    err,
+	}
+	switch {
+	case errors.Is(err, library.ErrArtistNotFound), errors.Is(err, library.ErrArtistAliasMissing):
+		props.Status = http.StatusNotFound
+	case errors.Is(err, library.ErrInvalidArtistAlias), errors.Is(err, library.ErrMergeSameArtist):
+		props.Status = http.StatusUnprocessableEntity
+		props.Response = *httpx.NewErrorResponse().SetComponent(ArtistIdentityError(err.Error()))
+	case errors.Is(err, library.ErrArtistAliasTaken):
+		props.Status = http.StatusConflict
+		props.Response = *httpx.NewErrorResponse().SetComponent(ArtistIdentityError(err.Error()))
+	}
+	httpx.HandleErrorResponse(ctx, w, props)
+}
+
+// renderArtistIdentity answers a change to an artist's sort name or aliases
+// with the section redrawn.
+func (h *HttpHandler) renderArtistIdentity(ctx contextx.ContextX, w http.ResponseWriter, userId string, artistId string) {
+	overview, err := h.libraryService.GetArtistOverview(ctx, userId, artistId)
+	if err != nil {
+		handleArtistError(ctx, w, err)
+		return
+	}
+
+	artists, err := h.libraryService.GetLibraryArtists(ctx, userId)
+	if err != nil {
+		handleArtistError(ctx, w, err)
+		return
+	}
+
+	err = ArtistIdentity(overview, library.FindArtistDuplicates(overview.Artist, artists)).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render artist identity: %w", err),
+		})
+	}
+}
+
+// SetArtistSortName sets or clears the user's own sort name for an artist.
+func (h *HttpHandler) SetArtistSortName(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	artistId := r.PathValue("artistId")
+	err = h.libraryService.SetArtistSortName(ctx, userId, artistId, r.FormValue("sort_name"))
+	if err != nil {
+		handleArtistError(ctx, w, err)
+		return
+	}
+
+	h.renderArtistIdentity(ctx, w, userId, artistId)
+}
+
+// AddArtistAlias records another identity of an artist.
+func (h *HttpHandler) AddArtistAlias(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	artistId := r.PathValue("artistId")
+	_, err = h.libraryService.AddArtistAlias(
+		ctx,
+		artistId,
+		models.ArtistAliasSource(r.FormValue("source")),
+		r.FormValue("external_id"),
+		r.FormValue("name"),
+	)
+	if err != nil {
+		handleArtistError(ctx, w, err)
+		return
+	}
+
+	h.renderArtistIdentity(ctx, w, userId, artistId)
+}
+
+// DeleteArtistAlias removes one of an artist's aliases.
+func (h *HttpHandler) DeleteArtistAlias(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	artistId := r.PathValue("artistId")
+	err = h.libraryService.DeleteArtistAlias(ctx, artistId, r.PathValue("aliasId"))
+	if err != nil {
+		handleArtistError(ctx, w, err)
+		return
+	}
+
+	h.renderArtistIdentity(ctx, w, userId, artistId)
+}
+
+// MergeArtist folds a duplicate into an artist and reloads the artist's page
+// with the duplicate's albums.
+func (h *HttpHandler) MergeArtist(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	artistId := r.PathValue("artistId")
+	err = h.libraryService.MergeArtists(ctx, userId, artistId, r.FormValue("duplicate_id"))
+	if err != nil {
+		handleArtistError(ctx, w, err)
+		return
+	}
+
+	err = templates.Redirect(artistPath(artistId), 0).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+	}
+}
@@ -0,0 +1,618 @@
+package library
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/alecdray/wax/src/internal/core/db"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"github.com/alecdray/wax/src/internal/core/db/sqlc"
+	"github.com/alecdray/wax/src/internal/spotify"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrArtistNotFound     = errors.New("artist not found")
+	ErrInvalidArtistAlias = errors.New("an alias needs a name, and an ID unless it's only a name")
+	ErrArtistAliasTaken   = errors.New("that id is already an alias of another artist")
+	ErrArtistAliasMissing = errors.New("artist alias not found")
+	ErrMergeSameArtist    = errors.New("an artist can't be merged into itself")
+)
+
+// artistSortNames are a user's own sort names, by artist ID.
+type artistSortNames map[string]string
+
+// apply gives the artist the user's sort name for them, if they have one.
+func (n artistSortNames) apply(artist ArtistDTO) ArtistDTO {
+	if sortName, ok := n[artist.ID]; ok {
+		artist.SortName = sortName
+	}
+	return artist
+}
+
+func (s *Service) getArtistSortNames(ctx context.Context, userId string) (artistSortNames, error) {
+	rows, err := s.db.Queries().GetArtistSortNames(ctx, userId)
+	if err != nil {
+		err = fmt.Errorf("failed to get artist sort names: %w", err)
+		return nil, err
+	}
+
+	sortNames := make(artistSortNames, len(rows))
+	for _, row := range rows {
+		sortNames[row.ArtistID] = row.SortName
+	}
+	return sortNames, nil
+}
+
+// getOrCreateArtist finds the artist behind a Spotify artist, following the
+// aliases left by merges so a merged duplicate isn't created again.
+func getOrCreateArtist(ctx context.Context, queries *sqlc.Queries, artist ArtistDTO) (sqlc.Artist, error) {
+	model, err := queries.GetArtistBySpotifyAlias(ctx, sql.NullString{String: artist.SpotifyID, Valid: true})
+	if err == nil {
+		return model, nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		err = fmt.Errorf("failed to get artist alias: %w", err)
+		return sqlc.Artist{}, err
+	}
+
+	model, err = queries.GetOrCreateArtist(ctx, sqlc.GetOrCreateArtistParams{
+		ID:        artist.ID,
+		SpotifyID: artist.SpotifyID,
+		Name:      artist.Name,
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to get/create artist: %w", err)
+		return sqlc.Artist{}, err
+	}
+	return model, nil
+}
+
+// ArtistAliasDTO is another identity of an artist: a duplicate merged into
+// it, its id on another source, or a name entered by hand.
+type ArtistAliasDTO struct {
+	ID     string
+	Source models.ArtistAliasSource
+	// ExternalID is the artist's id on the source, empty for manual aliases.
+	ExternalID string
+	Name       string
+	CreatedAt  time.Time
+}
+
+func NewArtistAliasDTOFromModel(model sqlc.ArtistAlias) ArtistAliasDTO {
+	return ArtistAliasDTO{
+		ID:         model.ID,
+		Source:     model.Source,
+		ExternalID: model.ExternalID.String,
+		Name:       model.Name,
+		CreatedAt:  model.CreatedAt,
+	}
+}
+
+// URL links to the alias on its source, empty when it has none.
+func (a ArtistAliasDTO) URL() string {
+	if a.ExternalID == "" {
+		return ""
+	}
+	switch a.Source {
+	case models.ArtistAliasSourceSpotify:
+		return "https://open.spotify.com/artist/" + a.ExternalID
+	case models.ArtistAliasSourceMusicBrainz:
+		return "https://musicbrainz.org/artist/" + a.ExternalID
+	case models.ArtistAliasSourceDiscogs:
+		return "https://www.discogs.com/artist/" + a.ExternalID
+	default:
+		return ""
+	}
+}
+
+// ArtistAliasSources are the sources an alias can be added from by hand.
+// Spotify aliases only come from merging duplicates.
+var ArtistAliasSources = []models.ArtistAliasSource{
+	models.ArtistAliasSourceManual,
+	models.ArtistAliasSourceMusicBrainz,
+	models.ArtistAliasSourceDiscogs,
+}
+
+// ArtistAliasSourceLabel names an alias source for display.
+func ArtistAliasSourceLabel(source models.ArtistAliasSource) string {
+	switch source {
+	case models.ArtistAliasSourceSpotify:
+		return "Spotify"
+	case models.ArtistAliasSourceMusicBrainz:
+		return "MusicBrainz"
+	case models.ArtistAliasSourceDiscogs:
+		return "Discogs"
+	default:
+		return "Name"
+	}
+}
+
+// ArtistOverview is what the user has of an artist: their albums in the
+// library and how much they've listened.
+type ArtistOverview struct {
+	Artist ArtistDTO
+	// CustomSortName is whether the artist's sort name is the user's own.
+	CustomSortName bool
+	Aliases        []ArtistAliasDTO
+	// Albums are the library albums the artist is a primary credit on,
+	// highest rated first.
+	Albums []AlbumDTO
+	// AppearsOn are the library albums the artist is only featured on.
+	AppearsOn []AlbumDTO
+	// AverageRating is the mean overall rating of the rated albums, nil when
+	// none is rated.
+	AverageRating *float64
+	// Plays is how many plays the user has of tracks on the artist's albums,
+	// in the library or not.
+	Plays int64
+	// knownAlbums are all the albums credited to the artist, in the library
+	// or only listened to, with the user's plays of each.
+	knownAlbums []artistAlbum
+}
+
+type artistAlbum struct {
+	id        string
+	spotifyId string
+	title     string
+	plays     int64
+}
+
+// CoverageStatus is how far the user has got with an album of an artist's
+// discography.
+type CoverageStatus string
+
+const (
+	CoverageRated    CoverageStatus = "rated"
+	CoverageOwned    CoverageStatus = "owned"
+	CoverageHeard    CoverageStatus = "heard"
+	CoverageNotHeard CoverageStatus = "not_heard"
+)
+
+// CoverageStatuses are the statuses from furthest along to not started.
+var CoverageStatuses = []CoverageStatus{CoverageRated, CoverageOwned, CoverageHeard, CoverageNotHeard}
+
+func (s CoverageStatus) Label() string {
+	switch s {
+	case CoverageRated:
+		return "Rated"
+	case CoverageOwned:
+		return "Owned"
+	case CoverageHeard:
+		return "Heard"
+	default:
+		return "Not heard"
+	}
+}
+
+// DiscographyAlbum is an album from an artist's Spotify discography and
+// where the user stands with it.
+type DiscographyAlbum struct {
+	SpotifyID string
+	Title     string
+	ImageURL  string
+	// Year is the year it was released, when Spotify knows it.
+	Year string
+	// Type is Spotify's album type: album, single or compilation.
+	Type string
+	// AlbumID is the library album it matched, empty when it isn't in the
+	// library.
+	AlbumID string
+	Rating  *float64
+	Plays   int64
+	Status  CoverageStatus
+}
+
+// Discography is an artist's Spotify discography, newest first within each
+// album type, as Spotify lists it.
+type Discography struct {
+	Albums []DiscographyAlbum
+}
+
+// Count is how many albums of the discography have a status.
+func (d Discography) Count(status CoverageStatus) int {
+	count := 0
+	for _, album := range d.Albums {
+		if album.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Discography matches an artist's albums on Spotify against the library and
+// the user's plays. An album matches by its Spotify id or, failing that, by
+// title, since the library often holds a different release of it. Spotify
+// lists an album once per market it's released in; only the first is kept.
+func (o ArtistOverview) Discography(spotifyAlbums []spotify.SimpleAlbum) Discography {
+	libraryBySpotifyId := make(map[string]AlbumDTO, len(o.Albums)+len(o.AppearsOn))
+	libraryByTitle := make(map[string]AlbumDTO, len(o.Albums)+len(o.AppearsOn))
+	for _, album := range slices.Concat(o.Albums, o.AppearsOn) {
+		libraryBySpotifyId[album.SpotifyID] = album
+		if _, ok := libraryByTitle[strings.ToLower(album.Title)]; !ok {
+			libraryByTitle[strings.ToLower(album.Title)] = album
+		}
+	}
+	playsByAlbumId := make(map[string]int64, len(o.knownAlbums))
+	playsBySpotifyId := make(map[string]int64, len(o.knownAlbums))
+	playsByTitle := make(map[string]int64, len(o.knownAlbums))
+	for _, album := range o.knownAlbums {
+		playsByAlbumId[album.id] = album.plays
+		playsBySpotifyId[album.spotifyId] = album.plays
+		playsByTitle[strings.ToLower(album.title)] += album.plays
+	}
+
+	discography := Discography{Albums: make([]DiscographyAlbum, 0, len(spotifyAlbums))}
+	seen := make(map[string]bool, len(spotifyAlbums))
+	for _, spotifyAlbum := range spotifyAlbums {
+		title := strings.ToLower(spotifyAlbum.Name)
+		if seen[spotifyAlbum.AlbumType+"\x00"+title] {
+			continue
+		}
+		seen[spotifyAlbum.AlbumType+"\x00"+title] = true
+
+		album := DiscographyAlbum{
+			SpotifyID: spotifyAlbum.ID.String(),
+			Title:     spotifyAlbum.Name,
+			Type:      spotifyAlbum.AlbumType,
+			Status:    CoverageNotHeard,
+		}
+		// Spotify lists cover art widest first; the page shows it small.
+		if len(spotifyAlbum.Images) > 0 {
+			album.ImageURL = spotifyAlbum.Images[len(spotifyAlbum.Images)-1].URL
+		}
+		if year, _, _ := strings.Cut(spotifyAlbum.ReleaseDate, "-"); len(year) == 4 {
+			album.Year = year
+		}
+
+		libraryAlbum, inLibrary := libraryBySpotifyId[album.SpotifyID]
+		if !inLibrary {
+			libraryAlbum, inLibrary = libraryByTitle[title]
+		}
+		switch {
+		case inLibrary:
+			album.AlbumID = libraryAlbum.ID
+			album.Plays = playsByAlbumId[libraryAlbum.ID]
+			album.Status = CoverageOwned
+			if libraryAlbum.Rating != nil && libraryAlbum.Rating.Rating != nil {
+				album.Rating = libraryAlbum.Rating.Rating
+				album.Status = CoverageRated
+			}
+		case playsBySpotifyId[album.SpotifyID] > 0:
+			album.Plays = playsBySpotifyId[album.SpotifyID]
+			album.Status = CoverageHeard
+		case playsByTitle[title] > 0:
+			album.Plays = playsByTitle[title]
+			album.Status = CoverageHeard
+		}
+
+		discography.Albums = append(discography.Albums, album)
+	}
+	return discography
+}
+
+// GetArtistOverview returns an artist with the user's albums by them, their
+// average rating and the user's plays.
+func (s *Service) GetArtistOverview(ctx context.Context, userId string, artistId string) (ArtistOverview, error) {
+	artist, err := s.db.Queries().GetArtist(ctx, artistId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ArtistOverview{}, ErrArtistNotFound
+	} else if err != nil {
+		err = fmt.Errorf("failed to get artist: %w", err)
+		return ArtistOverview{}, err
+	}
+	overview := ArtistOverview{Artist: NewArtistDTOFromModel(artist)}
+
+	sortNames, err := s.getArtistSortNames(ctx, userId)
+	if err != nil {
+		return ArtistOverview{}, err
+	}
+	_, overview.CustomSortName = sortNames[artistId]
+	overview.Artist = sortNames.apply(overview.Artist)
+
+	aliases, err := s.db.Queries().GetArtistAliases(ctx, artistId)
+	if err != nil {
+		err = fmt.Errorf("failed to get artist aliases: %w", err)
+		return ArtistOverview{}, err
+	}
+	for _, alias := range aliases {
+		overview.Aliases = append(overview.Aliases, NewArtistAliasDTOFromModel(alias))
+	}
+
+	albums, err := s.QueryAllAlbums(ctx, userId, AlbumsQuery{
+		Filter: FilterParams{ArtistIDs: []string{artistId}},
+		Sort:   AlbumSortRating,
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to get artist albums: %w", err)
+		return ArtistOverview{}, err
+	}
+	for _, album := range albums {
+		if slices.ContainsFunc(album.Artists, func(a ArtistDTO) bool { return a.ID == artistId }) {
+			overview.Albums = append(overview.Albums, album)
+		} else {
+			overview.AppearsOn = append(overview.AppearsOn, album)
+		}
+	}
+
+	var ratingSum float64
+	var rated int
+	for _, album := range overview.Albums {
+		if album.Rating != nil && album.Rating.Rating != nil {
+			ratingSum += *album.Rating.Rating
+			rated++
+		}
+	}
+	if rated > 0 {
+		average := ratingSum / float64(rated)
+		overview.AverageRating = &average
+	}
+
+	rows, err := s.db.Queries().GetArtistAlbumPlays(ctx, sqlc.GetArtistAlbumPlaysParams{
+		UserID:   userId,
+		ArtistID: artistId,
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to get artist plays: %w", err)
+		return ArtistOverview{}, err
+	}
+	for _, row := range rows {
+		overview.knownAlbums = append(overview.knownAlbums, artistAlbum{
+			id:        row.ID,
+			spotifyId: row.SpotifyID,
+			title:     row.Title,
+			plays:     row.Plays,
+		})
+		overview.Plays += row.Plays
+	}
+
+	return overview, nil
+}
+
+// ArtistDuplicates are the library artists that could be merged into an
+// artist, those with the same name first.
+type ArtistDuplicates struct {
+	// Likely have the same name, ignoring case, punctuation and a leading
+	// "The".
+	Likely []ArtistDTO
+	Others []ArtistDTO
+}
+
+// FindArtistDuplicates sorts the other library artists into likely
+// duplicates of an artist and the rest.
+func FindArtistDuplicates(artist ArtistDTO, artists []ArtistDTO) ArtistDuplicates {
+	var duplicates ArtistDuplicates
+	name := comparableArtistName(artist.Name)
+	for _, other := range artists {
+		if other.ID == artist.ID {
+			continue
+		}
+		if comparableArtistName(other.Name) == name {
+			duplicates.Likely = append(duplicates.Likely, other)
+		} else {
+			duplicates.Others = append(duplicates.Others, other)
+		}
+	}
+	return duplicates
+}
+
+func comparableArtistName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.TrimPrefix(name, "the ")
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, name)
+}
+
+// SetArtistSortName sets the user's own sort name for an artist. An empty
+// sort name goes back to the one derived from the artist's name.
+func (s *Service) SetArtistSortName(ctx context.Context, userId string, artistId string, sortName string) error {
+	sortName = strings.TrimSpace(sortName)
+	if sortName == "" {
+		err := s.db.Queries().DeleteArtistSortName(ctx, sqlc.DeleteArtistSortNameParams{
+			UserID:   userId,
+			ArtistID: artistId,
+		})
+		if err != nil {
+			err = fmt.Errorf("failed to delete artist sort name: %w", err)
+			return err
+		}
+		return nil
+	}
+
+	err := s.db.Queries().UpsertArtistSortName(ctx, sqlc.UpsertArtistSortNameParams{
+		UserID:   userId,
+		ArtistID: artistId,
+		SortName: sortName,
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to set artist sort name: %w", err)
+		return err
+	}
+	return nil
+}
+
+// AddArtistAlias records another identity of an artist. Only MusicBrainz and
+// Discogs aliases carry an id, which can belong to one artist only.
+func (s *Service) AddArtistAlias(ctx context.Context, artistId string, source models.ArtistAliasSource, externalId string, name string) (ArtistAliasDTO, error) {
+	name = strings.TrimSpace(name)
+	externalId = strings.TrimSpace(externalId)
+	if name == "" || !slices.Contains(ArtistAliasSources, source) {
+		return ArtistAliasDTO{}, ErrInvalidArtistAlias
+	}
+	if source == models.ArtistAliasSourceManual {
+		externalId = ""
+	} else if externalId == "" {
+		return ArtistAliasDTO{}, ErrInvalidArtistAlias
+	}
+	externalIdParam := sql.NullString{String: externalId, Valid: externalId != ""}
+
+	var alias sqlc.ArtistAlias
+	err := s.db.WithTx(func(tx *db.DB) error {
+		_, err := tx.Queries().GetArtist(ctx, artistId)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrArtistNotFound
+		} else if err != nil {
+			return fmt.Errorf("failed to get artist: %w", err)
+		}
+
+		if externalIdParam.Valid {
+			_, err := tx.Queries().GetArtistAlias(ctx, sqlc.GetArtistAliasParams{
+				Source:     source,
+				ExternalID: externalIdParam,
+			})
+			if err == nil {
+				return ErrArtistAliasTaken
+			} else if !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("failed to get artist alias: %w", err)
+			}
+		}
+
+		alias, err = tx.Queries().CreateArtistAlias(ctx, sqlc.CreateArtistAliasParams{
+			ID:         uuid.NewString(),
+			ArtistID:   artistId,
+			Source:     source,
+			ExternalID: externalIdParam,
+			Name:       name,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create artist alias: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return ArtistAliasDTO{}, err
+	}
+	return NewArtistAliasDTOFromModel(alias), nil
+}
+
+// DeleteArtistAlias removes one of an artist's aliases. Removing a Spotify
+// alias lets the duplicate it came from be created again by the next sync.
+func (s *Service) DeleteArtistAlias(ctx context.Context, artistId string, aliasId string) error {
+	deleted, err := s.db.Queries().DeleteArtistAlias(ctx, sqlc.DeleteArtistAliasParams{
+		ID:       aliasId,
+		ArtistID: artistId,
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to delete artist alias: %w", err)
+		return err
+	}
+	if deleted == 0 {
+		return ErrArtistAliasMissing
+	}
+	return nil
+}
+
+// MergeArtists folds a duplicate into an artist: the duplicate's credits,
+// aliases and sort names move over, its Spotify profile becomes an alias so
+// syncs credit the artist instead, and the duplicate is deleted. Artists are
+// shared, so the merge applies to every user's library, and only artists
+// credited in the user's own library can be merged.
+func (s *Service) MergeArtists(ctx context.Context, userId string, artistId string, duplicateId string) error {
+	if artistId == duplicateId {
+		return ErrMergeSameArtist
+	}
+
+	return s.db.WithTx(func(tx *db.DB) error {
+		_, err := getLibraryArtist(ctx, tx, userId, artistId)
+		if err != nil {
+			return err
+		}
+		duplicate, err := getLibraryArtist(ctx, tx, userId, duplicateId)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Queries().MoveAlbumArtists(ctx, sqlc.MoveAlbumArtistsParams{
+			ArtistID:    artistId,
+			DuplicateID: duplicateId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to move album artists: %w", err)
+		}
+		err = tx.Queries().MoveArtistAliases(ctx, sqlc.MoveArtistAliasesParams{
+			ArtistID:    artistId,
+			DuplicateID: duplicateId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to move artist aliases: %w", err)
+		}
+		// A user's sort name for the artist wins over theirs for the duplicate.
+		err = tx.Queries().MoveArtistSortNames(ctx, sqlc.MoveArtistSortNamesParams{
+			ArtistID:    artistId,
+			DuplicateID: duplicateId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to move artist sort names: %w", err)
+		}
+		err = tx.Queries().MoveUserArtists(ctx, sqlc.MoveUserArtistsParams{
+			ArtistID:    artistId,
+			DuplicateID: duplicateId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to move user artists: %w", err)
+		}
+
+		err = tx.Queries().UpsertArtistAlias(ctx, sqlc.UpsertArtistAliasParams{
+			ID:         uuid.NewString(),
+			ArtistID:   artistId,
+			Source:     models.ArtistAliasSourceSpotify,
+			ExternalID: sql.NullString{String: duplicate.SpotifyID, Valid: true},
+			Name:       duplicate.Name,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create artist alias: %w", err)
+		}
+
+		// Foreign keys aren't enforced, so what the moves left on the
+		// duplicate has to go before it does.
+		err = tx.Queries().DeleteAlbumArtistsByArtistId(ctx, duplicateId)
+		if err != nil {
+			return fmt.Errorf("failed to delete album artists: %w", err)
+		}
+		err = tx.Queries().DeleteUserArtistsByArtistId(ctx, duplicateId)
+		if err != nil {
+			return fmt.Errorf("failed to delete user artists: %w", err)
+		}
+		err = tx.Queries().DeleteArtistSortNamesByArtistId(ctx, duplicateId)
+		if err != nil {
+			return fmt.Errorf("failed to delete artist sort names: %w", err)
+		}
+
+		err = tx.Queries().DeleteArtist(ctx, duplicateId)
+		if err != nil {
+			return fmt.Errorf("failed to delete artist: %w", err)
+		}
+		return nil
+	})
+}
+
+// getLibraryArtist gets an artist credited on an album in the user's library.
+// Any other artist is reported as not found.
+func getLibraryArtist(ctx context.Context, tx *db.DB, userId string, artistId string) (sqlc.Artist, error) {
+	inLibrary, err := tx.Queries().IsArtistInUserLibrary(ctx, sqlc.IsArtistInUserLibraryParams{
+		ArtistID: artistId,
+		UserID:   userId,
+	})
+	if err != nil {
+		return sqlc.Artist{}, fmt.Errorf("failed to check the user's library for the artist: %w", err)
+	}
+	if inLibrary == 0 {
+		return sqlc.Artist{}, ErrArtistNotFound
+	}
+	artist, err := tx.Queries().GetArtist(ctx, artistId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return sqlc.Artist{}, ErrArtistNotFound
+	} else if err != nil {
+		return sqlc.Artist{}, fmt.Errorf("failed to get artist: %w", err)
+	}
+	return artist, nil
+}
@@ -0,0 +1,399 @@
+package library
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/alecdray/wax/src/internal/review"
+	"github.com/alecdray/wax/src/internal/tags"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("invalid page cursor")
+
+// AlbumSort is what the library list is sorted on.
+type AlbumSort string
+
+const (
+	AlbumSortDate       AlbumSort = "date"
+	AlbumSortTitle      AlbumSort = "album"
+	AlbumSortArtist     AlbumSort = "artist"
+	AlbumSortRating     AlbumSort = "rating"
+	AlbumSortQuality    AlbumSort = "quality"
+	AlbumSortEnjoyment  AlbumSort = "enjoyment"
+	AlbumSortLastPlayed AlbumSort = "lastPlayed"
+	// AlbumSortRelevance ranks albums by how well they match the search.
+	// Without one it sorts by date added.
+	AlbumSortRelevance AlbumSort = "relevance"
+)
+
+// ParseAlbumSort reads a sort from a request. Anything else sorts by date
+// added.
+func ParseAlbumSort(value string) AlbumSort {
+	switch sort := AlbumSort(value); sort {
+	case AlbumSortTitle, AlbumSortArtist, AlbumSortRating, AlbumSortQuality, AlbumSortEnjoyment, AlbumSortLastPlayed, AlbumSortRelevance:
+		return sort
+	default:
+		return AlbumSortDate
+	}
+}
+
+// Sort sorts the albums in memory the way QueryAlbums sorts them in SQL.
+// Albums without a value sort first ascending and last descending, and ties
+// fall back to the album ID in the same direction. Search relevance isn't
+// known in memory, so it sorts by date added.
+func (albums AlbumDTOs) Sort(by AlbumSort, ascending bool) {
+	albums.sortByID(ascending)
+	switch by {
+	case AlbumSortTitle:
+		albums.SortByTitle(ascending)
+	case AlbumSortArtist:
+		albums.SortByArtist(ascending)
+	case AlbumSortRating:
+		albums.SortByRating(ascending)
+	case AlbumSortQuality, AlbumSortEnjoyment:
+		albums.SortByRatingDimension(review.ParseRatingDimension(string(by)), ascending)
+	case AlbumSortLastPlayed:
+		albums.SortByLastPlayed(ascending)
+	default:
+		albums.SortByDate(ascending)
+	}
+}
+
+// AlbumsQuery asks for one page of the library, filtered and sorted.
+type AlbumsQuery struct {
+	Filter    FilterParams
+	Sort      AlbumSort
+	Ascending bool
+	// After is the Next cursor of the previous page, empty for the first.
+	After string
+	// Limit is the page size, AlbumsPageSize when zero.
+	Limit int
+}
+
+// AlbumsPage is a page of the library. Its albums carry everything but their
+// tracks.
+type AlbumsPage struct {
+	Albums AlbumDTOs
+	// Next is the cursor for the following page, empty on the last one.
+	Next string
+}
+
+// albumCursor marks the last album on a page by its sort value and ID, so
+// the next page picks up after it however the library has changed since.
+type albumCursor struct {
+	Key any    `json:"k"`
+	ID  string `json:"id"`
+}
+
+func (c albumCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeAlbumCursor(cursor string) (albumCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return albumCursor{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+	var c albumCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return albumCursor{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+	switch c.Key.(type) {
+	case nil, string, float64:
+	default:
+		return albumCursor{}, fmt.Errorf("%w: unexpected sort value", ErrInvalidCursor)
+	}
+	return c, nil
+}
+
+// librarySQL holds each library album with the date it was first added and
+// its latest rating log entry, as rated. It takes the user ID twice.
+const librarySQL = `WITH library AS (
+	SELECT releases.album_id AS id, MIN(user_releases.added_at) AS added_at
+	FROM user_releases
+	JOIN releases ON user_releases.release_id = releases.id
+	WHERE user_releases.user_id = ?
+	GROUP BY releases.album_id
+), rated AS (
+	SELECT library.id, library.added_at, (
+		SELECT album_rating_log.id FROM album_rating_log
+		WHERE album_rating_log.user_id = ? AND album_rating_log.album_id = library.id
+		ORDER BY album_rating_log.created_at DESC, album_rating_log.rowid DESC
+		LIMIT 1
+	) AS rating_id
+	FROM library
+)
+`
+
+// albumsQuerySQL selects a page of library album IDs with their sort values.
+// The placeholders take the sort value, the search join, the filter
+// conditions, the cursor condition and the direction.
+const albumsQuerySQL = librarySQL + `SELECT id, sort_key FROM (
+	SELECT rated.id, %s AS sort_key FROM rated
+	JOIN albums ON albums.id = rated.id
+	%s
+	WHERE %s
+)
+WHERE %s
+ORDER BY sort_key %[5]s, id %[5]s
+LIMIT ?`
+
+// QueryAlbums returns a page of the library with the filtering, sorting and
+// paging done in SQL. It matches sorting the whole library in memory with
+// Sort, without loading it.
+func (s *Service) QueryAlbums(ctx context.Context, userId string, q AlbumsQuery) (AlbumsPage, error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = AlbumsPageSize
+	}
+
+	args := []any{userId, userId}
+	sortKey, sortArgs := albumSortKeySQL(userId, q.Sort, q.Filter.Search)
+	args = append(args, sortArgs...)
+	join, joinArgs := albumSearchJoinSQL(userId, q.Filter.Search)
+	args = append(args, joinArgs...)
+	where, filterArgs := albumFilterSQL(userId, q.Filter)
+	args = append(args, filterArgs...)
+
+	after := "1"
+	if q.After != "" {
+		cursor, err := decodeAlbumCursor(q.After)
+		if err != nil {
+			return AlbumsPage{}, err
+		}
+		var afterArgs []any
+		after, afterArgs = albumCursorSQL(cursor, q.Ascending)
+		args = append(args, afterArgs...)
+	}
+
+	direction := "DESC"
+	if q.Ascending {
+		direction = "ASC"
+	}
+	// One extra row tells whether there's a next page.
+	args = append(args, limit+1)
+
+	rows, err := s.db.Sql().QueryContext(ctx, fmt.Sprintf(albumsQuerySQL, sortKey, join, where, after, direction), args...)
+	if err != nil {
+		return AlbumsPage{}, fmt.Errorf("failed to query albums: %w", err)
+	}
+	defer rows.Close()
+
+	var albumIds []string
+	var cursors []albumCursor
+	for rows.Next() {
+		var cursor albumCursor
+		if err := rows.Scan(&cursor.ID, &cursor.Key); err != nil {
+			return AlbumsPage{}, fmt.Errorf("failed to scan album: %w", err)
+		}
+		if key, ok := cursor.Key.([]byte); ok {
+			cursor.Key = string(key)
+		} else if key, ok := cursor.Key.(int64); ok {
+			cursor.Key = float64(key)
+		}
+		albumIds = append(albumIds, cursor.ID)
+		cursors = append(cursors, cursor)
+	}
+	if err := rows.Err(); err != nil {
+		return AlbumsPage{}, fmt.Errorf("failed to query albums: %w", err)
+	}
+
+	var page AlbumsPage
+	if len(albumIds) > limit {
+		albumIds = albumIds[:limit]
+		page.Next = cursors[limit-1].encode()
+	}
+	page.Albums, err = s.getAlbumsByIds(ctx, userId, albumIds)
+	if err != nil {
+		return AlbumsPage{}, err
+	}
+	return page, nil
+}
+
+// allAlbumsPageSize is the page size QueryAllAlbums loads at a time.
+const allAlbumsPageSize = 500
+
+// QueryAllAlbums returns every album the query matches, a page at a time.
+func (s *Service) QueryAllAlbums(ctx context.Context, userId string, q AlbumsQuery) (AlbumDTOs, error) {
+	if q.Limit <= 0 {
+		q.Limit = allAlbumsPageSize
+	}
+	var albums AlbumDTOs
+	for {
+		page, err := s.QueryAlbums(ctx, userId, q)
+		if err != nil {
+			return nil, err
+		}
+		albums = append(albums, page.Albums...)
+		if page.Next == "" {
+			return albums, nil
+		}
+		q.After = page.Next
+	}
+}
+
+// albumsCountSQL counts the library albums passing the search join and the
+// filter conditions.
+const albumsCountSQL = librarySQL + `SELECT COUNT(*) FROM rated
+JOIN albums ON albums.id = rated.id
+%s
+WHERE %s`
+
+// CountAlbums returns how many library albums pass the filter.
+func (s *Service) CountAlbums(ctx context.Context, userId string, p FilterParams) (int, error) {
+	join, joinArgs := albumSearchJoinSQL(userId, p.Search)
+	where, filterArgs := albumFilterSQL(userId, p)
+	args := append([]any{userId, userId}, joinArgs...)
+	args = append(args, filterArgs...)
+	var count int
+	err := s.db.Sql().QueryRowContext(ctx, fmt.Sprintf(albumsCountSQL, join, where), args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count albums: %w", err)
+	}
+	return count, nil
+}
+
+// albumSortKeySQL returns the value an album is sorted on, matching the
+// in-memory sorts: the first primary artist's sort name, the latest rating's
+// score, the date the album was first added and the last time it was played.
+// Relevance is the album's score against the search, from the search join.
+func albumSortKeySQL(userId string, by AlbumSort, search string) (string, []any) {
+	switch by {
+	case AlbumSortTitle:
+		return "albums.title", nil
+	case AlbumSortArtist:
+		return "(SELECT COALESCE(artist_sort_names.sort_name, artists.sort_name, artists.name) FROM album_artists JOIN artists ON artists.id = album_artists.artist_id LEFT JOIN artist_sort_names ON artist_sort_names.artist_id = artists.id AND artist_sort_names.user_id = ? WHERE album_artists.album_id = rated.id AND album_artists.role = 'primary' ORDER BY album_artists.position, album_artists.rowid LIMIT 1)", []any{userId}
+	case AlbumSortRating:
+		return ratingScoreSQL(review.RatingDimensionOverall)
+	case AlbumSortQuality, AlbumSortEnjoyment:
+		return ratingScoreSQL(review.ParseRatingDimension(string(by)))
+	case AlbumSortLastPlayed:
+		return "(SELECT MAX(track_plays.played_at) FROM track_plays WHERE track_plays.user_id = ? AND track_plays.album_id = rated.id)", []any{userId}
+	case AlbumSortRelevance:
+		if SearchMatch(search) == "" {
+			return "rated.added_at", nil
+		}
+		return "search_match.score", nil
+	default:
+		return "rated.added_at", nil
+	}
+}
+
+// albumSearchJoinSQL returns the join keeping the albums that match a search,
+// as search_match with each album's score, or "" when there's no search.
+// FTS5's bm25 is lower for better matches, so the score is negated to sort
+// best first descending, like the other keys.
+func albumSearchJoinSQL(userId, search string) (string, []any) {
+	match := SearchMatch(search)
+	if match == "" {
+		return "", nil
+	}
+	return "JOIN (SELECT library_search.album_id, -bm25(library_search, " + searchWeights + ") AS score FROM library_search WHERE library_search MATCH ? AND library_search.user_id = ?) AS search_match ON search_match.album_id = rated.id", []any{match, userId}
+}
+
+// ratingScoreSQL returns the latest rating's score on a dimension.
+func ratingScoreSQL(dimension review.RatingDimension) (string, []any) {
+	if dimension == "" || dimension == review.RatingDimensionOverall {
+		return "(SELECT album_rating_log.rating FROM album_rating_log WHERE album_rating_log.id = rated.rating_id)", nil
+	}
+	return "(SELECT album_rating_scores.score FROM album_rating_scores WHERE album_rating_scores.rating_log_id = rated.rating_id AND album_rating_scores.dimension = ?)", []any{string(dimension)}
+}
+
+// albumFilterSQL returns the condition matching the albums the params keep,
+// all but the search, which albumSearchJoinSQL joins.
+func albumFilterSQL(userId string, p FilterParams) (string, []any) {
+	var conditions []string
+	var args []any
+	add := func(condition string, conditionArgs ...any) {
+		conditions = append(conditions, condition)
+		args = append(args, conditionArgs...)
+	}
+
+	if p.MinRating != nil || p.MaxRating != nil {
+		score, scoreArgs := ratingScoreSQL(p.RatingDimension)
+		if p.MinRating != nil {
+			add(score+" >= ?", append(scoreArgs, *p.MinRating)...)
+		}
+		if p.MaxRating != nil {
+			add(score+" <= ?", append(scoreArgs, *p.MaxRating)...)
+		}
+	}
+	switch p.Rated {
+	case "only":
+		add("rated.rating_id IS NOT NULL")
+	case "unrated":
+		add("rated.rating_id IS NULL")
+	}
+	if len(p.Formats) > 0 {
+		formats := make([]any, len(p.Formats))
+		for i, format := range p.Formats {
+			formats[i] = string(format)
+		}
+		add("EXISTS (SELECT 1 FROM user_releases JOIN releases ON user_releases.release_id = releases.id WHERE user_releases.user_id = ? AND releases.album_id = rated.id AND releases.format IN ("+placeholders(len(formats))+"))", append([]any{userId}, formats...)...)
+	}
+	if len(p.ArtistIDs) > 0 {
+		add("EXISTS (SELECT 1 FROM album_artists WHERE album_artists.album_id = rated.id AND album_artists.artist_id IN ("+placeholders(len(p.ArtistIDs))+"))", stringArgs(p.ArtistIDs)...)
+	}
+	if len(p.TagIDs) > 0 {
+		condition, conditionArgs := tags.TagIDsSQL(userId, p.TagIDs, "rated.id")
+		add(condition, conditionArgs...)
+	}
+	if p.TagQuery != nil {
+		condition, conditionArgs := p.TagQuery.SQL(userId, "rated.id")
+		add(condition, conditionArgs...)
+	}
+	if len(p.IntroducedByIDs) > 0 {
+		add("EXISTS (SELECT 1 FROM album_people WHERE album_people.user_id = ? AND album_people.album_id = rated.id AND album_people.direction = 'introduced_by' AND album_people.person_id IN ("+placeholders(len(p.IntroducedByIDs))+"))", append([]any{userId}, stringArgs(p.IntroducedByIDs)...)...)
+	}
+	if p.albumId != "" {
+		add("rated.id = ?", p.albumId)
+	}
+	if p.Shelf != nil {
+		if !p.Shelf.IsSmart() {
+			add("EXISTS (SELECT 1 FROM shelf_albums JOIN shelves ON shelves.id = shelf_albums.shelf_id WHERE shelves.user_id = ? AND shelf_albums.shelf_id = ? AND shelf_albums.album_id = rated.id)", userId, p.Shelf.ID)
+		} else if shelfFilter, err := DecodeShelfFilter(p.Shelf.Filter); err != nil {
+			add("0")
+		} else {
+			shelfFilter.Shelf = nil
+			condition, conditionArgs := albumFilterSQL(userId, shelfFilter)
+			add("("+condition+")", conditionArgs...)
+		}
+	}
+
+	if len(conditions) == 0 {
+		return "1", nil
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// albumCursorSQL returns the condition for the albums after the cursor.
+// SQLite sorts NULL first, so albums without a sort value come before the
+// rest ascending and after them descending.
+func albumCursorSQL(cursor albumCursor, ascending bool) (string, []any) {
+	if ascending {
+		if cursor.Key == nil {
+			return "(sort_key IS NOT NULL OR id > ?)", []any{cursor.ID}
+		}
+		return "(sort_key > ? OR (sort_key = ? AND id > ?))", []any{cursor.Key, cursor.Key, cursor.ID}
+	}
+	if cursor.Key == nil {
+		return "(sort_key IS NULL AND id < ?)", []any{cursor.ID}
+	}
+	return "(sort_key < ? OR (sort_key = ? AND id < ?) OR sort_key IS NULL)", []any{cursor.Key, cursor.Key, cursor.ID}
+}
+
+func placeholders(count int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", count), ", ")
+}
+
+func stringArgs(values []string) []any {
+	args := make([]any, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	return args
+}
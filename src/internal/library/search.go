@@ -0,0 +1,46 @@
+package library
+
+import (
+	"strings"
+	"unicode"
+)
+
+// searchColumns are the search qualifiers and the library_search columns
+// they limit a word to.
+var searchColumns = map[string]string{
+	"artist": "artist",
+	"track":  "track",
+	"tag":    "tag",
+}
+
+// searchWeights weigh each library_search column in the bm25 ranking, in
+// column order: user_id, album_id, title, artist, track, tag, note.
+const searchWeights = "0.0, 0.0, 4.0, 3.0, 1.0, 2.0, 1.0"
+
+// SearchMatch turns what the user typed into the library search into a
+// full-text MATCH expression, or "" when there's nothing to search for.
+// Words match as prefixes of words in any field, or in one field when
+// qualified ("artist:bjork", "track:", "tag:"). Every word has to match.
+// Only letters and digits reach the expression, so it can't be malformed.
+func SearchMatch(text string) string {
+	var terms []string
+	for _, field := range strings.Fields(text) {
+		column := ""
+		if qualifier, rest, ok := strings.Cut(field, ":"); ok {
+			if c, ok := searchColumns[strings.ToLower(qualifier)]; ok {
+				column, field = c, rest
+			}
+		}
+		words := strings.FieldsFunc(strings.ToLower(field), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, word := range words {
+			term := word + "*"
+			if column != "" {
+				term = column + ":" + term
+			}
+			terms = append(terms, term)
+		}
+	}
+	return strings.Join(terms, " ")
+}
@@ -3,16 +3,23 @@ package library
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/alecdray/wax/src/internal/core/db"
 	"github.com/alecdray/wax/src/internal/core/db/models"
 	"github.com/alecdray/wax/src/internal/core/db/sqlc"
+	"github.com/alecdray/wax/src/internal/core/timex"
 	"github.com/alecdray/wax/src/internal/core/utils"
+	"github.com/alecdray/wax/src/internal/links"
 	"github.com/alecdray/wax/src/internal/listeninghistory"
+	"github.com/alecdray/wax/src/internal/people"
 	"github.com/alecdray/wax/src/internal/review"
+	"github.com/alecdray/wax/src/internal/shelves"
 	"github.com/alecdray/wax/src/internal/tags"
+	"slices"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -25,6 +32,12 @@ type AlbumSummaryDTO struct {
 	Artists   string
 	ImageURL  string
 	InLibrary bool
+	// Wishlisted is set when the album is on the user's wishlist and not yet
+	// fulfilled. Only filled for recently played albums.
+	Wishlisted bool
+	// Hint is a short line of context shown under the album, such as why it
+	// was suggested. Empty for most carousels.
+	Hint string
 }
 
 type ReleaseDTO struct {
@@ -75,6 +88,9 @@ type TrackDTO struct {
 	ID        string
 	SpotifyID string
 	Title     string
+	// Mark is the user's standout/skip marker, empty when unmarked. It is only
+	// loaded on the album detail page.
+	Mark models.TrackMark
 }
 
 func NewTrackDTOFromModel(model sqlc.Track) TrackDTO {
@@ -91,6 +107,10 @@ type ArtistDTO struct {
 	ID        string
 	SpotifyID string
 	Name      string
+	// SortName is what the artist sorts by: the user's own sort name for
+	// them, or else the name with a leading article moved to the end, as in
+	// "Beatles, The".
+	SortName string
 }
 
 func NewArtistDTOFromModel(model sqlc.Artist) ArtistDTO {
@@ -98,22 +118,53 @@ func NewArtistDTOFromModel(model sqlc.Artist) ArtistDTO {
 		ID:        model.ID,
 		SpotifyID: model.SpotifyID,
 		Name:      model.Name,
+		SortName:  model.Name,
+	}
+	if model.SortName.Valid {
+		dto.SortName = model.SortName.String
 	}
 
 	return dto
 }
 
+// sortKey is the artist's sort name, falling back to the name for artists
+// built without one.
+func (a ArtistDTO) sortKey() string {
+	if a.SortName != "" {
+		return a.SortName
+	}
+	return a.Name
+}
+
 type AlbumDTO struct {
-	ID           string
-	SpotifyID    string
-	Title        string
-	ImageURL     string
-	Artists      []ArtistDTO
-	Tracks       []TrackDTO
-	Releases     ReleaseDTOs
-	Rating       *review.AlbumRatingDTO
-	RatingLog    []*review.AlbumRatingDTO
-	Tags         []tags.TagDTO
+	ID        string
+	SpotifyID string
+	Title     string
+	ImageURL  string
+	// Artists are the album's primary credits, in credit order.
+	Artists []ArtistDTO
+	// Featured are the artists credited on the album's tracks but not on the
+	// album itself, such as guests and a compilation's performers.
+	Featured  []ArtistDTO
+	Tracks    []TrackDTO
+	Releases  ReleaseDTOs
+	Rating    *review.AlbumRatingDTO
+	RatingLog []*review.AlbumRatingDTO
+	Review    *review.AlbumReviewDTO
+	Tags      []tags.TagDTO
+	// ShelfIDs are the hand-built shelves the album is on.
+	ShelfIDs []string
+	// Shelves are all the shelves the album is on, smart ones included. Only
+	// the single-album lookup fills them in.
+	Shelves []shelves.ShelfDTO
+	// Links are the album's links to and from other albums. Only the
+	// single-album lookup fills them in.
+	Links []links.AlbumLinkDTO
+	// IntroducedByIDs are the people who introduced the user to the album.
+	IntroducedByIDs []string
+	// People are who introduced the album and who it was shared with. Only
+	// the single-album lookup fills them in.
+	People       []people.AlbumPersonDTO
 	LastPlayedAt *time.Time
 }
 
@@ -145,8 +196,17 @@ func (albums AlbumDTOs) Page(offset int) AlbumDTOs {
 	return albums[offset:end]
 }
 
-func (albums AlbumDTOs) SortByTitle(ascending bool) {
+func (albums AlbumDTOs) sortByID(ascending bool) {
 	sort.Slice(albums, func(i, j int) bool {
+		if ascending {
+			return albums[i].ID < albums[j].ID
+		}
+		return albums[i].ID > albums[j].ID
+	})
+}
+
+func (albums AlbumDTOs) SortByTitle(ascending bool) {
+	sort.SliceStable(albums, func(i, j int) bool {
 		if ascending {
 			return albums[i].Title < albums[j].Title
 		}
@@ -155,7 +215,7 @@ func (albums AlbumDTOs) SortByTitle(ascending bool) {
 }
 
 func (albums AlbumDTOs) SortByArtist(ascending bool) {
-	sort.Slice(albums, func(i, j int) bool {
+	sort.SliceStable(albums, func(i, j int) bool {
 		if len(albums[i].Artists) == 0 && len(albums[j].Artists) == 0 {
 			return false
 		}
@@ -166,21 +226,22 @@ func (albums AlbumDTOs) SortByArtist(ascending bool) {
 			return !ascending
 		}
 		if ascending {
-			return albums[i].Artists[0].Name < albums[j].Artists[0].Name
+			return albums[i].Artists[0].sortKey() < albums[j].Artists[0].sortKey()
 		}
-		return albums[i].Artists[0].Name > albums[j].Artists[0].Name
+		return albums[i].Artists[0].sortKey() > albums[j].Artists[0].sortKey()
 	})
 }
 
 func (albums AlbumDTOs) SortByRating(ascending bool) {
-	sort.Slice(albums, func(i, j int) bool {
-		var ratingI, ratingJ *float64
-		if albums[i].Rating != nil {
-			ratingI = albums[i].Rating.Rating
-		}
-		if albums[j].Rating != nil {
-			ratingJ = albums[j].Rating.Rating
-		}
+	albums.SortByRatingDimension(review.RatingDimensionOverall, ascending)
+}
+
+// SortByRatingDimension sorts by the current rating's score on the given
+// dimension. Albums without a score on that dimension sort last when descending.
+func (albums AlbumDTOs) SortByRatingDimension(dimension review.RatingDimension, ascending bool) {
+	sort.SliceStable(albums, func(i, j int) bool {
+		ratingI := albums[i].Rating.Score(dimension)
+		ratingJ := albums[j].Rating.Score(dimension)
 		if ratingI == nil && ratingJ == nil {
 			return false
 		}
@@ -198,7 +259,7 @@ func (albums AlbumDTOs) SortByRating(ascending bool) {
 }
 
 func (albums AlbumDTOs) SortByLastPlayed(ascending bool) {
-	sort.Slice(albums, func(i, j int) bool {
+	sort.SliceStable(albums, func(i, j int) bool {
 		if albums[i].LastPlayedAt == nil && albums[j].LastPlayedAt == nil {
 			return false
 		}
@@ -216,7 +277,7 @@ func (albums AlbumDTOs) SortByLastPlayed(ascending bool) {
 }
 
 func (albums AlbumDTOs) SortByDate(ascending bool) {
-	sort.Slice(albums, func(i, j int) bool {
+	sort.SliceStable(albums, func(i, j int) bool {
 		dateI := albums[i].Releases.OldestAddedAtDate()
 		dateJ := albums[j].Releases.OldestAddedAtDate()
 		if dateI == nil && dateJ == nil {
@@ -235,134 +296,115 @@ func (albums AlbumDTOs) SortByDate(ascending bool) {
 	})
 }
 
+// FilterParams narrow the library. They're saved as JSON as the filter of a
+// smart shelf, so field names are part of that format.
 type FilterParams struct {
-	MinRating *float64
-	MaxRating *float64
-	Rated     string // "only" | "unrated" | ""
-	Formats   []models.ReleaseFormat
-	ArtistIDs []string
+	MinRating *float64 `json:"minRating,omitempty"`
+	MaxRating *float64 `json:"maxRating,omitempty"`
+	// RatingDimension selects the axis MinRating and MaxRating apply to.
+	// The zero value filters on the overall rating.
+	RatingDimension review.RatingDimension `json:"ratingDimension,omitempty"`
+	Rated           string                 `json:"rated,omitempty"` // "only" | "unrated" | ""
+	Formats         []models.ReleaseFormat `json:"formats,omitempty"`
+	ArtistIDs       []string               `json:"artistIds,omitempty"`
+	// TagIDs keeps albums with any of the tags or their subtags.
+	TagIDs []string `json:"tagIds,omitempty"`
+	// TagQuery keeps albums whose tags match a boolean tag query. It's saved
+	// as text naming tags, which the tags service rewrites on renames.
+	TagQuery *tags.Query `json:"tagQuery,omitempty"`
+	// IntroducedByIDs keeps albums introduced by any of the people.
+	IntroducedByIDs []string `json:"introducedByIds,omitempty"`
+	// Search keeps albums matching a full-text search of the library, as
+	// SearchMatch reads it. It isn't saved with a smart shelf's filter.
+	Search string `json:"-"`
+	// Shelf limits the library to one shelf. It isn't saved with a smart
+	// shelf's filter, so smart shelves can't nest.
+	Shelf *shelves.ShelfDTO `json:"-"`
+	// albumId limits the library to one album, to check it against a filter.
+	albumId string
 }
 
-func (albums AlbumDTOs) Filter(p FilterParams) AlbumDTOs {
-	if p.MinRating == nil && p.MaxRating == nil && p.Rated == "" && len(p.Formats) == 0 && len(p.ArtistIDs) == 0 {
-		return albums
-	}
-	result := make(AlbumDTOs, 0, len(albums))
-	for _, album := range albums {
-		if p.MinRating != nil {
-			if album.Rating == nil || album.Rating.Rating == nil || *album.Rating.Rating < *p.MinRating {
-				continue
-			}
-		}
-		if p.MaxRating != nil {
-			if album.Rating == nil || album.Rating.Rating == nil || *album.Rating.Rating > *p.MaxRating {
-				continue
-			}
-		}
-		switch p.Rated {
-		case "only":
-			if album.Rating == nil || album.Rating.Rating == nil {
-				continue
-			}
-		case "unrated":
-			if album.Rating != nil && album.Rating.Rating != nil {
-				continue
-			}
-		}
-		if len(p.Formats) > 0 {
-			hasFormat := false
-			for _, format := range p.Formats {
-				if release := album.Releases.FindFormat(format); release != nil && release.AddedAt != nil {
-					hasFormat = true
-					break
-				}
-			}
-			if !hasFormat {
-				continue
-			}
-		}
-		if len(p.ArtistIDs) > 0 {
-			hasArtist := false
-		outer:
-			for _, artistID := range p.ArtistIDs {
-				for _, artist := range album.Artists {
-					if artist.ID == artistID {
-						hasArtist = true
-						break outer
-					}
-				}
-			}
-			if !hasArtist {
-				continue
-			}
-		}
-		result = append(result, album)
-	}
-	return result
+// IsEmpty reports whether the params filter nothing out.
+func (p FilterParams) IsEmpty() bool {
+	return p.MinRating == nil && p.MaxRating == nil && p.Rated == "" && len(p.Formats) == 0 && len(p.ArtistIDs) == 0 && len(p.TagIDs) == 0 && p.TagQuery == nil && len(p.IntroducedByIDs) == 0 && p.Search == "" && p.Shelf == nil
 }
 
-type Library struct {
-	OwnerUserID string
-	Albums      AlbumDTOs
-	Artists     []ArtistDTO
-	Tracks      []TrackDTO
-}
-
-func NewLibrary(ownerUserID string, albums []AlbumDTO) *Library {
-	lib := &Library{
-		OwnerUserID: ownerUserID,
-		Albums:      albums,
+// EncodeShelfFilter returns the params as a smart shelf's saved filter.
+func EncodeShelfFilter(p FilterParams) (string, error) {
+	p.Shelf = nil
+	p.Search = ""
+	if p.IsEmpty() {
+		return "", errors.New("a smart shelf needs at least one filter")
 	}
-
-	lib.Artists = lib.artists()
-	lib.Tracks = lib.tracks()
-
-	return lib
-}
-
-func (l *Library) artists() []ArtistDTO {
-	artistsSet := make(map[string]ArtistDTO)
-	for _, album := range l.Albums {
-		for _, artist := range album.Artists {
-			artistsSet[artist.ID] = artist
-		}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode filter: %w", err)
 	}
+	return string(data), nil
+}
 
-	artists := make([]ArtistDTO, 0, len(artistsSet))
-	for _, artist := range artistsSet {
-		artists = append(artists, artist)
+// DecodeShelfFilter reads back a smart shelf's saved filter.
+func DecodeShelfFilter(filter string) (FilterParams, error) {
+	var p FilterParams
+	err := json.Unmarshal([]byte(filter), &p)
+	if err != nil {
+		return FilterParams{}, fmt.Errorf("failed to decode filter: %w", err)
 	}
+	return p, nil
+}
 
-	return artists
+// OnShelf reports whether the album is on the shelf, hand-built or smart,
+// going by the Shelves the single-album lookup fills in.
+func (album AlbumDTO) OnShelf(shelf shelves.ShelfDTO) bool {
+	return slices.ContainsFunc(album.Shelves, func(albumShelf shelves.ShelfDTO) bool {
+		return albumShelf.ID == shelf.ID
+	})
 }
 
-func (l *Library) tracks() []TrackDTO {
-	tracksSet := make(map[string]TrackDTO)
-	for _, album := range l.Albums {
-		for _, track := range album.Tracks {
-			tracksSet[track.ID] = track
+// sortLibraryTags puts tags in group order and then by name, ungrouped tags
+// last.
+func sortLibraryTags(libraryTags []tags.TagDTO) {
+	sort.Slice(libraryTags, func(i, j int) bool {
+		groupI, groupJ := libraryTags[i].Group, libraryTags[j].Group
+		if (groupI == nil) != (groupJ == nil) {
+			return groupJ == nil
 		}
-	}
-
-	tracks := make([]TrackDTO, 0, len(tracksSet))
-	for _, track := range tracksSet {
-		tracks = append(tracks, track)
-	}
+		if groupI != nil && groupI.ID != groupJ.ID {
+			if groupI.Position != groupJ.Position {
+				return groupI.Position < groupJ.Position
+			}
+			return groupI.Name < groupJ.Name
+		}
+		return libraryTags[i].Name < libraryTags[j].Name
+	})
+}
 
-	return tracks
+// LibraryStats are the counts shown at the top of the dashboard.
+type LibraryStats struct {
+	Albums  int
+	Artists int
+	Tracks  int
 }
 
 type Service struct {
 	db                      *db.DB
 	listeningHistoryService *listeninghistory.Service
 	tagsService             *tags.Service
+	reviewService           *review.Service
+	shelvesService          *shelves.Service
+	linksService            *links.Service
+	peopleService           *people.Service
 }
 
-func NewService(db *db.DB, listeningHistoryService *listeninghistory.Service, tagsService *tags.Service) *Service {
+func NewService(db *db.DB, listeningHistoryService *listeninghistory.Service, tagsService *tags.Service, reviewService *review.Service, shelvesService *shelves.Service, linksService *links.Service, peopleService *people.Service) *Service {
 	return &Service{
 		db:                      db,
 		listeningHistoryService: listeningHistoryService,
 		tagsService:             tagsService,
+		reviewService:           reviewService,
+		shelvesService:          shelvesService,
+		linksService:            linksService,
+		peopleService:           peopleService,
 	}
 }
 
@@ -381,50 +423,90 @@ func (s *Service) GetReleasesInLibrary(ctx context.Context, userId string) ([]Re
 	return releaseDTOs, nil
 }
 
+// GetAlbumsInLibrary returns every album in the library, without tracks.
 func (s *Service) GetAlbumsInLibrary(ctx context.Context, userId string) ([]AlbumDTO, error) {
 	releases, err := s.GetReleasesInLibrary(ctx, userId)
 	if err != nil {
 		err = fmt.Errorf("failed to get releases: %w", err)
+		return nil, err
 	}
 
 	releasesByAlbumId := make(map[string][]ReleaseDTO, len(releases))
 	albumIds := make([]string, 0, len(releases))
 	for _, release := range releases {
-		albumIds = append(albumIds, release.AlbumID)
+		if _, ok := releasesByAlbumId[release.AlbumID]; !ok {
+			albumIds = append(albumIds, release.AlbumID)
+		}
 		releasesByAlbumId[release.AlbumID] = append(releasesByAlbumId[release.AlbumID], release)
+	}
+
+	return s.loadAlbums(ctx, userId, albumIds, releasesByAlbumId)
+}
+
+// getAlbumsByIds returns the library albums with the given IDs, in order and
+// without tracks.
+func (s *Service) getAlbumsByIds(ctx context.Context, userId string, albumIds []string) ([]AlbumDTO, error) {
+	if len(albumIds) == 0 {
+		return nil, nil
+	}
+
+	releases, err := s.db.Queries().GetUserReleasesByAlbumIds(ctx, sqlc.GetUserReleasesByAlbumIdsParams{
+		UserID:   userId,
+		AlbumIds: albumIds,
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to get releases: %w", err)
+		return nil, err
+	}
 
+	releasesByAlbumId := make(map[string][]ReleaseDTO, len(albumIds))
+	for _, release := range releases {
+		releasesByAlbumId[release.Release.AlbumID] = append(releasesByAlbumId[release.Release.AlbumID], NewReleaseDTOFromModel(release.Release, &release.UserRelease))
 	}
 
+	return s.loadAlbums(ctx, userId, albumIds, releasesByAlbumId)
+}
+
+// loadAlbums fills in the albums with the given IDs, in order, with
+// everything the library list shows and filters on. Tracks are left out;
+// only the album page needs them.
+func (s *Service) loadAlbums(ctx context.Context, userId string, albumIds []string, releasesByAlbumId map[string][]ReleaseDTO) ([]AlbumDTO, error) {
 	albums, err := s.db.Queries().GetAlbumsByIDs(ctx, albumIds)
 	if err != nil {
 		err = fmt.Errorf("failed to get albums: %w", err)
 		return nil, err
 	}
 
+	albumsById := make(map[string]sqlc.Album, len(albums))
+	for _, album := range albums {
+		albumsById[album.ID] = album
+	}
+
 	artists, err := s.db.Queries().GetAlbumArtistsByAlbumIds(ctx, albumIds)
 	if err != nil {
 		err = fmt.Errorf("failed to get album artists: %w", err)
 		return nil, err
 	}
 
-	artistsByAlbumId := make(map[string][]ArtistDTO, len(albumIds))
-	for _, artist := range artists {
-		artistsByAlbumId[artist.AlbumID] = append(artistsByAlbumId[artist.AlbumID], NewArtistDTOFromModel(artist.Artist))
-	}
-
-	tracks, err := s.db.Queries().GetAlbumTracksByAlbumIds(ctx, albumIds)
+	sortNames, err := s.getArtistSortNames(ctx, userId)
 	if err != nil {
-		err = fmt.Errorf("failed to get album tracks: %w", err)
 		return nil, err
 	}
 
-	tracksByAlbumId := make(map[string][]TrackDTO, len(albumIds))
-	for _, track := range tracks {
-		tracksByAlbumId[track.AlbumID] = append(tracksByAlbumId[track.AlbumID], NewTrackDTOFromModel(track.Track))
+	artistsByAlbumId := make(map[string][]ArtistDTO, len(albumIds))
+	featuredByAlbumId := make(map[string][]ArtistDTO, len(albumIds))
+	for _, artist := range artists {
+		dto := sortNames.apply(NewArtistDTOFromModel(artist.Artist))
+		if artist.Role == models.ArtistRoleFeatured {
+			featuredByAlbumId[artist.AlbumID] = append(featuredByAlbumId[artist.AlbumID], dto)
+		} else {
+			artistsByAlbumId[artist.AlbumID] = append(artistsByAlbumId[artist.AlbumID], dto)
+		}
 	}
 
-	ratings, err := s.db.Queries().GetLatestUserAlbumRatings(ctx, sqlc.GetLatestUserAlbumRatingsParams{
+	ratings, err := s.db.Queries().GetLatestUserAlbumRatingsByAlbumIds(ctx, sqlc.GetLatestUserAlbumRatingsByAlbumIdsParams{
 		UserID:   userId,
+		AlbumIds: albumIds,
 		UserID_2: userId,
 	})
 	if err != nil {
@@ -432,9 +514,20 @@ func (s *Service) GetAlbumsInLibrary(ctx context.Context, userId string) ([]Albu
 		return nil, err
 	}
 
-	ratingsByAlbumId := make(map[string]review.AlbumRatingDTO, len(ratings))
-	for _, rating := range ratings {
-		ratingsByAlbumId[rating.AlbumID] = *review.NewAlbumRatingDTOFromModel(rating)
+	ratingDTOs := make([]*review.AlbumRatingDTO, len(ratings))
+	for i, rating := range ratings {
+		ratingDTOs[i] = review.NewAlbumRatingDTOFromModel(rating)
+	}
+
+	err = s.reviewService.LoadRatingDetails(ctx, ratingDTOs)
+	if err != nil {
+		err = fmt.Errorf("failed to get rating details: %w", err)
+		return nil, err
+	}
+
+	ratingsByAlbumId := make(map[string]review.AlbumRatingDTO, len(ratingDTOs))
+	for _, rating := range ratingDTOs {
+		ratingsByAlbumId[rating.AlbumID] = *rating
 	}
 
 	lastPlayedAtByAlbumId, err := s.listeningHistoryService.GetLastPlayedAtByAlbumIds(ctx, userId, albumIds)
@@ -449,33 +542,127 @@ func (s *Service) GetAlbumsInLibrary(ctx context.Context, userId string) ([]Albu
 		return nil, err
 	}
 
-	var albumDTOs []AlbumDTO
-	for _, album := range albums {
+	shelfIdsByAlbumId, err := s.shelvesService.GetShelfIdsByAlbumIds(ctx, userId, albumIds)
+	if err != nil {
+		err = fmt.Errorf("failed to get album shelves: %w", err)
+		return nil, err
+	}
+
+	introducerIdsByAlbumId, err := s.peopleService.GetIntroducerIdsByAlbumIds(ctx, userId, albumIds)
+	if err != nil {
+		err = fmt.Errorf("failed to get album introducers: %w", err)
+		return nil, err
+	}
+
+	albumDTOs := make([]AlbumDTO, 0, len(albumIds))
+	for _, albumId := range albumIds {
+		album, ok := albumsById[albumId]
+		if !ok {
+			continue
+		}
 		dto := NewAlbumDTOFromModel(
 			album,
 			artistsByAlbumId[album.ID],
-			tracksByAlbumId[album.ID],
+			nil,
 			releasesByAlbumId[album.ID],
 			utils.NewPointer(ratingsByAlbumId[album.ID]),
 		)
+		dto.Featured = featuredByAlbumId[album.ID]
 		if t, ok := lastPlayedAtByAlbumId[album.ID]; ok {
 			dto.LastPlayedAt = &t
 		}
 		dto.Tags = tagsByAlbumId[album.ID]
+		dto.ShelfIDs = shelfIdsByAlbumId[album.ID]
+		dto.IntroducedByIDs = introducerIdsByAlbumId[album.ID]
 		albumDTOs = append(albumDTOs, dto)
 	}
 
 	return albumDTOs, nil
 }
 
-func (s *Service) GetLibrary(ctx context.Context, userId string) (*Library, error) {
-	albums, err := s.GetAlbumsInLibrary(ctx, userId)
+func (s *Service) GetLibraryStats(ctx context.Context, userId string) (LibraryStats, error) {
+	stats, err := s.db.Queries().GetLibraryStats(ctx, userId)
+	if err != nil {
+		err = fmt.Errorf("failed to get library stats: %w", err)
+		return LibraryStats{}, err
+	}
+
+	return LibraryStats{
+		Albums:  int(stats.AlbumCount),
+		Artists: int(stats.ArtistCount),
+		Tracks:  int(stats.TrackCount),
+	}, nil
+}
+
+// GetLibraryArtists returns the artists credited on the library's albums,
+// by sort name.
+func (s *Service) GetLibraryArtists(ctx context.Context, userId string) ([]ArtistDTO, error) {
+	artists, err := s.db.Queries().GetLibraryArtists(ctx, userId)
+	if err != nil {
+		err = fmt.Errorf("failed to get library artists: %w", err)
+		return nil, err
+	}
+
+	sortNames, err := s.getArtistSortNames(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	artistDTOs := make([]ArtistDTO, len(artists))
+	for i, artist := range artists {
+		artistDTOs[i] = sortNames.apply(NewArtistDTOFromModel(artist))
+	}
+	slices.SortStableFunc(artistDTOs, func(a, b ArtistDTO) int {
+		return strings.Compare(strings.ToLower(a.sortKey()), strings.ToLower(b.sortKey()))
+	})
+
+	return artistDTOs, nil
+}
+
+// GetLibraryTags returns the tags on the library's albums, ordered like
+// Library.Tags.
+func (s *Service) GetLibraryTags(ctx context.Context, userId string) ([]tags.TagDTO, error) {
+	tagIds, err := s.db.Queries().GetLibraryTagIds(ctx, userId)
+	if err != nil {
+		err = fmt.Errorf("failed to get library tags: %w", err)
+		return nil, err
+	}
+
+	userTags, err := s.tagsService.GetUserTags(ctx, userId)
 	if err != nil {
-		err = fmt.Errorf("failed to get user albums: %w", err)
+		err = fmt.Errorf("failed to get user tags: %w", err)
 		return nil, err
 	}
 
-	return NewLibrary(userId, albums), nil
+	libraryTags := make([]tags.TagDTO, 0, len(tagIds))
+	for _, tag := range userTags {
+		if slices.Contains(tagIds, tag.ID) {
+			libraryTags = append(libraryTags, tag)
+		}
+	}
+	sortLibraryTags(libraryTags)
+
+	return libraryTags, nil
+}
+
+// GetLibraryAlbumIdsBySpotifyIds returns the ids of the albums in the
+// library out of those with the given Spotify ids, keyed by Spotify id.
+func (s *Service) GetLibraryAlbumIdsBySpotifyIds(ctx context.Context, userId string, spotifyIds []string) (map[string]string, error) {
+	albums, err := s.db.Queries().GetLibraryAlbumsBySpotifyIds(ctx, sqlc.GetLibraryAlbumsBySpotifyIdsParams{
+		UserID:     userId,
+		SpotifyIds: spotifyIds,
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to get library albums: %w", err)
+		return nil, err
+	}
+
+	albumIds := make(map[string]string, len(albums))
+	for _, album := range albums {
+		albumIds[album.SpotifyID] = album.ID
+	}
+
+	return albumIds, nil
 }
 
 func (s *Service) AddAlbumsToLibrary(ctx context.Context, userId string, albums []AlbumDTO) error {
@@ -492,7 +679,9 @@ func (s *Service) AddAlbumsToLibrary(ctx context.Context, userId string, albums
 				err = fmt.Errorf("failed to get/create album: %w", err)
 				return err
 			}
+			featured := album.Featured
 			album = NewAlbumDTOFromModel(albumModel, album.Artists, album.Tracks, album.Releases, album.Rating)
+			album.Featured = featured
 
 			for i, track := range album.Tracks {
 				// insert tracks
@@ -519,29 +708,37 @@ func (s *Service) AddAlbumsToLibrary(ctx context.Context, userId string, albums
 				album.Tracks[i] = NewTrackDTOFromModel(trackModel)
 			}
 
-			for i, artist := range album.Artists {
+			// Featured credits follow the primary ones.
+			credits := slices.Concat(album.Artists, album.Featured)
+			for i, artist := range credits {
 				// insert artsits
-				artistModel, err := tx.Queries().GetOrCreateArtist(ctx, sqlc.GetOrCreateArtistParams{
-					ID:        artist.ID,
-					SpotifyID: artist.SpotifyID,
-					Name:      artist.Name,
-				})
+				artistModel, err := getOrCreateArtist(ctx, tx.Queries(), artist)
 				if err != nil {
-					err = fmt.Errorf("failed to get/create artist: %w", err)
 					return err
 				}
 
+				role := models.ArtistRolePrimary
+				if i >= len(album.Artists) {
+					role = models.ArtistRoleFeatured
+				}
+
 				// insert album_artists
 				_, err = tx.Queries().GetOrCreateAlbumArtist(ctx, sqlc.GetOrCreateAlbumArtistParams{
 					AlbumID:  albumModel.ID,
 					ArtistID: artistModel.ID,
+					Position: int64(i),
+					Role:     role,
 				})
 				if err != nil {
 					err = fmt.Errorf("failed to get/create album artist: %w", err)
 					return err
 				}
 
-				album.Artists[i] = NewArtistDTOFromModel(artistModel)
+				if role == models.ArtistRolePrimary {
+					album.Artists[i] = NewArtistDTOFromModel(artistModel)
+				} else {
+					album.Featured[i-len(album.Artists)] = NewArtistDTOFromModel(artistModel)
+				}
 			}
 
 			for i, release := range album.Releases {
@@ -609,9 +806,19 @@ func (s *Service) GetAlbumInLibrary(ctx context.Context, userId string, albumId
 		return nil, err
 	}
 
-	artistDtos := make([]ArtistDTO, len(artists))
-	for i, artist := range artists {
-		artistDtos[i] = NewArtistDTOFromModel(artist.Artist)
+	sortNames, err := s.getArtistSortNames(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var artistDtos, featuredDtos []ArtistDTO
+	for _, artist := range artists {
+		dto := sortNames.apply(NewArtistDTOFromModel(artist.Artist))
+		if artist.Role == models.ArtistRoleFeatured {
+			featuredDtos = append(featuredDtos, dto)
+		} else {
+			artistDtos = append(artistDtos, dto)
+		}
 	}
 
 	tracks, err := s.db.Queries().GetAlbumTracksByAlbumId(ctx, album.ID)
@@ -620,36 +827,28 @@ func (s *Service) GetAlbumInLibrary(ctx context.Context, userId string, albumId
 		return nil, err
 	}
 
+	trackMarks, err := s.reviewService.GetTrackMarks(ctx, userId, album.ID)
+	if err != nil {
+		err = fmt.Errorf("failed to get track marks: %w", err)
+		return nil, err
+	}
+
 	trackDtos := make([]TrackDTO, len(tracks))
 	for i, track := range tracks {
 		trackDtos[i] = NewTrackDTOFromModel(track.Track)
+		trackDtos[i].Mark = trackMarks[track.Track.ID]
 	}
 
-	latestRating, err := s.db.Queries().GetLatestUserAlbumRating(ctx, sqlc.GetLatestUserAlbumRatingParams{
-		UserID:  userId,
-		AlbumID: album.ID,
-	})
-	var ratingDTO *review.AlbumRatingDTO
-	if errors.Is(err, sql.ErrNoRows) {
-		// no rating
-	} else if err != nil {
-		err = fmt.Errorf("failed to get rating: %w", err)
-		return nil, err
-	} else {
-		ratingDTO = review.NewAlbumRatingDTOFromModel(latestRating)
-	}
-
-	ratingLogRows, err := s.db.Queries().GetUserAlbumRatingLog(ctx, sqlc.GetUserAlbumRatingLogParams{
-		UserID:  userId,
-		AlbumID: album.ID,
-	})
+	ratingLog, err := s.reviewService.GetRatingLog(ctx, userId, album.ID)
 	if err != nil {
 		err = fmt.Errorf("failed to get rating log: %w", err)
 		return nil, err
 	}
-	ratingLog := make([]*review.AlbumRatingDTO, len(ratingLogRows))
-	for i, row := range ratingLogRows {
-		ratingLog[i] = review.NewAlbumRatingDTOFromModel(row)
+
+	// The log is ordered newest first, so its head is the current rating.
+	var ratingDTO *review.AlbumRatingDTO
+	if len(ratingLog) > 0 {
+		ratingDTO = ratingLog[0]
 	}
 
 	albumDto := NewAlbumDTOFromModel(
@@ -659,8 +858,16 @@ func (s *Service) GetAlbumInLibrary(ctx context.Context, userId string, albumId
 		releasesDtos,
 		ratingDTO,
 	)
+	albumDto.Featured = featuredDtos
 	albumDto.RatingLog = ratingLog
 
+	albumReview, err := s.reviewService.GetAlbumReview(ctx, userId, album.ID)
+	if err != nil {
+		err = fmt.Errorf("failed to get album review: %w", err)
+		return nil, err
+	}
+	albumDto.Review = albumReview
+
 	albumTags, err := s.tagsService.GetAlbumTags(ctx, userId, albumId)
 	if err != nil {
 		err = fmt.Errorf("failed to get album tags: %w", err)
@@ -677,6 +884,52 @@ func (s *Service) GetAlbumInLibrary(ctx context.Context, userId string, albumId
 		albumDto.LastPlayedAt = &t
 	}
 
+	shelfIdsByAlbumId, err := s.shelvesService.GetShelfIdsByAlbumIds(ctx, userId, []string{albumId})
+	if err != nil {
+		err = fmt.Errorf("failed to get album shelves: %w", err)
+		return nil, err
+	}
+	albumDto.ShelfIDs = shelfIdsByAlbumId[albumId]
+
+	albumPeople, err := s.peopleService.GetAlbumPeople(ctx, userId, albumId)
+	if err != nil {
+		err = fmt.Errorf("failed to get album people: %w", err)
+		return nil, err
+	}
+	albumDto.People = albumPeople
+	for _, albumPerson := range albumPeople {
+		if albumPerson.Direction == models.AlbumPersonDirectionIntroducedBy {
+			albumDto.IntroducedByIDs = append(albumDto.IntroducedByIDs, albumPerson.PersonID)
+		}
+	}
+
+	userShelves, err := s.shelvesService.GetUserShelves(ctx, userId)
+	if err != nil {
+		err = fmt.Errorf("failed to get shelves: %w", err)
+		return nil, err
+	}
+	for _, shelf := range userShelves {
+		onShelf := slices.Contains(albumDto.ShelfIDs, shelf.ID)
+		if shelf.IsSmart() {
+			count, err := s.CountAlbums(ctx, userId, FilterParams{Shelf: &shelf, albumId: album.ID})
+			if err != nil {
+				err = fmt.Errorf("failed to check smart shelf: %w", err)
+				return nil, err
+			}
+			onShelf = count > 0
+		}
+		if onShelf {
+			albumDto.Shelves = append(albumDto.Shelves, shelf)
+		}
+	}
+
+	albumLinks, err := s.linksService.GetAlbumLinks(ctx, userId, albumId)
+	if err != nil {
+		err = fmt.Errorf("failed to get album links: %w", err)
+		return nil, err
+	}
+	albumDto.Links = albumLinks
+
 	return &albumDto, nil
 }
 
@@ -689,12 +942,13 @@ func (s *Service) GetRecentlyPlayedAlbums(ctx context.Context, userID string) ([
 	dtos := make([]AlbumSummaryDTO, 0, len(rows))
 	for _, row := range rows {
 		dtos = append(dtos, AlbumSummaryDTO{
-			ID:        row.ID,
-			SpotifyID: row.SpotifyID,
-			Title:     row.Title,
-			Artists:   fmt.Sprintf("%s", row.ArtistNames),
-			ImageURL:  row.ImageUrl.String,
-			InLibrary: row.InLibrary != 0,
+			ID:         row.ID,
+			SpotifyID:  row.SpotifyID,
+			Title:      row.Title,
+			Artists:    fmt.Sprintf("%s", row.ArtistNames),
+			ImageURL:   row.ImageUrl.String,
+			InLibrary:  row.InLibrary != 0,
+			Wishlisted: row.Wishlisted != 0,
 		})
 	}
 	return dtos, nil
@@ -723,3 +977,49 @@ func (s *Service) GetUnratedAlbums(ctx context.Context, userID string) ([]AlbumS
 	}
 	return dtos, nil
 }
+
+// GetRevisitAlbums returns the albums whose ratings the revisit task flagged
+// as due another listen, busiest first.
+func (s *Service) GetRevisitAlbums(ctx context.Context, userID string) ([]AlbumSummaryDTO, error) {
+	rows, err := s.db.Queries().GetRevisitAlbums(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revisit albums: %w", err)
+	}
+
+	now := time.Now()
+	dtos := make([]AlbumSummaryDTO, 0, len(rows))
+	for _, row := range rows {
+		dtos = append(dtos, AlbumSummaryDTO{
+			ID:        row.ID,
+			SpotifyID: row.SpotifyID,
+			Title:     row.Title,
+			Artists:   fmt.Sprintf("%s", row.ArtistNames),
+			ImageURL:  row.ImageUrl.String,
+			InLibrary: true,
+			Hint:      revisitHint(row.Reason, row.RatedAt, int(row.PlaysSince), now),
+		})
+	}
+	return dtos, nil
+}
+
+func revisitHint(reason models.RevisitReason, ratedAt time.Time, playsSince int, now time.Time) string {
+	if reason == models.RevisitReasonSingleListen {
+		return "Rated on first impressions"
+	}
+
+	age := now.Sub(ratedAt)
+	var since string
+	switch {
+	case age >= 365*timex.Day:
+		since = fmt.Sprintf("%dy", int(age/(365*timex.Day)))
+	case age >= 30*timex.Day:
+		since = fmt.Sprintf("%dmo", int(age/(30*timex.Day)))
+	default:
+		since = fmt.Sprintf("%dd", int(age/timex.Day))
+	}
+	plays := "plays"
+	if playsSince == 1 {
+		plays = "play"
+	}
+	return fmt.Sprintf("Rated %s ago · %d %s since", since, playsSince, plays)
+}
@@ -1,11 +1,24 @@
 package library
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/alecdray/wax/src/internal/core/db"
+	"github.com/alecdray/wax/src/internal/core/db/dbtest"
 	"github.com/alecdray/wax/src/internal/core/db/models"
+	"github.com/alecdray/wax/src/internal/links"
+	"github.com/alecdray/wax/src/internal/listeninghistory"
+	"github.com/alecdray/wax/src/internal/people"
 	"github.com/alecdray/wax/src/internal/review"
+	"github.com/alecdray/wax/src/internal/shelves"
+	"github.com/alecdray/wax/src/internal/tags"
+
+	spotifyapi "github.com/zmb3/spotify/v2"
 )
 
 // makeAlbumWithRelease creates an AlbumDTO with a single release format.
@@ -146,6 +159,39 @@ func TestSortByRating_NilRatingGoesLast_Descending(t *testing.T) {
 	}
 }
 
+// --- SortByRatingDimension ---
+
+func makeAlbumWithScores(id string, rating float64, scores review.RatingScores) AlbumDTO {
+	return AlbumDTO{
+		ID:     id,
+		Rating: &review.AlbumRatingDTO{Rating: ptr(rating), Scores: scores},
+	}
+}
+
+func TestSortByRatingDimension_Enjoyment_Descending(t *testing.T) {
+	albums := AlbumDTOs{
+		makeAlbumWithScores("1", 9.0, review.RatingScores{review.RatingDimensionEnjoyment: 5.0}),
+		makeAlbumWithScores("2", 6.0, review.RatingScores{review.RatingDimensionEnjoyment: 9.0}),
+		makeAlbumWithScores("3", 7.0, review.RatingScores{review.RatingDimensionQuality: 8.0}),
+	}
+	albums.SortByRatingDimension(review.RatingDimensionEnjoyment, false)
+	if albums[0].ID != "2" || albums[1].ID != "1" || albums[2].ID != "3" {
+		t.Fatalf("unexpected order: %v %v %v", albums[0].ID, albums[1].ID, albums[2].ID)
+	}
+}
+
+func TestSortByRatingDimension_Overall_MatchesSortByRating(t *testing.T) {
+	albums := AlbumDTOs{
+		makeAlbum("1", "Low", "", ptr(6.0), nil),
+		makeAlbum("2", "High", "", ptr(9.5), nil),
+		makeAlbum("3", "Unrated", "", nil, nil),
+	}
+	albums.SortByRatingDimension(review.RatingDimensionOverall, false)
+	if albums[0].ID != "2" || albums[1].ID != "1" || albums[2].ID != "3" {
+		t.Fatalf("unexpected order: %v %v %v", albums[0].ID, albums[1].ID, albums[2].ID)
+	}
+}
+
 // --- SortByLastPlayed ---
 
 func TestSortByLastPlayed_Descending(t *testing.T) {
@@ -248,137 +294,884 @@ func TestFindFormat_NotFound(t *testing.T) {
 	}
 }
 
-// --- AlbumDTOs.Filter ---
+// --- Shelf filters ---
 
-func TestFilter_NoParams_ReturnsAll(t *testing.T) {
-	albums := AlbumDTOs{
-		makeAlbum("1", "A", "", nil, nil),
-		makeAlbum("2", "B", "", ptr(7.0), nil),
+func TestEncodeShelfFilter_RoundTrips(t *testing.T) {
+	fp := FilterParams{
+		MinRating:       ptr(6.5),
+		RatingDimension: review.RatingDimensionEnjoyment,
+		Formats:         []models.ReleaseFormat{models.ReleaseFormatVinyl},
+		ArtistIDs:       []string{"artist-a"},
+		// The shelf being viewed isn't part of a saved filter.
+		Shelf: &shelves.ShelfDTO{ID: "other"},
 	}
-	result := albums.Filter(FilterParams{})
-	if len(result) != 2 {
-		t.Fatalf("expected 2 albums, got %d", len(result))
+	filter, err := EncodeShelfFilter(fp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := DecodeShelfFilter(filter)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.MinRating == nil || *got.MinRating != 6.5 || got.MaxRating != nil {
+		t.Errorf("rating bounds did not round-trip: %+v", got)
+	}
+	if got.RatingDimension != review.RatingDimensionEnjoyment || len(got.Formats) != 1 || len(got.ArtistIDs) != 1 {
+		t.Errorf("filters did not round-trip: %+v", got)
+	}
+	if got.Shelf != nil {
+		t.Error("expected the shelf to be left out of the saved filter")
 	}
 }
 
-func TestFilter_MinRating(t *testing.T) {
-	albums := AlbumDTOs{
-		makeAlbum("1", "Low", "", ptr(5.0), nil),
-		makeAlbum("2", "High", "", ptr(8.0), nil),
-		makeAlbum("3", "Unrated", "", nil, nil),
+func TestEncodeShelfFilter_SavesTagQueryAsText(t *testing.T) {
+	query, err := tags.ParseQuery("mood:late-night and not sound:ambient")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	result := albums.Filter(FilterParams{MinRating: ptr(7.0)})
-	if len(result) != 1 || result[0].ID != "2" {
-		t.Fatalf("expected only high-rated album, got %d albums", len(result))
+	filter, err := EncodeShelfFilter(FilterParams{TagQuery: query, Search: "moon"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"tagQuery":"mood:late-night AND NOT sound:ambient"}`
+	if filter != want {
+		t.Errorf("expected %s, got %s", want, filter)
+	}
+	got, err := DecodeShelfFilter(filter)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.TagQuery.String() != query.String() || got.Search != "" {
+		t.Errorf("tag query did not round-trip: %+v", got)
 	}
 }
 
-func TestFilter_MaxRating(t *testing.T) {
+func TestEncodeShelfFilter_RequiresAFilter(t *testing.T) {
+	if _, err := EncodeShelfFilter(FilterParams{Shelf: &shelves.ShelfDTO{ID: "other"}}); err == nil {
+		t.Error("expected an error for an empty filter")
+	}
+}
+
+// --- AlbumDTOs.Sort ---
+
+func TestSort_TiesFallBackToID(t *testing.T) {
 	albums := AlbumDTOs{
-		makeAlbum("1", "Low", "", ptr(4.0), nil),
-		makeAlbum("2", "High", "", ptr(9.0), nil),
-		makeAlbum("3", "Unrated", "", nil, nil),
+		makeAlbum("2", "Same", "", nil, nil),
+		makeAlbum("3", "Same", "", nil, nil),
+		makeAlbum("1", "Same", "", nil, nil),
+	}
+	albums.Sort(AlbumSortTitle, true)
+	if albums[0].ID != "1" || albums[1].ID != "2" || albums[2].ID != "3" {
+		t.Errorf("expected 1, 2, 3, got %s, %s, %s", albums[0].ID, albums[1].ID, albums[2].ID)
 	}
-	result := albums.Filter(FilterParams{MaxRating: ptr(6.0)})
-	if len(result) != 1 || result[0].ID != "1" {
-		t.Fatalf("expected only low-rated album, got %d albums", len(result))
+	albums.Sort(AlbumSortTitle, false)
+	if albums[0].ID != "3" || albums[1].ID != "2" || albums[2].ID != "1" {
+		t.Errorf("expected 3, 2, 1, got %s, %s, %s", albums[0].ID, albums[1].ID, albums[2].ID)
 	}
 }
 
-func TestFilter_MinAndMaxRating(t *testing.T) {
-	albums := AlbumDTOs{
-		makeAlbum("1", "Low", "", ptr(3.0), nil),
-		makeAlbum("2", "Mid", "", ptr(7.0), nil),
-		makeAlbum("3", "High", "", ptr(10.0), nil),
+func TestParseAlbumSort_DefaultsToDate(t *testing.T) {
+	for in, want := range map[string]AlbumSort{"": AlbumSortDate, "bogus": AlbumSortDate, "artist": AlbumSortArtist, "enjoyment": AlbumSortEnjoyment} {
+		if got := ParseAlbumSort(in); got != want {
+			t.Errorf("ParseAlbumSort(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// --- Service.QueryAlbums ---
+
+// newTestService builds the service over a migrated test database.
+func newTestService(t *testing.T) (*Service, *db.DB) {
+	database := dbtest.New(t)
+	service := NewService(
+		database,
+		listeninghistory.NewService(database, nil),
+		tags.NewService(database),
+		review.NewService(database),
+		shelves.NewService(database),
+		links.NewService(database),
+		people.NewService(database),
+	)
+	return service, database
+}
+
+// seedLibrary fills a library with ties on every sort key, albums missing
+// each sort value, and an album in another user's library.
+func seedLibrary(t *testing.T, database *db.DB) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(days int) time.Time { return base.AddDate(0, 0, days) }
+	exec := func(query string, args ...any) {
+		t.Helper()
+		if _, err := database.Sql().Exec(query, args...); err != nil {
+			t.Fatalf("failed to seed %q: %v", query, err)
+		}
+	}
+
+	exec("INSERT INTO users (id, spotify_id) VALUES ('u1', 'u1'), ('u2', 'u2')")
+	for i, artist := range []string{"Beta", "Alpha", "Beta", "Gamma"} {
+		exec("INSERT INTO artists (id, spotify_id, name) VALUES (?, ?, ?)", fmt.Sprintf("ar%d", i), fmt.Sprintf("ar%d", i), artist)
+	}
+
+	albums := []struct {
+		title   string
+		artists []string
+		formats []string
+		addedAt time.Time
+	}{
+		{"Moon", []string{"ar0"}, []string{"digital"}, at(1)},
+		{"Abbey", []string{"ar1", "ar3"}, []string{"vinyl"}, at(2)},
+		{"Moon", []string{"ar3", "ar1"}, []string{"digital", "vinyl"}, at(2)},
+		{"Ziggy", []string{"ar2"}, []string{"cd"}, at(3)},
+		{"Blue", nil, []string{"digital"}, at(3)},
+		{"Abbey", []string{"ar0"}, []string{"cassette", "digital"}, at(4)},
+		{"Kid A", []string{"ar1"}, []string{"digital"}, at(5)},
+		{"Low", []string{"ar2"}, []string{"vinyl"}, at(0)},
+		{"Heroes", []string{"ar3"}, []string{"digital"}, at(6)},
+	}
+	for i, album := range albums {
+		albumId := fmt.Sprintf("al%d", i)
+		exec("INSERT INTO albums (id, spotify_id, title) VALUES (?, ?, ?)", albumId, albumId, album.title)
+		for _, artistId := range album.artists {
+			exec("INSERT INTO album_artists (album_id, artist_id) VALUES (?, ?)", albumId, artistId)
+		}
+		for j, format := range album.formats {
+			releaseId := fmt.Sprintf("%s-%s", albumId, format)
+			exec("INSERT INTO releases (id, album_id, format) VALUES (?, ?, ?)", releaseId, albumId, format)
+			exec("INSERT INTO user_releases (id, user_id, release_id, added_at) VALUES (?, 'u1', ?, ?)", releaseId, releaseId, album.addedAt.AddDate(0, 0, j*3))
+		}
+		exec("INSERT INTO tracks (id, spotify_id, title) VALUES (?, ?, 'Track')", albumId+"-t", albumId+"-t")
+		exec("INSERT INTO album_tracks (album_id, track_id) VALUES (?, ?)", albumId, albumId+"-t")
+	}
+	exec("INSERT INTO albums (id, spotify_id, title) VALUES ('other', 'other', 'Other')")
+	exec("INSERT INTO releases (id, album_id, format) VALUES ('other-digital', 'other', 'digital')")
+	exec("INSERT INTO user_releases (id, user_id, release_id, added_at) VALUES ('other-digital', 'u2', 'other-digital', ?)", at(1))
+
+	ratings := []struct {
+		albumId   string
+		rating    float64
+		scores    map[string]float64
+		createdAt time.Time
+	}{
+		{"al0", 3, nil, at(1)},
+		{"al0", 8, map[string]float64{"enjoyment": 9}, at(2)},
+		{"al1", 8, map[string]float64{"quality": 7, "enjoyment": 9}, at(1)},
+		{"al2", 6.5, map[string]float64{"quality": 7}, at(1)},
+		{"al3", 9, map[string]float64{"quality": 5, "enjoyment": 4}, at(3)},
+		{"al3", 4, nil, at(1)},
+		{"al6", 6.5, nil, at(2)},
+	}
+	for i, rating := range ratings {
+		ratingId := fmt.Sprintf("r%d", i)
+		exec("INSERT INTO album_rating_log (id, user_id, album_id, rating, created_at) VALUES (?, 'u1', ?, ?, ?)", ratingId, rating.albumId, rating.rating, rating.createdAt)
+		for dimension, score := range rating.scores {
+			exec("INSERT INTO album_rating_scores (rating_log_id, dimension, score) VALUES (?, ?, ?)", ratingId, dimension, score)
+		}
+	}
+	exec("INSERT INTO album_rating_log (id, user_id, album_id, rating, created_at) VALUES ('r-u2', 'u2', 'al4', 10, ?)", at(1))
+
+	for i, play := range []struct {
+		albumId  string
+		playedAt time.Time
+	}{{"al0", at(3)}, {"al0", at(7)}, {"al1", at(7)}, {"al5", at(2)}, {"al8", at(1)}} {
+		exec("INSERT INTO track_plays (id, user_id, track_id, album_id, played_at) VALUES (?, 'u1', ?, ?, ?)", fmt.Sprintf("p%d", i), play.albumId+"-t", play.albumId, play.playedAt)
 	}
-	result := albums.Filter(FilterParams{MinRating: ptr(6.0), MaxRating: ptr(8.0)})
-	if len(result) != 1 || result[0].ID != "2" {
-		t.Fatalf("expected only mid-rated album, got %d albums", len(result))
+	exec("INSERT INTO track_plays (id, user_id, track_id, album_id, played_at) VALUES ('p-u2', 'u2', 'al4-t', 'al4', ?)", at(9))
+
+	exec("INSERT INTO tag_groups (id, user_id, name) VALUES ('g-mood', 'u1', 'mood')")
+	exec("INSERT INTO tags (id, user_id, name, group_id) VALUES ('t-calm', 'u1', 'calm', 'g-mood'), ('t-loud', 'u1', 'loud', 'g-mood')")
+	exec("INSERT INTO tags (id, user_id, name, parent_id) VALUES ('t-rock', 'u1', 'rock', NULL), ('t-punk', 'u1', 'punk', 't-rock')")
+	for i, albumTag := range [][2]string{{"al0", "t-calm"}, {"al1", "t-punk"}, {"al2", "t-rock"}, {"al2", "t-loud"}, {"al5", "t-calm"}, {"al5", "t-punk"}, {"al8", "t-loud"}} {
+		exec("INSERT INTO album_tags (id, user_id, album_id, tag_id) VALUES (?, 'u1', ?, ?)", fmt.Sprintf("at%d", i), albumTag[0], albumTag[1])
+	}
+
+	smart, err := EncodeShelfFilter(FilterParams{MinRating: ptr(6.5)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
+	exec("INSERT INTO shelves (id, user_id, name) VALUES ('s-hand', 'u1', 'Favorites')")
+	exec("INSERT INTO shelves (id, user_id, name, filter) VALUES ('s-smart', 'u1', 'Keepers', ?)", smart)
+	exec("INSERT INTO shelf_albums (shelf_id, album_id) VALUES ('s-hand', 'al1'), ('s-hand', 'al4'), ('s-hand', 'al7')")
+
+	exec("INSERT INTO people (id, user_id, name) VALUES ('pe-sam', 'u1', 'Sam'), ('pe-ana', 'u1', 'Ana')")
+	exec("INSERT INTO album_people (id, user_id, album_id, person_id, direction) VALUES ('ap0', 'u1', 'al2', 'pe-sam', 'introduced_by'), ('ap1', 'u1', 'al6', 'pe-ana', 'introduced_by'), ('ap2', 'u1', 'al3', 'pe-sam', 'shared_with')")
 }
 
-func TestFilter_RatedOnly(t *testing.T) {
-	albums := AlbumDTOs{
-		makeAlbum("1", "Rated", "", ptr(7.0), nil),
-		makeAlbum("2", "Unrated", "", nil, nil),
+func albumIDs(albums []AlbumDTO) []string {
+	ids := make([]string, len(albums))
+	for i, album := range albums {
+		ids[i] = album.ID
+	}
+	return ids
+}
+
+func TestQueryAlbums_FiltersAndMatchesSortingInMemory(t *testing.T) {
+	service, database := newTestService(t)
+	seedLibrary(t, database)
+	ctx := context.Background()
+
+	tagQuery, err := tags.ParseQuery("mood:* AND NOT loud")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	smartShelf, err := EncodeShelfFilter(FilterParams{MinRating: ptr(6.5)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	filters := map[string]struct {
+		fp   FilterParams
+		want []string
+	}{
+		"none":              {FilterParams{}, []string{"al0", "al1", "al2", "al3", "al4", "al5", "al6", "al7", "al8"}},
+		"min rating":        {FilterParams{MinRating: ptr(6.5)}, []string{"al0", "al1", "al2", "al3", "al6"}},
+		"max rating":        {FilterParams{MaxRating: ptr(8.0)}, []string{"al0", "al1", "al2", "al6"}},
+		"quality range":     {FilterParams{MinRating: ptr(5.0), MaxRating: ptr(7.0), RatingDimension: review.RatingDimensionQuality}, []string{"al1", "al2", "al3"}},
+		"enjoyment":         {FilterParams{MinRating: ptr(9.0), RatingDimension: review.RatingDimensionEnjoyment}, []string{"al0", "al1"}},
+		"rated":             {FilterParams{Rated: "only"}, []string{"al0", "al1", "al2", "al3", "al6"}},
+		"unrated":           {FilterParams{Rated: "unrated"}, []string{"al4", "al5", "al7", "al8"}},
+		"formats":           {FilterParams{Formats: []models.ReleaseFormat{models.ReleaseFormatVinyl, models.ReleaseFormatCassette}}, []string{"al1", "al2", "al5", "al7"}},
+		"format and rating": {FilterParams{Formats: []models.ReleaseFormat{models.ReleaseFormatVinyl}, MinRating: ptr(7.0)}, []string{"al1"}},
+		"artist":            {FilterParams{ArtistIDs: []string{"ar3"}}, []string{"al1", "al2", "al8"}},
+		"artists":           {FilterParams{ArtistIDs: []string{"ar1", "ar2"}}, []string{"al1", "al2", "al3", "al6", "al7"}},
+		"tags":              {FilterParams{TagIDs: []string{"t-calm", "t-loud", "missing"}}, []string{"al0", "al2", "al5", "al8"}},
+		"subtags":           {FilterParams{TagIDs: []string{"t-rock"}}, []string{"al1", "al2", "al5"}},
+		"tag query":         {FilterParams{TagQuery: tagQuery}, []string{"al0", "al5"}},
+		"introduced by":     {FilterParams{IntroducedByIDs: []string{"pe-sam", "pe-ana"}}, []string{"al2", "al6"}},
+		"hand shelf":        {FilterParams{Shelf: &shelves.ShelfDTO{ID: "s-hand"}}, []string{"al1", "al4", "al7"}},
+		"smart shelf":       {FilterParams{Shelf: &shelves.ShelfDTO{ID: "s-smart", Filter: smartShelf}, Formats: []models.ReleaseFormat{models.ReleaseFormatDigital}}, []string{"al0", "al2", "al6"}},
+		"broken shelf":      {FilterParams{Shelf: &shelves.ShelfDTO{ID: "s-broken", Filter: "{"}}, nil},
+	}
+	sorts := []AlbumSort{AlbumSortDate, AlbumSortTitle, AlbumSortArtist, AlbumSortRating, AlbumSortQuality, AlbumSortEnjoyment, AlbumSortLastPlayed}
+
+	library, err := service.GetAlbumsInLibrary(ctx, "u1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	result := albums.Filter(FilterParams{Rated: "only"})
-	if len(result) != 1 || result[0].ID != "1" {
-		t.Fatalf("expected only rated album, got %d albums", len(result))
+	if len(library) != 9 {
+		t.Fatalf("expected 9 albums in the library, got %d", len(library))
+	}
+
+	for name, filter := range filters {
+		count, err := service.CountAlbums(ctx, "u1", filter.fp)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if count != len(filter.want) {
+			t.Errorf("%s: expected a count of %d, got %d", name, len(filter.want), count)
+		}
+
+		for _, by := range sorts {
+			for _, ascending := range []bool{true, false} {
+				t.Run(fmt.Sprintf("%s/%s/ascending=%t", name, by, ascending), func(t *testing.T) {
+					var want AlbumDTOs
+					for _, album := range library {
+						if slices.Contains(filter.want, album.ID) {
+							want = append(want, album)
+						}
+					}
+					want.Sort(by, ascending)
+
+					var got []AlbumDTO
+					query := AlbumsQuery{Filter: filter.fp, Sort: by, Ascending: ascending, Limit: 2}
+					for page := 0; ; page++ {
+						if page > len(library) {
+							t.Fatal("paging did not end")
+						}
+						result, err := service.QueryAlbums(ctx, "u1", query)
+						if err != nil {
+							t.Fatalf("unexpected error: %v", err)
+						}
+						got = append(got, result.Albums...)
+						if result.Next == "" {
+							break
+						}
+						query.After = result.Next
+					}
+
+					if !slices.Equal(albumIDs(got), albumIDs(want)) {
+						t.Errorf("expected %v, got %v", albumIDs(want), albumIDs(got))
+					}
+				})
+			}
+		}
 	}
 }
 
-func TestFilter_UnratedOnly(t *testing.T) {
-	albums := AlbumDTOs{
-		makeAlbum("1", "Rated", "", ptr(7.0), nil),
-		makeAlbum("2", "Unrated", "", nil, nil),
+func TestQueryAllAlbums_LoadsEveryPage(t *testing.T) {
+	service, database := newTestService(t)
+	seedLibrary(t, database)
+
+	albums, err := service.QueryAllAlbums(context.Background(), "u1", AlbumsQuery{Sort: AlbumSortTitle, Ascending: true, Limit: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	result := albums.Filter(FilterParams{Rated: "unrated"})
-	if len(result) != 1 || result[0].ID != "2" {
-		t.Fatalf("expected only unrated album, got %d albums", len(result))
+	if got, want := albumIDs(albums), []string{"al1", "al5", "al4", "al8", "al6", "al7", "al0", "al2", "al3"}; !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
 	}
 }
 
-func TestFilter_Format(t *testing.T) {
-	vinyl := makeAlbumWithRelease("1", "Vinyl Album", models.ReleaseFormatVinyl)
-	digital := makeAlbumWithRelease("2", "Digital Album", models.ReleaseFormatDigital)
-	albums := AlbumDTOs{vinyl, digital}
+func TestGetAlbumInLibrary_FindsItsShelves(t *testing.T) {
+	service, database := newTestService(t)
+	seedLibrary(t, database)
+	ctx := context.Background()
 
-	result := albums.Filter(FilterParams{Formats: []models.ReleaseFormat{models.ReleaseFormatVinyl}})
-	if len(result) != 1 || result[0].ID != "1" {
-		t.Fatalf("expected only vinyl album, got %d albums", len(result))
+	for albumId, want := range map[string][]string{"al1": {"s-hand", "s-smart"}, "al2": {"s-smart"}, "al4": {"s-hand"}, "al5": nil} {
+		album, err := service.GetAlbumInLibrary(ctx, "u1", albumId)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var got []string
+		for _, shelf := range album.Shelves {
+			got = append(got, shelf.ID)
+			if !album.OnShelf(shelf) {
+				t.Errorf("expected %s on %s", albumId, shelf.ID)
+			}
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, want) {
+			t.Errorf("%s: expected shelves %v, got %v", albumId, want, got)
+		}
 	}
 }
 
-func TestFilter_Format_ExcludesNoAddedAt(t *testing.T) {
-	// A release without AddedAt means it's not in the library for that format
-	albums := AlbumDTOs{
-		{
-			ID:       "1",
-			Releases: ReleaseDTOs{{Format: models.ReleaseFormatVinyl, AddedAt: nil}},
-		},
+func TestQueryAlbums_LoadsAlbumsWithoutTracks(t *testing.T) {
+	service, database := newTestService(t)
+	seedLibrary(t, database)
+
+	page, err := service.QueryAlbums(context.Background(), "u1", AlbumsQuery{Sort: AlbumSortTitle, Ascending: true, Limit: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page.Albums) != 3 || page.Next == "" {
+		t.Fatalf("expected a full page with more to come, got %d albums", len(page.Albums))
 	}
-	result := albums.Filter(FilterParams{Formats: []models.ReleaseFormat{models.ReleaseFormatVinyl}})
-	if len(result) != 0 {
-		t.Fatalf("expected 0 albums (no AddedAt), got %d", len(result))
+	first := page.Albums[0]
+	if first.ID != "al1" || len(first.Artists) != 2 || first.Artists[0].Name != "Alpha" {
+		t.Errorf("expected al1 by Alpha first, got %+v", first)
+	}
+	if first.Rating == nil || first.Rating.Score(review.RatingDimensionQuality) == nil || len(first.Tags) != 1 || len(first.ShelfIDs) != 1 {
+		t.Errorf("expected al1 with its rating, tag and shelf, got %+v", first)
+	}
+	if first.Tracks != nil {
+		t.Errorf("expected no tracks, got %d", len(first.Tracks))
 	}
 }
 
-func TestFilter_SingleArtist(t *testing.T) {
-	albums := AlbumDTOs{
-		{ID: "1", Artists: []ArtistDTO{{ID: "artist-a", Name: "Artist A"}}},
-		{ID: "2", Artists: []ArtistDTO{{ID: "artist-b", Name: "Artist B"}}},
+func TestQueryAlbums_SmartShelfFollowsRatings(t *testing.T) {
+	service, database := newTestService(t)
+	seedLibrary(t, database)
+	ctx := context.Background()
+	reviewService := review.NewService(database)
+
+	shelf, err := shelves.NewService(database).GetShelf(ctx, "u1", "s-smart")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	result := albums.Filter(FilterParams{ArtistIDs: []string{"artist-a"}})
-	if len(result) != 1 || result[0].ID != "1" {
-		t.Fatalf("expected album by artist-a only, got %d albums", len(result))
+	shelfAlbums := func() []string {
+		t.Helper()
+		page, err := service.QueryAlbums(ctx, "u1", AlbumsQuery{Filter: FilterParams{Shelf: &shelf}, Sort: AlbumSortRating})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return albumIDs(page.Albums)
+	}
+
+	// Rated 6.5 and up on the latest rating, best first, ties by ID.
+	if got, want := shelfAlbums(), []string{"al3", "al1", "al0", "al6", "al2"}; !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	for _, rating := range []struct {
+		albumId string
+		rating  float64
+	}{{"al3", 5}, {"al5", 7}} {
+		if _, err := reviewService.AddRating(ctx, "u1", rating.albumId, review.RatingInput{Rating: rating.rating}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if got, want := shelfAlbums(), []string{"al1", "al0", "al5", "al6", "al2"}; !slices.Equal(got, want) {
+		t.Errorf("expected the rerated albums to leave and join, got %v, want %v", got, want)
 	}
 }
 
-func TestFilter_MultipleArtists(t *testing.T) {
-	albums := AlbumDTOs{
-		{ID: "1", Artists: []ArtistDTO{{ID: "artist-a", Name: "Artist A"}}},
-		{ID: "2", Artists: []ArtistDTO{{ID: "artist-b", Name: "Artist B"}}},
-		{ID: "3", Artists: []ArtistDTO{{ID: "artist-c", Name: "Artist C"}}},
+func TestQueryAlbums_RejectsABadCursor(t *testing.T) {
+	service, _ := newTestService(t)
+	_, err := service.QueryAlbums(context.Background(), "u1", AlbumsQuery{After: "not a cursor"})
+	if !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestGetLibraryStats(t *testing.T) {
+	service, database := newTestService(t)
+	seedLibrary(t, database)
+
+	stats, err := service.GetLibraryStats(context.Background(), "u1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	result := albums.Filter(FilterParams{ArtistIDs: []string{"artist-a", "artist-b"}})
-	if len(result) != 2 {
-		t.Fatalf("expected 2 albums, got %d", len(result))
+	if stats != (LibraryStats{Albums: 9, Artists: 4, Tracks: 9}) {
+		t.Errorf("unexpected stats: %+v", stats)
 	}
 }
 
-func TestFilter_CombinedFormatAndRating(t *testing.T) {
-	now := time.Now()
-	albums := AlbumDTOs{
-		{ID: "1", Releases: ReleaseDTOs{{Format: models.ReleaseFormatVinyl, AddedAt: &now}}, Rating: &review.AlbumRatingDTO{Rating: ptr(8.0)}},
-		{ID: "2", Releases: ReleaseDTOs{{Format: models.ReleaseFormatVinyl, AddedAt: &now}}, Rating: &review.AlbumRatingDTO{Rating: ptr(5.0)}},
-		{ID: "3", Releases: ReleaseDTOs{{Format: models.ReleaseFormatDigital, AddedAt: &now}}, Rating: &review.AlbumRatingDTO{Rating: ptr(9.0)}},
+func TestGetLibraryAlbumIdsBySpotifyIds(t *testing.T) {
+	service, database := newTestService(t)
+	seedLibrary(t, database)
+	if _, err := database.Sql().Exec("INSERT INTO albums (id, spotify_id, title) VALUES ('al-out', 'sp-out', 'Elsewhere')"); err != nil {
+		t.Fatalf("failed to seed: %v", err)
 	}
-	result := albums.Filter(FilterParams{
-		Formats:   []models.ReleaseFormat{models.ReleaseFormatVinyl},
-		MinRating: ptr(7.0),
+
+	albumIds, err := service.GetLibraryAlbumIdsBySpotifyIds(context.Background(), "u1", []string{"al0", "al3", "sp-out", "sp-unknown"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(albumIds) != 2 || albumIds["al0"] != "al0" || albumIds["al3"] != "al3" {
+		t.Errorf("expected only the library albums, got %v", albumIds)
+	}
+}
+
+// --- SearchMatch ---
+
+func TestSearchMatch(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"", ""},
+		{"  ", ""},
+		{"moon", "moon*"},
+		{"Kid A", "kid* a*"},
+		{"artist:Björk homo", "artist:björk* homo*"},
+		{"TRACK:river tag:late-night", "track:river* tag:late* tag:night*"},
+		{"title:moon", "title* moon*"},
+		{`"OR" NOT*`, "or* not*"},
+		{"tag:", ""},
+		{"-- !!", ""},
+	}
+	for _, c := range cases {
+		if got := SearchMatch(c.in); got != c.want {
+			t.Errorf("SearchMatch(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestQueryAlbums_Search(t *testing.T) {
+	service, database := newTestService(t)
+	seedLibrary(t, database)
+	ctx := context.Background()
+	exec := func(query string, args ...any) {
+		t.Helper()
+		if _, err := database.Sql().Exec(query, args...); err != nil {
+			t.Fatalf("failed to run %q: %v", query, err)
+		}
+	}
+	search := func(text string) []string {
+		t.Helper()
+		page, err := service.QueryAlbums(ctx, "u1", AlbumsQuery{Filter: FilterParams{Search: text}, Sort: AlbumSortRelevance})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return albumIDs(page.Albums)
+	}
+	sorted := func(ids []string) []string {
+		ids = slices.Clone(ids)
+		slices.Sort(ids)
+		return ids
+	}
+
+	// The index follows writes to the tables it's built from.
+	exec("UPDATE artists SET name = 'Björk' WHERE id = 'ar0'")
+	exec("INSERT INTO tracks (id, spotify_id, title) VALUES ('river', 'river', 'Moon River')")
+	exec("INSERT INTO album_tracks (album_id, track_id) VALUES ('al4', 'river')")
+	exec("INSERT INTO album_rating_log (id, user_id, album_id, rating, note, created_at) VALUES ('r-note', 'u1', 'al7', 7, 'Sounds like winter', ?)", time.Now())
+	exec("DELETE FROM album_tags WHERE album_id = 'al8' AND tag_id = 't-loud'")
+	exec("UPDATE tag_groups SET name = 'vibe' WHERE id = 'g-mood'")
+
+	cases := []struct {
+		text string
+		want []string
+	}{
+		{"bjork", []string{"al0", "al5"}},
+		{"BJÖ", []string{"al0", "al5"}},
+		{"artist:gam", []string{"al1", "al2", "al8"}},
+		{"artist:moon", nil},
+		{"track:river", []string{"al4"}},
+		{"winter", []string{"al7"}},
+		{"tag:vibe", []string{"al0", "al2", "al5"}},
+		{"tag:mood", nil},
+		{"tag:loud", []string{"al2"}},
+		{"moon gam", []string{"al2"}},
+		{"other", nil},
+	}
+	for _, c := range cases {
+		if got := sorted(search(c.text)); !slices.Equal(got, c.want) {
+			t.Errorf("search %q: expected %v, got %v", c.text, c.want, got)
+		}
+	}
+
+	// Title matches outrank a track title match.
+	got := search("moon")
+	if len(got) != 3 || got[2] != "al4" {
+		t.Errorf("expected al4 ranked last, got %v", got)
+	}
+	if count, err := service.CountAlbums(ctx, "u1", FilterParams{Search: "moon"}); err != nil || count != 3 {
+		t.Errorf("expected a count of 3, got %d, %v", count, err)
+	}
+
+	// Searching pages like any other sort.
+	page, err := service.QueryAlbums(ctx, "u1", AlbumsQuery{Filter: FilterParams{Search: "moon"}, Sort: AlbumSortRelevance, Limit: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	next, err := service.QueryAlbums(ctx, "u1", AlbumsQuery{Filter: FilterParams{Search: "moon"}, Sort: AlbumSortRelevance, Limit: 2, After: page.Next})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if paged := append(albumIDs(page.Albums), albumIDs(next.Albums)...); !slices.Equal(paged, got) || next.Next != "" {
+		t.Errorf("expected pages to give %v, got %v", got, paged)
+	}
+}
+
+func TestRevisitHint(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		reason     models.RevisitReason
+		ratedAt    time.Time
+		playsSince int
+		want       string
+	}{
+		{models.RevisitReasonStale, now.AddDate(-2, 0, -3), 14, "Rated 2y ago · 14 plays since"},
+		{models.RevisitReasonStale, now.AddDate(0, -5, 0), 3, "Rated 5mo ago · 3 plays since"},
+		{models.RevisitReasonStale, now.AddDate(0, 0, -9), 1, "Rated 9d ago · 1 play since"},
+		{models.RevisitReasonSingleListen, now.AddDate(0, 0, -20), 0, "Rated on first impressions"},
+	}
+	for _, c := range cases {
+		if got := revisitHint(c.reason, c.ratedAt, c.playsSince, now); got != c.want {
+			t.Errorf("revisitHint(%q, %v, %d) = %q, want %q", c.reason, c.ratedAt, c.playsSince, got, c.want)
+		}
+	}
+}
+
+// --- Artists ---
+
+func TestGetArtistOverview(t *testing.T) {
+	service, database := newTestService(t)
+	seedLibrary(t, database)
+	ctx := context.Background()
+	for _, query := range []string{
+		"INSERT INTO albums (id, spotify_id, title) VALUES ('al-heard', 'sp-heard', 'Amnesiac')",
+		"INSERT INTO album_artists (album_id, artist_id) VALUES ('al-heard', 'ar1')",
+		"INSERT INTO track_plays (id, user_id, track_id, album_id, played_at) VALUES ('p-heard', 'u1', 'al-heard-t', 'al-heard', '2026-01-02')",
+	} {
+		if _, err := database.Sql().Exec(query); err != nil {
+			t.Fatalf("failed to seed %q: %v", query, err)
+		}
+	}
+
+	overview, err := service.GetArtistOverview(ctx, "u1", "ar1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ids := albumIDs(overview.Albums)
+	if len(ids) != 3 || ids[0] != "al1" || !slices.Contains(ids, "al2") || !slices.Contains(ids, "al6") {
+		t.Errorf("expected the artist's library albums highest rated first, got %v", ids)
+	}
+	if overview.AverageRating == nil || *overview.AverageRating != 7 {
+		t.Errorf("expected an average rating of 7, got %v", overview.AverageRating)
+	}
+	if overview.Plays != 2 {
+		t.Errorf("expected plays of library and listened-to albums, got %d", overview.Plays)
+	}
+
+	discography := overview.Discography([]spotifyapi.SimpleAlbum{
+		{ID: "al1", Name: "Abbey", AlbumType: "album", ReleaseDate: "1969-09-26"},
+		{ID: "sp-kid-a", Name: "KID A", AlbumType: "album", ReleaseDate: "2000"},
+		{ID: "sp-heard", Name: "Amnesiac", AlbumType: "album"},
+		{ID: "sp-heard-jp", Name: "Amnesiac", AlbumType: "album"},
+		{ID: "sp-thief", Name: "Hail to the Thief", AlbumType: "album"},
+		{ID: "sp-single", Name: "Abbey", AlbumType: "single"},
 	})
-	if len(result) != 1 || result[0].ID != "1" {
-		t.Fatalf("expected only album 1, got %d albums", len(result))
+	want := []struct {
+		spotifyId string
+		albumId   string
+		status    CoverageStatus
+	}{
+		{"al1", "al1", CoverageRated},
+		{"sp-kid-a", "al6", CoverageRated},
+		{"sp-heard", "", CoverageHeard},
+		{"sp-thief", "", CoverageNotHeard},
+		{"sp-single", "al1", CoverageRated},
+	}
+	if len(discography.Albums) != len(want) {
+		t.Fatalf("expected %d albums, got %+v", len(want), discography.Albums)
+	}
+	for i, w := range want {
+		got := discography.Albums[i]
+		if got.SpotifyID != w.spotifyId || got.AlbumID != w.albumId || got.Status != w.status {
+			t.Errorf("album %d: expected %+v, got %+v", i, w, got)
+		}
+	}
+	if discography.Albums[0].Year != "1969" || discography.Albums[1].Year != "2000" || discography.Albums[2].Year != "" {
+		t.Errorf("unexpected years: %+v", discography.Albums)
+	}
+	if discography.Albums[2].Plays != 1 {
+		t.Errorf("expected the heard album's plays, got %d", discography.Albums[2].Plays)
+	}
+	if discography.Count(CoverageRated) != 3 || discography.Count(CoverageOwned) != 0 {
+		t.Errorf("unexpected counts: %+v", discography.Albums)
+	}
+
+	if _, err := service.GetArtistOverview(ctx, "u1", "unknown"); !errors.Is(err, ErrArtistNotFound) {
+		t.Errorf("expected ErrArtistNotFound, got %v", err)
+	}
+}
+
+// seedCredits adds credits beyond seedLibrary's: a featured artist, an
+// artist whose name starts with an article, and a user's own sort name.
+func seedCredits(t *testing.T, database *db.DB) {
+	for _, query := range []string{
+		"INSERT INTO artists (id, spotify_id, name) VALUES ('ar-apples', 'ar-apples', 'The Apples')",
+		"INSERT INTO album_artists (album_id, artist_id, position, role) VALUES ('al4', 'ar-apples', 0, 'primary')",
+		"INSERT INTO album_artists (album_id, artist_id, position, role) VALUES ('al7', 'ar0', 1, 'featured')",
+		"INSERT INTO artist_sort_names (user_id, artist_id, sort_name) VALUES ('u1', 'ar1', 'Zed')",
+	} {
+		if _, err := database.Sql().Exec(query); err != nil {
+			t.Fatalf("failed to seed %q: %v", query, err)
+		}
+	}
+}
+
+func TestQueryAlbums_SortsAndFiltersByCredits(t *testing.T) {
+	service, database := newTestService(t)
+	seedLibrary(t, database)
+	seedCredits(t, database)
+	ctx := context.Background()
+
+	library, err := service.GetAlbumsInLibrary(ctx, "u1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, ascending := range []bool{true, false} {
+		want := slices.Clone(AlbumDTOs(library))
+		want.Sort(AlbumSortArtist, ascending)
+		page, err := service.QueryAlbums(ctx, "u1", AlbumsQuery{Sort: AlbumSortArtist, Ascending: ascending})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !slices.Equal(albumIDs(page.Albums), albumIDs(want)) {
+			t.Errorf("ascending=%t: expected %v, got %v", ascending, albumIDs(want), albumIDs(page.Albums))
+		}
+		if ascending && page.Albums[0].ID != "al4" {
+			t.Errorf("expected The Apples to sort as Apples first, got %v", albumIDs(page.Albums))
+		}
+	}
+
+	filter := FilterParams{ArtistIDs: []string{"ar0"}}
+	page, err := service.QueryAlbums(ctx, "u1", AlbumsQuery{Filter: filter, Sort: AlbumSortTitle, Ascending: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := albumIDs(page.Albums), []string{"al5", "al7", "al0"}; !slices.Equal(got, want) {
+		t.Errorf("expected the featured album among the artist's, got %v, want %v", got, want)
+	}
+
+	low := page.Albums[slices.IndexFunc(page.Albums, func(album AlbumDTO) bool { return album.ID == "al7" })]
+	if len(low.Artists) != 1 || low.Artists[0].ID != "ar2" || len(low.Featured) != 1 || low.Featured[0].ID != "ar0" {
+		t.Errorf("expected al7 by ar2 featuring ar0, got %+v and %+v", low.Artists, low.Featured)
+	}
+}
+
+func TestSetArtistSortName(t *testing.T) {
+	service, database := newTestService(t)
+	seedLibrary(t, database)
+	seedCredits(t, database)
+	ctx := context.Background()
+
+	sortName := func(artistId string) (string, bool) {
+		t.Helper()
+		overview, err := service.GetArtistOverview(ctx, "u1", artistId)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return overview.Artist.SortName, overview.CustomSortName
+	}
+
+	if got, custom := sortName("ar-apples"); got != "Apples, The" || custom {
+		t.Errorf("expected the derived sort name, got %q, %t", got, custom)
+	}
+	if err := service.SetArtistSortName(ctx, "u1", "ar-apples", " Apples "); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, custom := sortName("ar-apples"); got != "Apples" || !custom {
+		t.Errorf("expected the user's sort name, got %q, %t", got, custom)
+	}
+	if err := service.SetArtistSortName(ctx, "u1", "ar-apples", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, custom := sortName("ar-apples"); got != "Apples, The" || custom {
+		t.Errorf("expected the derived sort name back, got %q, %t", got, custom)
+	}
+
+	if _, err := database.Sql().Exec("UPDATE artists SET name = 'A Tribe' WHERE id = 'ar-apples'"); err != nil {
+		t.Fatalf("failed to rename: %v", err)
+	}
+	if got, _ := sortName("ar-apples"); got != "Tribe, A" {
+		t.Errorf("expected the sort name to follow the name, got %q", got)
+	}
+	if got, _ := sortName("ar1"); got != "Zed" {
+		t.Errorf("expected the seeded sort name, got %q", got)
+	}
+}
+
+func TestMergeArtists(t *testing.T) {
+	service, database := newTestService(t)
+	seedLibrary(t, database)
+	seedCredits(t, database)
+	ctx := context.Background()
+	exec := func(query string) {
+		t.Helper()
+		if _, err := database.Sql().Exec(query); err != nil {
+			t.Fatalf("failed to run %q: %v", query, err)
+		}
+	}
+	// seedCredits features ar0 on al7, where the duplicate is the primary
+	// credit.
+	exec("INSERT INTO artist_aliases (id, artist_id, source, external_id, name) VALUES ('alias-mb', 'ar2', 'musicbrainz', 'mb-beta', 'Beta')")
+	exec("INSERT INTO artist_sort_names (user_id, artist_id, sort_name) VALUES ('u1', 'ar2', 'Beta (2)')")
+	// Rows the keeper already has are skipped by the moves and left behind
+	// on the duplicate.
+	exec("INSERT INTO artist_sort_names (user_id, artist_id, sort_name) VALUES ('u2', 'ar0', 'Beta'), ('u2', 'ar2', 'Beta, again')")
+	exec("INSERT INTO user_artists (id, user_id, artist_id) VALUES ('ua0', 'u1', 'ar0'), ('ua2', 'u1', 'ar2')")
+
+	// ar-other is only credited in u2's library.
+	exec("INSERT INTO artists (id, spotify_id, name) VALUES ('ar-other', 'ar-other', 'Beta')")
+	exec("INSERT INTO album_artists (album_id, artist_id) VALUES ('other', 'ar-other')")
+
+	if err := service.MergeArtists(ctx, "u1", "ar0", "ar0"); !errors.Is(err, ErrMergeSameArtist) {
+		t.Errorf("expected ErrMergeSameArtist, got %v", err)
+	}
+	for _, merge := range []struct{ userId, artistId, duplicateId string }{
+		{"u1", "ar0", "ar-other"},
+		{"u1", "ar-other", "ar0"},
+		{"u1", "ar0", "unknown"},
+		{"u2", "ar-other", "ar2"},
+	} {
+		if err := service.MergeArtists(ctx, merge.userId, merge.artistId, merge.duplicateId); !errors.Is(err, ErrArtistNotFound) {
+			t.Errorf("expected merging %s into %s for %s to be refused, got %v", merge.duplicateId, merge.artistId, merge.userId, err)
+		}
+	}
+	if overview, err := service.GetArtistOverview(ctx, "u1", "ar2"); err != nil || len(overview.Albums) == 0 {
+		t.Fatalf("expected a refused merge to leave the duplicate alone, got %+v, %v", overview, err)
+	}
+
+	if err := service.MergeArtists(ctx, "u1", "ar0", "ar2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := service.GetArtistOverview(ctx, "u1", "ar2"); !errors.Is(err, ErrArtistNotFound) {
+		t.Errorf("expected the duplicate to be gone, got %v", err)
+	}
+	for _, table := range []string{"album_artists", "user_artists", "artist_sort_names", "artist_aliases"} {
+		var references int
+		if err := database.Sql().QueryRow("SELECT COUNT(*) FROM "+table+" WHERE artist_id = 'ar2'").Scan(&references); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if references != 0 {
+			t.Errorf("expected nothing in %s left on the duplicate, got %d rows", table, references)
+		}
+	}
+	overview, err := service.GetArtistOverview(ctx, "u1", "ar0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ids := albumIDs(overview.Albums)
+	slices.Sort(ids)
+	if !slices.Equal(ids, []string{"al0", "al3", "al5", "al7"}) || len(overview.AppearsOn) != 0 {
+		t.Errorf("expected the duplicate's albums as primary credits, got %v and %v", ids, albumIDs(overview.AppearsOn))
+	}
+	if overview.Artist.SortName != "Beta (2)" || !overview.CustomSortName {
+		t.Errorf("expected the duplicate's sort name to move over, got %q", overview.Artist.SortName)
+	}
+	if len(overview.Aliases) != 2 {
+		t.Fatalf("expected the moved alias and the duplicate's profile, got %+v", overview.Aliases)
+	}
+	spotifyAlias := overview.Aliases[1]
+	if spotifyAlias.Source != models.ArtistAliasSourceSpotify || spotifyAlias.ExternalID != "ar2" || spotifyAlias.URL() != "https://open.spotify.com/artist/ar2" {
+		t.Errorf("expected the duplicate's Spotify profile as an alias, got %+v", spotifyAlias)
+	}
+
+	// A sync crediting the duplicate's profile credits the artist instead.
+	model, err := getOrCreateArtist(ctx, database.Queries(), ArtistDTO{ID: "new", SpotifyID: "ar2", Name: "Beta"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if model.ID != "ar0" {
+		t.Errorf("expected the alias to resolve to ar0, got %s", model.ID)
+	}
+}
+
+func TestAddArtistAlias(t *testing.T) {
+	service, database := newTestService(t)
+	seedLibrary(t, database)
+	ctx := context.Background()
+
+	alias, err := service.AddArtistAlias(ctx, "ar1", models.ArtistAliasSourceManual, "ignored", " Alfa ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if alias.Name != "Alfa" || alias.ExternalID != "" || alias.URL() != "" {
+		t.Errorf("expected a manual alias without an id, got %+v", alias)
+	}
+
+	if _, err := service.AddArtistAlias(ctx, "ar1", models.ArtistAliasSourceMusicBrainz, "mb-alpha", "Alpha"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.AddArtistAlias(ctx, "ar3", models.ArtistAliasSourceMusicBrainz, "mb-alpha", "Alpha"); !errors.Is(err, ErrArtistAliasTaken) {
+		t.Errorf("expected ErrArtistAliasTaken, got %v", err)
+	}
+	for _, bad := range []struct {
+		source     models.ArtistAliasSource
+		externalId string
+		name       string
+	}{
+		{models.ArtistAliasSourceDiscogs, "", "Alpha"},
+		{models.ArtistAliasSourceManual, "", " "},
+		{models.ArtistAliasSourceSpotify, "sp-alpha", "Alpha"},
+	} {
+		if _, err := service.AddArtistAlias(ctx, "ar1", bad.source, bad.externalId, bad.name); !errors.Is(err, ErrInvalidArtistAlias) {
+			t.Errorf("expected ErrInvalidArtistAlias for %+v, got %v", bad, err)
+		}
+	}
+	if _, err := service.AddArtistAlias(ctx, "unknown", models.ArtistAliasSourceManual, "", "Alpha"); !errors.Is(err, ErrArtistNotFound) {
+		t.Errorf("expected ErrArtistNotFound, got %v", err)
+	}
+
+	if err := service.DeleteArtistAlias(ctx, "ar3", alias.ID); !errors.Is(err, ErrArtistAliasMissing) {
+		t.Errorf("expected another artist's alias to be missing, got %v", err)
+	}
+	if err := service.DeleteArtistAlias(ctx, "ar1", alias.ID); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestFindArtistDuplicates(t *testing.T) {
+	artist := ArtistDTO{ID: "1", Name: "The Beatles"}
+	duplicates := FindArtistDuplicates(artist, []ArtistDTO{
+		{ID: "1", Name: "The Beatles"},
+		{ID: "2", Name: "Beatles"},
+		{ID: "3", Name: "the beatles."},
+		{ID: "4", Name: "Beatles Tribute"},
+	})
+	if ids := albumArtistIDs(duplicates.Likely); !slices.Equal(ids, []string{"2", "3"}) {
+		t.Errorf("expected the same names as likely duplicates, got %v", ids)
+	}
+	if ids := albumArtistIDs(duplicates.Others); !slices.Equal(ids, []string{"4"}) {
+		t.Errorf("expected the rest as others, got %v", ids)
+	}
+}
+
+func albumArtistIDs(artists []ArtistDTO) []string {
+	ids := make([]string, len(artists))
+	for i, artist := range artists {
+		ids[i] = artist.ID
 	}
+	return ids
 }
@@ -0,0 +1,395 @@
+package adapters
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"github.com/alecdray/wax/src/internal/core/contextx"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"github.com/alecdray/wax/src/internal/core/httpx"
+	"github.com/alecdray/wax/src/internal/library"
+	libraryAdapters "github.com/alecdray/wax/src/internal/library/adapters"
+	"github.com/alecdray/wax/src/internal/links"
+	"github.com/alecdray/wax/src/internal/musicbrainz"
+	"github.com/alecdray/wax/src/internal/review"
+	"strings"
+
+	"github.com/lithammer/fuzzysearch/fuzzy"
+)
+
+type HttpHandler struct {
+	libraryService *library.Service
+	linksService   *links.Service
+	mb             *musicbrainz.Service
+}
+
+func NewHttpHandler(libraryService *library.Service, linksService *links.Service, mb *musicbrainz.Service) *HttpHandler {
+	return &HttpHandler{
+		libraryService: libraryService,
+		linksService:   linksService,
+		mb:             mb,
+	}
+}
+
+func handleLinkError(ctx contextx.ContextX, w http.ResponseWriter, err error) {
+	props := httpx.HandleErrorResponseProps{
+		Status: http.StatusInternalServerError,
+		Err:    err,
+	}
+	switch {
+	case errors.Is(err, links.ErrLinkNotFound), errors.Is(err, links.ErrAlbumNotFound):
+		props.Status = http.StatusNotFound
+	case errors.Is(err, links.ErrInvalidLink):
+		props.Status = http.StatusUnprocessableEntity
+		props.Response = *httpx.NewErrorResponse().SetComponent(LinkError(err.Error()))
+	case errors.Is(err, links.ErrLinkExists):
+		props.Status = http.StatusConflict
+		props.Response = *httpx.NewErrorResponse().SetComponent(LinkError(err.Error()))
+	}
+	httpx.HandleErrorResponse(ctx, w, props)
+}
+
+// linkTargets lists the library albums an album can link to, by title.
+func (h *HttpHandler) linkTargets(ctx contextx.ContextX, userId, albumId string) (library.AlbumDTOs, error) {
+	albums, err := h.libraryService.GetAlbumsInLibrary(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	targets := make(library.AlbumDTOs, 0, len(albums))
+	for _, album := range albums {
+		if album.ID != albumId {
+			targets = append(targets, album)
+		}
+	}
+	targets.SortByTitle(true)
+	return targets, nil
+}
+
+func (h *HttpHandler) GetAlbumLinkModal(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	q := r.URL.Query()
+	albumId := q.Get("albumId")
+	if albumId == "" {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    errors.New("missing album ID"),
+		})
+		return
+	}
+
+	album, err := h.libraryService.GetAlbumInLibrary(ctx, userId, albumId)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get album: %w", err),
+		})
+		return
+	}
+
+	targets, err := h.linkTargets(ctx, userId, albumId)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to get albums: %w", err),
+		})
+		return
+	}
+
+	input := links.LinkInput{
+		ToAlbumID: q.Get("targetId"),
+		Kind:      models.AlbumLinkKind(q.Get("kind")),
+	}
+	if input.Kind == "" {
+		input.Kind = links.Kinds[0]
+	}
+
+	err = AlbumLinkModal(*album, targets, input).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+	}
+}
+
+// SubmitAlbumLink links an album from the link modal, then refreshes the
+// album's links on its detail page.
+func (h *HttpHandler) SubmitAlbumLink(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	albumId := r.URL.Query().Get("albumId")
+	if albumId == "" {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    errors.New("missing album ID"),
+		})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	_, err = h.linksService.CreateLink(ctx, userId, links.LinkInput{
+		FromAlbumID: albumId,
+		ToAlbumID:   r.Form.Get("targetId"),
+		Kind:        models.AlbumLinkKind(r.Form.Get("kind")),
+		Note:        r.Form.Get("note"),
+	})
+	if err != nil {
+		handleLinkError(ctx, w, err)
+		return
+	}
+
+	album, err := h.libraryService.GetAlbumInLibrary(ctx, userId, albumId)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get album: %w", err),
+		})
+		return
+	}
+
+	err = CloseAlbumLinkModal().Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+
+	err = libraryAdapters.AlbumLinksCell(*album, true).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+	}
+}
+
+// DeleteLink removes a link from an album's detail page, answering with the
+// album's remaining links.
+func (h *HttpHandler) DeleteLink(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	albumId := r.URL.Query().Get("albumId")
+	if albumId == "" {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    errors.New("missing album ID"),
+		})
+		return
+	}
+
+	err = h.linksService.DeleteLink(ctx, userId, r.PathValue("linkId"))
+	if err != nil {
+		handleLinkError(ctx, w, err)
+		return
+	}
+
+	album, err := h.libraryService.GetAlbumInLibrary(ctx, userId, albumId)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get album: %w", err),
+		})
+		return
+	}
+
+	err = libraryAdapters.AlbumLinksCell(*album, false).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+	}
+}
+
+// GetAlbumLinkSuggestions looks the album up on MusicBrainz and suggests
+// links to the albums in the library it's related to there.
+func (h *HttpHandler) GetAlbumLinkSuggestions(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	albumId := r.URL.Query().Get("albumId")
+	album, err := h.libraryService.GetAlbumInLibrary(ctx, userId, albumId)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get album: %w", err),
+		})
+		return
+	}
+
+	var relations []musicbrainz.Relation
+	if len(album.Artists) > 0 {
+		group, err := h.mb.FindReleaseGroup(ctx, album.Title, album.Artists[0].Name)
+		if err == nil && group != nil {
+			relations, err = h.mb.GetRelatedReleaseGroups(ctx, group.ID)
+		}
+		if err != nil {
+			httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+				Status:   http.StatusBadGateway,
+				Err:      err,
+				Response: *httpx.NewErrorResponse().SetComponent(LinkError("MusicBrainz isn't available right now")),
+			})
+			return
+		}
+	}
+
+	targets, err := h.linkTargets(ctx, userId, albumId)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to get albums: %w", err),
+		})
+		return
+	}
+
+	err = AlbumLinkSuggestions(albumId, suggestLinks(relations, targets)).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+	}
+}
+
+// suggestLinks matches related MusicBrainz release groups to library albums
+// by title and artist, one suggestion per album.
+func suggestLinks(relations []musicbrainz.Relation, albums library.AlbumDTOs) []Suggestion {
+	suggestions := []Suggestion{}
+	suggested := make(map[string]bool)
+	for _, relation := range relations {
+		group := relation.ReleaseGroup
+		for _, album := range albums {
+			if suggested[album.ID] || !strings.EqualFold(album.Title, group.Title) {
+				continue
+			}
+			if len(group.ArtistCredit) > 0 && !sharesArtist(album, group.ArtistCredit) {
+				continue
+			}
+			suggestions = append(suggestions, Suggestion{
+				Album:    album,
+				Kind:     links.SuggestedKind(relation.Type),
+				Relation: relation.Type,
+			})
+			suggested[album.ID] = true
+		}
+	}
+	return suggestions
+}
+
+func sharesArtist(album library.AlbumDTO, credits []musicbrainz.ArtistCredit) bool {
+	for _, artist := range album.Artists {
+		for _, credit := range credits {
+			if fuzzy.RankMatchNormalizedFold(artist.Name, credit.Name) != -1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetGraph answers with the user's linked albums as JSON nodes and edges for
+// a graph view. It takes the library's tag and rating filters, keeping only
+// links between albums that pass them.
+func (h *HttpHandler) GetGraph(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	fp := libraryAdapters.ParseFilterParams(ctx, r.URL.Query())
+	albums, err := h.libraryService.QueryAllAlbums(ctx, userId, library.AlbumsQuery{Filter: fp})
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to get library albums: %w", err),
+		})
+		return
+	}
+
+	userLinks, err := h.linksService.GetUserLinks(ctx, userId)
+	if err != nil {
+		handleLinkError(ctx, w, err)
+		return
+	}
+
+	profile := review.RatingProfileFromContext(ctx)
+	nodes := make(map[string]links.GraphNode, len(albums))
+	for _, album := range albums {
+		artists := make([]string, 0, len(album.Artists))
+		for _, artist := range album.Artists {
+			artists = append(artists, artist.Name)
+		}
+		node := links.GraphNode{
+			ID:       album.ID,
+			Title:    album.Title,
+			Artists:  strings.Join(artists, ", "),
+			ImageURL: album.ImageURL,
+		}
+		if album.Rating != nil && album.Rating.Rating != nil {
+			rating := profile.ToScale(*album.Rating.Rating)
+			node.Rating = &rating
+		}
+		nodes[album.ID] = node
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(links.NewGraph(userLinks, nodes))
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to encode graph: %w", err),
+		})
+	}
+}
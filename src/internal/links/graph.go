@@ -0,0 +1,65 @@
+package links
+
+import (
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"sort"
+)
+
+// Graph is the user's linked albums in the shape force-graph views expect:
+// albums as nodes and links as directed edges.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+type GraphNode struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Artists  string `json:"artists"`
+	ImageURL string `json:"imageUrl,omitempty"`
+	// Rating is on the user's rating scale, nil when the album is unrated.
+	Rating *float64 `json:"rating,omitempty"`
+}
+
+type GraphEdge struct {
+	ID     string               `json:"id"`
+	Source string               `json:"source"`
+	Target string               `json:"target"`
+	Kind   models.AlbumLinkKind `json:"kind"`
+	Label  string               `json:"label"`
+	Note   string               `json:"note,omitempty"`
+}
+
+// NewGraph builds a graph from the user's links, keeping only links between
+// the given albums and only the albums that are linked.
+func NewGraph(userLinks []LinkDTO, albums map[string]GraphNode) Graph {
+	graph := Graph{
+		Nodes: []GraphNode{},
+		Edges: []GraphEdge{},
+	}
+	linked := make(map[string]bool)
+	for _, link := range userLinks {
+		_, fromOk := albums[link.FromAlbumID]
+		_, toOk := albums[link.ToAlbumID]
+		if !fromOk || !toOk {
+			continue
+		}
+		graph.Edges = append(graph.Edges, GraphEdge{
+			ID:     link.ID,
+			Source: link.FromAlbumID,
+			Target: link.ToAlbumID,
+			Kind:   link.Kind,
+			Label:  KindLabel(link.Kind),
+			Note:   link.Note,
+		})
+		linked[link.FromAlbumID] = true
+		linked[link.ToAlbumID] = true
+	}
+	for albumId := range linked {
+		graph.Nodes = append(graph.Nodes, albums[albumId])
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].ID < graph.Nodes[j].ID
+	})
+	return graph
+}
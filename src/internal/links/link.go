@@ -0,0 +1,149 @@
+package links
+
+import (
+	"errors"
+	"fmt"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"github.com/alecdray/wax/src/internal/core/db/sqlc"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const noteMaxLength = 500
+
+var (
+	ErrInvalidLink   = errors.New("invalid link")
+	ErrLinkNotFound  = errors.New("link not found")
+	ErrAlbumNotFound = errors.New("album not found")
+	ErrLinkExists    = errors.New("those albums are already linked that way")
+)
+
+// Kinds lists the kinds of link in display order.
+var Kinds = []models.AlbumLinkKind{
+	models.AlbumLinkKindInfluencedBy,
+	models.AlbumLinkKindSoundsLike,
+	models.AlbumLinkKindSequelTo,
+	models.AlbumLinkKindSameSession,
+	models.AlbumLinkKindIntroducedMeTo,
+}
+
+// KindLabel reads a link from the album it starts at: "<album> influenced
+// by <other album>".
+func KindLabel(kind models.AlbumLinkKind) string {
+	switch kind {
+	case models.AlbumLinkKindInfluencedBy:
+		return "Influenced by"
+	case models.AlbumLinkKindSoundsLike:
+		return "Sounds like"
+	case models.AlbumLinkKindSequelTo:
+		return "Sequel to"
+	case models.AlbumLinkKindSameSession:
+		return "Same session as"
+	case models.AlbumLinkKindIntroducedMeTo:
+		return "Introduced me to"
+	default:
+		return string(kind)
+	}
+}
+
+// InverseKindLabel reads a link from the album it points at.
+func InverseKindLabel(kind models.AlbumLinkKind) string {
+	switch kind {
+	case models.AlbumLinkKindInfluencedBy:
+		return "Influenced"
+	case models.AlbumLinkKindSequelTo:
+		return "Followed by"
+	case models.AlbumLinkKindIntroducedMeTo:
+		return "Introduced me via"
+	default:
+		return KindLabel(kind)
+	}
+}
+
+// SuggestedKind picks the kind of link to suggest for a MusicBrainz release
+// group relationship. Singles share a session with the album they're from;
+// anything else MusicBrainz relates is at least likely to sound alike.
+func SuggestedKind(relationType string) models.AlbumLinkKind {
+	switch relationType {
+	case "single from":
+		return models.AlbumLinkKindSameSession
+	default:
+		return models.AlbumLinkKindSoundsLike
+	}
+}
+
+type LinkDTO struct {
+	ID          string
+	FromAlbumID string
+	ToAlbumID   string
+	Kind        models.AlbumLinkKind
+	Note        string
+	CreatedAt   time.Time
+}
+
+func newLinkDTOFromModel(model sqlc.AlbumLink) LinkDTO {
+	return LinkDTO{
+		ID:          model.ID,
+		FromAlbumID: model.FromAlbumID,
+		ToAlbumID:   model.ToAlbumID,
+		Kind:        model.Kind,
+		Note:        model.Note,
+		CreatedAt:   model.CreatedAt,
+	}
+}
+
+// AlbumRef is the album at the other end of a link.
+type AlbumRef struct {
+	ID       string
+	Title    string
+	ImageURL string
+	Artists  string
+}
+
+// AlbumLinkDTO is a link as seen from one of its albums.
+type AlbumLinkDTO struct {
+	LinkDTO
+	// Outgoing is set when the link starts at the album it's seen from.
+	Outgoing bool
+	Other    AlbumRef
+}
+
+// Label reads the link from the album it's seen from.
+func (l AlbumLinkDTO) Label() string {
+	if l.Outgoing {
+		return KindLabel(l.Kind)
+	}
+	return InverseKindLabel(l.Kind)
+}
+
+type LinkInput struct {
+	FromAlbumID string
+	ToAlbumID   string
+	Kind        models.AlbumLinkKind
+	Note        string
+}
+
+func (in LinkInput) Normalize() LinkInput {
+	in.FromAlbumID = strings.TrimSpace(in.FromAlbumID)
+	in.ToAlbumID = strings.TrimSpace(in.ToAlbumID)
+	in.Note = strings.TrimSpace(in.Note)
+	return in
+}
+
+func (in LinkInput) Validate() error {
+	var errs []error
+	if in.FromAlbumID == "" || in.ToAlbumID == "" {
+		errs = append(errs, errors.New("pick an album to link to"))
+	} else if in.FromAlbumID == in.ToAlbumID {
+		errs = append(errs, errors.New("an album can't link to itself"))
+	}
+	if !slices.Contains(Kinds, in.Kind) {
+		errs = append(errs, fmt.Errorf("unknown kind of link %q", in.Kind))
+	}
+	if utf8.RuneCountInString(in.Note) > noteMaxLength {
+		errs = append(errs, fmt.Errorf("the note can be at most %d characters", noteMaxLength))
+	}
+	return errors.Join(errs...)
+}
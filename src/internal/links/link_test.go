@@ -0,0 +1,103 @@
+package links
+
+import (
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"strings"
+	"testing"
+)
+
+func TestLinkInput_Validate(t *testing.T) {
+	cases := []struct {
+		name  string
+		input LinkInput
+		ok    bool
+	}{
+		{"valid", LinkInput{FromAlbumID: "a", ToAlbumID: "b", Kind: models.AlbumLinkKindSoundsLike}, true},
+		{"with note", LinkInput{FromAlbumID: "a", ToAlbumID: "b", Kind: models.AlbumLinkKindInfluencedBy, Note: "same producer"}, true},
+		{"missing target", LinkInput{FromAlbumID: "a", Kind: models.AlbumLinkKindSoundsLike}, false},
+		{"blank target", LinkInput{FromAlbumID: "a", ToAlbumID: "  ", Kind: models.AlbumLinkKindSoundsLike}, false},
+		{"self link", LinkInput{FromAlbumID: "a", ToAlbumID: " a ", Kind: models.AlbumLinkKindSequelTo}, false},
+		{"unknown kind", LinkInput{FromAlbumID: "a", ToAlbumID: "b", Kind: "covers"}, false},
+		{"note too long", LinkInput{FromAlbumID: "a", ToAlbumID: "b", Kind: models.AlbumLinkKindSoundsLike, Note: strings.Repeat("a", 501)}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.input.Normalize().Validate()
+			if c.ok && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !c.ok && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestAlbumLinkDTO_Label(t *testing.T) {
+	cases := []struct {
+		kind     models.AlbumLinkKind
+		outgoing bool
+		want     string
+	}{
+		{models.AlbumLinkKindInfluencedBy, true, "Influenced by"},
+		{models.AlbumLinkKindInfluencedBy, false, "Influenced"},
+		{models.AlbumLinkKindSequelTo, false, "Followed by"},
+		{models.AlbumLinkKindIntroducedMeTo, false, "Introduced me via"},
+		{models.AlbumLinkKindSoundsLike, false, "Sounds like"},
+		{models.AlbumLinkKindSameSession, false, "Same session as"},
+	}
+	for _, c := range cases {
+		link := AlbumLinkDTO{LinkDTO: LinkDTO{Kind: c.kind}, Outgoing: c.outgoing}
+		if got := link.Label(); got != c.want {
+			t.Errorf("Label() for %q (outgoing %v) = %q, want %q", c.kind, c.outgoing, got, c.want)
+		}
+	}
+}
+
+func TestSuggestedKind(t *testing.T) {
+	if got := SuggestedKind("single from"); got != models.AlbumLinkKindSameSession {
+		t.Errorf("expected singles to suggest %q, got %q", models.AlbumLinkKindSameSession, got)
+	}
+	if got := SuggestedKind("remix"); got != models.AlbumLinkKindSoundsLike {
+		t.Errorf("expected other relations to suggest %q, got %q", models.AlbumLinkKindSoundsLike, got)
+	}
+}
+
+func TestNewGraph(t *testing.T) {
+	albums := map[string]GraphNode{
+		"b":      {ID: "b", Title: "B"},
+		"a":      {ID: "a", Title: "A"},
+		"c":      {ID: "c", Title: "C"},
+		"lonely": {ID: "lonely", Title: "Lonely"},
+	}
+	userLinks := []LinkDTO{
+		{ID: "1", FromAlbumID: "b", ToAlbumID: "a", Kind: models.AlbumLinkKindInfluencedBy, Note: "riffs"},
+		{ID: "2", FromAlbumID: "a", ToAlbumID: "c", Kind: models.AlbumLinkKindSequelTo},
+		// Filtered out of the albums, so the link drops out too.
+		{ID: "3", FromAlbumID: "c", ToAlbumID: "hidden", Kind: models.AlbumLinkKindSoundsLike},
+	}
+
+	graph := NewGraph(userLinks, albums)
+
+	if len(graph.Edges) != 2 {
+		t.Fatalf("expected 2 edges, got %d", len(graph.Edges))
+	}
+	edge := graph.Edges[0]
+	if edge.Source != "b" || edge.Target != "a" || edge.Label != "Influenced by" || edge.Note != "riffs" {
+		t.Errorf("unexpected edge %+v", edge)
+	}
+	var ids []string
+	for _, node := range graph.Nodes {
+		ids = append(ids, node.ID)
+	}
+	if strings.Join(ids, ",") != "a,b,c" {
+		t.Errorf("expected linked nodes a,b,c in order, got %v", ids)
+	}
+}
+
+func TestNewGraph_Empty(t *testing.T) {
+	graph := NewGraph(nil, map[string]GraphNode{"a": {ID: "a"}})
+	if graph.Nodes == nil || graph.Edges == nil || len(graph.Nodes) != 0 || len(graph.Edges) != 0 {
+		t.Errorf("expected empty, non-nil nodes and edges, got %+v", graph)
+	}
+}
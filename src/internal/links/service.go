@@ -0,0 +1,177 @@
+package links
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/alecdray/wax/src/internal/core/db"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"github.com/alecdray/wax/src/internal/core/db/sqlc"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type Service struct {
+	db *db.DB
+}
+
+func NewService(db *db.DB) *Service {
+	return &Service{db: db}
+}
+
+// CreateLink links one album to another. Links are directed, so the same
+// kind of link can run each way between two albums.
+func (s *Service) CreateLink(ctx context.Context, userId string, input LinkInput) (LinkDTO, error) {
+	input = input.Normalize()
+	err := input.Validate()
+	if err != nil {
+		return LinkDTO{}, fmt.Errorf("%w: %w", ErrInvalidLink, err)
+	}
+
+	var link LinkDTO
+	err = s.db.WithTx(func(tx *db.DB) error {
+		for _, albumId := range []string{input.FromAlbumID, input.ToAlbumID} {
+			_, err := tx.Queries().GetAlbum(ctx, albumId)
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrAlbumNotFound
+			} else if err != nil {
+				return fmt.Errorf("failed to get album: %w", err)
+			}
+		}
+
+		_, err := tx.Queries().GetAlbumLinkByEnds(ctx, sqlc.GetAlbumLinkByEndsParams{
+			UserID:      userId,
+			FromAlbumID: input.FromAlbumID,
+			ToAlbumID:   input.ToAlbumID,
+			Kind:        input.Kind,
+		})
+		if err == nil {
+			return ErrLinkExists
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get link: %w", err)
+		}
+
+		model, err := tx.Queries().CreateAlbumLink(ctx, sqlc.CreateAlbumLinkParams{
+			ID:          uuid.NewString(),
+			UserID:      userId,
+			FromAlbumID: input.FromAlbumID,
+			ToAlbumID:   input.ToAlbumID,
+			Kind:        input.Kind,
+			Note:        input.Note,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create link: %w", err)
+		}
+		link = newLinkDTOFromModel(model)
+		return nil
+	})
+	if err != nil {
+		return LinkDTO{}, err
+	}
+	return link, nil
+}
+
+func (s *Service) DeleteLink(ctx context.Context, userId, linkId string) error {
+	return s.db.WithTx(func(tx *db.DB) error {
+		_, err := tx.Queries().GetAlbumLink(ctx, sqlc.GetAlbumLinkParams{
+			ID:     linkId,
+			UserID: userId,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrLinkNotFound
+		} else if err != nil {
+			return fmt.Errorf("failed to get link: %w", err)
+		}
+		err = tx.Queries().DeleteAlbumLink(ctx, sqlc.DeleteAlbumLinkParams{
+			ID:     linkId,
+			UserID: userId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete link: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetUserLinks returns all of the user's links, oldest first.
+func (s *Service) GetUserLinks(ctx context.Context, userId string) ([]LinkDTO, error) {
+	models, err := s.db.Queries().GetAlbumLinksByUserId(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get links: %w", err)
+	}
+	dtos := make([]LinkDTO, 0, len(models))
+	for _, model := range models {
+		dtos = append(dtos, newLinkDTOFromModel(model))
+	}
+	return dtos, nil
+}
+
+// GetAlbumLinks returns the links to and from an album, oldest first, with
+// the album at the other end of each.
+func (s *Service) GetAlbumLinks(ctx context.Context, userId, albumId string) ([]AlbumLinkDTO, error) {
+	models, err := s.db.Queries().GetAlbumLinksByAlbumId(ctx, sqlc.GetAlbumLinksByAlbumIdParams{
+		UserID:      userId,
+		FromAlbumID: albumId,
+		ToAlbumID:   albumId,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get album links: %w", err)
+	}
+	if len(models) == 0 {
+		return []AlbumLinkDTO{}, nil
+	}
+
+	otherIds := make([]string, 0, len(models))
+	for _, model := range models {
+		if model.FromAlbumID == albumId {
+			otherIds = append(otherIds, model.ToAlbumID)
+		} else {
+			otherIds = append(otherIds, model.FromAlbumID)
+		}
+	}
+	others, err := s.getAlbumRefs(ctx, otherIds)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]AlbumLinkDTO, 0, len(models))
+	for i, model := range models {
+		dtos = append(dtos, AlbumLinkDTO{
+			LinkDTO:  newLinkDTOFromModel(model),
+			Outgoing: model.FromAlbumID == albumId,
+			Other:    others[otherIds[i]],
+		})
+	}
+	return dtos, nil
+}
+
+func (s *Service) getAlbumRefs(ctx context.Context, albumIds []string) (map[string]AlbumRef, error) {
+	albums, err := s.db.Queries().GetAlbumsByIDs(ctx, albumIds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get albums: %w", err)
+	}
+	artistRows, err := s.db.Queries().GetAlbumArtistsByAlbumIds(ctx, albumIds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get album artists: %w", err)
+	}
+	artistsByAlbumId := make(map[string][]string, len(albumIds))
+	for _, row := range artistRows {
+		if row.Role != models.ArtistRolePrimary {
+			continue
+		}
+		artistsByAlbumId[row.AlbumID] = append(artistsByAlbumId[row.AlbumID], row.Artist.Name)
+	}
+
+	refs := make(map[string]AlbumRef, len(albums))
+	for _, album := range albums {
+		refs[album.ID] = AlbumRef{
+			ID:       album.ID,
+			Title:    album.Title,
+			ImageURL: album.ImageUrl.String,
+			Artists:  strings.Join(artistsByAlbumId[album.ID], ", "),
+		}
+	}
+	return refs, nil
+}
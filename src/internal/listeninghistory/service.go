@@ -3,9 +3,11 @@ package listeninghistory
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/alecdray/wax/src/internal/core/contextx"
 	"github.com/alecdray/wax/src/internal/core/db"
+	"github.com/alecdray/wax/src/internal/core/db/models"
 	"github.com/alecdray/wax/src/internal/core/db/sqlc"
 	"github.com/alecdray/wax/src/internal/spotify"
 	"time"
@@ -64,18 +66,24 @@ func (s *Service) upsertPlayHistory(ctx context.Context, userID string, items []
 			return fmt.Errorf("failed to get/create album track: %w", err)
 		}
 
-		for _, a := range album.Artists {
-			artistModel, err := s.db.Queries().GetOrCreateArtist(ctx, sqlc.GetOrCreateArtistParams{
-				ID:        uuid.NewString(),
-				SpotifyID: a.ID.String(),
-				Name:      a.Name,
-			})
+		for i, a := range album.Artists {
+			// A Spotify artist merged into another is credited to that one.
+			artistModel, err := s.db.Queries().GetArtistBySpotifyAlias(ctx, sql.NullString{String: a.ID.String(), Valid: true})
+			if errors.Is(err, sql.ErrNoRows) {
+				artistModel, err = s.db.Queries().GetOrCreateArtist(ctx, sqlc.GetOrCreateArtistParams{
+					ID:        uuid.NewString(),
+					SpotifyID: a.ID.String(),
+					Name:      a.Name,
+				})
+			}
 			if err != nil {
 				return fmt.Errorf("failed to get/create artist %s: %w", a.ID, err)
 			}
 			_, err = s.db.Queries().GetOrCreateAlbumArtist(ctx, sqlc.GetOrCreateAlbumArtistParams{
 				AlbumID:  albumModel.ID,
 				ArtistID: artistModel.ID,
+				Position: int64(i),
+				Role:     models.ArtistRolePrimary,
 			})
 			if err != nil {
 				return fmt.Errorf("failed to get/create album artist: %w", err)
@@ -27,6 +27,9 @@ var (
 	IncludeUserRatings Include = "user-ratings"
 	IncludeGenres      Include = "genres"
 	IncludeUserGenres  Include = "user-genres"
+	// IncludeReleaseGroupRels adds relationships to other release groups to
+	// a lookup.
+	IncludeReleaseGroupRels Include = "release-group-rels"
 )
 
 func (i Include) String() string {
@@ -157,3 +160,32 @@ func (client *Client) SearchEntities(ctx contextx.ContextX, entity Entity, props
 
 	return &result, nil
 }
+
+// LookupReleaseGroup fetches a release group by ID, with the given includes
+// such as "release-group-rels".
+func (client *Client) LookupReleaseGroup(ctx contextx.ContextX, id string, includes []string) (*ReleaseGroup, error) {
+	path := fmt.Sprintf("/ws/2/%s/%s", EntityReleaseGroup, id)
+
+	query := url.Values{}
+	if len(includes) > 0 {
+		query.Set("inc", strings.Join(includes, " "))
+	}
+
+	resp, err := client.MakeRequest(ctx, http.MethodGet, path, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	var result ReleaseGroup
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return &result, nil
+}
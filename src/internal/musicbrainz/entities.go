@@ -1,5 +1,7 @@
 package musicbrainz
 
+import "strings"
+
 type EntityType string
 
 var (
@@ -78,12 +80,23 @@ type ReleaseGroup struct {
 	ArtistCredit     []ArtistCredit `json:"artist-credit"`
 	Releases         []Release      `json:"releases"`
 	Tags             []Tag          `json:"tags"`
+	Genres           []Genre        `json:"genres,omitempty"`
+	Relations        []Relation     `json:"relations,omitempty"`
 }
 
 func (r ReleaseGroup) Slug() EntityType {
 	return EntityReleaseGroup
 }
 
+// Relation is a relationship from a looked-up entity to another. Only
+// release group targets are decoded.
+type Relation struct {
+	Type         string        `json:"type"`
+	Direction    string        `json:"direction"`
+	TargetType   string        `json:"target-type"`
+	ReleaseGroup *ReleaseGroup `json:"release_group,omitempty"`
+}
+
 type ReleaseEvent struct {
 	Date string `json:"date"`
 	Area Area   `json:"area"`
@@ -117,8 +130,20 @@ type Track struct {
 }
 
 type ArtistCredit struct {
-	Name   string `json:"name"`
-	Artist Artist `json:"artist"`
+	Name       string `json:"name"`
+	JoinPhrase string `json:"joinphrase"`
+	Artist     Artist `json:"artist"`
+}
+
+// CreditString joins an artist credit the way MusicBrainz displays it, e.g.
+// "Simon & Garfunkel".
+func CreditString(credits []ArtistCredit) string {
+	var b strings.Builder
+	for _, credit := range credits {
+		b.WriteString(credit.Name)
+		b.WriteString(credit.JoinPhrase)
+	}
+	return strings.TrimSpace(b.String())
 }
 
 type Artist struct {
@@ -167,3 +192,11 @@ type Tag struct {
 	Count int    `json:"count"`
 	Name  string `json:"name"`
 }
+
+// Genre is a tag MusicBrainz recognizes as a genre, with how many people
+// applied it.
+type Genre struct {
+	ID    string `json:"id"`
+	Count int    `json:"count"`
+	Name  string `json:"name"`
+}
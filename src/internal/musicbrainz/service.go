@@ -46,3 +46,91 @@ func (s *Service) FindRecording(ctx contextx.ContextX, entity Entity, title stri
 
 	return nil, nil
 }
+
+// SearchReleaseGroups searches MusicBrainz for albums matching a free-text
+// query, best match first.
+func (s *Service) SearchReleaseGroups(ctx contextx.ContextX, query string, limit int) ([]ReleaseGroup, error) {
+	results, err := s.client.SearchEntities(ctx, ReleaseGroup{}, QueryProps{
+		Query: query,
+		Limit: limit,
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to search musicbrainz: %w", err)
+		return nil, err
+	}
+
+	return results.ReleaseGroups, nil
+}
+
+// FindReleaseGroup finds the MusicBrainz album best matching a title and
+// artist, or nil when nothing matches.
+func (s *Service) FindReleaseGroup(ctx contextx.ContextX, title string, artist string) (*ReleaseGroup, error) {
+	results, err := s.client.SearchEntities(ctx, ReleaseGroup{}, QueryProps{
+		Query: fmt.Sprintf("releasegroup:(%s) AND artist:(%s)", title, artist),
+		Limit: 5,
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to search musicbrainz: %w", err)
+		return nil, err
+	}
+
+	for _, group := range results.ReleaseGroups {
+		isTitleMatch := fuzzy.RankMatchNormalizedFold(title, group.Title) != -1
+		if isTitleMatch {
+			for _, groupArtist := range group.ArtistCredit {
+				isArtistMatch := fuzzy.RankMatchNormalizedFold(artist, groupArtist.Name) != -1
+				if isArtistMatch {
+					return &group, nil
+				}
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// GetRelatedReleaseGroups returns a release group's relationships to other
+// release groups, such as the album a single is from.
+func (s *Service) GetRelatedReleaseGroups(ctx contextx.ContextX, id string) ([]Relation, error) {
+	group, err := s.client.LookupReleaseGroup(ctx, id, []string{IncludeReleaseGroupRels.String()})
+	if err != nil {
+		err = fmt.Errorf("failed to look up release group: %w", err)
+		return nil, err
+	}
+
+	relations := make([]Relation, 0, len(group.Relations))
+	for _, relation := range group.Relations {
+		if relation.ReleaseGroup != nil {
+			relations = append(relations, relation)
+		}
+	}
+	return relations, nil
+}
+
+// minTagVotes is how many people must have applied a tag that isn't a genre
+// before it's worth passing on; one-off tags are mostly noise like
+// "seen live".
+const minTagVotes = 2
+
+// GetReleaseGroupGenres returns a release group's genres, then the other tags
+// enough people applied to it, each with its vote count.
+func (s *Service) GetReleaseGroupGenres(ctx contextx.ContextX, id string) ([]Tag, error) {
+	group, err := s.client.LookupReleaseGroup(ctx, id, []string{IncludeGenres.String(), IncludeTags.String()})
+	if err != nil {
+		err = fmt.Errorf("failed to look up release group: %w", err)
+		return nil, err
+	}
+
+	genres := make(map[string]bool, len(group.Genres))
+	tags := make([]Tag, 0, len(group.Genres)+len(group.Tags))
+	for _, genre := range group.Genres {
+		genres[genre.Name] = true
+		tags = append(tags, Tag{Name: genre.Name, Count: genre.Count})
+	}
+	for _, tag := range group.Tags {
+		if !genres[tag.Name] && tag.Count >= minTagVotes {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
@@ -0,0 +1,357 @@
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"github.com/alecdray/wax/src/internal/core/contextx"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"github.com/alecdray/wax/src/internal/core/httpx"
+	"github.com/alecdray/wax/src/internal/core/templates"
+	"github.com/alecdray/wax/src/internal/library"
+	libraryAdapters "github.com/alecdray/wax/src/internal/library/adapters"
+	"github.com/alecdray/wax/src/internal/people"
+	"strings"
+	"time"
+)
+
+type HttpHandler struct {
+	libraryService *library.Service
+	peopleService  *people.Service
+}
+
+func NewHttpHandler(libraryService *library.Service, peopleService *people.Service) *HttpHandler {
+	return &HttpHandler{
+		libraryService: libraryService,
+		peopleService:  peopleService,
+	}
+}
+
+func handlePeopleError(ctx contextx.ContextX, w http.ResponseWriter, err error) {
+	props := httpx.HandleErrorResponseProps{
+		Status: http.StatusInternalServerError,
+		Err:    err,
+	}
+	switch {
+	case errors.Is(err, people.ErrPersonNotFound), errors.Is(err, people.ErrAlbumPersonNotFound), errors.Is(err, people.ErrAlbumNotFound):
+		props.Status = http.StatusNotFound
+	case errors.Is(err, people.ErrInvalidPerson):
+		props.Status = http.StatusUnprocessableEntity
+		props.Response = *httpx.NewErrorResponse().SetComponent(PeopleError(err.Error()))
+	case errors.Is(err, people.ErrPersonNameTaken), errors.Is(err, people.ErrAlbumPersonExists):
+		props.Status = http.StatusConflict
+		props.Response = *httpx.NewErrorResponse().SetComponent(PeopleError(err.Error()))
+	}
+	httpx.HandleErrorResponse(ctx, w, props)
+}
+
+func parsePersonInput(form url.Values) people.PersonInput {
+	return people.PersonInput{
+		Name:  form.Get("name"),
+		Notes: form.Get("notes"),
+	}
+}
+
+// parseSharedAt reads the day an album was shared from a date input. An
+// empty value leaves it to default to now.
+func parseSharedAt(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	sharedAt, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: the date must look like 2006-01-02", people.ErrInvalidPerson)
+	}
+	return sharedAt, nil
+}
+
+func (h *HttpHandler) GetPeoplePage(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	stats, err := h.peopleService.GetRecommendationStats(ctx, userId)
+	if err != nil {
+		handlePeopleError(ctx, w, err)
+		return
+	}
+
+	err = PeoplePage(stats).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) CreatePerson(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	_, err = h.peopleService.CreatePerson(ctx, userId, parsePersonInput(r.Form))
+	if err != nil {
+		handlePeopleError(ctx, w, err)
+		return
+	}
+
+	err = templates.Redirect("/app/people", 0).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) UpdatePerson(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	_, err = h.peopleService.UpdatePerson(ctx, userId, r.PathValue("personId"), parsePersonInput(r.Form))
+	if err != nil {
+		handlePeopleError(ctx, w, err)
+		return
+	}
+
+	err = templates.Redirect("/app/people", 0).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+// DeletePerson forgets a person, answering with nothing so their row is
+// swapped out.
+func (h *HttpHandler) DeletePerson(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	err = h.peopleService.DeletePerson(ctx, userId, r.PathValue("personId"))
+	if err != nil {
+		handlePeopleError(ctx, w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *HttpHandler) GetAlbumPersonModal(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	albumId := r.URL.Query().Get("albumId")
+	if albumId == "" {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    errors.New("missing album ID"),
+		})
+		return
+	}
+
+	album, err := h.libraryService.GetAlbumInLibrary(ctx, userId, albumId)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get album: %w", err),
+		})
+		return
+	}
+
+	userPeople, err := h.peopleService.GetUserPeople(ctx, userId)
+	if err != nil {
+		handlePeopleError(ctx, w, err)
+		return
+	}
+
+	err = AlbumPersonModal(*album, userPeople).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+	}
+}
+
+// SubmitAlbumPerson records a person on an album from the album person modal,
+// then refreshes the album's people on its detail page.
+func (h *HttpHandler) SubmitAlbumPerson(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	albumId := r.URL.Query().Get("albumId")
+	if albumId == "" {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    errors.New("missing album ID"),
+		})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	sharedAt, err := parseSharedAt(r.Form.Get("sharedAt"))
+	if err != nil {
+		handlePeopleError(ctx, w, err)
+		return
+	}
+
+	_, err = h.peopleService.AddAlbumPerson(ctx, userId, people.AlbumPersonInput{
+		AlbumID:    albumId,
+		PersonName: r.Form.Get("name"),
+		Direction:  models.AlbumPersonDirection(r.Form.Get("direction")),
+		SharedAt:   sharedAt,
+	})
+	if err != nil {
+		handlePeopleError(ctx, w, err)
+		return
+	}
+
+	album, err := h.libraryService.GetAlbumInLibrary(ctx, userId, albumId)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get album: %w", err),
+		})
+		return
+	}
+
+	err = CloseAlbumPersonModal().Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+
+	err = libraryAdapters.AlbumPeopleCell(*album, true).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+	}
+}
+
+// RemoveAlbumPerson takes a person off an album's detail page, answering
+// with the album's remaining people.
+func (h *HttpHandler) RemoveAlbumPerson(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	albumId := r.URL.Query().Get("albumId")
+	if albumId == "" {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    errors.New("missing album ID"),
+		})
+		return
+	}
+
+	err = h.peopleService.RemoveAlbumPerson(ctx, userId, r.PathValue("albumPersonId"))
+	if err != nil {
+		handlePeopleError(ctx, w, err)
+		return
+	}
+
+	album, err := h.libraryService.GetAlbumInLibrary(ctx, userId, albumId)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get album: %w", err),
+		})
+		return
+	}
+
+	err = libraryAdapters.AlbumPeopleCell(*album, false).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+	}
+}
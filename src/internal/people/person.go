@@ -0,0 +1,154 @@
+package people
+
+import (
+	"errors"
+	"fmt"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"github.com/alecdray/wax/src/internal/core/db/sqlc"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	personNameMaxLength  = 100
+	personNotesMaxLength = 1000
+)
+
+var (
+	ErrInvalidPerson       = errors.New("invalid person")
+	ErrPersonNotFound      = errors.New("person not found")
+	ErrPersonNameTaken     = errors.New("someone with that name already exists")
+	ErrAlbumNotFound       = errors.New("album not found")
+	ErrAlbumPersonNotFound = errors.New("album person not found")
+	ErrAlbumPersonExists   = errors.New("they're already on this album that way")
+)
+
+// Directions lists the ways an album passes between the user and a person,
+// in display order.
+var Directions = []models.AlbumPersonDirection{
+	models.AlbumPersonDirectionIntroducedBy,
+	models.AlbumPersonDirectionSharedWith,
+}
+
+func DirectionLabel(direction models.AlbumPersonDirection) string {
+	switch direction {
+	case models.AlbumPersonDirectionIntroducedBy:
+		return "Introduced by"
+	case models.AlbumPersonDirectionSharedWith:
+		return "Shared with"
+	default:
+		return string(direction)
+	}
+}
+
+type PersonDTO struct {
+	ID        string
+	Name      string
+	Notes     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func newPersonDTOFromModel(model sqlc.Person) PersonDTO {
+	return PersonDTO{
+		ID:        model.ID,
+		Name:      model.Name,
+		Notes:     model.Notes,
+		CreatedAt: model.CreatedAt,
+		UpdatedAt: model.UpdatedAt,
+	}
+}
+
+type PersonInput struct {
+	Name  string
+	Notes string
+}
+
+func (in PersonInput) Normalize() PersonInput {
+	in.Name = strings.TrimSpace(in.Name)
+	in.Notes = strings.TrimSpace(in.Notes)
+	return in
+}
+
+func (in PersonInput) Validate() error {
+	var errs []error
+	if in.Name == "" {
+		errs = append(errs, errors.New("a name is required"))
+	} else if utf8.RuneCountInString(in.Name) > personNameMaxLength {
+		errs = append(errs, fmt.Errorf("the name can be at most %d characters", personNameMaxLength))
+	}
+	if utf8.RuneCountInString(in.Notes) > personNotesMaxLength {
+		errs = append(errs, fmt.Errorf("the notes can be at most %d characters", personNotesMaxLength))
+	}
+	return errors.Join(errs...)
+}
+
+// AlbumPersonDTO records an album passing between the user and a person:
+// who introduced it to them, or who they shared it with, and when.
+type AlbumPersonDTO struct {
+	ID         string
+	AlbumID    string
+	PersonID   string
+	PersonName string
+	Direction  models.AlbumPersonDirection
+	SharedAt   time.Time
+}
+
+func newAlbumPersonDTOFromModel(model sqlc.AlbumPerson, personName string) AlbumPersonDTO {
+	return AlbumPersonDTO{
+		ID:         model.ID,
+		AlbumID:    model.AlbumID,
+		PersonID:   model.PersonID,
+		PersonName: personName,
+		Direction:  model.Direction,
+		SharedAt:   model.SharedAt,
+	}
+}
+
+func (ap AlbumPersonDTO) Label() string {
+	return DirectionLabel(ap.Direction)
+}
+
+// AlbumPersonInput adds a person to an album by name, creating them when the
+// user hasn't named them before.
+type AlbumPersonInput struct {
+	AlbumID    string
+	PersonName string
+	Direction  models.AlbumPersonDirection
+	// SharedAt defaults to now when zero.
+	SharedAt time.Time
+}
+
+func (in AlbumPersonInput) Normalize() AlbumPersonInput {
+	in.AlbumID = strings.TrimSpace(in.AlbumID)
+	in.PersonName = strings.TrimSpace(in.PersonName)
+	return in
+}
+
+func (in AlbumPersonInput) Validate() error {
+	var errs []error
+	if in.AlbumID == "" {
+		errs = append(errs, errors.New("an album is required"))
+	}
+	if err := (PersonInput{Name: in.PersonName}).Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if !slices.Contains(Directions, in.Direction) {
+		errs = append(errs, fmt.Errorf("unknown direction %q", in.Direction))
+	}
+	return errors.Join(errs...)
+}
+
+// RecommendationStatsDTO sums up how the albums a person introduced the user
+// to have been rated.
+type RecommendationStatsDTO struct {
+	Person          PersonDTO
+	IntroducedCount int
+	RatedCount      int
+	SharedCount     int
+	// AverageRating is the mean of the latest ratings of the albums they
+	// introduced, on the canonical scale; nil when none are rated.
+	AverageRating *float64
+}
@@ -0,0 +1,65 @@
+package people
+
+import (
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"strings"
+	"testing"
+)
+
+func TestPersonInput_Validate(t *testing.T) {
+	cases := []struct {
+		name  string
+		input PersonInput
+		ok    bool
+	}{
+		{"name only", PersonInput{Name: "Jamie"}, true},
+		{"with notes", PersonInput{Name: "Jamie", Notes: "record store coworker"}, true},
+		{"blank name", PersonInput{Name: "   "}, false},
+		{"name too long", PersonInput{Name: strings.Repeat("a", 101)}, false},
+		{"notes too long", PersonInput{Name: "Jamie", Notes: strings.Repeat("a", 1001)}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.input.Normalize().Validate()
+			if c.ok && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !c.ok && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestAlbumPersonInput_Validate(t *testing.T) {
+	cases := []struct {
+		name  string
+		input AlbumPersonInput
+		ok    bool
+	}{
+		{"introduced by", AlbumPersonInput{AlbumID: "a", PersonName: "Jamie", Direction: models.AlbumPersonDirectionIntroducedBy}, true},
+		{"shared with", AlbumPersonInput{AlbumID: "a", PersonName: "Jamie", Direction: models.AlbumPersonDirectionSharedWith}, true},
+		{"missing album", AlbumPersonInput{PersonName: "Jamie", Direction: models.AlbumPersonDirectionIntroducedBy}, false},
+		{"blank name", AlbumPersonInput{AlbumID: "a", PersonName: " ", Direction: models.AlbumPersonDirectionIntroducedBy}, false},
+		{"unknown direction", AlbumPersonInput{AlbumID: "a", PersonName: "Jamie", Direction: "borrowed_from"}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.input.Normalize().Validate()
+			if c.ok && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !c.ok && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestDirectionLabel(t *testing.T) {
+	for _, direction := range Directions {
+		if DirectionLabel(direction) == string(direction) {
+			t.Errorf("direction %q has no label", direction)
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package people
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/alecdray/wax/src/internal/core/db"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"github.com/alecdray/wax/src/internal/core/db/sqlc"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Service struct {
+	db *db.DB
+}
+
+func NewService(db *db.DB) *Service {
+	return &Service{db: db}
+}
+
+func getPerson(ctx context.Context, tx *db.DB, userId, personId string) (PersonDTO, error) {
+	model, err := tx.Queries().GetPerson(ctx, sqlc.GetPersonParams{
+		ID:     personId,
+		UserID: userId,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return PersonDTO{}, ErrPersonNotFound
+	} else if err != nil {
+		return PersonDTO{}, fmt.Errorf("failed to get person: %w", err)
+	}
+	return newPersonDTOFromModel(model), nil
+}
+
+// getPersonByName finds one of the user's people by name, ignoring case. It
+// returns nil when nobody has the name.
+func getPersonByName(ctx context.Context, tx *db.DB, userId, name string) (*PersonDTO, error) {
+	model, err := tx.Queries().GetPersonByName(ctx, sqlc.GetPersonByNameParams{
+		UserID: userId,
+		Name:   name,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get person: %w", err)
+	}
+	person := newPersonDTOFromModel(model)
+	return &person, nil
+}
+
+// GetUserPeople returns the user's people by name.
+func (s *Service) GetUserPeople(ctx context.Context, userId string) ([]PersonDTO, error) {
+	models, err := s.db.Queries().GetPeopleByUserId(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get people: %w", err)
+	}
+	dtos := make([]PersonDTO, 0, len(models))
+	for _, model := range models {
+		dtos = append(dtos, newPersonDTOFromModel(model))
+	}
+	return dtos, nil
+}
+
+func (s *Service) GetPerson(ctx context.Context, userId, personId string) (PersonDTO, error) {
+	return getPerson(ctx, s.db, userId, personId)
+}
+
+func (s *Service) CreatePerson(ctx context.Context, userId string, input PersonInput) (PersonDTO, error) {
+	input = input.Normalize()
+	err := input.Validate()
+	if err != nil {
+		return PersonDTO{}, fmt.Errorf("%w: %w", ErrInvalidPerson, err)
+	}
+
+	var person PersonDTO
+	err = s.db.WithTx(func(tx *db.DB) error {
+		existing, err := getPersonByName(ctx, tx, userId, input.Name)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return ErrPersonNameTaken
+		}
+		person, err = createPerson(ctx, tx, userId, input)
+		return err
+	})
+	if err != nil {
+		return PersonDTO{}, err
+	}
+	return person, nil
+}
+
+func createPerson(ctx context.Context, tx *db.DB, userId string, input PersonInput) (PersonDTO, error) {
+	model, err := tx.Queries().CreatePerson(ctx, sqlc.CreatePersonParams{
+		ID:     uuid.NewString(),
+		UserID: userId,
+		Name:   input.Name,
+		Notes:  input.Notes,
+	})
+	if err != nil {
+		return PersonDTO{}, fmt.Errorf("failed to create person: %w", err)
+	}
+	return newPersonDTOFromModel(model), nil
+}
+
+// UpdatePerson renames a person and replaces their notes.
+func (s *Service) UpdatePerson(ctx context.Context, userId, personId string, input PersonInput) (PersonDTO, error) {
+	input = input.Normalize()
+	err := input.Validate()
+	if err != nil {
+		return PersonDTO{}, fmt.Errorf("%w: %w", ErrInvalidPerson, err)
+	}
+
+	var person PersonDTO
+	err = s.db.WithTx(func(tx *db.DB) error {
+		_, err := getPerson(ctx, tx, userId, personId)
+		if err != nil {
+			return err
+		}
+		existing, err := getPersonByName(ctx, tx, userId, input.Name)
+		if err != nil {
+			return err
+		}
+		if existing != nil && existing.ID != personId {
+			return ErrPersonNameTaken
+		}
+		err = tx.Queries().UpdatePerson(ctx, sqlc.UpdatePersonParams{
+			Name:   input.Name,
+			Notes:  input.Notes,
+			ID:     personId,
+			UserID: userId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update person: %w", err)
+		}
+		person, err = getPerson(ctx, tx, userId, personId)
+		return err
+	})
+	if err != nil {
+		return PersonDTO{}, err
+	}
+	return person, nil
+}
+
+// DeletePerson removes a person along with every album they're recorded on.
+func (s *Service) DeletePerson(ctx context.Context, userId, personId string) error {
+	return s.db.WithTx(func(tx *db.DB) error {
+		_, err := getPerson(ctx, tx, userId, personId)
+		if err != nil {
+			return err
+		}
+		err = tx.Queries().DeleteAlbumPeopleByPersonId(ctx, sqlc.DeleteAlbumPeopleByPersonIdParams{
+			PersonID: personId,
+			UserID:   userId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete album people: %w", err)
+		}
+		err = tx.Queries().DeletePerson(ctx, sqlc.DeletePersonParams{
+			ID:     personId,
+			UserID: userId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete person: %w", err)
+		}
+		return nil
+	})
+}
+
+// AddAlbumPerson records who introduced an album to the user or who they
+// shared it with. A name the user hasn't used before adds a new person.
+func (s *Service) AddAlbumPerson(ctx context.Context, userId string, input AlbumPersonInput) (AlbumPersonDTO, error) {
+	input = input.Normalize()
+	err := input.Validate()
+	if err != nil {
+		return AlbumPersonDTO{}, fmt.Errorf("%w: %w", ErrInvalidPerson, err)
+	}
+	if input.SharedAt.IsZero() {
+		input.SharedAt = time.Now().UTC()
+	}
+
+	var albumPerson AlbumPersonDTO
+	err = s.db.WithTx(func(tx *db.DB) error {
+		_, err := tx.Queries().GetAlbum(ctx, input.AlbumID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAlbumNotFound
+		} else if err != nil {
+			return fmt.Errorf("failed to get album: %w", err)
+		}
+
+		person, err := getPersonByName(ctx, tx, userId, input.PersonName)
+		if err != nil {
+			return err
+		}
+		if person == nil {
+			created, err := createPerson(ctx, tx, userId, PersonInput{Name: input.PersonName})
+			if err != nil {
+				return err
+			}
+			person = &created
+		}
+
+		_, err = tx.Queries().GetAlbumPersonByEnds(ctx, sqlc.GetAlbumPersonByEndsParams{
+			UserID:    userId,
+			AlbumID:   input.AlbumID,
+			PersonID:  person.ID,
+			Direction: input.Direction,
+		})
+		if err == nil {
+			return ErrAlbumPersonExists
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get album person: %w", err)
+		}
+
+		model, err := tx.Queries().CreateAlbumPerson(ctx, sqlc.CreateAlbumPersonParams{
+			ID:        uuid.NewString(),
+			UserID:    userId,
+			AlbumID:   input.AlbumID,
+			PersonID:  person.ID,
+			Direction: input.Direction,
+			SharedAt:  input.SharedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create album person: %w", err)
+		}
+		albumPerson = newAlbumPersonDTOFromModel(model, person.Name)
+		return nil
+	})
+	if err != nil {
+		return AlbumPersonDTO{}, err
+	}
+	return albumPerson, nil
+}
+
+func (s *Service) RemoveAlbumPerson(ctx context.Context, userId, albumPersonId string) error {
+	return s.db.WithTx(func(tx *db.DB) error {
+		_, err := tx.Queries().GetAlbumPerson(ctx, sqlc.GetAlbumPersonParams{
+			ID:     albumPersonId,
+			UserID: userId,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAlbumPersonNotFound
+		} else if err != nil {
+			return fmt.Errorf("failed to get album person: %w", err)
+		}
+		err = tx.Queries().DeleteAlbumPerson(ctx, sqlc.DeleteAlbumPersonParams{
+			ID:     albumPersonId,
+			UserID: userId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete album person: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetAlbumPeople returns who introduced an album to the user and who they
+// shared it with, oldest first.
+func (s *Service) GetAlbumPeople(ctx context.Context, userId, albumId string) ([]AlbumPersonDTO, error) {
+	rows, err := s.db.Queries().GetAlbumPeopleByAlbumId(ctx, sqlc.GetAlbumPeopleByAlbumIdParams{
+		UserID:  userId,
+		AlbumID: albumId,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get album people: %w", err)
+	}
+	dtos := make([]AlbumPersonDTO, 0, len(rows))
+	for _, row := range rows {
+		dtos = append(dtos, newAlbumPersonDTOFromModel(row.AlbumPerson, row.PersonName))
+	}
+	return dtos, nil
+}
+
+// GetIntroducerIdsByAlbumIds returns a map of albumId → the people who
+// introduced it to the user, for bulk fetching.
+func (s *Service) GetIntroducerIdsByAlbumIds(ctx context.Context, userId string, albumIds []string) (map[string][]string, error) {
+	if len(albumIds) == 0 {
+		return map[string][]string{}, nil
+	}
+	rows, err := s.db.Queries().GetAlbumPeopleByAlbumIds(ctx, sqlc.GetAlbumPeopleByAlbumIdsParams{
+		UserID:   userId,
+		AlbumIds: albumIds,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get album people: %w", err)
+	}
+	result := make(map[string][]string, len(albumIds))
+	for _, row := range rows {
+		if row.Direction == models.AlbumPersonDirectionIntroducedBy {
+			result[row.AlbumID] = append(result[row.AlbumID], row.PersonID)
+		}
+	}
+	return result, nil
+}
+
+// GetRecommendationStats returns each of the user's people with how the
+// albums they introduced have been rated, best average first.
+func (s *Service) GetRecommendationStats(ctx context.Context, userId string) ([]RecommendationStatsDTO, error) {
+	rows, err := s.db.Queries().GetPersonRecommendationStats(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recommendation stats: %w", err)
+	}
+	dtos := make([]RecommendationStatsDTO, 0, len(rows))
+	for _, row := range rows {
+		dto := RecommendationStatsDTO{
+			Person:          newPersonDTOFromModel(row.Person),
+			IntroducedCount: int(row.IntroducedCount),
+			RatedCount:      int(row.RatedCount),
+			SharedCount:     int(row.SharedCount),
+		}
+		if row.AverageRating.Valid {
+			dto.AverageRating = &row.AverageRating.Float64
+		}
+		dtos = append(dtos, dto)
+	}
+	return dtos, nil
+}
@@ -0,0 +1,129 @@
+package people
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alecdray/wax/src/internal/core/db"
+	"github.com/alecdray/wax/src/internal/core/db/dbtest"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+)
+
+func newTestService(t *testing.T) (*Service, *db.DB) {
+	database := dbtest.New(t)
+	return NewService(database), database
+}
+
+func TestGetRecommendationStats_AveragesLatestRatingsOfIntroducedAlbums(t *testing.T) {
+	service, database := newTestService(t)
+	ctx := context.Background()
+
+	dbtest.Exec(t, database, "INSERT INTO users (id, spotify_id) VALUES ('u1', 'u1'), ('u2', 'u2')")
+	for _, albumId := range []string{"a1", "a2", "a3", "a4"} {
+		dbtest.Exec(t, database, "INSERT INTO albums (id, spotify_id, title) VALUES (?, ?, ?)", albumId, albumId, albumId)
+	}
+
+	link := func(userId, albumId, name string, direction models.AlbumPersonDirection) {
+		t.Helper()
+		_, err := service.AddAlbumPerson(ctx, userId, AlbumPersonInput{AlbumID: albumId, PersonName: name, Direction: direction})
+		if err != nil {
+			t.Fatalf("failed to add %s to %s: %v", name, albumId, err)
+		}
+	}
+	link("u1", "a1", "Jamie", models.AlbumPersonDirectionIntroducedBy)
+	link("u1", "a2", "jamie", models.AlbumPersonDirectionIntroducedBy)
+	link("u1", "a3", "Jamie", models.AlbumPersonDirectionIntroducedBy)
+	link("u1", "a1", "Jamie", models.AlbumPersonDirectionSharedWith)
+	link("u1", "a4", "Sam", models.AlbumPersonDirectionIntroducedBy)
+	link("u1", "a2", "Alex", models.AlbumPersonDirectionSharedWith)
+	link("u2", "a3", "Jamie", models.AlbumPersonDirectionIntroducedBy)
+
+	// a1 was rated 8 after 6, though the 6 was logged last. a3 is unrated,
+	// and u2's ratings aren't u1's.
+	dbtest.Exec(t, database, `INSERT INTO album_rating_log (id, user_id, album_id, rating, created_at) VALUES
+		('r1', 'u1', 'a1', 8, '2026-02-01 12:00:00'),
+		('r2', 'u1', 'a1', 6, '2026-01-01 12:00:00'),
+		('r3', 'u1', 'a2', 5, '2026-01-01 12:00:00'),
+		('r4', 'u1', 'a4', 9, '2026-01-01 12:00:00'),
+		('r5', 'u2', 'a2', 1, '2026-03-01 12:00:00'),
+		('r6', 'u2', 'a3', 10, '2026-03-01 12:00:00')`)
+
+	stats, err := service.GetRecommendationStats(ctx, "u1")
+	if err != nil {
+		t.Fatalf("failed to get recommendation stats: %v", err)
+	}
+	if len(stats) != 3 {
+		t.Fatalf("expected Sam, Jamie and Alex, got %+v", stats)
+	}
+
+	sam, jamie, alex := stats[0], stats[1], stats[2]
+	if sam.Person.Name != "Sam" || sam.IntroducedCount != 1 || sam.RatedCount != 1 || sam.AverageRating == nil || *sam.AverageRating != 9 {
+		t.Errorf("expected Sam first with one album rated 9, got %+v", sam)
+	}
+	if jamie.Person.Name != "Jamie" || jamie.IntroducedCount != 3 || jamie.RatedCount != 2 || jamie.SharedCount != 1 {
+		t.Errorf("expected Jamie to have introduced 3, rated 2 and shared 1, got %+v", jamie)
+	}
+	if jamie.AverageRating == nil || *jamie.AverageRating != 6.5 {
+		t.Errorf("expected Jamie's average of the latest ratings to be 6.5, got %v", jamie.AverageRating)
+	}
+	if alex.Person.Name != "Alex" || alex.IntroducedCount != 0 || alex.SharedCount != 1 || alex.AverageRating != nil {
+		t.Errorf("expected Alex last with nothing introduced, got %+v", alex)
+	}
+
+	// Taking Jamie off an album drops it from their stats.
+	albumPeople, err := service.GetAlbumPeople(ctx, "u1", "a2")
+	if err != nil {
+		t.Fatalf("failed to get album people: %v", err)
+	}
+	for _, albumPerson := range albumPeople {
+		if albumPerson.PersonID == jamie.Person.ID {
+			if err := service.RemoveAlbumPerson(ctx, "u1", albumPerson.ID); err != nil {
+				t.Fatalf("failed to remove album person: %v", err)
+			}
+		}
+	}
+	stats, err = service.GetRecommendationStats(ctx, "u1")
+	if err != nil {
+		t.Fatalf("failed to get recommendation stats: %v", err)
+	}
+	if jamie := stats[1]; jamie.IntroducedCount != 2 || jamie.AverageRating == nil || *jamie.AverageRating != 8 {
+		t.Errorf("expected Jamie left with a1 rated 8 and unrated a3, got %+v", jamie)
+	}
+}
+
+func TestAddAlbumPerson_ReusesPeopleByName(t *testing.T) {
+	service, database := newTestService(t)
+	ctx := context.Background()
+
+	dbtest.Exec(t, database, "INSERT INTO users (id, spotify_id) VALUES ('u1', 'u1')")
+	dbtest.Exec(t, database, "INSERT INTO albums (id, spotify_id, title) VALUES ('a1', 'a1', 'a1')")
+
+	introduced, err := service.AddAlbumPerson(ctx, "u1", AlbumPersonInput{AlbumID: "a1", PersonName: " Jamie ", Direction: models.AlbumPersonDirectionIntroducedBy})
+	if err != nil {
+		t.Fatalf("failed to add album person: %v", err)
+	}
+	shared, err := service.AddAlbumPerson(ctx, "u1", AlbumPersonInput{AlbumID: "a1", PersonName: "JAMIE", Direction: models.AlbumPersonDirectionSharedWith})
+	if err != nil {
+		t.Fatalf("failed to add album person: %v", err)
+	}
+	if shared.PersonID != introduced.PersonID || shared.PersonName != "Jamie" {
+		t.Errorf("expected the same person reused, got %+v and %+v", introduced, shared)
+	}
+	if people, err := service.GetUserPeople(ctx, "u1"); err != nil || len(people) != 1 {
+		t.Errorf("expected one person, got %+v, %v", people, err)
+	}
+
+	_, err = service.AddAlbumPerson(ctx, "u1", AlbumPersonInput{AlbumID: "a1", PersonName: "jamie", Direction: models.AlbumPersonDirectionIntroducedBy})
+	if !errors.Is(err, ErrAlbumPersonExists) {
+		t.Errorf("expected a repeat to be rejected, got %v", err)
+	}
+	_, err = service.AddAlbumPerson(ctx, "u1", AlbumPersonInput{AlbumID: "a1", PersonName: "Sam", Direction: "borrowed_from"})
+	if !errors.Is(err, ErrInvalidPerson) {
+		t.Errorf("expected an unknown direction to be rejected, got %v", err)
+	}
+	_, err = service.AddAlbumPerson(ctx, "u1", AlbumPersonInput{AlbumID: "missing", PersonName: "Sam", Direction: models.AlbumPersonDirectionSharedWith})
+	if !errors.Is(err, ErrAlbumNotFound) {
+		t.Errorf("expected a missing album to be rejected, got %v", err)
+	}
+}
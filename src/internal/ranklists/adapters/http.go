@@ -0,0 +1,459 @@
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"github.com/alecdray/wax/src/internal/core/contextx"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"github.com/alecdray/wax/src/internal/core/httpx"
+	"github.com/alecdray/wax/src/internal/core/templates"
+	"github.com/alecdray/wax/src/internal/ranklists"
+	"github.com/alecdray/wax/src/internal/review"
+	"github.com/alecdray/wax/src/internal/tags"
+	"strconv"
+	"strings"
+)
+
+type HttpHandler struct {
+	ranklistsService *ranklists.Service
+	tagsService      *tags.Service
+}
+
+func NewHttpHandler(ranklistsService *ranklists.Service, tagsService *tags.Service) *HttpHandler {
+	return &HttpHandler{
+		ranklistsService: ranklistsService,
+		tagsService:      tagsService,
+	}
+}
+
+// handleRanklistError maps service errors to a status, showing the user why
+// a change was refused where they can fix it.
+func handleRanklistError(ctx contextx.ContextX, w http.ResponseWriter, err error) {
+	props := httpx.HandleErrorResponseProps{
+		Status: http.StatusInternalServerError,
+		Err:    err,
+	}
+	switch {
+	case errors.Is(err, ranklists.ErrRanklistNotFound), errors.Is(err, ranklists.ErrEntryNotFound):
+		props.Status = http.StatusNotFound
+	case errors.Is(err, ranklists.ErrInvalidRanklist), errors.Is(err, ranklists.ErrAlbumNotInLibrary):
+		props.Status = http.StatusUnprocessableEntity
+		props.Response = *httpx.NewErrorResponse().SetComponent(RanklistError(err.Error()))
+	case errors.Is(err, ranklists.ErrAutoRanklist), errors.Is(err, ranklists.ErrAlbumAlreadyRanked):
+		props.Status = http.StatusConflict
+		props.Response = *httpx.NewErrorResponse().SetComponent(RanklistError(err.Error()))
+	}
+	httpx.HandleErrorResponse(ctx, w, props)
+}
+
+// parseRanklistForm reads a new list's fields. Rating bounds are given on the
+// user's scale and default to its ends.
+func parseRanklistForm(form url.Values, profile review.RatingProfile) (ranklists.RanklistInput, error) {
+	input := ranklists.RanklistInput{
+		Name:        form.Get("name"),
+		Description: form.Get("description"),
+		Source:      models.RanklistSource(form.Get("source")),
+		TagID:       form.Get("tagId"),
+		MinRating:   profile.FromScale(profile.ScaleMin),
+		MaxRating:   profile.FromScale(profile.ScaleMax),
+	}
+	if input.Source != models.RanklistSourceRating {
+		return input, nil
+	}
+
+	bounds := []struct {
+		name  string
+		label string
+		dest  *float64
+	}{
+		{"minRating", "lowest rating", &input.MinRating},
+		{"maxRating", "highest rating", &input.MaxRating},
+	}
+	for _, bound := range bounds {
+		raw := strings.TrimSpace(form.Get(bound.name))
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return ranklists.RanklistInput{}, fmt.Errorf("%w: the %s must be a number", ranklists.ErrInvalidRanklist, bound.label)
+		}
+		if value < profile.ScaleMin || value > profile.ScaleMax {
+			return ranklists.RanklistInput{}, fmt.Errorf("%w: the %s must fall within your scale", ranklists.ErrInvalidRanklist, bound.label)
+		}
+		*bound.dest = profile.FromScale(value)
+	}
+	return input, nil
+}
+
+// parseRank reads an optional 1-based rank, returning 0 when it's blank.
+func parseRank(raw string) (int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+	rank, err := strconv.Atoi(raw)
+	if err != nil || rank < 1 {
+		return 0, fmt.Errorf("%w: the position must be a whole number from 1", ranklists.ErrInvalidRanklist)
+	}
+	return rank, nil
+}
+
+func (h *HttpHandler) GetRanklistsPage(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	lists, err := h.ranklistsService.GetUserRanklists(ctx, userId)
+	if err != nil {
+		handleRanklistError(ctx, w, err)
+		return
+	}
+
+	userTags, err := h.tagsService.GetUserTags(ctx, userId)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to get user tags: %w", err),
+		})
+		return
+	}
+
+	err = RanklistsPage(lists, userTags).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) CreateRanklist(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	input, err := parseRanklistForm(r.Form, review.RatingProfileFromContext(ctx))
+	if err != nil {
+		handleRanklistError(ctx, w, err)
+		return
+	}
+
+	ranklist, err := h.ranklistsService.CreateRanklist(ctx, userId, input)
+	if err != nil {
+		handleRanklistError(ctx, w, err)
+		return
+	}
+
+	err = templates.Redirect(fmt.Sprintf("/app/ranklists/%s", ranklist.ID), 0).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) GetRanklistPage(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	ranklist, entries, err := h.ranklistsService.GetRanklist(ctx, userId, r.PathValue("ranklistId"))
+	if err != nil {
+		handleRanklistError(ctx, w, err)
+		return
+	}
+
+	var candidates []ranklists.CandidateAlbumDTO
+	if !ranklist.IsAuto() {
+		candidates, err = h.ranklistsService.GetCandidateAlbums(ctx, userId, ranklist.ID)
+		if err != nil {
+			handleRanklistError(ctx, w, err)
+			return
+		}
+	}
+
+	err = RanklistPage(ranklist, entries, candidates).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) UpdateRanklist(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	ranklistId := r.PathValue("ranklistId")
+	err = h.ranklistsService.UpdateRanklist(ctx, userId, ranklistId, r.Form.Get("name"), r.Form.Get("description"))
+	if err != nil {
+		handleRanklistError(ctx, w, err)
+		return
+	}
+
+	ranklist, _, err := h.ranklistsService.GetRanklist(ctx, userId, ranklistId)
+	if err != nil {
+		handleRanklistError(ctx, w, err)
+		return
+	}
+
+	err = RanklistHeader(ranklist).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) DeleteRanklist(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	err = h.ranklistsService.DeleteRanklist(ctx, userId, r.PathValue("ranklistId"))
+	if err != nil {
+		handleRanklistError(ctx, w, err)
+		return
+	}
+
+	err = templates.Redirect("/app/ranklists", 0).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+// renderRanklistEntries re-renders a list's entries after a change, with the
+// albums that can still be added to it.
+func (h *HttpHandler) renderRanklistEntries(ctx contextx.ContextX, w http.ResponseWriter, userId, ranklistId string) {
+	ranklist, entries, err := h.ranklistsService.GetRanklist(ctx, userId, ranklistId)
+	if err != nil {
+		handleRanklistError(ctx, w, err)
+		return
+	}
+
+	var candidates []ranklists.CandidateAlbumDTO
+	if !ranklist.IsAuto() {
+		candidates, err = h.ranklistsService.GetCandidateAlbums(ctx, userId, ranklist.ID)
+		if err != nil {
+			handleRanklistError(ctx, w, err)
+			return
+		}
+	}
+
+	err = RanklistEntries(ranklist, entries, candidates).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) AddRanklistEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	albumId := r.Form.Get("albumId")
+	if albumId == "" {
+		handleRanklistError(ctx, w, fmt.Errorf("%w: pick an album to add", ranklists.ErrInvalidRanklist))
+		return
+	}
+	rank, err := parseRank(r.Form.Get("rank"))
+	if err != nil {
+		handleRanklistError(ctx, w, err)
+		return
+	}
+
+	ranklistId := r.PathValue("ranklistId")
+	err = h.ranklistsService.AddAlbum(ctx, userId, ranklistId, albumId, rank)
+	if err != nil {
+		handleRanklistError(ctx, w, err)
+		return
+	}
+
+	h.renderRanklistEntries(ctx, w, userId, ranklistId)
+}
+
+// MoveRanklistEntry reorders an entry, either between the neighbours it was
+// dragged to or to a typed-in rank.
+func (h *HttpHandler) MoveRanklistEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	ranklistId := r.PathValue("ranklistId")
+	entryId := r.PathValue("entryId")
+	if r.Form.Has("rank") {
+		var rank int
+		rank, err = parseRank(r.Form.Get("rank"))
+		if err == nil && rank == 0 {
+			err = fmt.Errorf("%w: a position is required", ranklists.ErrInvalidRanklist)
+		}
+		if err == nil {
+			err = h.ranklistsService.MoveEntryToRank(ctx, userId, ranklistId, entryId, rank)
+		}
+	} else {
+		err = h.ranklistsService.MoveEntry(ctx, userId, ranklistId, entryId, r.Form.Get("beforeId"), r.Form.Get("afterId"))
+	}
+	if err != nil {
+		handleRanklistError(ctx, w, err)
+		return
+	}
+
+	h.renderRanklistEntries(ctx, w, userId, ranklistId)
+}
+
+func (h *HttpHandler) SetRanklistEntryBlurb(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	ranklistId := r.PathValue("ranklistId")
+	err = h.ranklistsService.SetEntryBlurb(ctx, userId, ranklistId, r.PathValue("entryId"), r.Form.Get("blurb"))
+	if err != nil {
+		handleRanklistError(ctx, w, err)
+		return
+	}
+
+	h.renderRanklistEntries(ctx, w, userId, ranklistId)
+}
+
+func (h *HttpHandler) RemoveRanklistEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	ranklistId := r.PathValue("ranklistId")
+	err = h.ranklistsService.RemoveEntry(ctx, userId, ranklistId, r.PathValue("entryId"))
+	if err != nil {
+		handleRanklistError(ctx, w, err)
+		return
+	}
+
+	h.renderRanklistEntries(ctx, w, userId, ranklistId)
+}
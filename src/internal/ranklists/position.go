@@ -0,0 +1,150 @@
+package ranklists
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// positionDigits are the base-62 digits positions are written in. They're in
+// ASCII order, so positions sort correctly as plain strings in SQL.
+const positionDigits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+var ErrInvalidPosition = errors.New("invalid position")
+
+// Positions are fractional indexes: base-62 fractions between 0 and 1 that
+// never end in a zero digit, so there is always room for another position
+// before, after or between any two. Moving an entry rewrites only that
+// entry's position, which keeps concurrent moves of different entries from
+// clobbering each other.
+
+func validatePosition(position string) error {
+	if position == "" {
+		return nil
+	}
+	if strings.HasSuffix(position, positionDigits[:1]) {
+		return fmt.Errorf("%w: %q ends in zero", ErrInvalidPosition, position)
+	}
+	for _, r := range position {
+		if !strings.ContainsRune(positionDigits, r) {
+			return fmt.Errorf("%w: %q contains %q", ErrInvalidPosition, position, r)
+		}
+	}
+	return nil
+}
+
+// PositionBetween returns a position that sorts after before and ahead of
+// after. An empty before means the start of the list and an empty after the
+// end.
+func PositionBetween(before, after string) (string, error) {
+	if err := validatePosition(before); err != nil {
+		return "", err
+	}
+	if err := validatePosition(after); err != nil {
+		return "", err
+	}
+	if after != "" && before >= after {
+		return "", fmt.Errorf("%w: %q is not before %q", ErrInvalidPosition, before, after)
+	}
+	if after == "" && before != "" {
+		return positionAfter(before), nil
+	}
+	return positionMidpoint(before, after), nil
+}
+
+// PositionsAfter returns n ascending positions after before, spaced evenly
+// so a large list can be seeded without positions growing long.
+func PositionsAfter(before string, n int) ([]string, error) {
+	if err := validatePosition(before); err != nil {
+		return nil, err
+	}
+	positions := make([]string, 0, n)
+	if n == 0 {
+		return positions, nil
+	}
+
+	base := len(positionDigits)
+	width, capacity := 1, base
+	for capacity <= n {
+		width++
+		capacity *= base
+	}
+	step := capacity / (n + 1)
+
+	// The evenly spaced keys sit after before by sharing its prefix, then
+	// stepping to the next digit at that prefix's depth.
+	prefix := positionAfter(before)
+	if before == "" {
+		prefix = ""
+	}
+	for i := 1; i <= n; i++ {
+		digits := make([]byte, width)
+		value := i * step
+		for j := width - 1; j >= 0; j-- {
+			digits[j] = positionDigits[value%base]
+			value /= base
+		}
+		positions = append(positions, prefix+strings.TrimRight(string(digits), positionDigits[:1]))
+	}
+	return positions, nil
+}
+
+// positionAfter steps the first digit that isn't already the largest, so
+// appending to a list grows positions by one digit every 61 appends.
+func positionAfter(position string) string {
+	for i := 0; i < len(position); i++ {
+		if digit := strings.IndexByte(positionDigits, position[i]); digit < len(positionDigits)-1 {
+			return position[:i] + string(positionDigits[digit+1])
+		}
+	}
+	return position + positionDigits[1:2]
+}
+
+// positionMidpoint returns a position roughly halfway between a and b, where
+// an empty b stands for the end of the list.
+func positionMidpoint(a, b string) string {
+	if b != "" {
+		// Carry over the shared prefix, padding a with zeros.
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(a) {
+				rest = a[n:]
+			}
+			return b[:n] + positionMidpoint(rest, b[n:])
+		}
+	}
+
+	digitA := 0
+	if a != "" {
+		digitA = strings.IndexByte(positionDigits, a[0])
+	}
+	digitB := len(positionDigits)
+	if b != "" {
+		digitB = strings.IndexByte(positionDigits, b[0])
+	}
+	if digitB-digitA > 1 {
+		return string(positionDigits[(digitA+digitB+1)/2])
+	}
+
+	// The first digits are adjacent. If b has more digits, its first digit on
+	// its own already sits between the two.
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+	return string(positionDigits[digitA]) + positionMidpoint(rest, "")
+}
+
+func digitAt(position string, i int) byte {
+	if i < len(position) {
+		return position[i]
+	}
+	return positionDigits[0]
+}
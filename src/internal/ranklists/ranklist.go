@@ -0,0 +1,182 @@
+package ranklists
+
+import (
+	"errors"
+	"fmt"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"github.com/alecdray/wax/src/internal/core/db/sqlc"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	ranklistNameMaxLength        = 100
+	ranklistDescriptionMaxLength = 1000
+	ranklistBlurbMaxLength       = 500
+)
+
+var (
+	ErrInvalidRanklist    = errors.New("invalid ranklist")
+	ErrRanklistNotFound   = errors.New("ranklist not found")
+	ErrEntryNotFound      = errors.New("ranklist entry not found")
+	ErrAlbumAlreadyRanked = errors.New("album is already on this ranklist")
+	ErrAlbumNotInLibrary  = errors.New("album is not in your library")
+	// ErrAutoRanklist is returned when adding or removing albums by hand on a
+	// list whose albums come from a tag or rating filter.
+	ErrAutoRanklist = errors.New("albums on an automatic ranklist follow its filter")
+)
+
+type RanklistDTO struct {
+	ID          string
+	Name        string
+	Description string
+	Source      models.RanklistSource
+	TagID       string
+	TagName     string
+	// MinRating and MaxRating bound a rating list on the canonical scale.
+	MinRating  *float64
+	MaxRating  *float64
+	EntryCount int
+	UpdatedAt  time.Time
+}
+
+func newRanklistDTOFromModel(model sqlc.Ranklist, tagName string, entryCount int64) RanklistDTO {
+	dto := RanklistDTO{
+		ID:          model.ID,
+		Name:        model.Name,
+		Description: model.Description,
+		Source:      model.Source,
+		TagID:       model.TagID.String,
+		TagName:     tagName,
+		EntryCount:  int(entryCount),
+		UpdatedAt:   model.UpdatedAt,
+	}
+	if model.MinRating.Valid {
+		dto.MinRating = &model.MinRating.Float64
+	}
+	if model.MaxRating.Valid {
+		dto.MaxRating = &model.MaxRating.Float64
+	}
+	return dto
+}
+
+// IsAuto reports whether the list's albums come from a tag or rating filter
+// rather than being added by hand.
+func (r RanklistDTO) IsAuto() bool {
+	return r.Source != models.RanklistSourceManual
+}
+
+type RanklistEntryDTO struct {
+	ID         string
+	AlbumID    string
+	AlbumTitle string
+	Artists    string
+	ImageURL   string
+	Blurb      string
+	Rating     *float64
+}
+
+type CandidateAlbumDTO struct {
+	ID      string
+	Title   string
+	Artists string
+}
+
+type RanklistInput struct {
+	Name        string
+	Description string
+	Source      models.RanklistSource
+	// TagID is the tag a tag list follows.
+	TagID string
+	// MinRating and MaxRating bound a rating list on the canonical scale.
+	MinRating float64
+	MaxRating float64
+}
+
+func (in RanklistInput) Normalize() RanklistInput {
+	in.Name = strings.TrimSpace(in.Name)
+	in.Description = strings.TrimSpace(in.Description)
+	if in.Source == "" {
+		in.Source = models.RanklistSourceManual
+	}
+	return in
+}
+
+func (in RanklistInput) Validate() error {
+	var errs []error
+	if in.Name == "" {
+		errs = append(errs, errors.New("a name is required"))
+	} else if utf8.RuneCountInString(in.Name) > ranklistNameMaxLength {
+		errs = append(errs, fmt.Errorf("the name can be at most %d characters", ranklistNameMaxLength))
+	}
+	if utf8.RuneCountInString(in.Description) > ranklistDescriptionMaxLength {
+		errs = append(errs, fmt.Errorf("the description can be at most %d characters", ranklistDescriptionMaxLength))
+	}
+	switch in.Source {
+	case models.RanklistSourceManual:
+	case models.RanklistSourceTag:
+		if in.TagID == "" {
+			errs = append(errs, errors.New("pick a tag to build the list from"))
+		}
+	case models.RanklistSourceRating:
+		if in.MinRating > in.MaxRating {
+			errs = append(errs, errors.New("the lowest rating can't be above the highest"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown list source %q", in.Source))
+	}
+	return errors.Join(errs...)
+}
+
+func validateBlurb(blurb string) error {
+	if utf8.RuneCountInString(blurb) > ranklistBlurbMaxLength {
+		return fmt.Errorf("%w: blurbs can be at most %d characters", ErrInvalidRanklist, ranklistBlurbMaxLength)
+	}
+	return nil
+}
+
+// positionAt returns a position for an entry landing at index among the
+// list's other entries, which must be in order.
+func positionAt(entries []sqlc.GetRanklistEntryPositionsRow, index int) (string, error) {
+	index = max(0, min(index, len(entries)))
+	before := ""
+	if index > 0 {
+		before = entries[index-1].Position
+	}
+	// Entries placed concurrently can share a position; land after all of
+	// them rather than between equals.
+	after := ""
+	for _, entry := range entries[index:] {
+		if entry.Position > before {
+			after = entry.Position
+			break
+		}
+	}
+	return PositionBetween(before, after)
+}
+
+// positionBetweenEntries places an entry dropped after beforeId and ahead of
+// afterId, as the client last saw the list. The entry is anchored to its new
+// predecessor when that's still on the list, so a drop made against a stale
+// view still lands next to the album it was dropped by.
+func positionBetweenEntries(entries []sqlc.GetRanklistEntryPositionsRow, beforeId, afterId string) (string, error) {
+	if beforeId != "" {
+		for i, entry := range entries {
+			if entry.ID == beforeId {
+				return positionAt(entries, i+1)
+			}
+		}
+	}
+	if afterId != "" {
+		for i, entry := range entries {
+			if entry.ID == afterId {
+				return positionAt(entries, i)
+			}
+		}
+	}
+	if beforeId != "" {
+		return positionAt(entries, len(entries))
+	}
+	return positionAt(entries, 0)
+}
@@ -0,0 +1,241 @@
+package ranklists
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"github.com/alecdray/wax/src/internal/core/db/sqlc"
+)
+
+func TestPositionBetween_Orders(t *testing.T) {
+	cases := []struct {
+		name          string
+		before, after string
+	}{
+		{"empty list", "", ""},
+		{"start of list", "", "V"},
+		{"end of list", "V", ""},
+		{"between neighbours", "V", "W"},
+		{"shared prefix", "V1", "V2"},
+		{"after the last digit", "z", ""},
+		{"ahead of the first digit", "", "1"},
+		{"ahead of a long position", "", "01"},
+		{"between a position and its extension", "V", "V1"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := PositionBetween(c.before, c.after)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got <= c.before || (c.after != "" && got >= c.after) {
+				t.Errorf("PositionBetween(%q, %q) = %q, not between", c.before, c.after, got)
+			}
+			if err := validatePosition(got); err != nil {
+				t.Errorf("PositionBetween(%q, %q) = %q: %v", c.before, c.after, got, err)
+			}
+		})
+	}
+}
+
+func TestPositionBetween_Invalid(t *testing.T) {
+	cases := []struct {
+		name          string
+		before, after string
+	}{
+		{"reversed", "W", "V"},
+		{"equal", "V", "V"},
+		{"trailing zero", "V0", ""},
+		{"bad character", "V-", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := PositionBetween(c.before, c.after)
+			if !errors.Is(err, ErrInvalidPosition) {
+				t.Errorf("expected ErrInvalidPosition, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPositionBetween_RepeatedInsertsStayOrdered(t *testing.T) {
+	// Inserting at random places keeps the list sorted and every position
+	// distinct.
+	rng := rand.New(rand.NewSource(1))
+	positions := []string{}
+	for i := 0; i < 500; i++ {
+		index := rng.Intn(len(positions) + 1)
+		before, after := "", ""
+		if index > 0 {
+			before = positions[index-1]
+		}
+		if index < len(positions) {
+			after = positions[index]
+		}
+		position, err := PositionBetween(before, after)
+		if err != nil {
+			t.Fatalf("insert %d: %v", i, err)
+		}
+		positions = append(positions[:index], append([]string{position}, positions[index:]...)...)
+	}
+	if !sort.StringsAreSorted(positions) {
+		t.Error("positions are out of order")
+	}
+	for i := 1; i < len(positions); i++ {
+		if positions[i] == positions[i-1] {
+			t.Fatalf("duplicate position %q", positions[i])
+		}
+	}
+}
+
+func TestPositionBetween_AppendingStaysShort(t *testing.T) {
+	position := ""
+	for i := 0; i < 500; i++ {
+		next, err := PositionBetween(position, "")
+		if err != nil {
+			t.Fatalf("append %d: %v", i, err)
+		}
+		position = next
+	}
+	if len(position) > 10 {
+		t.Errorf("position grew to %d characters after 500 appends", len(position))
+	}
+}
+
+func TestPositionsAfter(t *testing.T) {
+	for _, before := range []string{"", "V", "zz"} {
+		positions, err := PositionsAfter(before, 1000)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(positions) != 1000 {
+			t.Fatalf("got %d positions, want 1000", len(positions))
+		}
+		if !sort.StringsAreSorted(positions) {
+			t.Errorf("positions after %q are out of order", before)
+		}
+		for i, position := range positions {
+			if position <= before {
+				t.Fatalf("position %q is not after %q", position, before)
+			}
+			if i > 0 && position == positions[i-1] {
+				t.Fatalf("duplicate position %q", position)
+			}
+			if strings.HasSuffix(position, "0") {
+				t.Fatalf("position %q ends in zero", position)
+			}
+		}
+	}
+}
+
+func entryPositions(positions ...string) []sqlc.GetRanklistEntryPositionsRow {
+	entries := make([]sqlc.GetRanklistEntryPositionsRow, len(positions))
+	for i, position := range positions {
+		entries[i] = sqlc.GetRanklistEntryPositionsRow{
+			ID:       string(rune('a' + i)),
+			Position: position,
+		}
+	}
+	return entries
+}
+
+func TestPositionAt(t *testing.T) {
+	entries := entryPositions("A", "B", "C")
+	cases := []struct {
+		name          string
+		index         int
+		before, after string
+	}{
+		{"top", 0, "", "A"},
+		{"middle", 2, "B", "C"},
+		{"bottom", 3, "C", ""},
+		{"past the bottom", 10, "C", ""},
+		{"before the top", -2, "", "A"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := positionAt(entries, c.index)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got <= c.before || (c.after != "" && got >= c.after) {
+				t.Errorf("positionAt(%d) = %q, want between %q and %q", c.index, got, c.before, c.after)
+			}
+		})
+	}
+}
+
+func TestPositionAt_SkipsTiedPositions(t *testing.T) {
+	// Two entries placed between the same neighbours at once share a
+	// position; dropping after the first lands after both.
+	entries := entryPositions("A", "B", "B", "C")
+	got, err := positionAt(entries, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got <= "B" || got >= "C" {
+		t.Errorf("got %q, want between B and C", got)
+	}
+}
+
+func TestPositionBetweenEntries(t *testing.T) {
+	// Entries a, b, c at A, B, C.
+	entries := entryPositions("A", "B", "C")
+	cases := []struct {
+		name              string
+		beforeId, afterId string
+		low, high         string
+	}{
+		{"between two entries", "a", "b", "A", "B"},
+		{"to the top", "", "a", "", "A"},
+		{"to the bottom", "c", "", "C", ""},
+		// Another edit moved c between a and b since the client loaded the
+		// list; the drop still lands right after a.
+		{"stale neighbours", "a", "c", "A", "B"},
+		{"predecessor removed", "x", "b", "A", "B"},
+		{"both neighbours removed", "x", "y", "C", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := positionBetweenEntries(entries, c.beforeId, c.afterId)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got <= c.low || (c.high != "" && got >= c.high) {
+				t.Errorf("got %q, want between %q and %q", got, c.low, c.high)
+			}
+		})
+	}
+}
+
+func TestRanklistInput_Validate(t *testing.T) {
+	cases := []struct {
+		name  string
+		input RanklistInput
+		ok    bool
+	}{
+		{"manual", RanklistInput{Name: "Best of 2024"}, true},
+		{"missing name", RanklistInput{Name: "  "}, false},
+		{"name too long", RanklistInput{Name: strings.Repeat("a", 101)}, false},
+		{"tag list", RanklistInput{Name: "Shoegaze", Source: models.RanklistSourceTag, TagID: "t1"}, true},
+		{"tag list without a tag", RanklistInput{Name: "Shoegaze", Source: models.RanklistSourceTag}, false},
+		{"rating list", RanklistInput{Name: "Classics", Source: models.RanklistSourceRating, MinRating: 8, MaxRating: 10}, true},
+		{"reversed rating bounds", RanklistInput{Name: "Classics", Source: models.RanklistSourceRating, MinRating: 9, MaxRating: 8}, false},
+		{"unknown source", RanklistInput{Name: "Classics", Source: "shelf"}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.input.Normalize().Validate()
+			if c.ok && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !c.ok && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
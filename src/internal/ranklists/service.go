@@ -0,0 +1,487 @@
+package ranklists
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/alecdray/wax/src/internal/core/db"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"github.com/alecdray/wax/src/internal/core/db/sqlc"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ratingMatchTolerance widens a rating list's bounds so ratings converted
+// from the user's scale still match their own endpoints.
+const ratingMatchTolerance = 1e-9
+
+type Service struct {
+	db *db.DB
+}
+
+func NewService(db *db.DB) *Service {
+	return &Service{db: db}
+}
+
+func getRanklist(ctx context.Context, tx *db.DB, userId, ranklistId string) (RanklistDTO, error) {
+	row, err := tx.Queries().GetRanklist(ctx, sqlc.GetRanklistParams{
+		ID:     ranklistId,
+		UserID: userId,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return RanklistDTO{}, ErrRanklistNotFound
+	} else if err != nil {
+		return RanklistDTO{}, fmt.Errorf("failed to get ranklist: %w", err)
+	}
+	return newRanklistDTOFromModel(row.Ranklist, row.TagName, row.EntryCount), nil
+}
+
+// syncRanklist brings an automatic list's albums in line with its filter.
+// Albums that no longer match are dropped and new matches join the bottom of
+// the list, best rated first; albums that stay keep their place and blurb.
+func syncRanklist(ctx context.Context, tx *db.DB, userId string, ranklist RanklistDTO) error {
+	var matches []string
+	var err error
+	switch ranklist.Source {
+	case models.RanklistSourceTag:
+		// A tag's list takes in albums tagged with any of its subtags too.
+		var tagIds []string
+		tagIds, err = tx.Queries().GetSubtagIds(ctx, sqlc.GetSubtagIdsParams{
+			ID:     ranklist.TagID,
+			UserID: userId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get subtags: %w", err)
+		}
+		matches, err = tx.Queries().GetRanklistTagMatches(ctx, sqlc.GetRanklistTagMatchesParams{
+			UserID:   userId,
+			UserID_2: userId,
+			TagIds:   tagIds,
+			UserID_3: userId,
+		})
+	case models.RanklistSourceRating:
+		if ranklist.MinRating == nil || ranklist.MaxRating == nil {
+			return nil
+		}
+		matches, err = tx.Queries().GetRanklistRatingMatches(ctx, sqlc.GetRanklistRatingMatchesParams{
+			UserID:   userId,
+			Rating:   *ranklist.MinRating - ratingMatchTolerance,
+			Rating_2: *ranklist.MaxRating + ratingMatchTolerance,
+		})
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get ranklist matches: %w", err)
+	}
+
+	entries, err := tx.Queries().GetRanklistEntryPositions(ctx, ranklist.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get ranklist entries: %w", err)
+	}
+
+	matched := make(map[string]bool, len(matches))
+	for _, albumId := range matches {
+		matched[albumId] = true
+	}
+	ranked := make(map[string]bool, len(entries))
+	last := ""
+	for _, entry := range entries {
+		if !matched[entry.AlbumID] {
+			_, err := tx.Queries().DeleteRanklistEntry(ctx, sqlc.DeleteRanklistEntryParams{
+				ID:         entry.ID,
+				RanklistID: ranklist.ID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to remove unmatched entry: %w", err)
+			}
+			continue
+		}
+		ranked[entry.AlbumID] = true
+		last = entry.Position
+	}
+
+	var added []string
+	for _, albumId := range matches {
+		if !ranked[albumId] {
+			added = append(added, albumId)
+		}
+	}
+	positions, err := PositionsAfter(last, len(added))
+	if err != nil {
+		return err
+	}
+	for i, albumId := range added {
+		err := tx.Queries().InsertRanklistEntry(ctx, sqlc.InsertRanklistEntryParams{
+			ID:         uuid.NewString(),
+			RanklistID: ranklist.ID,
+			AlbumID:    albumId,
+			Position:   positions[i],
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add matched entry: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetUserRanklists returns the user's ranklists, most recently edited first,
+// with automatic lists brought up to date.
+func (s *Service) GetUserRanklists(ctx context.Context, userId string) ([]RanklistDTO, error) {
+	var dtos []RanklistDTO
+	err := s.db.WithTx(func(tx *db.DB) error {
+		rows, err := tx.Queries().GetRanklistsByUserId(ctx, userId)
+		if err != nil {
+			return fmt.Errorf("failed to get ranklists: %w", err)
+		}
+
+		dtos = make([]RanklistDTO, 0, len(rows))
+		for _, row := range rows {
+			dto := newRanklistDTOFromModel(row.Ranklist, row.TagName, row.EntryCount)
+			if dto.IsAuto() {
+				err := syncRanklist(ctx, tx, userId, dto)
+				if err != nil {
+					return err
+				}
+				dto, err = getRanklist(ctx, tx, userId, dto.ID)
+				if err != nil {
+					return err
+				}
+			}
+			dtos = append(dtos, dto)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dtos, nil
+}
+
+// GetRanklist returns a ranklist and its entries in order, bringing an
+// automatic list up to date first.
+func (s *Service) GetRanklist(ctx context.Context, userId, ranklistId string) (RanklistDTO, []RanklistEntryDTO, error) {
+	var ranklist RanklistDTO
+	var entries []RanklistEntryDTO
+	err := s.db.WithTx(func(tx *db.DB) error {
+		var err error
+		ranklist, err = getRanklist(ctx, tx, userId, ranklistId)
+		if err != nil {
+			return err
+		}
+		if ranklist.IsAuto() {
+			err = syncRanklist(ctx, tx, userId, ranklist)
+			if err != nil {
+				return err
+			}
+		}
+
+		rows, err := tx.Queries().GetRanklistEntries(ctx, ranklistId)
+		if err != nil {
+			return fmt.Errorf("failed to get ranklist entries: %w", err)
+		}
+		entries = make([]RanklistEntryDTO, 0, len(rows))
+		for _, row := range rows {
+			entry := RanklistEntryDTO{
+				ID:         row.RanklistEntry.ID,
+				AlbumID:    row.RanklistEntry.AlbumID,
+				AlbumTitle: row.AlbumTitle,
+				Artists:    fmt.Sprintf("%s", row.ArtistNames),
+				ImageURL:   row.ImageUrl.String,
+				Blurb:      row.RanklistEntry.Blurb,
+			}
+			if row.Rating.Valid {
+				entry.Rating = &row.Rating.Float64
+			}
+			entries = append(entries, entry)
+		}
+		ranklist.EntryCount = len(entries)
+		return nil
+	})
+	if err != nil {
+		return RanklistDTO{}, nil, err
+	}
+	return ranklist, entries, nil
+}
+
+// CreateRanklist creates a list; an automatic list starts out with every
+// matching album, best rated first.
+func (s *Service) CreateRanklist(ctx context.Context, userId string, input RanklistInput) (RanklistDTO, error) {
+	input = input.Normalize()
+	err := input.Validate()
+	if err != nil {
+		return RanklistDTO{}, fmt.Errorf("%w: %w", ErrInvalidRanklist, err)
+	}
+
+	params := sqlc.CreateRanklistParams{
+		ID:          uuid.NewString(),
+		UserID:      userId,
+		Name:        input.Name,
+		Description: input.Description,
+		Source:      input.Source,
+	}
+	switch input.Source {
+	case models.RanklistSourceTag:
+		params.TagID = sql.NullString{String: input.TagID, Valid: true}
+	case models.RanklistSourceRating:
+		params.MinRating = sql.NullFloat64{Float64: input.MinRating, Valid: true}
+		params.MaxRating = sql.NullFloat64{Float64: input.MaxRating, Valid: true}
+	}
+
+	var ranklist RanklistDTO
+	err = s.db.WithTx(func(tx *db.DB) error {
+		model, err := tx.Queries().CreateRanklist(ctx, params)
+		if err != nil {
+			return fmt.Errorf("failed to create ranklist: %w", err)
+		}
+		ranklist = newRanklistDTOFromModel(model, "", 0)
+		return syncRanklist(ctx, tx, userId, ranklist)
+	})
+	if err != nil {
+		return RanklistDTO{}, err
+	}
+	return ranklist, nil
+}
+
+// UpdateRanklist renames a list and replaces its description. A list's
+// source can't change once it's created.
+func (s *Service) UpdateRanklist(ctx context.Context, userId, ranklistId, name, description string) error {
+	ranklist, err := getRanklist(ctx, s.db, userId, ranklistId)
+	if err != nil {
+		return err
+	}
+
+	input := RanklistInput{
+		Name:        name,
+		Description: description,
+		Source:      ranklist.Source,
+		TagID:       ranklist.TagID,
+	}.Normalize()
+	if ranklist.MinRating != nil && ranklist.MaxRating != nil {
+		input.MinRating, input.MaxRating = *ranklist.MinRating, *ranklist.MaxRating
+	}
+	err = input.Validate()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidRanklist, err)
+	}
+
+	err = s.db.Queries().UpdateRanklist(ctx, sqlc.UpdateRanklistParams{
+		Name:        input.Name,
+		Description: input.Description,
+		ID:          ranklistId,
+		UserID:      userId,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update ranklist: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) DeleteRanklist(ctx context.Context, userId, ranklistId string) error {
+	return s.db.WithTx(func(tx *db.DB) error {
+		_, err := getRanklist(ctx, tx, userId, ranklistId)
+		if err != nil {
+			return err
+		}
+		err = tx.Queries().DeleteRanklistEntriesByRanklistId(ctx, ranklistId)
+		if err != nil {
+			return fmt.Errorf("failed to delete ranklist entries: %w", err)
+		}
+		err = tx.Queries().DeleteRanklist(ctx, sqlc.DeleteRanklistParams{
+			ID:     ranklistId,
+			UserID: userId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete ranklist: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetCandidateAlbums returns the library albums that aren't on the list yet,
+// by title.
+func (s *Service) GetCandidateAlbums(ctx context.Context, userId, ranklistId string) ([]CandidateAlbumDTO, error) {
+	rows, err := s.db.Queries().GetRanklistCandidateAlbums(ctx, sqlc.GetRanklistCandidateAlbumsParams{
+		UserID:     userId,
+		RanklistID: ranklistId,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get candidate albums: %w", err)
+	}
+	dtos := make([]CandidateAlbumDTO, 0, len(rows))
+	for _, row := range rows {
+		dtos = append(dtos, CandidateAlbumDTO{
+			ID:      row.ID,
+			Title:   row.Title,
+			Artists: fmt.Sprintf("%s", row.ArtistNames),
+		})
+	}
+	return dtos, nil
+}
+
+// AddAlbum puts an album from the user's library on a hand-built list at the
+// given 1-based rank, or at the bottom when rank is 0.
+func (s *Service) AddAlbum(ctx context.Context, userId, ranklistId, albumId string, rank int) error {
+	return s.db.WithTx(func(tx *db.DB) error {
+		ranklist, err := getRanklist(ctx, tx, userId, ranklistId)
+		if err != nil {
+			return err
+		}
+		if ranklist.IsAuto() {
+			return ErrAutoRanklist
+		}
+
+		libraryAlbumIds, err := tx.Queries().GetLibraryAlbumIds(ctx, sqlc.GetLibraryAlbumIdsParams{
+			UserID:   userId,
+			AlbumIds: []string{albumId},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to check the library for the album: %w", err)
+		}
+		if len(libraryAlbumIds) == 0 {
+			return ErrAlbumNotInLibrary
+		}
+
+		entries, err := tx.Queries().GetRanklistEntryPositions(ctx, ranklistId)
+		if err != nil {
+			return fmt.Errorf("failed to get ranklist entries: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.AlbumID == albumId {
+				return ErrAlbumAlreadyRanked
+			}
+		}
+
+		index := len(entries)
+		if rank > 0 {
+			index = rank - 1
+		}
+		position, err := positionAt(entries, index)
+		if err != nil {
+			return err
+		}
+		err = tx.Queries().InsertRanklistEntry(ctx, sqlc.InsertRanklistEntryParams{
+			ID:         uuid.NewString(),
+			RanklistID: ranklistId,
+			AlbumID:    albumId,
+			Position:   position,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add album to ranklist: %w", err)
+		}
+		return touchRanklist(ctx, tx, ranklistId)
+	})
+}
+
+// MoveEntry places an entry after beforeId and ahead of afterId, the
+// neighbours it was dropped between. Either may be empty at the ends of the
+// list. Only the moved entry's position changes.
+func (s *Service) MoveEntry(ctx context.Context, userId, ranklistId, entryId, beforeId, afterId string) error {
+	return s.moveEntry(ctx, userId, ranklistId, entryId, func(others []sqlc.GetRanklistEntryPositionsRow) (string, error) {
+		return positionBetweenEntries(others, beforeId, afterId)
+	})
+}
+
+// MoveEntryToRank moves an entry to a 1-based rank, clamped to the list.
+func (s *Service) MoveEntryToRank(ctx context.Context, userId, ranklistId, entryId string, rank int) error {
+	return s.moveEntry(ctx, userId, ranklistId, entryId, func(others []sqlc.GetRanklistEntryPositionsRow) (string, error) {
+		return positionAt(others, rank-1)
+	})
+}
+
+func (s *Service) moveEntry(ctx context.Context, userId, ranklistId, entryId string, place func([]sqlc.GetRanklistEntryPositionsRow) (string, error)) error {
+	return s.db.WithTx(func(tx *db.DB) error {
+		_, err := getRanklist(ctx, tx, userId, ranklistId)
+		if err != nil {
+			return err
+		}
+
+		entries, err := tx.Queries().GetRanklistEntryPositions(ctx, ranklistId)
+		if err != nil {
+			return fmt.Errorf("failed to get ranklist entries: %w", err)
+		}
+		others := make([]sqlc.GetRanklistEntryPositionsRow, 0, len(entries))
+		for _, entry := range entries {
+			if entry.ID != entryId {
+				others = append(others, entry)
+			}
+		}
+		if len(others) == len(entries) {
+			return ErrEntryNotFound
+		}
+
+		position, err := place(others)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Queries().UpdateRanklistEntryPosition(ctx, sqlc.UpdateRanklistEntryPositionParams{
+			Position:   position,
+			ID:         entryId,
+			RanklistID: ranklistId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to move ranklist entry: %w", err)
+		}
+		return touchRanklist(ctx, tx, ranklistId)
+	})
+}
+
+func (s *Service) SetEntryBlurb(ctx context.Context, userId, ranklistId, entryId, blurb string) error {
+	blurb = strings.TrimSpace(blurb)
+	err := validateBlurb(blurb)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithTx(func(tx *db.DB) error {
+		_, err := getRanklist(ctx, tx, userId, ranklistId)
+		if err != nil {
+			return err
+		}
+		updated, err := tx.Queries().UpdateRanklistEntryBlurb(ctx, sqlc.UpdateRanklistEntryBlurbParams{
+			Blurb:      blurb,
+			ID:         entryId,
+			RanklistID: ranklistId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save blurb: %w", err)
+		}
+		if updated == 0 {
+			return ErrEntryNotFound
+		}
+		return touchRanklist(ctx, tx, ranklistId)
+	})
+}
+
+func (s *Service) RemoveEntry(ctx context.Context, userId, ranklistId, entryId string) error {
+	return s.db.WithTx(func(tx *db.DB) error {
+		ranklist, err := getRanklist(ctx, tx, userId, ranklistId)
+		if err != nil {
+			return err
+		}
+		if ranklist.IsAuto() {
+			return ErrAutoRanklist
+		}
+		removed, err := tx.Queries().DeleteRanklistEntry(ctx, sqlc.DeleteRanklistEntryParams{
+			ID:         entryId,
+			RanklistID: ranklistId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to remove ranklist entry: %w", err)
+		}
+		if removed == 0 {
+			return ErrEntryNotFound
+		}
+		return touchRanklist(ctx, tx, ranklistId)
+	})
+}
+
+func touchRanklist(ctx context.Context, tx *db.DB, ranklistId string) error {
+	err := tx.Queries().TouchRanklist(ctx, ranklistId)
+	if err != nil {
+		return fmt.Errorf("failed to update ranklist: %w", err)
+	}
+	return nil
+}
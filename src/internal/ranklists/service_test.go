@@ -0,0 +1,43 @@
+package ranklists
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alecdray/wax/src/internal/core/db/dbtest"
+)
+
+func TestAddAlbum_OnlyFromTheUsersLibrary(t *testing.T) {
+	database := dbtest.New(t)
+	service := NewService(database)
+	ctx := context.Background()
+
+	dbtest.Exec(t, database, "INSERT INTO users (id, spotify_id) VALUES ('u1', 'u1'), ('u2', 'u2')")
+	for _, album := range []struct{ id, userId string }{{"mine", "u1"}, {"theirs", "u2"}} {
+		dbtest.Exec(t, database, "INSERT INTO albums (id, spotify_id, title) VALUES (?, ?, ?)", album.id, album.id, album.id)
+		dbtest.Exec(t, database, "INSERT INTO releases (id, album_id, format) VALUES (?, ?, 'digital')", album.id+"-r", album.id)
+		dbtest.Exec(t, database, "INSERT INTO user_releases (id, user_id, release_id) VALUES (?, ?, ?)", album.id+"-ur", album.userId, album.id+"-r")
+	}
+
+	ranklist, err := service.CreateRanklist(ctx, "u1", RanklistInput{Name: "Best of"})
+	if err != nil {
+		t.Fatalf("failed to create ranklist: %v", err)
+	}
+	if err := service.AddAlbum(ctx, "u1", ranklist.ID, "mine", 0); err != nil {
+		t.Fatalf("expected an album from the library to be added, got %v", err)
+	}
+	for _, albumId := range []string{"theirs", "missing"} {
+		if err := service.AddAlbum(ctx, "u1", ranklist.ID, albumId, 0); !errors.Is(err, ErrAlbumNotInLibrary) {
+			t.Errorf("expected %q to be refused, got %v", albumId, err)
+		}
+	}
+
+	_, entries, err := service.GetRanklist(ctx, "u1", ranklist.ID)
+	if err != nil {
+		t.Fatalf("failed to get ranklist: %v", err)
+	}
+	if len(entries) != 1 || entries[0].AlbumID != "mine" {
+		t.Errorf("expected only the library album ranked, got %+v", entries)
+	}
+}
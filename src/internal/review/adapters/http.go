@@ -1,15 +1,22 @@
 package adapters
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"slices"
 	"github.com/alecdray/wax/src/internal/core/contextx"
+	"github.com/alecdray/wax/src/internal/core/db/models"
 	"github.com/alecdray/wax/src/internal/core/httpx"
 	"github.com/alecdray/wax/src/internal/library"
 	"github.com/alecdray/wax/src/internal/library/adapters"
 	"github.com/alecdray/wax/src/internal/review"
 	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
 )
 
 type HttpHandler struct {
@@ -130,8 +137,9 @@ func (h *HttpHandler) SubmitRatingRecommenderQuestions(w http.ResponseWriter, r
 	}
 	formData := r.Form
 
-	questionsWithValues := make(review.RatingQuestions, len(review.RatingRecommenderQuestions))
-	for i, question := range review.RatingRecommenderQuestions {
+	profile := review.RatingProfileFromContext(ctx)
+	questionsWithValues := make(review.RatingQuestions, len(profile.Questions))
+	for i, question := range profile.Questions {
 		if !formData.Has(question.Key.String()) {
 			err = fmt.Errorf("missing form value for key %s", question.Key.String())
 			httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
@@ -154,9 +162,9 @@ func (h *HttpHandler) SubmitRatingRecommenderQuestions(w http.ResponseWriter, r
 		questionsWithValues[i] = question.WithValue(val)
 	}
 
-	rating := questionsWithValues.Rating()
+	rating := profile.Rating(questionsWithValues)
 
-	err = RatingRecommenderConfirm(*album, &rating).Render(ctx, w)
+	err = RatingRecommenderConfirm(*album, &rating, questionsWithValues.Answers()).Render(ctx, w)
 	if err != nil {
 		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
 			Status: http.StatusInternalServerError,
@@ -170,6 +178,107 @@ func (h *HttpHandler) SubmitRatingRecommenderQuestions(w http.ResponseWriter, r
 func (h *HttpHandler) GetRatingRecommenderQuestions(w http.ResponseWriter, r *http.Request) {
 	ctx := contextx.NewContextX(r.Context())
 
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	albumId := r.URL.Query().Get("albumId")
+	if albumId == "" {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    errors.New("missing album ID"),
+		})
+		return
+	}
+
+	trackMarks, err := h.reviewService.GetTrackMarks(ctx, userId, albumId)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+
+	questions, prefilled := review.RatingProfileFromContext(ctx).Questions.PrefillFromTrackMarks(trackMarks.Summary())
+
+	err = RatingRecommenderQuestions(albumId, questions, prefilled).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+	}
+}
+
+func (h *HttpHandler) GetRatingRecommenderComparison(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		err = fmt.Errorf("failed to get user ID: %w", err)
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	albumId := r.URL.Query().Get("albumId")
+	if albumId == "" {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    errors.New("missing album ID"),
+		})
+		return
+	}
+
+	album, err := h.libraryService.GetAlbumInLibrary(ctx, userId, albumId)
+	if err != nil {
+		err = fmt.Errorf("failed to get album: %w", err)
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	candidates, err := h.reviewService.GetComparisonCandidates(ctx, userId, albumId)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+
+	err = RatingRecommenderComparison(*album, candidates, review.NewComparisonSearch(len(candidates))).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+	}
+}
+
+func (h *HttpHandler) SubmitRatingRecommenderComparison(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		err = fmt.Errorf("failed to get user ID: %w", err)
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
 	albumId := r.URL.Query().Get("albumId")
 	if albumId == "" {
 		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
@@ -179,7 +288,93 @@ func (h *HttpHandler) GetRatingRecommenderQuestions(w http.ResponseWriter, r *ht
 		return
 	}
 
-	err := RatingRecommenderQuestions(albumId, review.RatingRecommenderQuestions).Render(ctx, w)
+	album, err := h.libraryService.GetAlbumInLibrary(ctx, userId, albumId)
+	if err != nil {
+		err = fmt.Errorf("failed to get album: %w", err)
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+	formData := r.Form
+
+	lo, loErr := strconv.Atoi(formData.Get("lo"))
+	hi, hiErr := strconv.Atoi(formData.Get("hi"))
+	if err := errors.Join(loErr, hiErr); err != nil {
+		err = fmt.Errorf("failed to parse search bounds: %w", err)
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	outcome := models.ComparisonOutcome(formData.Get("outcome"))
+	switch outcome {
+	case models.ComparisonOutcomeBetter, models.ComparisonOutcomeWorse, models.ComparisonOutcomeEqual:
+	default:
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("invalid comparison outcome %q", outcome),
+		})
+		return
+	}
+
+	opponentAlbumId := formData.Get("opponentAlbumId")
+	if opponentAlbumId == "" {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    errors.New("missing opponent album ID"),
+		})
+		return
+	}
+
+	err = h.reviewService.RecordComparison(ctx, userId, albumId, opponentAlbumId, outcome)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, review.ErrInvalidComparisonOpponent) {
+			status = http.StatusBadRequest
+		}
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: status,
+			Err:    err,
+		})
+		return
+	}
+
+	candidates, err := h.reviewService.GetComparisonCandidates(ctx, userId, albumId)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+
+	search := review.ComparisonSearch{Lo: lo, Hi: hi}
+	if search.Lo < 0 || search.Hi >= len(candidates) || search.Done() || candidates[search.Mid()].AlbumID != opponentAlbumId {
+		// Ratings changed since the last step; the comparison is still
+		// recorded, but the search has to start over.
+		search = review.NewComparisonSearch(len(candidates))
+	} else {
+		search = search.Apply(outcome)
+	}
+
+	if score := search.Score(candidates); score != nil {
+		err = RatingRecommenderConfirm(*album, score, nil).Render(ctx, w)
+	} else {
+		err = RatingRecommenderComparison(*album, candidates, search).Render(ctx, w)
+	}
 	if err != nil {
 		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
 			Status: http.StatusInternalServerError,
@@ -222,6 +417,9 @@ func (h *HttpHandler) SubmitRatingRecommenderRating(w http.ResponseWriter, r *ht
 	}
 	formData := r.Form
 
+	// Ratings are entered on the user's scale and stored canonically.
+	profile := review.RatingProfileFromContext(ctx)
+
 	queryRating := formData.Get("rating")
 	rating, err := strconv.ParseFloat(queryRating, 64)
 	if err != nil {
@@ -232,6 +430,7 @@ func (h *HttpHandler) SubmitRatingRecommenderRating(w http.ResponseWriter, r *ht
 		})
 		return
 	}
+	rating = profile.FromScale(rating)
 
 	note := formData.Get("note")
 	if len(note) > 2000 {
@@ -242,7 +441,48 @@ func (h *HttpHandler) SubmitRatingRecommenderRating(w http.ResponseWriter, r *ht
 		return
 	}
 
-	albumRating, err := h.reviewService.AddRating(ctx, userId, albumId, rating, note)
+	scores := review.RatingScores{}
+	for _, dimension := range review.RatingSubDimensions {
+		value := formData.Get(dimension.String())
+		if value == "" {
+			continue
+		}
+		score, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			err = fmt.Errorf("failed to parse %s score: %w", dimension, err)
+			httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+				Status: http.StatusBadRequest,
+				Err:    err,
+			})
+			return
+		}
+		scores[dimension] = profile.FromScale(score)
+	}
+
+	answers := review.RatingAnswers{}
+	for _, question := range profile.Questions {
+		value := formData.Get(ratingAnswerFieldName(question.Key))
+		if value == "" {
+			continue
+		}
+		answer, err := strconv.Atoi(value)
+		if err != nil {
+			err = fmt.Errorf("failed to parse %s answer: %w", question.Key, err)
+			httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+				Status: http.StatusBadRequest,
+				Err:    err,
+			})
+			return
+		}
+		answers[question.Key] = answer
+	}
+
+	albumRating, err := h.reviewService.AddRating(ctx, userId, albumId, review.RatingInput{
+		Rating:  rating,
+		Note:    note,
+		Scores:  scores,
+		Answers: answers,
+	})
 	if err != nil {
 		err = fmt.Errorf("failed to add rating: %w", err)
 		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
@@ -289,6 +529,15 @@ func (h *HttpHandler) SubmitRatingRecommenderRating(w http.ResponseWriter, r *ht
 		})
 		return
 	}
+
+	err = adapters.AlbumRatingDimensions(*album, true).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
 }
 
 func (h *HttpHandler) DeleteRatingLogEntry(w http.ResponseWriter, r *http.Request) {
@@ -356,4 +605,811 @@ func (h *HttpHandler) DeleteRatingLogEntry(w http.ResponseWriter, r *http.Reques
 		})
 		return
 	}
+
+	err = adapters.AlbumRatingDimensions(*album, true).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+}
+
+// parseRatingProfileForm builds a profile from the settings form. Bands and
+// questions arrive as parallel arrays; question options are entered one per
+// line, worst first.
+func parseRatingProfileForm(form url.Values) (review.RatingProfile, error) {
+	var profile review.RatingProfile
+
+	numbers := []struct {
+		name  string
+		value *float64
+	}{
+		{"scaleMin", &profile.ScaleMin},
+		{"scaleMax", &profile.ScaleMax},
+		{"step", &profile.Step},
+		{"floor", &profile.Floor},
+		{"ceiling", &profile.Ceiling},
+		{"curveExponent", &profile.CurveExponent},
+	}
+	for _, n := range numbers {
+		value, err := strconv.ParseFloat(form.Get(n.name), 64)
+		if err != nil {
+			return profile, fmt.Errorf("%w: %s must be a number", review.ErrInvalidRatingProfile, n.name)
+		}
+		*n.value = value
+	}
+
+	bandMins := form["bandMin[]"]
+	bandLabels := form["bandLabel[]"]
+	if len(bandMins) != len(bandLabels) {
+		return profile, fmt.Errorf("%w: mismatched label fields", review.ErrInvalidRatingProfile)
+	}
+	for i := range bandMins {
+		minValue, err := strconv.ParseFloat(bandMins[i], 64)
+		if err != nil {
+			return profile, fmt.Errorf("%w: label %q needs a starting score", review.ErrInvalidRatingProfile, bandLabels[i])
+		}
+		profile.Bands = append(profile.Bands, review.RatingKeyEntry{
+			MinValue: minValue,
+			Label:    review.RatingLabel(bandLabels[i]),
+		})
+	}
+
+	keys := form["questionKey[]"]
+	texts := form["questionText[]"]
+	weights := form["questionWeight[]"]
+	options := form["questionOptions[]"]
+	if len(keys) != len(texts) || len(keys) != len(weights) || len(keys) != len(options) {
+		return profile, fmt.Errorf("%w: mismatched question fields", review.ErrInvalidRatingProfile)
+	}
+	for i := range keys {
+		weight, err := strconv.ParseFloat(weights[i], 64)
+		if err != nil {
+			return profile, fmt.Errorf("%w: question %d needs a numeric weight", review.ErrInvalidRatingProfile, i+1)
+		}
+
+		// Keep existing keys so stored answers stay attached to their question.
+		key := review.RatingQuestionKey(strings.TrimSpace(keys[i]))
+		if key == "" {
+			key = review.RatingQuestionKey("q-" + uuid.NewString()[:8])
+		}
+
+		question := review.RatingQuestion{
+			Key:      key,
+			Question: strings.TrimSpace(texts[i]),
+			Weight:   weight,
+		}
+		for _, line := range strings.Split(options[i], "\n") {
+			label := strings.TrimSpace(line)
+			if label == "" {
+				continue
+			}
+			question.Options = append(question.Options, review.RatingQuestionOption{
+				Value: len(question.Options) + 1,
+				Label: label,
+			})
+		}
+		profile.Questions = append(profile.Questions, question)
+	}
+
+	return profile, nil
+}
+
+func (h *HttpHandler) GetRatingProfilePage(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	revisitRules, err := h.reviewService.GetRevisitRules(ctx, userId)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+
+	err = RatingProfilePage(review.RatingProfileFromContext(ctx), revisitRules).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) SubmitRatingProfile(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	profile, err := parseRatingProfileForm(r.Form)
+	if err == nil {
+		profile, err = h.reviewService.SaveRatingProfile(ctx, userId, profile)
+	}
+	if errors.Is(err, review.ErrInvalidRatingProfile) {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status:   http.StatusUnprocessableEntity,
+			Err:      err,
+			Response: *httpx.NewErrorResponse().SetComponent(RatingProfileError(err.Error())),
+		})
+		return
+	} else if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+
+	err = RatingProfileForm(profile, true).Render(review.ContextWithRatingProfile(ctx, profile), w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) ApplyRatingProfilePreset(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	preset, ok := review.GetRatingProfilePreset(r.URL.Query().Get("preset"))
+	if !ok {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    errors.New("unknown rating profile preset"),
+		})
+		return
+	}
+
+	// Presets replace the scale and labels but keep the user's questions.
+	preset.Questions = review.RatingProfileFromContext(ctx).Questions
+
+	profile, err := h.reviewService.SaveRatingProfile(ctx, userId, preset)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+
+	err = RatingProfileForm(profile, true).Render(review.ContextWithRatingProfile(ctx, profile), w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) ResetRatingProfile(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	err = h.reviewService.ResetRatingProfile(ctx, userId)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+
+	profile := review.DefaultRatingProfile()
+	err = RatingProfileForm(profile, true).Render(review.ContextWithRatingProfile(ctx, profile), w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) GetAlbumReviewPage(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	albumId := r.URL.Query().Get("albumId")
+	if albumId == "" {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    errors.New("missing album ID"),
+		})
+		return
+	}
+
+	album, revisions, err := h.getAlbumWithReviewRevisions(ctx, userId, albumId)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	err = AlbumReviewPage(*album, revisions).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) getAlbumWithReviewRevisions(ctx context.Context, userId, albumId string) (*library.AlbumDTO, []*review.AlbumReviewRevisionDTO, error) {
+	album, err := h.libraryService.GetAlbumInLibrary(ctx, userId, albumId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get album: %w", err)
+	}
+
+	var revisions []*review.AlbumReviewRevisionDTO
+	if album.Review != nil {
+		revisions, err = h.reviewService.GetAlbumReviewRevisions(ctx, album.Review.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return album, revisions, nil
+}
+
+func (h *HttpHandler) SubmitAlbumReview(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	albumId := r.URL.Query().Get("albumId")
+	if albumId == "" {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    errors.New("missing album ID"),
+		})
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	input := review.AlbumReviewInput{
+		Body: strings.ReplaceAll(r.Form.Get("body"), "\r\n", "\n"),
+		Mode: review.AlbumReviewSaveMode(r.Form.Get("mode")),
+	}
+	switch input.Mode {
+	case review.AlbumReviewSaveAutosave, review.AlbumReviewSaveDraft, review.AlbumReviewSavePublish:
+	default:
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("invalid save mode %q", input.Mode),
+		})
+		return
+	}
+	if ratingLogId := r.Form.Get("ratingLogId"); ratingLogId != "" {
+		input.RatingLogID = &ratingLogId
+	}
+
+	saved, err := h.reviewService.SaveAlbumReview(ctx, userId, albumId, input)
+	if errors.Is(err, review.ErrAlbumReviewTooLong) ||
+		errors.Is(err, review.ErrAlbumReviewRatingNotFound) ||
+		errors.Is(err, review.ErrAlbumReviewPublished) {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status:   http.StatusUnprocessableEntity,
+			Err:      err,
+			Response: *httpx.NewErrorResponse().SetComponent(AlbumReviewError(err.Error())),
+		})
+		return
+	} else if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+
+	// Autosaves only refresh the status line so the textarea isn't replaced
+	// while the user is typing.
+	if input.Mode == review.AlbumReviewSaveAutosave {
+		err = AlbumReviewSaveStatus(saved).Render(ctx, w)
+		if err != nil {
+			httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			})
+		}
+		return
+	}
+
+	album, revisions, err := h.getAlbumWithReviewRevisions(ctx, userId, albumId)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	err = AlbumReviewEditor(*album, revisions).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) PreviewAlbumReview(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	err := r.ParseForm()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	preview := &review.AlbumReviewDTO{Body: r.Form.Get("body")}
+	err = AlbumReviewPreview(preview).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) DeleteAlbumReview(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	albumId := r.URL.Query().Get("albumId")
+	if albumId == "" {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    errors.New("missing album ID"),
+		})
+		return
+	}
+
+	err = h.reviewService.DeleteAlbumReview(ctx, userId, albumId)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+
+	album, revisions, err := h.getAlbumWithReviewRevisions(ctx, userId, albumId)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	err = AlbumReviewEditor(*album, revisions).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) SetTrackMark(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	query := r.URL.Query()
+	albumId := query.Get("albumId")
+	trackId := query.Get("trackId")
+	if albumId == "" || trackId == "" {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    errors.New("missing album or track ID"),
+		})
+		return
+	}
+
+	mark := models.TrackMark(query.Get("mark"))
+	switch mark {
+	case "", models.TrackMarkStandout, models.TrackMarkSkip:
+	default:
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("invalid track mark: %q", mark),
+		})
+		return
+	}
+
+	album, err := h.libraryService.GetAlbumInLibrary(ctx, userId, albumId)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get album: %w", err),
+		})
+		return
+	}
+
+	index := slices.IndexFunc(album.Tracks, func(track library.TrackDTO) bool {
+		return track.ID == trackId
+	})
+	if index < 0 {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    errors.New("track not found on album"),
+		})
+		return
+	}
+
+	err = h.reviewService.SetTrackMark(ctx, userId, trackId, mark)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+
+	track := album.Tracks[index]
+	track.Mark = mark
+
+	err = adapters.AlbumTrackRow(album.ID, index, track).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) GetTopTracksPage(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	tracks, err := h.reviewService.GetTopTracks(ctx, userId)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+
+	err = TopTracksPage(tracks).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+func parseRevisitRulesForm(form url.Values) (review.RevisitRules, error) {
+	var rules review.RevisitRules
+	fields := []struct {
+		name  string
+		label string
+		dest  *int
+	}{
+		{"staleAfterDays", "stale rating age", &rules.StaleAfterDays},
+		{"minPlaysSince", "plays since rating", &rules.MinPlaysSince},
+		{"maxListensBefore", "listens before rating", &rules.MaxListensBefore},
+	}
+	for _, field := range fields {
+		value, err := strconv.Atoi(strings.TrimSpace(form.Get(field.name)))
+		if err != nil {
+			return review.RevisitRules{}, fmt.Errorf("%w: %s must be a whole number", review.ErrInvalidRevisitRules, field.label)
+		}
+		*field.dest = value
+	}
+	return rules, nil
+}
+
+func (h *HttpHandler) SubmitRevisitRules(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	rules, err := parseRevisitRulesForm(r.Form)
+	if err == nil {
+		rules, err = h.reviewService.SaveRevisitRules(ctx, userId, rules)
+	}
+	if errors.Is(err, review.ErrInvalidRevisitRules) {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status:   http.StatusUnprocessableEntity,
+			Err:      err,
+			Response: *httpx.NewErrorResponse().SetComponent(RevisitRulesError(err.Error())),
+		})
+		return
+	} else if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+
+	err = RevisitRulesForm(rules, true).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+}
+
+// parseCalibrationTarget reads the target curve from a request, falling back
+// to the default curve when none was picked.
+func parseCalibrationTarget(values url.Values, profile review.RatingProfile) (review.CalibrationTarget, error) {
+	key := values.Get("target")
+	if key == "" {
+		return review.DefaultCalibrationTarget(), nil
+	}
+	if key != review.CalibrationTargetCustom {
+		target, ok := review.GetCalibrationTarget(key)
+		if !ok {
+			return review.CalibrationTarget{}, fmt.Errorf("%w: unknown curve %q", review.ErrInvalidCalibrationTarget, key)
+		}
+		return target, nil
+	}
+
+	mean, err := strconv.ParseFloat(values.Get("mean"), 64)
+	if err != nil {
+		return review.CalibrationTarget{}, fmt.Errorf("%w: the average must be a number", review.ErrInvalidCalibrationTarget)
+	}
+	spread, err := strconv.ParseFloat(values.Get("spread"), 64)
+	if err != nil {
+		return review.CalibrationTarget{}, fmt.Errorf("%w: the spread must be a number", review.ErrInvalidCalibrationTarget)
+	}
+	return review.NewCustomCalibrationTarget(profile, mean, spread)
+}
+
+func (h *HttpHandler) GetCalibrationPage(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	report, err := h.reviewService.GetCalibrationReport(ctx, userId, review.DefaultCalibrationTarget())
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+
+	err = CalibrationPage(report).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) GetCalibrationReport(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	target, err := parseCalibrationTarget(r.URL.Query(), review.RatingProfileFromContext(ctx))
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status:   http.StatusUnprocessableEntity,
+			Err:      err,
+			Response: *httpx.NewErrorResponse().SetComponent(CalibrationError(err.Error())),
+		})
+		return
+	}
+
+	report, err := h.reviewService.GetCalibrationReport(ctx, userId, target)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+
+	err = CalibrationReport(report, 0).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) ApplyCalibration(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	target, err := parseCalibrationTarget(r.Form, review.RatingProfileFromContext(ctx))
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status:   http.StatusUnprocessableEntity,
+			Err:      err,
+			Response: *httpx.NewErrorResponse().SetComponent(CalibrationError(err.Error())),
+		})
+		return
+	}
+
+	applied, err := h.reviewService.ApplyCalibration(ctx, userId, target, r.Form["albumId"])
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+
+	report, err := h.reviewService.GetCalibrationReport(ctx, userId, target)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+
+	err = CalibrationReport(report, applied).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
 }
@@ -0,0 +1,34 @@
+package adapters
+
+import (
+	"log/slog"
+	"net/http"
+	"github.com/alecdray/wax/src/internal/core/contextx"
+	"github.com/alecdray/wax/src/internal/core/httpx"
+	"github.com/alecdray/wax/src/internal/review"
+)
+
+// RatingProfileMiddleware attaches the user's rating profile to the request
+// context so handlers and templates can read it with
+// review.RatingProfileFromContext. It must run after the JWT middleware.
+func RatingProfileMiddleware(reviewService *review.Service) httpx.Middleware {
+	return func(next httpx.HandlerFunc) httpx.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ctx := contextx.NewContextX(r.Context())
+
+			userId, err := ctx.UserId()
+			if err != nil {
+				next(w, r)
+				return
+			}
+
+			profile, err := reviewService.GetRatingProfile(ctx, userId)
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to load rating profile, using default", "error", err)
+				profile = review.DefaultRatingProfile()
+			}
+
+			next(w, r.WithContext(review.ContextWithRatingProfile(ctx, profile)))
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package review
+
+import (
+	"errors"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"github.com/alecdray/wax/src/internal/core/db/sqlc"
+	"time"
+)
+
+const (
+	// AlbumReviewMaxLength caps a review body, in bytes.
+	AlbumReviewMaxLength = 100_000
+	// revisionCoalesceWindow is how long autosaves keep updating the latest
+	// draft revision before a new one is started.
+	revisionCoalesceWindow = 10 * time.Minute
+)
+
+var (
+	ErrAlbumReviewTooLong        = errors.New("review exceeds the maximum length")
+	ErrAlbumReviewRatingNotFound = errors.New("linked rating not found for this album")
+	ErrAlbumReviewPublished      = errors.New("published reviews are not autosaved")
+)
+
+type AlbumReviewDTO struct {
+	ID          string
+	UserID      string
+	AlbumID     string
+	RatingLogID *string
+	Body        string
+	Status      models.ReviewStatus
+	PublishedAt *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func NewAlbumReviewDTOFromModel(model sqlc.AlbumReview) *AlbumReviewDTO {
+	dto := &AlbumReviewDTO{
+		ID:        model.ID,
+		UserID:    model.UserID,
+		AlbumID:   model.AlbumID,
+		Body:      model.Body,
+		Status:    model.Status,
+		CreatedAt: model.CreatedAt,
+		UpdatedAt: model.UpdatedAt,
+	}
+	if model.RatingLogID.Valid {
+		dto.RatingLogID = &model.RatingLogID.String
+	}
+	if model.PublishedAt.Valid {
+		dto.PublishedAt = &model.PublishedAt.Time
+	}
+	return dto
+}
+
+func (dto *AlbumReviewDTO) IsPublished() bool {
+	return dto != nil && dto.Status == models.ReviewStatusPublished
+}
+
+// HTML renders the review body as sanitized markdown.
+func (dto *AlbumReviewDTO) HTML() string {
+	return RenderMarkdown(dto.Body)
+}
+
+type AlbumReviewRevisionDTO struct {
+	ID        string
+	ReviewID  string
+	Body      string
+	Status    models.ReviewStatus
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func NewAlbumReviewRevisionDTOFromModel(model sqlc.AlbumReviewRevision) *AlbumReviewRevisionDTO {
+	return &AlbumReviewRevisionDTO{
+		ID:        model.ID,
+		ReviewID:  model.ReviewID,
+		Body:      model.Body,
+		Status:    model.Status,
+		CreatedAt: model.CreatedAt,
+		UpdatedAt: model.UpdatedAt,
+	}
+}
+
+// HTML renders the revision body as sanitized markdown.
+func (dto *AlbumReviewRevisionDTO) HTML() string {
+	return RenderMarkdown(dto.Body)
+}
+
+type AlbumReviewSaveMode string
+
+const (
+	// AlbumReviewSaveAutosave is a background save while editing a draft.
+	AlbumReviewSaveAutosave AlbumReviewSaveMode = "autosave"
+	// AlbumReviewSaveDraft is an explicit save that keeps the review a draft.
+	AlbumReviewSaveDraft AlbumReviewSaveMode = "draft"
+	// AlbumReviewSavePublish saves and publishes the review.
+	AlbumReviewSavePublish AlbumReviewSaveMode = "publish"
+)
+
+func (m AlbumReviewSaveMode) Status() models.ReviewStatus {
+	if m == AlbumReviewSavePublish {
+		return models.ReviewStatusPublished
+	}
+	return models.ReviewStatusDraft
+}
+
+type AlbumReviewInput struct {
+	Body        string
+	RatingLogID *string
+	Mode        AlbumReviewSaveMode
+}
+
+type revisionAction int
+
+const (
+	revisionSkip revisionAction = iota
+	revisionInsert
+	revisionUpdate
+)
+
+// nextRevisionAction decides how a save is recorded in the revision history.
+// Unchanged saves are skipped, autosaves fold into a recent draft revision so
+// the history isn't one entry per keystroke pause, and everything else starts
+// a new revision.
+func nextRevisionAction(latest *AlbumReviewRevisionDTO, body string, mode AlbumReviewSaveMode, now time.Time) revisionAction {
+	if latest == nil {
+		return revisionInsert
+	}
+	status := mode.Status()
+	if latest.Body == body && latest.Status == status {
+		return revisionSkip
+	}
+	if mode == AlbumReviewSaveAutosave &&
+		latest.Status == models.ReviewStatusDraft &&
+		now.Sub(latest.CreatedAt) < revisionCoalesceWindow {
+		return revisionUpdate
+	}
+	return revisionInsert
+}
@@ -0,0 +1,83 @@
+package review
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecdray/wax/src/internal/core/db/models"
+)
+
+// --- nextRevisionAction ---
+
+func TestNextRevisionAction_FirstSaveInserts(t *testing.T) {
+	got := nextRevisionAction(nil, "hello", AlbumReviewSaveAutosave, time.Now())
+	if got != revisionInsert {
+		t.Fatalf("expected insert, got %v", got)
+	}
+}
+
+func TestNextRevisionAction_UnchangedIsSkipped(t *testing.T) {
+	now := time.Now()
+	latest := &AlbumReviewRevisionDTO{Body: "hello", Status: models.ReviewStatusDraft, CreatedAt: now}
+	if got := nextRevisionAction(latest, "hello", AlbumReviewSaveDraft, now); got != revisionSkip {
+		t.Fatalf("expected skip, got %v", got)
+	}
+}
+
+func TestNextRevisionAction_AutosaveCoalescesRecentDraft(t *testing.T) {
+	now := time.Now()
+	latest := &AlbumReviewRevisionDTO{Body: "hel", Status: models.ReviewStatusDraft, CreatedAt: now.Add(-time.Minute)}
+	if got := nextRevisionAction(latest, "hello", AlbumReviewSaveAutosave, now); got != revisionUpdate {
+		t.Fatalf("expected update, got %v", got)
+	}
+}
+
+func TestNextRevisionAction_AutosaveAfterWindowInserts(t *testing.T) {
+	now := time.Now()
+	latest := &AlbumReviewRevisionDTO{Body: "hel", Status: models.ReviewStatusDraft, CreatedAt: now.Add(-revisionCoalesceWindow)}
+	if got := nextRevisionAction(latest, "hello", AlbumReviewSaveAutosave, now); got != revisionInsert {
+		t.Fatalf("expected insert, got %v", got)
+	}
+}
+
+func TestNextRevisionAction_ExplicitSaveInserts(t *testing.T) {
+	now := time.Now()
+	latest := &AlbumReviewRevisionDTO{Body: "hel", Status: models.ReviewStatusDraft, CreatedAt: now}
+	if got := nextRevisionAction(latest, "hello", AlbumReviewSaveDraft, now); got != revisionInsert {
+		t.Fatalf("expected insert, got %v", got)
+	}
+}
+
+func TestNextRevisionAction_PublishingSameBodyInserts(t *testing.T) {
+	now := time.Now()
+	latest := &AlbumReviewRevisionDTO{Body: "hello", Status: models.ReviewStatusDraft, CreatedAt: now}
+	if got := nextRevisionAction(latest, "hello", AlbumReviewSavePublish, now); got != revisionInsert {
+		t.Fatalf("expected publishing to record a revision, got %v", got)
+	}
+}
+
+// --- RenderMarkdown ---
+
+func TestRenderMarkdown_Formatting(t *testing.T) {
+	got := RenderMarkdown("**bold** and _italic_\n\n- one\n- two")
+	for _, want := range []string{"<strong>bold</strong>", "<em>italic</em>", "<li>one</li>"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in %q", want, got)
+		}
+	}
+}
+
+func TestRenderMarkdown_StripsRawHTML(t *testing.T) {
+	got := RenderMarkdown("hi <script>alert(1)</script>\n\n<img src=x onerror=alert(1)>")
+	if strings.Contains(got, "<script") || strings.Contains(got, "<img") {
+		t.Fatalf("expected raw HTML to be removed, got %q", got)
+	}
+}
+
+func TestRenderMarkdown_DropsDangerousLinks(t *testing.T) {
+	got := RenderMarkdown("[click](javascript:alert(1))")
+	if strings.Contains(got, "javascript:") {
+		t.Fatalf("expected javascript link to be dropped, got %q", got)
+	}
+}
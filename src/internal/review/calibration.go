@@ -0,0 +1,311 @@
+package review
+
+import (
+	"errors"
+	"fmt"
+	"github.com/alecdray/wax/src/internal/core/utils"
+	"math"
+	"sort"
+	"time"
+)
+
+// calibrationBucketCount is how many equal slices of the scale the rating
+// distribution is split into.
+const calibrationBucketCount = 10
+
+var ErrInvalidCalibrationTarget = errors.New("invalid calibration target")
+
+// CalibrationTarget is the bell curve ratings are re-calibrated against, on
+// the canonical 0–10 scale.
+type CalibrationTarget struct {
+	Key         string
+	Name        string
+	Description string
+	Mean        float64
+	Spread      float64
+}
+
+const CalibrationTargetCustom = "custom"
+
+var CalibrationTargets = []CalibrationTarget{
+	{
+		Key:         "centered",
+		Name:        "Centered",
+		Description: "The average album lands mid-scale, with room above and below",
+		Mean:        5.5,
+		Spread:      1.5,
+	},
+	{
+		Key:         "critic",
+		Name:        "Critic",
+		Description: "Publication-style: most albums in the 6s and 7s, 9s are rare",
+		Mean:        6.8,
+		Spread:      1.2,
+	},
+	{
+		Key:         "wide",
+		Name:        "Wide",
+		Description: "Uses the whole scale, so differences between albums stand out",
+		Mean:        5.0,
+		Spread:      2.2,
+	},
+}
+
+func DefaultCalibrationTarget() CalibrationTarget {
+	return CalibrationTargets[0]
+}
+
+func GetCalibrationTarget(key string) (CalibrationTarget, bool) {
+	for _, target := range CalibrationTargets {
+		if target.Key == key {
+			return target, true
+		}
+	}
+	return CalibrationTarget{}, false
+}
+
+// NewCustomCalibrationTarget builds a target from a mean and spread given on
+// the profile's scale.
+func NewCustomCalibrationTarget(profile RatingProfile, mean, spread float64) (CalibrationTarget, error) {
+	target := CalibrationTarget{
+		Key:    CalibrationTargetCustom,
+		Name:   "Custom",
+		Mean:   profile.FromScale(mean),
+		Spread: spread * (canonicalRatingMax - canonicalRatingMin) / (profile.ScaleMax - profile.ScaleMin),
+	}
+	if target.Mean < canonicalRatingMin || target.Mean > canonicalRatingMax {
+		return CalibrationTarget{}, fmt.Errorf("%w: the mean must fall within your scale", ErrInvalidCalibrationTarget)
+	}
+	if target.Spread <= 0 || target.Spread > (canonicalRatingMax-canonicalRatingMin)/2 {
+		return CalibrationTarget{}, fmt.Errorf("%w: the spread must be positive and at most half your scale", ErrInvalidCalibrationTarget)
+	}
+	return target, nil
+}
+
+// quantile returns the score at the given fraction (0–1, exclusive) of the
+// target curve, kept on the canonical scale.
+func (t CalibrationTarget) quantile(fraction float64) float64 {
+	z := math.Sqrt2 * math.Erfinv(2*fraction-1)
+	return utils.Clamp(t.Mean+z*t.Spread, canonicalRatingMin, canonicalRatingMax)
+}
+
+// CalibrationRating is one rating log entry as seen by calibration.
+type CalibrationRating struct {
+	RatingLogID string
+	AlbumID     string
+	AlbumTitle  string
+	Artists     string
+	Rating      float64
+	RatedAt     time.Time
+}
+
+type CalibrationBucket struct {
+	// Min and Max bound the bucket on the canonical scale; the last bucket
+	// includes its Max.
+	Min   float64
+	Max   float64
+	Count int
+}
+
+type CalibrationYear struct {
+	Year  int
+	Count int
+	Mean  float64
+	// Drift is the change in mean from the previous year with ratings, nil
+	// for the first year.
+	Drift *float64
+}
+
+// CalibrationAlbum is an album's current rating placed within the user's
+// distribution, with the score it would get on the target curve.
+type CalibrationAlbum struct {
+	CalibrationRating
+	// Percentile is the share of current ratings below this one, counting
+	// ties as half, from 0 to 100.
+	Percentile float64
+	Suggested  float64
+}
+
+// Changed reports whether the suggested score differs from the current one
+// once both are shown on the profile's scale.
+func (a CalibrationAlbum) Changed(profile RatingProfile) bool {
+	return profile.Display(a.Rating) != profile.Display(a.Suggested)
+}
+
+type CalibrationReport struct {
+	Target  CalibrationTarget
+	Count   int
+	Mean    float64
+	Median  float64
+	StdDev  float64
+	Buckets []CalibrationBucket
+	Years   []CalibrationYear
+	// Albums holds each album's current rating, highest first.
+	Albums []CalibrationAlbum
+}
+
+// NewCalibrationReport summarizes a user's rating log. The distribution and
+// suggestions use only each album's latest entry; drift by year uses every
+// entry, since re-ratings are part of how a scale moves. Entries must be in
+// the order they were logged.
+func NewCalibrationReport(log []CalibrationRating, target CalibrationTarget, profile RatingProfile) CalibrationReport {
+	report := CalibrationReport{
+		Target: target,
+		Years:  calibrationYears(log),
+	}
+
+	latestByAlbum := map[string]CalibrationRating{}
+	for _, entry := range log {
+		latestByAlbum[entry.AlbumID] = entry
+	}
+	if len(latestByAlbum) == 0 {
+		return report
+	}
+
+	current := make([]CalibrationRating, 0, len(latestByAlbum))
+	for _, entry := range latestByAlbum {
+		current = append(current, entry)
+	}
+	sort.Slice(current, func(i, j int) bool {
+		if current[i].Rating != current[j].Rating {
+			return current[i].Rating > current[j].Rating
+		}
+		return current[i].AlbumTitle < current[j].AlbumTitle
+	})
+
+	ratings := make([]float64, len(current))
+	for i, entry := range current {
+		ratings[i] = entry.Rating
+	}
+	report.Count = len(ratings)
+	report.Mean, report.StdDev = meanAndStdDev(ratings)
+	report.Median = median(ratings)
+	report.Buckets = calibrationBuckets(ratings)
+
+	report.Albums = make([]CalibrationAlbum, len(current))
+	for i, entry := range current {
+		fraction := percentileFraction(ratings, entry.Rating)
+		report.Albums[i] = CalibrationAlbum{
+			CalibrationRating: entry,
+			Percentile:        fraction * 100,
+			Suggested:         profile.FromScale(profile.Display(target.quantile(fraction))),
+		}
+	}
+
+	return report
+}
+
+// Changes returns the albums whose suggested score differs from their
+// current one on the profile's scale.
+func (r CalibrationReport) Changes(profile RatingProfile) []CalibrationAlbum {
+	var changes []CalibrationAlbum
+	for _, album := range r.Albums {
+		if album.Changed(profile) {
+			changes = append(changes, album)
+		}
+	}
+	return changes
+}
+
+// MaxBucketCount is the tallest bar in the distribution, for scaling.
+func (r CalibrationReport) MaxBucketCount() int {
+	highest := 0
+	for _, bucket := range r.Buckets {
+		highest = max(highest, bucket.Count)
+	}
+	return highest
+}
+
+// percentileFraction is the mid-rank of value among ratings: the share below
+// it plus half the share equal to it. Equal ratings share a fraction, and the
+// result never reaches 0 or 1.
+func percentileFraction(ratings []float64, value float64) float64 {
+	below, equal := 0, 0
+	for _, rating := range ratings {
+		if rating < value {
+			below++
+		} else if rating == value {
+			equal++
+		}
+	}
+	return (float64(below) + float64(equal)/2) / float64(len(ratings))
+}
+
+func meanAndStdDev(values []float64) (float64, float64) {
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	mean := sum / float64(len(values))
+
+	variance := 0.0
+	for _, value := range values {
+		variance += (value - mean) * (value - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func calibrationBuckets(ratings []float64) []CalibrationBucket {
+	width := (canonicalRatingMax - canonicalRatingMin) / calibrationBucketCount
+	buckets := make([]CalibrationBucket, calibrationBucketCount)
+	for i := range buckets {
+		buckets[i].Min = canonicalRatingMin + float64(i)*width
+		buckets[i].Max = buckets[i].Min + width
+	}
+	for _, rating := range ratings {
+		index := int((rating - canonicalRatingMin) / width)
+		buckets[utils.Clamp(index, 0, calibrationBucketCount-1)].Count++
+	}
+	return buckets
+}
+
+func calibrationYears(log []CalibrationRating) []CalibrationYear {
+	sums := map[int]float64{}
+	counts := map[int]int{}
+	for _, entry := range log {
+		year := entry.RatedAt.Year()
+		sums[year] += entry.Rating
+		counts[year]++
+	}
+
+	years := make([]CalibrationYear, 0, len(counts))
+	for year, count := range counts {
+		years = append(years, CalibrationYear{
+			Year:  year,
+			Count: count,
+			Mean:  sums[year] / float64(count),
+		})
+	}
+	sort.Slice(years, func(i, j int) bool { return years[i].Year < years[j].Year })
+
+	for i := 1; i < len(years); i++ {
+		drift := years[i].Mean - years[i-1].Mean
+		years[i].Drift = &drift
+	}
+	return years
+}
+
+// ordinal renders 1 as "1st", 22 as "22nd" and so on.
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
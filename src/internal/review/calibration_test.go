@@ -0,0 +1,172 @@
+package review
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func calibrationLog(ratings ...float64) []CalibrationRating {
+	log := make([]CalibrationRating, len(ratings))
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, rating := range ratings {
+		log[i] = CalibrationRating{
+			RatingLogID: string(rune('a' + i)),
+			AlbumID:     string(rune('A' + i)),
+			AlbumTitle:  string(rune('A' + i)),
+			Rating:      rating,
+			RatedAt:     start.AddDate(0, 0, i),
+		}
+	}
+	return log
+}
+
+func TestNewCalibrationReport_Empty(t *testing.T) {
+	report := NewCalibrationReport(nil, DefaultCalibrationTarget(), DefaultRatingProfile())
+	if report.Count != 0 || len(report.Albums) != 0 || len(report.Years) != 0 {
+		t.Errorf("expected an empty report, got %+v", report)
+	}
+}
+
+func TestNewCalibrationReport_Summary(t *testing.T) {
+	report := NewCalibrationReport(calibrationLog(6, 7, 8, 9), DefaultCalibrationTarget(), DefaultRatingProfile())
+
+	if report.Count != 4 {
+		t.Errorf("Count = %d, want 4", report.Count)
+	}
+	if math.Abs(report.Mean-7.5) > 1e-9 {
+		t.Errorf("Mean = %v, want 7.5", report.Mean)
+	}
+	if math.Abs(report.Median-7.5) > 1e-9 {
+		t.Errorf("Median = %v, want 7.5", report.Median)
+	}
+	if math.Abs(report.StdDev-math.Sqrt(1.25)) > 1e-9 {
+		t.Errorf("StdDev = %v, want %v", report.StdDev, math.Sqrt(1.25))
+	}
+
+	if len(report.Buckets) != calibrationBucketCount {
+		t.Fatalf("expected %d buckets, got %d", calibrationBucketCount, len(report.Buckets))
+	}
+	for i, want := range []int{0, 0, 0, 0, 0, 0, 1, 1, 1, 1} {
+		if report.Buckets[i].Count != want {
+			t.Errorf("bucket %d count = %d, want %d", i, report.Buckets[i].Count, want)
+		}
+	}
+}
+
+func TestNewCalibrationReport_TopBucketIncludesMax(t *testing.T) {
+	report := NewCalibrationReport(calibrationLog(10), DefaultCalibrationTarget(), DefaultRatingProfile())
+	if report.Buckets[calibrationBucketCount-1].Count != 1 {
+		t.Error("expected a 10 to land in the top bucket")
+	}
+}
+
+func TestNewCalibrationReport_UsesLatestEntryPerAlbum(t *testing.T) {
+	log := calibrationLog(5, 8)
+	rerating := log[0]
+	rerating.RatingLogID = "z"
+	rerating.Rating = 9
+	rerating.RatedAt = rerating.RatedAt.AddDate(1, 0, 0)
+	log = append(log, rerating)
+
+	report := NewCalibrationReport(log, DefaultCalibrationTarget(), DefaultRatingProfile())
+	if report.Count != 2 {
+		t.Fatalf("Count = %d, want 2", report.Count)
+	}
+	if report.Albums[0].RatingLogID != "z" || report.Albums[0].Rating != 9 {
+		t.Errorf("expected the re-rating to be the album's current score, got %+v", report.Albums[0])
+	}
+}
+
+func TestNewCalibrationReport_Percentiles(t *testing.T) {
+	report := NewCalibrationReport(calibrationLog(2, 5, 5, 9), DefaultCalibrationTarget(), DefaultRatingProfile())
+
+	want := map[float64]float64{9: 87.5, 5: 50, 2: 12.5}
+	for _, album := range report.Albums {
+		if math.Abs(album.Percentile-want[album.Rating]) > 1e-9 {
+			t.Errorf("percentile of %v = %v, want %v", album.Rating, album.Percentile, want[album.Rating])
+		}
+	}
+}
+
+func TestNewCalibrationReport_SuggestionsFollowTheTargetCurve(t *testing.T) {
+	target := CalibrationTarget{Key: "test", Name: "Test", Mean: 5, Spread: 1.5}
+	// Everything bunched up high, as ratings drift upward.
+	report := NewCalibrationReport(calibrationLog(8, 8.5, 9, 9.5, 10), target, DefaultRatingProfile())
+
+	for i := 1; i < len(report.Albums); i++ {
+		if report.Albums[i].Suggested > report.Albums[i-1].Suggested {
+			t.Errorf("suggestions should keep the albums' order, got %v above %v", report.Albums[i].Suggested, report.Albums[i-1].Suggested)
+		}
+	}
+
+	middle := report.Albums[2]
+	if middle.Rating != 9 || middle.Suggested != 5 {
+		t.Errorf("the median album should land on the target mean, got %v -> %v", middle.Rating, middle.Suggested)
+	}
+	if len(report.Changes(DefaultRatingProfile())) != len(report.Albums) {
+		t.Error("expected every album to change")
+	}
+}
+
+func TestNewCalibrationReport_SuggestionsSnapToProfileStep(t *testing.T) {
+	profile := fiveStarProfile(t)
+	report := NewCalibrationReport(calibrationLog(3, 6, 7, 9), DefaultCalibrationTarget(), profile)
+	for _, album := range report.Albums {
+		if profile.FromScale(profile.Display(album.Suggested)) != album.Suggested {
+			t.Errorf("suggestion %v is not on the profile's step", album.Suggested)
+		}
+	}
+}
+
+func TestNewCalibrationReport_DriftByYear(t *testing.T) {
+	log := []CalibrationRating{
+		{AlbumID: "a", Rating: 6, RatedAt: time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{AlbumID: "b", Rating: 7, RatedAt: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{AlbumID: "c", Rating: 8, RatedAt: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{AlbumID: "a", Rating: 9, RatedAt: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	report := NewCalibrationReport(log, DefaultCalibrationTarget(), DefaultRatingProfile())
+
+	if len(report.Years) != 2 {
+		t.Fatalf("expected 2 years, got %d", len(report.Years))
+	}
+	first, second := report.Years[0], report.Years[1]
+	if first.Year != 2023 || first.Count != 2 || first.Mean != 6.5 || first.Drift != nil {
+		t.Errorf("unexpected first year %+v", first)
+	}
+	if second.Year != 2025 || second.Count != 2 || second.Mean != 8.5 {
+		t.Errorf("unexpected second year %+v", second)
+	}
+	if second.Drift == nil || *second.Drift != 2 {
+		t.Errorf("expected drift of +2, got %v", second.Drift)
+	}
+}
+
+func TestNewCustomCalibrationTarget(t *testing.T) {
+	profile := fiveStarProfile(t)
+
+	target, err := NewCustomCalibrationTarget(profile, 3, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.Mean != 6 || target.Spread != 2 {
+		t.Errorf("expected mean 6 and spread 2 on the canonical scale, got %v and %v", target.Mean, target.Spread)
+	}
+
+	for _, c := range []struct{ mean, spread float64 }{{6, 1}, {3, 0}, {3, 3}} {
+		if _, err := NewCustomCalibrationTarget(profile, c.mean, c.spread); !errors.Is(err, ErrInvalidCalibrationTarget) {
+			t.Errorf("mean %v spread %v: expected ErrInvalidCalibrationTarget, got %v", c.mean, c.spread, err)
+		}
+	}
+}
+
+func TestOrdinal(t *testing.T) {
+	cases := map[int]string{1: "1st", 2: "2nd", 3: "3rd", 4: "4th", 11: "11th", 12: "12th", 13: "13th", 21: "21st", 52: "52nd", 100: "100th"}
+	for n, want := range cases {
+		if got := ordinal(n); got != want {
+			t.Errorf("ordinal(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
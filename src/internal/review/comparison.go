@@ -0,0 +1,93 @@
+package review
+
+import (
+	"math"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+)
+
+// ComparisonCandidate is an album the user has already rated and can be
+// compared against.
+type ComparisonCandidate struct {
+	AlbumID  string
+	Title    string
+	Artists  string
+	ImageURL string
+	Rating   float64
+}
+
+// ComparisonSearch is a binary search for where an album belongs among
+// candidates sorted by ascending rating. Lo and Hi are the inclusive bounds
+// of the candidates that still need comparing.
+type ComparisonSearch struct {
+	Lo int
+	Hi int
+	// Tied is set when the album was judged equal to the candidate at Lo.
+	Tied bool
+}
+
+func NewComparisonSearch(candidates int) ComparisonSearch {
+	return ComparisonSearch{Lo: 0, Hi: candidates - 1}
+}
+
+// Done reports whether the album's position has been found.
+func (s ComparisonSearch) Done() bool {
+	return s.Tied || s.Lo > s.Hi
+}
+
+// Mid is the index of the next candidate to compare against.
+func (s ComparisonSearch) Mid() int {
+	return s.Lo + (s.Hi-s.Lo)/2
+}
+
+// Remaining is the most comparisons that may still be asked.
+func (s ComparisonSearch) Remaining() int {
+	if s.Done() {
+		return 0
+	}
+	return int(math.Floor(math.Log2(float64(s.Hi-s.Lo+1)))) + 1
+}
+
+// Apply narrows the search using the outcome of comparing the album against
+// the candidate at Mid.
+func (s ComparisonSearch) Apply(outcome models.ComparisonOutcome) ComparisonSearch {
+	mid := s.Mid()
+	switch outcome {
+	case models.ComparisonOutcomeBetter:
+		s.Lo = mid + 1
+	case models.ComparisonOutcomeWorse:
+		s.Hi = mid - 1
+	case models.ComparisonOutcomeEqual:
+		s.Lo = mid
+		s.Hi = mid
+		s.Tied = true
+	}
+	return s
+}
+
+// Score places the album between its neighbours in the candidates' rating
+// distribution. It returns nil until the search is done or when there is
+// nothing to compare against.
+func (s ComparisonSearch) Score(candidates []ComparisonCandidate) *float64 {
+	if !s.Done() || len(candidates) == 0 {
+		return nil
+	}
+
+	var score float64
+	if s.Tied {
+		score = candidates[s.Lo].Rating
+	} else {
+		// Lo is the insertion index: everything below it is worse, everything
+		// from it onwards is better.
+		below, above := 0.0, ratingCeiling
+		if s.Lo > 0 {
+			below = candidates[s.Lo-1].Rating
+		}
+		if s.Lo < len(candidates) {
+			above = candidates[s.Lo].Rating
+		}
+		score = (below + above) / 2
+	}
+
+	score = math.Round(score*10) / 10
+	return &score
+}
@@ -0,0 +1,100 @@
+package review
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alecdray/wax/src/internal/core/db/models"
+)
+
+func makeCandidates(ratings ...float64) []ComparisonCandidate {
+	candidates := make([]ComparisonCandidate, len(ratings))
+	for i, rating := range ratings {
+		candidates[i] = ComparisonCandidate{AlbumID: string(rune('a' + i)), Rating: rating}
+	}
+	return candidates
+}
+
+// runComparisonSearch answers every comparison as if the album's true rating
+// were target, returning the score and the number of comparisons asked.
+func runComparisonSearch(candidates []ComparisonCandidate, target float64) (*float64, int) {
+	search := NewComparisonSearch(len(candidates))
+	steps := 0
+	for !search.Done() {
+		opponent := candidates[search.Mid()]
+		switch {
+		case target > opponent.Rating:
+			search = search.Apply(models.ComparisonOutcomeBetter)
+		case target < opponent.Rating:
+			search = search.Apply(models.ComparisonOutcomeWorse)
+		default:
+			search = search.Apply(models.ComparisonOutcomeEqual)
+		}
+		steps++
+	}
+	return search.Score(candidates), steps
+}
+
+// --- ComparisonSearch ---
+
+func TestComparisonSearch_PlacesBetweenNeighbours(t *testing.T) {
+	candidates := makeCandidates(3.0, 5.0, 6.5, 7.0, 8.0, 9.0)
+	score, _ := runComparisonSearch(candidates, 7.5)
+	if score == nil || math.Abs(*score-7.5) > 0.001 {
+		t.Fatalf("expected score 7.5 between 7.0 and 8.0, got %v", score)
+	}
+}
+
+func TestComparisonSearch_TieTakesOpponentRating(t *testing.T) {
+	candidates := makeCandidates(3.0, 5.0, 6.5, 7.0, 8.0)
+	score, _ := runComparisonSearch(candidates, 6.5)
+	if score == nil || *score != 6.5 {
+		t.Fatalf("expected tied score 6.5, got %v", score)
+	}
+}
+
+func TestComparisonSearch_BetterThanAllUsesCeiling(t *testing.T) {
+	candidates := makeCandidates(4.0, 6.0, 8.0)
+	score, _ := runComparisonSearch(candidates, 9.9)
+	if score == nil || *score != 9.0 {
+		t.Fatalf("expected score 9.0 halfway to the ceiling, got %v", score)
+	}
+}
+
+func TestComparisonSearch_WorseThanAllUsesZero(t *testing.T) {
+	candidates := makeCandidates(4.0, 6.0, 8.0)
+	score, _ := runComparisonSearch(candidates, 1.0)
+	if score == nil || *score != 2.0 {
+		t.Fatalf("expected score 2.0 halfway to zero, got %v", score)
+	}
+}
+
+func TestComparisonSearch_IsLogarithmic(t *testing.T) {
+	ratings := make([]float64, 100)
+	for i := range ratings {
+		ratings[i] = float64(i) / 10
+	}
+	candidates := makeCandidates(ratings...)
+	initial := NewComparisonSearch(len(candidates)).Remaining()
+	_, steps := runComparisonSearch(candidates, 5.55)
+	if steps > initial || initial > 7 {
+		t.Fatalf("expected at most %d (<= 7) comparisons, took %d", initial, steps)
+	}
+}
+
+func TestComparisonSearch_NoCandidates(t *testing.T) {
+	search := NewComparisonSearch(0)
+	if !search.Done() {
+		t.Fatal("expected search over no candidates to be done")
+	}
+	if score := search.Score(nil); score != nil {
+		t.Fatalf("expected no score without candidates, got %v", *score)
+	}
+}
+
+func TestComparisonSearch_ScoreNilUntilDone(t *testing.T) {
+	candidates := makeCandidates(4.0, 6.0, 8.0)
+	if score := NewComparisonSearch(len(candidates)).Score(candidates); score != nil {
+		t.Fatalf("expected no score before comparing, got %v", *score)
+	}
+}
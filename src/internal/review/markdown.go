@@ -0,0 +1,28 @@
+package review
+
+import (
+	"bytes"
+	"html"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	gmhtml "github.com/yuin/goldmark/renderer/html"
+)
+
+// markdown renders review bodies. Raw HTML is left out and links with
+// dangerous schemes (javascript:, data:, ...) are dropped, since goldmark's
+// unsafe mode is never enabled.
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithRendererOptions(gmhtml.WithHardWraps()),
+)
+
+// RenderMarkdown converts a review body to HTML that is safe to embed in a
+// page. If rendering fails the escaped source is returned instead.
+func RenderMarkdown(source string) string {
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(source), &buf); err != nil {
+		return "<p>" + html.EscapeString(source) + "</p>"
+	}
+	return buf.String()
+}
@@ -0,0 +1,306 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"github.com/alecdray/wax/src/internal/core/utils"
+)
+
+// Ratings are always stored on a canonical 0–10 scale. A RatingProfile
+// describes how a user sees and enters them: the scale they rate on, the
+// labels for each band of that scale, and the questionnaire that recommends
+// a score. Converting at the edges means existing ratings keep their meaning
+// when a user changes their profile.
+const (
+	canonicalRatingMin = 0.0
+	canonicalRatingMax = 10.0
+)
+
+var ErrInvalidRatingProfile = errors.New("invalid rating profile")
+
+type RatingProfile struct {
+	// ScaleMin and ScaleMax bound the scale the user rates on.
+	ScaleMin float64 `json:"scaleMin"`
+	ScaleMax float64 `json:"scaleMax"`
+	// Step is the smallest increment a rating can be entered in.
+	Step float64 `json:"step"`
+	// CurveExponent controls how much extreme questionnaire answers are
+	// amplified. Valid range 0.0–1.0, where 1.0 is fully linear.
+	CurveExponent float64 `json:"curveExponent"`
+	// Floor and Ceiling are the lowest and highest ratings the questionnaire
+	// can recommend, on the profile's scale.
+	Floor   float64 `json:"floor"`
+	Ceiling float64 `json:"ceiling"`
+	// Bands label ranges of the profile's scale, ordered by MinValue.
+	Bands []RatingKeyEntry `json:"bands"`
+	// Questions make up the rating questionnaire.
+	Questions RatingQuestions `json:"questions"`
+}
+
+// DefaultRatingProfile is the built-in 0–10 profile used until a user
+// customizes their own.
+func DefaultRatingProfile() RatingProfile {
+	bands := make([]RatingKeyEntry, len(RatingKey))
+	copy(bands, RatingKey)
+	questions := make(RatingQuestions, len(RatingRecommenderQuestions))
+	copy(questions, RatingRecommenderQuestions)
+
+	return RatingProfile{
+		ScaleMin:      canonicalRatingMin,
+		ScaleMax:      canonicalRatingMax,
+		Step:          0.1,
+		CurveExponent: ratingCurveExponent,
+		Floor:         ratingFloor,
+		Ceiling:       ratingCeiling,
+		Bands:         bands,
+		Questions:     questions,
+	}
+}
+
+// defaultRatingProfile is shared by the helpers that only read the default
+// profile, so they don't rebuild it on every call.
+var defaultRatingProfile = DefaultRatingProfile()
+
+type RatingProfilePreset struct {
+	Key     string
+	Name    string
+	Profile func() RatingProfile
+}
+
+var RatingProfilePresets = []RatingProfilePreset{
+	{
+		Key:     "ten-point",
+		Name:    "10-point (0–10)",
+		Profile: DefaultRatingProfile,
+	},
+	{
+		Key:  "five-star",
+		Name: "5-star (0–5)",
+		Profile: func() RatingProfile {
+			p := DefaultRatingProfile()
+			p.ScaleMin, p.ScaleMax, p.Step = 0, 5, 0.5
+			p.Floor, p.Ceiling = 1, 5
+			p.Bands = []RatingKeyEntry{
+				{MinValue: 0, Label: RatingLabelDOA},
+				{MinValue: 1.5, Label: RatingLabelNope},
+				{MinValue: 2, Label: RatingLabelNotForMe},
+				{MinValue: 3, Label: RatingLabelLukewarm},
+				{MinValue: 3.5, Label: RatingLabelRecommended},
+				{MinValue: 4, Label: RatingLabelEssential},
+				{MinValue: 4.5, Label: RatingLabelInstantClassic},
+				{MinValue: 5, Label: RatingLabelMasterpiece},
+			}
+			return p.Normalize()
+		},
+	},
+	{
+		Key:  "hundred-point",
+		Name: "100-point (0–100)",
+		Profile: func() RatingProfile {
+			p := DefaultRatingProfile()
+			p.ScaleMin, p.ScaleMax, p.Step = 0, 100, 1
+			p.Floor, p.Ceiling = p.Floor*10, p.Ceiling*10
+			for i := range p.Bands {
+				p.Bands[i].MinValue *= 10
+			}
+			return p.Normalize()
+		},
+	},
+}
+
+func GetRatingProfilePreset(key string) (RatingProfile, bool) {
+	for _, preset := range RatingProfilePresets {
+		if preset.Key == key {
+			return preset.Profile(), true
+		}
+	}
+	return RatingProfile{}, false
+}
+
+// Normalize sorts the bands and derives each band's MaxValue from the start
+// of the next band, so users only have to choose where each band begins.
+func (p RatingProfile) Normalize() RatingProfile {
+	bands := make([]RatingKeyEntry, len(p.Bands))
+	copy(bands, p.Bands)
+	sort.SliceStable(bands, func(i, j int) bool {
+		return bands[i].MinValue < bands[j].MinValue
+	})
+	for i := range bands {
+		bands[i].Label = RatingLabel(strings.TrimSpace(string(bands[i].Label)))
+		if i < len(bands)-1 {
+			bands[i].MaxValue = p.roundToStep(bands[i+1].MinValue - p.Step)
+		} else {
+			bands[i].MaxValue = p.ScaleMax
+		}
+	}
+	p.Bands = bands
+	return p
+}
+
+func (p RatingProfile) Validate() error {
+	var errs []error
+	if p.ScaleMax <= p.ScaleMin {
+		errs = append(errs, errors.New("scale maximum must be greater than its minimum"))
+	}
+	if p.Step <= 0 || p.Step > p.ScaleMax-p.ScaleMin {
+		errs = append(errs, errors.New("step must be positive and fit within the scale"))
+	}
+	if p.CurveExponent <= 0 || p.CurveExponent > 1 {
+		errs = append(errs, errors.New("curve exponent must be between 0 and 1"))
+	}
+	if p.Floor < p.ScaleMin || p.Ceiling > p.ScaleMax || p.Floor >= p.Ceiling {
+		errs = append(errs, errors.New("questionnaire floor and ceiling must fall within the scale, floor first"))
+	}
+
+	if len(p.Bands) == 0 {
+		errs = append(errs, errors.New("at least one label band is required"))
+	}
+	for i, band := range p.Bands {
+		if band.Label == "" {
+			errs = append(errs, fmt.Errorf("band %d is missing a label", i+1))
+		}
+		if band.MinValue < p.ScaleMin || band.MinValue > p.ScaleMax {
+			errs = append(errs, fmt.Errorf("band %q starts outside the scale", band.Label))
+		}
+		if i > 0 && band.MinValue <= p.Bands[i-1].MinValue {
+			errs = append(errs, fmt.Errorf("band %q must start above the band before it", band.Label))
+		}
+	}
+	if len(p.Bands) > 0 && p.Bands[0].MinValue != p.ScaleMin {
+		errs = append(errs, errors.New("the first band must start at the bottom of the scale"))
+	}
+
+	if len(p.Questions) == 0 {
+		errs = append(errs, errors.New("at least one question is required"))
+	}
+	keys := make(map[RatingQuestionKey]bool, len(p.Questions))
+	for i, question := range p.Questions {
+		if question.Key == "" || keys[question.Key] {
+			errs = append(errs, fmt.Errorf("question %d needs a unique key", i+1))
+		}
+		keys[question.Key] = true
+		if strings.TrimSpace(question.Question) == "" {
+			errs = append(errs, fmt.Errorf("question %d is missing its text", i+1))
+		}
+		if len(question.Options) < 2 {
+			errs = append(errs, fmt.Errorf("question %d needs at least two options", i+1))
+		}
+		if question.Weight <= 0 {
+			errs = append(errs, fmt.Errorf("question %d needs a positive weight", i+1))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (p RatingProfile) roundToStep(value float64) float64 {
+	steps := math.Round((value - p.ScaleMin) / p.Step)
+	return roundPrecision(p.ScaleMin + steps*p.Step)
+}
+
+// roundPrecision trims floating point noise introduced by scale conversions.
+func roundPrecision(value float64) float64 {
+	return math.Round(value*1e9) / 1e9
+}
+
+// ToScale converts a canonical rating to the profile's scale without rounding
+// to the profile's step.
+func (p RatingProfile) ToScale(rating float64) float64 {
+	fraction := (rating - canonicalRatingMin) / (canonicalRatingMax - canonicalRatingMin)
+	return roundPrecision(p.ScaleMin + fraction*(p.ScaleMax-p.ScaleMin))
+}
+
+// FromScale converts a value on the profile's scale to a canonical rating.
+func (p RatingProfile) FromScale(value float64) float64 {
+	fraction := (value - p.ScaleMin) / (p.ScaleMax - p.ScaleMin)
+	return roundPrecision(canonicalRatingMin + fraction*(canonicalRatingMax-canonicalRatingMin))
+}
+
+// Display converts a canonical rating to the profile's scale, rounded to the
+// profile's step and clamped to its range.
+func (p RatingProfile) Display(rating float64) float64 {
+	return utils.Clamp(p.roundToStep(p.ToScale(rating)), p.ScaleMin, p.ScaleMax)
+}
+
+// Decimals is the number of decimal places needed to show a value at the
+// profile's step.
+func (p RatingProfile) Decimals() int {
+	decimals := 0
+	for step := p.Step; decimals < 4 && math.Abs(step-math.Round(step)) > 1e-9; step *= 10 {
+		decimals++
+	}
+	return decimals
+}
+
+// Format renders a canonical rating on the profile's scale.
+func (p RatingProfile) Format(rating float64) string {
+	return p.FormatValue(p.Display(rating))
+}
+
+// FormatValue renders a value that is already on the profile's scale.
+func (p RatingProfile) FormatValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', p.Decimals(), 64)
+}
+
+// Label returns the band label for a canonical rating.
+func (p RatingProfile) Label(rating float64) RatingLabel {
+	value := p.Display(rating)
+	for _, entry := range p.Bands {
+		if value >= entry.MinValue && value <= entry.MaxValue {
+			return entry.Label
+		}
+	}
+	return p.Bands[len(p.Bands)-1].Label
+}
+
+func (p RatingProfile) curvedValue(question RatingQuestion) float64 {
+	if len(question.Options) < 2 {
+		return 0
+	}
+	lowest, highest := question.Options[0].Value, question.Options[0].Value
+	for _, opt := range question.Options {
+		lowest = min(lowest, opt.Value)
+		highest = max(highest, opt.Value)
+	}
+	mid := float64(lowest+highest) / 2
+	half := float64(highest-lowest) / 2
+	normalized := utils.Clamp((float64(question.Value)-mid)/half, -1, 1)
+	return math.Copysign(math.Pow(math.Abs(normalized), p.CurveExponent), normalized)
+}
+
+// Rating recommends a canonical rating from answered questions, spreading the
+// weighted answers between the profile's floor and ceiling and rounding to the
+// profile's step.
+func (p RatingProfile) Rating(questions RatingQuestions) float64 {
+	var weighted, totalWeight float64
+	for _, question := range questions {
+		weighted += p.curvedValue(question) * question.Weight
+		totalWeight += question.Weight
+	}
+	if totalWeight == 0 {
+		return p.FromScale(p.Floor)
+	}
+	raw := weighted / totalWeight
+	value := p.Floor + ((raw+1)/2)*(p.Ceiling-p.Floor)
+	return p.FromScale(p.roundToStep(value))
+}
+
+type ratingProfileCtxKey struct{}
+
+func ContextWithRatingProfile(ctx context.Context, profile RatingProfile) context.Context {
+	return context.WithValue(ctx, ratingProfileCtxKey{}, profile)
+}
+
+// RatingProfileFromContext returns the profile attached to the request, or the
+// default profile when there is none.
+func RatingProfileFromContext(ctx context.Context) RatingProfile {
+	if profile, ok := ctx.Value(ratingProfileCtxKey{}).(RatingProfile); ok {
+		return profile
+	}
+	return DefaultRatingProfile()
+}
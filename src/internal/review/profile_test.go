@@ -0,0 +1,160 @@
+package review
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func fiveStarProfile(t *testing.T) RatingProfile {
+	t.Helper()
+	p, ok := GetRatingProfilePreset("five-star")
+	if !ok {
+		t.Fatal("expected five-star preset to exist")
+	}
+	return p
+}
+
+// --- RatingProfile scale conversion ---
+
+func TestRatingProfile_DefaultIsIdentity(t *testing.T) {
+	p := DefaultRatingProfile()
+	for _, rating := range []float64{0, 3.3, 7.5, 10} {
+		if got := p.Display(rating); math.Abs(got-rating) > 1e-9 {
+			t.Errorf("Display(%v) = %v, want %v", rating, got, rating)
+		}
+		if got := p.FromScale(rating); math.Abs(got-rating) > 1e-9 {
+			t.Errorf("FromScale(%v) = %v, want %v", rating, got, rating)
+		}
+	}
+}
+
+func TestRatingProfile_FiveStarDisplay(t *testing.T) {
+	p := fiveStarProfile(t)
+	cases := []struct {
+		rating float64
+		want   string
+	}{
+		{10, "5.0"},
+		{7.5, "4.0"}, // 3.75 rounds to the nearest half star
+		{7.0, "3.5"},
+		{0, "0.0"},
+	}
+	for _, c := range cases {
+		if got := p.Format(c.rating); got != c.want {
+			t.Errorf("Format(%v) = %q, want %q", c.rating, got, c.want)
+		}
+	}
+}
+
+func TestRatingProfile_ScaleRoundTrip(t *testing.T) {
+	p, _ := GetRatingProfilePreset("hundred-point")
+	for _, rating := range []float64{0, 1.5, 6.2, 10} {
+		if got := p.FromScale(p.ToScale(rating)); math.Abs(got-rating) > 1e-9 {
+			t.Errorf("round trip of %v gave %v", rating, got)
+		}
+	}
+	if got := p.Format(6.2); got != "62" {
+		t.Errorf("Format(6.2) = %q, want %q", got, "62")
+	}
+}
+
+// --- RatingProfile labels ---
+
+func TestRatingProfile_DefaultLabelsMatchRatingKey(t *testing.T) {
+	p := DefaultRatingProfile()
+	for _, entry := range RatingKey {
+		if got := p.Label(entry.MinValue); got != entry.Label {
+			t.Errorf("Label(%v) = %q, want %q", entry.MinValue, got, entry.Label)
+		}
+	}
+}
+
+func TestRatingProfile_FiveStarLabels(t *testing.T) {
+	p := fiveStarProfile(t)
+	if got := p.Label(10); got != RatingLabelMasterpiece {
+		t.Errorf("Label(10) = %q, want %q", got, RatingLabelMasterpiece)
+	}
+	if got := p.Label(8); got != RatingLabelEssential {
+		t.Errorf("Label(8) = %q, want %q", got, RatingLabelEssential)
+	}
+	if got := p.Label(0); got != RatingLabelDOA {
+		t.Errorf("Label(0) = %q, want %q", got, RatingLabelDOA)
+	}
+}
+
+func TestRatingProfile_NormalizeDerivesBandMax(t *testing.T) {
+	p := DefaultRatingProfile()
+	p.Bands = []RatingKeyEntry{
+		{MinValue: 5, Label: " Good "},
+		{MinValue: 0, Label: "Bad"},
+	}
+	p = p.Normalize()
+
+	if p.Bands[0].Label != "Bad" || p.Bands[1].Label != "Good" {
+		t.Fatalf("expected bands sorted and trimmed, got %+v", p.Bands)
+	}
+	if math.Abs(p.Bands[0].MaxValue-4.9) > 1e-9 {
+		t.Errorf("expected first band to end at 4.9, got %v", p.Bands[0].MaxValue)
+	}
+	if p.Bands[1].MaxValue != p.ScaleMax {
+		t.Errorf("expected last band to end at the scale max, got %v", p.Bands[1].MaxValue)
+	}
+}
+
+// --- RatingProfile.Validate ---
+
+func TestRatingProfile_PresetsAreValid(t *testing.T) {
+	for _, preset := range RatingProfilePresets {
+		if err := preset.Profile().Validate(); err != nil {
+			t.Errorf("preset %q is invalid: %v", preset.Key, err)
+		}
+	}
+}
+
+func TestRatingProfile_ValidateRejectsBadProfiles(t *testing.T) {
+	cases := []struct {
+		name   string
+		mutate func(p *RatingProfile)
+		want   string
+	}{
+		{"inverted scale", func(p *RatingProfile) { p.ScaleMax = p.ScaleMin }, "scale maximum"},
+		{"zero step", func(p *RatingProfile) { p.Step = 0 }, "step"},
+		{"ceiling out of scale", func(p *RatingProfile) { p.Ceiling = 11 }, "floor and ceiling"},
+		{"first band above min", func(p *RatingProfile) { p.Bands = p.Bands[1:] }, "first band"},
+		{"no questions", func(p *RatingProfile) { p.Questions = nil }, "at least one question"},
+		{"duplicate key", func(p *RatingProfile) { p.Questions[1].Key = p.Questions[0].Key }, "unique key"},
+	}
+	for _, c := range cases {
+		p := DefaultRatingProfile()
+		c.mutate(&p)
+		err := p.Validate()
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: expected error containing %q, got %v", c.name, c.want, err)
+		}
+	}
+}
+
+// --- RatingProfile.Rating ---
+
+func TestRatingProfile_RatingMatchesDefault(t *testing.T) {
+	withValues := make(RatingQuestions, len(RatingRecommenderQuestions))
+	for i, q := range RatingRecommenderQuestions {
+		withValues[i] = q.WithValue(4)
+	}
+	if got, want := DefaultRatingProfile().Rating(withValues), withValues.Rating(); got != want {
+		t.Fatalf("expected default profile rating %v, got %v", want, got)
+	}
+}
+
+func TestRatingProfile_FiveStarRatingIsCanonical(t *testing.T) {
+	p := fiveStarProfile(t)
+	withValues := make(RatingQuestions, len(p.Questions))
+	for i, q := range p.Questions {
+		withValues[i] = q.WithValue(5)
+	}
+	// All top answers hit the five-star ceiling, which is 10 canonically.
+	if got := p.Rating(withValues); math.Abs(got-10) > 1e-9 {
+		t.Fatalf("expected canonical 10, got %v", got)
+	}
+}
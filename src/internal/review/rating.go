@@ -2,7 +2,6 @@ package review
 
 import (
 	"math"
-	"github.com/alecdray/wax/src/internal/core/utils"
 )
 
 const (
@@ -43,16 +42,16 @@ func (k RatingQuestionKey) String() string {
 }
 
 type RatingQuestionOption struct {
-	Value int
-	Label string
+	Value int    `json:"value"`
+	Label string `json:"label"`
 }
 
 type RatingQuestion struct {
-	Key      RatingQuestionKey
-	Question string
-	Options  []RatingQuestionOption
-	Value    int
-	Weight   float64
+	Key      RatingQuestionKey      `json:"key"`
+	Question string                 `json:"question"`
+	Options  []RatingQuestionOption `json:"options"`
+	Value    int                    `json:"-"`
+	Weight   float64                `json:"weight"`
 }
 
 func (qs RatingQuestion) CurvedValue() float64 {
@@ -68,13 +67,9 @@ func (qs RatingQuestion) WithValue(value int) RatingQuestion {
 
 type RatingQuestions []RatingQuestion
 
+// Rating recommends a rating using the default profile.
 func (qs RatingQuestions) Rating() float64 {
-	var raw float64
-	for _, question := range qs {
-		raw += question.CurvedValue() * question.Weight
-	}
-	rating := ratingFloor + ((raw-1.0)/4.0)*(ratingCeiling-ratingFloor)
-	return math.Round(rating*10) / 10
+	return defaultRatingProfile.Rating(qs)
 }
 
 var RatingRecommenderQuestions RatingQuestions = RatingQuestions{
@@ -133,11 +128,11 @@ const (
 // RatingKeyEntry defines an inclusive rating range and its associated label.
 type RatingKeyEntry struct {
 	// MinValue is the lowest rating (inclusive) that maps to this label.
-	MinValue float64
+	MinValue float64 `json:"minValue"`
 	// MaxValue is the highest rating (inclusive) that maps to this label.
-	MaxValue float64
+	MaxValue float64 `json:"maxValue"`
 	// Label is the human-readable name for this rating range.
-	Label RatingLabel
+	Label RatingLabel `json:"label"`
 }
 
 var RatingKey = []RatingKeyEntry{
@@ -152,12 +147,76 @@ var RatingKey = []RatingKeyEntry{
 	{10.0, 10.0, RatingLabelMasterpiece},
 }
 
+// GetRatingLabel labels a rating using the default profile.
 func GetRatingLabel(rating float64) RatingLabel {
-	clampedRating := utils.Clamp(rating, 0, 10)
-	for _, entry := range RatingKey {
-		if clampedRating >= entry.MinValue && clampedRating <= entry.MaxValue {
-			return entry.Label
+	return defaultRatingProfile.Label(rating)
+}
+
+// RatingDimension names a sub-score that can be stored alongside the overall
+// rating of a log entry.
+type RatingDimension string
+
+const (
+	// RatingDimensionOverall refers to the entry's overall rating rather than a
+	// stored sub-score.
+	RatingDimensionOverall RatingDimension = "overall"
+	// RatingDimensionQuality is an objective assessment of the craft.
+	RatingDimensionQuality RatingDimension = "quality"
+	// RatingDimensionEnjoyment is how much the listener personally enjoys it.
+	RatingDimensionEnjoyment RatingDimension = "enjoyment"
+)
+
+// RatingSubDimensions are the sub-scores that can be recorded on a rating.
+var RatingSubDimensions = []RatingDimension{
+	RatingDimensionQuality,
+	RatingDimensionEnjoyment,
+}
+
+func (d RatingDimension) String() string {
+	return string(d)
+}
+
+func (d RatingDimension) Label() string {
+	switch d {
+	case RatingDimensionQuality:
+		return "Quality"
+	case RatingDimensionEnjoyment:
+		return "Enjoyment"
+	default:
+		return "Overall"
+	}
+}
+
+// ParseRatingDimension returns the dimension matching value, falling back to
+// RatingDimensionOverall for empty or unknown values.
+func ParseRatingDimension(value string) RatingDimension {
+	for _, d := range RatingSubDimensions {
+		if string(d) == value {
+			return d
 		}
 	}
-	return RatingKey[len(RatingKey)-1].Label
+	return RatingDimensionOverall
+}
+
+// RatingScores holds the sub-scores recorded for a rating log entry.
+type RatingScores map[RatingDimension]float64
+
+func (s RatingScores) Get(dimension RatingDimension) *float64 {
+	score, ok := s[dimension]
+	if !ok {
+		return nil
+	}
+	return &score
+}
+
+// RatingAnswers holds the questionnaire answers that produced a rating.
+type RatingAnswers map[RatingQuestionKey]int
+
+// Answers returns the values chosen for each question.
+func (qs RatingQuestions) Answers() RatingAnswers {
+	answers := make(RatingAnswers, len(qs))
+	for _, question := range qs {
+		answers[question.Key] = question.Value
+	}
+	return answers
 }
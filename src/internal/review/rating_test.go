@@ -1,6 +1,7 @@
 package review
 
 import (
+	"errors"
 	"math"
 	"testing"
 )
@@ -123,3 +124,77 @@ func TestGetRatingLabel_ClampsBelow0(t *testing.T) {
 		t.Fatalf("expected DOA for negative rating, got %q", got)
 	}
 }
+
+// --- RatingDimension ---
+
+func TestParseRatingDimension(t *testing.T) {
+	cases := []struct {
+		value string
+		want  RatingDimension
+	}{
+		{"quality", RatingDimensionQuality},
+		{"enjoyment", RatingDimensionEnjoyment},
+		{"overall", RatingDimensionOverall},
+		{"", RatingDimensionOverall},
+		{"bogus", RatingDimensionOverall},
+	}
+
+	for _, tc := range cases {
+		if got := ParseRatingDimension(tc.value); got != tc.want {
+			t.Errorf("ParseRatingDimension(%q) = %q, want %q", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestAlbumRatingDTOScore(t *testing.T) {
+	rating := 7.5
+	dto := &AlbumRatingDTO{
+		Rating: &rating,
+		Scores: RatingScores{RatingDimensionQuality: 8.0},
+	}
+
+	if got := dto.Score(RatingDimensionOverall); got == nil || *got != 7.5 {
+		t.Fatalf("expected overall score 7.5, got %v", got)
+	}
+	if got := dto.Score(RatingDimensionQuality); got == nil || *got != 8.0 {
+		t.Fatalf("expected quality score 8.0, got %v", got)
+	}
+	if got := dto.Score(RatingDimensionEnjoyment); got != nil {
+		t.Fatalf("expected no enjoyment score, got %v", *got)
+	}
+
+	var missing *AlbumRatingDTO
+	if got := missing.Score(RatingDimensionOverall); got != nil {
+		t.Fatalf("expected nil score for nil rating, got %v", *got)
+	}
+}
+
+// --- RatingInput.Validate ---
+
+func TestRatingInputValidate_RejectsOffScaleScores(t *testing.T) {
+	if err := (RatingInput{Rating: 10, Scores: RatingScores{RatingDimensionQuality: 0}}).Validate(); err != nil {
+		t.Fatalf("expected the ends of the scale to be valid, got %v", err)
+	}
+	if err := (RatingInput{Rating: 10.5}).Validate(); !errors.Is(err, ErrRatingOutOfRange) {
+		t.Errorf("expected a rating above 10 to be rejected, got %v", err)
+	}
+	if err := (RatingInput{Rating: 7, Scores: RatingScores{RatingDimensionEnjoyment: 40}}).Validate(); !errors.Is(err, ErrRatingOutOfRange) {
+		t.Errorf("expected an enjoyment score above 10 to be rejected, got %v", err)
+	}
+	if err := (RatingInput{Rating: 7, Scores: RatingScores{RatingDimensionQuality: -1}}).Validate(); !errors.Is(err, ErrRatingOutOfRange) {
+		t.Errorf("expected a negative quality score to be rejected, got %v", err)
+	}
+}
+
+// --- RatingQuestions.Answers ---
+
+func TestAnswers_CapturesQuestionValues(t *testing.T) {
+	qs := RatingQuestions{
+		RatingRecommenderQuestions[0].WithValue(4),
+		RatingRecommenderQuestions[1].WithValue(2),
+	}
+	answers := qs.Answers()
+	if len(answers) != 2 || answers[RatingQuestionConsistency] != 4 || answers[RatingQuestionImpact] != 2 {
+		t.Fatalf("unexpected answers: %v", answers)
+	}
+}
@@ -0,0 +1,90 @@
+package review
+
+import (
+	"errors"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"github.com/alecdray/wax/src/internal/core/timex"
+	"time"
+)
+
+const (
+	// revisitMaxStaleAfterDays caps the staleness threshold at ten years.
+	revisitMaxStaleAfterDays = 3650
+	// singleListenGracePeriod keeps a fresh rating out of the Revisit
+	// carousel, however few listens it came after, so it isn't suggested
+	// again the day it was made.
+	singleListenGracePeriod = 2 * timex.Week
+)
+
+var ErrInvalidRevisitRules = errors.New("invalid revisit rules")
+
+// RevisitRules are a user's thresholds for suggesting an album be re-rated.
+// A zero threshold turns its rule off.
+type RevisitRules struct {
+	// StaleAfterDays is how old the latest rating must be before plays since
+	// then count towards a revisit.
+	StaleAfterDays int `json:"staleAfterDays"`
+	// MinPlaysSince is how many track plays a stale rating needs since it
+	// was made, so albums that have dropped out of rotation are left alone.
+	MinPlaysSince int `json:"minPlaysSince"`
+	// MaxListensBefore flags ratings made after this many listening days or
+	// fewer.
+	MaxListensBefore int `json:"maxListensBefore"`
+}
+
+func DefaultRevisitRules() RevisitRules {
+	return RevisitRules{
+		StaleAfterDays:   365,
+		MinPlaysSince:    10,
+		MaxListensBefore: 1,
+	}
+}
+
+func (r RevisitRules) Validate() error {
+	var errs []error
+	if r.StaleAfterDays < 0 || r.StaleAfterDays > revisitMaxStaleAfterDays {
+		errs = append(errs, errors.New("stale rating age must be between 0 and 3650 days"))
+	}
+	if r.MinPlaysSince < 1 {
+		errs = append(errs, errors.New("plays since rating must be at least 1"))
+	}
+	if r.MaxListensBefore < 0 {
+		errs = append(errs, errors.New("listens before rating can't be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// RevisitCandidate is an album's latest rating with the listening activity
+// around it.
+type RevisitCandidate struct {
+	AlbumID     string
+	RatingLogID string
+	RatedAt     time.Time
+	// PlaysSince counts track plays after the rating.
+	PlaysSince int
+	// ListensBefore counts the days the album was played on up to the rating.
+	ListensBefore int
+}
+
+// Reason reports why a rating should be revisited, if at all. A stale rating
+// on an album still in rotation wins over one made after a single listen.
+// Ratings with no recorded listens before them aren't treated as snap
+// judgements, since the album may have been heard off Spotify.
+func (r RevisitRules) Reason(candidate RevisitCandidate, now time.Time) (models.RevisitReason, bool) {
+	age := now.Sub(candidate.RatedAt)
+
+	if r.StaleAfterDays > 0 &&
+		age >= time.Duration(r.StaleAfterDays)*timex.Day &&
+		candidate.PlaysSince >= r.MinPlaysSince {
+		return models.RevisitReasonStale, true
+	}
+
+	if r.MaxListensBefore > 0 &&
+		candidate.ListensBefore > 0 &&
+		candidate.ListensBefore <= r.MaxListensBefore &&
+		age >= singleListenGracePeriod {
+		return models.RevisitReasonSingleListen, true
+	}
+
+	return "", false
+}
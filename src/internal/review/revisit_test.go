@@ -0,0 +1,81 @@
+package review
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"github.com/alecdray/wax/src/internal/core/timex"
+)
+
+func TestRevisitRules_DefaultsAreValid(t *testing.T) {
+	if err := DefaultRevisitRules().Validate(); err != nil {
+		t.Errorf("default rules invalid: %v", err)
+	}
+}
+
+func TestRevisitRules_Validate(t *testing.T) {
+	cases := []struct {
+		name  string
+		rules RevisitRules
+		ok    bool
+	}{
+		{"rules off", RevisitRules{StaleAfterDays: 0, MinPlaysSince: 1, MaxListensBefore: 0}, true},
+		{"negative age", RevisitRules{StaleAfterDays: -1, MinPlaysSince: 1}, false},
+		{"age too large", RevisitRules{StaleAfterDays: 4000, MinPlaysSince: 1}, false},
+		{"no plays required", RevisitRules{StaleAfterDays: 30, MinPlaysSince: 0}, false},
+		{"negative listens", RevisitRules{StaleAfterDays: 30, MinPlaysSince: 1, MaxListensBefore: -1}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.rules.Validate()
+			if c.ok && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !c.ok && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestRevisitRules_Reason(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	rules := DefaultRevisitRules()
+	daysAgo := func(days int) time.Time {
+		return now.Add(-time.Duration(days) * timex.Day)
+	}
+
+	cases := []struct {
+		name      string
+		candidate RevisitCandidate
+		want      models.RevisitReason
+		ok        bool
+	}{
+		{"old rating still in rotation", RevisitCandidate{RatedAt: daysAgo(400), PlaysSince: 12, ListensBefore: 5}, models.RevisitReasonStale, true},
+		{"old rating not played since", RevisitCandidate{RatedAt: daysAgo(400), PlaysSince: 3, ListensBefore: 5}, "", false},
+		{"recent rating in rotation", RevisitCandidate{RatedAt: daysAgo(100), PlaysSince: 50, ListensBefore: 5}, "", false},
+		{"rated after one listen", RevisitCandidate{RatedAt: daysAgo(30), ListensBefore: 1}, models.RevisitReasonSingleListen, true},
+		{"rated after one listen, still fresh", RevisitCandidate{RatedAt: daysAgo(3), ListensBefore: 1}, "", false},
+		{"rated with no recorded listens", RevisitCandidate{RatedAt: daysAgo(30), ListensBefore: 0}, "", false},
+		{"rated after several listens", RevisitCandidate{RatedAt: daysAgo(30), ListensBefore: 4}, "", false},
+		{"stale wins over single listen", RevisitCandidate{RatedAt: daysAgo(400), PlaysSince: 20, ListensBefore: 1}, models.RevisitReasonStale, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, ok := rules.Reason(c.candidate, now)
+			if ok != c.ok || got != c.want {
+				t.Errorf("Reason() = (%q, %v), want (%q, %v)", got, ok, c.want, c.ok)
+			}
+		})
+	}
+}
+
+func TestRevisitRules_ReasonDisabledRules(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	rules := RevisitRules{StaleAfterDays: 0, MinPlaysSince: 1, MaxListensBefore: 0}
+	candidate := RevisitCandidate{RatedAt: now.Add(-1000 * timex.Day), PlaysSince: 100, ListensBefore: 1}
+	if reason, ok := rules.Reason(candidate, now); ok {
+		t.Errorf("expected no reason with both rules off, got %q", reason)
+	}
+}
@@ -3,8 +3,15 @@ package review
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/alecdray/wax/src/internal/core/db"
+	"github.com/alecdray/wax/src/internal/core/db/models"
 	"github.com/alecdray/wax/src/internal/core/db/sqlc"
+	"log/slog"
+	"math"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,6 +23,8 @@ type AlbumRatingDTO struct {
 	AlbumID   string
 	Rating    *float64
 	Note      *string
+	Scores    RatingScores
+	Answers   RatingAnswers
 	CreatedAt time.Time
 }
 
@@ -24,6 +33,8 @@ func NewAlbumRatingDTOFromModel(model sqlc.AlbumRatingLog) *AlbumRatingDTO {
 		ID:        model.ID,
 		UserID:    model.UserID,
 		AlbumID:   model.AlbumID,
+		Scores:    RatingScores{},
+		Answers:   RatingAnswers{},
 		CreatedAt: model.CreatedAt,
 	}
 
@@ -36,6 +47,40 @@ func NewAlbumRatingDTOFromModel(model sqlc.AlbumRatingLog) *AlbumRatingDTO {
 	return dto
 }
 
+// Score returns the entry's value on the given dimension, or nil when that
+// sub-score was not recorded.
+func (dto *AlbumRatingDTO) Score(dimension RatingDimension) *float64 {
+	if dto == nil {
+		return nil
+	}
+	if dimension == RatingDimensionOverall {
+		return dto.Rating
+	}
+	return dto.Scores.Get(dimension)
+}
+
+type RatingInput struct {
+	Rating  float64
+	Note    string
+	Scores  RatingScores
+	Answers RatingAnswers
+}
+
+var ErrRatingOutOfRange = errors.New("rating out of range")
+
+// Validate checks the rating and its sub-scores are on the canonical scale.
+func (i RatingInput) Validate() error {
+	if i.Rating < canonicalRatingMin || i.Rating > canonicalRatingMax {
+		return fmt.Errorf("%w: %g", ErrRatingOutOfRange, i.Rating)
+	}
+	for dimension, score := range i.Scores {
+		if score < canonicalRatingMin || score > canonicalRatingMax {
+			return fmt.Errorf("%w: %s score %g", ErrRatingOutOfRange, dimension, score)
+		}
+	}
+	return nil
+}
+
 type Service struct {
 	db *db.DB
 }
@@ -46,30 +91,130 @@ func NewService(db *db.DB) *Service {
 	}
 }
 
-func (s *Service) AddRating(ctx context.Context, userId, albumId string, rating float64, note string) (*AlbumRatingDTO, error) {
+func (s *Service) AddRating(ctx context.Context, userId, albumId string, input RatingInput) (*AlbumRatingDTO, error) {
+	var dto *AlbumRatingDTO
+	err := s.db.WithTx(func(tx *db.DB) error {
+		var err error
+		dto, err = insertRating(ctx, tx, userId, albumId, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dto, nil
+}
+
+// insertRating appends a rating log entry with its sub-scores and answers.
+func insertRating(ctx context.Context, tx *db.DB, userId, albumId string, input RatingInput) (*AlbumRatingDTO, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
 	var noteParam sql.NullString
-	if note != "" {
-		noteParam = sql.NullString{String: note, Valid: true}
+	if input.Note != "" {
+		noteParam = sql.NullString{String: input.Note, Valid: true}
 	}
-	model, err := s.db.Queries().InsertAlbumRatingLogEntry(ctx, sqlc.InsertAlbumRatingLogEntryParams{
+
+	model, err := tx.Queries().InsertAlbumRatingLogEntry(ctx, sqlc.InsertAlbumRatingLogEntryParams{
 		ID:      uuid.NewString(),
 		UserID:  userId,
 		AlbumID: albumId,
-		Rating:  rating,
+		Rating:  input.Rating,
 		Note:    noteParam,
 	})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to insert rating log entry: %w", err)
 	}
+	dto := NewAlbumRatingDTOFromModel(model)
 
-	return NewAlbumRatingDTOFromModel(model), nil
+	for dimension, score := range input.Scores {
+		err = tx.Queries().InsertAlbumRatingScore(ctx, sqlc.InsertAlbumRatingScoreParams{
+			RatingLogID: model.ID,
+			Dimension:   dimension.String(),
+			Score:       score,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert %s score: %w", dimension, err)
+		}
+		dto.Scores[dimension] = score
+	}
+
+	for key, value := range input.Answers {
+		err = tx.Queries().InsertAlbumRatingAnswer(ctx, sqlc.InsertAlbumRatingAnswerParams{
+			RatingLogID: model.ID,
+			QuestionKey: key.String(),
+			Value:       int64(value),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert %s answer: %w", key, err)
+		}
+		dto.Answers[key] = value
+	}
+
+	// A fresh rating answers any pending revisit suggestion.
+	err = tx.Queries().DeleteRevisitSuggestion(ctx, sqlc.DeleteRevisitSuggestionParams{
+		UserID:  userId,
+		AlbumID: albumId,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to clear revisit suggestion: %w", err)
+	}
+
+	return dto, nil
 }
 
 func (s *Service) DeleteRatingEntry(ctx context.Context, userId, entryId string) error {
-	return s.db.Queries().DeleteAlbumRatingLogEntry(ctx, sqlc.DeleteAlbumRatingLogEntryParams{
+	return s.db.WithTx(func(tx *db.DB) error {
+		return DeleteRatingEntryTx(ctx, tx, userId, entryId)
+	})
+}
+
+// DeleteRatingEntryTx deletes a rating log entry within an open transaction,
+// taking its scores and answers with it.
+func DeleteRatingEntryTx(ctx context.Context, tx *db.DB, userId, entryId string) error {
+	err := tx.Queries().DeleteAlbumRatingScoresByEntryId(ctx, sqlc.DeleteAlbumRatingScoresByEntryIdParams{
+		ID:     entryId,
+		UserID: userId,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete rating scores: %w", err)
+	}
+
+	err = tx.Queries().DeleteAlbumRatingAnswersByEntryId(ctx, sqlc.DeleteAlbumRatingAnswersByEntryIdParams{
+		ID:     entryId,
+		UserID: userId,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete rating answers: %w", err)
+	}
+
+	// A review written alongside this entry outlives it, but loses the link.
+	err = tx.Queries().ClearAlbumReviewRatingLogId(ctx, sqlc.ClearAlbumReviewRatingLogIdParams{
+		RatingLogID: sql.NullString{String: entryId, Valid: true},
+		UserID:      userId,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unlink album review: %w", err)
+	}
+
+	err = tx.Queries().DeleteRevisitSuggestionByRatingLogId(ctx, sqlc.DeleteRevisitSuggestionByRatingLogIdParams{
+		RatingLogID: entryId,
+		UserID:      userId,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clear revisit suggestion: %w", err)
+	}
+
+	err = tx.Queries().DeleteAlbumRatingLogEntry(ctx, sqlc.DeleteAlbumRatingLogEntryParams{
 		ID:     entryId,
 		UserID: userId,
 	})
+	if err != nil {
+		return fmt.Errorf("failed to delete rating log entry: %w", err)
+	}
+
+	return nil
 }
 
 func (s *Service) GetRatingLog(ctx context.Context, userId, albumId string) ([]*AlbumRatingDTO, error) {
@@ -85,5 +230,589 @@ func (s *Service) GetRatingLog(ctx context.Context, userId, albumId string) ([]*
 	for i, row := range rows {
 		dtos[i] = NewAlbumRatingDTOFromModel(row)
 	}
+
+	err = s.LoadRatingDetails(ctx, dtos)
+	if err != nil {
+		return nil, err
+	}
+
 	return dtos, nil
 }
+
+// LoadRatingDetails populates the sub-scores and questionnaire answers of the
+// given rating log entries.
+func (s *Service) LoadRatingDetails(ctx context.Context, dtos []*AlbumRatingDTO) error {
+	if len(dtos) == 0 {
+		return nil
+	}
+
+	byId := make(map[string]*AlbumRatingDTO, len(dtos))
+	entryIds := make([]string, 0, len(dtos))
+	for _, dto := range dtos {
+		if dto == nil {
+			continue
+		}
+		byId[dto.ID] = dto
+		entryIds = append(entryIds, dto.ID)
+	}
+
+	scores, err := s.db.Queries().GetAlbumRatingScoresByEntryIds(ctx, entryIds)
+	if err != nil {
+		return fmt.Errorf("failed to get rating scores: %w", err)
+	}
+	for _, score := range scores {
+		if dto, ok := byId[score.RatingLogID]; ok {
+			dto.Scores[RatingDimension(score.Dimension)] = score.Score
+		}
+	}
+
+	answers, err := s.db.Queries().GetAlbumRatingAnswersByEntryIds(ctx, entryIds)
+	if err != nil {
+		return fmt.Errorf("failed to get rating answers: %w", err)
+	}
+	for _, answer := range answers {
+		if dto, ok := byId[answer.RatingLogID]; ok {
+			dto.Answers[RatingQuestionKey(answer.QuestionKey)] = int(answer.Value)
+		}
+	}
+
+	return nil
+}
+
+// GetComparisonCandidates returns the user's other rated albums ordered by
+// ascending current rating, ready for a ComparisonSearch.
+func (s *Service) GetComparisonCandidates(ctx context.Context, userId, albumId string) ([]ComparisonCandidate, error) {
+	rows, err := s.db.Queries().GetComparisonCandidates(ctx, sqlc.GetComparisonCandidatesParams{
+		UserID:   userId,
+		UserID_2: userId,
+		AlbumID:  albumId,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comparison candidates: %w", err)
+	}
+
+	candidates := make([]ComparisonCandidate, len(rows))
+	for i, row := range rows {
+		candidates[i] = ComparisonCandidate{
+			AlbumID:  row.ID,
+			Title:    row.Title,
+			Artists:  fmt.Sprintf("%s", row.ArtistNames),
+			ImageURL: row.ImageUrl.String,
+			Rating:   row.Rating,
+		}
+	}
+	return candidates, nil
+}
+
+var ErrInvalidComparisonOpponent = errors.New("opponent is not one of the user's other rated albums")
+
+// RecordComparison persists the result of comparing albumId against
+// opponentAlbumId, from albumId's point of view. The opponent has to be one of
+// the album's comparison candidates.
+func (s *Service) RecordComparison(ctx context.Context, userId, albumId, opponentAlbumId string, outcome models.ComparisonOutcome) error {
+	candidates, err := s.GetComparisonCandidates(ctx, userId, albumId)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(candidates, func(candidate ComparisonCandidate) bool {
+		return candidate.AlbumID == opponentAlbumId
+	}) {
+		return fmt.Errorf("%w: %s", ErrInvalidComparisonOpponent, opponentAlbumId)
+	}
+
+	_, err = s.db.Queries().InsertAlbumComparison(ctx, sqlc.InsertAlbumComparisonParams{
+		ID:              uuid.NewString(),
+		UserID:          userId,
+		AlbumID:         albumId,
+		OpponentAlbumID: opponentAlbumId,
+		Outcome:         outcome,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to insert comparison: %w", err)
+	}
+	return nil
+}
+
+// GetRatingProfile returns the user's rating profile, or the default profile
+// when they have not customized one. A stored profile that no longer decodes
+// or validates also falls back to the default, since every rating shown goes
+// through it.
+func (s *Service) GetRatingProfile(ctx context.Context, userId string) (RatingProfile, error) {
+	model, err := s.db.Queries().GetRatingProfile(ctx, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return DefaultRatingProfile(), nil
+	} else if err != nil {
+		return RatingProfile{}, fmt.Errorf("failed to get rating profile: %w", err)
+	}
+
+	var profile RatingProfile
+	err = json.Unmarshal([]byte(model.Profile), &profile)
+	if err == nil {
+		err = profile.Validate()
+	}
+	if err != nil {
+		slog.Warn("falling back to the default rating profile", "userId", userId, "error", err)
+		return DefaultRatingProfile(), nil
+	}
+	return profile, nil
+}
+
+func (s *Service) SaveRatingProfile(ctx context.Context, userId string, profile RatingProfile) (RatingProfile, error) {
+	profile = profile.Normalize()
+	err := profile.Validate()
+	if err != nil {
+		return RatingProfile{}, fmt.Errorf("%w: %w", ErrInvalidRatingProfile, err)
+	}
+
+	encoded, err := json.Marshal(profile)
+	if err != nil {
+		return RatingProfile{}, fmt.Errorf("failed to encode rating profile: %w", err)
+	}
+
+	err = s.db.Queries().UpsertRatingProfile(ctx, sqlc.UpsertRatingProfileParams{
+		UserID:  userId,
+		Profile: string(encoded),
+	})
+	if err != nil {
+		return RatingProfile{}, fmt.Errorf("failed to save rating profile: %w", err)
+	}
+	return profile, nil
+}
+
+func (s *Service) ResetRatingProfile(ctx context.Context, userId string) error {
+	err := s.db.Queries().DeleteRatingProfile(ctx, userId)
+	if err != nil {
+		return fmt.Errorf("failed to reset rating profile: %w", err)
+	}
+	return nil
+}
+
+// GetAlbumReview returns the user's review of an album, or nil when they
+// haven't started one.
+func (s *Service) GetAlbumReview(ctx context.Context, userId, albumId string) (*AlbumReviewDTO, error) {
+	model, err := s.db.Queries().GetAlbumReview(ctx, sqlc.GetAlbumReviewParams{
+		UserID:  userId,
+		AlbumID: albumId,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get album review: %w", err)
+	}
+	return NewAlbumReviewDTOFromModel(model), nil
+}
+
+// SaveAlbumReview creates or updates the user's review of an album and
+// records the change in its revision history.
+func (s *Service) SaveAlbumReview(ctx context.Context, userId, albumId string, input AlbumReviewInput) (*AlbumReviewDTO, error) {
+	if len(input.Body) > AlbumReviewMaxLength {
+		return nil, ErrAlbumReviewTooLong
+	}
+
+	var ratingLogParam sql.NullString
+	if input.RatingLogID != nil {
+		ratingLog, err := s.GetRatingLog(ctx, userId, albumId)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, entry := range ratingLog {
+			found = found || entry.ID == *input.RatingLogID
+		}
+		if !found {
+			return nil, ErrAlbumReviewRatingNotFound
+		}
+		ratingLogParam = sql.NullString{String: *input.RatingLogID, Valid: true}
+	}
+
+	var dto *AlbumReviewDTO
+	err := s.db.WithTx(func(tx *db.DB) error {
+		model, err := tx.Queries().GetAlbumReview(ctx, sqlc.GetAlbumReviewParams{
+			UserID:  userId,
+			AlbumID: albumId,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			model, err = tx.Queries().InsertAlbumReview(ctx, sqlc.InsertAlbumReviewParams{
+				ID:          uuid.NewString(),
+				UserID:      userId,
+				AlbumID:     albumId,
+				RatingLogID: ratingLogParam,
+				Body:        input.Body,
+				Status:      models.ReviewStatusDraft,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to insert album review: %w", err)
+			}
+		} else if err != nil {
+			return fmt.Errorf("failed to get album review: %w", err)
+		} else {
+			// Autosave only runs while drafting; a published review changes
+			// only when the user explicitly updates or unpublishes it.
+			if input.Mode == AlbumReviewSaveAutosave && model.Status == models.ReviewStatusPublished {
+				return ErrAlbumReviewPublished
+			}
+			model, err = tx.Queries().UpdateAlbumReview(ctx, sqlc.UpdateAlbumReviewParams{
+				Body:        input.Body,
+				RatingLogID: ratingLogParam,
+				Status:      models.ReviewStatusDraft,
+				ID:          model.ID,
+				UserID:      userId,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to update album review: %w", err)
+			}
+		}
+
+		if input.Mode == AlbumReviewSavePublish {
+			model, err = tx.Queries().PublishAlbumReview(ctx, sqlc.PublishAlbumReviewParams{
+				ID:     model.ID,
+				UserID: userId,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to publish album review: %w", err)
+			}
+		}
+
+		var latest *AlbumReviewRevisionDTO
+		latestModel, err := tx.Queries().GetLatestAlbumReviewRevision(ctx, model.ID)
+		if err == nil {
+			latest = NewAlbumReviewRevisionDTOFromModel(latestModel)
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get latest review revision: %w", err)
+		}
+
+		switch nextRevisionAction(latest, model.Body, input.Mode, model.UpdatedAt) {
+		case revisionInsert:
+			err = tx.Queries().InsertAlbumReviewRevision(ctx, sqlc.InsertAlbumReviewRevisionParams{
+				ID:       uuid.NewString(),
+				ReviewID: model.ID,
+				Body:     model.Body,
+				Status:   model.Status,
+			})
+		case revisionUpdate:
+			err = tx.Queries().UpdateAlbumReviewRevision(ctx, sqlc.UpdateAlbumReviewRevisionParams{
+				Body: model.Body,
+				ID:   latest.ID,
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to record review revision: %w", err)
+		}
+
+		dto = NewAlbumReviewDTOFromModel(model)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dto, nil
+}
+
+func (s *Service) GetAlbumReviewRevisions(ctx context.Context, reviewId string) ([]*AlbumReviewRevisionDTO, error) {
+	rows, err := s.db.Queries().GetAlbumReviewRevisions(ctx, reviewId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review revisions: %w", err)
+	}
+
+	dtos := make([]*AlbumReviewRevisionDTO, len(rows))
+	for i, row := range rows {
+		dtos[i] = NewAlbumReviewRevisionDTOFromModel(row)
+	}
+	return dtos, nil
+}
+
+func (s *Service) DeleteAlbumReview(ctx context.Context, userId, albumId string) error {
+	return s.db.WithTx(func(tx *db.DB) error {
+		model, err := tx.Queries().GetAlbumReview(ctx, sqlc.GetAlbumReviewParams{
+			UserID:  userId,
+			AlbumID: albumId,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to get album review: %w", err)
+		}
+
+		err = tx.Queries().DeleteAlbumReviewRevisionsByReviewId(ctx, model.ID)
+		if err != nil {
+			return fmt.Errorf("failed to delete review revisions: %w", err)
+		}
+
+		err = tx.Queries().DeleteAlbumReview(ctx, sqlc.DeleteAlbumReviewParams{
+			ID:     model.ID,
+			UserID: userId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete album review: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// SetTrackMark marks a track as a standout or a skip, or clears its marker
+// when mark is empty.
+func (s *Service) SetTrackMark(ctx context.Context, userId, trackId string, mark models.TrackMark) error {
+	if mark == "" {
+		err := s.db.Queries().DeleteTrackMark(ctx, sqlc.DeleteTrackMarkParams{
+			UserID:  userId,
+			TrackID: trackId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to clear track mark: %w", err)
+		}
+		return nil
+	}
+
+	err := s.db.Queries().UpsertTrackMark(ctx, sqlc.UpsertTrackMarkParams{
+		UserID:  userId,
+		TrackID: trackId,
+		Mark:    mark,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set track mark: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) GetTrackMarks(ctx context.Context, userId, albumId string) (TrackMarks, error) {
+	rows, err := s.db.Queries().GetTrackMarksByAlbumId(ctx, sqlc.GetTrackMarksByAlbumIdParams{
+		UserID:  userId,
+		AlbumID: albumId,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get track marks: %w", err)
+	}
+
+	marks := make(TrackMarks, len(rows))
+	for _, row := range rows {
+		marks[row.TrackID] = row.Mark
+	}
+	return marks, nil
+}
+
+// GetTopTracks returns the user's standout tracks across their library, best
+// rated albums first and most played tracks first within an album.
+func (s *Service) GetTopTracks(ctx context.Context, userId string) ([]TopTrackDTO, error) {
+	rows, err := s.db.Queries().GetTopTracks(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top tracks: %w", err)
+	}
+
+	dtos := make([]TopTrackDTO, 0, len(rows))
+	for _, row := range rows {
+		dto := TopTrackDTO{
+			ID:         row.ID,
+			Title:      row.Title,
+			AlbumID:    row.AlbumID,
+			AlbumTitle: row.AlbumTitle,
+			Artists:    fmt.Sprintf("%s", row.ArtistNames),
+			ImageURL:   row.ImageUrl.String,
+			PlayCount:  int(row.PlayCount),
+		}
+		if row.Rating.Valid {
+			dto.AlbumRating = &row.Rating.Float64
+		}
+		dtos = append(dtos, dto)
+	}
+	return dtos, nil
+}
+
+// GetRevisitRules returns the user's revisit thresholds, or the defaults when
+// they have not changed them.
+func (s *Service) GetRevisitRules(ctx context.Context, userId string) (RevisitRules, error) {
+	model, err := s.db.Queries().GetRevisitRules(ctx, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return DefaultRevisitRules(), nil
+	} else if err != nil {
+		return RevisitRules{}, fmt.Errorf("failed to get revisit rules: %w", err)
+	}
+
+	var rules RevisitRules
+	err = json.Unmarshal([]byte(model.Rules), &rules)
+	if err != nil {
+		return RevisitRules{}, fmt.Errorf("failed to decode revisit rules: %w", err)
+	}
+	return rules, nil
+}
+
+// SaveRevisitRules stores the user's revisit thresholds and rebuilds their
+// suggestions so the Revisit carousel reflects the change straight away.
+func (s *Service) SaveRevisitRules(ctx context.Context, userId string, rules RevisitRules) (RevisitRules, error) {
+	err := rules.Validate()
+	if err != nil {
+		return RevisitRules{}, fmt.Errorf("%w: %w", ErrInvalidRevisitRules, err)
+	}
+
+	encoded, err := json.Marshal(rules)
+	if err != nil {
+		return RevisitRules{}, fmt.Errorf("failed to encode revisit rules: %w", err)
+	}
+
+	err = s.db.Queries().UpsertRevisitRules(ctx, sqlc.UpsertRevisitRulesParams{
+		UserID: userId,
+		Rules:  string(encoded),
+	})
+	if err != nil {
+		return RevisitRules{}, fmt.Errorf("failed to save revisit rules: %w", err)
+	}
+
+	err = s.RefreshRevisitSuggestions(ctx, userId)
+	if err != nil {
+		return RevisitRules{}, err
+	}
+	return rules, nil
+}
+
+// GetUsersWithRatings returns the ids of every user who has rated an album,
+// the users revisit suggestions are refreshed for.
+func (s *Service) GetUsersWithRatings(ctx context.Context) ([]string, error) {
+	userIds, err := s.db.Queries().GetUsersWithRatings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users with ratings: %w", err)
+	}
+	return userIds, nil
+}
+
+// RefreshRevisitSuggestions replaces the user's revisit suggestions with the
+// albums their current rules flag.
+func (s *Service) RefreshRevisitSuggestions(ctx context.Context, userId string) error {
+	rules, err := s.GetRevisitRules(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	rows, err := s.db.Queries().GetRevisitCandidates(ctx, userId)
+	if err != nil {
+		return fmt.Errorf("failed to get revisit candidates: %w", err)
+	}
+
+	now := time.Now()
+	return s.db.WithTx(func(tx *db.DB) error {
+		err := tx.Queries().DeleteRevisitSuggestionsByUserId(ctx, userId)
+		if err != nil {
+			return fmt.Errorf("failed to clear revisit suggestions: %w", err)
+		}
+
+		for _, row := range rows {
+			candidate := RevisitCandidate{
+				AlbumID:       row.AlbumID,
+				RatingLogID:   row.RatingLogID,
+				RatedAt:       row.RatedAt,
+				PlaysSince:    int(row.PlaysSince),
+				ListensBefore: int(row.ListensBefore),
+			}
+			reason, ok := rules.Reason(candidate, now)
+			if !ok {
+				continue
+			}
+			err = tx.Queries().InsertRevisitSuggestion(ctx, sqlc.InsertRevisitSuggestionParams{
+				UserID:      userId,
+				AlbumID:     candidate.AlbumID,
+				RatingLogID: candidate.RatingLogID,
+				Reason:      reason,
+				PlaysSince:  row.PlaysSince,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to insert revisit suggestion: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetCalibrationReport summarizes the user's rating log against a target
+// curve, on their rating profile's scale.
+func (s *Service) GetCalibrationReport(ctx context.Context, userId string, target CalibrationTarget) (CalibrationReport, error) {
+	profile, err := s.GetRatingProfile(ctx, userId)
+	if err != nil {
+		return CalibrationReport{}, err
+	}
+
+	rows, err := s.db.Queries().GetUserRatingLogWithAlbums(ctx, userId)
+	if err != nil {
+		return CalibrationReport{}, fmt.Errorf("failed to get rating log: %w", err)
+	}
+
+	log := make([]CalibrationRating, 0, len(rows))
+	for _, row := range rows {
+		log = append(log, CalibrationRating{
+			RatingLogID: row.ID,
+			AlbumID:     row.AlbumID,
+			AlbumTitle:  row.AlbumTitle,
+			Artists:     fmt.Sprintf("%s", row.ArtistNames),
+			Rating:      row.Rating,
+			RatedAt:     row.CreatedAt,
+		})
+	}
+
+	return NewCalibrationReport(log, target, profile), nil
+}
+
+// ApplyCalibration logs the suggested score as a new rating for each of the
+// given albums whose score would change, keeping the current entries in the
+// log. Suggestions are recomputed rather than taken from the client, and
+// quality and enjoyment sub-scores carry over unchanged. It returns how many
+// albums were re-rated.
+func (s *Service) ApplyCalibration(ctx context.Context, userId string, target CalibrationTarget, albumIds []string) (int, error) {
+	profile, err := s.GetRatingProfile(ctx, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	report, err := s.GetCalibrationReport(ctx, userId, target)
+	if err != nil {
+		return 0, err
+	}
+
+	selected := make(map[string]bool, len(albumIds))
+	for _, albumId := range albumIds {
+		selected[albumId] = true
+	}
+
+	var changes []CalibrationAlbum
+	var current []*AlbumRatingDTO
+	for _, album := range report.Changes(profile) {
+		if !selected[album.AlbumID] {
+			continue
+		}
+		changes = append(changes, album)
+		current = append(current, &AlbumRatingDTO{
+			ID:      album.RatingLogID,
+			Scores:  RatingScores{},
+			Answers: RatingAnswers{},
+		})
+	}
+	if len(changes) == 0 {
+		return 0, nil
+	}
+
+	err = s.LoadRatingDetails(ctx, current)
+	if err != nil {
+		return 0, err
+	}
+
+	calibratedOn := time.Now().Format("Jan 2, 2006")
+	err = s.db.WithTx(func(tx *db.DB) error {
+		for i, album := range changes {
+			_, err := insertRating(ctx, tx, userId, album.AlbumID, RatingInput{
+				Rating: album.Suggested,
+				Note: fmt.Sprintf(
+					"Recalibrated from %s to %s against the %s curve on %s (was in the %s percentile).",
+					profile.Format(album.Rating), profile.Format(album.Suggested), target.Name, calibratedOn, ordinal(int(math.Round(album.Percentile))),
+				),
+				Scores: current[i].Scores,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(changes), nil
+}
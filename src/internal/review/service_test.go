@@ -0,0 +1,121 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/alecdray/wax/src/internal/core/db"
+	"github.com/alecdray/wax/src/internal/core/db/dbtest"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+)
+
+func newTestService(t *testing.T) (*Service, *db.DB) {
+	database := dbtest.New(t)
+	return NewService(database), database
+}
+
+func TestRecordComparison_OnlyAgainstRatedCandidates(t *testing.T) {
+	service, database := newTestService(t)
+	ctx := context.Background()
+
+	dbtest.Exec(t, database, "INSERT INTO users (id, spotify_id) VALUES ('u1', 'u1'), ('u2', 'u2')")
+	for _, albumId := range []string{"new", "rated", "unrated", "theirs"} {
+		dbtest.Exec(t, database, "INSERT INTO albums (id, spotify_id, title) VALUES (?, ?, ?)", albumId, albumId, albumId)
+	}
+	dbtest.Exec(t, database, "INSERT INTO album_rating_log (id, user_id, album_id, rating) VALUES ('r1', 'u1', 'rated', 7), ('r2', 'u2', 'theirs', 9)")
+
+	err := service.RecordComparison(ctx, "u1", "new", "rated", models.ComparisonOutcomeBetter)
+	if err != nil {
+		t.Fatalf("expected a comparison against a rated album to be recorded, got %v", err)
+	}
+
+	for _, opponent := range []string{"unrated", "theirs", "missing", "new"} {
+		err := service.RecordComparison(ctx, "u1", "new", opponent, models.ComparisonOutcomeBetter)
+		if !errors.Is(err, ErrInvalidComparisonOpponent) {
+			t.Errorf("expected a comparison against %q to be rejected, got %v", opponent, err)
+		}
+	}
+
+	var recorded int
+	if err := database.Sql().QueryRow("SELECT COUNT(*) FROM album_comparisons").Scan(&recorded); err != nil {
+		t.Fatalf("failed to count comparisons: %v", err)
+	}
+	if recorded != 1 {
+		t.Errorf("expected only the valid comparison recorded, got %d", recorded)
+	}
+}
+
+func TestGetRatingProfile_FallsBackFromAnInvalidProfile(t *testing.T) {
+	service, database := newTestService(t)
+	ctx := context.Background()
+
+	dbtest.Exec(t, database, "INSERT INTO users (id, spotify_id) VALUES ('u1', 'u1'), ('u2', 'u2'), ('u3', 'u3')")
+	noBands := DefaultRatingProfile()
+	noBands.Bands = nil
+	encoded, err := json.Marshal(noBands)
+	if err != nil {
+		t.Fatalf("failed to encode profile: %v", err)
+	}
+	dbtest.Exec(t, database, "INSERT INTO rating_profiles (user_id, profile) VALUES ('u1', ?), ('u2', '{not json')", string(encoded))
+	fivePoint, err := service.SaveRatingProfile(ctx, "u3", RatingProfilePresets[1].Profile())
+	if err != nil {
+		t.Fatalf("failed to save profile: %v", err)
+	}
+
+	for _, userId := range []string{"u1", "u2"} {
+		profile, err := service.GetRatingProfile(ctx, userId)
+		if err != nil {
+			t.Fatalf("expected a fallback for %s, got %v", userId, err)
+		}
+		if len(profile.Bands) == 0 || profile.Label(7) != GetRatingLabel(7) {
+			t.Errorf("expected the default profile for %s, got %+v", userId, profile)
+		}
+	}
+
+	profile, err := service.GetRatingProfile(ctx, "u3")
+	if err != nil || profile.ScaleMax != fivePoint.ScaleMax {
+		t.Errorf("expected the saved profile back, got %+v, %v", profile, err)
+	}
+}
+
+func TestGetTopTracks_CountsEachPlayOnceAndOnlyLibraryAlbums(t *testing.T) {
+	service, database := newTestService(t)
+	ctx := context.Background()
+
+	dbtest.Exec(t, database, "INSERT INTO users (id, spotify_id) VALUES ('u1', 'u1')")
+	for _, albumId := range []string{"owned", "gone"} {
+		dbtest.Exec(t, database, "INSERT INTO albums (id, spotify_id, title) VALUES (?, ?, ?)", albumId, albumId, albumId)
+		trackId := albumId + "-t"
+		dbtest.Exec(t, database, "INSERT INTO tracks (id, spotify_id, title) VALUES (?, ?, ?)", trackId, trackId, trackId)
+		dbtest.Exec(t, database, "INSERT INTO album_tracks (album_id, track_id) VALUES (?, ?)", albumId, trackId)
+		dbtest.Exec(t, database, "INSERT INTO track_marks (user_id, track_id, mark) VALUES ('u1', ?, 'standout')", trackId)
+		dbtest.Exec(t, database, "INSERT INTO releases (id, album_id, format) VALUES (?, ?, 'digital')", albumId+"-r", albumId)
+	}
+	// The other album was marked, then removed from the library.
+	dbtest.Exec(t, database, "INSERT INTO user_releases (id, user_id, release_id) VALUES ('ur1', 'u1', 'owned-r')")
+
+	// Two entries at the same moment: the later insert is the latest.
+	dbtest.Exec(t, database, `INSERT INTO album_rating_log (id, user_id, album_id, rating, created_at) VALUES
+		('r1', 'u1', 'owned', 6, '2026-01-01 12:00:00'),
+		('r2', 'u1', 'owned', 8, '2026-01-01 12:00:00')`)
+	for i, playedAt := range []string{"2026-01-02 12:00:00", "2026-01-03 12:00:00", "2026-01-04 12:00:00"} {
+		dbtest.Exec(t, database, "INSERT INTO track_plays (id, user_id, track_id, album_id, played_at) VALUES (?, 'u1', 'owned-t', 'owned', ?)", fmt.Sprintf("p%d", i), playedAt)
+	}
+
+	tracks, err := service.GetTopTracks(ctx, "u1")
+	if err != nil {
+		t.Fatalf("failed to get top tracks: %v", err)
+	}
+	if len(tracks) != 1 || tracks[0].ID != "owned-t" {
+		t.Fatalf("expected only the track on the album still in the library, got %+v", tracks)
+	}
+	if tracks[0].PlayCount != 3 {
+		t.Errorf("expected 3 plays, got %d", tracks[0].PlayCount)
+	}
+	if tracks[0].AlbumRating == nil || *tracks[0].AlbumRating != 8 {
+		t.Errorf("expected the latest rating of 8, got %v", tracks[0].AlbumRating)
+	}
+}
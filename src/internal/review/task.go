@@ -0,0 +1,43 @@
+package review
+
+import (
+	"log/slog"
+	"github.com/alecdray/wax/src/internal/core/contextx"
+	"github.com/alecdray/wax/src/internal/core/task"
+)
+
+type RefreshRevisitSuggestionsTask struct {
+	service *Service
+}
+
+var _ task.Task = RefreshRevisitSuggestionsTask{}
+
+func NewRefreshRevisitSuggestionsTask(service *Service) task.Task {
+	return RefreshRevisitSuggestionsTask{service: service}
+}
+
+func (t RefreshRevisitSuggestionsTask) Run(ctx contextx.ContextX) error {
+	userIds, err := t.service.GetUsersWithRatings(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, userId := range userIds {
+		if err := t.service.RefreshRevisitSuggestions(ctx, userId); err != nil {
+			slog.Error("failed to refresh revisit suggestions", "userId", userId, "error", err)
+			continue
+		}
+		slog.Debug("refreshed revisit suggestions", "userId", userId)
+	}
+
+	return nil
+}
+
+func (t RefreshRevisitSuggestionsTask) Schedule() *task.CronExpression {
+	schedule := task.CronExpression("0 4 * * *") // Daily at 04:00
+	return &schedule
+}
+
+func (t RefreshRevisitSuggestionsTask) Name() string {
+	return "refresh_revisit_suggestions"
+}
@@ -0,0 +1,82 @@
+package review
+
+import (
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"math"
+	"sort"
+)
+
+// TrackMarks maps track IDs to the user's standout/skip marker on that track.
+type TrackMarks map[string]models.TrackMark
+
+// TrackMarkSummary counts the markers on an album's tracks.
+type TrackMarkSummary struct {
+	Standouts int
+	Skips     int
+}
+
+func (marks TrackMarks) Summary() TrackMarkSummary {
+	var summary TrackMarkSummary
+	for _, mark := range marks {
+		switch mark {
+		case models.TrackMarkStandout:
+			summary.Standouts++
+		case models.TrackMarkSkip:
+			summary.Skips++
+		}
+	}
+	return summary
+}
+
+func (s TrackMarkSummary) Marked() int {
+	return s.Standouts + s.Skips
+}
+
+// ConsistencyValue picks the answer to the track consistency question implied
+// by the share of standouts against skips: all standouts lands on the best
+// option, all skips on the worst, an even split in the middle. It reports
+// false when no tracks are marked.
+func (s TrackMarkSummary) ConsistencyValue(question RatingQuestion) (int, bool) {
+	if s.Marked() == 0 || len(question.Options) == 0 {
+		return 0, false
+	}
+
+	values := make([]int, len(question.Options))
+	for i, opt := range question.Options {
+		values[i] = opt.Value
+	}
+	sort.Ints(values)
+
+	balance := float64(s.Standouts-s.Skips) / float64(s.Marked())
+	index := int(math.Round((balance + 1) / 2 * float64(len(values)-1)))
+	return values[index], true
+}
+
+// PrefillFromTrackMarks answers the track consistency question from the
+// album's track markers, leaving the other questions untouched. It reports
+// whether a question was prefilled.
+func (qs RatingQuestions) PrefillFromTrackMarks(summary TrackMarkSummary) (RatingQuestions, bool) {
+	prefilled := make(RatingQuestions, len(qs))
+	copy(prefilled, qs)
+	for i, question := range prefilled {
+		if question.Key != RatingQuestionConsistency {
+			continue
+		}
+		if value, ok := summary.ConsistencyValue(question); ok {
+			prefilled[i] = question.WithValue(value)
+			return prefilled, true
+		}
+	}
+	return prefilled, false
+}
+
+type TopTrackDTO struct {
+	ID          string
+	Title       string
+	AlbumID     string
+	AlbumTitle  string
+	Artists     string
+	ImageURL    string
+	AlbumRating *float64
+	PlayCount   int
+}
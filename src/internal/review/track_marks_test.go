@@ -0,0 +1,104 @@
+package review
+
+import (
+	"testing"
+
+	"github.com/alecdray/wax/src/internal/core/db/models"
+)
+
+func consistencyQuestion(t *testing.T) RatingQuestion {
+	t.Helper()
+	for _, q := range RatingRecommenderQuestions {
+		if q.Key == RatingQuestionConsistency {
+			return q
+		}
+	}
+	t.Fatal("expected default questions to include track consistency")
+	return RatingQuestion{}
+}
+
+func TestTrackMarks_Summary(t *testing.T) {
+	marks := TrackMarks{
+		"a": models.TrackMarkStandout,
+		"b": models.TrackMarkStandout,
+		"c": models.TrackMarkSkip,
+	}
+	got := marks.Summary()
+	if got.Standouts != 2 || got.Skips != 1 || got.Marked() != 3 {
+		t.Errorf("Summary() = %+v, want 2 standouts and 1 skip", got)
+	}
+}
+
+func TestTrackMarkSummary_ConsistencyValue(t *testing.T) {
+	question := consistencyQuestion(t)
+	cases := []struct {
+		name    string
+		summary TrackMarkSummary
+		want    int
+	}{
+		{"all standouts", TrackMarkSummary{Standouts: 4}, 5},
+		{"all skips", TrackMarkSummary{Skips: 3}, 1},
+		{"even split", TrackMarkSummary{Standouts: 2, Skips: 2}, 3},
+		{"mostly standouts", TrackMarkSummary{Standouts: 3, Skips: 1}, 4},
+		{"mostly skips", TrackMarkSummary{Standouts: 1, Skips: 3}, 2},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, ok := c.summary.ConsistencyValue(question)
+			if !ok {
+				t.Fatal("expected a value")
+			}
+			if got != c.want {
+				t.Errorf("ConsistencyValue() = %d, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+func TestTrackMarkSummary_ConsistencyValue_NoMarks(t *testing.T) {
+	if _, ok := (TrackMarkSummary{}).ConsistencyValue(consistencyQuestion(t)); ok {
+		t.Error("expected no value without marked tracks")
+	}
+}
+
+func TestTrackMarkSummary_ConsistencyValue_CustomOptions(t *testing.T) {
+	question := RatingQuestion{
+		Key: RatingQuestionConsistency,
+		Options: []RatingQuestionOption{
+			{Value: 3, Label: "Great"},
+			{Value: 1, Label: "Poor"},
+			{Value: 2, Label: "Fine"},
+		},
+	}
+	got, _ := TrackMarkSummary{Standouts: 5}.ConsistencyValue(question)
+	if got != 3 {
+		t.Errorf("ConsistencyValue() = %d, want the highest option value 3", got)
+	}
+}
+
+func TestRatingQuestions_PrefillFromTrackMarks(t *testing.T) {
+	questions := RatingRecommenderQuestions
+
+	prefilled, ok := questions.PrefillFromTrackMarks(TrackMarkSummary{Standouts: 6})
+	if !ok {
+		t.Fatal("expected the consistency question to be prefilled")
+	}
+	for i, q := range prefilled {
+		if q.Key == RatingQuestionConsistency {
+			if q.Value != 5 {
+				t.Errorf("consistency value = %d, want 5", q.Value)
+			}
+		} else if q.Value != questions[i].Value {
+			t.Errorf("question %q changed to %d", q.Key, q.Value)
+		}
+	}
+	for _, q := range questions {
+		if q.Key == RatingQuestionConsistency && q.Value == 5 {
+			t.Error("prefill modified the original questions")
+		}
+	}
+
+	if _, ok := questions.PrefillFromTrackMarks(TrackMarkSummary{}); ok {
+		t.Error("expected no prefill without marked tracks")
+	}
+}
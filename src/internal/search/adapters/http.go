@@ -0,0 +1,86 @@
+package adapters
+
+import (
+	"fmt"
+	"github.com/alecdray/wax/src/internal/core/contextx"
+	"github.com/alecdray/wax/src/internal/core/httpx"
+	"github.com/alecdray/wax/src/internal/feed"
+	"github.com/alecdray/wax/src/internal/search"
+	"net/http"
+)
+
+type HttpHandler struct {
+	searchService *search.Service
+	feedService   *feed.Service
+}
+
+func NewHttpHandler(searchService *search.Service, feedService *feed.Service) *HttpHandler {
+	return &HttpHandler{
+		searchService: searchService,
+		feedService:   feedService,
+	}
+}
+
+// Search answers the command palette with what the query found.
+func (h *HttpHandler) Search(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	results, err := h.searchService.Search(ctx, userId, r.URL.Query().Get("q"))
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+
+	err = CommandPaletteResults(results).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render search results: %w", err),
+		})
+		return
+	}
+}
+
+// AddCatalogueAlbum adds an album found on Spotify to the library.
+func (h *HttpHandler) AddCatalogueAlbum(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	albumId, err := h.feedService.AddSpotifyAlbum(ctx, userId, r.PathValue("spotifyId"))
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadGateway,
+			Err:    err,
+		})
+		return
+	}
+
+	err = CatalogueAlbumAdded(albumId, r.FormValue("title")).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render added album: %w", err),
+		})
+		return
+	}
+}
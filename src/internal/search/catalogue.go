@@ -0,0 +1,45 @@
+package search
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// debouncer lets through only the last of a burst of calls with the same
+// key: each call waits out the window, and goes ahead only if no later call
+// came in meanwhile.
+type debouncer struct {
+	wait time.Duration
+
+	mu     sync.Mutex
+	latest map[string]uint64
+}
+
+func newDebouncer(wait time.Duration) *debouncer {
+	return &debouncer{
+		wait:   wait,
+		latest: make(map[string]uint64),
+	}
+}
+
+// settle waits out the window and reports whether the call should go ahead.
+// A call whose context ends while it waits doesn't.
+func (d *debouncer) settle(ctx context.Context, key string) bool {
+	d.mu.Lock()
+	d.latest[key]++
+	call := d.latest[key]
+	d.mu.Unlock()
+
+	timer := time.NewTimer(d.wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.latest[key] == call
+}
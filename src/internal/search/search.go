@@ -0,0 +1,98 @@
+package search
+
+import (
+	"github.com/alecdray/wax/src/internal/library"
+	"github.com/alecdray/wax/src/internal/spotify"
+	"github.com/alecdray/wax/src/internal/tags"
+	"sort"
+	"strings"
+
+	"github.com/lithammer/fuzzysearch/fuzzy"
+)
+
+// groupLimit is how many results each group of the palette shows.
+const groupLimit = 5
+
+// Results are what the command palette found for a query, grouped in the
+// order they're shown.
+type Results struct {
+	Query string
+	// Albums are the library albums matching the query, most relevant first.
+	Albums []library.AlbumDTO
+	// MoreAlbums is set when the library has more matching albums than
+	// Albums holds.
+	MoreAlbums bool
+	Artists    []library.ArtistDTO
+	Tags       []tags.TagDTO
+	// Catalogue holds Spotify albums matching the query that aren't in the
+	// library yet.
+	Catalogue []CatalogueAlbum
+	// CatalogueUnavailable is set when Spotify couldn't be searched.
+	CatalogueUnavailable bool
+}
+
+func (r Results) IsEmpty() bool {
+	return len(r.Albums) == 0 && len(r.Artists) == 0 && len(r.Tags) == 0 && len(r.Catalogue) == 0
+}
+
+// CatalogueAlbum is an album found in Spotify's catalogue.
+type CatalogueAlbum struct {
+	SpotifyID string
+	Title     string
+	Artists   []string
+	ImageURL  string
+	// Year is the year it was released, when Spotify knows it.
+	Year string
+}
+
+func NewCatalogueAlbumFromSpotify(album spotify.SimpleAlbum) CatalogueAlbum {
+	catalogueAlbum := CatalogueAlbum{
+		SpotifyID: album.ID.String(),
+		Title:     album.Name,
+		Artists:   make([]string, len(album.Artists)),
+	}
+	for i, artist := range album.Artists {
+		catalogueAlbum.Artists[i] = artist.Name
+	}
+	// Spotify lists cover art widest first; the palette shows it small.
+	if len(album.Images) > 0 {
+		catalogueAlbum.ImageURL = album.Images[len(album.Images)-1].URL
+	}
+	if year, _, _ := strings.Cut(album.ReleaseDate, "-"); len(year) == 4 {
+		catalogueAlbum.Year = year
+	}
+	return catalogueAlbum
+}
+
+// normalizeQuery folds the ways of typing the same query together, so they
+// share a cache entry.
+func normalizeQuery(query string) string {
+	return strings.ToLower(strings.Join(strings.Fields(query), " "))
+}
+
+// rankByName keeps the items whose name fuzzily matches the query, closest
+// match first and then by name, up to limit.
+func rankByName[T any](items []T, query string, name func(T) string, limit int) []T {
+	type ranked struct {
+		item T
+		rank int
+	}
+	matches := make([]ranked, 0, len(items))
+	for _, item := range items {
+		if rank := fuzzy.RankMatchNormalizedFold(query, name(item)); rank != -1 {
+			matches = append(matches, ranked{item: item, rank: rank})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].rank != matches[j].rank {
+			return matches[i].rank < matches[j].rank
+		}
+		return strings.ToLower(name(matches[i].item)) < strings.ToLower(name(matches[j].item))
+	})
+
+	results := make([]T, 0, min(len(matches), limit))
+	for _, match := range matches[:min(len(matches), limit)] {
+		results = append(results, match.item)
+	}
+	return results
+}
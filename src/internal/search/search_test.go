@@ -0,0 +1,106 @@
+package search
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alecdray/wax/src/internal/library"
+	"github.com/alecdray/wax/src/internal/spotify"
+
+	spotifyapi "github.com/zmb3/spotify/v2"
+)
+
+func TestRankByName_ClosestMatchFirst(t *testing.T) {
+	artists := []library.ArtistDTO{
+		{ID: "1", Name: "Boards of Canada"},
+		{ID: "2", Name: "Björk"},
+		{ID: "3", Name: "Burial"},
+		{ID: "4", Name: "Bjork Tribute Band"},
+	}
+	name := func(artist library.ArtistDTO) string { return artist.Name }
+
+	got := rankByName(artists, "bjork", name, 5)
+	if len(got) != 2 || got[0].ID != "2" || got[1].ID != "4" {
+		t.Errorf("expected Björk then the tribute band, got %v", got)
+	}
+
+	got = rankByName(artists, "b", name, 2)
+	if len(got) != 2 {
+		t.Errorf("expected the limit to apply, got %v", got)
+	}
+
+	if got := rankByName(artists, "zz", name, 5); len(got) != 0 {
+		t.Errorf("expected no matches, got %v", got)
+	}
+}
+
+func TestNewCatalogueAlbumFromSpotify(t *testing.T) {
+	album := NewCatalogueAlbumFromSpotify(spotify.SimpleAlbum{
+		ID:   "sp-1",
+		Name: "Homogenic",
+		Artists: []spotifyapi.SimpleArtist{
+			{Name: "Björk"},
+		},
+		Images: []spotifyapi.Image{
+			{URL: "large.jpg", Width: 640},
+			{URL: "small.jpg", Width: 64},
+		},
+		ReleaseDate: "1997-09-22",
+	})
+
+	if album.SpotifyID != "sp-1" || album.Title != "Homogenic" {
+		t.Errorf("unexpected album %+v", album)
+	}
+	if len(album.Artists) != 1 || album.Artists[0] != "Björk" {
+		t.Errorf("expected the artist names, got %v", album.Artists)
+	}
+	if album.ImageURL != "small.jpg" {
+		t.Errorf("expected the smallest cover, got %q", album.ImageURL)
+	}
+	if album.Year != "1997" {
+		t.Errorf("expected the release year, got %q", album.Year)
+	}
+}
+
+func TestNormalizeQuery(t *testing.T) {
+	if got := normalizeQuery("  Pink   FLOYD "); got != "pink floyd" {
+		t.Errorf("expected %q, got %q", "pink floyd", got)
+	}
+}
+
+func TestDebouncer_OnlyTheLastCallGoesAhead(t *testing.T) {
+	d := newDebouncer(50 * time.Millisecond)
+
+	var wg sync.WaitGroup
+	results := make([]bool, 3)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = d.settle(context.Background(), "user")
+		}()
+		time.Sleep(10 * time.Millisecond)
+	}
+	wg.Wait()
+
+	if results[0] || results[1] || !results[2] {
+		t.Errorf("expected only the last call to go ahead, got %v", results)
+	}
+
+	// Another user's calls don't hold this one up.
+	if !d.settle(context.Background(), "other") {
+		t.Error("expected a lone call to go ahead")
+	}
+}
+
+func TestDebouncer_CancelledCallDoesNotGoAhead(t *testing.T) {
+	d := newDebouncer(time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if d.settle(ctx, "user") {
+		t.Error("expected a cancelled call not to go ahead")
+	}
+}
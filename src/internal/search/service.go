@@ -0,0 +1,143 @@
+package search
+
+import (
+	"fmt"
+	"github.com/alecdray/wax/src/internal/core/cache"
+	"github.com/alecdray/wax/src/internal/core/contextx"
+	"github.com/alecdray/wax/src/internal/library"
+	"github.com/alecdray/wax/src/internal/spotify"
+	"github.com/alecdray/wax/src/internal/tags"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// minCatalogueQuery is how many characters a query needs before Spotify
+	// is searched; shorter ones match too much to be worth a request.
+	minCatalogueQuery = 3
+	// catalogueLimit is how many albums are asked of Spotify, leaving room
+	// for the ones already in the library.
+	catalogueLimit = 10
+	// Spotify results are cached by query, so
+	// retyping a query, backspacing over it or another user searching the
+	// same thing doesn't search Spotify again.
+	catalogueCacheTTL  = 10 * time.Minute
+	catalogueCacheSize = 500
+	// catalogueDebounce is how long a user has to stop typing before Spotify
+	// is searched.
+	catalogueDebounce = 300 * time.Millisecond
+)
+
+type Service struct {
+	libraryService *library.Service
+	spotifyService *spotify.Service
+	catalogueCache *cache.TTL[[]CatalogueAlbum]
+	debouncer      *debouncer
+}
+
+func NewService(libraryService *library.Service, spotifyService *spotify.Service) *Service {
+	return &Service{
+		libraryService: libraryService,
+		spotifyService: spotifyService,
+		catalogueCache: cache.NewTTL[[]CatalogueAlbum](catalogueCacheTTL, catalogueCacheSize),
+		debouncer:      newDebouncer(catalogueDebounce),
+	}
+}
+
+// Search finds what a query matches across the library and Spotify's
+// catalogue. Spotify being unavailable doesn't fail the search; the library
+// results still come back.
+func (s *Service) Search(ctx contextx.ContextX, userId string, query string) (Results, error) {
+	query = strings.TrimSpace(query)
+	results := Results{Query: query}
+	if query == "" {
+		return results, nil
+	}
+
+	// A query with nothing to match on would list the whole library.
+	if library.SearchMatch(query) != "" {
+		page, err := s.libraryService.QueryAlbums(ctx, userId, library.AlbumsQuery{
+			Filter: library.FilterParams{Search: query},
+			Sort:   library.AlbumSortRelevance,
+			Limit:  groupLimit,
+		})
+		if err != nil {
+			err = fmt.Errorf("failed to search library albums: %w", err)
+			return results, err
+		}
+		results.Albums = page.Albums
+		results.MoreAlbums = page.Next != ""
+	}
+
+	artists, err := s.libraryService.GetLibraryArtists(ctx, userId)
+	if err != nil {
+		err = fmt.Errorf("failed to get library artists: %w", err)
+		return results, err
+	}
+	results.Artists = rankByName(artists, query, func(artist library.ArtistDTO) string { return artist.Name }, groupLimit)
+
+	libraryTags, err := s.libraryService.GetLibraryTags(ctx, userId)
+	if err != nil {
+		err = fmt.Errorf("failed to get library tags: %w", err)
+		return results, err
+	}
+	results.Tags = rankByName(libraryTags, query, func(tag tags.TagDTO) string { return tag.Name }, groupLimit)
+
+	catalogue, err := s.searchCatalogue(ctx, userId, query)
+	if err != nil {
+		slog.Error("failed to search spotify catalogue", "error", err)
+		results.CatalogueUnavailable = true
+		return results, nil
+	}
+	if len(catalogue) == 0 {
+		return results, nil
+	}
+
+	spotifyIds := make([]string, len(catalogue))
+	for i, album := range catalogue {
+		spotifyIds[i] = album.SpotifyID
+	}
+	inLibrary, err := s.libraryService.GetLibraryAlbumIdsBySpotifyIds(ctx, userId, spotifyIds)
+	if err != nil {
+		err = fmt.Errorf("failed to check catalogue albums against the library: %w", err)
+		return results, err
+	}
+	for _, album := range catalogue {
+		if _, ok := inLibrary[album.SpotifyID]; !ok && len(results.Catalogue) < groupLimit {
+			results.Catalogue = append(results.Catalogue, album)
+		}
+	}
+
+	return results, nil
+}
+
+// searchCatalogue searches Spotify's catalogue for albums, from the cache
+// when it can. A search still typing, or one another search from the same
+// user overtook, comes back empty without asking Spotify.
+func (s *Service) searchCatalogue(ctx contextx.ContextX, userId string, query string) ([]CatalogueAlbum, error) {
+	key := normalizeQuery(query)
+	if utf8.RuneCountInString(key) < minCatalogueQuery {
+		return nil, nil
+	}
+	if albums, ok := s.catalogueCache.Get(key); ok {
+		return albums, nil
+	}
+	if !s.debouncer.settle(ctx, userId) {
+		return nil, nil
+	}
+
+	spotifyAlbums, err := s.spotifyService.SearchAlbums(ctx, userId, query, catalogueLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	albums := make([]CatalogueAlbum, len(spotifyAlbums))
+	for i, album := range spotifyAlbums {
+		albums[i] = NewCatalogueAlbumFromSpotify(album)
+	}
+	s.catalogueCache.Put(key, albums)
+
+	return albums, nil
+}
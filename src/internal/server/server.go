@@ -8,6 +8,8 @@ import (
 	"os"
 
 	"github.com/alecdray/wax/src/internal/auth"
+	"github.com/alecdray/wax/src/internal/bulk"
+	bulkAdapters "github.com/alecdray/wax/src/internal/bulk/adapters"
 	"github.com/alecdray/wax/src/internal/core/app"
 	"github.com/alecdray/wax/src/internal/core/contextx"
 	"github.com/alecdray/wax/src/internal/core/db"
@@ -17,14 +19,28 @@ import (
 	"github.com/alecdray/wax/src/internal/feed"
 	"github.com/alecdray/wax/src/internal/library"
 	libraryAdapters "github.com/alecdray/wax/src/internal/library/adapters"
+	"github.com/alecdray/wax/src/internal/links"
+	linksAdapters "github.com/alecdray/wax/src/internal/links/adapters"
 	"github.com/alecdray/wax/src/internal/listeninghistory"
 	"github.com/alecdray/wax/src/internal/musicbrainz"
+	"github.com/alecdray/wax/src/internal/people"
+	peopleAdapters "github.com/alecdray/wax/src/internal/people/adapters"
+	"github.com/alecdray/wax/src/internal/ranklists"
+	ranklistsAdapters "github.com/alecdray/wax/src/internal/ranklists/adapters"
 	"github.com/alecdray/wax/src/internal/review"
 	reviewAdapters "github.com/alecdray/wax/src/internal/review/adapters"
+	"github.com/alecdray/wax/src/internal/search"
+	searchAdapters "github.com/alecdray/wax/src/internal/search/adapters"
+	"github.com/alecdray/wax/src/internal/shelves"
+	shelvesAdapters "github.com/alecdray/wax/src/internal/shelves/adapters"
 	"github.com/alecdray/wax/src/internal/spotify"
+	"github.com/alecdray/wax/src/internal/stats"
+	statsAdapters "github.com/alecdray/wax/src/internal/stats/adapters"
 	"github.com/alecdray/wax/src/internal/tags"
 	tagsAdapters "github.com/alecdray/wax/src/internal/tags/adapters"
 	"github.com/alecdray/wax/src/internal/user"
+	"github.com/alecdray/wax/src/internal/wishlist"
+	wishlistAdapters "github.com/alecdray/wax/src/internal/wishlist/adapters"
 
 	spotifyauth "github.com/zmb3/spotify/v2/auth"
 )
@@ -40,6 +56,14 @@ type services struct {
 	review           *review.Service
 	listeningHistory *listeninghistory.Service
 	tags             *tags.Service
+	ranklists        *ranklists.Service
+	shelves          *shelves.Service
+	wishlist         *wishlist.Service
+	links            *links.Service
+	people           *people.Service
+	bulk             *bulk.Service
+	search           *search.Service
+	stats            *stats.Service
 }
 
 func NewServices(app app.App, db *db.DB) *services {
@@ -78,14 +102,32 @@ func NewServices(app app.App, db *db.DB) *services {
 
 	s.tags = tags.NewService(db)
 
-	s.library = library.NewService(db, s.listeningHistory, s.tags)
+	s.ranklists = ranklists.NewService(db)
 
-	s.feed = feed.NewService(db, s.spotify, s.library)
+	s.shelves = shelves.NewService(db)
+
+	s.review = review.NewService(db)
+	s.taskManager.RegisterCronTask(
+		review.NewRefreshRevisitSuggestionsTask(s.review),
+	)
+
+	s.links = links.NewService(db)
+
+	s.people = people.NewService(db)
+	s.bulk = bulk.NewService(db)
+
+	s.library = library.NewService(db, s.listeningHistory, s.tags, s.review, s.shelves, s.links, s.people)
+
+	s.wishlist = wishlist.NewService(db)
+
+	s.feed = feed.NewService(db, s.spotify, s.library, s.wishlist)
 	s.taskManager.RegisterCronTask(
 		feed.NewSyncStaleSpotifyFeedsTask(s.feed),
 	)
 
-	s.review = review.NewService(db)
+	s.search = search.NewService(s.library, s.spotify)
+
+	s.stats = stats.NewService(db, s.user)
 
 	return s
 }
@@ -113,14 +155,22 @@ func Start(ctx context.Context, app app.App) {
 	rootMux.Handle("/logout", httpx.HandlerFunc(authHandler.Logout))
 	rootMux.Handle("/spotify/callback", httpx.HandlerFunc(authHandler.AuthorizeSpotify))
 
-	appMux := httpx.NewMux(app, httpx.JwtMiddleware(services.spotify, services.user))
+	// Middleware wraps inside-out: the last listed runs first, so the JWT
+	// middleware resolves the user before their rating profile is loaded.
+	appMux := httpx.NewMux(app,
+		reviewAdapters.RatingProfileMiddleware(services.review),
+		httpx.JwtMiddleware(services.spotify, services.user),
+	)
 	rootMux.Use("/app/", appMux)
 
 	libraryHandler := libraryAdapters.NewHttpHandler(
 		services.spotifyAuth,
+		services.spotify,
 		services.musicbrainz,
 		services.feed,
 		services.library,
+		services.shelves,
+		services.people,
 		services.taskManager,
 	)
 	appMux.Handle("/app/library/dashboard", httpx.HandlerFunc(libraryHandler.GetDashboardPage))
@@ -130,17 +180,109 @@ func Start(ctx context.Context, app app.App) {
 	appMux.Handle("GET /app/library/dashboard/albums-page", httpx.HandlerFunc(libraryHandler.GetAlbumsPage))
 	appMux.Handle("GET /app/library/dashboard/carousel", httpx.HandlerFunc(libraryHandler.GetCarousel))
 	appMux.Handle("GET /app/library/albums/{albumId}", httpx.HandlerFunc(libraryHandler.GetAlbumDetailPage))
+	appMux.Handle("GET /app/library/artists/{artistId}", httpx.HandlerFunc(libraryHandler.GetArtistPage))
+	appMux.Handle("GET /app/library/artists/{artistId}/discography", httpx.HandlerFunc(libraryHandler.GetArtistDiscography))
+	appMux.Handle("POST /app/library/artists/{artistId}/sort-name", httpx.HandlerFunc(libraryHandler.SetArtistSortName))
+	appMux.Handle("POST /app/library/artists/{artistId}/aliases", httpx.HandlerFunc(libraryHandler.AddArtistAlias))
+	appMux.Handle("DELETE /app/library/artists/{artistId}/aliases/{aliasId}", httpx.HandlerFunc(libraryHandler.DeleteArtistAlias))
+	appMux.Handle("POST /app/library/artists/{artistId}/merge", httpx.HandlerFunc(libraryHandler.MergeArtist))
 
-	tagsHandler := tagsAdapters.NewHttpHandler(services.library, services.tags)
+	tagsHandler := tagsAdapters.NewHttpHandler(services.library, services.tags, services.musicbrainz)
 	appMux.Handle("GET /app/tags/album", httpx.HandlerFunc(tagsHandler.GetTagsModal))
+	appMux.Handle("GET /app/tags/suggestions", httpx.HandlerFunc(tagsHandler.GetTagSuggestions))
 	appMux.Handle("POST /app/tags/album", httpx.HandlerFunc(tagsHandler.SubmitAlbumTags))
+	appMux.Handle("GET /app/tags", httpx.HandlerFunc(tagsHandler.GetTagsPage))
+	appMux.Handle("POST /app/tags/{tagId}", httpx.HandlerFunc(tagsHandler.UpdateTag))
+	appMux.Handle("POST /app/tags/{tagId}/merge", httpx.HandlerFunc(tagsHandler.MergeTag))
+	appMux.Handle("DELETE /app/tags/{tagId}", httpx.HandlerFunc(tagsHandler.DeleteTag))
+	appMux.Handle("POST /app/tag-groups", httpx.HandlerFunc(tagsHandler.CreateTagGroup))
+	appMux.Handle("POST /app/tag-groups/{groupId}", httpx.HandlerFunc(tagsHandler.UpdateTagGroup))
+	appMux.Handle("POST /app/tag-groups/{groupId}/move", httpx.HandlerFunc(tagsHandler.MoveTagGroup))
+	appMux.Handle("DELETE /app/tag-groups/{groupId}", httpx.HandlerFunc(tagsHandler.DeleteTagGroup))
+
+	ranklistsHandler := ranklistsAdapters.NewHttpHandler(services.ranklists, services.tags)
+	appMux.Handle("GET /app/ranklists", httpx.HandlerFunc(ranklistsHandler.GetRanklistsPage))
+	appMux.Handle("POST /app/ranklists", httpx.HandlerFunc(ranklistsHandler.CreateRanklist))
+	appMux.Handle("GET /app/ranklists/{ranklistId}", httpx.HandlerFunc(ranklistsHandler.GetRanklistPage))
+	appMux.Handle("POST /app/ranklists/{ranklistId}", httpx.HandlerFunc(ranklistsHandler.UpdateRanklist))
+	appMux.Handle("DELETE /app/ranklists/{ranklistId}", httpx.HandlerFunc(ranklistsHandler.DeleteRanklist))
+	appMux.Handle("POST /app/ranklists/{ranklistId}/entries", httpx.HandlerFunc(ranklistsHandler.AddRanklistEntry))
+	appMux.Handle("POST /app/ranklists/{ranklistId}/entries/{entryId}/move", httpx.HandlerFunc(ranklistsHandler.MoveRanklistEntry))
+	appMux.Handle("POST /app/ranklists/{ranklistId}/entries/{entryId}/blurb", httpx.HandlerFunc(ranklistsHandler.SetRanklistEntryBlurb))
+	appMux.Handle("DELETE /app/ranklists/{ranklistId}/entries/{entryId}", httpx.HandlerFunc(ranklistsHandler.RemoveRanklistEntry))
+
+	shelvesHandler := shelvesAdapters.NewHttpHandler(services.library, services.shelves)
+	appMux.Handle("GET /app/shelves", httpx.HandlerFunc(shelvesHandler.GetShelvesPage))
+	appMux.Handle("POST /app/shelves", httpx.HandlerFunc(shelvesHandler.CreateShelf))
+	appMux.Handle("GET /app/shelves/album", httpx.HandlerFunc(shelvesHandler.GetAlbumShelvesModal))
+	appMux.Handle("POST /app/shelves/album", httpx.HandlerFunc(shelvesHandler.SubmitAlbumShelves))
+	appMux.Handle("GET /app/shelves/{shelfId}", httpx.HandlerFunc(shelvesHandler.GetShelfPage))
+	appMux.Handle("POST /app/shelves/{shelfId}", httpx.HandlerFunc(shelvesHandler.UpdateShelf))
+	appMux.Handle("DELETE /app/shelves/{shelfId}", httpx.HandlerFunc(shelvesHandler.DeleteShelf))
+	appMux.Handle("DELETE /app/shelves/{shelfId}/albums/{albumId}", httpx.HandlerFunc(shelvesHandler.RemoveShelfAlbum))
+
+	linksHandler := linksAdapters.NewHttpHandler(services.library, services.links, services.musicbrainz)
+	appMux.Handle("GET /app/links/album", httpx.HandlerFunc(linksHandler.GetAlbumLinkModal))
+	appMux.Handle("POST /app/links/album", httpx.HandlerFunc(linksHandler.SubmitAlbumLink))
+	appMux.Handle("GET /app/links/album/suggestions", httpx.HandlerFunc(linksHandler.GetAlbumLinkSuggestions))
+	appMux.Handle("GET /app/links/graph", httpx.HandlerFunc(linksHandler.GetGraph))
+	appMux.Handle("DELETE /app/links/{linkId}", httpx.HandlerFunc(linksHandler.DeleteLink))
+
+	peopleHandler := peopleAdapters.NewHttpHandler(services.library, services.people)
+	appMux.Handle("GET /app/people", httpx.HandlerFunc(peopleHandler.GetPeoplePage))
+	appMux.Handle("POST /app/people", httpx.HandlerFunc(peopleHandler.CreatePerson))
+	appMux.Handle("GET /app/people/album", httpx.HandlerFunc(peopleHandler.GetAlbumPersonModal))
+	appMux.Handle("POST /app/people/album", httpx.HandlerFunc(peopleHandler.SubmitAlbumPerson))
+	appMux.Handle("DELETE /app/people/album/{albumPersonId}", httpx.HandlerFunc(peopleHandler.RemoveAlbumPerson))
+	appMux.Handle("POST /app/people/{personId}", httpx.HandlerFunc(peopleHandler.UpdatePerson))
+	appMux.Handle("DELETE /app/people/{personId}", httpx.HandlerFunc(peopleHandler.DeletePerson))
+
+	bulkHandler := bulkAdapters.NewHttpHandler(services.bulk)
+	appMux.Handle("POST /app/bulk-edits", httpx.HandlerFunc(bulkHandler.ApplyBulkEdit))
+	appMux.Handle("POST /app/bulk-edits/{editId}/undo", httpx.HandlerFunc(bulkHandler.UndoBulkEdit))
+
+	searchHandler := searchAdapters.NewHttpHandler(services.search, services.feed)
+	appMux.Handle("GET /app/search", httpx.HandlerFunc(searchHandler.Search))
+	appMux.Handle("POST /app/search/catalogue/{spotifyId}", httpx.HandlerFunc(searchHandler.AddCatalogueAlbum))
+
+	statsHandler := statsAdapters.NewHttpHandler(services.stats, services.user, services.tags)
+	appMux.Handle("GET /app/stats", httpx.HandlerFunc(statsHandler.GetStatsPage))
+	appMux.Handle("GET /app/stats/report", httpx.HandlerFunc(statsHandler.GetStatsReport))
+	appMux.Handle("GET /app/stats.json", httpx.HandlerFunc(statsHandler.GetStatsJSON))
+	appMux.Handle("GET /app/stats/listening", httpx.HandlerFunc(statsHandler.GetListeningPage))
+	appMux.Handle("POST /app/stats/timezone", httpx.HandlerFunc(statsHandler.SetTimezone))
+
+	wishlistHandler := wishlistAdapters.NewHttpHandler(services.musicbrainz, services.wishlist)
+	appMux.Handle("GET /app/wishlist", httpx.HandlerFunc(wishlistHandler.GetWishlistPage))
+	appMux.Handle("POST /app/wishlist", httpx.HandlerFunc(wishlistHandler.AddItem))
+	appMux.Handle("GET /app/wishlist/new", httpx.HandlerFunc(wishlistHandler.GetAddItemModal))
+	appMux.Handle("GET /app/wishlist/search", httpx.HandlerFunc(wishlistHandler.SearchAlbums))
+	appMux.Handle("POST /app/wishlist/{itemId}", httpx.HandlerFunc(wishlistHandler.UpdateItem))
+	appMux.Handle("DELETE /app/wishlist/{itemId}", httpx.HandlerFunc(wishlistHandler.DeleteItem))
+	appMux.Handle("POST /app/wishlist/{itemId}/fulfilled", httpx.HandlerFunc(wishlistHandler.SetItemFulfilled))
 
 	reviewHandler := reviewAdapters.NewHttpHandler(services.library, services.review)
 	appMux.Handle("GET /app/review/rating-recommender", httpx.HandlerFunc(reviewHandler.GetRatingRecommender))
 	appMux.Handle("GET /app/review/rating-recommender/questions", httpx.HandlerFunc(reviewHandler.GetRatingRecommenderQuestions))
 	appMux.Handle("POST /app/review/rating-recommender/questions", httpx.HandlerFunc(reviewHandler.SubmitRatingRecommenderQuestions))
+	appMux.Handle("GET /app/review/rating-recommender/compare", httpx.HandlerFunc(reviewHandler.GetRatingRecommenderComparison))
+	appMux.Handle("POST /app/review/rating-recommender/compare", httpx.HandlerFunc(reviewHandler.SubmitRatingRecommenderComparison))
 	appMux.Handle("POST /app/review/rating-recommender/rating", httpx.HandlerFunc(reviewHandler.SubmitRatingRecommenderRating))
 	appMux.Handle("DELETE /app/review/rating-log/{id}", httpx.HandlerFunc(reviewHandler.DeleteRatingLogEntry))
+	appMux.Handle("GET /app/review/rating-profile", httpx.HandlerFunc(reviewHandler.GetRatingProfilePage))
+	appMux.Handle("POST /app/review/rating-profile", httpx.HandlerFunc(reviewHandler.SubmitRatingProfile))
+	appMux.Handle("POST /app/review/rating-profile/preset", httpx.HandlerFunc(reviewHandler.ApplyRatingProfilePreset))
+	appMux.Handle("DELETE /app/review/rating-profile", httpx.HandlerFunc(reviewHandler.ResetRatingProfile))
+	appMux.Handle("POST /app/review/revisit-rules", httpx.HandlerFunc(reviewHandler.SubmitRevisitRules))
+	appMux.Handle("GET /app/review/album-review", httpx.HandlerFunc(reviewHandler.GetAlbumReviewPage))
+	appMux.Handle("POST /app/review/album-review", httpx.HandlerFunc(reviewHandler.SubmitAlbumReview))
+	appMux.Handle("POST /app/review/album-review/preview", httpx.HandlerFunc(reviewHandler.PreviewAlbumReview))
+	appMux.Handle("DELETE /app/review/album-review", httpx.HandlerFunc(reviewHandler.DeleteAlbumReview))
+	appMux.Handle("POST /app/review/track-mark", httpx.HandlerFunc(reviewHandler.SetTrackMark))
+	appMux.Handle("GET /app/review/top-tracks", httpx.HandlerFunc(reviewHandler.GetTopTracksPage))
+	appMux.Handle("GET /app/review/calibration", httpx.HandlerFunc(reviewHandler.GetCalibrationPage))
+	appMux.Handle("GET /app/review/calibration/report", httpx.HandlerFunc(reviewHandler.GetCalibrationReport))
+	appMux.Handle("POST /app/review/calibration/apply", httpx.HandlerFunc(reviewHandler.ApplyCalibration))
 
 	// Not found handler, must be registered after all other handlers
 	rootMux.HandleFunc("/", httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
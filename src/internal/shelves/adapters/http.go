@@ -0,0 +1,407 @@
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"github.com/alecdray/wax/src/internal/core/contextx"
+	"github.com/alecdray/wax/src/internal/core/httpx"
+	"github.com/alecdray/wax/src/internal/core/templates"
+	"github.com/alecdray/wax/src/internal/library"
+	libraryAdapters "github.com/alecdray/wax/src/internal/library/adapters"
+	"github.com/alecdray/wax/src/internal/shelves"
+	"strings"
+)
+
+type HttpHandler struct {
+	libraryService *library.Service
+	shelvesService *shelves.Service
+}
+
+func NewHttpHandler(libraryService *library.Service, shelvesService *shelves.Service) *HttpHandler {
+	return &HttpHandler{
+		libraryService: libraryService,
+		shelvesService: shelvesService,
+	}
+}
+
+func handleShelfError(ctx contextx.ContextX, w http.ResponseWriter, err error) {
+	props := httpx.HandleErrorResponseProps{
+		Status: http.StatusInternalServerError,
+		Err:    err,
+	}
+	switch {
+	case errors.Is(err, shelves.ErrShelfNotFound):
+		props.Status = http.StatusNotFound
+	case errors.Is(err, shelves.ErrInvalidShelf):
+		props.Status = http.StatusUnprocessableEntity
+		props.Response = *httpx.NewErrorResponse().SetComponent(ShelfError(err.Error()))
+	case errors.Is(err, shelves.ErrShelfNameTaken), errors.Is(err, shelves.ErrSmartShelf):
+		props.Status = http.StatusConflict
+		props.Response = *httpx.NewErrorResponse().SetComponent(ShelfError(err.Error()))
+	}
+	httpx.HandleErrorResponse(ctx, w, props)
+}
+
+// shelfAlbums returns the library albums on a shelf, by title.
+func (h *HttpHandler) shelfAlbums(ctx contextx.ContextX, userId string, shelf shelves.ShelfDTO) (library.AlbumDTOs, error) {
+	albums, err := h.libraryService.QueryAllAlbums(ctx, userId, library.AlbumsQuery{
+		Filter:    library.FilterParams{Shelf: &shelf},
+		Sort:      library.AlbumSortTitle,
+		Ascending: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shelf albums: %w", err)
+	}
+	return albums, nil
+}
+
+func (h *HttpHandler) GetShelvesPage(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	userShelves, err := h.shelvesService.GetUserShelves(ctx, userId)
+	if err != nil {
+		handleShelfError(ctx, w, err)
+		return
+	}
+
+	counts := make(map[string]int, len(userShelves))
+	for _, shelf := range userShelves {
+		counts[shelf.ID], err = h.libraryService.CountAlbums(ctx, userId, library.FilterParams{Shelf: &shelf})
+		if err != nil {
+			httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+				Status: http.StatusInternalServerError,
+				Err:    fmt.Errorf("failed to count shelf albums: %w", err),
+			})
+			return
+		}
+	}
+
+	err = ShelvesPage(userShelves, counts).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+// CreateShelf creates a hand-built shelf, or a smart shelf from the library
+// filters posted along with it.
+func (h *HttpHandler) CreateShelf(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	input := shelves.ShelfInput{
+		Name:        r.Form.Get("name"),
+		Description: r.Form.Get("description"),
+	}
+	if r.Form.Get("smart") == "true" {
+		input.Filter, err = library.EncodeShelfFilter(libraryAdapters.ParseFilterParams(ctx, r.Form))
+		if err != nil {
+			handleShelfError(ctx, w, fmt.Errorf("%w: %w", shelves.ErrInvalidShelf, err))
+			return
+		}
+	}
+
+	shelf, err := h.shelvesService.CreateShelf(ctx, userId, input)
+	if err != nil {
+		handleShelfError(ctx, w, err)
+		return
+	}
+
+	err = templates.Redirect(shelfPath(shelf.ID), 0).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) GetShelfPage(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	shelf, err := h.shelvesService.GetShelf(ctx, userId, r.PathValue("shelfId"))
+	if err != nil {
+		handleShelfError(ctx, w, err)
+		return
+	}
+
+	albums, err := h.shelfAlbums(ctx, userId, shelf)
+	if err != nil {
+		handleShelfError(ctx, w, err)
+		return
+	}
+
+	err = ShelfPage(shelf, albums).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) UpdateShelf(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	shelf, err := h.shelvesService.UpdateShelf(ctx, userId, r.PathValue("shelfId"), r.Form.Get("name"), r.Form.Get("description"))
+	if err != nil {
+		handleShelfError(ctx, w, err)
+		return
+	}
+
+	err = ShelfHeader(shelf).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) DeleteShelf(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	err = h.shelvesService.DeleteShelf(ctx, userId, r.PathValue("shelfId"))
+	if err != nil {
+		handleShelfError(ctx, w, err)
+		return
+	}
+
+	err = templates.Redirect("/app/shelves", 0).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) RemoveShelfAlbum(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	shelfId := r.PathValue("shelfId")
+	err = h.shelvesService.RemoveAlbum(ctx, userId, shelfId, r.PathValue("albumId"))
+	if err != nil {
+		handleShelfError(ctx, w, err)
+		return
+	}
+
+	shelf, err := h.shelvesService.GetShelf(ctx, userId, shelfId)
+	if err != nil {
+		handleShelfError(ctx, w, err)
+		return
+	}
+
+	albums, err := h.shelfAlbums(ctx, userId, shelf)
+	if err != nil {
+		handleShelfError(ctx, w, err)
+		return
+	}
+
+	err = ShelfAlbums(shelf, albums).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) GetAlbumShelvesModal(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	albumId := r.URL.Query().Get("albumId")
+	if albumId == "" {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    errors.New("missing album ID"),
+		})
+		return
+	}
+
+	album, err := h.libraryService.GetAlbumInLibrary(ctx, userId, albumId)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get album: %w", err),
+		})
+		return
+	}
+
+	userShelves, err := h.shelvesService.GetUserShelves(ctx, userId)
+	if err != nil {
+		handleShelfError(ctx, w, err)
+		return
+	}
+
+	err = AlbumShelvesModal(*album, userShelves).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+	}
+}
+
+// SubmitAlbumShelves saves the shelves picked for an album, creating a new
+// shelf for it first when one is named.
+func (h *HttpHandler) SubmitAlbumShelves(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	albumId := r.URL.Query().Get("albumId")
+	if albumId == "" {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    errors.New("missing album ID"),
+		})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	shelfIds := r.Form["shelf"]
+	if name := strings.TrimSpace(r.Form.Get("newShelf")); name != "" {
+		shelf, err := h.shelvesService.CreateShelf(ctx, userId, shelves.ShelfInput{Name: name})
+		if err != nil {
+			handleShelfError(ctx, w, err)
+			return
+		}
+		shelfIds = append(shelfIds, shelf.ID)
+	}
+
+	err = h.shelvesService.SetAlbumShelves(ctx, userId, albumId, shelfIds)
+	if err != nil {
+		handleShelfError(ctx, w, err)
+		return
+	}
+
+	album, err := h.libraryService.GetAlbumInLibrary(ctx, userId, albumId)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get album: %w", err),
+		})
+		return
+	}
+
+	err = CloseAlbumShelvesModal().Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+
+	err = libraryAdapters.AlbumShelvesCell(*album, true).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+	}
+}
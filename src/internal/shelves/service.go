@@ -0,0 +1,258 @@
+package shelves
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/alecdray/wax/src/internal/core/db"
+	"github.com/alecdray/wax/src/internal/core/db/sqlc"
+
+	"github.com/google/uuid"
+)
+
+type Service struct {
+	db *db.DB
+}
+
+func NewService(db *db.DB) *Service {
+	return &Service{db: db}
+}
+
+func getShelf(ctx context.Context, tx *db.DB, userId, shelfId string) (ShelfDTO, error) {
+	model, err := tx.Queries().GetShelf(ctx, sqlc.GetShelfParams{
+		ID:     shelfId,
+		UserID: userId,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ShelfDTO{}, ErrShelfNotFound
+	} else if err != nil {
+		return ShelfDTO{}, fmt.Errorf("failed to get shelf: %w", err)
+	}
+	return newShelfDTOFromModel(model), nil
+}
+
+// checkShelfName returns ErrShelfNameTaken when another of the user's
+// shelves already has the name.
+func checkShelfName(ctx context.Context, tx *db.DB, userId, shelfId, name string) error {
+	existing, err := tx.Queries().GetShelfByName(ctx, sqlc.GetShelfByNameParams{
+		UserID: userId,
+		Name:   name,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get shelf: %w", err)
+	}
+	if existing.ID != shelfId {
+		return ErrShelfNameTaken
+	}
+	return nil
+}
+
+// GetUserShelves returns the user's shelves by name.
+func (s *Service) GetUserShelves(ctx context.Context, userId string) ([]ShelfDTO, error) {
+	models, err := s.db.Queries().GetShelvesByUserId(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shelves: %w", err)
+	}
+	dtos := make([]ShelfDTO, 0, len(models))
+	for _, model := range models {
+		dtos = append(dtos, newShelfDTOFromModel(model))
+	}
+	return dtos, nil
+}
+
+func (s *Service) GetShelf(ctx context.Context, userId, shelfId string) (ShelfDTO, error) {
+	return getShelf(ctx, s.db, userId, shelfId)
+}
+
+// CreateShelf creates a shelf, which is a smart shelf when the input carries
+// a saved filter.
+func (s *Service) CreateShelf(ctx context.Context, userId string, input ShelfInput) (ShelfDTO, error) {
+	input = input.Normalize()
+	err := input.Validate()
+	if err != nil {
+		return ShelfDTO{}, fmt.Errorf("%w: %w", ErrInvalidShelf, err)
+	}
+
+	var shelf ShelfDTO
+	err = s.db.WithTx(func(tx *db.DB) error {
+		err := checkShelfName(ctx, tx, userId, "", input.Name)
+		if err != nil {
+			return err
+		}
+		model, err := tx.Queries().CreateShelf(ctx, sqlc.CreateShelfParams{
+			ID:          uuid.NewString(),
+			UserID:      userId,
+			Name:        input.Name,
+			Description: input.Description,
+			Filter:      sql.NullString{String: input.Filter, Valid: input.Filter != ""},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create shelf: %w", err)
+		}
+		shelf = newShelfDTOFromModel(model)
+		return nil
+	})
+	if err != nil {
+		return ShelfDTO{}, err
+	}
+	return shelf, nil
+}
+
+// UpdateShelf renames a shelf and replaces its description. Whether a shelf
+// is smart can't change once it's created.
+func (s *Service) UpdateShelf(ctx context.Context, userId, shelfId, name, description string) (ShelfDTO, error) {
+	input := ShelfInput{Name: name, Description: description}.Normalize()
+	err := input.Validate()
+	if err != nil {
+		return ShelfDTO{}, fmt.Errorf("%w: %w", ErrInvalidShelf, err)
+	}
+
+	var shelf ShelfDTO
+	err = s.db.WithTx(func(tx *db.DB) error {
+		_, err := getShelf(ctx, tx, userId, shelfId)
+		if err != nil {
+			return err
+		}
+		err = checkShelfName(ctx, tx, userId, shelfId, input.Name)
+		if err != nil {
+			return err
+		}
+		err = tx.Queries().UpdateShelf(ctx, sqlc.UpdateShelfParams{
+			Name:        input.Name,
+			Description: input.Description,
+			ID:          shelfId,
+			UserID:      userId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update shelf: %w", err)
+		}
+		shelf, err = getShelf(ctx, tx, userId, shelfId)
+		return err
+	})
+	if err != nil {
+		return ShelfDTO{}, err
+	}
+	return shelf, nil
+}
+
+func (s *Service) DeleteShelf(ctx context.Context, userId, shelfId string) error {
+	return s.db.WithTx(func(tx *db.DB) error {
+		_, err := getShelf(ctx, tx, userId, shelfId)
+		if err != nil {
+			return err
+		}
+		err = tx.Queries().DeleteShelfAlbumsByShelfId(ctx, shelfId)
+		if err != nil {
+			return fmt.Errorf("failed to delete shelf albums: %w", err)
+		}
+		err = tx.Queries().DeleteShelf(ctx, sqlc.DeleteShelfParams{
+			ID:     shelfId,
+			UserID: userId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete shelf: %w", err)
+		}
+		return nil
+	})
+}
+
+// AddAlbum puts an album on a shelf. Adding an album that's already there
+// does nothing.
+func (s *Service) AddAlbum(ctx context.Context, userId, shelfId, albumId string) error {
+	return s.db.WithTx(func(tx *db.DB) error {
+		shelf, err := getShelf(ctx, tx, userId, shelfId)
+		if err != nil {
+			return err
+		}
+		if shelf.IsSmart() {
+			return ErrSmartShelf
+		}
+		err = tx.Queries().AddShelfAlbum(ctx, sqlc.AddShelfAlbumParams{
+			ShelfID: shelfId,
+			AlbumID: albumId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add album to shelf: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *Service) RemoveAlbum(ctx context.Context, userId, shelfId, albumId string) error {
+	return s.db.WithTx(func(tx *db.DB) error {
+		shelf, err := getShelf(ctx, tx, userId, shelfId)
+		if err != nil {
+			return err
+		}
+		if shelf.IsSmart() {
+			return ErrSmartShelf
+		}
+		err = tx.Queries().DeleteShelfAlbum(ctx, sqlc.DeleteShelfAlbumParams{
+			ShelfID: shelfId,
+			AlbumID: albumId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to remove album from shelf: %w", err)
+		}
+		return nil
+	})
+}
+
+// SetAlbumShelves puts an album on exactly the given hand-built shelves,
+// taking it off the user's others. Smart shelves are left alone.
+func (s *Service) SetAlbumShelves(ctx context.Context, userId, albumId string, shelfIds []string) error {
+	selected := make(map[string]bool, len(shelfIds))
+	for _, shelfId := range shelfIds {
+		selected[shelfId] = true
+	}
+
+	return s.db.WithTx(func(tx *db.DB) error {
+		models, err := tx.Queries().GetShelvesByUserId(ctx, userId)
+		if err != nil {
+			return fmt.Errorf("failed to get shelves: %w", err)
+		}
+		for _, model := range models {
+			if newShelfDTOFromModel(model).IsSmart() {
+				continue
+			}
+			if selected[model.ID] {
+				err = tx.Queries().AddShelfAlbum(ctx, sqlc.AddShelfAlbumParams{
+					ShelfID: model.ID,
+					AlbumID: albumId,
+				})
+			} else {
+				err = tx.Queries().DeleteShelfAlbum(ctx, sqlc.DeleteShelfAlbumParams{
+					ShelfID: model.ID,
+					AlbumID: albumId,
+				})
+			}
+			if err != nil {
+				return fmt.Errorf("failed to update album shelves: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetShelfIdsByAlbumIds returns a map of albumId → the hand-built shelves
+// it's on, for bulk fetching.
+func (s *Service) GetShelfIdsByAlbumIds(ctx context.Context, userId string, albumIds []string) (map[string][]string, error) {
+	if len(albumIds) == 0 {
+		return map[string][]string{}, nil
+	}
+	rows, err := s.db.Queries().GetShelfAlbumsByAlbumIds(ctx, sqlc.GetShelfAlbumsByAlbumIdsParams{
+		UserID:   userId,
+		AlbumIds: albumIds,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shelf albums: %w", err)
+	}
+	result := make(map[string][]string, len(albumIds))
+	for _, row := range rows {
+		result[row.AlbumID] = append(result[row.AlbumID], row.ShelfID)
+	}
+	return result, nil
+}
@@ -0,0 +1,153 @@
+package shelves
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/alecdray/wax/src/internal/core/db"
+	"github.com/alecdray/wax/src/internal/core/db/dbtest"
+)
+
+func newTestService(t *testing.T) (*Service, *db.DB) {
+	database := dbtest.New(t)
+	return NewService(database), database
+}
+
+// seedShelves creates two users, albums a1 to a3, and for u1 two hand-built
+// shelves and a smart one.
+func seedShelves(t *testing.T, service *Service, database *db.DB) (ShelfDTO, ShelfDTO, ShelfDTO) {
+	t.Helper()
+	ctx := context.Background()
+	dbtest.Exec(t, database, "INSERT INTO users (id, spotify_id) VALUES ('u1', 'u1'), ('u2', 'u2')")
+	dbtest.Exec(t, database, "INSERT INTO albums (id, spotify_id, title) VALUES ('a1', 'a1', 'One'), ('a2', 'a2', 'Two'), ('a3', 'a3', 'Three')")
+
+	create := func(input ShelfInput) ShelfDTO {
+		t.Helper()
+		shelf, err := service.CreateShelf(ctx, "u1", input)
+		if err != nil {
+			t.Fatalf("failed to create shelf %q: %v", input.Name, err)
+		}
+		return shelf
+	}
+	return create(ShelfInput{Name: "lent out"}), create(ShelfInput{Name: "Favorites"}), create(ShelfInput{Name: "Keepers", Filter: `{"minRating":8}`})
+}
+
+func TestGetUserShelves_OrdersByNameIgnoringCase(t *testing.T) {
+	service, database := newTestService(t)
+	seedShelves(t, service, database)
+	ctx := context.Background()
+
+	if _, err := service.CreateShelf(ctx, "u2", ShelfInput{Name: "Another user's"}); err != nil {
+		t.Fatalf("failed to create shelf: %v", err)
+	}
+
+	shelves, err := service.GetUserShelves(ctx, "u1")
+	if err != nil {
+		t.Fatalf("failed to get shelves: %v", err)
+	}
+	var names []string
+	for _, shelf := range shelves {
+		names = append(names, shelf.Name)
+	}
+	if want := []string{"Favorites", "Keepers", "lent out"}; !slices.Equal(names, want) {
+		t.Errorf("expected %v, got %v", want, names)
+	}
+	if !shelves[1].IsSmart() || shelves[0].IsSmart() {
+		t.Errorf("expected only Keepers to be smart, got %+v", shelves)
+	}
+
+	if _, err := service.CreateShelf(ctx, "u1", ShelfInput{Name: " Favorites "}); !errors.Is(err, ErrShelfNameTaken) {
+		t.Errorf("expected a taken name to be rejected, got %v", err)
+	}
+}
+
+func TestShelfMembership_HandBuiltShelves(t *testing.T) {
+	service, database := newTestService(t)
+	lent, favorites, keepers := seedShelves(t, service, database)
+	ctx := context.Background()
+
+	for _, step := range []struct{ shelfId, albumId string }{
+		{lent.ID, "a1"}, {lent.ID, "a1"}, {favorites.ID, "a1"}, {favorites.ID, "a2"},
+	} {
+		if err := service.AddAlbum(ctx, "u1", step.shelfId, step.albumId); err != nil {
+			t.Fatalf("failed to add %s: %v", step.albumId, err)
+		}
+	}
+
+	membership := func() map[string][]string {
+		t.Helper()
+		result, err := service.GetShelfIdsByAlbumIds(ctx, "u1", []string{"a1", "a2", "a3"})
+		if err != nil {
+			t.Fatalf("failed to get membership: %v", err)
+		}
+		for _, shelfIds := range result {
+			slices.Sort(shelfIds)
+		}
+		return result
+	}
+	sorted := func(ids ...string) []string {
+		slices.Sort(ids)
+		return ids
+	}
+
+	got := membership()
+	if !slices.Equal(got["a1"], sorted(lent.ID, favorites.ID)) || !slices.Equal(got["a2"], []string{favorites.ID}) || len(got["a3"]) != 0 {
+		t.Fatalf("expected a1 on both shelves once and a2 on Favorites, got %v", got)
+	}
+
+	// Picking shelves for an album takes it off the others.
+	if err := service.SetAlbumShelves(ctx, "u1", "a1", []string{favorites.ID, keepers.ID}); err != nil {
+		t.Fatalf("failed to set shelves: %v", err)
+	}
+	if err := service.RemoveAlbum(ctx, "u1", favorites.ID, "a2"); err != nil {
+		t.Fatalf("failed to remove album: %v", err)
+	}
+	got = membership()
+	if !slices.Equal(got["a1"], []string{favorites.ID}) || len(got["a2"]) != 0 {
+		t.Errorf("expected a1 only on Favorites and a2 on nothing, got %v", got)
+	}
+
+	// Another user can't see or fill the shelves.
+	if err := service.AddAlbum(ctx, "u2", favorites.ID, "a3"); !errors.Is(err, ErrShelfNotFound) {
+		t.Errorf("expected another user's shelf to be not found, got %v", err)
+	}
+	if other, err := service.GetShelfIdsByAlbumIds(ctx, "u2", []string{"a1"}); err != nil || len(other) != 0 {
+		t.Errorf("expected no membership for another user, got %v, %v", other, err)
+	}
+
+	if err := service.DeleteShelf(ctx, "u1", favorites.ID); err != nil {
+		t.Fatalf("failed to delete shelf: %v", err)
+	}
+	if got := membership(); len(got) != 0 {
+		t.Errorf("expected deleting the shelf to empty it, got %v", got)
+	}
+}
+
+func TestShelfMembership_SmartShelvesFollowTheirFilter(t *testing.T) {
+	service, database := newTestService(t)
+	_, _, keepers := seedShelves(t, service, database)
+	ctx := context.Background()
+
+	if err := service.AddAlbum(ctx, "u1", keepers.ID, "a1"); !errors.Is(err, ErrSmartShelf) {
+		t.Errorf("expected adding by hand to a smart shelf to fail, got %v", err)
+	}
+	if err := service.RemoveAlbum(ctx, "u1", keepers.ID, "a1"); !errors.Is(err, ErrSmartShelf) {
+		t.Errorf("expected removing by hand from a smart shelf to fail, got %v", err)
+	}
+	if err := service.SetAlbumShelves(ctx, "u1", "a1", []string{keepers.ID}); err != nil {
+		t.Fatalf("failed to set shelves: %v", err)
+	}
+	if got, err := service.GetShelfIdsByAlbumIds(ctx, "u1", []string{"a1"}); err != nil || len(got) != 0 {
+		t.Errorf("expected a smart shelf to hold no albums by hand, got %v, %v", got, err)
+	}
+
+	// Renaming keeps the saved filter.
+	if _, err := service.UpdateShelf(ctx, "u1", keepers.ID, "Keepers only", ""); err != nil {
+		t.Fatalf("failed to update shelf: %v", err)
+	}
+	if shelf, err := service.GetShelf(ctx, "u1", keepers.ID); err != nil || shelf.Filter != `{"minRating":8}` {
+		t.Errorf("expected the filter kept, got %+v, %v", shelf, err)
+	}
+}
@@ -0,0 +1,80 @@
+package shelves
+
+import (
+	"errors"
+	"fmt"
+	"github.com/alecdray/wax/src/internal/core/db/sqlc"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	shelfNameMaxLength        = 100
+	shelfDescriptionMaxLength = 1000
+)
+
+var (
+	ErrInvalidShelf   = errors.New("invalid shelf")
+	ErrShelfNotFound  = errors.New("shelf not found")
+	ErrShelfNameTaken = errors.New("a shelf with that name already exists")
+	// ErrSmartShelf is returned when adding or removing albums by hand on a
+	// shelf whose albums come from a saved filter.
+	ErrSmartShelf = errors.New("albums on a smart shelf follow its filter")
+)
+
+type ShelfDTO struct {
+	ID          string
+	Name        string
+	Description string
+	// Filter is the saved library filter of a smart shelf, as JSON. Shelves
+	// store it opaquely; the library decides which albums it matches.
+	Filter    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func newShelfDTOFromModel(model sqlc.Shelf) ShelfDTO {
+	return ShelfDTO{
+		ID:          model.ID,
+		Name:        model.Name,
+		Description: model.Description,
+		Filter:      model.Filter.String,
+		CreatedAt:   model.CreatedAt,
+		UpdatedAt:   model.UpdatedAt,
+	}
+}
+
+// IsSmart reports whether the shelf's albums come from a saved filter rather
+// than being added by hand.
+func (s ShelfDTO) IsSmart() bool {
+	return s.Filter != ""
+}
+
+type ShelfInput struct {
+	Name        string
+	Description string
+	// Filter makes the shelf a smart shelf. It can only be set when the
+	// shelf is created.
+	Filter string
+}
+
+func (in ShelfInput) Normalize() ShelfInput {
+	in.Name = strings.TrimSpace(in.Name)
+	in.Description = strings.TrimSpace(in.Description)
+	in.Filter = strings.TrimSpace(in.Filter)
+	return in
+}
+
+func (in ShelfInput) Validate() error {
+	var errs []error
+	if in.Name == "" {
+		errs = append(errs, errors.New("a name is required"))
+	} else if utf8.RuneCountInString(in.Name) > shelfNameMaxLength {
+		errs = append(errs, fmt.Errorf("the name can be at most %d characters", shelfNameMaxLength))
+	}
+	if utf8.RuneCountInString(in.Description) > shelfDescriptionMaxLength {
+		errs = append(errs, fmt.Errorf("the description can be at most %d characters", shelfDescriptionMaxLength))
+	}
+	return errors.Join(errs...)
+}
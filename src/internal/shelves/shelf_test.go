@@ -0,0 +1,40 @@
+package shelves
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShelfInput_Validate(t *testing.T) {
+	cases := []struct {
+		name  string
+		input ShelfInput
+		ok    bool
+	}{
+		{"hand-picked", ShelfInput{Name: "Sunday morning"}, true},
+		{"smart", ShelfInput{Name: "Classics", Filter: `{"minRating":8}`}, true},
+		{"missing name", ShelfInput{Name: "  "}, false},
+		{"name too long", ShelfInput{Name: strings.Repeat("a", 101)}, false},
+		{"description too long", ShelfInput{Name: "Lent to Sam", Description: strings.Repeat("a", 1001)}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.input.Normalize().Validate()
+			if c.ok && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !c.ok && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestShelfDTO_IsSmart(t *testing.T) {
+	if (ShelfDTO{Name: "Sunday morning"}).IsSmart() {
+		t.Error("a shelf without a filter is hand-picked")
+	}
+	if !(ShelfDTO{Name: "Classics", Filter: `{"minRating":8}`}).IsSmart() {
+		t.Error("a shelf with a filter is smart")
+	}
+}
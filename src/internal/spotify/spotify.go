@@ -10,7 +10,10 @@ import (
 )
 
 type (
-	SavedAlbum = spotify.SavedAlbum
+	SavedAlbum  = spotify.SavedAlbum
+	SimpleAlbum = spotify.SimpleAlbum
+	FullAlbum   = spotify.FullAlbum
+	SimpleTrack = spotify.SimpleTrack
 )
 
 const maxCallsPerFunc = 10
@@ -160,3 +163,65 @@ func (s *Service) GetUsersSavedTracks(ctx contextx.ContextX, userId string) ([]s
 	}
 	return collectedTracks, nil
 }
+
+// SearchAlbums searches Spotify's catalogue for albums matching a free-text
+// query, best match first.
+func (s *Service) SearchAlbums(ctx contextx.ContextX, userId string, query string, limit int) ([]spotify.SimpleAlbum, error) {
+	client, err := s.Client(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := client.Search(ctx, query, spotify.SearchTypeAlbum, spotify.Limit(limit))
+	if err != nil {
+		return nil, err
+	}
+	if results.Albums == nil {
+		return []spotify.SimpleAlbum{}, nil
+	}
+
+	return results.Albums.Albums, nil
+}
+
+// GetAlbum gets an album from Spotify's catalogue, with its first page of
+// tracks.
+func (s *Service) GetAlbum(ctx contextx.ContextX, userId string, albumId string) (*spotify.FullAlbum, error) {
+	client, err := s.Client(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.GetAlbum(ctx, spotify.ID(albumId))
+}
+
+// maxArtistAlbums bounds how much of a prolific artist's discography is
+// fetched.
+const maxArtistAlbums = 200
+
+// GetArtistAlbums gets an artist's albums and singles from Spotify's
+// catalogue, albums first, each newest first.
+func (s *Service) GetArtistAlbums(ctx contextx.ContextX, userId string, artistId string) ([]spotify.SimpleAlbum, error) {
+	client, err := s.Client(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	albumTypes := []spotify.AlbumType{spotify.AlbumTypeAlbum, spotify.AlbumTypeSingle}
+	collectedAlbums := make([]spotify.SimpleAlbum, 0)
+	limit := 50
+	offset := 0
+	for offset < maxArtistAlbums {
+		albums, err := client.GetArtistAlbums(ctx, spotify.ID(artistId), albumTypes, spotify.Limit(limit), spotify.Offset(offset))
+		if err != nil {
+			return nil, err
+		}
+
+		collectedAlbums = append(collectedAlbums, albums.Albums...)
+
+		if len(albums.Albums) < limit {
+			break
+		}
+		offset += len(albums.Albums)
+	}
+	return collectedAlbums, nil
+}
@@ -0,0 +1,202 @@
+package adapters
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/alecdray/wax/src/internal/core/contextx"
+	"github.com/alecdray/wax/src/internal/core/httpx"
+	"github.com/alecdray/wax/src/internal/core/templates"
+	"github.com/alecdray/wax/src/internal/review"
+	"github.com/alecdray/wax/src/internal/stats"
+	"github.com/alecdray/wax/src/internal/tags"
+	"github.com/alecdray/wax/src/internal/user"
+	"net/http"
+)
+
+type HttpHandler struct {
+	statsService *stats.Service
+	userService  *user.Service
+	tagsService  *tags.Service
+}
+
+func NewHttpHandler(statsService *stats.Service, userService *user.Service, tagsService *tags.Service) *HttpHandler {
+	return &HttpHandler{
+		statsService: statsService,
+		userService:  userService,
+		tagsService:  tagsService,
+	}
+}
+
+func handleStatsError(ctx contextx.ContextX, w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, stats.ErrInvalidRange), errors.Is(err, user.ErrInvalidTimezone):
+		status = http.StatusUnprocessableEntity
+	case errors.Is(err, stats.ErrAlbumNotFound), errors.Is(err, stats.ErrTagNotFound):
+		status = http.StatusNotFound
+	}
+	httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+		Status:   status,
+		Err:      err,
+		Response: *httpx.NewErrorResponse().SetComponent(StatsError(err.Error())),
+	})
+}
+
+// getStats returns the user's stats over the range in the request's from and
+// to days, answering with the error itself when it can't.
+func (h *HttpHandler) getStats(ctx contextx.ContextX, w http.ResponseWriter, r *http.Request) (stats.Stats, bool) {
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return stats.Stats{}, false
+	}
+
+	statsRange, err := stats.ParseRange(r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	if err != nil {
+		handleStatsError(ctx, w, err)
+		return stats.Stats{}, false
+	}
+
+	userStats, err := h.statsService.GetStats(ctx, userId, statsRange)
+	if err != nil {
+		handleStatsError(ctx, w, err)
+		return stats.Stats{}, false
+	}
+	return userStats, true
+}
+
+func (h *HttpHandler) GetStatsPage(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userStats, ok := h.getStats(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	err := StatsPage(userStats).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+// GetStatsReport renders the stats for the range picked on the stats page.
+func (h *HttpHandler) GetStatsReport(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userStats, ok := h.getStats(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	err := StatsReport(userStats).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+// GetStatsJSON answers with the stats as JSON, ratings on the user's rating
+// scale.
+func (h *HttpHandler) GetStatsJSON(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userStats, ok := h.getStats(ctx, w, r)
+	if !ok {
+		return
+	}
+
+	profile := review.RatingProfileFromContext(ctx)
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(userStats.OnScale(profile.ToScale))
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to encode stats: %w", err),
+		})
+	}
+}
+
+// GetListeningPage shows when the user listens, narrowed to the album or tag
+// in the request.
+func (h *HttpHandler) GetListeningPage(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	filter := stats.ListeningFilter{
+		AlbumID: r.URL.Query().Get("album"),
+		TagID:   r.URL.Query().Get("tag"),
+	}
+	listening, err := h.statsService.GetListening(ctx, userId, filter)
+	if err != nil {
+		handleStatsError(ctx, w, err)
+		return
+	}
+
+	userTags, err := h.tagsService.GetUserTags(ctx, userId)
+	if err != nil {
+		handleStatsError(ctx, w, err)
+		return
+	}
+
+	err = ListeningPage(listening, userTags).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+// SetTimezone sets the timezone the user's stats are counted in, then
+// reloads the listening page they set it from.
+func (h *HttpHandler) SetTimezone(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	err = h.userService.SetTimezone(ctx, userId, r.FormValue("timezone"))
+	if err != nil {
+		handleStatsError(ctx, w, err)
+		return
+	}
+
+	filter := stats.ListeningFilter{
+		AlbumID: r.FormValue("album"),
+		TagID:   r.FormValue("tag"),
+	}
+	err = templates.Redirect(string(listeningURL(filter)), 0).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
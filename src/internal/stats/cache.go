@@ -0,0 +1,48 @@
+package stats
+
+import (
+	"time"
+
+	"github.com/alecdray/wax/src/internal/core/cache"
+)
+
+// statsCache holds computed stats by user and what they cover, so viewing a
+// stats page again doesn't recompute them over the whole library. An entry is only
+// served while the library's version still matches the one it was computed
+// at; the TTL covers what the version doesn't see, like renamed tags and the
+// day moving on under an open range.
+type statsCache[T any] struct {
+	entries *cache.TTL[statsEntry[T]]
+}
+
+type statsEntry[T any] struct {
+	stats   T
+	version string
+}
+
+func newStatsCache[T any](ttl time.Duration, size int) *statsCache[T] {
+	return &statsCache[T]{entries: cache.NewTTL[statsEntry[T]](ttl, size)}
+}
+
+func statsCacheKey(userId string, location *time.Location, r Range) string {
+	return userId + "|" + location.String() + "|" + r.key()
+}
+
+func listeningCacheKey(userId string, location *time.Location, filter ListeningFilter) string {
+	return userId + "|" + location.String() + "|" + filter.AlbumID + "|" + filter.TagID
+}
+
+func (c *statsCache[T]) get(key, version string) (T, bool) {
+	entry, ok := c.entries.Get(key)
+	if !ok || entry.version != version {
+		var zero T
+		return zero, false
+	}
+	return entry.stats, true
+}
+
+// put stores a user's stats under a key, at the library version they were
+// computed at.
+func (c *statsCache[T]) put(key, version string, stats T) {
+	c.entries.Put(key, statsEntry[T]{stats: stats, version: version})
+}
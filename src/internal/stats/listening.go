@@ -0,0 +1,173 @@
+package stats
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// heatmapWeeks is how many weeks the heatmap spans, ending with this one.
+	heatmapWeeks = 53
+	// heatmapLevels is how many shades the heatmap's busier days spread over.
+	heatmapLevels = 4
+)
+
+var (
+	ErrAlbumNotFound = errors.New("album not found")
+	ErrTagNotFound   = errors.New("tag not found")
+)
+
+// ListeningFilter narrows the listening stats to an album's plays, or to the
+// plays of albums with a tag or any of its subtags.
+type ListeningFilter struct {
+	AlbumID string
+	TagID   string
+}
+
+func (f ListeningFilter) IsEmpty() bool {
+	return f.AlbumID == "" && f.TagID == ""
+}
+
+// HeatmapDay is a day's plays. Date is the day in the user's timezone, held
+// as that date in UTC.
+type HeatmapDay struct {
+	Date  time.Time `json:"date"`
+	Plays int       `json:"plays"`
+}
+
+// HeatmapMonth labels the week of the heatmap a month starts in.
+type HeatmapMonth struct {
+	Start time.Time `json:"start"`
+	Week  int       `json:"week"`
+}
+
+// Heatmap is a year of daily plays, a column per week from Monday. The last
+// week stops at today.
+type Heatmap struct {
+	Weeks    [][]HeatmapDay `json:"weeks"`
+	Months   []HeatmapMonth `json:"months"`
+	MaxPlays int            `json:"maxPlays"`
+}
+
+// Level shades a day from 0, for no plays, up to the heatmap's busiest day.
+func (h Heatmap) Level(day HeatmapDay) int {
+	if day.Plays == 0 || h.MaxPlays == 0 {
+		return 0
+	}
+	return (day.Plays*heatmapLevels + h.MaxPlays - 1) / h.MaxPlays
+}
+
+// Streak is a run of consecutive days with plays.
+type Streak struct {
+	Days  int       `json:"days"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Listening is when the user listens, counted in their timezone.
+type Listening struct {
+	Filter ListeningFilter `json:"-"`
+	// FilterName is the filtered album's title or tag's name.
+	FilterName    string    `json:"filter,omitempty"`
+	Timezone      string    `json:"timezone"`
+	Plays         int       `json:"plays"`
+	Heatmap       Heatmap   `json:"heatmap"`
+	CurrentStreak Streak    `json:"currentStreak"`
+	LongestStreak Streak    `json:"longestStreak"`
+	Hours         [24]int   `json:"hours"`
+	Weekdays      [7]int    `json:"weekdays"`
+	ComputedAt    time.Time `json:"computedAt"`
+}
+
+func (l Listening) MaxHourPlays() int {
+	return maxCount(l.Hours[:])
+}
+
+func (l Listening) MaxWeekdayPlays() int {
+	return maxCount(l.Weekdays[:])
+}
+
+func maxCount(counts []int) int {
+	highest := 0
+	for _, count := range counts {
+		highest = max(highest, count)
+	}
+	return highest
+}
+
+// computeListening counts plays by day, hour and weekday in now's timezone.
+// The heatmap covers the last year, while the streaks and distributions
+// cover every play.
+func computeListening(playTimes []time.Time, now time.Time) Listening {
+	location := now.Location()
+	listening := Listening{
+		Timezone:   location.String(),
+		Plays:      len(playTimes),
+		ComputedAt: now,
+	}
+
+	daily := make(map[time.Time]int)
+	for _, t := range playTimes {
+		t = t.In(location)
+		daily[dayKey(t)]++
+		listening.Hours[t.Hour()]++
+		listening.Weekdays[weekdayIndex(t)]++
+	}
+
+	listening.Heatmap = computeHeatmap(daily, now)
+	listening.CurrentStreak, listening.LongestStreak = computeStreaks(daily, now)
+	return listening
+}
+
+// dayKey is a day as a date in UTC, so days can be keyed and stepped through
+// without daylight saving time shortening or lengthening them.
+func dayKey(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func computeHeatmap(daily map[time.Time]int, now time.Time) Heatmap {
+	today := dayKey(now)
+	first := today.AddDate(0, 0, -weekdayIndex(today)-7*(heatmapWeeks-1))
+
+	heatmap := Heatmap{
+		Weeks:  make([][]HeatmapDay, 0, heatmapWeeks),
+		Months: []HeatmapMonth{},
+	}
+	for day := first; !day.After(today); day = day.AddDate(0, 0, 1) {
+		if weekdayIndex(day) == 0 {
+			heatmap.Weeks = append(heatmap.Weeks, make([]HeatmapDay, 0, 7))
+		}
+		week := len(heatmap.Weeks) - 1
+		heatmap.Weeks[week] = append(heatmap.Weeks[week], HeatmapDay{Date: day, Plays: daily[day]})
+		heatmap.MaxPlays = max(heatmap.MaxPlays, daily[day])
+		if day.Day() == 1 {
+			heatmap.Months = append(heatmap.Months, HeatmapMonth{Start: day, Week: week})
+		}
+	}
+	return heatmap
+}
+
+// computeStreaks finds the current streak, which is still alive until a
+// whole day passes without plays, and the longest ever.
+func computeStreaks(daily map[time.Time]int, now time.Time) (Streak, Streak) {
+	var current, longest Streak
+	today := dayKey(now)
+	for day := range daily {
+		// Only the first day of each run is walked forward from.
+		if daily[day.AddDate(0, 0, -1)] > 0 {
+			continue
+		}
+		streak := Streak{Start: day, End: day, Days: 1}
+		for daily[streak.End.AddDate(0, 0, 1)] > 0 {
+			streak.End = streak.End.AddDate(0, 0, 1)
+			streak.Days++
+		}
+		if streak.Days > longest.Days || (streak.Days == longest.Days && streak.End.After(longest.End)) {
+			longest = streak
+		}
+		if streak.End.Equal(today) || streak.End.Equal(today.AddDate(0, 0, -1)) {
+			current = streak
+		}
+	}
+	return current, longest
+}
@@ -0,0 +1,81 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeListening(t *testing.T) {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("failed to load location: %v", err)
+	}
+	local := func(month time.Month, d, hour, minute int) time.Time {
+		return time.Date(2026, month, d, hour, minute, 0, 0, location)
+	}
+
+	plays := []time.Time{
+		local(time.January, 1, 12, 0),
+		local(time.January, 2, 12, 0),
+		local(time.January, 3, 12, 0),
+		local(time.January, 4, 12, 0),
+		local(time.January, 5, 12, 0),
+		// The clocks go forward on March 8, so the run crosses a 23 hour day.
+		local(time.March, 7, 23, 30),
+		local(time.March, 8, 12, 0),
+		// Still March 8 in New York, though March 9 in UTC.
+		time.Date(2026, time.March, 9, 3, 0, 0, 0, time.UTC),
+		local(time.March, 9, 1, 30),
+		local(time.March, 10, 9, 0),
+	}
+	listening := computeListening(plays, local(time.March, 10, 10, 0))
+
+	if listening.Plays != 10 || listening.Timezone != "America/New_York" {
+		t.Errorf("expected 10 plays in New York, got %d in %s", listening.Plays, listening.Timezone)
+	}
+	if listening.CurrentStreak.Days != 4 || listening.CurrentStreak.Start != dayKey(local(time.March, 7, 0, 0)) {
+		t.Errorf("expected a 4 day streak from March 7, got %+v", listening.CurrentStreak)
+	}
+	if listening.LongestStreak.Days != 5 || listening.LongestStreak.End != dayKey(local(time.January, 5, 0, 0)) {
+		t.Errorf("expected the 5 days of January as the longest, got %+v", listening.LongestStreak)
+	}
+	if listening.Hours[23] != 2 || listening.Hours[12] != 6 || listening.Hours[3] != 0 {
+		t.Errorf("expected hours in New York time, got %v", listening.Hours)
+	}
+	// Sundays: January 4 and both March 8 plays.
+	if listening.Weekdays[6] != 3 {
+		t.Errorf("expected 3 Sunday plays, got %v", listening.Weekdays)
+	}
+
+	heatmap := listening.Heatmap
+	if len(heatmap.Weeks) != heatmapWeeks {
+		t.Fatalf("expected %d weeks, got %d", heatmapWeeks, len(heatmap.Weeks))
+	}
+	if first := heatmap.Weeks[0][0].Date; first.Weekday() != time.Monday {
+		t.Errorf("expected weeks from Monday, got %v", first)
+	}
+	last := heatmap.Weeks[len(heatmap.Weeks)-1]
+	if len(last) != 2 || last[1].Date != dayKey(local(time.March, 10, 0, 0)) {
+		t.Errorf("expected the last week to stop at today, got %+v", last)
+	}
+	if last[0].Plays != 1 || heatmap.MaxPlays != 2 || heatmap.Level(last[0]) != 2 || heatmap.Level(HeatmapDay{}) != 0 {
+		t.Errorf("expected March 9's play at half the busiest day, got %+v with max %d", last[0], heatmap.MaxPlays)
+	}
+	if len(heatmap.Months) != 12 || heatmap.Months[len(heatmap.Months)-1].Start.Month() != time.March {
+		t.Errorf("expected a label for each month starting in the year, got %+v", heatmap.Months)
+	}
+}
+
+func TestComputeListening_CurrentStreakEndsAfterADayOff(t *testing.T) {
+	now := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	listening := computeListening([]time.Time{now.AddDate(0, 0, -2), now.AddDate(0, 0, -1)}, now)
+	if listening.CurrentStreak.Days != 2 {
+		t.Errorf("expected a streak through yesterday to be current, got %+v", listening.CurrentStreak)
+	}
+
+	listening = computeListening([]time.Time{now.AddDate(0, 0, -3), now.AddDate(0, 0, -2)}, now)
+	if listening.CurrentStreak.Days != 0 || listening.LongestStreak.Days != 2 {
+		t.Errorf("expected only a past streak, got %+v and %+v", listening.CurrentStreak, listening.LongestStreak)
+	}
+}
@@ -0,0 +1,230 @@
+package stats
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/alecdray/wax/src/internal/core/db"
+	"github.com/alecdray/wax/src/internal/core/db/sqlc"
+	"github.com/alecdray/wax/src/internal/user"
+	"time"
+)
+
+const (
+	statsCacheTTL  = 10 * time.Minute
+	statsCacheSize = 200
+)
+
+type Service struct {
+	db             *db.DB
+	userService    *user.Service
+	cache          *statsCache[Stats]
+	listeningCache *statsCache[Listening]
+	now            func() time.Time
+}
+
+func NewService(db *db.DB, userService *user.Service) *Service {
+	return &Service{
+		db:             db,
+		userService:    userService,
+		cache:          newStatsCache[Stats](statsCacheTTL, statsCacheSize),
+		listeningCache: newStatsCache[Listening](statsCacheTTL, statsCacheSize),
+		now:            time.Now,
+	}
+}
+
+// GetStats returns the user's library stats over the range, with its days in
+// the user's timezone, from the cache while the library hasn't changed since
+// they were computed.
+func (s *Service) GetStats(ctx context.Context, userId string, r Range) (Stats, error) {
+	location, err := s.getLocation(ctx, userId)
+	if err != nil {
+		return Stats{}, err
+	}
+	r = r.In(location)
+
+	version, err := s.getVersion(ctx, userId)
+	if err != nil {
+		return Stats{}, err
+	}
+	key := statsCacheKey(userId, location, r)
+	if stats, ok := s.cache.get(key, version); ok {
+		return stats, nil
+	}
+
+	data, err := s.loadLibraryData(ctx, userId, r)
+	if err != nil {
+		return Stats{}, err
+	}
+	stats := computeStats(data, r, s.now().In(location))
+	s.cache.put(key, version, stats)
+
+	return stats, nil
+}
+
+// GetListening returns when the user listens, in their timezone, narrowed by
+// the filter.
+func (s *Service) GetListening(ctx context.Context, userId string, filter ListeningFilter) (Listening, error) {
+	location, err := s.getLocation(ctx, userId)
+	if err != nil {
+		return Listening{}, err
+	}
+
+	version, err := s.getVersion(ctx, userId)
+	if err != nil {
+		return Listening{}, err
+	}
+	key := listeningCacheKey(userId, location, filter)
+	if listening, ok := s.listeningCache.get(key, version); ok {
+		return listening, nil
+	}
+
+	filterName, playTimes, err := s.loadPlayTimes(ctx, userId, filter)
+	if err != nil {
+		return Listening{}, err
+	}
+	listening := computeListening(playTimes, s.now().In(location))
+	listening.Filter = filter
+	listening.FilterName = filterName
+	s.listeningCache.put(key, version, listening)
+
+	return listening, nil
+}
+
+func (s *Service) getLocation(ctx context.Context, userId string) (*time.Location, error) {
+	user, err := s.userService.GetUserById(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user.Location(), nil
+}
+
+// getVersion fingerprints what the stats are computed from, cheaply enough
+// to check on every view.
+func (s *Service) getVersion(ctx context.Context, userId string) (string, error) {
+	row, err := s.db.Queries().GetStatsVersion(ctx, sqlc.GetStatsVersionParams{
+		UserID:   userId,
+		UserID_2: userId,
+		UserID_3: userId,
+		UserID_4: userId,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get stats version: %w", err)
+	}
+	return fmt.Sprintf("%s|%s|%s|%s", row.Releases, row.Ratings, row.Tags, row.Plays), nil
+}
+
+func (s *Service) loadLibraryData(ctx context.Context, userId string, r Range) (libraryData, error) {
+	data := newLibraryData()
+
+	releases, err := s.db.Queries().GetStatsReleases(ctx, userId)
+	if err != nil {
+		return data, fmt.Errorf("failed to get library releases: %w", err)
+	}
+	for _, release := range releases {
+		data.addRelease(release.AlbumID, release.Format, release.AddedAt)
+	}
+
+	ratings, err := s.db.Queries().GetLatestUserAlbumRatings(ctx, sqlc.GetLatestUserAlbumRatingsParams{
+		UserID:   userId,
+		UserID_2: userId,
+	})
+	if err != nil {
+		return data, fmt.Errorf("failed to get album ratings: %w", err)
+	}
+	for _, rating := range ratings {
+		data.ratings[rating.AlbumID] = rating.Rating
+	}
+
+	albumTags, err := s.db.Queries().GetStatsAlbumTags(ctx, userId)
+	if err != nil {
+		return data, fmt.Errorf("failed to get album tags: %w", err)
+	}
+	for _, albumTag := range albumTags {
+		data.tags[albumTag.AlbumID] = append(data.tags[albumTag.AlbumID], tagRef{id: albumTag.TagID, name: albumTag.TagName})
+	}
+
+	albumArtists, err := s.db.Queries().GetStatsAlbumArtists(ctx, userId)
+	if err != nil {
+		return data, fmt.Errorf("failed to get album artists: %w", err)
+	}
+	for _, albumArtist := range albumArtists {
+		data.artists[albumArtist.AlbumID] = append(data.artists[albumArtist.AlbumID], artistRef{id: albumArtist.ArtistID, name: albumArtist.ArtistName})
+	}
+
+	from, to := r.bounds()
+	plays, err := s.db.Queries().GetStatsArtistPlays(ctx, sqlc.GetStatsArtistPlaysParams{
+		UserID:     userId,
+		PlayedFrom: from,
+		PlayedTo:   to,
+		MaxArtists: topArtistsLimit,
+	})
+	if err != nil {
+		return data, fmt.Errorf("failed to get artist plays: %w", err)
+	}
+	for _, play := range plays {
+		data.plays = append(data.plays, ArtistPlays{
+			ArtistID: play.ArtistID,
+			Name:     play.ArtistName,
+			Plays:    int(play.Plays),
+		})
+	}
+
+	return data, nil
+}
+
+// loadPlayTimes returns when the user played what the filter narrows to,
+// along with the name of the filtered album or tag.
+func (s *Service) loadPlayTimes(ctx context.Context, userId string, filter ListeningFilter) (string, []time.Time, error) {
+	switch {
+	case filter.AlbumID != "":
+		album, err := s.db.Queries().GetAlbum(ctx, filter.AlbumID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil, ErrAlbumNotFound
+		} else if err != nil {
+			return "", nil, fmt.Errorf("failed to get album: %w", err)
+		}
+		playTimes, err := s.db.Queries().GetStatsAlbumPlayTimes(ctx, sqlc.GetStatsAlbumPlayTimesParams{
+			UserID:  userId,
+			AlbumID: album.ID,
+		})
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to get album plays: %w", err)
+		}
+		return album.Title, playTimes, nil
+	case filter.TagID != "":
+		tag, err := s.db.Queries().GetTag(ctx, sqlc.GetTagParams{
+			ID:     filter.TagID,
+			UserID: userId,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil, ErrTagNotFound
+		} else if err != nil {
+			return "", nil, fmt.Errorf("failed to get tag: %w", err)
+		}
+		// A tag takes in the plays of albums with any of its subtags too.
+		tagIds, err := s.db.Queries().GetSubtagIds(ctx, sqlc.GetSubtagIdsParams{
+			ID:     tag.ID,
+			UserID: userId,
+		})
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to get subtags: %w", err)
+		}
+		playTimes, err := s.db.Queries().GetStatsTagPlayTimes(ctx, sqlc.GetStatsTagPlayTimesParams{
+			UserID:   userId,
+			UserID_2: userId,
+			TagIds:   tagIds,
+		})
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to get tag plays: %w", err)
+		}
+		return tag.Name, playTimes, nil
+	default:
+		playTimes, err := s.db.Queries().GetStatsPlayTimes(ctx, userId)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to get plays: %w", err)
+		}
+		return "", playTimes, nil
+	}
+}
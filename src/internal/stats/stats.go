@@ -0,0 +1,535 @@
+package stats
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"github.com/alecdray/wax/src/internal/core/timex"
+	"github.com/alecdray/wax/src/internal/core/utils"
+	"sort"
+	"time"
+)
+
+const (
+	// Ratings are stored on the canonical 0–10 scale; the rating profile
+	// converts them for display.
+	ratingMin = 0.0
+	ratingMax = 10.0
+	// ratingBucketCount is how many equal slices of the scale the rating
+	// distribution is split into.
+	ratingBucketCount = 10
+	// topArtistsLimit is how many artists each top artists list holds.
+	topArtistsLimit = 10
+	// minArtistRatedAlbums is how many rated albums an artist needs to rank
+	// by average rating, so one lucky album doesn't top the list.
+	minArtistRatedAlbums = 2
+	// dateLayout is how range days are read from and written to requests.
+	dateLayout = "2006-01-02"
+)
+
+var ErrInvalidRange = errors.New("invalid date range")
+
+// Range narrows stats to the albums added within it and the plays within
+// it. ParseRange reads its days as dates, and In places them in the user's
+// timezone.
+type Range struct {
+	// From is the start of the first day, zero for since the library began.
+	From time.Time
+	// To is the start of the day after the last, zero for up to now.
+	To time.Time
+}
+
+// ParseRange reads a range from the first and last days, as YYYY-MM-DD,
+// either of which may be empty to leave that end open.
+func ParseRange(from, to string) (Range, error) {
+	var r Range
+	if from != "" {
+		day, err := time.Parse(dateLayout, from)
+		if err != nil {
+			return Range{}, fmt.Errorf("%w: %q isn't a date", ErrInvalidRange, from)
+		}
+		r.From = day
+	}
+	if to != "" {
+		day, err := time.Parse(dateLayout, to)
+		if err != nil {
+			return Range{}, fmt.Errorf("%w: %q isn't a date", ErrInvalidRange, to)
+		}
+		r.To = day.AddDate(0, 0, 1)
+	}
+	if !r.From.IsZero() && !r.To.IsZero() && !r.From.Before(r.To) {
+		return Range{}, fmt.Errorf("%w: the start must be on or before the end", ErrInvalidRange)
+	}
+	return r, nil
+}
+
+// FromValue is the first day as YYYY-MM-DD, empty when open.
+func (r Range) FromValue() string {
+	if r.From.IsZero() {
+		return ""
+	}
+	return r.From.Format(dateLayout)
+}
+
+// ToValue is the last day as YYYY-MM-DD, empty when open.
+func (r Range) ToValue() string {
+	if r.To.IsZero() {
+		return ""
+	}
+	return r.To.AddDate(0, 0, -1).Format(dateLayout)
+}
+
+func (r Range) IsAllTime() bool {
+	return r.From.IsZero() && r.To.IsZero()
+}
+
+func (r Range) Contains(t time.Time) bool {
+	return (r.From.IsZero() || !t.Before(r.From)) && (r.To.IsZero() || t.Before(r.To))
+}
+
+// In places the range's days in a timezone.
+func (r Range) In(location *time.Location) Range {
+	if !r.From.IsZero() {
+		r.From = time.Date(r.From.Year(), r.From.Month(), r.From.Day(), 0, 0, 0, 0, location)
+	}
+	if !r.To.IsZero() {
+		r.To = time.Date(r.To.Year(), r.To.Month(), r.To.Day(), 0, 0, 0, 0, location)
+	}
+	return r
+}
+
+func (r Range) key() string {
+	return r.FromValue() + ".." + r.ToValue()
+}
+
+// bounds closes the open ends of the range for querying.
+func (r Range) bounds() (time.Time, time.Time) {
+	to := r.To.UTC()
+	if r.To.IsZero() {
+		to = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+	}
+	return r.From.UTC(), to
+}
+
+func (r Range) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		From string `json:"from,omitempty"`
+		To   string `json:"to,omitempty"`
+	}{r.FromValue(), r.ToValue()})
+}
+
+// RangePreset is a commonly picked range, offered as a shortcut.
+type RangePreset struct {
+	Label string
+	Range Range
+}
+
+// RangePresets are the shortcut ranges as of now, in now's timezone.
+func RangePresets(now time.Time) []RangePreset {
+	today := startOfDay(now)
+	tomorrow := today.AddDate(0, 0, 1)
+	return []RangePreset{
+		{Label: "All time"},
+		{Label: "Last 30 days", Range: Range{From: today.AddDate(0, 0, -29), To: tomorrow}},
+		{Label: "Last 12 months", Range: Range{From: today.AddDate(-1, 0, 1), To: tomorrow}},
+		{Label: "This year", Range: Range{From: time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, today.Location()), To: tomorrow}},
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// Granularity is how long each point of the growth series spans.
+type Granularity string
+
+const (
+	GranularityDay   Granularity = "day"
+	GranularityWeek  Granularity = "week"
+	GranularityMonth Granularity = "month"
+)
+
+// granularityFor picks points fine enough to show a short range's shape
+// without drawing years of days.
+func granularityFor(span time.Duration) Granularity {
+	switch {
+	case span <= 31*timex.Day:
+		return GranularityDay
+	case span <= 26*timex.Week:
+		return GranularityWeek
+	default:
+		return GranularityMonth
+	}
+}
+
+// start is the start of the point t falls in, in t's timezone.
+func (g Granularity) start(t time.Time) time.Time {
+	switch g {
+	case GranularityDay:
+		return startOfDay(t)
+	case GranularityWeek:
+		return startOfWeek(t)
+	default:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// startOfWeek is the start of the Monday of t's week.
+func startOfWeek(t time.Time) time.Time {
+	return startOfDay(t).AddDate(0, 0, -weekdayIndex(t))
+}
+
+// weekdayIndex numbers the days of the week from Monday.
+func weekdayIndex(t time.Time) int {
+	return (int(t.Weekday()) + 6) % 7
+}
+
+func (g Granularity) next(t time.Time) time.Time {
+	switch g {
+	case GranularityDay:
+		return t.AddDate(0, 0, 1)
+	case GranularityWeek:
+		return t.AddDate(0, 0, 7)
+	default:
+		return t.AddDate(0, 1, 0)
+	}
+}
+
+type GrowthPoint struct {
+	Start time.Time `json:"start"`
+	// Added is how many albums were added during the point.
+	Added int `json:"added"`
+	// Total is the size of the library at the end of the point.
+	Total int `json:"total"`
+}
+
+type Growth struct {
+	Granularity Granularity   `json:"granularity"`
+	Points      []GrowthPoint `json:"points"`
+}
+
+func (g Growth) MaxAdded() int {
+	highest := 0
+	for _, point := range g.Points {
+		highest = max(highest, point.Added)
+	}
+	return highest
+}
+
+type RatingBucket struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Count int     `json:"count"`
+}
+
+type TagRating struct {
+	TagID string `json:"tagId"`
+	Name  string `json:"name"`
+	// Albums is how many rated albums have the tag.
+	Albums  int     `json:"albums"`
+	Average float64 `json:"average"`
+}
+
+type ArtistPlays struct {
+	ArtistID string `json:"artistId"`
+	Name     string `json:"name"`
+	Plays    int    `json:"plays"`
+}
+
+type ArtistRating struct {
+	ArtistID string `json:"artistId"`
+	Name     string `json:"name"`
+	// Albums is how many of the artist's albums are rated.
+	Albums  int     `json:"albums"`
+	Average float64 `json:"average"`
+}
+
+type FormatCount struct {
+	Format models.ReleaseFormat `json:"format"`
+	Albums int                  `json:"albums"`
+}
+
+func FormatLabel(format models.ReleaseFormat) string {
+	switch format {
+	case models.ReleaseFormatDigital:
+		return "Digital"
+	case models.ReleaseFormatVinyl:
+		return "Vinyl"
+	case models.ReleaseFormatCD:
+		return "CD"
+	case models.ReleaseFormatCassette:
+		return "Cassette"
+	default:
+		return string(format)
+	}
+}
+
+// Stats are the user's library analytics over a range. Ratings are on the
+// canonical scale, and ComputedAt is in the user's timezone.
+type Stats struct {
+	Range Range `json:"range"`
+	// Albums and Rated count the albums added in the range, and how many of
+	// them are rated.
+	Albums int `json:"albums"`
+	Rated  int `json:"rated"`
+	// AverageRating is nil when none of the albums are rated.
+	AverageRating      *float64       `json:"averageRating"`
+	Growth             Growth         `json:"growth"`
+	RatingDistribution []RatingBucket `json:"ratingDistribution"`
+	RatingsByTag       []TagRating    `json:"ratingsByTag"`
+	TopArtistsByPlays  []ArtistPlays  `json:"topArtistsByPlays"`
+	TopArtistsByRating []ArtistRating `json:"topArtistsByRating"`
+	Formats            []FormatCount  `json:"formats"`
+	ComputedAt         time.Time      `json:"computedAt"`
+}
+
+// RatedShare is the fraction of the albums that are rated.
+func (s Stats) RatedShare() float64 {
+	if s.Albums == 0 {
+		return 0
+	}
+	return float64(s.Rated) / float64(s.Albums)
+}
+
+func (s Stats) MaxBucketCount() int {
+	highest := 0
+	for _, bucket := range s.RatingDistribution {
+		highest = max(highest, bucket.Count)
+	}
+	return highest
+}
+
+// OnScale returns the stats with every rating converted by toScale, for
+// answering on the user's rating scale.
+func (s Stats) OnScale(toScale func(float64) float64) Stats {
+	if s.AverageRating != nil {
+		average := toScale(*s.AverageRating)
+		s.AverageRating = &average
+	}
+	buckets := make([]RatingBucket, len(s.RatingDistribution))
+	for i, bucket := range s.RatingDistribution {
+		buckets[i] = RatingBucket{Min: toScale(bucket.Min), Max: toScale(bucket.Max), Count: bucket.Count}
+	}
+	s.RatingDistribution = buckets
+	tags := make([]TagRating, len(s.RatingsByTag))
+	for i, tag := range s.RatingsByTag {
+		tag.Average = toScale(tag.Average)
+		tags[i] = tag
+	}
+	s.RatingsByTag = tags
+	artists := make([]ArtistRating, len(s.TopArtistsByRating))
+	for i, artist := range s.TopArtistsByRating {
+		artist.Average = toScale(artist.Average)
+		artists[i] = artist
+	}
+	s.TopArtistsByRating = artists
+	return s
+}
+
+type tagRef struct {
+	id   string
+	name string
+}
+
+type artistRef struct {
+	id   string
+	name string
+}
+
+// libraryData is what the stats are computed from: the user's library
+// albums by ID, and the plays already narrowed to the range.
+type libraryData struct {
+	added   map[string]time.Time
+	formats map[string][]models.ReleaseFormat
+	ratings map[string]float64
+	tags    map[string][]tagRef
+	artists map[string][]artistRef
+	plays   []ArtistPlays
+}
+
+func newLibraryData() libraryData {
+	return libraryData{
+		added:   make(map[string]time.Time),
+		formats: make(map[string][]models.ReleaseFormat),
+		ratings: make(map[string]float64),
+		tags:    make(map[string][]tagRef),
+		artists: make(map[string][]artistRef),
+	}
+}
+
+// addRelease records a release in the library. An album counts as added
+// when its first release was.
+func (d libraryData) addRelease(albumId string, format models.ReleaseFormat, addedAt time.Time) {
+	if added, ok := d.added[albumId]; !ok || addedAt.Before(added) {
+		d.added[albumId] = addedAt
+	}
+	d.formats[albumId] = append(d.formats[albumId], format)
+}
+
+func computeStats(data libraryData, r Range, now time.Time) Stats {
+	stats := Stats{
+		Range:              r,
+		Growth:             computeGrowth(data.added, r, now),
+		RatingsByTag:       []TagRating{},
+		TopArtistsByPlays:  []ArtistPlays{},
+		TopArtistsByRating: []ArtistRating{},
+		Formats:            []FormatCount{},
+		ComputedAt:         now,
+	}
+	stats.TopArtistsByPlays = append(stats.TopArtistsByPlays, data.plays...)
+
+	var albumIds []string
+	for albumId, added := range data.added {
+		if r.Contains(added) {
+			albumIds = append(albumIds, albumId)
+		}
+	}
+	sort.Strings(albumIds)
+	stats.Albums = len(albumIds)
+
+	stats.RatingDistribution = make([]RatingBucket, ratingBucketCount)
+	width := (ratingMax - ratingMin) / ratingBucketCount
+	for i := range stats.RatingDistribution {
+		stats.RatingDistribution[i].Min = ratingMin + float64(i)*width
+		stats.RatingDistribution[i].Max = stats.RatingDistribution[i].Min + width
+	}
+
+	sum := 0.0
+	formats := make(map[models.ReleaseFormat]int)
+	tagSums := make(map[tagRef]float64)
+	tagCounts := make(map[tagRef]int)
+	artistSums := make(map[artistRef]float64)
+	artistCounts := make(map[artistRef]int)
+	for _, albumId := range albumIds {
+		seen := make(map[models.ReleaseFormat]bool)
+		for _, format := range data.formats[albumId] {
+			if !seen[format] {
+				seen[format] = true
+				formats[format]++
+			}
+		}
+
+		rating, ok := data.ratings[albumId]
+		if !ok {
+			continue
+		}
+		stats.Rated++
+		sum += rating
+		index := int((rating - ratingMin) / width)
+		stats.RatingDistribution[utils.Clamp(index, 0, ratingBucketCount-1)].Count++
+		for _, tag := range data.tags[albumId] {
+			tagSums[tag] += rating
+			tagCounts[tag]++
+		}
+		for _, artist := range data.artists[albumId] {
+			artistSums[artist] += rating
+			artistCounts[artist]++
+		}
+	}
+	if stats.Rated > 0 {
+		average := sum / float64(stats.Rated)
+		stats.AverageRating = &average
+	}
+
+	for _, format := range []models.ReleaseFormat{
+		models.ReleaseFormatDigital,
+		models.ReleaseFormatVinyl,
+		models.ReleaseFormatCD,
+		models.ReleaseFormatCassette,
+	} {
+		if formats[format] > 0 {
+			stats.Formats = append(stats.Formats, FormatCount{Format: format, Albums: formats[format]})
+		}
+	}
+
+	for tag, count := range tagCounts {
+		stats.RatingsByTag = append(stats.RatingsByTag, TagRating{
+			TagID:   tag.id,
+			Name:    tag.name,
+			Albums:  count,
+			Average: tagSums[tag] / float64(count),
+		})
+	}
+	sort.Slice(stats.RatingsByTag, func(i, j int) bool {
+		a, b := stats.RatingsByTag[i], stats.RatingsByTag[j]
+		if a.Average != b.Average {
+			return a.Average > b.Average
+		}
+		if a.Albums != b.Albums {
+			return a.Albums > b.Albums
+		}
+		return a.Name < b.Name
+	})
+
+	for artist, count := range artistCounts {
+		if count < minArtistRatedAlbums {
+			continue
+		}
+		stats.TopArtistsByRating = append(stats.TopArtistsByRating, ArtistRating{
+			ArtistID: artist.id,
+			Name:     artist.name,
+			Albums:   count,
+			Average:  artistSums[artist] / float64(count),
+		})
+	}
+	sort.Slice(stats.TopArtistsByRating, func(i, j int) bool {
+		a, b := stats.TopArtistsByRating[i], stats.TopArtistsByRating[j]
+		if a.Average != b.Average {
+			return a.Average > b.Average
+		}
+		if a.Albums != b.Albums {
+			return a.Albums > b.Albums
+		}
+		return a.Name < b.Name
+	})
+	if len(stats.TopArtistsByRating) > topArtistsLimit {
+		stats.TopArtistsByRating = stats.TopArtistsByRating[:topArtistsLimit]
+	}
+
+	return stats
+}
+
+// computeGrowth counts the albums added in each point of the range, and the
+// library's size as of each, in now's timezone. An open start begins with
+// the first album.
+func computeGrowth(added map[string]time.Time, r Range, now time.Time) Growth {
+	start, end := r.From, r.To
+	if end.IsZero() || end.After(now) {
+		end = now
+	}
+	if start.IsZero() {
+		for _, t := range added {
+			if start.IsZero() || t.Before(start) {
+				start = t
+			}
+		}
+		start = start.In(now.Location())
+	}
+	if start.IsZero() || !start.Before(end) {
+		return Growth{Granularity: GranularityMonth, Points: []GrowthPoint{}}
+	}
+
+	growth := Growth{Granularity: granularityFor(end.Sub(start))}
+	index := make(map[int64]int)
+	for t := growth.Granularity.start(start); t.Before(end); t = growth.Granularity.next(t) {
+		index[t.Unix()] = len(growth.Points)
+		growth.Points = append(growth.Points, GrowthPoint{Start: t})
+	}
+
+	before := 0
+	for _, t := range added {
+		switch {
+		case t.Before(start):
+			before++
+		case t.Before(end):
+			growth.Points[index[growth.Granularity.start(t.In(now.Location())).Unix()]].Added++
+		}
+	}
+	total := before
+	for i := range growth.Points {
+		total += growth.Points[i].Added
+		growth.Points[i].Total = total
+	}
+	return growth
+}
@@ -0,0 +1,171 @@
+package stats
+
+import (
+	"errors"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"testing"
+	"time"
+)
+
+func day(month time.Month, d int) time.Time {
+	return time.Date(2026, month, d, 12, 0, 0, 0, time.UTC)
+}
+
+func TestParseRange(t *testing.T) {
+	r, err := ParseRange("2026-01-01", "2026-01-31")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !r.Contains(day(time.January, 31)) || r.Contains(day(time.February, 1)) {
+		t.Errorf("expected the last day to be included and the next excluded, got %+v", r)
+	}
+	if r.FromValue() != "2026-01-01" || r.ToValue() != "2026-01-31" {
+		t.Errorf("expected the days back, got %q to %q", r.FromValue(), r.ToValue())
+	}
+
+	location, err := time.LoadLocation("Pacific/Auckland")
+	if err != nil {
+		t.Fatalf("failed to load location: %v", err)
+	}
+	local := r.In(location)
+	if local.FromValue() != "2026-01-01" || !local.Contains(time.Date(2025, time.December, 31, 11, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the days to start at midnight in Auckland, got %+v", local)
+	}
+
+	if r, err := ParseRange("", ""); err != nil || !r.IsAllTime() {
+		t.Errorf("expected an open range, got %+v, %v", r, err)
+	}
+	if _, err := ParseRange("2026-02-01", "2026-01-01"); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("expected a backwards range to be invalid, got %v", err)
+	}
+	if _, err := ParseRange("last week", ""); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("expected a non-date to be invalid, got %v", err)
+	}
+}
+
+func TestComputeStats(t *testing.T) {
+	data := newLibraryData()
+	data.addRelease("a1", models.ReleaseFormatDigital, day(time.January, 5))
+	data.addRelease("a1", models.ReleaseFormatVinyl, day(time.March, 1))
+	data.addRelease("a2", models.ReleaseFormatDigital, day(time.January, 10))
+	data.addRelease("a3", models.ReleaseFormatCD, day(time.January, 20))
+	data.addRelease("old", models.ReleaseFormatDigital, day(time.January, 1).AddDate(-1, 0, 0))
+	data.ratings["a1"] = 9
+	data.ratings["a2"] = 7
+	data.ratings["old"] = 2
+	data.tags["a1"] = []tagRef{{id: "t1", name: "jazz"}, {id: "t2", name: "late night"}}
+	data.tags["a2"] = []tagRef{{id: "t1", name: "jazz"}}
+	data.artists["a1"] = []artistRef{{id: "ar1", name: "Coltrane"}}
+	data.artists["a2"] = []artistRef{{id: "ar1", name: "Coltrane"}}
+	data.artists["a3"] = []artistRef{{id: "ar2", name: "Davis"}}
+
+	r, err := ParseRange("2026-01-01", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stats := computeStats(data, r, day(time.March, 15))
+
+	if stats.Albums != 3 || stats.Rated != 2 {
+		t.Errorf("expected 2 of the 3 albums added this year rated, got %d of %d", stats.Rated, stats.Albums)
+	}
+	if stats.AverageRating == nil || *stats.AverageRating != 8 {
+		t.Errorf("expected an average of 8, got %v", stats.AverageRating)
+	}
+	if stats.RatingDistribution[7].Count != 1 || stats.RatingDistribution[9].Count != 1 || stats.RatingDistribution[2].Count != 0 {
+		t.Errorf("expected the ratings in range bucketed, got %+v", stats.RatingDistribution)
+	}
+
+	if len(stats.RatingsByTag) != 2 || stats.RatingsByTag[0].Name != "late night" || stats.RatingsByTag[1].Albums != 2 || stats.RatingsByTag[1].Average != 8 {
+		t.Errorf("expected tags by average, got %+v", stats.RatingsByTag)
+	}
+	if len(stats.TopArtistsByRating) != 1 || stats.TopArtistsByRating[0].ArtistID != "ar1" {
+		t.Errorf("expected only the artist with two rated albums, got %+v", stats.TopArtistsByRating)
+	}
+
+	want := []FormatCount{
+		{Format: models.ReleaseFormatDigital, Albums: 2},
+		{Format: models.ReleaseFormatVinyl, Albums: 1},
+		{Format: models.ReleaseFormatCD, Albums: 1},
+	}
+	if len(stats.Formats) != len(want) {
+		t.Fatalf("expected %v, got %v", want, stats.Formats)
+	}
+	for i := range want {
+		if stats.Formats[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, stats.Formats)
+		}
+	}
+}
+
+func TestComputeGrowth(t *testing.T) {
+	added := map[string]time.Time{
+		"old": day(time.January, 1).AddDate(-1, 0, 0),
+		"a1":  day(time.January, 5),
+		"a2":  day(time.January, 5),
+		"a3":  day(time.January, 20),
+	}
+
+	r, _ := ParseRange("2026-01-01", "2026-01-31")
+	growth := computeGrowth(added, r, day(time.March, 1))
+	if growth.Granularity != GranularityDay || len(growth.Points) != 31 {
+		t.Fatalf("expected a point per day of January, got %s with %d", growth.Granularity, len(growth.Points))
+	}
+	if growth.Points[4].Added != 2 || growth.Points[4].Total != 3 || growth.Points[30].Total != 4 {
+		t.Errorf("expected the library to grow from the album before the range, got %+v", growth.Points)
+	}
+
+	growth = computeGrowth(added, Range{}, day(time.March, 1))
+	if growth.Granularity != GranularityMonth || growth.Points[0].Start != time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("expected months from the first album, got %s from %v", growth.Granularity, growth.Points[0].Start)
+	}
+	if last := growth.Points[len(growth.Points)-1]; last.Total != 4 {
+		t.Errorf("expected the whole library by the end, got %+v", last)
+	}
+
+	r, _ = ParseRange("2026-01-01", "2026-03-31")
+	growth = computeGrowth(added, r, day(time.March, 1))
+	if growth.Granularity != GranularityWeek || growth.Points[0].Start.Weekday() != time.Monday {
+		t.Errorf("expected weeks starting on Monday, got %s from %v", growth.Granularity, growth.Points[0].Start)
+	}
+
+	if growth := computeGrowth(nil, Range{}, day(time.March, 1)); len(growth.Points) != 0 {
+		t.Errorf("expected no points for an empty library, got %+v", growth.Points)
+	}
+}
+
+func TestStatsCache_ServesOnlyTheSameVersion(t *testing.T) {
+	now := time.Now()
+	cache := newStatsCache[Stats](time.Minute, 10)
+	cache.entries.Now = func() time.Time { return now }
+
+	cache.put("u1|..", "v1", Stats{Albums: 3})
+	if stats, ok := cache.get("u1|..", "v1"); !ok || stats.Albums != 3 {
+		t.Fatalf("expected cached stats, got %v, %v", stats, ok)
+	}
+	if _, ok := cache.get("u1|..", "v2"); ok {
+		t.Error("expected a changed library to miss")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := cache.get("u1|..", "v1"); ok {
+		t.Error("expected the entry to expire")
+	}
+}
+
+func TestStats_OnScale(t *testing.T) {
+	average := 8.0
+	stats := Stats{
+		AverageRating:      &average,
+		RatingDistribution: []RatingBucket{{Min: 0, Max: 1, Count: 2}},
+		RatingsByTag:       []TagRating{{Name: "jazz", Average: 6}},
+		TopArtistsByRating: []ArtistRating{{Name: "Coltrane", Average: 9}},
+	}
+	scaled := stats.OnScale(func(rating float64) float64 { return rating / 2 })
+
+	if *scaled.AverageRating != 4 || scaled.RatingDistribution[0].Max != 0.5 || scaled.RatingsByTag[0].Average != 3 || scaled.TopArtistsByRating[0].Average != 4.5 {
+		t.Errorf("expected every rating converted, got %+v", scaled)
+	}
+	if *stats.AverageRating != 8 || stats.RatingsByTag[0].Average != 6 {
+		t.Error("expected the original stats untouched")
+	}
+}
@@ -3,27 +3,54 @@ package adapters
 import (
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"github.com/alecdray/wax/src/internal/core/contextx"
+	"github.com/alecdray/wax/src/internal/core/db/models"
 	"github.com/alecdray/wax/src/internal/core/httpx"
+	"github.com/alecdray/wax/src/internal/core/templates"
 	"github.com/alecdray/wax/src/internal/library"
 	libraryAdapters "github.com/alecdray/wax/src/internal/library/adapters"
+	"github.com/alecdray/wax/src/internal/musicbrainz"
 	"github.com/alecdray/wax/src/internal/tags"
+	"strconv"
 	"strings"
 )
 
 type HttpHandler struct {
 	libraryService *library.Service
 	tagsService    *tags.Service
+	mb             *musicbrainz.Service
 }
 
-func NewHttpHandler(libraryService *library.Service, tagsService *tags.Service) *HttpHandler {
+func NewHttpHandler(libraryService *library.Service, tagsService *tags.Service, mb *musicbrainz.Service) *HttpHandler {
 	return &HttpHandler{
 		libraryService: libraryService,
 		tagsService:    tagsService,
+		mb:             mb,
 	}
 }
 
+func handleTagsError(ctx contextx.ContextX, w http.ResponseWriter, err error) {
+	props := httpx.HandleErrorResponseProps{
+		Status: http.StatusInternalServerError,
+		Err:    err,
+	}
+	switch {
+	case errors.Is(err, tags.ErrTagNotFound), errors.Is(err, tags.ErrTagGroupNotFound):
+		props.Status = http.StatusNotFound
+	case errors.Is(err, tags.ErrInvalidTag), errors.Is(err, tags.ErrInvalidTagGroup), errors.Is(err, tags.ErrTagCycle):
+		props.Status = http.StatusUnprocessableEntity
+		props.Response = *httpx.NewErrorResponse().SetComponent(TagError(err.Error()))
+	case errors.Is(err, tags.ErrTagNameTaken), errors.Is(err, tags.ErrTagGroupNameTaken), errors.Is(err, tags.ErrExclusiveGroupInUse),
+		errors.Is(err, tags.ErrTagOnSmartShelf):
+		props.Status = http.StatusConflict
+		props.Response = *httpx.NewErrorResponse().SetComponent(TagError(err.Error()))
+	}
+	httpx.HandleErrorResponse(ctx, w, props)
+}
+
 func (h *HttpHandler) GetTagsModal(w http.ResponseWriter, r *http.Request) {
 	ctx := contextx.NewContextX(r.Context())
 
@@ -55,7 +82,7 @@ func (h *HttpHandler) GetTagsModal(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Ensure default groups exist on first use
-	tagGroups, err := h.tagsService.GetOrCreateDefaultGroups(ctx, userId)
+	tagGroups, err := h.tagsService.GetOrCreateTagGroups(ctx, userId)
 	if err != nil {
 		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
 			Status: http.StatusInternalServerError,
@@ -82,6 +109,71 @@ func (h *HttpHandler) GetTagsModal(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// GetTagSuggestions suggests tags for an album from the user's own tagging
+// and the album's MusicBrainz genres. When MusicBrainz can't be reached the
+// suggestions come from the library alone.
+func (h *HttpHandler) GetTagSuggestions(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	album, err := h.libraryService.GetAlbumInLibrary(ctx, userId, r.URL.Query().Get("albumId"))
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get album: %w", err),
+		})
+		return
+	}
+
+	external, err := h.musicBrainzGenres(ctx, *album)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to get musicbrainz genres for tag suggestions", "albumId", album.ID, "error", err)
+	}
+
+	suggestions, err := h.tagsService.SuggestTags(ctx, userId, album.ID, external)
+	if err != nil {
+		handleTagsError(ctx, w, err)
+		return
+	}
+
+	err = TagSuggestions(suggestions).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+	}
+}
+
+// musicBrainzGenres looks the album up on MusicBrainz and returns its genres
+// and well-voted tags, or none when it isn't found.
+func (h *HttpHandler) musicBrainzGenres(ctx contextx.ContextX, album library.AlbumDTO) ([]tags.ExternalTag, error) {
+	if len(album.Artists) == 0 {
+		return nil, nil
+	}
+	group, err := h.mb.FindReleaseGroup(ctx, album.Title, album.Artists[0].Name)
+	if err != nil || group == nil {
+		return nil, err
+	}
+	genres, err := h.mb.GetReleaseGroupGenres(ctx, group.ID)
+	if err != nil {
+		return nil, err
+	}
+	external := make([]tags.ExternalTag, 0, len(genres))
+	for _, genre := range genres {
+		external = append(external, tags.ExternalTag{Name: genre.Name, Count: genre.Count})
+	}
+	return external, nil
+}
+
 func (h *HttpHandler) SubmitAlbumTags(w http.ResponseWriter, r *http.Request) {
 	ctx := contextx.NewContextX(r.Context())
 
@@ -128,10 +220,7 @@ func (h *HttpHandler) SubmitAlbumTags(w http.ResponseWriter, r *http.Request) {
 
 	newTags, err := h.tagsService.SetAlbumTags(ctx, userId, albumId, inputs)
 	if err != nil {
-		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
-			Status: http.StatusInternalServerError,
-			Err:    fmt.Errorf("failed to set album tags: %w", err),
-		})
+		handleTagsError(ctx, w, err)
 		return
 	}
 
@@ -164,3 +253,283 @@ func (h *HttpHandler) SubmitAlbumTags(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 }
+
+func (h *HttpHandler) GetTagsPage(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	tagGroups, err := h.tagsService.GetOrCreateTagGroups(ctx, userId)
+	if err != nil {
+		handleTagsError(ctx, w, err)
+		return
+	}
+
+	usage, err := h.tagsService.GetTagUsage(ctx, userId)
+	if err != nil {
+		handleTagsError(ctx, w, err)
+		return
+	}
+
+	err = TagsPage(usage, tagGroups).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+	}
+}
+
+func (h *HttpHandler) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	_, err = h.tagsService.UpdateTag(ctx, userId, r.PathValue("tagId"), tags.TagUpdate{
+		Name:     r.Form.Get("name"),
+		GroupID:  r.Form.Get("groupId"),
+		Color:    models.TagColor(r.Form.Get("color")),
+		ParentID: r.Form.Get("parentId"),
+	})
+	if err != nil {
+		handleTagsError(ctx, w, err)
+		return
+	}
+
+	err = templates.Redirect("/app/tags", 0).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+	}
+}
+
+func (h *HttpHandler) MergeTag(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	err = h.tagsService.MergeTags(ctx, userId, r.PathValue("tagId"), r.Form.Get("intoTagId"))
+	if err != nil {
+		handleTagsError(ctx, w, err)
+		return
+	}
+
+	err = templates.Redirect("/app/tags", 0).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+	}
+}
+
+// DeleteTag deletes a tag, answering with nothing so its row is swapped out.
+func (h *HttpHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	err = h.tagsService.DeleteTag(ctx, userId, r.PathValue("tagId"))
+	if err != nil {
+		handleTagsError(ctx, w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func parseTagGroupInput(form url.Values) tags.TagGroupInput {
+	return tags.TagGroupInput{
+		Name:      form.Get("name"),
+		Color:     models.TagColor(form.Get("color")),
+		Exclusive: form.Get("exclusive") == "true",
+	}
+}
+
+func (h *HttpHandler) CreateTagGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	_, err = h.tagsService.CreateTagGroup(ctx, userId, parseTagGroupInput(r.Form))
+	if err != nil {
+		handleTagsError(ctx, w, err)
+		return
+	}
+
+	err = templates.Redirect("/app/tags", 0).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+	}
+}
+
+func (h *HttpHandler) UpdateTagGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	_, err = h.tagsService.UpdateTagGroup(ctx, userId, r.PathValue("groupId"), parseTagGroupInput(r.Form))
+	if err != nil {
+		handleTagsError(ctx, w, err)
+		return
+	}
+
+	err = templates.Redirect("/app/tags", 0).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+	}
+}
+
+// MoveTagGroup moves a tag group by the form's offset: -1 for up, 1 for down.
+func (h *HttpHandler) MoveTagGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	offset, err := strconv.Atoi(r.Form.Get("offset"))
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("invalid offset: %w", err),
+		})
+		return
+	}
+
+	err = h.tagsService.MoveTagGroup(ctx, userId, r.PathValue("groupId"), offset)
+	if err != nil {
+		handleTagsError(ctx, w, err)
+		return
+	}
+
+	err = templates.Redirect("/app/tags", 0).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+	}
+}
+
+func (h *HttpHandler) DeleteTagGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	err = h.tagsService.DeleteTagGroup(ctx, userId, r.PathValue("groupId"))
+	if err != nil {
+		handleTagsError(ctx, w, err)
+		return
+	}
+
+	err = templates.Redirect("/app/tags", 0).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package tags
+
+import (
+	"errors"
+	"fmt"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const tagGroupNameMaxLength = 50
+
+var (
+	ErrInvalidTagGroup     = errors.New("invalid tag group")
+	ErrTagGroupNameTaken   = errors.New("another tag group already has that name")
+	ErrExclusiveGroupInUse = errors.New("some albums already have more than one tag from that group")
+)
+
+// TagGroupInput creates or changes a tag group.
+type TagGroupInput struct {
+	Name  string
+	Color models.TagColor
+	// Exclusive groups allow only one of their tags per album.
+	Exclusive bool
+}
+
+func (in TagGroupInput) Normalize() TagGroupInput {
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Color == "" {
+		in.Color = models.TagColorNone
+	}
+	return in
+}
+
+func (in TagGroupInput) Validate() error {
+	var errs []error
+	if in.Name == "" {
+		errs = append(errs, errors.New("a name is required"))
+	} else if utf8.RuneCountInString(in.Name) > tagGroupNameMaxLength {
+		errs = append(errs, fmt.Errorf("the name can be at most %d characters", tagGroupNameMaxLength))
+	}
+	if !slices.Contains(Colors, in.Color) {
+		errs = append(errs, fmt.Errorf("unknown color %q", in.Color))
+	}
+	return errors.Join(errs...)
+}
+
+// moveGroup returns the groups with the one at index moved by offset places,
+// or nil when it can't move that far.
+func moveGroup(groups []*TagGroupDTO, index, offset int) []*TagGroupDTO {
+	target := index + offset
+	if index < 0 || index >= len(groups) || target < 0 || target >= len(groups) || offset == 0 {
+		return nil
+	}
+	moved := slices.Clone(groups)
+	group := moved[index]
+	moved = slices.Delete(moved, index, index+1)
+	return slices.Insert(moved, target, group)
+}
@@ -0,0 +1,67 @@
+package tags
+
+import (
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"strings"
+	"testing"
+)
+
+func TestTagGroupInput_Validate(t *testing.T) {
+	cases := []struct {
+		name  string
+		input TagGroupInput
+		ok    bool
+	}{
+		{"name only", TagGroupInput{Name: "Era"}, true},
+		{"colored and exclusive", TagGroupInput{Name: "Era", Color: models.TagColorWarning, Exclusive: true}, true},
+		{"blank name", TagGroupInput{Name: "  "}, false},
+		{"name too long", TagGroupInput{Name: strings.Repeat("a", 51)}, false},
+		{"unknown color", TagGroupInput{Name: "Era", Color: "mauve"}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.input.Normalize().Validate()
+			if c.ok && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !c.ok && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestMoveGroup(t *testing.T) {
+	groups := []*TagGroupDTO{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	ids := func(groups []*TagGroupDTO) string {
+		names := make([]string, 0, len(groups))
+		for _, group := range groups {
+			names = append(names, group.ID)
+		}
+		return strings.Join(names, "")
+	}
+
+	cases := []struct {
+		name   string
+		index  int
+		offset int
+		want   string
+	}{
+		{"up", 2, -1, "acb"},
+		{"down", 0, 1, "bac"},
+		{"to the end", 0, 2, "bca"},
+		{"past the top", 0, -1, ""},
+		{"past the bottom", 2, 1, ""},
+		{"nowhere", 1, 0, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := ids(moveGroup(groups, c.index, c.offset)); got != c.want {
+				t.Errorf("expected %q, got %q", c.want, got)
+			}
+		})
+	}
+	if ids(groups) != "abc" {
+		t.Errorf("expected the groups to be left alone, got %q", ids(groups))
+	}
+}
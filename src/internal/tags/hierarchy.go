@@ -0,0 +1,71 @@
+package tags
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrTagCycle = errors.New("a tag can't sit under itself or one of its own subtags")
+
+// Lineage returns the tag followed by the tags it sits under, nearest first.
+func (t TagDTO) Lineage() []TagDTO {
+	lineage := []TagDTO{t}
+	seen := map[string]bool{t.ID: true}
+	for parent := t.Parent; parent != nil && !seen[parent.ID]; parent = parent.Parent {
+		seen[parent.ID] = true
+		lineage = append(lineage, *parent)
+	}
+	return lineage
+}
+
+// IsWithin reports whether the tag is the given tag or sits anywhere under it.
+func (t TagDTO) IsWithin(tagId string) bool {
+	for _, tag := range t.Lineage() {
+		if tag.ID == tagId {
+			return true
+		}
+	}
+	return false
+}
+
+// Path names the tag from the top of its hierarchy down, as in
+// "rock / shoegaze".
+func (t TagDTO) Path() string {
+	lineage := t.Lineage()
+	names := make([]string, len(lineage))
+	for i, tag := range lineage {
+		names[len(lineage)-1-i] = tag.Name
+	}
+	return strings.Join(names, " / ")
+}
+
+// tagTree indexes a user's tags by id, each linked to the tag it sits under.
+type tagTree map[string]*TagDTO
+
+func newTagTree(userTags []TagDTO) tagTree {
+	tree := make(tagTree, len(userTags))
+	for _, tag := range userTags {
+		tree[tag.ID] = &tag
+	}
+	for _, tag := range tree {
+		tag.Parent = tree[tag.ParentID]
+	}
+	return tree
+}
+
+// link fills in the parents of a tag loaded apart from the tree.
+func (tree tagTree) link(tag TagDTO) TagDTO {
+	tag.Parent = tree[tag.ParentID]
+	return tag
+}
+
+// checkTagParent refuses to put a tag under itself or under one of its own
+// subtags. parents maps each of the user's tags to the tag it sits under.
+func checkTagParent(parents map[string]string, tagId, parentId string) error {
+	for id, steps := parentId, 0; id != "" && steps <= len(parents); id, steps = parents[id], steps+1 {
+		if id == tagId {
+			return ErrTagCycle
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package tags
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTagDTO_Path(t *testing.T) {
+	tree := newTagTree([]TagDTO{
+		{ID: "rock", Name: "rock"},
+		{ID: "shoegaze", Name: "shoegaze", ParentID: "rock"},
+		{ID: "blackgaze", Name: "blackgaze", ParentID: "shoegaze"},
+		{ID: "orphan", Name: "orphan", ParentID: "deleted"},
+	})
+
+	cases := []struct {
+		tagId string
+		want  string
+	}{
+		{"rock", "rock"},
+		{"shoegaze", "rock / shoegaze"},
+		{"blackgaze", "rock / shoegaze / blackgaze"},
+		{"orphan", "orphan"},
+	}
+	for _, c := range cases {
+		t.Run(c.tagId, func(t *testing.T) {
+			if got := tree[c.tagId].Path(); got != c.want {
+				t.Errorf("expected %q, got %q", c.want, got)
+			}
+		})
+	}
+}
+
+func TestTagDTO_IsWithin(t *testing.T) {
+	tree := newTagTree([]TagDTO{
+		{ID: "rock", Name: "rock"},
+		{ID: "shoegaze", Name: "shoegaze", ParentID: "rock"},
+		{ID: "jazz", Name: "jazz"},
+	})
+	shoegaze := tree.link(TagDTO{ID: "shoegaze", Name: "shoegaze", ParentID: "rock"})
+
+	cases := []struct {
+		name  string
+		tagId string
+		want  bool
+	}{
+		{"itself", "shoegaze", true},
+		{"its parent", "rock", true},
+		{"an unrelated tag", "jazz", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := shoegaze.IsWithin(c.tagId); got != c.want {
+				t.Errorf("expected %v, got %v", c.want, got)
+			}
+		})
+	}
+}
+
+func TestTagDTO_Lineage_StopsAtCycle(t *testing.T) {
+	tree := newTagTree([]TagDTO{
+		{ID: "a", Name: "a", ParentID: "b"},
+		{ID: "b", Name: "b", ParentID: "a"},
+	})
+	if got := len(tree["a"].Lineage()); got != 2 {
+		t.Errorf("expected 2 tags in the lineage, got %d", got)
+	}
+}
+
+func TestCheckTagParent(t *testing.T) {
+	parents := map[string]string{
+		"rock":      "",
+		"shoegaze":  "rock",
+		"blackgaze": "shoegaze",
+		"jazz":      "",
+	}
+
+	cases := []struct {
+		name     string
+		tagId    string
+		parentId string
+		wantErr  error
+	}{
+		{"under an unrelated tag", "jazz", "rock", nil},
+		{"deeper in its own branch", "blackgaze", "rock", nil},
+		{"under itself", "rock", "rock", ErrTagCycle},
+		{"under its child", "rock", "shoegaze", ErrTagCycle},
+		{"under its grandchild", "rock", "blackgaze", ErrTagCycle},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := checkTagParent(parents, c.tagId, c.parentId)
+			if !errors.Is(err, c.wantErr) {
+				t.Errorf("expected %v, got %v", c.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,533 @@
+package tags
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const queryMaxLength = 500
+
+var ErrInvalidTagQuery = errors.New("invalid tag query")
+
+// Query is a boolean filter over an album's tags, such as
+// "mood:late-night AND NOT sound:ambient". A term is a tag name, a tag name
+// in a group ("group:tag") or any tag in a group ("group:*"). NOT binds
+// tighter than AND, which binds tighter than OR; terms side by side are
+// ANDed, and parentheses group. Names with spaces are quoted.
+type Query struct {
+	root queryNode
+}
+
+// ParseQuery reads a tag query. Keywords are case-insensitive, as are tag and
+// group names.
+func ParseQuery(text string) (*Query, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("%w: the query is empty", ErrInvalidTagQuery)
+	}
+	if utf8.RuneCountInString(text) > queryMaxLength {
+		return nil, fmt.Errorf("%w: the query can be at most %d characters", ErrInvalidTagQuery, queryMaxLength)
+	}
+	tokens, err := lexQuery(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTagQuery, err)
+	}
+	p := &queryParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %s", p.tokens[p.pos])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTagQuery, err)
+	}
+	return &Query{root: root}, nil
+}
+
+// String writes the query back out in a canonical form that parses to the
+// same query.
+func (q *Query) String() string {
+	if q == nil {
+		return ""
+	}
+	return q.root.string(precedenceOr)
+}
+
+// Matches reports whether an album with the given tags matches the query.
+func (q *Query) Matches(albumTags []TagDTO) bool {
+	return q.root.matches(albumTags)
+}
+
+// SQL returns a condition matching the albums, identified by albumIdColumn,
+// whose tags match the query, and the arguments it takes.
+func (q *Query) SQL(userId, albumIdColumn string) (string, []any) {
+	var b strings.Builder
+	var args []any
+	q.root.sql(&b, &args, userId, albumIdColumn)
+	return b.String(), args
+}
+
+// TagIDsSQL returns a condition matching the albums, identified by
+// albumIdColumn, that have any of the tags or their subtags, and the
+// arguments it takes.
+func TagIDsSQL(userId string, tagIds []string, albumIdColumn string) (string, []any) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "EXISTS (SELECT 1 FROM album_tags WHERE album_tags.user_id = ? AND album_tags.album_id = %s", albumIdColumn)
+	b.WriteString(" AND album_tags.tag_id IN (WITH RECURSIVE subtags(id) AS (SELECT tags.id FROM tags WHERE tags.user_id = ? AND tags.id IN (")
+	args := []any{userId, userId}
+	for i, tagId := range tagIds {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("?")
+		args = append(args, tagId)
+	}
+	b.WriteString(") UNION SELECT tags.id FROM tags JOIN subtags ON tags.parent_id = subtags.id) SELECT id FROM subtags))")
+	return b.String(), args
+}
+
+// MarshalText lets a query be saved as its text, as in a smart shelf's filter.
+// Saved queries name tags and groups, so the tags service rewrites them when
+// one is renamed or merged.
+func (q *Query) MarshalText() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+func (q *Query) UnmarshalText(text []byte) error {
+	parsed, err := ParseQuery(string(text))
+	if err != nil {
+		return err
+	}
+	*q = *parsed
+	return nil
+}
+
+// renameTag points the terms naming a tag at what it's now called. from and
+// to are the tag's name, in its group if it has one; terms naming the tag in
+// another group matched nothing and are left alone. It reports whether the
+// query changed.
+func (q *Query) renameTag(from, to queryTerm) bool {
+	changed := false
+	q.root = mapQueryTerms(q.root, func(t queryTerm) queryTerm {
+		if t.tag != from.tag || (t.group != "" && !strings.EqualFold(t.group, from.group)) {
+			return t
+		}
+		renamed := queryTerm{tag: to.tag}
+		if t.group != "" {
+			renamed.group = to.group
+		}
+		changed = changed || renamed != t
+		return renamed
+	})
+	return changed
+}
+
+// renameGroup points the terms naming a group at its new name, reporting
+// whether the query changed.
+func (q *Query) renameGroup(from, to string) bool {
+	changed := false
+	q.root = mapQueryTerms(q.root, func(t queryTerm) queryTerm {
+		if t.group != "" && strings.EqualFold(t.group, from) && t.group != to {
+			t.group = to
+			changed = true
+		}
+		return t
+	})
+	return changed
+}
+
+// namesTag reports whether any of the query's terms names the tag, given as
+// its name in its group if it has one.
+func (q *Query) namesTag(tag queryTerm) bool {
+	named := false
+	mapQueryTerms(q.root, func(t queryTerm) queryTerm {
+		named = named || (t.tag == tag.tag && (t.group == "" || strings.EqualFold(t.group, tag.group)))
+		return t
+	})
+	return named
+}
+
+// mapQueryTerms rebuilds a query with each of its terms replaced.
+func mapQueryTerms(node queryNode, fn func(queryTerm) queryTerm) queryNode {
+	switch n := node.(type) {
+	case queryTerm:
+		return fn(n)
+	case queryNot:
+		return queryNot{operand: mapQueryTerms(n.operand, fn)}
+	case queryJunction:
+		operands := make([]queryNode, 0, len(n.operands))
+		for _, operand := range n.operands {
+			operands = append(operands, mapQueryTerms(operand, fn))
+		}
+		return queryJunction{or: n.or, operands: operands}
+	default:
+		return node
+	}
+}
+
+// QueryTerm is the query term that matches the tag: its name, in its group if
+// it has one.
+func QueryTerm(tag TagDTO) string {
+	term := queryTerm{tag: tag.Name}
+	if tag.Group != nil {
+		term.group = tag.Group.Name
+	}
+	return term.string(precedenceOr)
+}
+
+const (
+	precedenceOr = iota
+	precedenceAnd
+	precedenceNot
+)
+
+type queryNode interface {
+	matches(albumTags []TagDTO) bool
+	sql(b *strings.Builder, args *[]any, userId, albumIdColumn string)
+	string(parent int) string
+}
+
+// queryTerm matches albums with a tag or any of its subtags. An empty group
+// matches the tag in any group or none; an empty tag matches any tag in the
+// group.
+type queryTerm struct {
+	group string
+	tag   string
+}
+
+func (t queryTerm) matches(albumTags []TagDTO) bool {
+	for _, albumTag := range albumTags {
+		for _, tag := range albumTag.Lineage() {
+			if t.matchesTag(tag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (t queryTerm) matchesTag(tag TagDTO) bool {
+	if t.tag != "" && tag.Name != t.tag {
+		return false
+	}
+	if t.group != "" && (tag.Group == nil || !strings.EqualFold(tag.Group.Name, t.group)) {
+		return false
+	}
+	return true
+}
+
+func (t queryTerm) sql(b *strings.Builder, args *[]any, userId, albumIdColumn string) {
+	fmt.Fprintf(b, "EXISTS (SELECT 1 FROM album_tags WHERE album_tags.user_id = ? AND album_tags.album_id = %s", albumIdColumn)
+	b.WriteString(" AND album_tags.tag_id IN (WITH RECURSIVE subtags(id) AS (SELECT tags.id FROM tags")
+	if t.group != "" {
+		b.WriteString(" JOIN tag_groups ON tag_groups.id = tags.group_id")
+	}
+	b.WriteString(" WHERE tags.user_id = ?")
+	*args = append(*args, userId, userId)
+	if t.tag != "" {
+		b.WriteString(" AND tags.name = ?")
+		*args = append(*args, t.tag)
+	}
+	if t.group != "" {
+		b.WriteString(" AND tag_groups.name = ? COLLATE NOCASE")
+		*args = append(*args, t.group)
+	}
+	b.WriteString(" UNION SELECT tags.id FROM tags JOIN subtags ON tags.parent_id = subtags.id) SELECT id FROM subtags))")
+}
+
+func (t queryTerm) string(parent int) string {
+	if t.group == "" {
+		return quoteQueryName(t.tag)
+	}
+	if t.tag == "" {
+		return quoteQueryName(t.group) + ":*"
+	}
+	return quoteQueryName(t.group) + ":" + quoteQueryName(t.tag)
+}
+
+type queryNot struct {
+	operand queryNode
+}
+
+func (n queryNot) matches(albumTags []TagDTO) bool {
+	return !n.operand.matches(albumTags)
+}
+
+func (n queryNot) sql(b *strings.Builder, args *[]any, userId, albumIdColumn string) {
+	b.WriteString("NOT ")
+	n.operand.sql(b, args, userId, albumIdColumn)
+}
+
+func (n queryNot) string(parent int) string {
+	return "NOT " + n.operand.string(precedenceNot)
+}
+
+// queryJunction is a run of operands joined by AND, or by OR.
+type queryJunction struct {
+	or       bool
+	operands []queryNode
+}
+
+func (j queryJunction) matches(albumTags []TagDTO) bool {
+	for _, operand := range j.operands {
+		if operand.matches(albumTags) == j.or {
+			return j.or
+		}
+	}
+	return !j.or
+}
+
+func (j queryJunction) sql(b *strings.Builder, args *[]any, userId, albumIdColumn string) {
+	b.WriteString("(")
+	for i, operand := range j.operands {
+		if i > 0 {
+			b.WriteString(" " + j.keyword() + " ")
+		}
+		operand.sql(b, args, userId, albumIdColumn)
+	}
+	b.WriteString(")")
+}
+
+func (j queryJunction) string(parent int) string {
+	precedence := precedenceAnd
+	if j.or {
+		precedence = precedenceOr
+	}
+	parts := make([]string, 0, len(j.operands))
+	for _, operand := range j.operands {
+		parts = append(parts, operand.string(precedence))
+	}
+	s := strings.Join(parts, " "+j.keyword()+" ")
+	if parent > precedence {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+func (j queryJunction) keyword() string {
+	if j.or {
+		return "OR"
+	}
+	return "AND"
+}
+
+// quoteQueryName quotes a name the lexer would otherwise split or read as a
+// keyword.
+func quoteQueryName(name string) string {
+	if name == "" || strings.ContainsAny(name, " ():\"") || isQueryKeyword(name) {
+		return `"` + strings.ReplaceAll(name, `"`, "") + `"`
+	}
+	return name
+}
+
+func isQueryKeyword(word string) bool {
+	switch strings.ToUpper(word) {
+	case "AND", "OR", "NOT":
+		return true
+	}
+	return false
+}
+
+type queryTokenKind int
+
+const (
+	queryTokenTerm queryTokenKind = iota
+	queryTokenAnd
+	queryTokenOr
+	queryTokenNot
+	queryTokenOpen
+	queryTokenClose
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	term queryTerm
+}
+
+func (t queryToken) String() string {
+	switch t.kind {
+	case queryTokenAnd:
+		return "AND"
+	case queryTokenOr:
+		return "OR"
+	case queryTokenNot:
+		return "NOT"
+	case queryTokenOpen:
+		return `"("`
+	case queryTokenClose:
+		return `")"`
+	default:
+		return fmt.Sprintf("%q", t.term.string(precedenceOr))
+	}
+}
+
+func lexQuery(text string) ([]queryToken, error) {
+	var tokens []queryToken
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		switch r := runes[i]; {
+		case r == ' ' || r == '\t' || r == '\n':
+			i++
+		case r == '(':
+			tokens = append(tokens, queryToken{kind: queryTokenOpen})
+			i++
+		case r == ')':
+			tokens = append(tokens, queryToken{kind: queryTokenClose})
+			i++
+		default:
+			token, next, err := lexQueryTerm(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token)
+			i = next
+		}
+	}
+	return tokens, nil
+}
+
+// lexQueryTerm reads a keyword or a term starting at runes[start] and returns
+// the index just past it.
+func lexQueryTerm(runes []rune, start int) (queryToken, int, error) {
+	var parts []string
+	var current strings.Builder
+	quoted := false
+	i := start
+	for ; i < len(runes); i++ {
+		r := runes[i]
+		if r == '"' {
+			quoted = !quoted
+			continue
+		}
+		if !quoted && (r == ' ' || r == '\t' || r == '\n' || r == '(' || r == ')') {
+			break
+		}
+		if !quoted && r == ':' && len(parts) == 0 {
+			parts = append(parts, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	if quoted {
+		return queryToken{}, 0, errors.New("a quote isn't closed")
+	}
+	parts = append(parts, current.String())
+
+	word := string(runes[start:i])
+	switch strings.ToUpper(word) {
+	case "AND":
+		return queryToken{kind: queryTokenAnd}, i, nil
+	case "OR":
+		return queryToken{kind: queryTokenOr}, i, nil
+	case "NOT":
+		return queryToken{kind: queryTokenNot}, i, nil
+	}
+
+	var term queryTerm
+	if len(parts) == 1 {
+		term.tag = normalizeTag(parts[0])
+		if term.tag == "" {
+			return queryToken{}, 0, fmt.Errorf("%q isn't a tag name", word)
+		}
+	} else {
+		term.group = strings.TrimSpace(parts[0])
+		if term.group == "" {
+			return queryToken{}, 0, fmt.Errorf("%q is missing a group name before the colon", word)
+		}
+		if strings.TrimSpace(parts[1]) != "*" {
+			term.tag = normalizeTag(parts[1])
+			if term.tag == "" {
+				return queryToken{}, 0, fmt.Errorf("%q is missing a tag name after the colon; use %s:* for any tag in the group", word, quoteQueryName(term.group))
+			}
+		}
+	}
+	return queryToken{kind: queryTokenTerm, term: term}, i, nil
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() (queryToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return queryToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	return p.parseJunction(true)
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	return p.parseJunction(false)
+}
+
+// parseJunction reads operands joined by OR, or by AND. Operands side by side
+// with no keyword between them are ANDed.
+func (p *queryParser) parseJunction(or bool) (queryNode, error) {
+	operand := p.parseNot
+	if or {
+		operand = p.parseAnd
+	}
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	operands := []queryNode{first}
+	for {
+		token, ok := p.peek()
+		if !ok {
+			break
+		}
+		if or && token.kind == queryTokenOr || !or && token.kind == queryTokenAnd {
+			p.pos++
+		} else if or || token.kind == queryTokenOr || token.kind == queryTokenClose {
+			break
+		}
+		next, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, next)
+	}
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return queryJunction{or: or, operands: operands}, nil
+}
+
+func (p *queryParser) parseNot() (queryNode, error) {
+	token, ok := p.peek()
+	if !ok {
+		return nil, errors.New("the query ends where a tag was expected")
+	}
+	switch token.kind {
+	case queryTokenNot:
+		p.pos++
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return queryNot{operand: operand}, nil
+	case queryTokenOpen:
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if token, ok := p.peek(); !ok || token.kind != queryTokenClose {
+			return nil, errors.New(`a "(" isn't closed`)
+		}
+		p.pos++
+		return inner, nil
+	case queryTokenTerm:
+		p.pos++
+		return token.term, nil
+	default:
+		return nil, fmt.Errorf("expected a tag but found %s", token)
+	}
+}
@@ -0,0 +1,172 @@
+package tags
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"single tag", "Shoegaze", "shoegaze"},
+		{"tag in group", "mood:late-night", "mood:late-night"},
+		{"any tag in group", "Mood:*", "Mood:*"},
+		{"quoted names", `"long drives":"late night"`, `"long drives":"late night"`},
+		{"keywords are case-insensitive", "mood:late-night and not sound:ambient", "mood:late-night AND NOT sound:ambient"},
+		{"side by side is AND", "jazz night", "jazz AND night"},
+		{"AND binds tighter than OR", "a OR b AND c", "a OR b AND c"},
+		{"parentheses group", "(a OR b) AND c", "(a OR b) AND c"},
+		{"redundant parentheses drop", "((a)) OR (b AND c)", "a OR b AND c"},
+		{"NOT of a group", "NOT (a OR b)", "NOT (a OR b)"},
+		{"quoted keyword is a tag", `"and" OR b`, `"and" OR b`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			q, err := ParseQuery(c.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := q.String(); got != c.want {
+				t.Errorf("expected %q, got %q", c.want, got)
+			}
+			again, err := ParseQuery(q.String())
+			if err != nil || again.String() != c.want {
+				t.Errorf("expected %q to round-trip, got %q (%v)", c.want, again, err)
+			}
+		})
+	}
+}
+
+func TestParseQuery_Invalid(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+	}{
+		{"empty", "  "},
+		{"dangling operator", "jazz AND"},
+		{"leading operator", "OR jazz"},
+		{"unclosed parenthesis", "(jazz OR soul"},
+		{"stray parenthesis", "jazz)"},
+		{"unclosed quote", `"late night`},
+		{"missing group", ":jazz"},
+		{"missing tag", "mood:"},
+		{"nothing left of the tag", "!!"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := ParseQuery(c.in)
+			if !errors.Is(err, ErrInvalidTagQuery) {
+				t.Errorf("expected ErrInvalidTagQuery, got %v", err)
+			}
+		})
+	}
+}
+
+func TestQuery_Matches(t *testing.T) {
+	mood := &TagGroupDTO{ID: "g1", Name: "Mood"}
+	sound := &TagGroupDTO{ID: "g2", Name: "Sound"}
+	evening := &TagDTO{ID: "t0", Name: "evening", Group: mood}
+	albumTags := []TagDTO{
+		{ID: "t1", Name: "late-night", Group: mood, ParentID: "t0", Parent: evening},
+		{ID: "t2", Name: "jazz", Group: sound},
+		{ID: "t3", Name: "vinyl rip"},
+	}
+
+	cases := []struct {
+		name  string
+		query string
+		want  bool
+	}{
+		{"tag in any group", "jazz", true},
+		{"tag in its group", "mood:late-night", true},
+		{"group names are case-insensitive", "MOOD:late-night", true},
+		{"tag in another group", "sound:late-night", false},
+		{"any tag in group", "sound:*", true},
+		{"ungrouped tag", `"vinyl rip"`, true},
+		{"AND NOT excludes", "mood:late-night AND NOT sound:ambient", true},
+		{"AND NOT excludes a present tag", "mood:late-night AND NOT sound:jazz", false},
+		{"OR needs one side", "ambient OR jazz", true},
+		{"AND needs both sides", "ambient AND jazz", false},
+		{"missing tag", "ambient", false},
+		{"parent of a tag", "mood:evening", true},
+		{"sibling of a parent", "mood:morning", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			q, err := ParseQuery(c.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := q.Matches(albumTags); got != c.want {
+				t.Errorf("expected %v, got %v", c.want, got)
+			}
+		})
+	}
+}
+
+func TestQuery_SQL(t *testing.T) {
+	q, err := ParseQuery("mood:late-night AND NOT jazz")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	where, args := q.SQL("u1", "albums.id")
+	subtags := " UNION SELECT tags.id FROM tags JOIN subtags ON tags.parent_id = subtags.id) SELECT id FROM subtags))"
+	want := "(EXISTS (SELECT 1 FROM album_tags WHERE album_tags.user_id = ? AND album_tags.album_id = albums.id" +
+		" AND album_tags.tag_id IN (WITH RECURSIVE subtags(id) AS (SELECT tags.id FROM tags JOIN tag_groups ON tag_groups.id = tags.group_id" +
+		" WHERE tags.user_id = ? AND tags.name = ? AND tag_groups.name = ? COLLATE NOCASE" + subtags +
+		" AND NOT EXISTS (SELECT 1 FROM album_tags WHERE album_tags.user_id = ? AND album_tags.album_id = albums.id" +
+		" AND album_tags.tag_id IN (WITH RECURSIVE subtags(id) AS (SELECT tags.id FROM tags" +
+		" WHERE tags.user_id = ? AND tags.name = ?" + subtags + ")"
+	if where != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, where)
+	}
+	wantArgs := []any{"u1", "u1", "late-night", "mood", "u1", "u1", "jazz"}
+	if len(args) != len(wantArgs) {
+		t.Fatalf("expected args %v, got %v", wantArgs, args)
+	}
+	for i := range wantArgs {
+		if args[i] != wantArgs[i] {
+			t.Errorf("expected args %v, got %v", wantArgs, args)
+			break
+		}
+	}
+}
+
+func TestQuery_RenameTag(t *testing.T) {
+	cases := []struct {
+		query    string
+		from, to queryTerm
+		want     string
+		changed  bool
+	}{
+		{"jazz AND NOT mood:sad", queryTerm{tag: "jazz"}, queryTerm{tag: "jazz-fusion"}, "jazz-fusion AND NOT mood:sad", true},
+		{"genre:jazz OR jazz", queryTerm{group: "genre", tag: "jazz"}, queryTerm{group: "style", tag: "bebop"}, "style:bebop OR bebop", true},
+		{"GENRE:jazz", queryTerm{group: "genre", tag: "jazz"}, queryTerm{tag: "jazz"}, "jazz", true},
+		{"mood:jazz", queryTerm{group: "genre", tag: "jazz"}, queryTerm{tag: "bebop"}, "mood:jazz", false},
+		{"genre:*", queryTerm{group: "genre", tag: "jazz"}, queryTerm{tag: "bebop"}, "genre:*", false},
+	}
+	for _, c := range cases {
+		q, err := ParseQuery(c.query)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		changed := q.renameTag(c.from, c.to)
+		if got := q.String(); got != c.want || changed != c.changed {
+			t.Errorf("renaming in %q: expected %q (%v), got %q (%v)", c.query, c.want, c.changed, got, changed)
+		}
+	}
+
+	q, err := ParseQuery("Genre:* AND NOT genre:jazz")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !q.renameGroup("genre", "style") || q.String() != "style:* AND NOT style:jazz" {
+		t.Errorf("expected the group renamed, got %q", q.String())
+	}
+	if !q.namesTag(queryTerm{group: "style", tag: "jazz"}) || q.namesTag(queryTerm{tag: "jazz"}) {
+		t.Errorf("expected only style:jazz named in %q", q.String())
+	}
+}
@@ -3,10 +3,14 @@ package tags
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"regexp"
 	"github.com/alecdray/wax/src/internal/core/db"
+	"github.com/alecdray/wax/src/internal/core/db/models"
 	"github.com/alecdray/wax/src/internal/core/db/sqlc"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/google/uuid"
@@ -26,14 +30,32 @@ const (
 )
 
 type TagGroupDTO struct {
-	ID   string
-	Name string
+	ID    string
+	Name  string
+	Color models.TagColor
+	// Exclusive groups allow only one of their tags per album.
+	Exclusive bool
+	Position  int
 }
 
 type TagDTO struct {
 	ID    string
 	Name  string
+	Color models.TagColor
 	Group *TagGroupDTO
+	// ParentID is the tag this one sits under, empty at the top level.
+	ParentID string
+	// Parent is the tag this one sits under, linked up to the top of the
+	// hierarchy wherever the user's tags are loaded.
+	Parent *TagDTO
+}
+
+// BadgeColor is the color the tag is shown in: its own, or else its group's.
+func (t TagDTO) BadgeColor() models.TagColor {
+	if t.Color != models.TagColorNone || t.Group == nil {
+		return t.Color
+	}
+	return t.Group.Color
 }
 
 type TagInput struct {
@@ -49,44 +71,70 @@ func NewService(db *db.DB) *Service {
 	return &Service{db: db}
 }
 
-func newTagGroupDTO(id, name string) *TagGroupDTO {
+func newTagGroupDTOFromModel(model sqlc.TagGroup) *TagGroupDTO {
+	return &TagGroupDTO{
+		ID:        model.ID,
+		Name:      model.Name,
+		Color:     model.Color,
+		Exclusive: model.Exclusive,
+		Position:  int(model.Position),
+	}
+}
+
+// newTagGroupDTOFromColumns constructs a group from query rows that use
+// COALESCE for group fields, returning nil for ungrouped tags.
+func newTagGroupDTOFromColumns(id, name, color string, position int64, exclusive bool) *TagGroupDTO {
 	if id == "" {
 		return nil
 	}
-	return &TagGroupDTO{ID: id, Name: name}
+	return &TagGroupDTO{
+		ID:        id,
+		Name:      name,
+		Color:     models.TagColor(color),
+		Exclusive: exclusive,
+		Position:  int(position),
+	}
 }
 
-// newTagDTOFromRow constructs a TagDTO from query rows that use COALESCE for group fields.
-func newTagDTOFromRow(tag sqlc.Tag, groupIDValue, groupName string) TagDTO {
+func newTagDTOFromRow(tag sqlc.Tag, group *TagGroupDTO) TagDTO {
 	dto := TagDTO{
-		ID:   tag.ID,
-		Name: tag.Name,
+		ID:       tag.ID,
+		Name:     tag.Name,
+		Color:    tag.Color,
+		ParentID: tag.ParentID.String,
 	}
-	if tag.GroupID.Valid && groupIDValue != "" {
-		dto.Group = newTagGroupDTO(groupIDValue, groupName)
+	if tag.GroupID.Valid {
+		dto.Group = group
 	}
 	return dto
 }
 
-// GetOrCreateDefaultGroups ensures "Sound" and "Mood" tag groups exist for the user.
-func (s *Service) GetOrCreateDefaultGroups(ctx context.Context, userId string) ([]*TagGroupDTO, error) {
+// GetOrCreateTagGroups returns the user's tag groups in order. Users start
+// with "Sound" and "Mood", which are created whenever they have no groups.
+func (s *Service) GetOrCreateTagGroups(ctx context.Context, userId string) ([]*TagGroupDTO, error) {
+	groups, err := s.GetUserTagGroups(ctx, userId)
+	if err != nil || len(groups) > 0 {
+		return groups, err
+	}
+
 	groupNames := []string{TagGroupSound, TagGroupMood}
-	groups := make([]*TagGroupDTO, 0, len(groupNames))
-	for _, name := range groupNames {
+	groups = make([]*TagGroupDTO, 0, len(groupNames))
+	for i, name := range groupNames {
 		model, err := s.db.Queries().GetOrCreateTagGroup(ctx, sqlc.GetOrCreateTagGroupParams{
-			ID:     uuid.NewString(),
-			UserID: userId,
-			Name:   name,
+			ID:       uuid.NewString(),
+			UserID:   userId,
+			Name:     name,
+			Position: int64(i),
 		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to get or create tag group %q: %w", name, err)
 		}
-		groups = append(groups, &TagGroupDTO{ID: model.ID, Name: model.Name})
+		groups = append(groups, newTagGroupDTOFromModel(model))
 	}
 	return groups, nil
 }
 
-// GetUserTagGroups returns all tag groups owned by the user.
+// GetUserTagGroups returns all tag groups owned by the user, in order.
 func (s *Service) GetUserTagGroups(ctx context.Context, userId string) ([]*TagGroupDTO, error) {
 	models, err := s.db.Queries().GetTagGroupsByUserId(ctx, userId)
 	if err != nil {
@@ -94,12 +142,13 @@ func (s *Service) GetUserTagGroups(ctx context.Context, userId string) ([]*TagGr
 	}
 	dtos := make([]*TagGroupDTO, 0, len(models))
 	for _, m := range models {
-		dtos = append(dtos, &TagGroupDTO{ID: m.ID, Name: m.Name})
+		dtos = append(dtos, newTagGroupDTOFromModel(m))
 	}
 	return dtos, nil
 }
 
-// GetUserTags returns all tags owned by the user (for autocomplete).
+// GetUserTags returns all tags owned by the user (for autocomplete), each
+// linked to the tags it sits under.
 func (s *Service) GetUserTags(ctx context.Context, userId string) ([]TagDTO, error) {
 	rows, err := s.db.Queries().GetTagsByUserId(ctx, userId)
 	if err != nil {
@@ -107,11 +156,24 @@ func (s *Service) GetUserTags(ctx context.Context, userId string) ([]TagDTO, err
 	}
 	dtos := make([]TagDTO, 0, len(rows))
 	for _, row := range rows {
-		dtos = append(dtos, newTagDTOFromRow(row.Tag, row.GroupIDValue, row.GroupName))
+		group := newTagGroupDTOFromColumns(row.GroupIDValue, row.GroupName, row.GroupColor, row.GroupPosition, row.GroupExclusive)
+		dtos = append(dtos, newTagDTOFromRow(row.Tag, group))
+	}
+	tree := newTagTree(dtos)
+	for i, dto := range dtos {
+		dtos[i] = tree.link(dto)
 	}
 	return dtos, nil
 }
 
+func (s *Service) getTagTree(ctx context.Context, userId string) (tagTree, error) {
+	userTags, err := s.GetUserTags(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	return newTagTree(userTags), nil
+}
+
 // GetAlbumTagsByAlbumIds returns a map of albumId → []TagDTO for bulk fetching.
 func (s *Service) GetAlbumTagsByAlbumIds(ctx context.Context, userId string, albumIds []string) (map[string][]TagDTO, error) {
 	if len(albumIds) == 0 {
@@ -124,9 +186,14 @@ func (s *Service) GetAlbumTagsByAlbumIds(ctx context.Context, userId string, alb
 	if err != nil {
 		return nil, fmt.Errorf("failed to get album tags: %w", err)
 	}
+	tree, err := s.getTagTree(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
 	result := make(map[string][]TagDTO, len(albumIds))
 	for _, row := range rows {
-		result[row.AlbumID] = append(result[row.AlbumID], newTagDTOFromRow(row.Tag, row.GroupIDValue, row.GroupName))
+		group := newTagGroupDTOFromColumns(row.GroupIDValue, row.GroupName, row.GroupColor, row.GroupPosition, row.GroupExclusive)
+		result[row.AlbumID] = append(result[row.AlbumID], tree.link(newTagDTOFromRow(row.Tag, group)))
 	}
 	return result, nil
 }
@@ -140,19 +207,34 @@ func (s *Service) GetAlbumTags(ctx context.Context, userId, albumId string) ([]T
 	if err != nil {
 		return nil, fmt.Errorf("failed to get album tags: %w", err)
 	}
+	tree, err := s.getTagTree(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
 	dtos := make([]TagDTO, 0, len(rows))
 	for _, row := range rows {
-		dtos = append(dtos, newTagDTOFromRow(row.Tag, row.GroupIDValue, row.GroupName))
+		group := newTagGroupDTOFromColumns(row.GroupIDValue, row.GroupName, row.GroupColor, row.GroupPosition, row.GroupExclusive)
+		dtos = append(dtos, tree.link(newTagDTOFromRow(row.Tag, group)))
 	}
 	return dtos, nil
 }
 
 // SetAlbumTags replaces the tags on an album. It resolves each name to a tag
-// (get-or-create), then replaces all existing album_tags rows.
+// (get-or-create), then replaces all existing album_tags rows. An album can
+// carry only one tag from an exclusive group.
 func (s *Service) SetAlbumTags(ctx context.Context, userId, albumId string, inputs []TagInput) ([]TagDTO, error) {
 	var result []TagDTO
 
 	err := s.db.WithTx(func(tx *db.DB) error {
+		groupModels, err := tx.Queries().GetTagGroupsByUserId(ctx, userId)
+		if err != nil {
+			return fmt.Errorf("failed to get tag groups: %w", err)
+		}
+		groups := make(map[string]*TagGroupDTO, len(groupModels))
+		for _, model := range groupModels {
+			groups[model.ID] = newTagGroupDTOFromModel(model)
+		}
+
 		if err := tx.Queries().DeleteAlbumTagsByAlbumId(ctx, sqlc.DeleteAlbumTagsByAlbumIdParams{
 			UserID:  userId,
 			AlbumID: albumId,
@@ -167,7 +249,7 @@ func (s *Service) SetAlbumTags(ctx context.Context, userId, albumId string, inpu
 			}
 
 			groupID := sql.NullString{}
-			if input.GroupID != "" {
+			if _, ok := groups[input.GroupID]; ok {
 				groupID = sql.NullString{String: input.GroupID, Valid: true}
 			}
 
@@ -191,31 +273,545 @@ func (s *Service) SetAlbumTags(ctx context.Context, userId, albumId string, inpu
 				return fmt.Errorf("failed to create album tag: %w", err)
 			}
 
-			// Resolve group name for DTO from the tag's stored group_id
-			groupIDValue := ""
-			groupName := ""
-			if tag.GroupID.Valid {
-				groupIDValue = tag.GroupID.String
-				// Look up the group name
-				groups, err := tx.Queries().GetTagGroupsByUserId(ctx, userId)
-				if err == nil {
-					for _, g := range groups {
-						if g.ID == tag.GroupID.String {
-							groupName = g.Name
-							break
-						}
-					}
+			// The tag keeps the group it was created with, whatever the input says.
+			result = append(result, newTagDTOFromRow(tag, groups[tag.GroupID.String]))
+		}
+
+		return checkExclusiveGroups(result)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// checkExclusiveGroups refuses an album's tags when more than one is from an
+// exclusive group.
+func checkExclusiveGroups(albumTags []TagDTO) error {
+	counts := make(map[string]int)
+	for _, tag := range albumTags {
+		if tag.Group == nil || !tag.Group.Exclusive {
+			continue
+		}
+		counts[tag.Group.ID]++
+		if counts[tag.Group.ID] == 2 {
+			return fmt.Errorf("%w: an album can have only one %s tag", ErrInvalidTag, tag.Group.Name)
+		}
+	}
+	return nil
+}
+
+// GetTag returns one of the user's tags within an open transaction.
+func GetTag(ctx context.Context, tx *db.DB, userId, tagId string) (sqlc.Tag, error) {
+	tag, err := tx.Queries().GetTag(ctx, sqlc.GetTagParams{
+		ID:     tagId,
+		UserID: userId,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return sqlc.Tag{}, ErrTagNotFound
+	} else if err != nil {
+		return sqlc.Tag{}, fmt.Errorf("failed to get tag: %w", err)
+	}
+	return tag, nil
+}
+
+// GetTagUsage returns all of the user's tags by path, so subtags follow the
+// tag they sit under, with the number of albums each is on by itself and
+// together with its subtags.
+func (s *Service) GetTagUsage(ctx context.Context, userId string) ([]TagUsageDTO, error) {
+	rows, err := s.db.Queries().GetTagUsageByUserId(ctx, sqlc.GetTagUsageByUserIdParams{
+		UserID:   userId,
+		UserID_2: userId,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag usage: %w", err)
+	}
+	userTags := make([]TagDTO, 0, len(rows))
+	for _, row := range rows {
+		group := newTagGroupDTOFromColumns(row.GroupIDValue, row.GroupName, row.GroupColor, row.GroupPosition, row.GroupExclusive)
+		userTags = append(userTags, newTagDTOFromRow(row.Tag, group))
+	}
+	tree := newTagTree(userTags)
+	dtos := make([]TagUsageDTO, 0, len(rows))
+	for i, row := range rows {
+		dtos = append(dtos, TagUsageDTO{
+			Tag:             tree.link(userTags[i]),
+			AlbumCount:      int(row.AlbumCount),
+			TotalAlbumCount: int(row.TotalAlbumCount),
+		})
+	}
+	sort.SliceStable(dtos, func(i, j int) bool {
+		return dtos[i].Tag.Path() < dtos[j].Tag.Path()
+	})
+	return dtos, nil
+}
+
+// UpdateTag renames, regroups, recolors and moves a tag. The name is
+// normalized the same way album tags are, and can't be one another tag
+// already has. A tag can't be moved under itself or one of its subtags.
+// Smart shelves' tag queries naming the tag are rewritten to its new name.
+func (s *Service) UpdateTag(ctx context.Context, userId, tagId string, update TagUpdate) (TagDTO, error) {
+	update = update.Normalize()
+	err := update.Validate()
+	if err != nil {
+		return TagDTO{}, fmt.Errorf("%w: %w", ErrInvalidTag, err)
+	}
+
+	var result TagDTO
+	err = s.db.WithTx(func(tx *db.DB) error {
+		tag, err := GetTag(ctx, tx, userId, tagId)
+		if err != nil {
+			return err
+		}
+
+		var group *TagGroupDTO
+		if update.GroupID != "" {
+			groups, err := tx.Queries().GetTagGroupsByUserId(ctx, userId)
+			if err != nil {
+				return fmt.Errorf("failed to get tag groups: %w", err)
+			}
+			for _, g := range groups {
+				if g.ID == update.GroupID {
+					group = newTagGroupDTOFromModel(g)
+					break
 				}
 			}
+			if group == nil {
+				return ErrTagGroupNotFound
+			}
+		}
+
+		if update.ParentID != "" {
+			err := checkTagParentInTx(ctx, tx, userId, tagId, update.ParentID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if update.Name != tag.Name {
+			existing, err := tx.Queries().GetTagByName(ctx, sqlc.GetTagByNameParams{
+				UserID: userId,
+				Name:   update.Name,
+			})
+			if err == nil && existing.ID != tagId {
+				return ErrTagNameTaken
+			} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("failed to get tag: %w", err)
+			}
+		}
+
+		previousTerm, err := tagTerm(ctx, tx, userId, tag)
+		if err != nil {
+			return err
+		}
+		previousGroupID := tag.GroupID.String
+		groupID := sql.NullString{}
+		if group != nil {
+			groupID = sql.NullString{String: group.ID, Valid: true}
+		}
+		tag, err = tx.Queries().UpdateTag(ctx, sqlc.UpdateTagParams{
+			Name:     update.Name,
+			GroupID:  groupID,
+			Color:    update.Color,
+			ParentID: sql.NullString{String: update.ParentID, Valid: update.ParentID != ""},
+			ID:       tagId,
+			UserID:   userId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update tag: %w", err)
+		}
+
+		if group != nil && group.Exclusive && group.ID != previousGroupID {
+			err = checkExclusiveGroup(ctx, tx, userId, group.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		term := queryTerm{tag: tag.Name}
+		if group != nil {
+			term.group = group.Name
+		}
+		err = renameShelfQueryTag(ctx, tx, userId, previousTerm, term)
+		if err != nil {
+			return err
+		}
+
+		result = newTagDTOFromRow(tag, group)
+		return nil
+	})
+	if err != nil {
+		return TagDTO{}, err
+	}
+	return result, nil
+}
+
+// MergeTags moves every album from one tag onto another, then deletes the
+// first. Albums that already carry both keep a single copy, and tag lists
+// and smart shelves that followed the merged tag follow the one it was merged
+// into. The merged tag's subtags move under the other tag, or up a level when
+// the other tag is one of them or sits under one. A merge that would leave an album with
+// two tags from an exclusive group is refused.
+func (s *Service) MergeTags(ctx context.Context, userId, fromTagId, intoTagId string) error {
+	if fromTagId == intoTagId {
+		return fmt.Errorf("%w: a tag can't be merged into itself", ErrInvalidTag)
+	}
+
+	return s.db.WithTx(func(tx *db.DB) error {
+		from, err := GetTag(ctx, tx, userId, fromTagId)
+		if err != nil {
+			return err
+		}
+		into, err := GetTag(ctx, tx, userId, intoTagId)
+		if err != nil {
+			return err
+		}
+
+		subtagIds, err := tx.Queries().GetSubtagIds(ctx, sqlc.GetSubtagIdsParams{
+			ID:     fromTagId,
+			UserID: userId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get subtags: %w", err)
+		}
+		newParentId := sql.NullString{String: intoTagId, Valid: true}
+		if slices.Contains(subtagIds, intoTagId) {
+			newParentId = from.ParentID
+		}
+		err = tx.Queries().ReparentTags(ctx, sqlc.ReparentTagsParams{
+			ToParentID:   newParentId,
+			UserID:       userId,
+			FromParentID: sql.NullString{String: fromTagId, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to move subtags: %w", err)
+		}
+
+		albumIds, err := tx.Queries().GetAlbumIdsByTagId(ctx, sqlc.GetAlbumIdsByTagIdParams{
+			UserID: userId,
+			TagID:  fromTagId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get tagged albums: %w", err)
+		}
+		for _, albumId := range albumIds {
+			// Albums already tagged with both are left alone by the upsert.
+			_, err := tx.Queries().CreateAlbumTag(ctx, sqlc.CreateAlbumTagParams{
+				ID:      uuid.NewString(),
+				UserID:  userId,
+				AlbumID: albumId,
+				TagID:   intoTagId,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create album tag: %w", err)
+			}
+		}
+
+		err = tx.Queries().UpdateRanklistsTagId(ctx, sqlc.UpdateRanklistsTagIdParams{
+			ToTagID:   sql.NullString{String: intoTagId, Valid: true},
+			UserID:    userId,
+			FromTagID: sql.NullString{String: fromTagId, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update tag lists: %w", err)
+		}
+		err = moveShelfFilters(ctx, tx, userId, fromTagId, intoTagId)
+		if err != nil {
+			return err
+		}
+		fromTerm, err := tagTerm(ctx, tx, userId, from)
+		if err != nil {
+			return err
+		}
+		intoTerm, err := tagTerm(ctx, tx, userId, into)
+		if err != nil {
+			return err
+		}
+		err = renameShelfQueryTag(ctx, tx, userId, fromTerm, intoTerm)
+		if err != nil {
+			return err
+		}
+
+		err = deleteTag(ctx, tx, userId, fromTagId)
+		if err != nil {
+			return err
+		}
+		if into.GroupID.Valid {
+			group, err := getTagGroup(ctx, tx, userId, into.GroupID.String)
+			if err != nil {
+				return err
+			}
+			if group.Exclusive {
+				return checkExclusiveGroup(ctx, tx, userId, group.ID)
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteTag takes a tag off every album and deletes it. Its subtags move up
+// to the tag it sat under. A tag a smart shelf filters on can't be deleted.
+func (s *Service) DeleteTag(ctx context.Context, userId, tagId string) error {
+	return s.db.WithTx(func(tx *db.DB) error {
+		tag, err := GetTag(ctx, tx, userId, tagId)
+		if err != nil {
+			return err
+		}
+		term, err := tagTerm(ctx, tx, userId, tag)
+		if err != nil {
+			return err
+		}
+		err = checkShelfFilters(ctx, tx, userId, tagId, term)
+		if err != nil {
+			return err
+		}
+		err = tx.Queries().ReparentTags(ctx, sqlc.ReparentTagsParams{
+			ToParentID:   tag.ParentID,
+			UserID:       userId,
+			FromParentID: sql.NullString{String: tagId, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to move subtags: %w", err)
+		}
+		return deleteTag(ctx, tx, userId, tagId)
+	})
+}
+
+func deleteTag(ctx context.Context, tx *db.DB, userId, tagId string) error {
+	err := tx.Queries().DeleteAlbumTagsByTagId(ctx, sqlc.DeleteAlbumTagsByTagIdParams{
+		UserID: userId,
+		TagID:  tagId,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete album tags: %w", err)
+	}
+	err = tx.Queries().DeleteTag(ctx, sqlc.DeleteTagParams{
+		ID:     tagId,
+		UserID: userId,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+	return nil
+}
+
+// checkTagParentInTx refuses a parent that isn't one of the user's tags, or
+// that would put the tag under itself.
+func checkTagParentInTx(ctx context.Context, tx *db.DB, userId, tagId, parentId string) error {
+	rows, err := tx.Queries().GetTagsByUserId(ctx, userId)
+	if err != nil {
+		return fmt.Errorf("failed to get user tags: %w", err)
+	}
+	parents := make(map[string]string, len(rows))
+	for _, row := range rows {
+		parents[row.Tag.ID] = row.Tag.ParentID.String
+	}
+	if _, ok := parents[parentId]; !ok {
+		return fmt.Errorf("%w: the tag to put it under doesn't exist", ErrInvalidTag)
+	}
+	return checkTagParent(parents, tagId, parentId)
+}
+
+func getTagGroup(ctx context.Context, tx *db.DB, userId, groupId string) (sqlc.TagGroup, error) {
+	group, err := tx.Queries().GetTagGroup(ctx, sqlc.GetTagGroupParams{
+		ID:     groupId,
+		UserID: userId,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return sqlc.TagGroup{}, ErrTagGroupNotFound
+	} else if err != nil {
+		return sqlc.TagGroup{}, fmt.Errorf("failed to get tag group: %w", err)
+	}
+	return group, nil
+}
+
+// checkTagGroupName refuses a group name another of the user's groups has,
+// ignoring case.
+func checkTagGroupName(ctx context.Context, tx *db.DB, userId, groupId, name string) error {
+	existing, err := tx.Queries().GetTagGroupByName(ctx, sqlc.GetTagGroupByNameParams{
+		UserID: userId,
+		Name:   name,
+	})
+	if err == nil && existing.ID != groupId {
+		return ErrTagGroupNameTaken
+	} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to get tag group: %w", err)
+	}
+	return nil
+}
+
+// checkExclusiveGroup refuses to make a group exclusive while albums carry
+// more than one of its tags.
+func checkExclusiveGroup(ctx context.Context, tx *db.DB, userId, groupId string) error {
+	count, err := tx.Queries().CountAlbumsWithManyTagsInGroup(ctx, sqlc.CountAlbumsWithManyTagsInGroupParams{
+		UserID:  userId,
+		GroupID: sql.NullString{String: groupId, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to count albums in tag group: %w", err)
+	}
+	if count > 0 {
+		return ErrExclusiveGroupInUse
+	}
+	return nil
+}
 
-			result = append(result, newTagDTOFromRow(tag, groupIDValue, groupName))
+// CreateTagGroup adds a tag group after the user's others.
+func (s *Service) CreateTagGroup(ctx context.Context, userId string, input TagGroupInput) (*TagGroupDTO, error) {
+	input = input.Normalize()
+	err := input.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTagGroup, err)
+	}
+
+	var group *TagGroupDTO
+	err = s.db.WithTx(func(tx *db.DB) error {
+		err := checkTagGroupName(ctx, tx, userId, "", input.Name)
+		if err != nil {
+			return err
+		}
+
+		groups, err := tx.Queries().GetTagGroupsByUserId(ctx, userId)
+		if err != nil {
+			return fmt.Errorf("failed to get tag groups: %w", err)
+		}
+		position := int64(0)
+		if len(groups) > 0 {
+			position = groups[len(groups)-1].Position + 1
 		}
 
+		model, err := tx.Queries().CreateTagGroup(ctx, sqlc.CreateTagGroupParams{
+			ID:        uuid.NewString(),
+			UserID:    userId,
+			Name:      input.Name,
+			Position:  position,
+			Color:     input.Color,
+			Exclusive: input.Exclusive,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create tag group: %w", err)
+		}
+		group = newTagGroupDTOFromModel(model)
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// UpdateTagGroup renames and recolors a tag group, and sets whether albums
+// can carry more than one of its tags. A group can only be made exclusive
+// while no album carries more than one of its tags. Smart shelves' tag
+// queries naming the group follow a rename.
+func (s *Service) UpdateTagGroup(ctx context.Context, userId, groupId string, input TagGroupInput) (*TagGroupDTO, error) {
+	input = input.Normalize()
+	err := input.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTagGroup, err)
+	}
+
+	var group *TagGroupDTO
+	err = s.db.WithTx(func(tx *db.DB) error {
+		existing, err := getTagGroup(ctx, tx, userId, groupId)
+		if err != nil {
+			return err
+		}
+		err = checkTagGroupName(ctx, tx, userId, groupId, input.Name)
+		if err != nil {
+			return err
+		}
+		if input.Exclusive && !existing.Exclusive {
+			err = checkExclusiveGroup(ctx, tx, userId, groupId)
+			if err != nil {
+				return err
+			}
+		}
 
+		model, err := tx.Queries().UpdateTagGroup(ctx, sqlc.UpdateTagGroupParams{
+			Name:      input.Name,
+			Color:     input.Color,
+			Exclusive: input.Exclusive,
+			ID:        groupId,
+			UserID:    userId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update tag group: %w", err)
+		}
+		err = renameShelfQueryGroup(ctx, tx, userId, existing.Name, model.Name)
+		if err != nil {
+			return err
+		}
+		group = newTagGroupDTOFromModel(model)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	return result, nil
+	return group, nil
+}
+
+// MoveTagGroup moves a tag group up (negative offset) or down the user's
+// groups. Moving past either end leaves the order as it is.
+func (s *Service) MoveTagGroup(ctx context.Context, userId, groupId string, offset int) error {
+	return s.db.WithTx(func(tx *db.DB) error {
+		groupModels, err := tx.Queries().GetTagGroupsByUserId(ctx, userId)
+		if err != nil {
+			return fmt.Errorf("failed to get tag groups: %w", err)
+		}
+		groups := make([]*TagGroupDTO, 0, len(groupModels))
+		index := -1
+		for i, model := range groupModels {
+			groups = append(groups, newTagGroupDTOFromModel(model))
+			if model.ID == groupId {
+				index = i
+			}
+		}
+		if index == -1 {
+			return ErrTagGroupNotFound
+		}
+
+		moved := moveGroup(groups, index, offset)
+		for position, group := range moved {
+			if group.Position == position {
+				continue
+			}
+			err := tx.Queries().SetTagGroupPosition(ctx, sqlc.SetTagGroupPositionParams{
+				Position: int64(position),
+				ID:       group.ID,
+				UserID:   userId,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to move tag group: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteTagGroup deletes a tag group. Its tags stay on their albums,
+// ungrouped.
+func (s *Service) DeleteTagGroup(ctx context.Context, userId, groupId string) error {
+	return s.db.WithTx(func(tx *db.DB) error {
+		_, err := getTagGroup(ctx, tx, userId, groupId)
+		if err != nil {
+			return err
+		}
+		err = tx.Queries().UngroupTagsByGroupId(ctx, sqlc.UngroupTagsByGroupIdParams{
+			UserID:  userId,
+			GroupID: sql.NullString{String: groupId, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to ungroup tags: %w", err)
+		}
+		err = tx.Queries().DeleteTagGroup(ctx, sqlc.DeleteTagGroupParams{
+			ID:     groupId,
+			UserID: userId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete tag group: %w", err)
+		}
+		return nil
+	})
 }
@@ -0,0 +1,171 @@
+package tags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/alecdray/wax/src/internal/core/db"
+	"github.com/alecdray/wax/src/internal/core/db/dbtest"
+)
+
+func newTestService(t *testing.T) (*Service, *db.DB) {
+	database := dbtest.New(t)
+	return NewService(database), database
+}
+
+// seedAlbumTags tags new albums for u1, creating the tags, and returns the
+// tags by name.
+func seedAlbumTags(t *testing.T, service *Service, database *db.DB, albumTags map[string][]TagInput) map[string]TagDTO {
+	t.Helper()
+	ctx := context.Background()
+	byName := make(map[string]TagDTO)
+	for albumId, inputs := range albumTags {
+		dbtest.Exec(t, database, "INSERT INTO albums (id, spotify_id, title) VALUES (?, ?, ?)", albumId, albumId, albumId)
+		tags, err := service.SetAlbumTags(ctx, "u1", albumId, inputs)
+		if err != nil {
+			t.Fatalf("failed to tag %s: %v", albumId, err)
+		}
+		for _, tag := range tags {
+			byName[tag.Name] = tag
+		}
+	}
+	return byName
+}
+
+func shelfFilterFields(t *testing.T, database *db.DB, shelfId string) map[string]any {
+	t.Helper()
+	var filter string
+	if err := database.Sql().QueryRow("SELECT filter FROM shelves WHERE id = ?", shelfId).Scan(&filter); err != nil {
+		t.Fatalf("failed to get shelf filter: %v", err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(filter), &fields); err != nil {
+		t.Fatalf("failed to decode shelf filter %q: %v", filter, err)
+	}
+	return fields
+}
+
+func TestMergeTags_SmartShelvesFollowTheMergedTag(t *testing.T) {
+	service, database := newTestService(t)
+	ctx := context.Background()
+	dbtest.Exec(t, database, "INSERT INTO users (id, spotify_id) VALUES ('u1', 'u1')")
+	tags := seedAlbumTags(t, service, database, map[string][]TagInput{
+		"a1": {{Name: "jazz"}},
+		"a2": {{Name: "bebop"}, {Name: "swing"}},
+	})
+	jazz, bebop, swing := tags["jazz"].ID, tags["bebop"].ID, tags["swing"].ID
+
+	dbtest.Exec(t, database, "INSERT INTO shelves (id, user_id, name, filter) VALUES ('s1', 'u1', 'Jazz', ?), ('s2', 'u1', 'Swing', ?)",
+		`{"minRating":8,"tagIds":["`+bebop+`","`+jazz+`"]}`, `{"tagIds":["`+swing+`"]}`)
+
+	if err := service.MergeTags(ctx, "u1", bebop, jazz); err != nil {
+		t.Fatalf("failed to merge tags: %v", err)
+	}
+	fields := shelfFilterFields(t, database, "s1")
+	if tagIds, _ := fields["tagIds"].([]any); len(tagIds) != 1 || tagIds[0] != jazz || fields["minRating"] != 8.0 {
+		t.Errorf("expected the shelf to follow jazz once and keep its rating filter, got %v", fields)
+	}
+	if tagIds, _ := shelfFilterFields(t, database, "s2")["tagIds"].([]any); len(tagIds) != 1 || tagIds[0] != swing {
+		t.Errorf("expected the other shelf left alone, got %v", tagIds)
+	}
+
+	for _, tagId := range []string{jazz, swing} {
+		if err := service.DeleteTag(ctx, "u1", tagId); !errors.Is(err, ErrTagOnSmartShelf) {
+			t.Errorf("expected deleting a tag a shelf follows to be refused, got %v", err)
+		}
+	}
+	dbtest.Exec(t, database, "DELETE FROM shelves WHERE id = 's2'")
+	if err := service.DeleteTag(ctx, "u1", swing); err != nil {
+		t.Errorf("expected a tag no shelf follows to be deleted, got %v", err)
+	}
+
+	userTags, err := service.GetUserTags(ctx, "u1")
+	if err != nil {
+		t.Fatalf("failed to get tags: %v", err)
+	}
+	if !slices.ContainsFunc(userTags, func(tag TagDTO) bool { return tag.ID == jazz }) || len(userTags) != 1 {
+		t.Errorf("expected only jazz left, got %+v", userTags)
+	}
+}
+
+func TestMergeTags_KeepsExclusiveGroupsToOneTagPerAlbum(t *testing.T) {
+	service, database := newTestService(t)
+	ctx := context.Background()
+	dbtest.Exec(t, database, "INSERT INTO users (id, spotify_id) VALUES ('u1', 'u1')")
+	era, err := service.CreateTagGroup(ctx, "u1", TagGroupInput{Name: "era", Exclusive: true})
+	if err != nil {
+		t.Fatalf("failed to create group: %v", err)
+	}
+
+	tags := make(map[string]TagDTO)
+	tagAlbum := func(albumId string, inputs ...TagInput) {
+		t.Helper()
+		dbtest.Exec(t, database, "INSERT INTO albums (id, spotify_id, title) VALUES (?, ?, ?)", albumId, albumId, albumId)
+		albumTags, err := service.SetAlbumTags(ctx, "u1", albumId, inputs)
+		if err != nil {
+			t.Fatalf("failed to tag %s: %v", albumId, err)
+		}
+		for _, tag := range albumTags {
+			tags[tag.Name] = tag
+		}
+	}
+	tagAlbum("a1", TagInput{Name: "1970s"}, TagInput{Name: "80s", GroupID: era.ID})
+	tagAlbum("a2", TagInput{Name: "70s", GroupID: era.ID})
+
+	err = service.MergeTags(ctx, "u1", tags["1970s"].ID, tags["70s"].ID)
+	if !errors.Is(err, ErrExclusiveGroupInUse) {
+		t.Fatalf("expected a merge giving a1 two era tags to be refused, got %v", err)
+	}
+	albumTags, err := service.GetAlbumTags(ctx, "u1", "a1")
+	if err != nil {
+		t.Fatalf("failed to get album tags: %v", err)
+	}
+	if len(albumTags) != 2 || !slices.ContainsFunc(albumTags, func(tag TagDTO) bool { return tag.Name == "1970s" }) {
+		t.Errorf("expected the refused merge rolled back, got %+v", albumTags)
+	}
+
+	// Once a1 loses its other era tag, the merge goes through.
+	if _, err := service.SetAlbumTags(ctx, "u1", "a1", []TagInput{{Name: "1970s"}}); err != nil {
+		t.Fatalf("failed to retag a1: %v", err)
+	}
+	if err := service.MergeTags(ctx, "u1", tags["1970s"].ID, tags["70s"].ID); err != nil {
+		t.Fatalf("failed to merge tags: %v", err)
+	}
+	albumTags, err = service.GetAlbumTags(ctx, "u1", "a1")
+	if err != nil || len(albumTags) != 1 || albumTags[0].ID != tags["70s"].ID {
+		t.Errorf("expected a1 tagged 70s, got %+v, %v", albumTags, err)
+	}
+}
+
+func TestUpdateTag_SmartShelfQueriesFollowRenames(t *testing.T) {
+	service, database := newTestService(t)
+	ctx := context.Background()
+	dbtest.Exec(t, database, "INSERT INTO users (id, spotify_id) VALUES ('u1', 'u1')")
+	mood, err := service.CreateTagGroup(ctx, "u1", TagGroupInput{Name: "mood"})
+	if err != nil {
+		t.Fatalf("failed to create group: %v", err)
+	}
+	tags := seedAlbumTags(t, service, database, map[string][]TagInput{
+		"a1": {{Name: "late-night", GroupID: mood.ID}, {Name: "ambient"}},
+	})
+	dbtest.Exec(t, database, "INSERT INTO shelves (id, user_id, name, filter) VALUES ('s1', 'u1', 'Night', ?)",
+		`{"rated":"only","tagQuery":"mood:late-night AND NOT ambient"}`)
+
+	if _, err := service.UpdateTag(ctx, "u1", tags["late-night"].ID, TagUpdate{Name: "after-hours", GroupID: mood.ID}); err != nil {
+		t.Fatalf("failed to rename tag: %v", err)
+	}
+	if _, err := service.UpdateTagGroup(ctx, "u1", mood.ID, TagGroupInput{Name: "Vibe"}); err != nil {
+		t.Fatalf("failed to rename group: %v", err)
+	}
+	fields := shelfFilterFields(t, database, "s1")
+	if fields["tagQuery"] != "Vibe:after-hours AND NOT ambient" || fields["rated"] != "only" {
+		t.Errorf("expected the saved query to follow the renames, got %v", fields)
+	}
+
+	if err := service.DeleteTag(ctx, "u1", tags["ambient"].ID); !errors.Is(err, ErrTagOnSmartShelf) {
+		t.Errorf("expected deleting a tag the query names to be refused, got %v", err)
+	}
+}
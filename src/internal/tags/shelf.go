@@ -0,0 +1,190 @@
+package tags
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/alecdray/wax/src/internal/core/db"
+	"github.com/alecdray/wax/src/internal/core/db/sqlc"
+)
+
+// ErrTagOnSmartShelf is returned when deleting a tag a smart shelf filters
+// on, which would quietly empty the shelf.
+var ErrTagOnSmartShelf = errors.New("a smart shelf filters on this tag")
+
+// The fields of a smart shelf's saved filter that hold the tags it follows,
+// by id and as a tag query naming them. The library saves the filter as JSON;
+// tags only rewrite the fields that name them and keep the rest as they are.
+const (
+	shelfFilterTagIDs   = "tagIds"
+	shelfFilterTagQuery = "tagQuery"
+)
+
+// shelfFilter is a smart shelf's saved filter, with the tags it follows read
+// out.
+type shelfFilter struct {
+	shelf    sqlc.Shelf
+	fields   map[string]json.RawMessage
+	TagIDs   []string
+	TagQuery *Query
+}
+
+// getShelfFilters returns the user's smart shelves' filters. A filter that
+// can't be read matches nothing already, so it's skipped.
+func getShelfFilters(ctx context.Context, tx *db.DB, userId string) ([]shelfFilter, error) {
+	shelves, err := tx.Queries().GetShelvesByUserId(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shelves: %w", err)
+	}
+	var filters []shelfFilter
+	for _, shelf := range shelves {
+		if shelf.Filter.String == "" {
+			continue
+		}
+		filter := shelfFilter{shelf: shelf}
+		if json.Unmarshal([]byte(shelf.Filter.String), &filter.fields) != nil {
+			continue
+		}
+		if raw, ok := filter.fields[shelfFilterTagIDs]; ok && json.Unmarshal(raw, &filter.TagIDs) != nil {
+			continue
+		}
+		if raw, ok := filter.fields[shelfFilterTagQuery]; ok && json.Unmarshal(raw, &filter.TagQuery) != nil {
+			continue
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+func (f shelfFilter) save(ctx context.Context, tx *db.DB, userId string) error {
+	delete(f.fields, shelfFilterTagIDs)
+	if len(f.TagIDs) > 0 {
+		raw, err := json.Marshal(f.TagIDs)
+		if err != nil {
+			return fmt.Errorf("failed to encode shelf filter: %w", err)
+		}
+		f.fields[shelfFilterTagIDs] = raw
+	}
+	delete(f.fields, shelfFilterTagQuery)
+	if f.TagQuery != nil {
+		raw, err := json.Marshal(f.TagQuery)
+		if err != nil {
+			return fmt.Errorf("failed to encode shelf filter: %w", err)
+		}
+		f.fields[shelfFilterTagQuery] = raw
+	}
+	data, err := json.Marshal(f.fields)
+	if err != nil {
+		return fmt.Errorf("failed to encode shelf filter: %w", err)
+	}
+	err = tx.Queries().UpdateShelfFilter(ctx, sqlc.UpdateShelfFilterParams{
+		Filter: sql.NullString{String: string(data), Valid: true},
+		ID:     f.shelf.ID,
+		UserID: userId,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update shelf filter: %w", err)
+	}
+	return nil
+}
+
+// moveShelfFilters points the smart shelves that follow one tag at another.
+func moveShelfFilters(ctx context.Context, tx *db.DB, userId, fromTagId, intoTagId string) error {
+	filters, err := getShelfFilters(ctx, tx, userId)
+	if err != nil {
+		return err
+	}
+	for _, filter := range filters {
+		if !slices.Contains(filter.TagIDs, fromTagId) {
+			continue
+		}
+		tagIds := make([]string, 0, len(filter.TagIDs))
+		for _, tagId := range filter.TagIDs {
+			if tagId == fromTagId {
+				tagId = intoTagId
+			}
+			if !slices.Contains(tagIds, tagId) {
+				tagIds = append(tagIds, tagId)
+			}
+		}
+		filter.TagIDs = tagIds
+		err := filter.save(ctx, tx, userId)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renameShelfQueryTag points the smart shelves' tag queries naming a tag at
+// what it's now called. from and to are the tag's name, in its group if it
+// has one.
+func renameShelfQueryTag(ctx context.Context, tx *db.DB, userId string, from, to queryTerm) error {
+	if from == to {
+		return nil
+	}
+	return rewriteShelfQueries(ctx, tx, userId, func(query *Query) bool {
+		return query.renameTag(from, to)
+	})
+}
+
+// renameShelfQueryGroup points the smart shelves' tag queries naming a group
+// at its new name.
+func renameShelfQueryGroup(ctx context.Context, tx *db.DB, userId, from, to string) error {
+	if from == to {
+		return nil
+	}
+	return rewriteShelfQueries(ctx, tx, userId, func(query *Query) bool {
+		return query.renameGroup(from, to)
+	})
+}
+
+func rewriteShelfQueries(ctx context.Context, tx *db.DB, userId string, rewrite func(*Query) bool) error {
+	filters, err := getShelfFilters(ctx, tx, userId)
+	if err != nil {
+		return err
+	}
+	for _, filter := range filters {
+		if filter.TagQuery == nil || !rewrite(filter.TagQuery) {
+			continue
+		}
+		err := filter.save(ctx, tx, userId)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkShelfFilters refuses to delete a tag while a smart shelf filters on
+// it, by id or in its tag query.
+func checkShelfFilters(ctx context.Context, tx *db.DB, userId, tagId string, term queryTerm) error {
+	filters, err := getShelfFilters(ctx, tx, userId)
+	if err != nil {
+		return err
+	}
+	for _, filter := range filters {
+		if slices.Contains(filter.TagIDs, tagId) || (filter.TagQuery != nil && filter.TagQuery.namesTag(term)) {
+			return fmt.Errorf("%w: delete %q first, or merge the tag into another", ErrTagOnSmartShelf, filter.shelf.Name)
+		}
+	}
+	return nil
+}
+
+// tagTerm is the query term naming a tag: its name, in its group if it has
+// one.
+func tagTerm(ctx context.Context, tx *db.DB, userId string, tag sqlc.Tag) (queryTerm, error) {
+	term := queryTerm{tag: tag.Name}
+	if tag.GroupID.Valid {
+		group, err := getTagGroup(ctx, tx, userId, tag.GroupID.String)
+		if err != nil {
+			return queryTerm{}, err
+		}
+		term.group = group.Name
+	}
+	return term, nil
+}
@@ -0,0 +1,191 @@
+package tags
+
+import (
+	"context"
+	"fmt"
+	"github.com/alecdray/wax/src/internal/core/db/sqlc"
+	"sort"
+)
+
+const suggestionLimit = 8
+
+// SuggestionSource is where a tag suggestion came from.
+type SuggestionSource string
+
+const (
+	// SuggestionSourceArtist is the user's tags on other albums by the same
+	// artists.
+	SuggestionSourceArtist SuggestionSource = "artist"
+	// SuggestionSourceCooccurrence is the user's tags that often sit
+	// alongside the album's own.
+	SuggestionSourceCooccurrence SuggestionSource = "cooccurrence"
+	// SuggestionSourceMusicBrainz is the genres and tags MusicBrainz has for
+	// the album.
+	SuggestionSourceMusicBrainz SuggestionSource = "musicbrainz"
+)
+
+// suggestionWeights ranks the sources: the user's own habits say more about
+// how they'd tag an album than anyone else's.
+var suggestionWeights = map[SuggestionSource]float64{
+	SuggestionSourceArtist:       1,
+	SuggestionSourceCooccurrence: 0.75,
+	SuggestionSourceMusicBrainz:  0.5,
+}
+
+// knownTagBonus lifts names the user already tags with above new ones.
+const knownTagBonus = 0.25
+
+// ExternalTag is a tag found for an album outside the user's library, such
+// as a MusicBrainz genre, with how many people applied it.
+type ExternalTag struct {
+	Name  string
+	Count int
+}
+
+// TagSuggestion is a tag proposed for an album: one of the user's tags, or a
+// new name when Tag.ID is empty.
+type TagSuggestion struct {
+	Tag     TagDTO
+	Score   float64
+	Sources []SuggestionSource
+}
+
+// SuggestTags proposes tags for an album, best first, from the user's tags on
+// other albums by the same artists, the tags they often pair with the
+// album's own, and outside tags such as MusicBrainz genres. Outside names are
+// normalized like any tag, so a genre the user already has suggests their
+// tag. Tags already on the album aren't suggested.
+func (s *Service) SuggestTags(ctx context.Context, userId, albumId string, external []ExternalTag) ([]TagSuggestion, error) {
+	userTags, err := s.GetUserTags(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	albumTags, err := s.GetAlbumTags(ctx, userId, albumId)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(userTags))
+	for _, tag := range userTags {
+		names[tag.ID] = tag.Name
+	}
+
+	artistRows, err := s.db.Queries().GetArtistTagCounts(ctx, sqlc.GetArtistTagCountsParams{
+		UserID:    userId,
+		AlbumID:   albumId,
+		AlbumID_2: albumId,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get artist tag counts: %w", err)
+	}
+	artistCounts := make(map[string]int, len(artistRows))
+	for _, row := range artistRows {
+		artistCounts[names[row.TagID]] = int(row.AlbumCount)
+	}
+
+	cooccurringRows, err := s.db.Queries().GetCooccurringTagCounts(ctx, sqlc.GetCooccurringTagCountsParams{
+		UserID:    userId,
+		AlbumID:   albumId,
+		UserID_2:  userId,
+		AlbumID_2: albumId,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get co-occurring tag counts: %w", err)
+	}
+	cooccurringCounts := make(map[string]int, len(cooccurringRows))
+	for _, row := range cooccurringRows {
+		cooccurringCounts[names[row.TagID]] = int(row.AlbumCount)
+	}
+
+	externalCounts := make(map[string]int, len(external))
+	for _, tag := range external {
+		externalCounts[tag.Name] = max(externalCounts[tag.Name], tag.Count)
+	}
+
+	var signals []tagSignal
+	signals = append(signals, newTagSignals(SuggestionSourceArtist, artistCounts)...)
+	signals = append(signals, newTagSignals(SuggestionSourceCooccurrence, cooccurringCounts)...)
+	signals = append(signals, newTagSignals(SuggestionSourceMusicBrainz, externalCounts)...)
+	return rankTagSuggestions(userTags, albumTags, signals, suggestionLimit), nil
+}
+
+// tagSignal is one source's vote for a tag name, from 0 to 1.
+type tagSignal struct {
+	source   SuggestionSource
+	name     string
+	strength float64
+}
+
+// newTagSignals normalizes the names and scales the counts against the
+// largest, so each source's strongest signal has a strength of 1.
+func newTagSignals(source SuggestionSource, counts map[string]int) []tagSignal {
+	normalized := make(map[string]int, len(counts))
+	most := 0
+	for name, count := range counts {
+		name = normalizeTag(name)
+		if name == "" {
+			continue
+		}
+		normalized[name] = max(normalized[name], count)
+		most = max(most, count)
+	}
+
+	signals := make([]tagSignal, 0, len(normalized))
+	for name, count := range normalized {
+		strength := 1.0
+		if most > 0 {
+			strength = float64(count) / float64(most)
+		}
+		signals = append(signals, tagSignal{source: source, name: name, strength: strength})
+	}
+	return signals
+}
+
+// rankTagSuggestions adds up the signals for each name, weighted by source,
+// and returns the best-scoring names not already on the album, mapped onto
+// the user's tags where they have one by that name.
+func rankTagSuggestions(userTags, albumTags []TagDTO, signals []tagSignal, limit int) []TagSuggestion {
+	known := make(map[string]TagDTO, len(userTags))
+	for _, tag := range userTags {
+		known[tag.Name] = tag
+	}
+	onAlbum := make(map[string]bool, len(albumTags))
+	for _, tag := range albumTags {
+		onAlbum[tag.Name] = true
+	}
+
+	byName := make(map[string]*TagSuggestion)
+	for _, signal := range signals {
+		if onAlbum[signal.name] {
+			continue
+		}
+		suggestion, ok := byName[signal.name]
+		if !ok {
+			suggestion = &TagSuggestion{Tag: TagDTO{Name: signal.name}}
+			if tag, isKnown := known[signal.name]; isKnown {
+				suggestion.Tag = tag
+				suggestion.Score = knownTagBonus
+			}
+			byName[signal.name] = suggestion
+		}
+		suggestion.Score += suggestionWeights[signal.source] * signal.strength
+		suggestion.Sources = append(suggestion.Sources, signal.source)
+	}
+
+	suggestions := make([]TagSuggestion, 0, len(byName))
+	for _, suggestion := range byName {
+		sort.Slice(suggestion.Sources, func(i, j int) bool {
+			return suggestionWeights[suggestion.Sources[i]] > suggestionWeights[suggestion.Sources[j]]
+		})
+		suggestions = append(suggestions, *suggestion)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].Tag.Name < suggestions[j].Tag.Name
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
@@ -0,0 +1,124 @@
+package tags
+
+import (
+	"testing"
+)
+
+func suggestionNames(suggestions []TagSuggestion) []string {
+	names := make([]string, len(suggestions))
+	for i, suggestion := range suggestions {
+		names[i] = suggestion.Tag.Name
+	}
+	return names
+}
+
+func TestNewTagSignals(t *testing.T) {
+	signals := newTagSignals(SuggestionSourceMusicBrainz, map[string]int{
+		"Shoegaze":   4,
+		"Dream Pop":  2,
+		"!!":         9,
+		"Post-Punk!": 1,
+	})
+	want := map[string]float64{"shoegaze": 1, "dream pop": 0.5, "post-punk": 0.25}
+	if len(signals) != len(want) {
+		t.Fatalf("expected %d signals, got %d: %v", len(want), len(signals), signals)
+	}
+	for _, signal := range signals {
+		if want[signal.name] != signal.strength {
+			t.Errorf("expected %q to have strength %v, got %v", signal.name, want[signal.name], signal.strength)
+		}
+	}
+}
+
+func TestRankTagSuggestions(t *testing.T) {
+	userTags := []TagDTO{
+		{ID: "t1", Name: "shoegaze"},
+		{ID: "t2", Name: "late-night"},
+		{ID: "t3", Name: "vinyl rip"},
+	}
+	albumTags := []TagDTO{{ID: "t3", Name: "vinyl rip"}}
+
+	cases := []struct {
+		name    string
+		signals []tagSignal
+		limit   int
+		want    []string
+	}{
+		{
+			name: "the user's habits outrank outside tags",
+			signals: []tagSignal{
+				{source: SuggestionSourceMusicBrainz, name: "dream pop", strength: 1},
+				{source: SuggestionSourceArtist, name: "late-night", strength: 1},
+			},
+			limit: 8,
+			want:  []string{"late-night", "dream pop"},
+		},
+		{
+			name: "outside tags the user already has outrank new ones",
+			signals: []tagSignal{
+				{source: SuggestionSourceMusicBrainz, name: "dream pop", strength: 1},
+				{source: SuggestionSourceMusicBrainz, name: "shoegaze", strength: 0.75},
+			},
+			limit: 8,
+			want:  []string{"shoegaze", "dream pop"},
+		},
+		{
+			name: "sources add up",
+			signals: []tagSignal{
+				{source: SuggestionSourceArtist, name: "late-night", strength: 1},
+				{source: SuggestionSourceCooccurrence, name: "shoegaze", strength: 1},
+				{source: SuggestionSourceMusicBrainz, name: "shoegaze", strength: 1},
+			},
+			limit: 8,
+			want:  []string{"shoegaze", "late-night"},
+		},
+		{
+			name: "tags on the album are left out",
+			signals: []tagSignal{
+				{source: SuggestionSourceArtist, name: "vinyl rip", strength: 1},
+				{source: SuggestionSourceMusicBrainz, name: "dream pop", strength: 1},
+			},
+			limit: 8,
+			want:  []string{"dream pop"},
+		},
+		{
+			name: "ties go by name and the limit applies",
+			signals: []tagSignal{
+				{source: SuggestionSourceMusicBrainz, name: "noise pop", strength: 1},
+				{source: SuggestionSourceMusicBrainz, name: "dream pop", strength: 1},
+				{source: SuggestionSourceMusicBrainz, name: "indie", strength: 0.5},
+			},
+			limit: 2,
+			want:  []string{"dream pop", "noise pop"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := suggestionNames(rankTagSuggestions(userTags, albumTags, c.signals, c.limit))
+			if len(got) != len(c.want) {
+				t.Fatalf("expected %v, got %v", c.want, got)
+			}
+			for i := range c.want {
+				if got[i] != c.want[i] {
+					t.Fatalf("expected %v, got %v", c.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRankTagSuggestions_MapsOntoUserTags(t *testing.T) {
+	userTags := []TagDTO{{ID: "t1", Name: "shoegaze", Group: &TagGroupDTO{ID: "g1", Name: "Sound"}}}
+	signals := newTagSignals(SuggestionSourceMusicBrainz, map[string]int{"Shoegaze": 3, "noise pop": 1})
+
+	suggestions := rankTagSuggestions(userTags, nil, signals, 8)
+	if len(suggestions) != 2 {
+		t.Fatalf("expected 2 suggestions, got %v", suggestionNames(suggestions))
+	}
+	if suggestions[0].Tag.ID != "t1" || suggestions[0].Tag.Group == nil {
+		t.Errorf("expected the user's shoegaze tag, got %+v", suggestions[0].Tag)
+	}
+	if suggestions[1].Tag.ID != "" {
+		t.Errorf("expected noise pop to be a new tag, got %+v", suggestions[1].Tag)
+	}
+}
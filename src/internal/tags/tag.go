@@ -0,0 +1,108 @@
+package tags
+
+import (
+	"errors"
+	"fmt"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"slices"
+)
+
+var (
+	ErrInvalidTag       = errors.New("invalid tag")
+	ErrTagNotFound      = errors.New("tag not found")
+	ErrTagGroupNotFound = errors.New("tag group not found")
+	ErrTagNameTaken     = errors.New("another tag already has that name; merge them instead")
+)
+
+// Colors lists the colors a tag can be shown in, in display order.
+var Colors = []models.TagColor{
+	models.TagColorNone,
+	models.TagColorPrimary,
+	models.TagColorSecondary,
+	models.TagColorAccent,
+	models.TagColorInfo,
+	models.TagColorSuccess,
+	models.TagColorWarning,
+	models.TagColorError,
+}
+
+func ColorLabel(color models.TagColor) string {
+	switch color {
+	case models.TagColorNone:
+		return "Plain"
+	case models.TagColorPrimary:
+		return "Primary"
+	case models.TagColorSecondary:
+		return "Secondary"
+	case models.TagColorAccent:
+		return "Accent"
+	case models.TagColorInfo:
+		return "Blue"
+	case models.TagColorSuccess:
+		return "Green"
+	case models.TagColorWarning:
+		return "Amber"
+	case models.TagColorError:
+		return "Red"
+	default:
+		return string(color)
+	}
+}
+
+// ColorBadgeClass returns the badge classes that show a tag in its color.
+// The classes are spelled out so Tailwind picks them up.
+func ColorBadgeClass(color models.TagColor) string {
+	switch color {
+	case models.TagColorPrimary:
+		return "badge-soft badge-primary"
+	case models.TagColorSecondary:
+		return "badge-soft badge-secondary"
+	case models.TagColorAccent:
+		return "badge-soft badge-accent"
+	case models.TagColorInfo:
+		return "badge-soft badge-info"
+	case models.TagColorSuccess:
+		return "badge-soft badge-success"
+	case models.TagColorWarning:
+		return "badge-soft badge-warning"
+	case models.TagColorError:
+		return "badge-soft badge-error"
+	default:
+		return "badge-ghost"
+	}
+}
+
+// TagUsageDTO is a tag with the number of albums it's on.
+type TagUsageDTO struct {
+	Tag        TagDTO
+	AlbumCount int
+	// TotalAlbumCount counts the albums with the tag or any of its subtags.
+	TotalAlbumCount int
+}
+
+// TagUpdate renames, regroups, recolors and moves a tag.
+type TagUpdate struct {
+	Name     string
+	GroupID  string // empty = ungrouped
+	Color    models.TagColor
+	ParentID string // empty = top level
+}
+
+func (u TagUpdate) Normalize() TagUpdate {
+	u.Name = normalizeTag(u.Name)
+	if u.Color == "" {
+		u.Color = models.TagColorNone
+	}
+	return u
+}
+
+func (u TagUpdate) Validate() error {
+	var errs []error
+	if u.Name == "" {
+		errs = append(errs, errors.New("a name with at least one letter or number is required"))
+	}
+	if !slices.Contains(Colors, u.Color) {
+		errs = append(errs, fmt.Errorf("unknown color %q", u.Color))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,108 @@
+package tags
+
+import (
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"testing"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"lowercases", "Shoegaze", "shoegaze"},
+		{"trims", "  late night  ", "late night"},
+		{"keeps dashes and ampersands", "r&b / neo-soul", "r&b  neo-soul"},
+		{"drops punctuation", "90's!", "90s"},
+		{"nothing left", "!!", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := normalizeTag(c.in); got != c.want {
+				t.Errorf("expected %q, got %q", c.want, got)
+			}
+		})
+	}
+}
+
+func TestTagUpdate_Validate(t *testing.T) {
+	cases := []struct {
+		name   string
+		update TagUpdate
+		ok     bool
+	}{
+		{"name only", TagUpdate{Name: "shoegaze"}, true},
+		{"grouped and colored", TagUpdate{Name: "shoegaze", GroupID: "g1", Color: models.TagColorAccent}, true},
+		{"name normalizes away", TagUpdate{Name: "!!"}, false},
+		{"unknown color", TagUpdate{Name: "shoegaze", Color: "mauve"}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.update.Normalize().Validate()
+			if c.ok && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !c.ok && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestColorBadgeClass(t *testing.T) {
+	seen := make(map[string]models.TagColor)
+	for _, color := range Colors {
+		class := ColorBadgeClass(color)
+		if other, ok := seen[class]; ok {
+			t.Errorf("colors %q and %q share badge class %q", other, color, class)
+		}
+		seen[class] = color
+	}
+}
+
+func TestTagDTO_BadgeColor(t *testing.T) {
+	era := &TagGroupDTO{ID: "g1", Name: "Era", Color: models.TagColorAccent}
+	cases := []struct {
+		name string
+		tag  TagDTO
+		want models.TagColor
+	}{
+		{"own color", TagDTO{Color: models.TagColorError, Group: era}, models.TagColorError},
+		{"group color", TagDTO{Color: models.TagColorNone, Group: era}, models.TagColorAccent},
+		{"ungrouped", TagDTO{Color: models.TagColorNone}, models.TagColorNone},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.tag.BadgeColor(); got != c.want {
+				t.Errorf("expected %q, got %q", c.want, got)
+			}
+		})
+	}
+}
+
+func TestCheckExclusiveGroups(t *testing.T) {
+	era := &TagGroupDTO{ID: "era", Name: "Era", Exclusive: true}
+	mood := &TagGroupDTO{ID: "mood", Name: "Mood"}
+	cases := []struct {
+		name string
+		tags []TagDTO
+		ok   bool
+	}{
+		{"one from an exclusive group", []TagDTO{{Name: "90s", Group: era}, {Name: "dreamy", Group: mood}}, true},
+		{"many from an open group", []TagDTO{{Name: "dreamy", Group: mood}, {Name: "hazy", Group: mood}}, true},
+		{"ungrouped", []TagDTO{{Name: "loose"}, {Name: "live"}}, true},
+		{"two from an exclusive group", []TagDTO{{Name: "90s", Group: era}, {Name: "80s", Group: era}}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := checkExclusiveGroups(c.tags)
+			if c.ok && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !c.ok && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
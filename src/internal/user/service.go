@@ -9,13 +9,18 @@ import (
 	"github.com/alecdray/wax/src/internal/core/db"
 	"github.com/alecdray/wax/src/internal/core/db/sqlc"
 	"github.com/alecdray/wax/src/internal/core/sqlx"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+var ErrInvalidTimezone = errors.New("unknown timezone")
+
 type UserDTO struct {
-	ID                  string
-	SpotifyID           string
+	ID        string
+	SpotifyID string
+	// Timezone is the IANA name of the user's timezone.
+	Timezone            string
 	spotifyRefreshToken *string
 }
 
@@ -23,6 +28,7 @@ func NewUserDTOFromModel(model sqlc.User) *UserDTO {
 	user := &UserDTO{
 		ID:        model.ID,
 		SpotifyID: model.SpotifyID,
+		Timezone:  model.Timezone,
 	}
 
 	if model.SpotifyRefreshToken.Valid {
@@ -45,6 +51,28 @@ func (u *UserDTO) SpotifyRefreshToken(secret string) *string {
 	return &decrypted
 }
 
+// Location is the user's timezone, or UTC if it's no longer known.
+func (u *UserDTO) Location() *time.Location {
+	location, err := LoadTimezone(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// LoadTimezone loads an IANA timezone by name. Unlike time.LoadLocation, it
+// doesn't take an empty name or "Local" to mean the server's timezone.
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimezone, name)
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimezone, name)
+	}
+	return location, nil
+}
+
 type Service struct {
 	db *db.DB
 }
@@ -95,6 +123,21 @@ func (s *Service) UpsertSpotifyUser(ctx contextx.ContextX, spotifyId string, spo
 	return NewUserDTOFromModel(user), nil
 }
 
+// SetTimezone sets the timezone the user's days and hours are counted in.
+func (s *Service) SetTimezone(ctx context.Context, userId string, timezone string) error {
+	if _, err := LoadTimezone(timezone); err != nil {
+		return err
+	}
+	err := s.db.Queries().SetUserTimezone(ctx, sqlc.SetUserTimezoneParams{
+		Timezone: timezone,
+		ID:       userId,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set timezone: %w", err)
+	}
+	return nil
+}
+
 func (s *Service) GetUserFromCtx(ctx contextx.ContextX) (*UserDTO, error) {
 	userId, err := ctx.UserId()
 	if errors.Is(err, contextx.ErrEmptyValue) {
@@ -0,0 +1,366 @@
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"github.com/alecdray/wax/src/internal/core/contextx"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"github.com/alecdray/wax/src/internal/core/httpx"
+	"github.com/alecdray/wax/src/internal/core/templates"
+	libraryAdapters "github.com/alecdray/wax/src/internal/library/adapters"
+	"github.com/alecdray/wax/src/internal/musicbrainz"
+	"github.com/alecdray/wax/src/internal/wishlist"
+	"strconv"
+	"strings"
+)
+
+const searchResultsLimit = 10
+
+type HttpHandler struct {
+	mb              *musicbrainz.Service
+	wishlistService *wishlist.Service
+}
+
+func NewHttpHandler(mb *musicbrainz.Service, wishlistService *wishlist.Service) *HttpHandler {
+	return &HttpHandler{
+		mb:              mb,
+		wishlistService: wishlistService,
+	}
+}
+
+func handleWishlistError(ctx contextx.ContextX, w http.ResponseWriter, err error) {
+	props := httpx.HandleErrorResponseProps{
+		Status: http.StatusInternalServerError,
+		Err:    err,
+	}
+	switch {
+	case errors.Is(err, wishlist.ErrItemNotFound), errors.Is(err, wishlist.ErrAlbumNotFound):
+		props.Status = http.StatusNotFound
+	case errors.Is(err, wishlist.ErrInvalidItem):
+		props.Status = http.StatusUnprocessableEntity
+		props.Response = *httpx.NewErrorResponse().SetComponent(WishlistError(err.Error()))
+	case errors.Is(err, wishlist.ErrAlreadyWishlisted):
+		props.Status = http.StatusConflict
+		props.Response = *httpx.NewErrorResponse().SetComponent(WishlistError(err.Error()))
+	}
+	httpx.HandleErrorResponse(ctx, w, props)
+}
+
+// parseItemDetails reads the format, priority, max price, notes and where the
+// user heard about an album from a submitted form.
+func parseItemDetails(form url.Values) (wishlist.ItemDetails, error) {
+	details := wishlist.ItemDetails{
+		Format:    models.WishlistFormat(form.Get("format")),
+		Notes:     form.Get("notes"),
+		HeardFrom: form.Get("heardFrom"),
+	}
+	if value := form.Get("priority"); value != "" {
+		priority, err := strconv.Atoi(value)
+		if err != nil {
+			return wishlist.ItemDetails{}, fmt.Errorf("%w: unknown priority %q", wishlist.ErrInvalidItem, value)
+		}
+		details.Priority = wishlist.Priority(priority)
+	}
+	if value := strings.TrimSpace(form.Get("maxPrice")); value != "" {
+		maxPrice, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return wishlist.ItemDetails{}, fmt.Errorf("%w: the max price must be a number", wishlist.ErrInvalidItem)
+		}
+		details.MaxPrice = &maxPrice
+	}
+	return details, nil
+}
+
+func (h *HttpHandler) GetWishlistPage(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	items, err := h.wishlistService.GetUserWishlist(ctx, userId)
+	if err != nil {
+		handleWishlistError(ctx, w, err)
+		return
+	}
+
+	err = WishlistPage(items).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+// SearchAlbums searches MusicBrainz for albums to wishlist.
+func (h *HttpHandler) SearchAlbums(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    errors.New("missing search query"),
+		})
+		return
+	}
+
+	groups, err := h.mb.SearchReleaseGroups(ctx, query, searchResultsLimit)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status:   http.StatusBadGateway,
+			Err:      err,
+			Response: *httpx.NewErrorResponse().SetComponent(WishlistError("MusicBrainz search isn't available right now")),
+		})
+		return
+	}
+
+	items, err := h.wishlistService.GetUserWishlist(ctx, userId)
+	if err != nil {
+		handleWishlistError(ctx, w, err)
+		return
+	}
+	wishlisted := make(map[string]bool, len(items))
+	for _, item := range items {
+		if item.MusicBrainzID != "" && !item.IsFulfilled() {
+			wishlisted[item.MusicBrainzID] = true
+		}
+	}
+
+	err = WishlistSearchResults(groups, wishlisted).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) GetAddItemModal(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	q := r.URL.Query()
+	target := AddTarget{
+		AlbumID:       q.Get("albumId"),
+		MusicBrainzID: q.Get("musicbrainzId"),
+		Title:         q.Get("title"),
+		Artists:       q.Get("artists"),
+	}
+	if target.Key() == "" {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    errors.New("missing album or MusicBrainz ID"),
+		})
+		return
+	}
+
+	err := AddItemModal(target).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+	}
+}
+
+// AddItem wishlists an album from the add modal, then swaps the button that
+// opened it for a badge.
+func (h *HttpHandler) AddItem(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	details, err := parseItemDetails(r.Form)
+	if err != nil {
+		handleWishlistError(ctx, w, err)
+		return
+	}
+
+	var key string
+	if albumId := r.Form.Get("albumId"); albumId != "" {
+		_, err = h.wishlistService.AddAlbum(ctx, userId, albumId, details)
+		key = albumId
+	} else {
+		group := wishlist.ReleaseGroup{
+			MusicBrainzID: r.Form.Get("musicbrainzId"),
+			Title:         r.Form.Get("title"),
+			Artists:       r.Form.Get("artists"),
+		}
+		_, err = h.wishlistService.AddReleaseGroup(ctx, userId, group, details)
+		key = group.MusicBrainzID
+	}
+	if err != nil {
+		handleWishlistError(ctx, w, err)
+		return
+	}
+
+	err = CloseAddItemModal().Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+
+	err = libraryAdapters.WishlistedBadge(key, true).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    err,
+		})
+		return
+	}
+
+	// Albums found by search are added from the wishlist page itself, so its
+	// list is refreshed too.
+	if r.Form.Get("albumId") == "" {
+		items, err := h.wishlistService.GetUserWishlist(ctx, userId)
+		if err != nil {
+			handleWishlistError(ctx, w, err)
+			return
+		}
+		err = WishlistItems(items, true).Render(ctx, w)
+		if err != nil {
+			httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+				Status: http.StatusInternalServerError,
+				Err:    err,
+			})
+		}
+	}
+}
+
+func (h *HttpHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	details, err := parseItemDetails(r.Form)
+	if err != nil {
+		handleWishlistError(ctx, w, err)
+		return
+	}
+
+	item, err := h.wishlistService.UpdateItem(ctx, userId, r.PathValue("itemId"), details)
+	if err != nil {
+		handleWishlistError(ctx, w, err)
+		return
+	}
+
+	err = WishlistItem(item).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+// SetItemFulfilled marks an item fulfilled by hand, or puts it back on the
+// wishlist, and reloads the page so it moves to the right list.
+func (h *HttpHandler) SetItemFulfilled(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    err,
+		})
+		return
+	}
+
+	_, err = h.wishlistService.SetFulfilled(ctx, userId, r.PathValue("itemId"), r.Form.Get("fulfilled") == "true")
+	if err != nil {
+		handleWishlistError(ctx, w, err)
+		return
+	}
+
+	err = templates.Redirect("/app/wishlist", 0).Render(ctx, w)
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusInternalServerError,
+			Err:    fmt.Errorf("failed to render response: %w", err),
+		})
+		return
+	}
+}
+
+func (h *HttpHandler) DeleteItem(w http.ResponseWriter, r *http.Request) {
+	ctx := contextx.NewContextX(r.Context())
+
+	userId, err := ctx.UserId()
+	if err != nil {
+		httpx.HandleErrorResponse(ctx, w, httpx.HandleErrorResponseProps{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("failed to get user ID: %w", err),
+		})
+		return
+	}
+
+	err = h.wishlistService.DeleteItem(ctx, userId, r.PathValue("itemId"))
+	if err != nil {
+		handleWishlistError(ctx, w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
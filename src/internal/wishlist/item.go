@@ -0,0 +1,185 @@
+package wishlist
+
+import (
+	"errors"
+	"fmt"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"github.com/alecdray/wax/src/internal/core/db/sqlc"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	notesMaxLength     = 1000
+	heardFromMaxLength = 200
+)
+
+var (
+	ErrInvalidItem       = errors.New("invalid wishlist item")
+	ErrItemNotFound      = errors.New("wishlist item not found")
+	ErrAlbumNotFound     = errors.New("album not found")
+	ErrAlreadyWishlisted = errors.New("that album is already on your wishlist")
+)
+
+// Formats lists the formats an item can be wanted in, in display order.
+var Formats = []models.WishlistFormat{
+	models.WishlistFormatAny,
+	models.WishlistFormatVinyl,
+	models.WishlistFormatCD,
+	models.WishlistFormatCassette,
+	models.WishlistFormatDigital,
+}
+
+func FormatLabel(format models.WishlistFormat) string {
+	switch format {
+	case models.WishlistFormatVinyl:
+		return "Vinyl"
+	case models.WishlistFormatCD:
+		return "CD"
+	case models.WishlistFormatCassette:
+		return "Cassette"
+	case models.WishlistFormatDigital:
+		return "Digital"
+	default:
+		return "Any format"
+	}
+}
+
+type Priority int64
+
+const (
+	PriorityHigh   Priority = 1
+	PriorityNormal Priority = 2
+	PriorityLow    Priority = 3
+)
+
+// Priorities lists the priorities from most to least wanted.
+var Priorities = []Priority{PriorityHigh, PriorityNormal, PriorityLow}
+
+func (p Priority) Label() string {
+	switch p {
+	case PriorityHigh:
+		return "High"
+	case PriorityLow:
+		return "Low"
+	default:
+		return "Normal"
+	}
+}
+
+type ItemDTO struct {
+	ID string
+	// AlbumID is set for albums wax already knows about, such as ones from
+	// listening history, and once a MusicBrainz item is found in the library.
+	AlbumID       string
+	MusicBrainzID string
+	Title         string
+	Artists       string
+	ImageURL      string
+	Details       ItemDetails
+	FulfilledAt   *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func newItemDTOFromModel(model sqlc.WishlistItem) ItemDTO {
+	dto := ItemDTO{
+		ID:            model.ID,
+		AlbumID:       model.AlbumID.String,
+		MusicBrainzID: model.MusicbrainzID.String,
+		Title:         model.Title,
+		Artists:       model.ArtistNames,
+		ImageURL:      model.ImageUrl.String,
+		Details: ItemDetails{
+			Format:    model.Format,
+			Priority:  Priority(model.Priority),
+			Notes:     model.Notes,
+			HeardFrom: model.HeardFrom,
+		},
+		CreatedAt: model.CreatedAt,
+		UpdatedAt: model.UpdatedAt,
+	}
+	if model.MaxPrice.Valid {
+		dto.Details.MaxPrice = &model.MaxPrice.Float64
+	}
+	if model.FulfilledAt.Valid {
+		dto.FulfilledAt = &model.FulfilledAt.Time
+	}
+	return dto
+}
+
+func (i ItemDTO) IsFulfilled() bool {
+	return i.FulfilledAt != nil
+}
+
+// ItemDetails is what the user says about an album they want.
+type ItemDetails struct {
+	Format   models.WishlistFormat
+	Priority Priority
+	// MaxPrice is the most the user would pay, nil when they haven't said.
+	MaxPrice *float64
+	Notes    string
+	// HeardFrom is where the user heard about the album, e.g. a friend or a
+	// review.
+	HeardFrom string
+}
+
+func (d ItemDetails) Normalize() ItemDetails {
+	if d.Format == "" {
+		d.Format = models.WishlistFormatAny
+	}
+	if d.Priority == 0 {
+		d.Priority = PriorityNormal
+	}
+	d.Notes = strings.TrimSpace(d.Notes)
+	d.HeardFrom = strings.TrimSpace(d.HeardFrom)
+	return d
+}
+
+func (d ItemDetails) Validate() error {
+	var errs []error
+	if !slices.Contains(Formats, d.Format) {
+		errs = append(errs, fmt.Errorf("unknown format %q", d.Format))
+	}
+	if !slices.Contains(Priorities, d.Priority) {
+		errs = append(errs, fmt.Errorf("unknown priority %d", d.Priority))
+	}
+	if d.MaxPrice != nil && *d.MaxPrice < 0 {
+		errs = append(errs, errors.New("the max price can't be negative"))
+	}
+	if utf8.RuneCountInString(d.Notes) > notesMaxLength {
+		errs = append(errs, fmt.Errorf("notes can be at most %d characters", notesMaxLength))
+	}
+	if utf8.RuneCountInString(d.HeardFrom) > heardFromMaxLength {
+		errs = append(errs, fmt.Errorf("where you heard about it can be at most %d characters", heardFromMaxLength))
+	}
+	return errors.Join(errs...)
+}
+
+// ReleaseGroup identifies an album found on MusicBrainz that wax may not know
+// about yet.
+type ReleaseGroup struct {
+	MusicBrainzID string
+	Title         string
+	Artists       string
+}
+
+func (g ReleaseGroup) Normalize() ReleaseGroup {
+	g.MusicBrainzID = strings.TrimSpace(g.MusicBrainzID)
+	g.Title = strings.TrimSpace(g.Title)
+	g.Artists = strings.TrimSpace(g.Artists)
+	return g
+}
+
+func (g ReleaseGroup) Validate() error {
+	var errs []error
+	if g.MusicBrainzID == "" {
+		errs = append(errs, errors.New("a MusicBrainz ID is required"))
+	}
+	if g.Title == "" {
+		errs = append(errs, errors.New("a title is required"))
+	}
+	return errors.Join(errs...)
+}
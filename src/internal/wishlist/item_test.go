@@ -0,0 +1,74 @@
+package wishlist
+
+import (
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"strings"
+	"testing"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestItemDetails_Normalize(t *testing.T) {
+	details := ItemDetails{Notes: "  look for the reissue  "}.Normalize()
+	if details.Format != models.WishlistFormatAny {
+		t.Errorf("expected format %q, got %q", models.WishlistFormatAny, details.Format)
+	}
+	if details.Priority != PriorityNormal {
+		t.Errorf("expected priority %d, got %d", PriorityNormal, details.Priority)
+	}
+	if details.Notes != "look for the reissue" {
+		t.Errorf("expected trimmed notes, got %q", details.Notes)
+	}
+}
+
+func TestItemDetails_Validate(t *testing.T) {
+	cases := []struct {
+		name    string
+		details ItemDetails
+		ok      bool
+	}{
+		{"defaults", ItemDetails{}, true},
+		{"everything", ItemDetails{Format: models.WishlistFormatVinyl, Priority: PriorityHigh, MaxPrice: ptr(30.0), Notes: "first press", HeardFrom: "Sam"}, true},
+		{"free", ItemDetails{MaxPrice: ptr(0.0)}, true},
+		{"unknown format", ItemDetails{Format: "8-track"}, false},
+		{"unknown priority", ItemDetails{Priority: 4}, false},
+		{"negative price", ItemDetails{MaxPrice: ptr(-1.0)}, false},
+		{"notes too long", ItemDetails{Notes: strings.Repeat("a", 1001)}, false},
+		{"heard from too long", ItemDetails{HeardFrom: strings.Repeat("a", 201)}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.details.Normalize().Validate()
+			if c.ok && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !c.ok && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestReleaseGroup_Validate(t *testing.T) {
+	cases := []struct {
+		name  string
+		group ReleaseGroup
+		ok    bool
+	}{
+		{"complete", ReleaseGroup{MusicBrainzID: "b1392450-e666-3926-a536-22c65f834433", Title: "OK Computer", Artists: "Radiohead"}, true},
+		{"no artists", ReleaseGroup{MusicBrainzID: "b1392450-e666-3926-a536-22c65f834433", Title: "OK Computer"}, true},
+		{"missing id", ReleaseGroup{Title: "OK Computer"}, false},
+		{"missing title", ReleaseGroup{MusicBrainzID: "b1392450-e666-3926-a536-22c65f834433", Title: " "}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.group.Normalize().Validate()
+			if c.ok && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !c.ok && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
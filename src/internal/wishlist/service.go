@@ -0,0 +1,284 @@
+package wishlist
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/alecdray/wax/src/internal/core/db"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+	"github.com/alecdray/wax/src/internal/core/db/sqlc"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Service struct {
+	db *db.DB
+}
+
+func NewService(db *db.DB) *Service {
+	return &Service{db: db}
+}
+
+func getItem(ctx context.Context, tx *db.DB, userId, itemId string) (ItemDTO, error) {
+	model, err := tx.Queries().GetWishlistItem(ctx, sqlc.GetWishlistItemParams{
+		ID:     itemId,
+		UserID: userId,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ItemDTO{}, ErrItemNotFound
+	} else if err != nil {
+		return ItemDTO{}, fmt.Errorf("failed to get wishlist item: %w", err)
+	}
+	return newItemDTOFromModel(model), nil
+}
+
+func maxPriceParam(maxPrice *float64) sql.NullFloat64 {
+	if maxPrice == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: *maxPrice, Valid: true}
+}
+
+func validDetails(details ItemDetails) (ItemDetails, error) {
+	details = details.Normalize()
+	err := details.Validate()
+	if err != nil {
+		return ItemDetails{}, fmt.Errorf("%w: %w", ErrInvalidItem, err)
+	}
+	return details, nil
+}
+
+// GetUserWishlist returns the user's wishlist, wanted items first by priority
+// and fulfilled items last.
+func (s *Service) GetUserWishlist(ctx context.Context, userId string) ([]ItemDTO, error) {
+	models, err := s.db.Queries().GetWishlistItemsByUserId(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wishlist: %w", err)
+	}
+	dtos := make([]ItemDTO, 0, len(models))
+	for _, model := range models {
+		dtos = append(dtos, newItemDTOFromModel(model))
+	}
+	return dtos, nil
+}
+
+func (s *Service) GetItem(ctx context.Context, userId, itemId string) (ItemDTO, error) {
+	return getItem(ctx, s.db, userId, itemId)
+}
+
+// GetAlbumItem returns the user's wishlist item for an album, or nil when the
+// album isn't on their wishlist.
+func (s *Service) GetAlbumItem(ctx context.Context, userId, albumId string) (*ItemDTO, error) {
+	model, err := s.db.Queries().GetWishlistItemByAlbumId(ctx, sqlc.GetWishlistItemByAlbumIdParams{
+		UserID:  userId,
+		AlbumID: sql.NullString{String: albumId, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get wishlist item: %w", err)
+	}
+	dto := newItemDTOFromModel(model)
+	return &dto, nil
+}
+
+// AddAlbum wishlists an album wax already knows about, such as one from the
+// user's listening history.
+func (s *Service) AddAlbum(ctx context.Context, userId, albumId string, details ItemDetails) (ItemDTO, error) {
+	details, err := validDetails(details)
+	if err != nil {
+		return ItemDTO{}, err
+	}
+
+	var item ItemDTO
+	err = s.db.WithTx(func(tx *db.DB) error {
+		_, err := tx.Queries().GetWishlistItemByAlbumId(ctx, sqlc.GetWishlistItemByAlbumIdParams{
+			UserID:  userId,
+			AlbumID: sql.NullString{String: albumId, Valid: true},
+		})
+		if err == nil {
+			return ErrAlreadyWishlisted
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get wishlist item: %w", err)
+		}
+
+		album, err := tx.Queries().GetAlbum(ctx, albumId)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAlbumNotFound
+		} else if err != nil {
+			return fmt.Errorf("failed to get album: %w", err)
+		}
+		artistRows, err := tx.Queries().GetAlbumArtistByAlbumId(ctx, albumId)
+		if err != nil {
+			return fmt.Errorf("failed to get album artists: %w", err)
+		}
+		artists := make([]string, 0, len(artistRows))
+		for _, row := range artistRows {
+			if row.Role != models.ArtistRolePrimary {
+				continue
+			}
+			artists = append(artists, row.Artist.Name)
+		}
+
+		model, err := tx.Queries().CreateWishlistItem(ctx, sqlc.CreateWishlistItemParams{
+			ID:          uuid.NewString(),
+			UserID:      userId,
+			AlbumID:     sql.NullString{String: album.ID, Valid: true},
+			Title:       album.Title,
+			ArtistNames: strings.Join(artists, ", "),
+			ImageUrl:    album.ImageUrl,
+			Format:      details.Format,
+			Priority:    int64(details.Priority),
+			MaxPrice:    maxPriceParam(details.MaxPrice),
+			Notes:       details.Notes,
+			HeardFrom:   details.HeardFrom,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create wishlist item: %w", err)
+		}
+		item = newItemDTOFromModel(model)
+		return nil
+	})
+	if err != nil {
+		return ItemDTO{}, err
+	}
+	return item, nil
+}
+
+// AddReleaseGroup wishlists an album found on MusicBrainz. It's linked to a
+// library album once one with the same title and artist is synced.
+func (s *Service) AddReleaseGroup(ctx context.Context, userId string, group ReleaseGroup, details ItemDetails) (ItemDTO, error) {
+	group = group.Normalize()
+	err := group.Validate()
+	if err != nil {
+		return ItemDTO{}, fmt.Errorf("%w: %w", ErrInvalidItem, err)
+	}
+	details, err = validDetails(details)
+	if err != nil {
+		return ItemDTO{}, err
+	}
+
+	var item ItemDTO
+	err = s.db.WithTx(func(tx *db.DB) error {
+		musicbrainzId := sql.NullString{String: group.MusicBrainzID, Valid: true}
+		_, err := tx.Queries().GetWishlistItemByMusicBrainzId(ctx, sqlc.GetWishlistItemByMusicBrainzIdParams{
+			UserID:        userId,
+			MusicbrainzID: musicbrainzId,
+		})
+		if err == nil {
+			return ErrAlreadyWishlisted
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get wishlist item: %w", err)
+		}
+
+		model, err := tx.Queries().CreateWishlistItem(ctx, sqlc.CreateWishlistItemParams{
+			ID:            uuid.NewString(),
+			UserID:        userId,
+			MusicbrainzID: musicbrainzId,
+			Title:         group.Title,
+			ArtistNames:   group.Artists,
+			Format:        details.Format,
+			Priority:      int64(details.Priority),
+			MaxPrice:      maxPriceParam(details.MaxPrice),
+			Notes:         details.Notes,
+			HeardFrom:     details.HeardFrom,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create wishlist item: %w", err)
+		}
+		item = newItemDTOFromModel(model)
+		return nil
+	})
+	if err != nil {
+		return ItemDTO{}, err
+	}
+	return item, nil
+}
+
+func (s *Service) UpdateItem(ctx context.Context, userId, itemId string, details ItemDetails) (ItemDTO, error) {
+	details, err := validDetails(details)
+	if err != nil {
+		return ItemDTO{}, err
+	}
+	model, err := s.db.Queries().UpdateWishlistItem(ctx, sqlc.UpdateWishlistItemParams{
+		Format:    details.Format,
+		Priority:  int64(details.Priority),
+		MaxPrice:  maxPriceParam(details.MaxPrice),
+		Notes:     details.Notes,
+		HeardFrom: details.HeardFrom,
+		ID:        itemId,
+		UserID:    userId,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ItemDTO{}, ErrItemNotFound
+	} else if err != nil {
+		return ItemDTO{}, fmt.Errorf("failed to update wishlist item: %w", err)
+	}
+	return newItemDTOFromModel(model), nil
+}
+
+// SetFulfilled marks an item fulfilled by hand, e.g. for a record bought in a
+// shop, or puts a fulfilled item back on the wishlist.
+func (s *Service) SetFulfilled(ctx context.Context, userId, itemId string, fulfilled bool) (ItemDTO, error) {
+	fulfilledAt := sql.NullTime{}
+	if fulfilled {
+		fulfilledAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+	model, err := s.db.Queries().SetWishlistItemFulfilledAt(ctx, sqlc.SetWishlistItemFulfilledAtParams{
+		FulfilledAt: fulfilledAt,
+		ID:          itemId,
+		UserID:      userId,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ItemDTO{}, ErrItemNotFound
+	} else if err != nil {
+		return ItemDTO{}, fmt.Errorf("failed to update wishlist item: %w", err)
+	}
+	return newItemDTOFromModel(model), nil
+}
+
+func (s *Service) DeleteItem(ctx context.Context, userId, itemId string) error {
+	return s.db.WithTx(func(tx *db.DB) error {
+		_, err := getItem(ctx, tx, userId, itemId)
+		if err != nil {
+			return err
+		}
+		err = tx.Queries().DeleteWishlistItem(ctx, sqlc.DeleteWishlistItemParams{
+			ID:     itemId,
+			UserID: userId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete wishlist item: %w", err)
+		}
+		return nil
+	})
+}
+
+// FulfillFromLibrary marks wanted items fulfilled once the user's library has
+// the album in the wanted format. MusicBrainz items are first linked to a
+// library album with the same title and artist. It returns how many items
+// were fulfilled.
+func (s *Service) FulfillFromLibrary(ctx context.Context, userId string) (int64, error) {
+	var fulfilled int64
+	err := s.db.WithTx(func(tx *db.DB) error {
+		_, err := tx.Queries().LinkWishlistItemsToLibrary(ctx, userId)
+		if err != nil {
+			return fmt.Errorf("failed to link wishlist items to library: %w", err)
+		}
+		fulfilled, err = tx.Queries().FulfillWishlistItemsInLibrary(ctx, sqlc.FulfillWishlistItemsInLibraryParams{
+			FulfilledAt: sql.NullTime{Time: time.Now(), Valid: true},
+			UserID:      userId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to fulfill wishlist items: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return fulfilled, nil
+}
@@ -0,0 +1,133 @@
+package wishlist
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alecdray/wax/src/internal/core/db"
+	"github.com/alecdray/wax/src/internal/core/db/dbtest"
+	"github.com/alecdray/wax/src/internal/core/db/models"
+)
+
+func newTestService(t *testing.T) (*Service, *db.DB) {
+	database := dbtest.New(t)
+	return NewService(database), database
+}
+
+func TestFulfillFromLibrary_OnlyFulfillsWantedItemsNowInTheLibrary(t *testing.T) {
+	service, database := newTestService(t)
+	ctx := context.Background()
+
+	dbtest.Exec(t, database, "INSERT INTO users (id, spotify_id) VALUES ('u1', 'u1'), ('u2', 'u2')")
+	dbtest.Exec(t, database, "INSERT INTO artists (id, spotify_id, name) VALUES ('ar1', 'ar1', 'Radiohead')")
+	for _, album := range []struct{ id, title string }{
+		{"ok", "OK Computer"}, {"kid", "Kid A"}, {"amnesiac", "Amnesiac"}, {"rainbows", "In Rainbows"},
+	} {
+		dbtest.Exec(t, database, "INSERT INTO albums (id, spotify_id, title) VALUES (?, ?, ?)", album.id, album.id, album.title)
+		dbtest.Exec(t, database, "INSERT INTO album_artists (album_id, artist_id) VALUES (?, 'ar1')", album.id)
+		dbtest.Exec(t, database, "INSERT INTO releases (id, album_id, format) VALUES (?, ?, 'digital')", album.id+"-r", album.id)
+	}
+
+	add := func(userId, albumId string, format models.WishlistFormat) ItemDTO {
+		t.Helper()
+		item, err := service.AddAlbum(ctx, userId, albumId, ItemDetails{Format: format})
+		if err != nil {
+			t.Fatalf("failed to wishlist %s: %v", albumId, err)
+		}
+		return item
+	}
+	wanted := add("u1", "ok", models.WishlistFormatAny)
+	onVinyl := add("u1", "kid", models.WishlistFormatVinyl)
+	notSynced := add("u1", "amnesiac", models.WishlistFormatAny)
+	theirs := add("u2", "ok", models.WishlistFormatAny)
+	fromMusicBrainz, err := service.AddReleaseGroup(ctx, "u1", ReleaseGroup{
+		MusicBrainzID: "6e729716-c0eb-3f50-a740-96ac173be50d",
+		Title:         "in rainbows",
+		Artists:       "Radiohead",
+	}, ItemDetails{})
+	if err != nil {
+		t.Fatalf("failed to wishlist a release group: %v", err)
+	}
+
+	// The library sync brings in digital copies of everything but Amnesiac.
+	for _, albumId := range []string{"ok", "kid", "rainbows"} {
+		dbtest.Exec(t, database, "INSERT INTO user_releases (id, user_id, release_id) VALUES (?, 'u1', ?)", "ur-"+albumId, albumId+"-r")
+	}
+
+	fulfilled, err := service.FulfillFromLibrary(ctx, "u1")
+	if err != nil {
+		t.Fatalf("failed to fulfill from library: %v", err)
+	}
+	if fulfilled != 2 {
+		t.Errorf("expected 2 items fulfilled, got %d", fulfilled)
+	}
+
+	get := func(userId string, item ItemDTO) ItemDTO {
+		t.Helper()
+		got, err := service.GetItem(ctx, userId, item.ID)
+		if err != nil {
+			t.Fatalf("failed to get %s: %v", item.Title, err)
+		}
+		return got
+	}
+	if got := get("u1", wanted); got.FulfilledAt == nil {
+		t.Errorf("expected %s fulfilled", got.Title)
+	}
+	if got := get("u1", fromMusicBrainz); got.FulfilledAt == nil || got.AlbumID != "rainbows" {
+		t.Errorf("expected the release group linked to the library album and fulfilled, got %+v", got)
+	}
+	if got := get("u1", onVinyl); got.FulfilledAt != nil {
+		t.Errorf("expected the vinyl item still wanted with only a digital copy, got %+v", got)
+	}
+	if got := get("u1", notSynced); got.FulfilledAt != nil {
+		t.Errorf("expected the album not in the library still wanted, got %+v", got)
+	}
+	if got := get("u2", theirs); got.FulfilledAt != nil {
+		t.Errorf("expected another user's item left alone, got %+v", got)
+	}
+
+	// Running it again after the next sync finds nothing new.
+	fulfilled, err = service.FulfillFromLibrary(ctx, "u1")
+	if err != nil || fulfilled != 0 {
+		t.Errorf("expected nothing more to fulfill, got %d, %v", fulfilled, err)
+	}
+}
+
+func TestFulfillFromLibrary_MatchesWholeArtistNames(t *testing.T) {
+	service, database := newTestService(t)
+	ctx := context.Background()
+
+	dbtest.Exec(t, database, "INSERT INTO users (id, spotify_id) VALUES ('u1', 'u1')")
+	dbtest.Exec(t, database, "INSERT INTO artists (id, spotify_id, name) VALUES ('ar1', 'ar1', 'Can')")
+	for _, album := range []struct{ id, title string }{{"tago", "Tago Mago"}, {"soon", "Soon Over Babaluma"}} {
+		dbtest.Exec(t, database, "INSERT INTO albums (id, spotify_id, title) VALUES (?, ?, ?)", album.id, album.id, album.title)
+		dbtest.Exec(t, database, "INSERT INTO album_artists (album_id, artist_id) VALUES (?, 'ar1')", album.id)
+		dbtest.Exec(t, database, "INSERT INTO releases (id, album_id, format) VALUES (?, ?, 'digital')", album.id+"-r", album.id)
+		dbtest.Exec(t, database, "INSERT INTO user_releases (id, user_id, release_id) VALUES (?, 'u1', ?)", "ur-"+album.id, album.id+"-r")
+	}
+
+	add := func(musicBrainzId, title, artists string) ItemDTO {
+		t.Helper()
+		item, err := service.AddReleaseGroup(ctx, "u1", ReleaseGroup{MusicBrainzID: musicBrainzId, Title: title, Artists: artists}, ItemDetails{})
+		if err != nil {
+			t.Fatalf("failed to wishlist %s: %v", title, err)
+		}
+		return item
+	}
+	someoneElse := add("mb-1", "Tago Mago", "Duncan Sheik")
+	credited := add("mb-2", "Soon Over Babaluma", "CAN & Damo Suzuki")
+
+	fulfilled, err := service.FulfillFromLibrary(ctx, "u1")
+	if err != nil {
+		t.Fatalf("failed to fulfill from library: %v", err)
+	}
+	if fulfilled != 1 {
+		t.Errorf("expected 1 item fulfilled, got %d", fulfilled)
+	}
+	if got, err := service.GetItem(ctx, "u1", someoneElse.ID); err != nil || got.AlbumID != "" || got.FulfilledAt != nil {
+		t.Errorf("expected an artist only containing the name to be left alone, got %+v, %v", got, err)
+	}
+	if got, err := service.GetItem(ctx, "u1", credited.ID); err != nil || got.AlbumID != "soon" || got.FulfilledAt == nil {
+		t.Errorf("expected a credit naming the artist to be linked and fulfilled, got %+v, %v", got, err)
+	}
+}